@@ -0,0 +1,483 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build go1.17
+// +build go1.17
+
+package bigquery
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"time"
+
+	"cloud.google.com/go/civil"
+	"github.com/apache/arrow/go/v10/arrow"
+	"github.com/apache/arrow/go/v10/arrow/array"
+	"github.com/apache/arrow/go/v10/arrow/decimal128"
+	"github.com/apache/arrow/go/v10/arrow/decimal256"
+	"github.com/apache/arrow/go/v10/arrow/ipc"
+	"github.com/apache/arrow/go/v10/arrow/memory"
+	"google.golang.org/api/iterator"
+	storagepb "google.golang.org/genproto/googleapis/cloud/bigquery/storage/v1"
+)
+
+// defaultArrowBatchSize is the number of rows per record used when records
+// are built from rows returned by the BigQuery API.
+const defaultArrowBatchSize = 1024
+
+var (
+	arrowNumericType    = &arrow.Decimal128Type{Precision: 38, Scale: NumericScaleDigits}
+	arrowBigNumericType = &arrow.Decimal256Type{Precision: 76, Scale: BigNumericScaleDigits}
+	arrowTimestampType  = &arrow.TimestampType{Unit: arrow.Microsecond, TimeZone: "UTC"}
+	arrowDateTimeType   = &arrow.TimestampType{Unit: arrow.Microsecond}
+	arrowTimeType       = &arrow.Time64Type{Unit: arrow.Microsecond}
+)
+
+// ArrowRecordIterator is an iterator over the rows of a RowIterator, grouped
+// into Arrow records.
+type ArrowRecordIterator struct {
+	it        *RowIterator
+	mem       memory.Allocator
+	batchSize int
+
+	schema  *arrow.Schema
+	started bool
+
+	// Set when records are read from the BigQuery Storage API.
+	read    *storageArrowReader
+	pending []arrow.Record
+}
+
+// ArrowRecords returns an iterator over the rows of it as Arrow records.
+// It must be called before the first call to Next on it, and it must not be
+// used with Next afterwards.
+//
+// If the client has a Storage Read API client enabled (see
+// Client.EnableStorageReadClient) and the rows are read from a table or the
+// destination table of a query, the rows are streamed in Arrow format
+// directly from the BigQuery Storage API. Otherwise, rows are fetched with
+// the BigQuery API and converted to records of at most batchSize rows. A
+// batchSize of zero or less uses a default size.
+//
+// If mem is nil, memory.DefaultAllocator is used.
+//
+// ArrowRecords requires Go 1.17 or later.
+func (it *RowIterator) ArrowRecords(mem memory.Allocator, batchSize int) *ArrowRecordIterator {
+	if mem == nil {
+		mem = memory.DefaultAllocator
+	}
+	if batchSize <= 0 {
+		batchSize = defaultArrowBatchSize
+	}
+	return &ArrowRecordIterator{it: it, mem: mem, batchSize: batchSize}
+}
+
+// Schema returns the Arrow schema of the records. It is available after the
+// first call to Next.
+func (ai *ArrowRecordIterator) Schema() *arrow.Schema {
+	return ai.schema
+}
+
+// Next returns the next record. Its second return value is iterator.Done if
+// there are no more results. Once Next returns Done, all subsequent calls will
+// return Done.
+//
+// The caller is responsible for calling Release on the returned record.
+func (ai *ArrowRecordIterator) Next() (arrow.Record, error) {
+	if !ai.started {
+		ai.started = true
+		r, err := ai.it.storageArrowReader(ai.mem)
+		if err != nil {
+			return nil, err
+		}
+		if r != nil {
+			ai.read = r
+			ai.schema = r.schema
+		}
+	}
+	if ai.read != nil {
+		return ai.nextFromStorage()
+	}
+	return ai.nextFromRows()
+}
+
+func (ai *ArrowRecordIterator) nextFromStorage() (arrow.Record, error) {
+	for len(ai.pending) == 0 {
+		recs, err := ai.read.next()
+		if err != nil {
+			return nil, err
+		}
+		ai.pending = recs
+	}
+	rec := ai.pending[0]
+	ai.pending = ai.pending[1:]
+	return rec, nil
+}
+
+func (ai *ArrowRecordIterator) nextFromRows() (arrow.Record, error) {
+	var b *array.RecordBuilder
+	n := 0
+	for n < ai.batchSize {
+		var row []Value
+		err := ai.it.Next(&row)
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			if b != nil {
+				b.Release()
+			}
+			return nil, err
+		}
+		if b == nil {
+			if ai.schema == nil {
+				s, err := ai.it.Schema.toArrow()
+				if err != nil {
+					return nil, err
+				}
+				ai.schema = s
+			}
+			b = array.NewRecordBuilder(ai.mem, ai.schema)
+		}
+		for i, f := range ai.it.Schema {
+			if err := appendArrowValue(b.Field(i), f, row[i]); err != nil {
+				b.Release()
+				return nil, fmt.Errorf("bigquery: column %s: %v", f.Name, err)
+			}
+		}
+		n++
+	}
+	if b == nil {
+		return nil, iterator.Done
+	}
+	defer b.Release()
+	return b.NewRecord(), nil
+}
+
+// toArrow returns the Arrow schema corresponding to s, using the same type
+// mapping as the BigQuery Storage API.
+func (s Schema) toArrow() (*arrow.Schema, error) {
+	fields := make([]arrow.Field, len(s))
+	for i, f := range s {
+		af, err := f.toArrow()
+		if err != nil {
+			return nil, err
+		}
+		fields[i] = af
+	}
+	return arrow.NewSchema(fields, nil), nil
+}
+
+func (fs *FieldSchema) toArrow() (arrow.Field, error) {
+	af := arrow.Field{Name: fs.Name, Nullable: !fs.Required && !fs.Repeated}
+	switch fs.Type {
+	case StringFieldType, IntervalFieldType, RangeFieldType:
+		af.Type = arrow.BinaryTypes.String
+	case GeographyFieldType:
+		af.Type = arrow.BinaryTypes.String
+		af.Metadata = arrow.NewMetadata([]string{"ARROW:extension:name"}, []string{"google:sqlType:geography"})
+	case BytesFieldType:
+		af.Type = arrow.BinaryTypes.Binary
+	case IntegerFieldType:
+		af.Type = arrow.PrimitiveTypes.Int64
+	case FloatFieldType:
+		af.Type = arrow.PrimitiveTypes.Float64
+	case BooleanFieldType:
+		af.Type = arrow.FixedWidthTypes.Boolean
+	case TimestampFieldType:
+		af.Type = arrowTimestampType
+	case DateFieldType:
+		af.Type = arrow.FixedWidthTypes.Date32
+	case TimeFieldType:
+		af.Type = arrowTimeType
+	case DateTimeFieldType:
+		af.Type = arrowDateTimeType
+	case NumericFieldType:
+		af.Type = arrowNumericType
+	case BigNumericFieldType:
+		af.Type = arrowBigNumericType
+	case RecordFieldType:
+		sub, err := fs.Schema.toArrow()
+		if err != nil {
+			return arrow.Field{}, err
+		}
+		af.Type = arrow.StructOf(sub.Fields()...)
+	default:
+		return arrow.Field{}, fmt.Errorf("bigquery: column %s has type %s, which cannot be represented in Arrow", fs.Name, fs.Type)
+	}
+	if fs.Repeated {
+		af.Type = arrow.ListOf(af.Type)
+	}
+	return af, nil
+}
+
+// appendArrowValue appends v, a value of the column described by fs, to b.
+func appendArrowValue(b array.Builder, fs *FieldSchema, v Value) error {
+	if v == nil {
+		b.AppendNull()
+		return nil
+	}
+	if fs.Repeated {
+		vals, ok := v.([]Value)
+		if !ok {
+			return fmt.Errorf("got %T for repeated column, want []Value", v)
+		}
+		lb := b.(*array.ListBuilder)
+		lb.Append(true)
+		elem := *fs
+		elem.Repeated = false
+		for _, ev := range vals {
+			if err := appendArrowValue(lb.ValueBuilder(), &elem, ev); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	var ok bool
+	switch fs.Type {
+	case StringFieldType, GeographyFieldType:
+		var s string
+		if s, ok = v.(string); ok {
+			b.(*array.StringBuilder).Append(s)
+		}
+	case IntervalFieldType:
+		var iv *IntervalValue
+		if iv, ok = v.(*IntervalValue); ok {
+			b.(*array.StringBuilder).Append(iv.String())
+		}
+	case RangeFieldType:
+		var rv *RangeValue
+		if rv, ok = v.(*RangeValue); ok {
+			b.(*array.StringBuilder).Append(rv.String())
+		}
+	case BytesFieldType:
+		var bs []byte
+		if bs, ok = v.([]byte); ok {
+			b.(*array.BinaryBuilder).Append(bs)
+		}
+	case IntegerFieldType:
+		var i int64
+		if i, ok = v.(int64); ok {
+			b.(*array.Int64Builder).Append(i)
+		}
+	case FloatFieldType:
+		var f float64
+		if f, ok = v.(float64); ok {
+			b.(*array.Float64Builder).Append(f)
+		}
+	case BooleanFieldType:
+		var bv bool
+		if bv, ok = v.(bool); ok {
+			b.(*array.BooleanBuilder).Append(bv)
+		}
+	case TimestampFieldType:
+		var t time.Time
+		if t, ok = v.(time.Time); ok {
+			b.(*array.TimestampBuilder).Append(arrow.Timestamp(unixMicros(t)))
+		}
+	case DateFieldType:
+		var d civil.Date
+		if d, ok = v.(civil.Date); ok {
+			b.(*array.Date32Builder).Append(arrow.Date32(d.DaysSince(civil.Date{Year: 1970, Month: time.January, Day: 1})))
+		}
+	case TimeFieldType:
+		var t civil.Time
+		if t, ok = v.(civil.Time); ok {
+			micros := (int64(t.Hour)*3600+int64(t.Minute)*60+int64(t.Second))*1e6 + int64(t.Nanosecond)/1e3
+			b.(*array.Time64Builder).Append(arrow.Time64(micros))
+		}
+	case DateTimeFieldType:
+		var dt civil.DateTime
+		if dt, ok = v.(civil.DateTime); ok {
+			b.(*array.TimestampBuilder).Append(arrow.Timestamp(unixMicros(dt.In(time.UTC))))
+		}
+	case NumericFieldType:
+		var r *big.Rat
+		if r, ok = v.(*big.Rat); ok {
+			b.(*array.Decimal128Builder).Append(decimal128.FromBigInt(scaleRat(r, NumericScaleDigits)))
+		}
+	case BigNumericFieldType:
+		var r *big.Rat
+		if r, ok = v.(*big.Rat); ok {
+			b.(*array.Decimal256Builder).Append(decimal256.FromBigInt(scaleRat(r, BigNumericScaleDigits)))
+		}
+	case RecordFieldType:
+		var vals []Value
+		if vals, ok = v.([]Value); ok {
+			sb := b.(*array.StructBuilder)
+			sb.Append(true)
+			for i, sf := range fs.Schema {
+				if err := appendArrowValue(sb.FieldBuilder(i), sf, vals[i]); err != nil {
+					return fmt.Errorf("%s: %v", sf.Name, err)
+				}
+			}
+		}
+	default:
+		return fmt.Errorf("unsupported type %s", fs.Type)
+	}
+	if !ok {
+		return fmt.Errorf("got %T for %s column", v, fs.Type)
+	}
+	return nil
+}
+
+func unixMicros(t time.Time) int64 {
+	return t.Unix()*1e6 + int64(t.Nanosecond())/1e3
+}
+
+// scaleRat returns r multiplied by 10^scale, truncated to an integer.
+func scaleRat(r *big.Rat, scale int) *big.Int {
+	pow := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(scale)), nil)
+	n := new(big.Int).Mul(r.Num(), pow)
+	return n.Quo(n, r.Denom())
+}
+
+// storageArrowReader reads the rows of a table from the BigQuery Storage API
+// in Arrow format.
+type storageArrowReader struct {
+	ctx       context.Context
+	c         *Client
+	mem       memory.Allocator
+	schema    *arrow.Schema
+	rawSchema []byte
+	streams   []string
+
+	rows storagepb.BigQueryRead_ReadRowsClient
+}
+
+// storageArrowReader returns a reader for the rows of it from the Storage
+// API, or nil if the rows must be read with the BigQuery API.
+func (it *RowIterator) storageArrowReader(mem memory.Allocator) (*storageArrowReader, error) {
+	if it.src == nil || it.fetched || it.StartIndex != 0 || it.pageInfo.Token != "" {
+		return nil, nil
+	}
+	var (
+		c   *Client
+		tbl *Table
+	)
+	switch {
+	case it.src.t != nil:
+		c, tbl = it.src.t.c, it.src.t
+	case it.src.j != nil:
+		c = it.src.j.c
+		if c == nil || c.readClient == nil {
+			return nil, nil
+		}
+		job, err := c.JobFromProject(it.ctx, it.src.j.projectID, it.src.j.jobID, it.src.j.location)
+		if err != nil {
+			return nil, err
+		}
+		cfg, err := job.Config()
+		if err != nil {
+			return nil, err
+		}
+		// Scripts and DDL statements have no destination table to read.
+		if qc, ok := cfg.(*QueryConfig); ok && qc.Dst != nil {
+			tbl = qc.Dst
+		}
+	}
+	if c == nil || c.readClient == nil || tbl == nil {
+		return nil, nil
+	}
+	session, err := c.readClient.CreateReadSession(it.ctx, &storagepb.CreateReadSessionRequest{
+		Parent: fmt.Sprintf("projects/%s", c.projectID),
+		ReadSession: &storagepb.ReadSession{
+			Table:      fmt.Sprintf("projects/%s/datasets/%s/tables/%s", tbl.ProjectID, tbl.DatasetID, tbl.TableID),
+			DataFormat: storagepb.DataFormat_ARROW,
+		},
+		// A single stream preserves the order of query results.
+		MaxStreamCount: 1,
+	})
+	if err != nil {
+		return nil, err
+	}
+	r := &storageArrowReader{
+		ctx:       it.ctx,
+		c:         c,
+		mem:       mem,
+		rawSchema: session.GetArrowSchema().GetSerializedSchema(),
+	}
+	for _, s := range session.GetStreams() {
+		r.streams = append(r.streams, s.GetName())
+	}
+	ir, err := ipc.NewReader(bytes.NewReader(r.rawSchema), ipc.WithAllocator(mem))
+	if err != nil {
+		return nil, fmt.Errorf("bigquery: decoding Arrow schema: %v", err)
+	}
+	r.schema = ir.Schema()
+	ir.Release()
+	return r, nil
+}
+
+// next returns the records decoded from the next response of the read
+// streams, or iterator.Done once all streams are exhausted.
+func (r *storageArrowReader) next() ([]arrow.Record, error) {
+	for {
+		if r.rows == nil {
+			if len(r.streams) == 0 {
+				return nil, iterator.Done
+			}
+			rows, err := r.c.readClient.ReadRows(r.ctx, &storagepb.ReadRowsRequest{ReadStream: r.streams[0]})
+			if err != nil {
+				return nil, err
+			}
+			r.streams = r.streams[1:]
+			r.rows = rows
+		}
+		res, err := r.rows.Recv()
+		if err == io.EOF {
+			r.rows = nil
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		batch := res.GetArrowRecordBatch().GetSerializedRecordBatch()
+		if len(batch) == 0 {
+			continue
+		}
+		return r.decode(batch)
+	}
+}
+
+func (r *storageArrowReader) decode(batch []byte) ([]arrow.Record, error) {
+	buf := make([]byte, 0, len(r.rawSchema)+len(batch))
+	buf = append(append(buf, r.rawSchema...), batch...)
+	ir, err := ipc.NewReader(bytes.NewReader(buf), ipc.WithAllocator(r.mem), ipc.WithSchema(r.schema))
+	if err != nil {
+		return nil, fmt.Errorf("bigquery: decoding Arrow record batch: %v", err)
+	}
+	defer ir.Release()
+	var recs []arrow.Record
+	for ir.Next() {
+		rec := ir.Record()
+		rec.Retain()
+		recs = append(recs, rec)
+	}
+	if err := ir.Err(); err != nil {
+		for _, rec := range recs {
+			rec.Release()
+		}
+		return nil, fmt.Errorf("bigquery: decoding Arrow record batch: %v", err)
+	}
+	if len(recs) == 0 {
+		return nil, errors.New("bigquery: empty Arrow record batch")
+	}
+	return recs, nil
+}
@@ -0,0 +1,158 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build go1.17
+// +build go1.17
+
+package bigquery
+
+import (
+	"context"
+	"math/big"
+	"testing"
+	"time"
+
+	"cloud.google.com/go/civil"
+	"github.com/apache/arrow/go/v10/arrow"
+	"github.com/apache/arrow/go/v10/arrow/array"
+	"github.com/apache/arrow/go/v10/arrow/memory"
+	"google.golang.org/api/iterator"
+)
+
+func TestSchemaToArrow(t *testing.T) {
+	schema := Schema{
+		{Name: "s", Type: StringFieldType, Required: true},
+		{Name: "n", Type: NumericFieldType},
+		{Name: "bn", Type: BigNumericFieldType},
+		{Name: "ts", Type: TimestampFieldType},
+		{Name: "dt", Type: DateTimeFieldType},
+		{Name: "tags", Type: StringFieldType, Repeated: true},
+		{Name: "rec", Type: RecordFieldType, Schema: Schema{
+			{Name: "a", Type: IntegerFieldType},
+		}},
+	}
+	got, err := schema.toArrow()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := arrow.NewSchema([]arrow.Field{
+		{Name: "s", Type: arrow.BinaryTypes.String},
+		{Name: "n", Type: &arrow.Decimal128Type{Precision: 38, Scale: 9}, Nullable: true},
+		{Name: "bn", Type: &arrow.Decimal256Type{Precision: 76, Scale: 38}, Nullable: true},
+		{Name: "ts", Type: &arrow.TimestampType{Unit: arrow.Microsecond, TimeZone: "UTC"}, Nullable: true},
+		{Name: "dt", Type: &arrow.TimestampType{Unit: arrow.Microsecond}, Nullable: true},
+		{Name: "tags", Type: arrow.ListOf(arrow.BinaryTypes.String)},
+		{Name: "rec", Type: arrow.StructOf(arrow.Field{Name: "a", Type: arrow.PrimitiveTypes.Int64, Nullable: true}), Nullable: true},
+	}, nil)
+	if !got.Equal(want) {
+		t.Errorf("got  %s\nwant %s", got, want)
+	}
+
+	if _, err := (Schema{{Name: "x", Type: "UNKNOWN"}}).toArrow(); err == nil {
+		t.Error("got nil error for unknown type, want error")
+	}
+}
+
+func TestArrowRecordsFromRows(t *testing.T) {
+	schema := Schema{
+		{Name: "name", Type: StringFieldType},
+		{Name: "n", Type: IntegerFieldType},
+		{Name: "ts", Type: TimestampFieldType},
+		{Name: "d", Type: DateFieldType},
+		{Name: "num", Type: NumericFieldType},
+		{Name: "tags", Type: StringFieldType, Repeated: true},
+		{Name: "rec", Type: RecordFieldType, Schema: Schema{
+			{Name: "b", Type: BooleanFieldType},
+		}},
+	}
+	ts := time.Date(2022, 1, 2, 3, 4, 5, 6000, time.UTC)
+	rows := [][]Value{
+		{"a", int64(1), ts, civil.Date{Year: 1970, Month: 1, Day: 3}, big.NewRat(3, 2), []Value{"x", "y"}, []Value{true}},
+		{nil, int64(2), nil, nil, nil, []Value{}, nil},
+		{"c", int64(3), ts, civil.Date{Year: 1970, Month: 1, Day: 1}, big.NewRat(-1, 4), []Value{"z"}, []Value{false}},
+	}
+	pf := &pageFetcherStub{
+		fetchResponses: map[string]fetchResponse{
+			"": {result: &fetchPageResult{schema: schema, rows: rows}},
+		},
+	}
+	it := newRowIterator(context.Background(), nil, pf.fetchPage)
+	mem := memory.NewCheckedAllocator(memory.NewGoAllocator())
+	defer mem.AssertSize(t, 0)
+
+	ai := it.ArrowRecords(mem, 2)
+	var got []arrow.Record
+	for {
+		rec, err := ai.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		got = append(got, rec)
+	}
+	defer func() {
+		for _, rec := range got {
+			rec.Release()
+		}
+	}()
+	if len(got) != 2 || got[0].NumRows() != 2 || got[1].NumRows() != 1 {
+		t.Fatalf("got %d records, want records of 2 and 1 rows", len(got))
+	}
+	if want, _ := schema.toArrow(); !ai.Schema().Equal(want) {
+		t.Errorf("Schema: got %s, want %s", ai.Schema(), want)
+	}
+
+	first := got[0]
+	names := first.Column(0).(*array.String)
+	if names.Value(0) != "a" || !names.IsNull(1) {
+		t.Errorf("name column: got %s", names)
+	}
+	if got, want := first.Column(2).(*array.Timestamp).Value(0), arrow.Timestamp(ts.UnixNano()/1e3); got != want {
+		t.Errorf("ts: got %d, want %d", got, want)
+	}
+	if got := first.Column(3).(*array.Date32).Value(0); got != 2 {
+		t.Errorf("d: got %d, want 2", got)
+	}
+	if got := first.Column(4).(*array.Decimal128).Value(0).BigInt().Int64(); got != 1500000000 {
+		t.Errorf("num: got %d, want 1500000000", got)
+	}
+	tags := first.Column(5).(*array.List)
+	if start, end := tags.ValueOffsets(0); end-start != 2 {
+		t.Errorf("tags: got %d elements in first row, want 2", end-start)
+	}
+	rec := first.Column(6).(*array.Struct)
+	if !rec.Field(0).(*array.Boolean).Value(0) || !rec.IsNull(1) {
+		t.Errorf("rec: got %s", rec)
+	}
+	if got := got[1].Column(4).(*array.Decimal128).Value(0).BigInt().Int64(); got != -250000000 {
+		t.Errorf("num: got %d, want -250000000", got)
+	}
+}
+
+func TestArrowRecordsTypeMismatch(t *testing.T) {
+	pf := &pageFetcherStub{
+		fetchResponses: map[string]fetchResponse{
+			"": {result: &fetchPageResult{
+				schema: Schema{{Name: "n", Type: IntegerFieldType}},
+				rows:   [][]Value{{"not an int"}},
+			}},
+		},
+	}
+	it := newRowIterator(context.Background(), nil, pf.fetchPage)
+	if _, err := it.ArrowRecords(nil, 0).Next(); err == nil {
+		t.Error("got nil error, want error")
+	}
+}
@@ -16,6 +16,7 @@ package bigquery
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
@@ -24,6 +25,7 @@ import (
 	"time"
 
 	"cloud.google.com/go/bigquery/internal"
+	storage "cloud.google.com/go/bigquery/storage/apiv1"
 	cloudinternal "cloud.google.com/go/internal"
 	"cloud.google.com/go/internal/detect"
 	"cloud.google.com/go/internal/version"
@@ -54,8 +56,23 @@ type Client struct {
 	// those operations will override this value.
 	Location string
 
-	projectID string
-	bqs       *bq.Service
+	// JobRetryPolicy, if set, controls how job insertion is retried when
+	// BigQuery reports a transient failure such as rate limiting. If nil,
+	// the policy returned by DefaultJobRetryPolicy is used.
+	JobRetryPolicy *JobRetryPolicy
+
+	// JobPollBackoff, if set, controls how often Job.Wait, Job.Read and
+	// Query.Read poll BigQuery while waiting for a job to complete. A new
+	// copy of the backoff is used for each wait. If nil, query jobs are
+	// polled with a backoff starting at one second and growing to at most one
+	// minute, and other jobs with the default gax.Backoff.
+	//
+	// For a fixed interval, set Initial and Max to the same value.
+	JobPollBackoff *gax.Backoff
+
+	projectID  string
+	bqs        *bq.Service
+	readClient *storage.BigQueryReadClient
 }
 
 // DetectProjectID is a sentinel value that instructs NewClient to detect the
@@ -106,19 +123,55 @@ func (c *Client) Project() string {
 // Close should be called when the client is no longer needed.
 // It need not be called at program exit.
 func (c *Client) Close() error {
+	if c.readClient == nil {
+		return nil
+	}
+	// Drop the read client even if closing it fails, since its connection
+	// can't be used again either way.
+	err := c.readClient.Close()
+	c.readClient = nil
+	return err
+}
+
+// EnableStorageReadClient sets up a client for the BigQuery Storage Read API,
+// which is used to read table and query results in Arrow format. See
+// RowIterator.ArrowRecords, which requires Go 1.17 or later.
+//
+// It returns an error if the read client is already enabled.
+func (c *Client) EnableStorageReadClient(ctx context.Context, opts ...option.ClientOption) error {
+	if c.readClient != nil {
+		return errors.New("bigquery: storage read client already enabled")
+	}
+	rc, err := storage.NewBigQueryReadClient(ctx, opts...)
+	if err != nil {
+		return fmt.Errorf("bigquery: constructing storage read client: %w", err)
+	}
+	c.readClient = rc
 	return nil
 }
 
 // Calls the Jobs.Insert RPC and returns a Job.
-func (c *Client) insertJob(ctx context.Context, job *bq.Job, media io.Reader) (*Job, error) {
+func (c *Client) insertJob(ctx context.Context, job *bq.Job, media io.Reader) (j *Job, err error) {
+	ctx, span := startJobSpan(ctx, "cloud.google.com/go/bigquery.Jobs.Insert")
+	defer func() { endJobSpan(span, err) }()
+
 	call := c.bqs.Jobs.Insert(c.projectID, job).Context(ctx)
 	setClientHeader(call.Header())
 	if media != nil {
-		call.Media(media)
+		var opts []googleapi.MediaOption
+		if m, ok := media.(*uploadMedia); ok {
+			defer m.close()
+			opts = m.mediaOptions()
+			if m.progress != nil {
+				call.ProgressUpdater(func(current, _ int64) { m.progress(current) })
+			}
+		}
+		call.Media(media, opts...)
 	}
 	var res *bq.Job
-	var err error
+	attempts := 0
 	invoke := func() error {
+		attempts++
 		res, err = call.Do()
 		return err
 	}
@@ -128,36 +181,50 @@ func (c *Client) insertJob(ctx context.Context, job *bq.Job, media io.Reader) (*
 	// have to read the contents and keep it in memory, and that could be expensive.
 	// TODO(jba): Look into retrying if media != nil.
 	if job.JobReference != nil && media == nil {
-		// We deviate from default retries due to BigQuery wanting to retry structured internal job errors.
-		err = runWithRetryExplicit(ctx, invoke, jobRetryReasons)
+		err = c.jobRetryPolicy().run(ctx, invoke)
+		// An earlier attempt may have created the job even though its
+		// response was lost; in that case, fetch the job we created.
+		if attempts > 1 && isConflict(err) {
+			jr := job.JobReference
+			res, err = c.getJobInternal(ctx, jr.JobId, jr.Location, jr.ProjectId)
+		}
 	} else {
 		err = invoke()
 	}
 	if err != nil {
 		return nil, err
 	}
+	span.SetAttributes(jobRefTraceAttrs(res.JobReference)...)
+	span.SetAttributes(traceAttrAttempts.Int(attempts))
 	return bqToJob(res, c)
 }
 
 // runQuery invokes the optimized query path.
 // Due to differences in options it supports, it cannot be used for all existing
 // jobs.insert requests that are query jobs.
-func (c *Client) runQuery(ctx context.Context, queryRequest *bq.QueryRequest) (*bq.QueryResponse, error) {
+func (c *Client) runQuery(ctx context.Context, queryRequest *bq.QueryRequest) (res *bq.QueryResponse, err error) {
+	ctx, span := startJobSpan(ctx, "cloud.google.com/go/bigquery.Jobs.Query")
+	defer func() { endJobSpan(span, err) }()
+
 	call := c.bqs.Jobs.Query(c.projectID, queryRequest)
 	setClientHeader(call.Header())
 
-	var res *bq.QueryResponse
-	var err error
 	invoke := func() error {
 		res, err = call.Do()
 		return err
 	}
 
-	// We control request ID, so we can always runWithRetry.
-	err = runWithRetryExplicit(ctx, invoke, jobRetryReasons)
+	// We control request ID, so we can always retry.
+	err = c.jobRetryPolicy().run(ctx, invoke)
 	if err != nil {
 		return nil, err
 	}
+	span.SetAttributes(jobRefTraceAttrs(res.JobReference)...)
+	span.SetAttributes(
+		traceAttrCacheHit.Bool(res.CacheHit),
+		traceAttrBytesProcessed.Int64(res.TotalBytesProcessed),
+		traceAttrTotalRows.Int64(int64(res.TotalRows)),
+	)
 	return res, nil
 }
 
@@ -200,6 +267,88 @@ var (
 	jobRetryReasons     = []string{"backendError", "rateLimitExceeded", "internalError"}
 )
 
+// JobRetryPolicy controls how the client retries the insertion of jobs
+// (including queries) when BigQuery reports a transient failure.
+//
+// Jobs are always inserted with a job ID, so retrying an insert never
+// creates a duplicate job.  Jobs that upload data from an io.Reader
+// (see ReaderSource) are never retried.
+type JobRetryPolicy struct {
+	// Reasons lists the structured error reasons that are retried, such as
+	// "rateLimitExceeded". Responses with HTTP status 502 or 503 and
+	// transient network errors are always retried.
+	Reasons []string
+
+	// Backoff controls the delay between attempts. If the zero value, the
+	// backoff suggested by https://cloud.google.com/bigquery/sla is used.
+	Backoff gax.Backoff
+
+	// MaxAttempts limits the number of times a job insertion is attempted.
+	// If zero, attempts continue until the context is done. Set to 1 to
+	// disable retries.
+	MaxAttempts int
+}
+
+// DefaultJobRetryPolicy returns the policy used when Client.JobRetryPolicy is
+// nil. It retries job insertion until the context is done when BigQuery
+// reports a backend error, an internal error, or that a rate limit was
+// exceeded. Exceeded quotas are not retried, since they are often not
+// transient. Each call returns a new policy, which may be modified.
+func DefaultJobRetryPolicy() *JobRetryPolicy {
+	return &JobRetryPolicy{
+		Reasons: []string{
+			"backendError",
+			"internalError",
+			"jobBackendError",
+			"jobInternalError",
+			"rateLimitExceeded",
+			"jobRateLimitExceeded",
+		},
+	}
+}
+
+// defaultJobRetryPolicy is the policy returned by DefaultJobRetryPolicy,
+// shared by clients without a JobRetryPolicy.
+var defaultJobRetryPolicy = DefaultJobRetryPolicy()
+
+func (c *Client) jobRetryPolicy() *JobRetryPolicy {
+	if c.JobRetryPolicy != nil {
+		return c.JobRetryPolicy
+	}
+	return defaultJobRetryPolicy
+}
+
+func (p *JobRetryPolicy) run(ctx context.Context, call func() error) error {
+	backoff := p.Backoff
+	if backoff == (gax.Backoff{}) {
+		// These parameters match the suggestions in https://cloud.google.com/bigquery/sla.
+		backoff = gax.Backoff{
+			Initial:    1 * time.Second,
+			Max:        32 * time.Second,
+			Multiplier: 2,
+		}
+	}
+	attempts := 0
+	return cloudinternal.Retry(ctx, backoff, func() (stop bool, err error) {
+		attempts++
+		err = call()
+		if err == nil {
+			return true, nil
+		}
+		if p.MaxAttempts > 0 && attempts >= p.MaxAttempts {
+			return true, err
+		}
+		return !retryableError(err, p.Reasons), err
+	})
+}
+
+// isConflict reports whether err indicates that the resource being created
+// already exists.
+func isConflict(err error) bool {
+	var e *googleapi.Error
+	return errors.As(err, &e) && e.Code == http.StatusConflict
+}
+
 // This is the correct definition of retryable according to the BigQuery team. It
 // also considers 502 ("Bad Gateway") and 503 ("Service Unavailable") errors
 // retryable; these are returned by systems between the client and the BigQuery
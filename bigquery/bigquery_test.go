@@ -15,14 +15,19 @@
 package bigquery
 
 import (
+	"context"
 	"errors"
 	"io"
 	"net/http"
 	"net/url"
 	"testing"
+	"time"
 
+	gax "github.com/googleapis/gax-go/v2"
 	"golang.org/x/xerrors"
 	"google.golang.org/api/googleapi"
+	"google.golang.org/api/option"
+	"google.golang.org/grpc"
 )
 
 func TestRetryableErrors(t *testing.T) {
@@ -125,3 +130,113 @@ func TestRetryableErrors(t *testing.T) {
 		}
 	}
 }
+
+func TestJobRetryPolicy(t *testing.T) {
+	ctx := context.Background()
+	rateLimit := &googleapi.Error{
+		Code:   http.StatusForbidden,
+		Errors: []googleapi.ErrorItem{{Reason: "rateLimitExceeded"}},
+	}
+	quota := &googleapi.Error{
+		Code:   http.StatusForbidden,
+		Errors: []googleapi.ErrorItem{{Reason: "quotaExceeded"}},
+	}
+	invalid := &googleapi.Error{
+		Code:   http.StatusBadRequest,
+		Errors: []googleapi.ErrorItem{{Reason: "invalid"}},
+	}
+	fastBackoff := gax.Backoff{Initial: time.Millisecond, Max: time.Millisecond}
+	for _, tc := range []struct {
+		description  string
+		policy       *JobRetryPolicy
+		errs         []error
+		wantAttempts int
+		wantErr      error
+	}{
+		{
+			"retried until success",
+			&JobRetryPolicy{Reasons: DefaultJobRetryPolicy().Reasons, Backoff: fastBackoff},
+			[]error{rateLimit, rateLimit, nil},
+			3,
+			nil,
+		},
+		{
+			"quota not retried by default",
+			&JobRetryPolicy{Reasons: DefaultJobRetryPolicy().Reasons, Backoff: fastBackoff},
+			[]error{quota, nil},
+			1,
+			quota,
+		},
+		{
+			"non-retryable reason",
+			&JobRetryPolicy{Reasons: DefaultJobRetryPolicy().Reasons, Backoff: fastBackoff},
+			[]error{invalid, nil},
+			1,
+			invalid,
+		},
+		{
+			"reason not in policy",
+			&JobRetryPolicy{Reasons: []string{"backendError"}, Backoff: fastBackoff},
+			[]error{rateLimit, nil},
+			1,
+			rateLimit,
+		},
+		{
+			"max attempts",
+			&JobRetryPolicy{Reasons: DefaultJobRetryPolicy().Reasons, Backoff: fastBackoff, MaxAttempts: 2},
+			[]error{rateLimit, rateLimit, nil},
+			2,
+			rateLimit,
+		},
+	} {
+		attempts := 0
+		err := tc.policy.run(ctx, func() error {
+			err := tc.errs[attempts]
+			attempts++
+			return err
+		})
+		if attempts != tc.wantAttempts {
+			t.Errorf("%s: got %d attempts, want %d", tc.description, attempts, tc.wantAttempts)
+		}
+		if !errors.Is(err, tc.wantErr) {
+			t.Errorf("%s: got error %v, want %v", tc.description, err, tc.wantErr)
+		}
+	}
+}
+
+func TestIsConflict(t *testing.T) {
+	if !isConflict(&googleapi.Error{Code: http.StatusConflict}) {
+		t.Error("409 error: got false, want true")
+	}
+	if isConflict(&googleapi.Error{Code: http.StatusNotFound}) {
+		t.Error("404 error: got true, want false")
+	}
+	if isConflict(errors.New("blah")) {
+		t.Error("plain error: got true, want false")
+	}
+}
+
+func TestClientClose(t *testing.T) {
+	ctx := context.Background()
+	c := &Client{}
+	if err := c.Close(); err != nil {
+		t.Errorf("without read client: got %v, want nil", err)
+	}
+
+	conn, err := grpc.Dial("localhost:1", grpc.WithInsecure())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := c.EnableStorageReadClient(ctx, option.WithGRPCConn(conn)); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Close(); err != nil {
+		t.Errorf("with read client: got %v, want nil", err)
+	}
+	if c.readClient != nil {
+		t.Error("read client not dropped by Close")
+	}
+	if err := c.Close(); err != nil {
+		t.Errorf("second Close: got %v, want nil", err)
+	}
+}
@@ -0,0 +1,482 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bigquery
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	connection "cloud.google.com/go/bigquery/connection/apiv1"
+	"cloud.google.com/go/internal/detect"
+	"cloud.google.com/go/internal/optional"
+	"cloud.google.com/go/internal/trace"
+	"google.golang.org/api/iterator"
+	"google.golang.org/api/option"
+	connectionpb "google.golang.org/genproto/googleapis/cloud/bigquery/connection/v1"
+	"google.golang.org/protobuf/types/known/fieldmaskpb"
+)
+
+// ConnectionClient manages BigQuery connections. Connections hold the
+// configuration and credentials BigQuery uses to reach external data sources,
+// such as Cloud SQL databases queried with EXTERNAL_QUERY, Cloud Spanner
+// databases, or AWS resources used by BigQuery Omni.
+//
+// ConnectionClient is a thin layer over the BigQuery Connection API client in
+// cloud.google.com/go/bigquery/connection/apiv1.
+type ConnectionClient struct {
+	// Location, if set, will be used as the default location for connection
+	// handles created with Connection or listed with Connections when no
+	// location is given.
+	Location string
+
+	projectID string
+	cc        *connection.Client
+}
+
+// NewConnectionClient constructs a new ConnectionClient for the given project.
+//
+// If the project ID is set to DetectProjectID, NewConnectionClient will
+// attempt to detect the project ID from credentials.
+func NewConnectionClient(ctx context.Context, projectID string, opts ...option.ClientOption) (*ConnectionClient, error) {
+	cc, err := connection.NewClient(ctx, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("bigquery: constructing connection client: %w", err)
+	}
+	projectID, err = detect.ProjectID(ctx, projectID, "", opts...)
+	if err != nil {
+		cc.Close()
+		return nil, err
+	}
+	return &ConnectionClient{projectID: projectID, cc: cc}, nil
+}
+
+// Close closes any resources held by the client.
+func (c *ConnectionClient) Close() error {
+	return c.cc.Close()
+}
+
+// Project returns the project ID for this instance of the client.
+func (c *ConnectionClient) Project() string {
+	return c.projectID
+}
+
+// Connection is a reference to a BigQuery connection.
+type Connection struct {
+	ProjectID    string
+	Location     string
+	ConnectionID string
+	c            *ConnectionClient
+}
+
+// Connection creates a handle to a connection in the client's project.
+// If location is empty, the client's Location is used.
+// To determine if a connection exists, call Connection.Metadata.
+func (c *ConnectionClient) Connection(location, connectionID string) *Connection {
+	if location == "" {
+		location = c.Location
+	}
+	return &Connection{ProjectID: c.projectID, Location: location, ConnectionID: connectionID, c: c}
+}
+
+// Name returns the fully qualified resource name of the connection, in the
+// form projects/{project}/locations/{location}/connections/{connection}.
+// This is the form expected by RoutineMetadata and ExternalDataConfig.
+func (cn *Connection) Name() string {
+	return fmt.Sprintf("%s/connections/%s", locationName(cn.ProjectID, cn.Location), cn.ConnectionID)
+}
+
+// Identifier returns the connection ID in the dotted form
+// {project}.{location}.{connection} used by the EXTERNAL_QUERY function.
+func (cn *Connection) Identifier() string {
+	return fmt.Sprintf("%s.%s.%s", cn.ProjectID, cn.Location, cn.ConnectionID)
+}
+
+func locationName(projectID, location string) string {
+	return fmt.Sprintf("projects/%s/locations/%s", projectID, location)
+}
+
+// ConnectionMetadata contains information about a BigQuery connection.
+//
+// Exactly one of CloudSQL, AWS, CloudSpanner and CloudResource must be set
+// when creating a connection.
+type ConnectionMetadata struct {
+	// A descriptive name for the connection.
+	FriendlyName string
+	// A user-friendly description of the connection.
+	Description string
+
+	// Properties of a Cloud SQL connection.
+	CloudSQL *CloudSQLConnectionProperties
+	// Properties of an AWS connection.
+	AWS *AWSConnectionProperties
+	// Properties of a Cloud Spanner connection.
+	CloudSpanner *CloudSpannerConnectionProperties
+	// Properties of a Cloud Resource connection, used by BigLake tables and
+	// remote functions.
+	CloudResource *CloudResourceConnectionProperties
+
+	// The fully qualified resource name of the connection. Read-only.
+	Name string
+	// The time when the connection was created. Read-only.
+	CreationTime time.Time
+	// The time when the connection was last modified. Read-only.
+	LastModifiedTime time.Time
+	// Whether credentials have been set for the connection. Read-only.
+	HasCredential bool
+}
+
+// CloudSQLDatabaseType is the type of database behind a Cloud SQL connection.
+type CloudSQLDatabaseType string
+
+const (
+	// PostgresCloudSQLDatabase is a Cloud SQL for PostgreSQL database.
+	PostgresCloudSQLDatabase CloudSQLDatabaseType = "POSTGRES"
+	// MySQLCloudSQLDatabase is a Cloud SQL for MySQL database.
+	MySQLCloudSQLDatabase CloudSQLDatabaseType = "MYSQL"
+)
+
+// CloudSQLConnectionProperties describes a connection to a Cloud SQL instance.
+type CloudSQLConnectionProperties struct {
+	// Cloud SQL instance ID in the form project:location:instance.
+	InstanceID string
+	// Database name.
+	Database string
+	// Type of the Cloud SQL database.
+	Type CloudSQLDatabaseType
+	// Credential used to connect to the database. The credential is write-only
+	// and is never returned by the service.
+	Credential *CloudSQLCredential
+}
+
+// CloudSQLCredential holds the username and password for a Cloud SQL
+// connection.
+type CloudSQLCredential struct {
+	Username string
+	Password string
+}
+
+// CloudSpannerConnectionProperties describes a connection to a Cloud Spanner
+// database.
+type CloudSpannerConnectionProperties struct {
+	// Cloud Spanner database in the form
+	// projects/{project}/instances/{instance}/databases/{database}.
+	Database string
+	// If true, parallelism is used when reading from Cloud Spanner.
+	UseParallelism bool
+}
+
+// AWSConnectionProperties describes a connection to AWS. Exactly one of
+// AccessRole and CrossAccountRole should be set.
+type AWSConnectionProperties struct {
+	// Authentication using Google owned service account to assume into the
+	// customer's AWS IAM Role.
+	AccessRole *AWSAccessRole
+	// Authentication using Google owned AWS IAM user's access key to assume
+	// into the customer's AWS IAM Role.
+	//
+	// Deprecated: use AccessRole instead.
+	CrossAccountRole *AWSCrossAccountRole
+}
+
+// AWSAccessRole authenticates to AWS by assuming an IAM role with a Google
+// identity.
+type AWSAccessRole struct {
+	// The user's AWS IAM Role that trusts the Google-owned AWS IAM user.
+	IAMRoleID string
+	// A unique Google-owned and Google-generated identity for the connection.
+	// Read-only.
+	Identity string
+}
+
+// AWSCrossAccountRole authenticates to AWS by assuming an IAM role with a
+// Google-owned AWS IAM user.
+type AWSCrossAccountRole struct {
+	// The user's AWS IAM Role that trusts the Google-owned AWS IAM user.
+	IAMRoleID string
+	// The Google-owned AWS IAM user for the connection. Read-only.
+	IAMUserID string
+	// A Google-generated id for representing the connection's identity in
+	// AWS. Read-only.
+	ExternalID string
+}
+
+// CloudResourceConnectionProperties describes a connection to Google Cloud
+// resources, authenticated with a Google-managed service account.
+type CloudResourceConnectionProperties struct {
+	// The service account ID generated for the connection. Read-only.
+	ServiceAccountID string
+}
+
+// ConnectionMetadataToUpdate is used when updating a connection's metadata.
+// Only non-nil fields will be updated.
+type ConnectionMetadataToUpdate struct {
+	FriendlyName optional.String
+	Description  optional.String
+
+	// Replaces the Cloud SQL properties, including credentials.
+	CloudSQL *CloudSQLConnectionProperties
+	// Replaces the AWS properties.
+	AWS *AWSConnectionProperties
+	// Replaces the Cloud Spanner properties.
+	CloudSpanner *CloudSpannerConnectionProperties
+}
+
+// Create creates a connection in the BigQuery service. An error will be
+// returned if the connection already exists.
+func (cn *Connection) Create(ctx context.Context, md *ConnectionMetadata) (_ *ConnectionMetadata, err error) {
+	ctx = trace.StartSpan(ctx, "cloud.google.com/go/bigquery.Connection.Create")
+	defer func() { trace.EndSpan(ctx, err) }()
+
+	pb, err := md.toPB()
+	if err != nil {
+		return nil, err
+	}
+	res, err := cn.c.cc.CreateConnection(ctx, &connectionpb.CreateConnectionRequest{
+		Parent:       locationName(cn.ProjectID, cn.Location),
+		ConnectionId: cn.ConnectionID,
+		Connection:   pb,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return pbToConnectionMetadata(res), nil
+}
+
+// Metadata fetches the metadata for the connection.
+func (cn *Connection) Metadata(ctx context.Context) (md *ConnectionMetadata, err error) {
+	ctx = trace.StartSpan(ctx, "cloud.google.com/go/bigquery.Connection.Metadata")
+	defer func() { trace.EndSpan(ctx, err) }()
+
+	res, err := cn.c.cc.GetConnection(ctx, &connectionpb.GetConnectionRequest{Name: cn.Name()})
+	if err != nil {
+		return nil, err
+	}
+	return pbToConnectionMetadata(res), nil
+}
+
+// Update modifies specific connection metadata fields.
+func (cn *Connection) Update(ctx context.Context, cm ConnectionMetadataToUpdate) (md *ConnectionMetadata, err error) {
+	ctx = trace.StartSpan(ctx, "cloud.google.com/go/bigquery.Connection.Update")
+	defer func() { trace.EndSpan(ctx, err) }()
+
+	pb, mask, err := cm.toPB()
+	if err != nil {
+		return nil, err
+	}
+	res, err := cn.c.cc.UpdateConnection(ctx, &connectionpb.UpdateConnectionRequest{
+		Name:       cn.Name(),
+		Connection: pb,
+		UpdateMask: mask,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return pbToConnectionMetadata(res), nil
+}
+
+// Delete deletes the connection.
+func (cn *Connection) Delete(ctx context.Context) (err error) {
+	ctx = trace.StartSpan(ctx, "cloud.google.com/go/bigquery.Connection.Delete")
+	defer func() { trace.EndSpan(ctx, err) }()
+
+	return cn.c.cc.DeleteConnection(ctx, &connectionpb.DeleteConnectionRequest{Name: cn.Name()})
+}
+
+// Connections returns an iterator over the connections in the given location
+// of the client's project. If location is empty, the client's Location is used.
+func (c *ConnectionClient) Connections(ctx context.Context, location string) *ConnectionIterator {
+	if location == "" {
+		location = c.Location
+	}
+	return &ConnectionIterator{
+		it: c.cc.ListConnections(ctx, &connectionpb.ListConnectionsRequest{
+			Parent: locationName(c.projectID, location),
+		}),
+	}
+}
+
+// A ConnectionIterator is an iterator over the metadata of connections.
+type ConnectionIterator struct {
+	it *connection.ConnectionIterator
+}
+
+// Next returns the next result. Its second return value is iterator.Done if
+// there are no more results. Once Next returns Done, all subsequent calls will
+// return Done.
+func (it *ConnectionIterator) Next() (*ConnectionMetadata, error) {
+	pb, err := it.it.Next()
+	if err != nil {
+		return nil, err
+	}
+	return pbToConnectionMetadata(pb), nil
+}
+
+// PageInfo supports pagination. See the google.golang.org/api/iterator package for details.
+func (it *ConnectionIterator) PageInfo() *iterator.PageInfo { return it.it.PageInfo() }
+
+func (md *ConnectionMetadata) toPB() (*connectionpb.Connection, error) {
+	if md == nil {
+		return nil, errors.New("bigquery: ConnectionMetadata is required to create a connection")
+	}
+	pb := &connectionpb.Connection{
+		FriendlyName: md.FriendlyName,
+		Description:  md.Description,
+	}
+	n := 0
+	if md.CloudSQL != nil {
+		n++
+		pb.Properties = &connectionpb.Connection_CloudSql{CloudSql: md.CloudSQL.toPB()}
+	}
+	if md.AWS != nil {
+		n++
+		pb.Properties = &connectionpb.Connection_Aws{Aws: md.AWS.toPB()}
+	}
+	if md.CloudSpanner != nil {
+		n++
+		pb.Properties = &connectionpb.Connection_CloudSpanner{CloudSpanner: md.CloudSpanner.toPB()}
+	}
+	if md.CloudResource != nil {
+		n++
+		pb.Properties = &connectionpb.Connection_CloudResource{CloudResource: &connectionpb.CloudResourceProperties{}}
+	}
+	if n != 1 {
+		return nil, errors.New("bigquery: exactly one of CloudSQL, AWS, CloudSpanner or CloudResource must be set")
+	}
+	return pb, nil
+}
+
+func (cm *ConnectionMetadataToUpdate) toPB() (*connectionpb.Connection, *fieldmaskpb.FieldMask, error) {
+	pb := &connectionpb.Connection{}
+	mask := &fieldmaskpb.FieldMask{}
+	if cm.FriendlyName != nil {
+		pb.FriendlyName = optional.ToString(cm.FriendlyName)
+		mask.Paths = append(mask.Paths, "friendly_name")
+	}
+	if cm.Description != nil {
+		pb.Description = optional.ToString(cm.Description)
+		mask.Paths = append(mask.Paths, "description")
+	}
+	n := 0
+	if cm.CloudSQL != nil {
+		n++
+		pb.Properties = &connectionpb.Connection_CloudSql{CloudSql: cm.CloudSQL.toPB()}
+		mask.Paths = append(mask.Paths, "cloud_sql")
+	}
+	if cm.AWS != nil {
+		n++
+		pb.Properties = &connectionpb.Connection_Aws{Aws: cm.AWS.toPB()}
+		mask.Paths = append(mask.Paths, "aws")
+	}
+	if cm.CloudSpanner != nil {
+		n++
+		pb.Properties = &connectionpb.Connection_CloudSpanner{CloudSpanner: cm.CloudSpanner.toPB()}
+		mask.Paths = append(mask.Paths, "cloud_spanner")
+	}
+	if n > 1 {
+		return nil, nil, errors.New("bigquery: at most one of CloudSQL, AWS or CloudSpanner may be updated")
+	}
+	if len(mask.Paths) == 0 {
+		return nil, nil, errors.New("bigquery: no connection fields to update")
+	}
+	return pb, mask, nil
+}
+
+func (p *CloudSQLConnectionProperties) toPB() *connectionpb.CloudSqlProperties {
+	pb := &connectionpb.CloudSqlProperties{
+		InstanceId: p.InstanceID,
+		Database:   p.Database,
+	}
+	switch strings.ToUpper(string(p.Type)) {
+	case string(PostgresCloudSQLDatabase):
+		pb.Type = connectionpb.CloudSqlProperties_POSTGRES
+	case string(MySQLCloudSQLDatabase):
+		pb.Type = connectionpb.CloudSqlProperties_MYSQL
+	}
+	if p.Credential != nil {
+		pb.Credential = &connectionpb.CloudSqlCredential{
+			Username: p.Credential.Username,
+			Password: p.Credential.Password,
+		}
+	}
+	return pb
+}
+
+func (p *CloudSpannerConnectionProperties) toPB() *connectionpb.CloudSpannerProperties {
+	return &connectionpb.CloudSpannerProperties{
+		Database:       p.Database,
+		UseParallelism: p.UseParallelism,
+	}
+}
+
+func (p *AWSConnectionProperties) toPB() *connectionpb.AwsProperties {
+	pb := &connectionpb.AwsProperties{}
+	switch {
+	case p.AccessRole != nil:
+		pb.AuthenticationMethod = &connectionpb.AwsProperties_AccessRole{
+			AccessRole: &connectionpb.AwsAccessRole{IamRoleId: p.AccessRole.IAMRoleID},
+		}
+	case p.CrossAccountRole != nil:
+		pb.AuthenticationMethod = &connectionpb.AwsProperties_CrossAccountRole{
+			CrossAccountRole: &connectionpb.AwsCrossAccountRole{IamRoleId: p.CrossAccountRole.IAMRoleID},
+		}
+	}
+	return pb
+}
+
+func pbToConnectionMetadata(pb *connectionpb.Connection) *ConnectionMetadata {
+	md := &ConnectionMetadata{
+		Name:             pb.GetName(),
+		FriendlyName:     pb.GetFriendlyName(),
+		Description:      pb.GetDescription(),
+		CreationTime:     unixMillisToTime(pb.GetCreationTime()),
+		LastModifiedTime: unixMillisToTime(pb.GetLastModifiedTime()),
+		HasCredential:    pb.GetHasCredential(),
+	}
+	if p := pb.GetCloudSql(); p != nil {
+		md.CloudSQL = &CloudSQLConnectionProperties{
+			InstanceID: p.GetInstanceId(),
+			Database:   p.GetDatabase(),
+		}
+		if p.GetType() != connectionpb.CloudSqlProperties_DATABASE_TYPE_UNSPECIFIED {
+			md.CloudSQL.Type = CloudSQLDatabaseType(p.GetType().String())
+		}
+	}
+	if p := pb.GetAws(); p != nil {
+		md.AWS = &AWSConnectionProperties{}
+		if r := p.GetAccessRole(); r != nil {
+			md.AWS.AccessRole = &AWSAccessRole{IAMRoleID: r.GetIamRoleId(), Identity: r.GetIdentity()}
+		}
+		if r := p.GetCrossAccountRole(); r != nil {
+			md.AWS.CrossAccountRole = &AWSCrossAccountRole{
+				IAMRoleID:  r.GetIamRoleId(),
+				IAMUserID:  r.GetIamUserId(),
+				ExternalID: r.GetExternalId(),
+			}
+		}
+	}
+	if p := pb.GetCloudSpanner(); p != nil {
+		md.CloudSpanner = &CloudSpannerConnectionProperties{
+			Database:       p.GetDatabase(),
+			UseParallelism: p.GetUseParallelism(),
+		}
+	}
+	if p := pb.GetCloudResource(); p != nil {
+		md.CloudResource = &CloudResourceConnectionProperties{ServiceAccountID: p.GetServiceAccountId()}
+	}
+	return md
+}
@@ -0,0 +1,177 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bigquery
+
+import (
+	"testing"
+	"time"
+
+	"cloud.google.com/go/internal/testutil"
+	"github.com/google/go-cmp/cmp"
+	connectionpb "google.golang.org/genproto/googleapis/cloud/bigquery/connection/v1"
+	"google.golang.org/protobuf/testing/protocmp"
+	"google.golang.org/protobuf/types/known/fieldmaskpb"
+)
+
+func TestConnectionNames(t *testing.T) {
+	c := &ConnectionClient{projectID: "p", Location: "us"}
+	cn := c.Connection("", "conn")
+	if got, want := cn.Name(), "projects/p/locations/us/connections/conn"; got != want {
+		t.Errorf("Name: got %q, want %q", got, want)
+	}
+	if got, want := cn.Identifier(), "p.us.conn"; got != want {
+		t.Errorf("Identifier: got %q, want %q", got, want)
+	}
+	if got, want := c.Connection("eu", "conn").Location, "eu"; got != want {
+		t.Errorf("Location: got %q, want %q", got, want)
+	}
+}
+
+func TestConnectionMetadataToPB(t *testing.T) {
+	for _, test := range []struct {
+		in   *ConnectionMetadata
+		want *connectionpb.Connection
+	}{
+		{
+			&ConnectionMetadata{
+				FriendlyName: "f",
+				Description:  "d",
+				CloudSQL: &CloudSQLConnectionProperties{
+					InstanceID: "p:us:inst",
+					Database:   "db",
+					Type:       PostgresCloudSQLDatabase,
+					Credential: &CloudSQLCredential{Username: "u", Password: "pw"},
+				},
+			},
+			&connectionpb.Connection{
+				FriendlyName: "f",
+				Description:  "d",
+				Properties: &connectionpb.Connection_CloudSql{CloudSql: &connectionpb.CloudSqlProperties{
+					InstanceId: "p:us:inst",
+					Database:   "db",
+					Type:       connectionpb.CloudSqlProperties_POSTGRES,
+					Credential: &connectionpb.CloudSqlCredential{Username: "u", Password: "pw"},
+				}},
+			},
+		},
+		{
+			&ConnectionMetadata{
+				AWS: &AWSConnectionProperties{AccessRole: &AWSAccessRole{IAMRoleID: "role"}},
+			},
+			&connectionpb.Connection{
+				Properties: &connectionpb.Connection_Aws{Aws: &connectionpb.AwsProperties{
+					AuthenticationMethod: &connectionpb.AwsProperties_AccessRole{
+						AccessRole: &connectionpb.AwsAccessRole{IamRoleId: "role"},
+					},
+				}},
+			},
+		},
+		{
+			&ConnectionMetadata{
+				CloudSpanner: &CloudSpannerConnectionProperties{Database: "projects/p/instances/i/databases/d", UseParallelism: true},
+			},
+			&connectionpb.Connection{
+				Properties: &connectionpb.Connection_CloudSpanner{CloudSpanner: &connectionpb.CloudSpannerProperties{
+					Database:       "projects/p/instances/i/databases/d",
+					UseParallelism: true,
+				}},
+			},
+		},
+		{
+			&ConnectionMetadata{CloudResource: &CloudResourceConnectionProperties{}},
+			&connectionpb.Connection{
+				Properties: &connectionpb.Connection_CloudResource{CloudResource: &connectionpb.CloudResourceProperties{}},
+			},
+		},
+	} {
+		got, err := test.in.toPB()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if diff := cmp.Diff(got, test.want, protocmp.Transform()); diff != "" {
+			t.Errorf("toPB: -got, +want:\n%s", diff)
+		}
+	}
+
+	for _, md := range []*ConnectionMetadata{
+		nil,
+		{},
+		{CloudSQL: &CloudSQLConnectionProperties{}, CloudSpanner: &CloudSpannerConnectionProperties{}},
+	} {
+		if _, err := md.toPB(); err == nil {
+			t.Errorf("%+v: got nil error, want error", md)
+		}
+	}
+}
+
+func TestConnectionMetadataToUpdateToPB(t *testing.T) {
+	cm := ConnectionMetadataToUpdate{
+		Description: "new",
+		CloudSQL: &CloudSQLConnectionProperties{
+			Credential: &CloudSQLCredential{Username: "u", Password: "pw"},
+		},
+	}
+	gotPB, gotMask, err := cm.toPB()
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantPB := &connectionpb.Connection{
+		Description: "new",
+		Properties: &connectionpb.Connection_CloudSql{CloudSql: &connectionpb.CloudSqlProperties{
+			Credential: &connectionpb.CloudSqlCredential{Username: "u", Password: "pw"},
+		}},
+	}
+	if diff := cmp.Diff(gotPB, wantPB, protocmp.Transform()); diff != "" {
+		t.Errorf("connection: -got, +want:\n%s", diff)
+	}
+	wantMask := &fieldmaskpb.FieldMask{Paths: []string{"description", "cloud_sql"}}
+	if diff := cmp.Diff(gotMask, wantMask, protocmp.Transform()); diff != "" {
+		t.Errorf("mask: -got, +want:\n%s", diff)
+	}
+
+	if _, _, err := (&ConnectionMetadataToUpdate{}).toPB(); err == nil {
+		t.Error("empty update: got nil error, want error")
+	}
+}
+
+func TestPBToConnectionMetadata(t *testing.T) {
+	pb := &connectionpb.Connection{
+		Name:             "projects/p/locations/us/connections/c",
+		FriendlyName:     "f",
+		CreationTime:     1000,
+		LastModifiedTime: 2000,
+		HasCredential:    true,
+		Properties: &connectionpb.Connection_CloudSql{CloudSql: &connectionpb.CloudSqlProperties{
+			InstanceId: "p:us:inst",
+			Database:   "db",
+			Type:       connectionpb.CloudSqlProperties_MYSQL,
+		}},
+	}
+	want := &ConnectionMetadata{
+		Name:             "projects/p/locations/us/connections/c",
+		FriendlyName:     "f",
+		CreationTime:     time.Unix(1, 0),
+		LastModifiedTime: time.Unix(2, 0),
+		HasCredential:    true,
+		CloudSQL: &CloudSQLConnectionProperties{
+			InstanceID: "p:us:inst",
+			Database:   "db",
+			Type:       MySQLCloudSQLDatabase,
+		},
+	}
+	if diff := testutil.Diff(pbToConnectionMetadata(pb), want); diff != "" {
+		t.Errorf("-got, +want:\n%s", diff)
+	}
+}
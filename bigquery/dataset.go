@@ -24,6 +24,7 @@ import (
 	"cloud.google.com/go/internal/optional"
 	"cloud.google.com/go/internal/trace"
 	bq "google.golang.org/api/bigquery/v2"
+	"google.golang.org/api/googleapi"
 	"google.golang.org/api/iterator"
 )
 
@@ -357,7 +358,18 @@ func (d *Dataset) Tables(ctx context.Context) *TableIterator {
 }
 
 // A TableIterator is an iterator over Tables.
+//
+// To control the number of tables requested from BigQuery at a time, set
+// PageInfo().MaxSize before the first call to Next.
 type TableIterator struct {
+	// LabelFilter restricts the tables returned to those that have all of
+	// the given labels. A label whose value is empty matches tables that
+	// have the label with any value. The BigQuery API does not support
+	// filtering tables, so the filter is applied to each page of results
+	// as it is received; no additional requests are made.
+	// Set before the first call to Next.
+	LabelFilter map[string]string
+
 	ctx      context.Context
 	dataset  *Dataset
 	tables   []*Table
@@ -384,6 +396,7 @@ func (it *TableIterator) PageInfo() *iterator.PageInfo { return it.pageInfo }
 var listTables = func(it *TableIterator, pageSize int, pageToken string) (*bq.TableList, error) {
 	call := it.dataset.c.bqs.Tables.List(it.dataset.ProjectID, it.dataset.DatasetID).
 		PageToken(pageToken).
+		Fields(it.listFields()...).
 		Context(it.ctx)
 	setClientHeader(call.Header())
 	if pageSize > 0 {
@@ -403,11 +416,37 @@ func (it *TableIterator) fetch(pageSize int, pageToken string) (string, error) {
 		return "", err
 	}
 	for _, t := range res.Tables {
+		if !matchLabels(t.Labels, it.LabelFilter) {
+			continue
+		}
 		it.tables = append(it.tables, bqToTable(t.TableReference, it.dataset.c))
 	}
 	return res.NextPageToken, nil
 }
 
+// listFields returns the fields to request when listing tables. Only the
+// fields the iterator uses are requested, which keeps responses small for
+// datasets with many tables.
+func (it *TableIterator) listFields() []googleapi.Field {
+	fields := []googleapi.Field{"tables/tableReference", "nextPageToken"}
+	if len(it.LabelFilter) > 0 {
+		fields = append(fields, "tables/labels")
+	}
+	return fields
+}
+
+// matchLabels reports whether labels contains every label in filter. An empty
+// value in filter matches any value.
+func matchLabels(labels, filter map[string]string) bool {
+	for k, v := range filter {
+		lv, ok := labels[k]
+		if !ok || (v != "" && lv != v) {
+			return false
+		}
+	}
+	return true
+}
+
 func bqToTable(tr *bq.TableReference, c *Client) *Table {
 	if tr == nil {
 		return nil
@@ -616,6 +655,9 @@ func (c *Client) DatasetsInProject(ctx context.Context, projectID string) *Datas
 }
 
 // DatasetIterator iterates over the datasets in a project.
+//
+// To control the number of datasets requested from BigQuery at a time, set
+// PageInfo().MaxSize before the first call to Next.
 type DatasetIterator struct {
 	// ListHidden causes hidden datasets to be listed when set to true.
 	// Set before the first call to Next.
@@ -657,7 +699,9 @@ var listDatasets = func(it *DatasetIterator, pageSize int, pageToken string) (*b
 	call := it.c.bqs.Datasets.List(it.ProjectID).
 		Context(it.ctx).
 		PageToken(pageToken).
-		All(it.ListHidden)
+		All(it.ListHidden).
+		// Only the dataset references are used, so request nothing else.
+		Fields("datasets/datasetReference", "nextPageToken")
 	setClientHeader(call.Header())
 	if pageSize > 0 {
 		call.MaxResults(int64(pageSize))
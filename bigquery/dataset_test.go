@@ -26,6 +26,8 @@ import (
 	"github.com/google/go-cmp/cmp"
 	"github.com/google/go-cmp/cmp/cmpopts"
 	bq "google.golang.org/api/bigquery/v2"
+	"google.golang.org/api/googleapi"
+	"google.golang.org/api/iterator"
 	itest "google.golang.org/api/iterator/testing"
 )
 
@@ -583,3 +585,44 @@ func TestDatasetIdentifiers(t *testing.T) {
 		}
 	}
 }
+
+func TestTablesLabelFilter(t *testing.T) {
+	c := &Client{projectID: "p1"}
+	ref := func(id string) *bq.TableReference {
+		return &bq.TableReference{ProjectId: "p1", DatasetId: "d1", TableId: id}
+	}
+	lts := &listTablesStub{
+		expectedProject: "p1",
+		expectedDataset: "d1",
+		tables: []*bq.TableListTables{
+			{TableReference: ref("t1"), Labels: map[string]string{"env": "prod", "team": "a"}},
+			{TableReference: ref("t2"), Labels: map[string]string{"env": "dev", "team": "a"}},
+			{TableReference: ref("t3")},
+			{TableReference: ref("t4"), Labels: map[string]string{"env": "prod"}},
+			{TableReference: ref("t5"), Labels: map[string]string{"env": "prod", "team": "b"}},
+		},
+	}
+	old := listTables
+	listTables = lts.listTables // cannot use t.Parallel with this test
+	defer func() { listTables = old }()
+
+	it := c.Dataset("d1").Tables(context.Background())
+	it.LabelFilter = map[string]string{"env": "prod", "team": ""}
+	var got []string
+	for {
+		tbl, err := it.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		got = append(got, tbl.TableID)
+	}
+	if diff := testutil.Diff(got, []string{"t1", "t5"}); diff != "" {
+		t.Errorf("got=-, want=+:\n%s", diff)
+	}
+	if diff := testutil.Diff(it.listFields(), []googleapi.Field{"tables/tableReference", "nextPageToken", "tables/labels"}); diff != "" {
+		t.Errorf("listFields: got=-, want=+:\n%s", diff)
+	}
+}
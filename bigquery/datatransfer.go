@@ -0,0 +1,471 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bigquery
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	datatransfer "cloud.google.com/go/bigquery/datatransfer/apiv1"
+	"cloud.google.com/go/internal/detect"
+	"cloud.google.com/go/internal/trace"
+	"google.golang.org/api/iterator"
+	"google.golang.org/api/option"
+	datatransferpb "google.golang.org/genproto/googleapis/cloud/bigquery/datatransfer/v1"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/structpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+const (
+	scheduledQueryDataSourceID = "scheduled_query"
+	datasetCopyDataSourceID    = "cross_region_copy"
+)
+
+// DataTransferClient creates and monitors BigQuery Data Transfer Service
+// configurations for the two transfer types most closely tied to BigQuery
+// itself: scheduled queries and dataset copies.
+//
+// DataTransferClient is a thin layer over the client in
+// cloud.google.com/go/bigquery/datatransfer/apiv1, which should be used
+// directly for other data sources.
+type DataTransferClient struct {
+	// Location, if set, will be used as the default location for transfer
+	// configurations created by the client when no location is given.
+	Location string
+
+	projectID string
+	dtc       *datatransfer.Client
+}
+
+// NewDataTransferClient constructs a new DataTransferClient for the given
+// project.
+//
+// If the project ID is set to DetectProjectID, NewDataTransferClient will
+// attempt to detect the project ID from credentials.
+func NewDataTransferClient(ctx context.Context, projectID string, opts ...option.ClientOption) (*DataTransferClient, error) {
+	dtc, err := datatransfer.NewClient(ctx, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("bigquery: constructing data transfer client: %w", err)
+	}
+	projectID, err = detect.ProjectID(ctx, projectID, "", opts...)
+	if err != nil {
+		dtc.Close()
+		return nil, err
+	}
+	return &DataTransferClient{projectID: projectID, dtc: dtc}, nil
+}
+
+// Close closes any resources held by the client.
+func (c *DataTransferClient) Close() error {
+	return c.dtc.Close()
+}
+
+// Project returns the project ID for this instance of the client.
+func (c *DataTransferClient) Project() string {
+	return c.projectID
+}
+
+// ScheduledQueryConfig describes a query which is run on a schedule by the
+// Data Transfer Service.
+type ScheduledQueryConfig struct {
+	// User-specified display name for the scheduled query. Required.
+	DisplayName string
+
+	// The query to run. Required.
+	Query string
+
+	// The schedule, in the Data Transfer Service's English-like format, e.g.
+	// "every 24 hours" or "every mon,wed 09:00". If empty, the service's
+	// default schedule is used.
+	Schedule string
+
+	// The dataset that receives the results. If empty, the query must be a
+	// DDL or DML statement.
+	DestinationDatasetID string
+
+	// The destination table name, which may contain run-time parameters
+	// such as "results_{run_date}". Required if DestinationDatasetID is set.
+	DestinationTableNameTemplate string
+
+	// Specifies how the destination table is written. Defaults to
+	// WriteAppend.
+	WriteDisposition TableWriteDisposition
+
+	// If set, the destination table is partitioned by this column. Leave
+	// empty for ingestion-time partitioning.
+	PartitioningField string
+
+	// Optional service account email to run the query as, instead of the
+	// caller's credentials.
+	ServiceAccountName string
+
+	// Optional Pub/Sub topic, in the form projects/{project}/topics/{topic},
+	// which receives a notification after each run.
+	NotificationPubSubTopic string
+
+	// If true, the scheduled query is created in a disabled state.
+	Disabled bool
+}
+
+// DatasetCopyConfig describes a copy of all tables in a dataset, possibly in
+// another project or region, into a destination dataset.
+type DatasetCopyConfig struct {
+	// User-specified display name for the copy. Required.
+	DisplayName string
+
+	// The project and dataset to copy from. Required.
+	SourceProjectID string
+	SourceDatasetID string
+
+	// The dataset to copy into. It must already exist in the location the
+	// transfer is created in. Required.
+	DestinationDatasetID string
+
+	// If true, existing tables in the destination dataset are overwritten.
+	OverwriteDestinationTable bool
+
+	// The schedule, in the Data Transfer Service's English-like format. If
+	// empty, the service's default schedule is used.
+	Schedule string
+
+	// Optional Pub/Sub topic, in the form projects/{project}/topics/{topic},
+	// which receives a notification after each run.
+	NotificationPubSubTopic string
+
+	// If true, the copy is created in a disabled state.
+	Disabled bool
+}
+
+// TransferState is the state of a transfer configuration or run.
+type TransferState string
+
+const (
+	// TransferStatePending indicates the run is waiting to be scheduled.
+	TransferStatePending TransferState = "PENDING"
+	// TransferStateRunning indicates the run is in progress.
+	TransferStateRunning TransferState = "RUNNING"
+	// TransferStateSucceeded indicates the run completed successfully.
+	TransferStateSucceeded TransferState = "SUCCEEDED"
+	// TransferStateFailed indicates the run failed.
+	TransferStateFailed TransferState = "FAILED"
+	// TransferStateCancelled indicates the run was cancelled.
+	TransferStateCancelled TransferState = "CANCELLED"
+)
+
+// TransferConfig contains information about a Data Transfer Service
+// configuration.
+type TransferConfig struct {
+	// The resource name of the configuration, in the form
+	// projects/{project}/locations/{location}/transferConfigs/{config}.
+	Name string
+
+	DisplayName          string
+	DataSourceID         string
+	DestinationDatasetID string
+	Schedule             string
+	Disabled             bool
+
+	// Data source specific parameters, such as the query of a scheduled query.
+	Params map[string]interface{}
+
+	// The state of the most recently updated run.
+	State TransferState
+
+	// The region of the destination dataset.
+	DatasetRegion string
+
+	// The time of the next scheduled run.
+	NextRunTime time.Time
+
+	// The time the configuration was last modified.
+	UpdateTime time.Time
+}
+
+// TransferRun contains information about a single run of a transfer
+// configuration.
+type TransferRun struct {
+	// The resource name of the run.
+	Name string
+
+	State TransferState
+
+	// The time the run was scheduled for, and the logical time of the data
+	// it processes.
+	ScheduleTime time.Time
+	RunTime      time.Time
+
+	StartTime  time.Time
+	EndTime    time.Time
+	UpdateTime time.Time
+
+	DestinationDatasetID string
+
+	// Err holds the error of a failed run.
+	Err error
+}
+
+// Done reports whether the run has reached a terminal state.
+func (r *TransferRun) Done() bool {
+	switch r.State {
+	case TransferStateSucceeded, TransferStateFailed, TransferStateCancelled:
+		return true
+	}
+	return false
+}
+
+// CreateScheduledQuery creates a scheduled query in the given location. If
+// location is empty, the client's Location is used.
+func (c *DataTransferClient) CreateScheduledQuery(ctx context.Context, location string, sq *ScheduledQueryConfig) (_ *TransferConfig, err error) {
+	ctx = trace.StartSpan(ctx, "cloud.google.com/go/bigquery.DataTransferClient.CreateScheduledQuery")
+	defer func() { trace.EndSpan(ctx, err) }()
+
+	pb, err := sq.toPB()
+	if err != nil {
+		return nil, err
+	}
+	return c.createTransferConfig(ctx, location, pb, sq.ServiceAccountName)
+}
+
+// CreateDatasetCopy creates a dataset copy in the given location, which must
+// be the location of the destination dataset. If location is empty, the
+// client's Location is used.
+func (c *DataTransferClient) CreateDatasetCopy(ctx context.Context, location string, dc *DatasetCopyConfig) (_ *TransferConfig, err error) {
+	ctx = trace.StartSpan(ctx, "cloud.google.com/go/bigquery.DataTransferClient.CreateDatasetCopy")
+	defer func() { trace.EndSpan(ctx, err) }()
+
+	pb, err := dc.toPB()
+	if err != nil {
+		return nil, err
+	}
+	return c.createTransferConfig(ctx, location, pb, "")
+}
+
+func (c *DataTransferClient) createTransferConfig(ctx context.Context, location string, pb *datatransferpb.TransferConfig, serviceAccount string) (*TransferConfig, error) {
+	if location == "" {
+		location = c.Location
+	}
+	parent := fmt.Sprintf("projects/%s", c.projectID)
+	if location != "" {
+		parent = locationName(c.projectID, location)
+	}
+	res, err := c.dtc.CreateTransferConfig(ctx, &datatransferpb.CreateTransferConfigRequest{
+		Parent:             parent,
+		TransferConfig:     pb,
+		ServiceAccountName: serviceAccount,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return pbToTransferConfig(res), nil
+}
+
+// TransferConfig fetches the transfer configuration with the given resource
+// name.
+func (c *DataTransferClient) TransferConfig(ctx context.Context, name string) (_ *TransferConfig, err error) {
+	ctx = trace.StartSpan(ctx, "cloud.google.com/go/bigquery.DataTransferClient.TransferConfig")
+	defer func() { trace.EndSpan(ctx, err) }()
+
+	res, err := c.dtc.GetTransferConfig(ctx, &datatransferpb.GetTransferConfigRequest{Name: name})
+	if err != nil {
+		return nil, err
+	}
+	return pbToTransferConfig(res), nil
+}
+
+// DeleteTransferConfig deletes the transfer configuration with the given
+// resource name, along with its runs.
+func (c *DataTransferClient) DeleteTransferConfig(ctx context.Context, name string) (err error) {
+	ctx = trace.StartSpan(ctx, "cloud.google.com/go/bigquery.DataTransferClient.DeleteTransferConfig")
+	defer func() { trace.EndSpan(ctx, err) }()
+
+	return c.dtc.DeleteTransferConfig(ctx, &datatransferpb.DeleteTransferConfigRequest{Name: name})
+}
+
+// StartManualRun starts a run of the transfer configuration with the given
+// resource name immediately, processing data as of runTime. If runTime is
+// the zero time, the current time is used.
+func (c *DataTransferClient) StartManualRun(ctx context.Context, name string, runTime time.Time) (_ []*TransferRun, err error) {
+	ctx = trace.StartSpan(ctx, "cloud.google.com/go/bigquery.DataTransferClient.StartManualRun")
+	defer func() { trace.EndSpan(ctx, err) }()
+
+	if runTime.IsZero() {
+		runTime = time.Now()
+	}
+	res, err := c.dtc.StartManualTransferRuns(ctx, &datatransferpb.StartManualTransferRunsRequest{
+		Parent: name,
+		Time: &datatransferpb.StartManualTransferRunsRequest_RequestedRunTime{
+			RequestedRunTime: timestamppb.New(runTime),
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	var runs []*TransferRun
+	for _, r := range res.GetRuns() {
+		runs = append(runs, pbToTransferRun(r))
+	}
+	return runs, nil
+}
+
+// TransferRun fetches the run with the given resource name. It can be
+// polled until TransferRun.Done reports true to wait for a run to finish.
+func (c *DataTransferClient) TransferRun(ctx context.Context, name string) (_ *TransferRun, err error) {
+	ctx = trace.StartSpan(ctx, "cloud.google.com/go/bigquery.DataTransferClient.TransferRun")
+	defer func() { trace.EndSpan(ctx, err) }()
+
+	res, err := c.dtc.GetTransferRun(ctx, &datatransferpb.GetTransferRunRequest{Name: name})
+	if err != nil {
+		return nil, err
+	}
+	return pbToTransferRun(res), nil
+}
+
+// TransferRuns returns an iterator over the runs of the transfer
+// configuration with the given resource name, most recent first.
+func (c *DataTransferClient) TransferRuns(ctx context.Context, name string) *TransferRunIterator {
+	return &TransferRunIterator{
+		it: c.dtc.ListTransferRuns(ctx, &datatransferpb.ListTransferRunsRequest{Parent: name}),
+	}
+}
+
+// A TransferRunIterator is an iterator over TransferRuns.
+type TransferRunIterator struct {
+	it *datatransfer.TransferRunIterator
+}
+
+// Next returns the next result. Its second return value is iterator.Done if
+// there are no more results. Once Next returns Done, all subsequent calls will
+// return Done.
+func (it *TransferRunIterator) Next() (*TransferRun, error) {
+	pb, err := it.it.Next()
+	if err != nil {
+		return nil, err
+	}
+	return pbToTransferRun(pb), nil
+}
+
+// PageInfo supports pagination. See the google.golang.org/api/iterator package for details.
+func (it *TransferRunIterator) PageInfo() *iterator.PageInfo { return it.it.PageInfo() }
+
+func (sq *ScheduledQueryConfig) toPB() (*datatransferpb.TransferConfig, error) {
+	if sq == nil || sq.Query == "" {
+		return nil, errors.New("bigquery: ScheduledQueryConfig.Query is required")
+	}
+	params := map[string]interface{}{
+		"query": sq.Query,
+	}
+	pb := &datatransferpb.TransferConfig{
+		DisplayName:             sq.DisplayName,
+		DataSourceId:            scheduledQueryDataSourceID,
+		Schedule:                sq.Schedule,
+		Disabled:                sq.Disabled,
+		NotificationPubsubTopic: sq.NotificationPubSubTopic,
+	}
+	if sq.DestinationDatasetID != "" {
+		if sq.DestinationTableNameTemplate == "" {
+			return nil, errors.New("bigquery: ScheduledQueryConfig.DestinationTableNameTemplate is required with a destination dataset")
+		}
+		pb.Destination = &datatransferpb.TransferConfig_DestinationDatasetId{DestinationDatasetId: sq.DestinationDatasetID}
+		params["destination_table_name_template"] = sq.DestinationTableNameTemplate
+		wd := sq.WriteDisposition
+		if wd == "" {
+			wd = WriteAppend
+		}
+		params["write_disposition"] = string(wd)
+		params["partitioning_field"] = sq.PartitioningField
+	}
+	s, err := structpb.NewStruct(params)
+	if err != nil {
+		return nil, err
+	}
+	pb.Params = s
+	return pb, nil
+}
+
+func (dc *DatasetCopyConfig) toPB() (*datatransferpb.TransferConfig, error) {
+	if dc == nil || dc.SourceProjectID == "" || dc.SourceDatasetID == "" || dc.DestinationDatasetID == "" {
+		return nil, errors.New("bigquery: DatasetCopyConfig requires SourceProjectID, SourceDatasetID and DestinationDatasetID")
+	}
+	s, err := structpb.NewStruct(map[string]interface{}{
+		"source_project_id":           dc.SourceProjectID,
+		"source_dataset_id":           dc.SourceDatasetID,
+		"overwrite_destination_table": dc.OverwriteDestinationTable,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &datatransferpb.TransferConfig{
+		DisplayName:             dc.DisplayName,
+		DataSourceId:            datasetCopyDataSourceID,
+		Destination:             &datatransferpb.TransferConfig_DestinationDatasetId{DestinationDatasetId: dc.DestinationDatasetID},
+		Params:                  s,
+		Schedule:                dc.Schedule,
+		Disabled:                dc.Disabled,
+		NotificationPubsubTopic: dc.NotificationPubSubTopic,
+	}, nil
+}
+
+func pbToTransferState(s datatransferpb.TransferState) TransferState {
+	if s == datatransferpb.TransferState_TRANSFER_STATE_UNSPECIFIED {
+		return ""
+	}
+	return TransferState(s.String())
+}
+
+func pbToTime(ts *timestamppb.Timestamp) time.Time {
+	if ts == nil {
+		return time.Time{}
+	}
+	return ts.AsTime()
+}
+
+func pbToTransferConfig(pb *datatransferpb.TransferConfig) *TransferConfig {
+	tc := &TransferConfig{
+		Name:                 pb.GetName(),
+		DisplayName:          pb.GetDisplayName(),
+		DataSourceID:         pb.GetDataSourceId(),
+		DestinationDatasetID: pb.GetDestinationDatasetId(),
+		Schedule:             pb.GetSchedule(),
+		Disabled:             pb.GetDisabled(),
+		State:                pbToTransferState(pb.GetState()),
+		DatasetRegion:        pb.GetDatasetRegion(),
+		NextRunTime:          pbToTime(pb.GetNextRunTime()),
+		UpdateTime:           pbToTime(pb.GetUpdateTime()),
+	}
+	if pb.GetParams() != nil {
+		tc.Params = pb.GetParams().AsMap()
+	}
+	return tc
+}
+
+func pbToTransferRun(pb *datatransferpb.TransferRun) *TransferRun {
+	tr := &TransferRun{
+		Name:                 pb.GetName(),
+		State:                pbToTransferState(pb.GetState()),
+		ScheduleTime:         pbToTime(pb.GetScheduleTime()),
+		RunTime:              pbToTime(pb.GetRunTime()),
+		StartTime:            pbToTime(pb.GetStartTime()),
+		EndTime:              pbToTime(pb.GetEndTime()),
+		UpdateTime:           pbToTime(pb.GetUpdateTime()),
+		DestinationDatasetID: pb.GetDestinationDatasetId(),
+	}
+	if s := pb.GetErrorStatus(); s != nil && s.GetCode() != 0 {
+		tr.Err = status.ErrorProto(s)
+	}
+	return tr
+}
@@ -0,0 +1,136 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bigquery
+
+import (
+	"testing"
+	"time"
+
+	"cloud.google.com/go/internal/testutil"
+	"github.com/google/go-cmp/cmp"
+	datatransferpb "google.golang.org/genproto/googleapis/cloud/bigquery/datatransfer/v1"
+	statuspb "google.golang.org/genproto/googleapis/rpc/status"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/protobuf/testing/protocmp"
+	"google.golang.org/protobuf/types/known/structpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func TestScheduledQueryConfigToPB(t *testing.T) {
+	sq := &ScheduledQueryConfig{
+		DisplayName:                  "daily",
+		Query:                        "SELECT 1",
+		Schedule:                     "every 24 hours",
+		DestinationDatasetID:         "ds",
+		DestinationTableNameTemplate: "t_{run_date}",
+		PartitioningField:            "ts",
+		Disabled:                     true,
+	}
+	got, err := sq.toPB()
+	if err != nil {
+		t.Fatal(err)
+	}
+	params, _ := structpb.NewStruct(map[string]interface{}{
+		"query":                           "SELECT 1",
+		"destination_table_name_template": "t_{run_date}",
+		"write_disposition":               "WRITE_APPEND",
+		"partitioning_field":              "ts",
+	})
+	want := &datatransferpb.TransferConfig{
+		DisplayName:  "daily",
+		DataSourceId: "scheduled_query",
+		Destination:  &datatransferpb.TransferConfig_DestinationDatasetId{DestinationDatasetId: "ds"},
+		Params:       params,
+		Schedule:     "every 24 hours",
+		Disabled:     true,
+	}
+	if diff := cmp.Diff(got, want, protocmp.Transform()); diff != "" {
+		t.Errorf("-got, +want:\n%s", diff)
+	}
+
+	for _, bad := range []*ScheduledQueryConfig{
+		nil,
+		{DisplayName: "no query"},
+		{Query: "SELECT 1", DestinationDatasetID: "ds"},
+	} {
+		if _, err := bad.toPB(); err == nil {
+			t.Errorf("%+v: got nil error, want error", bad)
+		}
+	}
+}
+
+func TestDatasetCopyConfigToPB(t *testing.T) {
+	dc := &DatasetCopyConfig{
+		DisplayName:               "copy",
+		SourceProjectID:           "src-proj",
+		SourceDatasetID:           "src",
+		DestinationDatasetID:      "dst",
+		OverwriteDestinationTable: true,
+	}
+	got, err := dc.toPB()
+	if err != nil {
+		t.Fatal(err)
+	}
+	params, _ := structpb.NewStruct(map[string]interface{}{
+		"source_project_id":           "src-proj",
+		"source_dataset_id":           "src",
+		"overwrite_destination_table": true,
+	})
+	want := &datatransferpb.TransferConfig{
+		DisplayName:  "copy",
+		DataSourceId: "cross_region_copy",
+		Destination:  &datatransferpb.TransferConfig_DestinationDatasetId{DestinationDatasetId: "dst"},
+		Params:       params,
+	}
+	if diff := cmp.Diff(got, want, protocmp.Transform()); diff != "" {
+		t.Errorf("-got, +want:\n%s", diff)
+	}
+	if _, err := (&DatasetCopyConfig{SourceDatasetID: "src"}).toPB(); err == nil {
+		t.Error("got nil error for incomplete config, want error")
+	}
+}
+
+func TestPBToTransferRun(t *testing.T) {
+	ts := time.Date(2022, 6, 1, 12, 0, 0, 0, time.UTC)
+	got := pbToTransferRun(&datatransferpb.TransferRun{
+		Name:         "projects/p/locations/us/transferConfigs/c/runs/r",
+		State:        datatransferpb.TransferState_FAILED,
+		RunTime:      timestamppb.New(ts),
+		EndTime:      timestamppb.New(ts.Add(time.Minute)),
+		Destination:  &datatransferpb.TransferRun_DestinationDatasetId{DestinationDatasetId: "ds"},
+		ErrorStatus:  &statuspb.Status{Code: int32(codes.InvalidArgument), Message: "bad query"},
+		DataSourceId: "scheduled_query",
+	})
+	if !got.Done() {
+		t.Error("Done() = false for failed run, want true")
+	}
+	if got.Err == nil {
+		t.Fatal("got nil Err, want error")
+	}
+	got.Err = nil
+	want := &TransferRun{
+		Name:                 "projects/p/locations/us/transferConfigs/c/runs/r",
+		State:                TransferStateFailed,
+		RunTime:              ts,
+		EndTime:              ts.Add(time.Minute),
+		DestinationDatasetID: "ds",
+	}
+	if diff := testutil.Diff(got, want); diff != "" {
+		t.Errorf("-got, +want:\n%s", diff)
+	}
+	if (&TransferRun{State: TransferStateRunning}).Done() {
+		t.Error("Done() = true for running run, want false")
+	}
+}
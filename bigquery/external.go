@@ -34,6 +34,12 @@ const (
 	Bigtable        DataFormat = "BIGTABLE"
 	Parquet         DataFormat = "PARQUET"
 	ORC             DataFormat = "ORC"
+	// For external tables backed by Apache Iceberg. The source URI must point
+	// to the Iceberg metadata JSON file, and a ConnectionID is required.
+	Iceberg DataFormat = "ICEBERG"
+	// For external tables backed by Delta Lake. The source URI must point to
+	// the root of the Delta table, and a ConnectionID is required.
+	DeltaLake DataFormat = "DELTA_LAKE"
 	// For BQ ML Models, TensorFlow Saved Model format.
 	TFSavedModel DataFormat = "ML_TF_SAVED_MODEL"
 	// For BQ ML Models, xgBoost Booster format.
@@ -106,8 +112,12 @@ type ExternalDataConfig struct {
 	DecimalTargetTypes []DecimalTargetType
 
 	// ConnectionID associates an external data configuration with a connection ID.
-	// Connections are managed through the BigQuery Connection API:
-	// https://pkg.go.dev/cloud.google.com/go/bigquery/connection/apiv1
+	// Connections are managed with a ConnectionClient, and the value may be
+	// either the fully qualified name returned by Connection.Name or the
+	// dotted form returned by Connection.Identifier.
+	//
+	// A connection is required for BigLake tables, including the Iceberg and
+	// DeltaLake formats.
 	ConnectionID string
 }
 
@@ -43,6 +43,17 @@ func TestExternalDataConfig(t *testing.T) {
 			},
 			ConnectionID: "connection",
 		},
+		{
+			SourceFormat: Iceberg,
+			SourceURIs:   []string{"gs://bucket/table/metadata/v1.metadata.json"},
+			ConnectionID: "projects/p/locations/us/connections/c",
+		},
+		{
+			SourceFormat: DeltaLake,
+			SourceURIs:   []string{"gs://bucket/delta_table"},
+			AutoDetect:   true,
+			ConnectionID: "p.us.c",
+		},
 		{
 			SourceFormat: GoogleSheets,
 			Options: &GoogleSheetsOptions{
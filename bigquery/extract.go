@@ -42,6 +42,7 @@ type ExtractConfig struct {
 	Labels map[string]string
 
 	// For Avro-based extracts, controls whether logical type annotations are generated.
+	// It has no effect on other destination formats.
 	//
 	// Example:  With this enabled, writing a BigQuery TIMESTAMP column will result in
 	// an integer column annotated with the appropriate timestamp-micros/millis annotation
@@ -131,6 +131,22 @@ func TestExtract(t *testing.T) {
 				return j
 			}(),
 		},
+		{
+			dst: func() *GCSReference {
+				g := NewGCSReference("gs://bucket/a-*.parquet", "gs://bucket/b-*.parquet")
+				g.DestinationFormat = Parquet
+				g.Compression = Zstd
+				return g
+			}(),
+			src: c.Dataset("dataset-id").Table("table-id"),
+			want: func() *bq.Job {
+				j := defaultExtractJob()
+				j.Configuration.Extract.DestinationUris = []string{"gs://bucket/a-*.parquet", "gs://bucket/b-*.parquet"}
+				j.Configuration.Extract.DestinationFormat = "PARQUET"
+				j.Configuration.Extract.Compression = "ZSTD"
+				return j
+			}(),
+		},
 	}
 
 	for i, tc := range testCases {
@@ -15,9 +15,12 @@
 package bigquery
 
 import (
+	"compress/gzip"
 	"io"
+	"sync"
 
 	bq "google.golang.org/api/bigquery/v2"
+	"google.golang.org/api/googleapi"
 )
 
 // A ReaderSource is a source for a load operation that gets
@@ -29,18 +32,108 @@ import (
 type ReaderSource struct {
 	r io.Reader
 	FileConfig
+
+	// ChunkSize controls the maximum number of bytes of the data that will be
+	// sent in a single request. Larger chunks use more memory, while smaller
+	// chunks make more requests. Each chunk is retried on transient errors,
+	// so an interrupted upload only resends the current chunk.
+	//
+	// If ChunkSize is set to zero, chunking is disabled and the data is
+	// uploaded in a single request, without retries.
+	//
+	// NewReaderSource sets ChunkSize to googleapi.DefaultUploadChunkSize.
+	ChunkSize int
+
+	// ProgressFunc can be used to monitor the progress of a large upload.
+	// If not nil, it is called after each chunk is uploaded, with the total
+	// number of bytes uploaded so far. If Compress is true, the count is of
+	// compressed bytes.
+	//
+	// ProgressFunc is not called if ChunkSize is zero.
+	ProgressFunc func(int64)
+
+	// Compress, if true, causes the data to be compressed with gzip as it is
+	// uploaded, which reduces the amount of data sent for large CSV and JSON
+	// sources. The data read from the io.Reader must not already be
+	// compressed. Compression is not supported for other source formats.
+	Compress bool
 }
 
 // NewReaderSource creates a ReaderSource from an io.Reader. You may
 // optionally configure properties on the ReaderSource that describe the
 // data being read, before passing it to Table.LoaderFrom.
 func NewReaderSource(r io.Reader) *ReaderSource {
-	return &ReaderSource{r: r}
+	return &ReaderSource{r: r, ChunkSize: googleapi.DefaultUploadChunkSize}
 }
 
 func (r *ReaderSource) populateLoadConfig(lc *bq.JobConfigurationLoad) io.Reader {
 	r.FileConfig.populateLoadConfig(lc)
-	return r.r
+	if r.r == nil {
+		return nil
+	}
+	m := &uploadMedia{
+		Reader:    r.r,
+		chunkSize: r.ChunkSize,
+		progress:  r.ProgressFunc,
+	}
+	if r.Compress {
+		m.Reader = &gzipReader{src: r.r}
+	}
+	return m
+}
+
+// uploadMedia is the media of a load job, along with options for uploading
+// it.
+type uploadMedia struct {
+	io.Reader
+	chunkSize int
+	progress  func(int64)
+}
+
+// mediaOptions returns the options for uploading m.
+func (m *uploadMedia) mediaOptions() []googleapi.MediaOption {
+	return []googleapi.MediaOption{googleapi.ChunkSize(m.chunkSize)}
+}
+
+// close releases any resources held by m once the upload has finished.
+func (m *uploadMedia) close() {
+	if gr, ok := m.Reader.(*gzipReader); ok {
+		gr.close()
+	}
+}
+
+// gzipReader streams the gzip-compressed contents of src. Compression starts
+// with the first call to Read.
+type gzipReader struct {
+	src  io.Reader
+	once sync.Once
+	pr   *io.PipeReader
+}
+
+func (g *gzipReader) start() {
+	pr, pw := io.Pipe()
+	g.pr = pr
+	go func() {
+		zw := gzip.NewWriter(pw)
+		_, err := io.Copy(zw, g.src)
+		if cerr := zw.Close(); err == nil {
+			err = cerr
+		}
+		pw.CloseWithError(err)
+	}()
+}
+
+func (g *gzipReader) Read(p []byte) (int, error) {
+	g.once.Do(g.start)
+	return g.pr.Read(p)
+}
+
+// close stops compression, if it was started and has not completed.
+func (g *gzipReader) close() {
+	g.once.Do(func() {})
+	if g.pr != nil {
+		g.pr.CloseWithError(io.ErrClosedPipe)
+	}
 }
 
 // FileConfig contains configuration options that pertain to files, typically
@@ -15,10 +15,15 @@
 package bigquery
 
 import (
+	"bytes"
+	"compress/gzip"
+	"io/ioutil"
+	"strings"
 	"testing"
 
 	"cloud.google.com/go/internal/testutil"
 	bq "google.golang.org/api/bigquery/v2"
+	"google.golang.org/api/googleapi"
 )
 
 var (
@@ -187,3 +192,72 @@ func TestFileConfigPopulateExternalDataConfig(t *testing.T) {
 	}
 
 }
+
+func TestReaderSourceMedia(t *testing.T) {
+	if got := NewReaderSource(nil).populateLoadConfig(&bq.JobConfigurationLoad{}); got != nil {
+		t.Errorf("nil reader: got media %v, want nil", got)
+	}
+
+	rs := NewReaderSource(strings.NewReader("a,b\n"))
+	if rs.ChunkSize != googleapi.DefaultUploadChunkSize {
+		t.Errorf("ChunkSize: got %d, want %d", rs.ChunkSize, googleapi.DefaultUploadChunkSize)
+	}
+	rs.ChunkSize = 1 << 20
+	var progress int64
+	rs.ProgressFunc = func(n int64) { progress = n }
+	m, ok := rs.populateLoadConfig(&bq.JobConfigurationLoad{}).(*uploadMedia)
+	if !ok {
+		t.Fatal("media is not *uploadMedia")
+	}
+	if m.chunkSize != 1<<20 {
+		t.Errorf("chunkSize: got %d, want %d", m.chunkSize, 1<<20)
+	}
+	m.progress(42)
+	if progress != 42 {
+		t.Errorf("progress: got %d, want 42", progress)
+	}
+	if _, ok := m.Reader.(*gzipReader); ok {
+		t.Error("got gzipReader without Compress")
+	}
+}
+
+func TestReaderSourceCompress(t *testing.T) {
+	data := strings.Repeat("some,csv,data\n", 1000)
+	rs := NewReaderSource(strings.NewReader(data))
+	rs.Compress = true
+	m := rs.populateLoadConfig(&bq.JobConfigurationLoad{}).(*uploadMedia)
+	defer m.close()
+	compressed, err := ioutil.ReadAll(m)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(compressed) >= len(data) {
+		t.Errorf("compressed size %d is not smaller than %d", len(compressed), len(data))
+	}
+	zr, err := gzip.NewReader(bytes.NewReader(compressed))
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := ioutil.ReadAll(zr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != data {
+		t.Error("decompressed data does not match input")
+	}
+}
+
+func TestGzipReaderClose(t *testing.T) {
+	// Closing before the data is consumed stops the compressing goroutine.
+	g := &gzipReader{src: strings.NewReader(strings.Repeat("x", 1<<20))}
+	buf := make([]byte, 10)
+	if _, err := g.Read(buf); err != nil {
+		t.Fatal(err)
+	}
+	g.close()
+	if _, err := g.Read(buf); err == nil {
+		t.Error("Read after close: got nil error, want error")
+	}
+	// Closing an unread reader is a no-op.
+	(&gzipReader{src: strings.NewReader("")}).close()
+}
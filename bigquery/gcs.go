@@ -24,13 +24,18 @@ import (
 // an input or output to a BigQuery operation.
 type GCSReference struct {
 	// URIs refer to Google Cloud Storage objects.
+	//
+	// When used as an extract destination, multiple URIs may be supplied and
+	// each may contain a single '*' wildcard, which BigQuery replaces with a
+	// zero-padded file number to shard large exports.
 	URIs []string
 
 	FileConfig
 
 	// DestinationFormat is the format to use when writing exported files.
-	// Allowed values are: CSV, Avro, JSON.  The default is CSV.
+	// Allowed values are: CSV, Avro, JSON, Parquet.  The default is CSV.
 	// CSV is not supported for tables with nested or repeated fields.
+	// Model extracts use TFSavedModel or XGBoostBooster.
 	DestinationFormat DataFormat
 
 	// Compression specifies the type of compression to apply when writing data
@@ -38,6 +43,7 @@ type GCSReference struct {
 	// source with CSV or JSON SourceFormat. Default is None.
 	//
 	// Avro files allow additional compression types: DEFLATE and SNAPPY.
+	// Parquet files allow GZIP, SNAPPY and ZSTD.
 	Compression Compression
 }
 
@@ -61,8 +67,10 @@ const (
 	Gzip Compression = "GZIP"
 	// Deflate specifies DEFLATE compression for Avro files.
 	Deflate Compression = "DEFLATE"
-	// Snappy specifies SNAPPY compression for Avro files.
+	// Snappy specifies SNAPPY compression for Avro and Parquet files.
 	Snappy Compression = "SNAPPY"
+	// Zstd specifies ZSTD compression for Parquet files.
+	Zstd Compression = "ZSTD"
 )
 
 func (gcs *GCSReference) populateLoadConfig(lc *bq.JobConfigurationLoad) io.Reader {
@@ -0,0 +1,348 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bigquery
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Geography is a Go type for representing BigQuery GEOGRAPHY values.
+// The value is held in Well-Known Text (WKT) format, which is the format
+// BigQuery uses when returning GEOGRAPHY values in query results.
+//
+// Geography values can be used as query parameters, as struct fields when
+// reading rows with RowIterator.Next, and as struct fields when uploading rows
+// with an Inserter. For nullable GEOGRAPHY columns, use NullGeography.
+//
+// More information about BigQuery GEOGRAPHY types can be found at:
+// https://cloud.google.com/bigquery/docs/reference/standard-sql/data-types#geography_type
+type Geography struct {
+	// WKT is the Well-Known Text representation of the geography,
+	// e.g. "POINT(-122.350220 47.649154)".
+	WKT string
+}
+
+// String returns the WKT representation of the geography.
+func (g Geography) String() string {
+	return g.WKT
+}
+
+// GeographyFromGeoJSON constructs a Geography from a GeoJSON geometry object
+// (RFC 7946). Feature and FeatureCollection objects are not supported; pass
+// the geometry member of a feature instead.
+func GeographyFromGeoJSON(data []byte) (Geography, error) {
+	var g geoJSONGeometry
+	if err := json.Unmarshal(data, &g); err != nil {
+		return Geography{}, fmt.Errorf("bigquery: invalid GeoJSON: %v", err)
+	}
+	var buf bytes.Buffer
+	if err := writeWKT(&buf, &g); err != nil {
+		return Geography{}, err
+	}
+	return Geography{WKT: buf.String()}, nil
+}
+
+// GeoJSON returns the GeoJSON geometry object (RFC 7946) equivalent to the
+// geography's WKT representation.
+func (g Geography) GeoJSON() ([]byte, error) {
+	p := &wktParser{s: g.WKT}
+	geom, err := p.parseGeometry()
+	if err != nil {
+		return nil, err
+	}
+	if tok := p.next(); tok != "" {
+		return nil, fmt.Errorf("bigquery: unexpected %q after WKT geometry", tok)
+	}
+	return json.Marshal(geom)
+}
+
+// geoJSONGeometry is the JSON representation of a GeoJSON geometry object.
+type geoJSONGeometry struct {
+	Type        string             `json:"type"`
+	Coordinates interface{}        `json:"coordinates,omitempty"`
+	Geometries  []*geoJSONGeometry `json:"geometries,omitempty"`
+}
+
+// MarshalJSON emits the geometries member for collections, even when empty,
+// and the coordinates member for all other geometry types.
+func (g *geoJSONGeometry) MarshalJSON() ([]byte, error) {
+	if g.Type == "GeometryCollection" {
+		geoms := g.Geometries
+		if geoms == nil {
+			geoms = []*geoJSONGeometry{}
+		}
+		return json.Marshal(struct {
+			Type       string             `json:"type"`
+			Geometries []*geoJSONGeometry `json:"geometries"`
+		}{g.Type, geoms})
+	}
+	return json.Marshal(struct {
+		Type        string      `json:"type"`
+		Coordinates interface{} `json:"coordinates"`
+	}{g.Type, g.Coordinates})
+}
+
+// wktTypes maps upper-cased WKT geometry tags to GeoJSON geometry types.
+var wktTypes = map[string]string{
+	"POINT":              "Point",
+	"LINESTRING":         "LineString",
+	"POLYGON":            "Polygon",
+	"MULTIPOINT":         "MultiPoint",
+	"MULTILINESTRING":    "MultiLineString",
+	"MULTIPOLYGON":       "MultiPolygon",
+	"GEOMETRYCOLLECTION": "GeometryCollection",
+}
+
+// coordinateDepth is the nesting depth of the coordinates member for each
+// GeoJSON geometry type, where zero is a single position.
+var coordinateDepth = map[string]int{
+	"Point":           0,
+	"LineString":      1,
+	"MultiPoint":      1,
+	"Polygon":         2,
+	"MultiLineString": 2,
+	"MultiPolygon":    3,
+}
+
+// wktParser is a minimal recursive-descent parser for the 2D subset of WKT
+// produced and accepted by BigQuery.
+type wktParser struct {
+	s   string
+	pos int
+}
+
+// next consumes and returns the next token, or "" at the end of input.
+func (p *wktParser) next() string {
+	tok := p.peek()
+	p.pos += len(tok)
+	return tok
+}
+
+// peek returns the next token without consuming it.
+func (p *wktParser) peek() string {
+	for p.pos < len(p.s) && unicode.IsSpace(rune(p.s[p.pos])) {
+		p.pos++
+	}
+	if p.pos >= len(p.s) {
+		return ""
+	}
+	switch p.s[p.pos] {
+	case '(', ')', ',':
+		return p.s[p.pos : p.pos+1]
+	}
+	end := p.pos
+	for end < len(p.s) && !unicode.IsSpace(rune(p.s[end])) && !strings.ContainsRune("(),", rune(p.s[end])) {
+		end++
+	}
+	return p.s[p.pos:end]
+}
+
+func (p *wktParser) expect(want string) error {
+	if tok := p.next(); tok != want {
+		return fmt.Errorf("bigquery: invalid WKT %q: expected %q, got %q", p.s, want, tok)
+	}
+	return nil
+}
+
+func (p *wktParser) parseGeometry() (*geoJSONGeometry, error) {
+	tag := strings.ToUpper(p.next())
+	typ, ok := wktTypes[tag]
+	if !ok {
+		return nil, fmt.Errorf("bigquery: invalid WKT %q: unsupported geometry type %q", p.s, tag)
+	}
+	g := &geoJSONGeometry{Type: typ}
+	if strings.ToUpper(p.peek()) == "EMPTY" {
+		p.next()
+		if typ != "GeometryCollection" {
+			g.Coordinates = []interface{}{}
+		}
+		return g, nil
+	}
+	if typ == "GeometryCollection" {
+		if err := p.expect("("); err != nil {
+			return nil, err
+		}
+		for {
+			child, err := p.parseGeometry()
+			if err != nil {
+				return nil, err
+			}
+			g.Geometries = append(g.Geometries, child)
+			if p.peek() != "," {
+				break
+			}
+			p.next()
+		}
+		return g, p.expect(")")
+	}
+	coords, err := p.parseCoordinates(coordinateDepth[typ], typ == "MultiPoint")
+	if err != nil {
+		return nil, err
+	}
+	if typ == "Point" {
+		// POINT wraps its single position in parentheses.
+		pts := coords.([]interface{})
+		if len(pts) != 1 {
+			return nil, fmt.Errorf("bigquery: invalid WKT %q: POINT must have exactly one position", p.s)
+		}
+		coords = pts[0]
+	}
+	g.Coordinates = coords
+	return g, nil
+}
+
+// parseCoordinates parses a parenthesized list of positions nested depth
+// levels deep. A depth of zero still consumes one set of parentheses, which
+// is the shape of a POINT body. If optionalParens is true, positions in the
+// innermost list may themselves be parenthesized, as permitted by MULTIPOINT.
+func (p *wktParser) parseCoordinates(depth int, optionalParens bool) (interface{}, error) {
+	if err := p.expect("("); err != nil {
+		return nil, err
+	}
+	var list []interface{}
+	for {
+		var (
+			item interface{}
+			err  error
+		)
+		switch {
+		case depth > 1:
+			item, err = p.parseCoordinates(depth-1, false)
+		case optionalParens && p.peek() == "(":
+			p.next()
+			if item, err = p.parsePosition(); err == nil {
+				err = p.expect(")")
+			}
+		default:
+			item, err = p.parsePosition()
+		}
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, item)
+		if p.peek() != "," {
+			break
+		}
+		p.next()
+	}
+	return list, p.expect(")")
+}
+
+func (p *wktParser) parsePosition() ([]float64, error) {
+	var pos []float64
+	for {
+		tok := p.peek()
+		if tok == "" || tok == "," || tok == ")" || tok == "(" {
+			break
+		}
+		f, err := strconv.ParseFloat(p.next(), 64)
+		if err != nil {
+			return nil, fmt.Errorf("bigquery: invalid WKT %q: %v", p.s, err)
+		}
+		pos = append(pos, f)
+	}
+	if len(pos) != 2 {
+		return nil, fmt.Errorf("bigquery: invalid WKT %q: positions must have two coordinates", p.s)
+	}
+	return pos, nil
+}
+
+func writeWKT(buf *bytes.Buffer, g *geoJSONGeometry) error {
+	var tag string
+	for k, v := range wktTypes {
+		if v == g.Type {
+			tag = k
+		}
+	}
+	if tag == "" {
+		return fmt.Errorf("bigquery: unsupported GeoJSON geometry type %q", g.Type)
+	}
+	buf.WriteString(tag)
+	if g.Type == "GeometryCollection" {
+		if len(g.Geometries) == 0 {
+			buf.WriteString(" EMPTY")
+			return nil
+		}
+		buf.WriteByte('(')
+		for i, child := range g.Geometries {
+			if i > 0 {
+				buf.WriteString(", ")
+			}
+			if child == nil {
+				return errors.New("bigquery: invalid GeoJSON: null geometry in collection")
+			}
+			if err := writeWKT(buf, child); err != nil {
+				return err
+			}
+		}
+		buf.WriteByte(')')
+		return nil
+	}
+	coords, ok := g.Coordinates.([]interface{})
+	if !ok {
+		return fmt.Errorf("bigquery: invalid GeoJSON: %s coordinates must be an array", g.Type)
+	}
+	if len(coords) == 0 {
+		buf.WriteString(" EMPTY")
+		return nil
+	}
+	depth := coordinateDepth[g.Type]
+	if g.Type == "Point" {
+		// Wrap the position so that POINT gets its parentheses.
+		coords = []interface{}{coords}
+		depth = 1
+	}
+	return writeWKTCoordinates(buf, coords, depth)
+}
+
+func writeWKTCoordinates(buf *bytes.Buffer, list []interface{}, depth int) error {
+	buf.WriteByte('(')
+	for i, item := range list {
+		if i > 0 {
+			buf.WriteString(", ")
+		}
+		inner, ok := item.([]interface{})
+		if !ok {
+			return errors.New("bigquery: invalid GeoJSON: coordinates nested too shallowly")
+		}
+		if depth > 1 {
+			if err := writeWKTCoordinates(buf, inner, depth-1); err != nil {
+				return err
+			}
+			continue
+		}
+		if len(inner) != 2 {
+			return errors.New("bigquery: invalid GeoJSON: positions must have two coordinates")
+		}
+		for j, c := range inner {
+			f, ok := c.(float64)
+			if !ok {
+				return errors.New("bigquery: invalid GeoJSON: coordinates nested too deeply")
+			}
+			if j > 0 {
+				buf.WriteByte(' ')
+			}
+			buf.WriteString(strconv.FormatFloat(f, 'f', -1, 64))
+		}
+	}
+	buf.WriteByte(')')
+	return nil
+}
@@ -0,0 +1,164 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bigquery
+
+import (
+	"testing"
+
+	"cloud.google.com/go/internal/testutil"
+)
+
+func TestGeographyGeoJSON(t *testing.T) {
+	testcases := []struct {
+		wkt     string
+		geoJSON string
+	}{
+		{
+			wkt:     "POINT(-122.35022 47.649154)",
+			geoJSON: `{"type":"Point","coordinates":[-122.35022,47.649154]}`,
+		},
+		{
+			wkt:     "POINT EMPTY",
+			geoJSON: `{"type":"Point","coordinates":[]}`,
+		},
+		{
+			wkt:     "LINESTRING(1 2, 3 4, 5 6)",
+			geoJSON: `{"type":"LineString","coordinates":[[1,2],[3,4],[5,6]]}`,
+		},
+		{
+			wkt:     "POLYGON((0 0, 10 0, 10 10, 0 0), (1 1, 2 1, 2 2, 1 1))",
+			geoJSON: `{"type":"Polygon","coordinates":[[[0,0],[10,0],[10,10],[0,0]],[[1,1],[2,1],[2,2],[1,1]]]}`,
+		},
+		{
+			wkt:     "MULTIPOINT(1 2, 3 4)",
+			geoJSON: `{"type":"MultiPoint","coordinates":[[1,2],[3,4]]}`,
+		},
+		{
+			wkt:     "MULTILINESTRING((1 2, 3 4), (5 6, 7 8))",
+			geoJSON: `{"type":"MultiLineString","coordinates":[[[1,2],[3,4]],[[5,6],[7,8]]]}`,
+		},
+		{
+			wkt:     "MULTIPOLYGON(((0 0, 1 0, 1 1, 0 0)), ((5 5, 6 5, 6 6, 5 5)))",
+			geoJSON: `{"type":"MultiPolygon","coordinates":[[[[0,0],[1,0],[1,1],[0,0]]],[[[5,5],[6,5],[6,6],[5,5]]]]}`,
+		},
+		{
+			wkt:     "GEOMETRYCOLLECTION(POINT(1 2), LINESTRING(3 4, 5 6))",
+			geoJSON: `{"type":"GeometryCollection","geometries":[{"type":"Point","coordinates":[1,2]},{"type":"LineString","coordinates":[[3,4],[5,6]]}]}`,
+		},
+		{
+			wkt:     "GEOMETRYCOLLECTION EMPTY",
+			geoJSON: `{"type":"GeometryCollection","geometries":[]}`,
+		},
+	}
+	for _, tc := range testcases {
+		got, err := Geography{WKT: tc.wkt}.GeoJSON()
+		if err != nil {
+			t.Errorf("%q: GeoJSON() error: %v", tc.wkt, err)
+			continue
+		}
+		if string(got) != tc.geoJSON {
+			t.Errorf("%q: GeoJSON() got %s, want %s", tc.wkt, got, tc.geoJSON)
+		}
+		g, err := GeographyFromGeoJSON([]byte(tc.geoJSON))
+		if err != nil {
+			t.Errorf("%s: GeographyFromGeoJSON error: %v", tc.geoJSON, err)
+			continue
+		}
+		if g.WKT != tc.wkt {
+			t.Errorf("%s: GeographyFromGeoJSON got %q, want %q", tc.geoJSON, g.WKT, tc.wkt)
+		}
+	}
+}
+
+func TestGeographyGeoJSONLenientWKT(t *testing.T) {
+	// Lower-case tags, extra whitespace and parenthesized MULTIPOINT members
+	// are all valid WKT.
+	got, err := Geography{WKT: "  multipoint ( (1 2) ,(3   4) ) "}.GeoJSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := `{"type":"MultiPoint","coordinates":[[1,2],[3,4]]}`; string(got) != want {
+		t.Errorf("got %s, want %s", got, want)
+	}
+}
+
+func TestGeographyErrors(t *testing.T) {
+	for _, wkt := range []string{
+		"",
+		"CIRCLE(1 2)",
+		"POINT(1)",
+		"POINT(1 2 3)",
+		"POINT(1 2, 3 4)",
+		"LINESTRING(1 2, 3 4",
+		"POINT(1 2) extra",
+		"POINT(a b)",
+	} {
+		if _, err := (Geography{WKT: wkt}).GeoJSON(); err == nil {
+			t.Errorf("%q: GeoJSON() got nil error, want error", wkt)
+		}
+	}
+	for _, geoJSON := range []string{
+		`not json`,
+		`{"type":"Feature","geometry":{"type":"Point","coordinates":[1,2]}}`,
+		`{"type":"Point","coordinates":[1,2,3]}`,
+		`{"type":"Point","coordinates":1}`,
+		`{"type":"LineString","coordinates":[1,2]}`,
+		`{"type":"Point","coordinates":[[1,2]]}`,
+	} {
+		if _, err := GeographyFromGeoJSON([]byte(geoJSON)); err == nil {
+			t.Errorf("%s: GeographyFromGeoJSON got nil error, want error", geoJSON)
+		}
+	}
+}
+
+func TestGeographyStructFields(t *testing.T) {
+	type geoStruct struct {
+		G  Geography
+		GR []Geography
+	}
+	schema, err := InferSchema(geoStruct{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantSchema := Schema{
+		{Name: "G", Type: GeographyFieldType, Required: true},
+		{Name: "GR", Type: GeographyFieldType, Repeated: true},
+	}
+	if diff := testutil.Diff(schema, wantSchema); diff != "" {
+		t.Errorf("InferSchema: -got, +want:\n%s", diff)
+	}
+
+	var loaded geoStruct
+	mustLoad(t, &loaded, schema, []Value{testGeography, []Value{testGeography, "POINT(1 2)"}})
+	want := geoStruct{
+		G:  Geography{WKT: testGeography},
+		GR: []Geography{{WKT: testGeography}, {WKT: "POINT(1 2)"}},
+	}
+	if diff := testutil.Diff(loaded, want); diff != "" {
+		t.Errorf("load: -got, +want:\n%s", diff)
+	}
+
+	saved, _, err := (&StructSaver{Struct: want, Schema: schema}).Save()
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantSaved := map[string]Value{
+		"G":  testGeography,
+		"GR": []string{testGeography, "POINT(1 2)"},
+	}
+	if diff := testutil.Diff(saved, wantSaved); diff != "" {
+		t.Errorf("save: -got, +want:\n%s", diff)
+	}
+}
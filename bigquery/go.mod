@@ -7,14 +7,17 @@ require (
 	cloud.google.com/go/datacatalog v1.3.0
 	cloud.google.com/go/iam v0.3.0
 	cloud.google.com/go/storage v1.22.1
+	github.com/apache/arrow/go/v10 v10.0.1
 	github.com/golang/protobuf v1.5.2
 	github.com/google/go-cmp v0.5.8
 	github.com/googleapis/gax-go/v2 v2.4.0
 	go.opencensus.io v0.23.0
-	golang.org/x/sync v0.0.0-20220601150217-0de741cfad7f
+	go.opentelemetry.io/otel v1.2.0
+	go.opentelemetry.io/otel/trace v1.2.0
+	golang.org/x/sync v0.0.0-20220819030929-7fc1605a5dde
 	golang.org/x/xerrors v0.0.0-20220609144429-65e65417b02f
 	google.golang.org/api v0.85.0
 	google.golang.org/genproto v0.0.0-20220622131801-db39fadba55f
-	google.golang.org/grpc v1.47.0
-	google.golang.org/protobuf v1.28.0
+	google.golang.org/grpc v1.49.0
+	google.golang.org/protobuf v1.28.1
 )
@@ -19,6 +19,8 @@ import (
 	"errors"
 	"fmt"
 	"reflect"
+	"strconv"
+	"strings"
 
 	bq "google.golang.org/api/bigquery/v2"
 	"google.golang.org/api/googleapi"
@@ -35,7 +37,7 @@ func newRowIterator(ctx context.Context, src *rowSource, pf pageFetcher) *RowIte
 	it.pageInfo, it.nextFunc = iterator.NewPageInfo(
 		it.fetch,
 		func() int { return len(it.rows) },
-		func() interface{} { r := it.rows; it.rows = nil; return r })
+		func() interface{} { r := it.rows; it.rows = nil; it.consumed += uint64(len(r)); return r })
 	return it
 }
 
@@ -61,6 +63,10 @@ type RowIterator struct {
 
 	rows         [][]Value
 	structLoader structLoader // used to populate a pointer to a struct
+	fetched      bool         // whether any page has been fetched
+	consumed     uint64       // number of rows returned to the caller
+
+	startedFromToken bool // whether the first page was fetched with a page token
 }
 
 // SourceJob returns an instance of a Job if the RowIterator is backed by a query,
@@ -143,6 +149,7 @@ func (it *RowIterator) Next(dst interface{}) error {
 	}
 	row := it.rows[0]
 	it.rows = it.rows[1:]
+	it.consumed++
 
 	if vl == nil {
 		// This can only happen if dst is a pointer to a struct. We couldn't
@@ -163,7 +170,41 @@ func isStructPtr(x interface{}) bool {
 // PageInfo supports pagination. See the google.golang.org/api/iterator package for details.
 func (it *RowIterator) PageInfo() *iterator.PageInfo { return it.pageInfo }
 
+// ResumeToken returns a token recording the position of the next row that Next
+// would return. Unlike PageInfo().Token, the position is exact even when part
+// of a page has been read. The token can be used with Job.ResumeRead to
+// continue reading the results of the same query, for example in a later
+// request to a stateless service. Tokens are opaque and should not be parsed.
+//
+// ResumeToken returns an error if the iterator was started from a page token
+// set with PageInfo().Token, since the row position is then unknown.
+func (it *RowIterator) ResumeToken() (string, error) {
+	if it.startedFromToken {
+		return "", errors.New("bigquery: cannot compute a resume token for an iterator started from a page token")
+	}
+	return resumeTokenPrefix + strconv.FormatUint(it.StartIndex+it.consumed, 10), nil
+}
+
+const resumeTokenPrefix = "r"
+
+// parseResumeToken returns the row index recorded in a token returned by
+// RowIterator.ResumeToken.
+func parseResumeToken(tok string) (uint64, error) {
+	if !strings.HasPrefix(tok, resumeTokenPrefix) {
+		return 0, fmt.Errorf("bigquery: invalid resume token %q", tok)
+	}
+	n, err := strconv.ParseUint(strings.TrimPrefix(tok, resumeTokenPrefix), 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("bigquery: invalid resume token %q", tok)
+	}
+	return n, nil
+}
+
 func (it *RowIterator) fetch(pageSize int, pageToken string) (string, error) {
+	if !it.fetched && pageToken != "" {
+		it.startedFromToken = true
+	}
+	it.fetched = true
 	res, err := it.pf(it.ctx, it.src, it.Schema, it.StartIndex, int64(pageSize), pageToken)
 	if err != nil {
 		return "", err
@@ -210,8 +251,22 @@ type fetchPageResult struct {
 // fetchPage is our generalized fetch mechanism.  It interrogates from cache, and
 // then dispatches to either the appropriate job or table-based backend mechanism
 // as needed.
-func fetchPage(ctx context.Context, src *rowSource, schema Schema, startIndex uint64, pageSize int64, pageToken string) (*fetchPageResult, error) {
-	result, err := fetchCachedPage(ctx, src, schema, startIndex, pageSize, pageToken)
+func fetchPage(ctx context.Context, src *rowSource, schema Schema, startIndex uint64, pageSize int64, pageToken string) (result *fetchPageResult, err error) {
+	ctx, span := startJobSpan(ctx, "cloud.google.com/go/bigquery.RowIterator.FetchPage")
+	defer func() {
+		if err == nil {
+			if src.j != nil {
+				span.SetAttributes(jobTraceAttrs(src.j.projectID, src.j.jobID, src.j.location)...)
+			}
+			span.SetAttributes(
+				traceAttrPageRows.Int(len(result.rows)),
+				traceAttrTotalRows.Int64(int64(result.totalRows)),
+			)
+		}
+		endJobSpan(span, err)
+	}()
+
+	result, err = fetchCachedPage(ctx, src, schema, startIndex, pageSize, pageToken)
 	if err != nil {
 		if err != errNoCacheData {
 			// This likely means something more severe, like a problem with schema.
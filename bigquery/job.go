@@ -23,6 +23,7 @@ import (
 	"cloud.google.com/go/internal"
 	"cloud.google.com/go/internal/trace"
 	gax "github.com/googleapis/gax-go/v2"
+	oteltrace "go.opentelemetry.io/otel/trace"
 	bq "google.golang.org/api/bigquery/v2"
 	"google.golang.org/api/googleapi"
 	"google.golang.org/api/iterator"
@@ -270,6 +271,8 @@ func (j *Job) Delete(ctx context.Context) (err error) {
 func (j *Job) Wait(ctx context.Context) (js *JobStatus, err error) {
 	ctx = trace.StartSpan(ctx, "cloud.google.com/go/bigquery.Job.Wait")
 	defer func() { trace.EndSpan(ctx, err) }()
+	ctx, span := startJobSpan(ctx, "cloud.google.com/go/bigquery.Job.Wait")
+	defer func() { endJobSpan(span, err) }()
 
 	if j.isQuery() {
 		// We can avoid polling for query jobs.
@@ -281,10 +284,11 @@ func (j *Job) Wait(ctx context.Context) (js *JobStatus, err error) {
 		if err != nil {
 			return nil, err
 		}
+		j.traceCompletion(span, js)
 		return js, nil
 	}
 	// Non-query jobs must poll.
-	err = internal.Retry(ctx, gax.Backoff{}, func() (stop bool, err error) {
+	err = internal.Retry(ctx, j.pollBackoff(gax.Backoff{}), func() (stop bool, err error) {
 		js, err = j.Status(ctx)
 		if err != nil {
 			return true, err
@@ -297,9 +301,25 @@ func (j *Job) Wait(ctx context.Context) (js *JobStatus, err error) {
 	if err != nil {
 		return nil, err
 	}
+	j.traceCompletion(span, js)
 	return js, nil
 }
 
+// pollBackoff returns the backoff to use while waiting for j to complete,
+// which is def unless the client specifies one.
+func (j *Job) pollBackoff(def gax.Backoff) gax.Backoff {
+	if j.c != nil && j.c.JobPollBackoff != nil {
+		return *j.c.JobPollBackoff
+	}
+	return def
+}
+
+// traceCompletion annotates span with the statistics of the completed job.
+func (j *Job) traceCompletion(span oteltrace.Span, js *JobStatus) {
+	span.SetAttributes(jobTraceAttrs(j.projectID, j.jobID, j.location)...)
+	span.SetAttributes(jobStatsTraceAttrs(js.Statistics)...)
+}
+
 // Read fetches the results of a query job.
 // If j is not a query job, Read returns an error.
 func (j *Job) Read(ctx context.Context) (ri *RowIterator, err error) {
@@ -309,6 +329,30 @@ func (j *Job) Read(ctx context.Context) (ri *RowIterator, err error) {
 	return j.read(ctx, j.waitForQuery, fetchPage)
 }
 
+// ResumeRead fetches the results of a query job, starting at the position
+// recorded in token, which must have been returned by RowIterator.ResumeToken
+// for an iterator over the results of the same job.
+// If j is not a query job, ResumeRead returns an error.
+func (j *Job) ResumeRead(ctx context.Context, token string) (ri *RowIterator, err error) {
+	ctx = trace.StartSpan(ctx, "cloud.google.com/go/bigquery.Job.ResumeRead")
+	defer func() { trace.EndSpan(ctx, err) }()
+
+	return j.resumeRead(ctx, token, j.waitForQuery, fetchPage)
+}
+
+func (j *Job) resumeRead(ctx context.Context, token string, waitForQuery func(context.Context, string) (Schema, uint64, error), pf pageFetcher) (*RowIterator, error) {
+	start, err := parseResumeToken(token)
+	if err != nil {
+		return nil, err
+	}
+	it, err := j.read(ctx, waitForQuery, pf)
+	if err != nil {
+		return nil, err
+	}
+	it.StartIndex = start
+	return it, nil
+}
+
 func (j *Job) read(ctx context.Context, waitForQuery func(context.Context, string) (Schema, uint64, error), pf pageFetcher) (*RowIterator, error) {
 	if !j.isQuery() {
 		return nil, errors.New("bigquery: cannot read from a non-query job")
@@ -332,17 +376,22 @@ func (j *Job) read(ctx context.Context, waitForQuery func(context.Context, strin
 
 // waitForQuery waits for the query job to complete and returns its schema. It also
 // returns the total number of rows in the result set.
-func (j *Job) waitForQuery(ctx context.Context, projectID string) (Schema, uint64, error) {
+func (j *Job) waitForQuery(ctx context.Context, projectID string) (_ Schema, _ uint64, err error) {
+	ctx, span := startJobSpan(ctx, "cloud.google.com/go/bigquery.Jobs.GetQueryResults")
+	defer func() { endJobSpan(span, err) }()
+
 	// Use GetQueryResults only to wait for completion, not to read results.
 	call := j.c.bqs.Jobs.GetQueryResults(projectID, j.jobID).Location(j.location).Context(ctx).MaxResults(0)
 	setClientHeader(call.Header())
-	backoff := gax.Backoff{
+	backoff := j.pollBackoff(gax.Backoff{
 		Initial:    1 * time.Second,
 		Multiplier: 2,
 		Max:        60 * time.Second,
-	}
+	})
 	var res *bq.GetQueryResultsResponse
-	err := internal.Retry(ctx, backoff, func() (stop bool, err error) {
+	polls := 0
+	err = internal.Retry(ctx, backoff, func() (stop bool, err error) {
+		polls++
 		res, err = call.Do()
 		if err != nil {
 			return !retryableError(err, jobRetryReasons), err
@@ -355,6 +404,13 @@ func (j *Job) waitForQuery(ctx context.Context, projectID string) (Schema, uint6
 	if err != nil {
 		return nil, 0, err
 	}
+	span.SetAttributes(jobTraceAttrs(projectID, j.jobID, j.location)...)
+	span.SetAttributes(
+		traceAttrCacheHit.Bool(res.CacheHit),
+		traceAttrBytesProcessed.Int64(res.TotalBytesProcessed),
+		traceAttrTotalRows.Int64(int64(res.TotalRows)),
+		traceAttrAttempts.Int(polls),
+	)
 	return bqToSchema(res.Schema), res.TotalRows, nil
 }
 
@@ -365,6 +421,9 @@ type JobStatistics struct {
 	EndTime             time.Time
 	TotalBytesProcessed int64
 
+	// Details holds the statistics specific to the kind of job: one of
+	// *ExtractStatistics, *LoadStatistics, *QueryStatistics or
+	// *CopyStatistics.
 	Details Statistics
 
 	// NumChildJobs indicates the number of child jobs run as part of a script.
@@ -380,14 +439,34 @@ type JobStatistics struct {
 	// ReservationUsage attributes slot consumption to reservations.
 	ReservationUsage []*ReservationUsage
 
+	// ReservationID is the name of the primary reservation assigned to the job.
+	// This may differ from the reservations reported in ReservationUsage if
+	// parent reservations were used to execute the job.
+	ReservationID string
+
 	// TransactionInfo indicates the transaction ID associated with the job, if any.
 	TransactionInfo *TransactionInfo
 
 	// SessionInfo contains information about the session if this job is part of one.
 	SessionInfo *SessionInfo
+
+	// CompletionRatio is the approximate fraction of the job that has completed,
+	// between 0 and 1. It is only reported for running jobs.
+	CompletionRatio float64
+
+	// SlotMillis is the number of slot-milliseconds consumed by the job.
+	SlotMillis int64
+
+	// QuotaDeferments lists the quotas which delayed the start of the job.
+	QuotaDeferments []string
+
+	// RowLevelSecurityApplied reports whether any accessed data was protected
+	// by row access policies. Present only for query and extract jobs.
+	RowLevelSecurityApplied bool
 }
 
-// Statistics is one of ExtractStatistics, LoadStatistics or QueryStatistics.
+// Statistics is one of ExtractStatistics, LoadStatistics, QueryStatistics or
+// CopyStatistics.
 type Statistics interface {
 	implementsStatistics()
 }
@@ -398,6 +477,9 @@ type ExtractStatistics struct {
 	// extract configuration. These values will be in the same order as the
 	// URIs specified in the 'destinationUris' field.
 	DestinationURIFileCounts []int64
+
+	// The number of bytes of source table data read by the extract job.
+	InputBytes int64
 }
 
 // LoadStatistics contains statistics about a load job.
@@ -415,6 +497,21 @@ type LoadStatistics struct {
 	// The number of rows imported in a load job. Note that while an import job is
 	// in the running state, this value may change.
 	OutputRows int64
+
+	// The number of bad records encountered. Note that if the job has failed
+	// because of more bad records encountered than the maximum allowed in the
+	// load job configuration, then this number can be less than the total
+	// number of bad records present in the input data.
+	BadRecords int64
+}
+
+// CopyStatistics contains statistics about a copy job.
+type CopyStatistics struct {
+	// The number of rows copied to the destination table.
+	CopiedRows int64
+
+	// The number of logical bytes copied to the destination table.
+	CopiedLogicalBytes int64
 }
 
 // QueryStatistics contains statistics about a query job.
@@ -485,6 +582,27 @@ type QueryStatistics struct {
 
 	// The DDL target table, present only for CREATE/DROP FUNCTION/PROCEDURE queries.
 	DDLTargetRoutine *Routine
+
+	// The number of row access policies affected by a DDL statement. Present
+	// only for DROP ALL ROW ACCESS POLICIES queries.
+	DDLAffectedRowAccessPolicyCount int64
+
+	// For dry run queries, the original estimate of bytes processed for the
+	// job.
+	EstimatedBytesProcessed int64
+
+	// Total number of partitions processed from all partitioned tables
+	// referenced in the job.
+	TotalPartitionsProcessed int64
+
+	// Routines referenced by the job.
+	ReferencedRoutines []*Routine
+
+	// Statistics of a BigQuery ML training job.
+	MLStatistics *MLStatistics
+
+	// Statistics about the use of search indexes by the query.
+	SearchStatistics *SearchStatistics
 }
 
 // BIEngineStatistics contains query statistics specific to the use of BI Engine.
@@ -512,6 +630,100 @@ func bqToBIEngineStatistics(in *bq.BiEngineStatistics) *BIEngineStatistics {
 	return stats
 }
 
+// MLStatistics contains statistics about a BigQuery ML training job.
+type MLStatistics struct {
+	// Maximum number of iterations specified as max_iterations in the
+	// CREATE MODEL query. The actual number of iterations may be less than
+	// this number due to early stop.
+	MaxIterations int64
+
+	// Results for all completed iterations.
+	IterationResults []*TrainingIterationResult
+}
+
+// TrainingIterationResult contains information about a single iteration of
+// a BigQuery ML training run.
+type TrainingIterationResult struct {
+	// Index of the iteration, 0 based.
+	Index int64
+
+	// Time taken to run the iteration.
+	Duration time.Duration
+
+	// Loss computed on the training data at the end of the iteration.
+	TrainingLoss float64
+
+	// Loss computed on the eval data at the end of the iteration.
+	EvalLoss float64
+
+	// Learning rate used for the iteration.
+	LearnRate float64
+}
+
+func bqToMLStatistics(in *bq.MlStatistics) *MLStatistics {
+	if in == nil {
+		return nil
+	}
+	stats := &MLStatistics{
+		MaxIterations: in.MaxIterations,
+	}
+	for _, v := range in.IterationResults {
+		stats.IterationResults = append(stats.IterationResults, &TrainingIterationResult{
+			Index:        v.Index,
+			Duration:     time.Duration(v.DurationMs) * time.Millisecond,
+			TrainingLoss: v.TrainingLoss,
+			EvalLoss:     v.EvalLoss,
+			LearnRate:    v.LearnRate,
+		})
+	}
+	return stats
+}
+
+// SearchStatistics contains statistics about the use of search indexes by a
+// query.
+type SearchStatistics struct {
+	// Specifies whether search indexes were used: one of UNUSED,
+	// PARTIALLY_USED or FULLY_USED.
+	IndexUsageMode string
+
+	// When IndexUsageMode is UNUSED or PARTIALLY_USED, contains the reasons
+	// why search indexes could not be used.
+	IndexUnusedReasons []*IndexUnusedReason
+}
+
+// IndexUnusedReason describes why a search index was not used by a query.
+type IndexUnusedReason struct {
+	// A high-level reason code.
+	Code string
+
+	// Free form human-readable reason.
+	Message string
+
+	// The table containing the search index, if any.
+	BaseTable *Table
+
+	// The name of the unused search index, if available.
+	IndexName string
+}
+
+func bqToSearchStatistics(in *bq.SearchStatistics, c *Client) *SearchStatistics {
+	if in == nil {
+		return nil
+	}
+	stats := &SearchStatistics{
+		IndexUsageMode: in.IndexUsageMode,
+	}
+	for _, v := range in.IndexUnusedReason {
+		stats.IndexUnusedReasons = append(stats.IndexUnusedReasons, &IndexUnusedReason{
+			Code:      v.Code,
+			Message:   v.Message,
+			BaseTable: bqToTable(v.BaseTable, c),
+			IndexName: v.IndexName,
+		})
+	}
+	return stats
+}
+
 // BIEngineReason contains more detailed information about why a query wasn't fully
 // accelerated.
 type BIEngineReason struct {
@@ -755,6 +967,7 @@ func bqToDMLStatistics(q *bq.DmlStatistics) *DMLStatistics {
 func (*ExtractStatistics) implementsStatistics() {}
 func (*LoadStatistics) implementsStatistics()    {}
 func (*QueryStatistics) implementsStatistics()   {}
+func (*CopyStatistics) implementsStatistics()    {}
 
 // Jobs lists jobs within a project.
 func (c *Client) Jobs(ctx context.Context) *JobIterator {
@@ -946,13 +1159,21 @@ func (j *Job) setStatistics(s *bq.JobStatistics, c *Client) {
 		ParentJobID:         s.ParentJobId,
 		ScriptStatistics:    bqToScriptStatistics(s.ScriptStatistics),
 		ReservationUsage:    bqToReservationUsage(s.ReservationUsage),
+		ReservationID:       s.ReservationId,
 		TransactionInfo:     bqToTransactionInfo(s.TransactionInfo),
 		SessionInfo:         bqToSessionInfo(s.SessionInfo),
+		CompletionRatio:     s.CompletionRatio,
+		SlotMillis:          s.TotalSlotMs,
+		QuotaDeferments:     s.QuotaDeferments,
+	}
+	if s.RowLevelSecurityStatistics != nil {
+		js.RowLevelSecurityApplied = s.RowLevelSecurityStatistics.RowLevelSecurityApplied
 	}
 	switch {
 	case s.Extract != nil:
 		js.Details = &ExtractStatistics{
 			DestinationURIFileCounts: []int64(s.Extract.DestinationUriFileCounts),
+			InputBytes:               s.Extract.InputBytes,
 		}
 	case s.Load != nil:
 		js.Details = &LoadStatistics{
@@ -960,6 +1181,12 @@ func (j *Job) setStatistics(s *bq.JobStatistics, c *Client) {
 			InputFiles:     s.Load.InputFiles,
 			OutputBytes:    s.Load.OutputBytes,
 			OutputRows:     s.Load.OutputRows,
+			BadRecords:     s.Load.BadRecords,
+		}
+	case s.Copy != nil:
+		js.Details = &CopyStatistics{
+			CopiedRows:         s.Copy.CopiedRows,
+			CopiedLogicalBytes: s.Copy.CopiedLogicalBytes,
 		}
 	case s.Query != nil:
 		var names []string
@@ -970,25 +1197,35 @@ func (j *Job) setStatistics(s *bq.JobStatistics, c *Client) {
 		for _, tr := range s.Query.ReferencedTables {
 			tables = append(tables, bqToTable(tr, c))
 		}
+		var routines []*Routine
+		for _, rr := range s.Query.ReferencedRoutines {
+			routines = append(routines, bqToRoutine(rr, c))
+		}
 		js.Details = &QueryStatistics{
-			BIEngineStatistics:            bqToBIEngineStatistics(s.Query.BiEngineStatistics),
-			BillingTier:                   s.Query.BillingTier,
-			CacheHit:                      s.Query.CacheHit,
-			DDLTargetTable:                bqToTable(s.Query.DdlTargetTable, c),
-			DDLOperationPerformed:         s.Query.DdlOperationPerformed,
-			DDLTargetRoutine:              bqToRoutine(s.Query.DdlTargetRoutine, c),
-			StatementType:                 s.Query.StatementType,
-			TotalBytesBilled:              s.Query.TotalBytesBilled,
-			TotalBytesProcessed:           s.Query.TotalBytesProcessed,
-			TotalBytesProcessedAccuracy:   s.Query.TotalBytesProcessedAccuracy,
-			NumDMLAffectedRows:            s.Query.NumDmlAffectedRows,
-			DMLStats:                      bqToDMLStatistics(s.Query.DmlStats),
-			QueryPlan:                     queryPlanFromProto(s.Query.QueryPlan),
-			Schema:                        bqToSchema(s.Query.Schema),
-			SlotMillis:                    s.Query.TotalSlotMs,
-			Timeline:                      timelineFromProto(s.Query.Timeline),
-			ReferencedTables:              tables,
-			UndeclaredQueryParameterNames: names,
+			BIEngineStatistics:              bqToBIEngineStatistics(s.Query.BiEngineStatistics),
+			BillingTier:                     s.Query.BillingTier,
+			CacheHit:                        s.Query.CacheHit,
+			DDLTargetTable:                  bqToTable(s.Query.DdlTargetTable, c),
+			DDLOperationPerformed:           s.Query.DdlOperationPerformed,
+			DDLTargetRoutine:                bqToRoutine(s.Query.DdlTargetRoutine, c),
+			StatementType:                   s.Query.StatementType,
+			TotalBytesBilled:                s.Query.TotalBytesBilled,
+			TotalBytesProcessed:             s.Query.TotalBytesProcessed,
+			TotalBytesProcessedAccuracy:     s.Query.TotalBytesProcessedAccuracy,
+			NumDMLAffectedRows:              s.Query.NumDmlAffectedRows,
+			DMLStats:                        bqToDMLStatistics(s.Query.DmlStats),
+			QueryPlan:                       queryPlanFromProto(s.Query.QueryPlan),
+			Schema:                          bqToSchema(s.Query.Schema),
+			SlotMillis:                      s.Query.TotalSlotMs,
+			Timeline:                        timelineFromProto(s.Query.Timeline),
+			ReferencedTables:                tables,
+			UndeclaredQueryParameterNames:   names,
+			DDLAffectedRowAccessPolicyCount: s.Query.DdlAffectedRowAccessPolicyCount,
+			EstimatedBytesProcessed:         s.Query.EstimatedBytesProcessed,
+			TotalPartitionsProcessed:        s.Query.TotalPartitionsProcessed,
+			ReferencedRoutines:              routines,
+			MLStatistics:                    bqToMLStatistics(s.Query.MlStatistics),
+			SearchStatistics:                bqToSearchStatistics(s.Query.SearchStatistics, c),
 		}
 	}
 	j.lastStatus.Statistics = js
@@ -16,8 +16,10 @@ package bigquery
 
 import (
 	"testing"
+	"time"
 
 	"cloud.google.com/go/internal/testutil"
+	gax "github.com/googleapis/gax-go/v2"
 	bq "google.golang.org/api/bigquery/v2"
 )
 
@@ -73,6 +75,98 @@ func TestCreateJobRef(t *testing.T) {
 	}
 }
 
+func TestSetStatistics(t *testing.T) {
+	c := &Client{projectID: "projectID"}
+	for _, test := range []struct {
+		desc string
+		in   *bq.JobStatistics
+		want *JobStatistics
+	}{
+		{
+			desc: "copy",
+			in: &bq.JobStatistics{
+				CompletionRatio:            0.5,
+				TotalSlotMs:                1234,
+				QuotaDeferments:            []string{"quota"},
+				RowLevelSecurityStatistics: &bq.RowLevelSecurityStatistics{RowLevelSecurityApplied: true},
+				Copy:                       &bq.JobStatistics5{CopiedRows: 10, CopiedLogicalBytes: 100},
+			},
+			want: &JobStatistics{
+				CompletionRatio:         0.5,
+				SlotMillis:              1234,
+				QuotaDeferments:         []string{"quota"},
+				RowLevelSecurityApplied: true,
+				Details:                 &CopyStatistics{CopiedRows: 10, CopiedLogicalBytes: 100},
+			},
+		},
+		{
+			desc: "reservation",
+			in: &bq.JobStatistics{
+				ReservationId:    "projects/p/locations/US/reservations/r",
+				ReservationUsage: []*bq.JobStatisticsReservationUsage{{Name: "r", SlotMs: 10}},
+			},
+			want: &JobStatistics{
+				ReservationID:    "projects/p/locations/US/reservations/r",
+				ReservationUsage: []*ReservationUsage{{Name: "r", SlotMillis: 10}},
+			},
+		},
+		{
+			desc: "extract",
+			in: &bq.JobStatistics{
+				Extract: &bq.JobStatistics4{DestinationUriFileCounts: []int64{2}, InputBytes: 42},
+			},
+			want: &JobStatistics{
+				Details: &ExtractStatistics{DestinationURIFileCounts: []int64{2}, InputBytes: 42},
+			},
+		},
+		{
+			desc: "query",
+			in: &bq.JobStatistics{
+				Query: &bq.JobStatistics2{
+					EstimatedBytesProcessed:  99,
+					TotalPartitionsProcessed: 3,
+					BiEngineStatistics: &bq.BiEngineStatistics{
+						BiEngineMode:    "PARTIAL",
+						BiEngineReasons: []*bq.BiEngineReason{{Code: "OTHER_REASON", Message: "msg"}},
+					},
+					MlStatistics: &bq.MlStatistics{
+						MaxIterations:    5,
+						IterationResults: []*bq.IterationResult{{Index: 0, DurationMs: 1500, TrainingLoss: 0.25}},
+					},
+					SearchStatistics: &bq.SearchStatistics{
+						IndexUsageMode:    "UNUSED",
+						IndexUnusedReason: []*bq.IndexUnusedReason{{Code: "INDEX_CONFIG_NOT_AVAILABLE", IndexName: "idx"}},
+					},
+				},
+			},
+			want: &JobStatistics{
+				Details: &QueryStatistics{
+					EstimatedBytesProcessed:  99,
+					TotalPartitionsProcessed: 3,
+					BIEngineStatistics: &BIEngineStatistics{
+						BIEngineMode:    "PARTIAL",
+						BIEngineReasons: []*BIEngineReason{{Code: "OTHER_REASON", Message: "msg"}},
+					},
+					MLStatistics: &MLStatistics{
+						MaxIterations:    5,
+						IterationResults: []*TrainingIterationResult{{Index: 0, Duration: 1500 * time.Millisecond, TrainingLoss: 0.25}},
+					},
+					SearchStatistics: &SearchStatistics{
+						IndexUsageMode:     "UNUSED",
+						IndexUnusedReasons: []*IndexUnusedReason{{Code: "INDEX_CONFIG_NOT_AVAILABLE", IndexName: "idx"}},
+					},
+				},
+			},
+		},
+	} {
+		j := &Job{lastStatus: &JobStatus{}}
+		j.setStatistics(test.in, c)
+		if diff := testutil.Diff(j.lastStatus.Statistics, test.want); diff != "" {
+			t.Errorf("%s: -got, +want:\n%s", test.desc, diff)
+		}
+	}
+}
+
 func fixRandomID(s string) func() {
 	prev := randomIDFn
 	randomIDFn = func() string { return s }
@@ -93,3 +187,22 @@ func checkJob(t *testing.T, i int, got, want *bq.Job) {
 		t.Errorf("#%d: (got=-, want=+) %s", i, d)
 	}
 }
+
+func TestJobPollBackoff(t *testing.T) {
+	def := gax.Backoff{Initial: time.Second, Max: time.Minute, Multiplier: 2}
+	j := &Job{c: &Client{}}
+	if got := j.pollBackoff(def); got != def {
+		t.Errorf("no client backoff: got %+v, want %+v", got, def)
+	}
+	custom := &gax.Backoff{Initial: 100 * time.Millisecond, Max: 100 * time.Millisecond, Multiplier: 1}
+	j.c.JobPollBackoff = custom
+	got := j.pollBackoff(def)
+	if got != *custom {
+		t.Errorf("client backoff: got %+v, want %+v", got, *custom)
+	}
+	// Using the returned backoff must not affect the client's.
+	got.Pause()
+	if *custom != (gax.Backoff{Initial: 100 * time.Millisecond, Max: 100 * time.Millisecond, Multiplier: 1}) {
+		t.Errorf("client backoff modified: %+v", *custom)
+	}
+}
@@ -0,0 +1,178 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bigquery
+
+import (
+	"encoding/base64"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/big"
+	"strconv"
+	"time"
+
+	"cloud.google.com/go/civil"
+	"google.golang.org/api/iterator"
+)
+
+// WriteCSV writes the remaining rows of it to w as CSV, preceded by a header
+// row of column names. It must be called before the first call to Next on it.
+//
+// NULL is written as an empty field, BYTES are base64-encoded, TIMESTAMPs are
+// written in UTC, NUMERIC and BIGNUMERIC values are written with their full
+// scale, and repeated and RECORD columns are written as JSON.
+//
+// WriteCSV does not close w.
+func (it *RowIterator) WriteCSV(w io.Writer) error {
+	cw := csv.NewWriter(w)
+	header := false
+	for {
+		var row []Value
+		err := it.Next(&row)
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		if !header {
+			if err := writeCSVHeader(cw, it.Schema); err != nil {
+				return err
+			}
+			header = true
+		}
+		rec := make([]string, len(row))
+		for i, v := range row {
+			s, err := csvValue(it.Schema[i], v)
+			if err != nil {
+				return fmt.Errorf("bigquery: column %s: %v", it.Schema[i].Name, err)
+			}
+			rec[i] = s
+		}
+		if err := cw.Write(rec); err != nil {
+			return err
+		}
+	}
+	if !header {
+		if err := writeCSVHeader(cw, it.Schema); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+func writeCSVHeader(cw *csv.Writer, s Schema) error {
+	names := make([]string, len(s))
+	for i, f := range s {
+		names[i] = f.Name
+	}
+	return cw.Write(names)
+}
+
+// csvValue formats v, a value of the column described by fs, as a CSV field.
+func csvValue(fs *FieldSchema, v Value) (string, error) {
+	if v == nil {
+		return "", nil
+	}
+	if fs.Repeated || fs.Type == RecordFieldType {
+		jv, err := jsonExportValue(fs, v)
+		if err != nil {
+			return "", err
+		}
+		b, err := json.Marshal(jv)
+		if err != nil {
+			return "", err
+		}
+		return string(b), nil
+	}
+	return scalarExportString(fs, v)
+}
+
+// jsonExportValue converts v, a value of the column described by fs, to a
+// value that encodes to JSON as BigQuery would export it.
+func jsonExportValue(fs *FieldSchema, v Value) (interface{}, error) {
+	if v == nil {
+		return nil, nil
+	}
+	if fs.Repeated {
+		vals, ok := v.([]Value)
+		if !ok {
+			return nil, fmt.Errorf("got %T for repeated column, want []Value", v)
+		}
+		elem := *fs
+		elem.Repeated = false
+		out := make([]interface{}, len(vals))
+		for i, ev := range vals {
+			jv, err := jsonExportValue(&elem, ev)
+			if err != nil {
+				return nil, err
+			}
+			out[i] = jv
+		}
+		return out, nil
+	}
+	switch fs.Type {
+	case RecordFieldType:
+		vals, ok := v.([]Value)
+		if !ok {
+			return nil, fmt.Errorf("got %T for RECORD column, want []Value", v)
+		}
+		out := make(map[string]interface{}, len(fs.Schema))
+		for i, sf := range fs.Schema {
+			jv, err := jsonExportValue(sf, vals[i])
+			if err != nil {
+				return nil, fmt.Errorf("%s: %v", sf.Name, err)
+			}
+			out[sf.Name] = jv
+		}
+		return out, nil
+	case IntegerFieldType, FloatFieldType, BooleanFieldType:
+		return v, nil
+	}
+	return scalarExportString(fs, v)
+}
+
+func scalarExportString(fs *FieldSchema, v Value) (string, error) {
+	switch x := v.(type) {
+	case string:
+		return x, nil
+	case []byte:
+		return base64.StdEncoding.EncodeToString(x), nil
+	case int64:
+		return strconv.FormatInt(x, 10), nil
+	case float64:
+		return strconv.FormatFloat(x, 'g', -1, 64), nil
+	case bool:
+		return strconv.FormatBool(x), nil
+	case time.Time:
+		return x.UTC().Format("2006-01-02 15:04:05.999999 UTC"), nil
+	case civil.Date:
+		return x.String(), nil
+	case civil.Time:
+		return CivilTimeString(x), nil
+	case civil.DateTime:
+		return CivilDateTimeString(x), nil
+	case *big.Rat:
+		if fs.Type == BigNumericFieldType {
+			return BigNumericString(x), nil
+		}
+		return NumericString(x), nil
+	case fmt.Stringer:
+		return x.String(), nil
+	}
+	return "", fmt.Errorf("unsupported value type %T", v)
+}
@@ -0,0 +1,177 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build go1.17
+// +build go1.17
+
+package bigquery
+
+import (
+	"fmt"
+	"io"
+	"math/big"
+
+	"github.com/apache/arrow/go/v10/arrow"
+	"github.com/apache/arrow/go/v10/arrow/array"
+	"github.com/apache/arrow/go/v10/arrow/memory"
+	"github.com/apache/arrow/go/v10/parquet"
+	"github.com/apache/arrow/go/v10/parquet/compress"
+	"github.com/apache/arrow/go/v10/parquet/pqarrow"
+	"google.golang.org/api/iterator"
+)
+
+// WriteParquet writes the remaining rows of it to w as a Snappy-compressed
+// Parquet file. It must be called before the first call to Next on it.
+//
+// Rows are read as Arrow records (see ArrowRecords), so if the client has a
+// Storage Read API client enabled, they are streamed from the BigQuery
+// Storage API. Columns are mapped to Parquet types according to the Arrow
+// schema of the rows, except that top-level BIGNUMERIC columns are written
+// as strings, because Parquet writing does not support their precision.
+// BIGNUMERIC values nested inside RECORD columns are not supported.
+//
+// WriteParquet does not close w. It requires Go 1.17 or later.
+func (it *RowIterator) WriteParquet(w io.Writer) (err error) {
+	ai := it.ArrowRecords(memory.DefaultAllocator, 0)
+	var fw *pqarrow.FileWriter
+	defer func() {
+		if fw != nil {
+			if cerr := fw.Close(); err == nil {
+				err = cerr
+			}
+		}
+	}()
+	for {
+		rec, err := ai.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		prec, err := parquetRecord(rec)
+		rec.Release()
+		if err != nil {
+			return err
+		}
+		if fw == nil {
+			fw, err = newParquetWriter(prec.Schema(), w)
+			if err != nil {
+				prec.Release()
+				return err
+			}
+		}
+		err = fw.Write(prec)
+		prec.Release()
+		if err != nil {
+			return err
+		}
+	}
+	if fw == nil {
+		// There were no rows; write a file with just the schema. Rows read
+		// from the Storage API have the schema of the read session, and
+		// it.Schema is never set.
+		s := ai.Schema()
+		if s == nil {
+			if s, err = it.Schema.toArrow(); err != nil {
+				return err
+			}
+		}
+		s, err = parquetSchema(s)
+		if err != nil {
+			return err
+		}
+		fw, err = newParquetWriter(s, w)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func newParquetWriter(s *arrow.Schema, w io.Writer) (*pqarrow.FileWriter, error) {
+	props := parquet.NewWriterProperties(parquet.WithCompression(compress.Codecs.Snappy))
+	// Hide any Close method of w, so that closing the Parquet writer does not
+	// close it.
+	return pqarrow.NewFileWriter(s, struct{ io.Writer }{w}, props, pqarrow.DefaultWriterProps())
+}
+
+// parquetSchema returns s with top-level BIGNUMERIC columns replaced by
+// strings.
+func parquetSchema(s *arrow.Schema) (*arrow.Schema, error) {
+	fields := append([]arrow.Field(nil), s.Fields()...)
+	for i, f := range fields {
+		if f.Type.ID() == arrow.DECIMAL256 {
+			fields[i].Type = arrow.BinaryTypes.String
+			continue
+		}
+		if hasDecimal256(f.Type) {
+			return nil, fmt.Errorf("bigquery: column %s: nested BIGNUMERIC values cannot be written to Parquet", f.Name)
+		}
+	}
+	md := s.Metadata()
+	return arrow.NewSchema(fields, &md), nil
+}
+
+func hasDecimal256(t arrow.DataType) bool {
+	switch t := t.(type) {
+	case *arrow.Decimal256Type:
+		return true
+	case *arrow.ListType:
+		return hasDecimal256(t.Elem())
+	case *arrow.StructType:
+		for _, f := range t.Fields() {
+			if hasDecimal256(f.Type) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// parquetRecord returns rec converted to the schema returned by
+// parquetSchema. The caller must release the returned record.
+func parquetRecord(rec arrow.Record) (arrow.Record, error) {
+	s, err := parquetSchema(rec.Schema())
+	if err != nil {
+		return nil, err
+	}
+	cols := make([]arrow.Array, rec.NumCols())
+	for i, col := range rec.Columns() {
+		d, ok := col.(*array.Decimal256)
+		if !ok {
+			col.Retain()
+			cols[i] = col
+			continue
+		}
+		scale := d.DataType().(*arrow.Decimal256Type).Scale
+		denom := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(scale)), nil)
+		b := array.NewStringBuilder(memory.DefaultAllocator)
+		for j := 0; j < d.Len(); j++ {
+			if d.IsNull(j) {
+				b.AppendNull()
+				continue
+			}
+			b.Append(BigNumericString(new(big.Rat).SetFrac(d.Value(j).BigInt(), denom)))
+		}
+		cols[i] = b.NewArray()
+		b.Release()
+	}
+	defer func() {
+		for _, c := range cols {
+			c.Release()
+		}
+	}()
+	return array.NewRecord(s, cols, rec.NumRows()), nil
+}
@@ -0,0 +1,155 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build go1.17
+// +build go1.17
+
+package bigquery
+
+import (
+	"bytes"
+	"context"
+	"math/big"
+	"net"
+	"testing"
+
+	"github.com/apache/arrow/go/v10/arrow"
+	"github.com/apache/arrow/go/v10/arrow/array"
+	"github.com/apache/arrow/go/v10/arrow/ipc"
+	"github.com/apache/arrow/go/v10/arrow/memory"
+	"github.com/apache/arrow/go/v10/parquet/pqarrow"
+	"google.golang.org/api/option"
+	storagepb "google.golang.org/genproto/googleapis/cloud/bigquery/storage/v1"
+	"google.golang.org/grpc"
+)
+
+func TestWriteParquet(t *testing.T) {
+	schema := Schema{
+		{Name: "name", Type: StringFieldType},
+		{Name: "n", Type: IntegerFieldType},
+		{Name: "bn", Type: BigNumericFieldType},
+		{Name: "tags", Type: StringFieldType, Repeated: true},
+	}
+	bn, _ := new(big.Rat).SetString("12345678901234567890.5")
+	rows := [][]Value{
+		{"a", int64(1), bn, []Value{"x"}},
+		{nil, int64(2), nil, []Value{}},
+	}
+	var buf bytes.Buffer
+	if err := exportTestIterator(schema, rows).WriteParquet(&buf); err != nil {
+		t.Fatal(err)
+	}
+
+	tbl, err := pqarrow.ReadTable(context.Background(), bytes.NewReader(buf.Bytes()), nil, pqarrow.ArrowReadProperties{}, memory.DefaultAllocator)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tbl.Release()
+	if got := tbl.NumRows(); got != 2 {
+		t.Fatalf("got %d rows, want 2", got)
+	}
+	if got := tbl.Schema().Field(2).Type; !arrow.TypeEqual(got, arrow.BinaryTypes.String) {
+		t.Errorf("bn: got type %s, want string", got)
+	}
+	bns := tbl.Column(2).Data().Chunk(0).(*array.String)
+	if got, want := bns.Value(0), BigNumericString(bn); got != want {
+		t.Errorf("bn: got %q, want %q", got, want)
+	}
+	if !bns.IsNull(1) {
+		t.Error("bn: got non-null second value, want null")
+	}
+	ns := tbl.Column(1).Data().Chunk(0).(*array.Int64)
+	if ns.Value(0) != 1 || ns.Value(1) != 2 {
+		t.Errorf("n: got %s", ns)
+	}
+}
+
+func TestWriteParquetNestedBigNumeric(t *testing.T) {
+	schema := Schema{
+		{Name: "rec", Type: RecordFieldType, Schema: Schema{
+			{Name: "bn", Type: BigNumericFieldType},
+		}},
+	}
+	rows := [][]Value{{[]Value{big.NewRat(1, 1)}}}
+	var buf bytes.Buffer
+	if err := exportTestIterator(schema, rows).WriteParquet(&buf); err == nil {
+		t.Error("got nil error, want error for nested BIGNUMERIC")
+	}
+}
+
+// emptyReadServer creates read sessions with the given Arrow schema and no
+// streams, as the Storage API does for empty tables.
+type emptyReadServer struct {
+	storagepb.UnimplementedBigQueryReadServer
+	schema []byte
+}
+
+func (s *emptyReadServer) CreateReadSession(context.Context, *storagepb.CreateReadSessionRequest) (*storagepb.ReadSession, error) {
+	return &storagepb.ReadSession{
+		Schema: &storagepb.ReadSession_ArrowSchema{ArrowSchema: &storagepb.ArrowSchema{SerializedSchema: s.schema}},
+	}, nil
+}
+
+func TestWriteParquetEmptyFromStorage(t *testing.T) {
+	schema := arrow.NewSchema([]arrow.Field{
+		{Name: "name", Type: arrow.BinaryTypes.String, Nullable: true},
+		{Name: "n", Type: arrow.PrimitiveTypes.Int64, Nullable: true},
+	}, nil)
+	var raw bytes.Buffer
+	iw := ipc.NewWriter(&raw, ipc.WithSchema(schema))
+	if err := iw.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	srv := grpc.NewServer()
+	storagepb.RegisterBigQueryReadServer(srv, &emptyReadServer{schema: raw.Bytes()})
+	lis, err := net.Listen("tcp", "localhost:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	go srv.Serve(lis)
+	defer srv.Stop()
+
+	ctx := context.Background()
+	c := &Client{projectID: "p"}
+	if err := c.EnableStorageReadClient(ctx,
+		option.WithEndpoint(lis.Addr().String()),
+		option.WithoutAuthentication(),
+		option.WithGRPCDialOption(grpc.WithInsecure())); err != nil {
+		t.Fatal(err)
+	}
+	defer c.readClient.Close()
+
+	var buf bytes.Buffer
+	if err := c.Dataset("d").Table("t").Read(ctx).WriteParquet(&buf); err != nil {
+		t.Fatal(err)
+	}
+	tbl, err := pqarrow.ReadTable(ctx, bytes.NewReader(buf.Bytes()), nil, pqarrow.ArrowReadProperties{}, memory.DefaultAllocator)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tbl.Release()
+	if got := tbl.NumRows(); got != 0 {
+		t.Errorf("got %d rows, want 0", got)
+	}
+	got := tbl.Schema()
+	if len(got.Fields()) != len(schema.Fields()) {
+		t.Fatalf("got schema %s, want %s", got, schema)
+	}
+	for i, f := range schema.Fields() {
+		if g := got.Field(i); g.Name != f.Name || !arrow.TypeEqual(g.Type, f.Type) {
+			t.Errorf("field %d: got %s %s, want %s %s", i, g.Name, g.Type, f.Name, f.Type)
+		}
+	}
+}
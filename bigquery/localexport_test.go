@@ -0,0 +1,76 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bigquery
+
+import (
+	"bytes"
+	"context"
+	"math/big"
+	"testing"
+	"time"
+
+	"cloud.google.com/go/civil"
+)
+
+func exportTestIterator(schema Schema, rows [][]Value) *RowIterator {
+	pf := &pageFetcherStub{
+		fetchResponses: map[string]fetchResponse{
+			"": {result: &fetchPageResult{schema: schema, rows: rows}},
+		},
+	}
+	return newRowIterator(context.Background(), nil, pf.fetchPage)
+}
+
+func TestWriteCSV(t *testing.T) {
+	schema := Schema{
+		{Name: "s", Type: StringFieldType},
+		{Name: "n", Type: IntegerFieldType},
+		{Name: "b", Type: BytesFieldType},
+		{Name: "ts", Type: TimestampFieldType},
+		{Name: "t", Type: TimeFieldType},
+		{Name: "num", Type: NumericFieldType},
+		{Name: "tags", Type: StringFieldType, Repeated: true},
+		{Name: "rec", Type: RecordFieldType, Schema: Schema{
+			{Name: "x", Type: IntegerFieldType},
+			{Name: "y", Type: StringFieldType},
+		}},
+	}
+	rows := [][]Value{
+		{"a,b", int64(1), []byte("hi"), time.Date(2022, 1, 2, 3, 4, 5, 6000, time.UTC),
+			civil.Time{Hour: 1, Minute: 2, Second: 3}, big.NewRat(3, 2), []Value{"x", "y"}, []Value{int64(7), "z"}},
+		{nil, nil, nil, nil, nil, nil, []Value{}, nil},
+	}
+	var buf bytes.Buffer
+	if err := exportTestIterator(schema, rows).WriteCSV(&buf); err != nil {
+		t.Fatal(err)
+	}
+	want := "s,n,b,ts,t,num,tags,rec\n" +
+		`"a,b",1,aGk=,2022-01-02 03:04:05.000006 UTC,01:02:03,1.500000000,"[""x"",""y""]","{""x"":7,""y"":""z""}"` + "\n" +
+		",,,,,,[],\n"
+	if got := buf.String(); got != want {
+		t.Errorf("got\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestWriteCSVEmpty(t *testing.T) {
+	schema := Schema{{Name: "a", Type: StringFieldType}, {Name: "b", Type: IntegerFieldType}}
+	var buf bytes.Buffer
+	if err := exportTestIterator(schema, nil).WriteCSV(&buf); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := buf.String(), "a,b\n"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
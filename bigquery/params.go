@@ -21,6 +21,7 @@ import (
 	"math/big"
 	"reflect"
 	"regexp"
+	"strings"
 	"time"
 
 	"cloud.google.com/go/civil"
@@ -87,6 +88,8 @@ var (
 	typeOfGoTime        = reflect.TypeOf(time.Time{})
 	typeOfRat           = reflect.TypeOf(&big.Rat{})
 	typeOfIntervalValue = reflect.TypeOf(&IntervalValue{})
+	typeOfGeography     = reflect.TypeOf(Geography{})
+	typeOfRangeValue    = reflect.TypeOf(&RangeValue{})
 )
 
 // A QueryParameter is a parameter to a query.
@@ -109,8 +112,15 @@ type QueryParameter struct {
 	// time.Time: TIMESTAMP
 	// *big.Rat: NUMERIC
 	// *IntervalValue: INTERVAL
+	// Geography: GEOGRAPHY
 	// Arrays and slices of the above.
-	// Structs of the above. Only the exported fields are used.
+	// Structs of the above, and pointers to them. Only the exported fields are used.
+	// Arrays of structs, and structs containing arrays and other structs, may
+	// be nested to any depth. Arrays of arrays are not supported by BigQuery.
+	//
+	// Values held in interface types, such as the elements of an []interface{},
+	// are sent according to their dynamic type. All elements of an array must
+	// have the same parameter type.
 	//
 	// For scalar values, you can supply the Null types within this library
 	// to send the appropriate NULL values (e.g. NullInt64, NullString, etc).
@@ -129,13 +139,14 @@ type QueryParameter struct {
 }
 
 func (p QueryParameter) toBQ() (*bq.QueryParameter, error) {
-	pv, err := paramValue(reflect.ValueOf(p.Value))
+	v := reflect.ValueOf(p.Value)
+	pv, err := paramValue(v)
 	if err != nil {
-		return nil, err
+		return nil, p.annotateError(err)
 	}
-	pt, err := paramType(reflect.TypeOf(p.Value))
+	pt, err := paramTypeOfValue(v)
 	if err != nil {
-		return nil, err
+		return nil, p.annotateError(err)
 	}
 	return &bq.QueryParameter{
 		Name:           p.Name,
@@ -144,6 +155,47 @@ func (p QueryParameter) toBQ() (*bq.QueryParameter, error) {
 	}, nil
 }
 
+// annotateError adds the parameter name to errors converting p.
+func (p QueryParameter) annotateError(err error) error {
+	if pe, ok := err.(*paramError); ok {
+		pe.name = p.Name
+		return pe
+	}
+	if p.Name == "" {
+		return err
+	}
+	return &paramError{name: p.Name, err: err}
+}
+
+// paramError reports an error converting a value nested within a query
+// parameter, along with the location of the value.
+type paramError struct {
+	name string // name of the parameter, if known
+	path string // location of the value, e.g. ".Items[2].Price"
+	err  error
+}
+
+func (e *paramError) Error() string {
+	loc := strings.TrimPrefix(e.path, ".")
+	if e.name != "" {
+		loc = e.name + e.path
+	}
+	return fmt.Sprintf("bigquery: query parameter value %s: %s", loc, strings.TrimPrefix(e.err.Error(), "bigquery: "))
+}
+
+func (e *paramError) Unwrap() error { return e.err }
+
+// wrapParamError prefixes the location of err with elem, which is either a
+// struct field selector (".Name") or an array index ("[3]", or "[]" when
+// there is no value).
+func wrapParamError(err error, elem string) error {
+	if pe, ok := err.(*paramError); ok {
+		pe.path = elem + pe.path
+		return pe
+	}
+	return &paramError{path: elem, err: err}
+}
+
 func paramType(t reflect.Type) (*bq.QueryParameterType, error) {
 	if t == nil {
 		return nil, errors.New("bigquery: nil parameter")
@@ -169,8 +221,12 @@ func paramType(t reflect.Type) (*bq.QueryParameterType, error) {
 		return int64ParamType, nil
 	case typeOfNullString:
 		return stringParamType, nil
-	case typeOfNullGeography:
+	case typeOfGeography, typeOfNullGeography:
 		return geographyParamType, nil
+	case typeOfRangeValue:
+		// The RANGE parameter type requires a range element type, which the
+		// query parameter representation does not yet expose.
+		return nil, errors.New("bigquery: RANGE query parameters are not supported; use a STRING parameter and CAST it to RANGE in the query")
 	}
 	switch t.Kind() {
 	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64, reflect.Uint8, reflect.Uint16, reflect.Uint32:
@@ -194,9 +250,9 @@ func paramType(t reflect.Type) (*bq.QueryParameterType, error) {
 	case reflect.Array:
 		et, err := paramType(t.Elem())
 		if err != nil {
-			return nil, err
+			return nil, wrapParamError(err, "[]")
 		}
-		return &bq.QueryParameterType{Type: "ARRAY", ArrayType: et}, nil
+		return arrayParamType(et)
 
 	case reflect.Ptr:
 		if t.Elem().Kind() != reflect.Struct {
@@ -214,7 +270,7 @@ func paramType(t reflect.Type) (*bq.QueryParameterType, error) {
 		for _, f := range fields {
 			pt, err := paramType(f.Type)
 			if err != nil {
-				return nil, err
+				return nil, wrapParamError(err, "."+f.Name)
 			}
 			fts = append(fts, &bq.QueryParameterTypeStructTypes{
 				Name: f.Name,
@@ -222,15 +278,114 @@ func paramType(t reflect.Type) (*bq.QueryParameterType, error) {
 			})
 		}
 		return &bq.QueryParameterType{Type: "STRUCT", StructTypes: fts}, nil
+
+	case reflect.Interface:
+		return nil, fmt.Errorf("bigquery: the parameter type of interface type %s cannot be determined without a non-nil value", t)
 	}
 	return nil, fmt.Errorf("bigquery: Go type %s cannot be represented as a parameter type", t)
 }
 
+func arrayParamType(et *bq.QueryParameterType) (*bq.QueryParameterType, error) {
+	if et.Type == "ARRAY" {
+		return nil, errors.New("bigquery: arrays of arrays are not supported; wrap the inner array in a struct")
+	}
+	return &bq.QueryParameterType{Type: "ARRAY", ArrayType: et}, nil
+}
+
+// paramTypeOfValue returns the parameter type of v. Unlike paramType, it
+// resolves values held in interfaces, including array elements and struct
+// fields of interface type, to the parameter type of their dynamic type.
+func paramTypeOfValue(v reflect.Value) (*bq.QueryParameterType, error) {
+	if !v.IsValid() {
+		return nil, errors.New("bigquery: nil parameter")
+	}
+	if v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return paramType(v.Type())
+		}
+		return paramTypeOfValue(v.Elem())
+	}
+	// Use the static type when it fully determines the parameter type.
+	pt, err := paramType(v.Type())
+	if err == nil {
+		return pt, nil
+	}
+	t := v.Type()
+	switch t.Kind() {
+	case reflect.Slice, reflect.Array:
+		if v.Len() == 0 {
+			return nil, err
+		}
+		var et *bq.QueryParameterType
+		for i := 0; i < v.Len(); i++ {
+			elt, err := paramTypeOfValue(v.Index(i))
+			if err != nil {
+				return nil, wrapParamError(err, fmt.Sprintf("[%d]", i))
+			}
+			if et == nil {
+				et = elt
+			} else if !reflect.DeepEqual(et, elt) {
+				return nil, wrapParamError(fmt.Errorf("bigquery: array element type %s does not match type %s of element 0", paramTypeString(elt), paramTypeString(et)), fmt.Sprintf("[%d]", i))
+			}
+		}
+		return arrayParamType(et)
+
+	case reflect.Ptr:
+		if t.Elem().Kind() != reflect.Struct || v.IsNil() {
+			return nil, err
+		}
+		v = v.Elem()
+		t = v.Type()
+		fallthrough
+
+	case reflect.Struct:
+		fields, ferr := fieldCache.Fields(t)
+		if ferr != nil {
+			return nil, ferr
+		}
+		var fts []*bq.QueryParameterTypeStructTypes
+		for _, f := range fields {
+			ft, err := paramTypeOfValue(v.FieldByIndex(f.Index))
+			if err != nil {
+				return nil, wrapParamError(err, "."+f.Name)
+			}
+			fts = append(fts, &bq.QueryParameterTypeStructTypes{
+				Name: f.Name,
+				Type: ft,
+			})
+		}
+		return &bq.QueryParameterType{Type: "STRUCT", StructTypes: fts}, nil
+	}
+	return nil, err
+}
+
+// paramTypeString formats a parameter type in BigQuery SQL type syntax, for
+// use in error messages.
+func paramTypeString(pt *bq.QueryParameterType) string {
+	switch pt.Type {
+	case "ARRAY":
+		return "ARRAY<" + paramTypeString(pt.ArrayType) + ">"
+	case "STRUCT":
+		var fs []string
+		for _, st := range pt.StructTypes {
+			fs = append(fs, st.Name+" "+paramTypeString(st.Type))
+		}
+		return "STRUCT<" + strings.Join(fs, ", ") + ">"
+	}
+	return pt.Type
+}
+
 func paramValue(v reflect.Value) (*bq.QueryParameterValue, error) {
 	res := &bq.QueryParameterValue{}
 	if !v.IsValid() {
 		return res, errors.New("bigquery: nil parameter")
 	}
+	if v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return res, errors.New("bigquery: nil interface value; use a Null type such as NullInt64 to send NULL")
+		}
+		return paramValue(v.Elem())
+	}
 	t := v.Type()
 	switch t {
 
@@ -299,6 +454,14 @@ func paramValue(v reflect.Value) (*bq.QueryParameterValue, error) {
 		res.Value = v.Interface().(time.Time).Format(timestampFormat)
 		return res, nil
 
+	case typeOfRat, typeOfIntervalValue:
+		if v.IsNil() {
+			// A nil pointer is a NULL value.
+			res.NullFields = append(res.NullFields, "Value")
+			return res, nil
+		}
+	}
+	switch t {
 	case typeOfRat:
 		// big.Rat types don't communicate scale or precision, so we cannot
 		// disambiguate between NUMERIC and BIGNUMERIC.  For now, we'll continue
@@ -308,6 +471,9 @@ func paramValue(v reflect.Value) (*bq.QueryParameterValue, error) {
 	case typeOfIntervalValue:
 		res.Value = IntervalString(v.Interface().(*IntervalValue))
 		return res, nil
+	case typeOfGeography:
+		res.Value = v.Interface().(Geography).WKT
+		return res, nil
 	}
 	switch t.Kind() {
 	case reflect.Slice:
@@ -320,9 +486,13 @@ func paramValue(v reflect.Value) (*bq.QueryParameterValue, error) {
 	case reflect.Array:
 		var vals []*bq.QueryParameterValue
 		for i := 0; i < v.Len(); i++ {
-			val, err := paramValue(v.Index(i))
+			ev := v.Index(i)
+			if (ev.Kind() == reflect.Ptr || ev.Kind() == reflect.Interface) && ev.IsNil() {
+				return nil, wrapParamError(errors.New("bigquery: arrays cannot contain NULL elements"), fmt.Sprintf("[%d]", i))
+			}
+			val, err := paramValue(ev)
 			if err != nil {
-				return nil, err
+				return nil, wrapParamError(err, fmt.Sprintf("[%d]", i))
 			}
 			vals = append(vals, val)
 		}
@@ -350,7 +520,7 @@ func paramValue(v reflect.Value) (*bq.QueryParameterValue, error) {
 			fv := v.FieldByIndex(f.Index)
 			fp, err := paramValue(fv)
 			if err != nil {
-				return nil, err
+				return nil, wrapParamError(err, "."+f.Name)
 			}
 			res.StructValues[f.Name] = *fp
 		}
@@ -119,6 +119,7 @@ var scalarTests = []struct {
 	{&IntervalValue{Years: 1, Months: 2, Days: 3}, false, "1-2 3 0:0:0", intervalParamType, &IntervalValue{Years: 1, Months: 2, Days: 3}},
 	{NullGeography{GeographyVal: "POINT(-122.335503 47.625536)", Valid: true}, false, "POINT(-122.335503 47.625536)", geographyParamType, "POINT(-122.335503 47.625536)"},
 	{NullGeography{Valid: false}, true, "", geographyParamType, NullGeography{Valid: false}},
+	{Geography{WKT: "POINT(-122.335503 47.625536)"}, false, "POINT(-122.335503 47.625536)", geographyParamType, "POINT(-122.335503 47.625536)"},
 }
 
 type (
@@ -460,3 +461,109 @@ func TestQueryParameter_toBQ(t *testing.T) {
 		t.Fatalf("want %v, got %v", want, got)
 	}
 }
+
+func TestQueryParameterNested(t *testing.T) {
+	type item struct {
+		Price *big.Rat
+		Day   civil.Date
+		Tags  []string
+	}
+	type order struct {
+		ID    int64
+		Items []*item
+		Ship  *S2
+		Extra interface{}
+	}
+	in := QueryParameter{
+		Name: "o",
+		Value: []order{
+			{ID: 1, Items: []*item{{Price: big.NewRat(3, 2), Day: civil.Date{Year: 2022, Month: 1, Day: 2}, Tags: []string{"a"}}}, Extra: []interface{}{int64(1), 2}},
+			{ID: 2, Items: []*item{{Tags: []string{}}}, Extra: []interface{}{int64(3)}},
+		},
+	}
+	got, err := in.toBQ()
+	if err != nil {
+		t.Fatal(err)
+	}
+	itemType := &bq.QueryParameterType{
+		Type: "STRUCT",
+		StructTypes: []*bq.QueryParameterTypeStructTypes{
+			{Name: "Price", Type: numericParamType},
+			{Name: "Day", Type: dateParamType},
+			{Name: "Tags", Type: &bq.QueryParameterType{Type: "ARRAY", ArrayType: stringParamType}},
+		},
+	}
+	wantType := &bq.QueryParameterType{
+		Type: "ARRAY",
+		ArrayType: &bq.QueryParameterType{
+			Type: "STRUCT",
+			StructTypes: []*bq.QueryParameterTypeStructTypes{
+				{Name: "ID", Type: int64ParamType},
+				{Name: "Items", Type: &bq.QueryParameterType{Type: "ARRAY", ArrayType: itemType}},
+				{Name: "Ship", Type: s1ParamType.StructTypes[1].Type},
+				{Name: "Extra", Type: &bq.QueryParameterType{Type: "ARRAY", ArrayType: int64ParamType}},
+			},
+		},
+	}
+	if diff := testutil.Diff(got.ParameterType, wantType); diff != "" {
+		t.Errorf("type: -got, +want:\n%s", diff)
+	}
+	first := got.ParameterValue.ArrayValues[0].StructValues["Items"].ArrayValues[0].StructValues
+	if got, want := first["Price"].Value, "1.500000000"; got != want {
+		t.Errorf("Price: got %q, want %q", got, want)
+	}
+	if got, want := first["Day"].Value, "2022-01-02"; got != want {
+		t.Errorf("Day: got %q, want %q", got, want)
+	}
+	second := got.ParameterValue.ArrayValues[1].StructValues["Items"].ArrayValues[0].StructValues
+	if got, want := second["Price"].NullFields, []string{"Value"}; !cmp.Equal(got, want) {
+		t.Errorf("nil Price: got NullFields %v, want %v", got, want)
+	}
+}
+
+func TestQueryParameterNestedErrors(t *testing.T) {
+	type inner struct {
+		U uint
+	}
+	type outer struct {
+		In []inner
+	}
+	for _, test := range []struct {
+		val  interface{}
+		want string
+	}{
+		{
+			outer{In: []inner{{}}},
+			"bigquery: query parameter value p.In[0].U: Go type uint cannot be represented as a parameter type",
+		},
+		{
+			outer{},
+			"bigquery: query parameter value p.In[].U: Go type uint cannot be represented as a parameter type",
+		},
+		{
+			[]interface{}{int64(1), "x"},
+			"bigquery: query parameter value p[1]: array element type STRING does not match type INT64 of element 0",
+		},
+		{
+			[]*S2{{D: "a"}, nil},
+			"bigquery: query parameter value p[1]: arrays cannot contain NULL elements",
+		},
+		{
+			[][]int{{1}},
+			"bigquery: query parameter value p: arrays of arrays are not supported; wrap the inner array in a struct",
+		},
+		{
+			struct{ X interface{} }{},
+			"bigquery: query parameter value p.X: nil interface value; use a Null type such as NullInt64 to send NULL",
+		},
+	} {
+		_, err := QueryParameter{Name: "p", Value: test.val}.toBQ()
+		if err == nil {
+			t.Errorf("%#v: got nil error, want error", test.val)
+			continue
+		}
+		if err.Error() != test.want {
+			t.Errorf("%#v:\ngot  %s\nwant %s", test.val, err, test.want)
+		}
+	}
+}
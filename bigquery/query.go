@@ -324,6 +324,17 @@ const (
 type Query struct {
 	JobIDConfig
 	QueryConfig
+
+	// ResultCache, if set, is consulted by Read before the query is run. If
+	// it holds the results of an equivalent query, they are returned without
+	// contacting BigQuery; otherwise, the results are stored in the cache once
+	// they have been read in full. Queries with a destination table, table
+	// definitions, or session settings are never cached.
+	//
+	// Only read-only queries should be issued with a ResultCache set, since
+	// a cached result means that the query text is not executed again.
+	ResultCache QueryResultCache
+
 	client *Client
 }
 
@@ -373,6 +384,19 @@ func (q *Query) Read(ctx context.Context) (it *RowIterator, err error) {
 	}
 	ctx = trace.StartSpan(ctx, "cloud.google.com/go/bigquery.Query.Run")
 	defer func() { trace.EndSpan(ctx, err) }()
+	if q.ResultCache != nil {
+		if key, err := q.resultCacheKey(); err == nil {
+			if res, ok := q.ResultCache.Get(key); ok {
+				return newRowIterator(ctx, nil, cachedPageFetcher(res)), nil
+			}
+			defer func() {
+				if it != nil {
+					rec := &resultRecorder{cache: q.ResultCache, key: key, maxBytes: q.ResultCache.MaxResultBytes()}
+					it.pf = rec.wrap(it.pf)
+				}
+			}()
+		}
+	}
 	queryRequest, err := q.probeFastPath()
 	if err != nil {
 		// Any error means we fallback to the older mechanism.
@@ -489,7 +489,7 @@ func TestProbeFastPath(t *testing.T) {
 		},
 	}
 	for i, tc := range testCases {
-		in := &Query{tc.inJobCfg, tc.inCfg, c}
+		in := &Query{JobIDConfig: tc.inJobCfg, QueryConfig: tc.inCfg, client: c}
 		gotReq, err := in.probeFastPath()
 		if tc.wantErr && err == nil {
 			t.Errorf("case %d wanted error, got nil", i)
@@ -0,0 +1,179 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bigquery
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// QuoteIdentifier returns name as a quoted GoogleSQL identifier, suitable for
+// use as a column, table or dataset name in query text. It returns an error
+// if name is empty or contains control characters.
+func QuoteIdentifier(name string) (string, error) {
+	if name == "" {
+		return "", errors.New("bigquery: empty identifier")
+	}
+	var sb strings.Builder
+	sb.WriteByte('`')
+	for _, r := range name {
+		if unicode.IsControl(r) {
+			return "", fmt.Errorf("bigquery: identifier %q contains control character %U", name, r)
+		}
+		if r == '`' || r == '\\' {
+			sb.WriteByte('\\')
+		}
+		sb.WriteRune(r)
+	}
+	sb.WriteByte('`')
+	return sb.String(), nil
+}
+
+var paramNameRE = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// A QueryBuilder assembles the text of a GoogleSQL query from trusted SQL
+// fragments, validated identifiers and values. Values are never written into
+// the query text; each is passed as a named query parameter instead. This
+// makes it safe to build queries from user input, as long as that input is
+// only supplied through Ident, Table and Param.
+//
+// The zero value is an empty QueryBuilder ready to use.
+//
+// Methods return the builder, so calls may be chained. The first error
+// encountered is reported by Query or Build.
+//
+// Example:
+//
+//	b := bigquery.NewQueryBuilder().
+//		SQL("SELECT ").Ident(column).
+//		SQL(" FROM ").Table(table).
+//		SQL(" WHERE name = ").Param(name)
+//	q, err := b.Query(client)
+type QueryBuilder struct {
+	sb     strings.Builder
+	params []QueryParameter
+	names  map[string]bool
+	err    error
+}
+
+// NewQueryBuilder returns an empty QueryBuilder.
+func NewQueryBuilder() *QueryBuilder {
+	return &QueryBuilder{}
+}
+
+// SQL appends sql to the query text verbatim. sql must not contain untrusted
+// input; use Ident, Table or Param for values that are not constants.
+func (b *QueryBuilder) SQL(sql string) *QueryBuilder {
+	b.sb.WriteString(sql)
+	return b
+}
+
+// Ident appends a quoted identifier path to the query text, such as a column
+// name or a dotted path of a dataset and table. Each element of path is
+// quoted separately.
+func (b *QueryBuilder) Ident(path ...string) *QueryBuilder {
+	if len(path) == 0 {
+		b.setErr(errors.New("bigquery: empty identifier path"))
+		return b
+	}
+	for i, p := range path {
+		q, err := QuoteIdentifier(p)
+		if err != nil {
+			b.setErr(err)
+			return b
+		}
+		if i > 0 {
+			b.sb.WriteByte('.')
+		}
+		b.sb.WriteString(q)
+	}
+	return b
+}
+
+// Table appends the fully qualified, quoted name of t to the query text.
+func (b *QueryBuilder) Table(t *Table) *QueryBuilder {
+	return b.Ident(t.ProjectID, t.DatasetID, t.TableID)
+}
+
+// TableAt appends the fully qualified, quoted name of t to the query text,
+// followed by a FOR SYSTEM_TIME AS OF clause so that the query reads the
+// table as it was at time at. The time is passed as a query parameter.
+func (b *QueryBuilder) TableAt(t *Table, at time.Time) *QueryBuilder {
+	return b.Table(t).SQL(" FOR SYSTEM_TIME AS OF ").Param(at)
+}
+
+// Param appends a reference to a new query parameter whose value is v. The
+// parameter is given a generated name. See QueryParameter for the types v may
+// have.
+func (b *QueryBuilder) Param(v interface{}) *QueryBuilder {
+	name := fmt.Sprintf("p%d", len(b.params))
+	for b.names[name] {
+		name = "_" + name
+	}
+	return b.NamedParam(name, v)
+}
+
+// NamedParam appends a reference to the query parameter name, whose value is
+// v. Referring to the same name more than once is an error; to reuse a value,
+// write its reference with SQL("@name").
+func (b *QueryBuilder) NamedParam(name string, v interface{}) *QueryBuilder {
+	if !paramNameRE.MatchString(name) {
+		b.setErr(fmt.Errorf("bigquery: invalid query parameter name %q", name))
+		return b
+	}
+	if b.names[name] {
+		b.setErr(fmt.Errorf("bigquery: duplicate query parameter name %q", name))
+		return b
+	}
+	if b.names == nil {
+		b.names = map[string]bool{}
+	}
+	b.names[name] = true
+	b.params = append(b.params, QueryParameter{Name: name, Value: v})
+	b.sb.WriteByte('@')
+	b.sb.WriteString(name)
+	return b
+}
+
+// Build returns the query text and parameters, or the first error
+// encountered while building the query.
+func (b *QueryBuilder) Build() (string, []QueryParameter, error) {
+	if b.err != nil {
+		return "", nil, b.err
+	}
+	return b.sb.String(), append([]QueryParameter(nil), b.params...), nil
+}
+
+// Query returns a Query for the built query text and parameters, to be run
+// by c. The returned Query may be further configured before it is run.
+func (b *QueryBuilder) Query(c *Client) (*Query, error) {
+	sql, params, err := b.Build()
+	if err != nil {
+		return nil, err
+	}
+	q := c.Query(sql)
+	q.Parameters = params
+	return q, nil
+}
+
+func (b *QueryBuilder) setErr(err error) {
+	if b.err == nil {
+		b.err = err
+	}
+}
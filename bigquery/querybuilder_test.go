@@ -0,0 +1,131 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bigquery
+
+import (
+	"testing"
+	"time"
+
+	"cloud.google.com/go/internal/testutil"
+)
+
+func TestQuoteIdentifier(t *testing.T) {
+	for _, tc := range []struct {
+		in      string
+		want    string
+		wantErr bool
+	}{
+		{in: "col", want: "`col`"},
+		{in: "my-project", want: "`my-project`"},
+		{in: "a`b", want: "`a\\`b`"},
+		{in: `a\b`, want: "`a\\\\b`"},
+		{in: "", wantErr: true},
+		{in: "a\nb", wantErr: true},
+	} {
+		got, err := QuoteIdentifier(tc.in)
+		if (err != nil) != tc.wantErr {
+			t.Errorf("QuoteIdentifier(%q): got error %v, wantErr %t", tc.in, err, tc.wantErr)
+			continue
+		}
+		if got != tc.want {
+			t.Errorf("QuoteIdentifier(%q) = %q, want %q", tc.in, got, tc.want)
+		}
+	}
+}
+
+func TestQueryBuilder(t *testing.T) {
+	c := &Client{projectID: "client-project"}
+	q, err := NewQueryBuilder().
+		SQL("SELECT ").Ident("t", "name").
+		SQL(" FROM ").Table(c.DatasetInProject("google.com:p", "d").Table("t")).
+		SQL(" WHERE name = ").Param("x' OR 1=1 --").
+		SQL(" AND age > ").NamedParam("age", 21).
+		SQL(" LIMIT ").Param(10).
+		Query(c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantSQL := "SELECT `t`.`name` FROM `google.com:p`.`d`.`t` WHERE name = @p0 AND age > @age LIMIT @p2"
+	if q.Q != wantSQL {
+		t.Errorf("got SQL\n%s\nwant\n%s", q.Q, wantSQL)
+	}
+	wantParams := []QueryParameter{
+		{Name: "p0", Value: "x' OR 1=1 --"},
+		{Name: "age", Value: 21},
+		{Name: "p2", Value: 10},
+	}
+	if diff := testutil.Diff(q.Parameters, wantParams); diff != "" {
+		t.Errorf("parameters: got=-, want=+:\n%s", diff)
+	}
+}
+
+func TestQueryBuilderErrors(t *testing.T) {
+	for _, tc := range []struct {
+		desc string
+		b    *QueryBuilder
+	}{
+		{"empty identifier", NewQueryBuilder().SQL("SELECT ").Ident("")},
+		{"empty path", NewQueryBuilder().Ident()},
+		{"control character", NewQueryBuilder().Ident("a\x00")},
+		{"bad parameter name", NewQueryBuilder().NamedParam("a b", 1)},
+		{"duplicate parameter", NewQueryBuilder().NamedParam("a", 1).NamedParam("a", 2)},
+	} {
+		if _, _, err := tc.b.Build(); err == nil {
+			t.Errorf("%s: got nil error, want error", tc.desc)
+		}
+	}
+}
+
+func TestQueryBuilderGeneratedNames(t *testing.T) {
+	sql, params, err := NewQueryBuilder().NamedParam("p1", 1).SQL(", ").Param(2).Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "@p1, @_p1"; sql != want {
+		t.Errorf("got %q, want %q", sql, want)
+	}
+	if len(params) != 2 || params[1].Name != "_p1" {
+		t.Errorf("got params %+v", params)
+	}
+}
+
+func TestQueryBuilderZeroValue(t *testing.T) {
+	var b QueryBuilder
+	sql, params, err := b.SQL("SELECT ").Param(1).Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "SELECT @p0"; sql != want {
+		t.Errorf("got %q, want %q", sql, want)
+	}
+	if len(params) != 1 {
+		t.Errorf("got params %+v", params)
+	}
+}
+
+func TestQueryBuilderTableAt(t *testing.T) {
+	c := &Client{projectID: "client-project"}
+	at := time.Date(2022, 6, 1, 12, 0, 0, 0, time.UTC)
+	q, err := c.Dataset("d").Table("t").readAtQuery(at)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "SELECT * FROM `client-project`.`d`.`t` FOR SYSTEM_TIME AS OF @p0"; q.Q != want {
+		t.Errorf("got SQL %q, want %q", q.Q, want)
+	}
+	if diff := testutil.Diff(q.Parameters, []QueryParameter{{Name: "p0", Value: at}}); diff != "" {
+		t.Errorf("parameters: got=-, want=+:\n%s", diff)
+	}
+}
@@ -0,0 +1,335 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bigquery
+
+import (
+	"container/list"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+)
+
+// A QueryResultCache stores complete query results on the client, so that
+// repeated reads of the same query can be served without contacting BigQuery.
+//
+// Results are keyed by a fingerprint of the query text (with insignificant
+// whitespace removed), its parameters and the settings that affect how the
+// query is resolved. Implementations must be safe for concurrent use.
+//
+// See Query.ResultCache and NewMemoryQueryResultCache.
+type QueryResultCache interface {
+	// Get returns the result stored under key, if any.
+	Get(key string) (*CachedQueryResult, bool)
+
+	// Put stores r under key. Put may decline to store r, for example
+	// because it is too large.
+	Put(key string, r *CachedQueryResult)
+
+	// MaxResultBytes reports the approximate size, in bytes, of the largest
+	// result the cache will store. Results that grow larger while they are
+	// being read are not offered to Put. Zero means no limit.
+	MaxResultBytes() int64
+}
+
+// CachedQueryResult is a complete set of query results held in a
+// QueryResultCache.
+type CachedQueryResult struct {
+	Schema    Schema
+	Rows      [][]Value
+	TotalRows uint64
+
+	// CreationTime is the time at which the result was read from BigQuery.
+	CreationTime time.Time
+
+	// Size is the approximate in-memory size of Rows, in bytes.
+	Size int64
+}
+
+// MemoryQueryResultCache is an in-memory QueryResultCache. Entries expire
+// after a fixed time-to-live, and the least recently used entries are evicted
+// to keep the total size of cached results within a budget.
+type MemoryQueryResultCache struct {
+	ttl      time.Duration
+	maxBytes int64
+
+	mu      sync.Mutex
+	size    int64
+	lru     *list.List // of *memoryCacheEntry, most recently used first
+	entries map[string]*list.Element
+}
+
+type memoryCacheEntry struct {
+	key    string
+	result *CachedQueryResult
+}
+
+// NewMemoryQueryResultCache returns an in-memory cache whose entries expire
+// after ttl, and which holds at most maxBytes of results in total. A ttl of
+// zero means entries do not expire; a maxBytes of zero means the size of the
+// cache is not limited.
+func NewMemoryQueryResultCache(ttl time.Duration, maxBytes int64) *MemoryQueryResultCache {
+	return &MemoryQueryResultCache{
+		ttl:      ttl,
+		maxBytes: maxBytes,
+		lru:      list.New(),
+		entries:  map[string]*list.Element{},
+	}
+}
+
+// Get implements QueryResultCache.
+func (c *MemoryQueryResultCache) Get(key string) (*CachedQueryResult, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	e, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+	r := e.Value.(*memoryCacheEntry).result
+	if c.ttl > 0 && time.Since(r.CreationTime) > c.ttl {
+		c.remove(e)
+		return nil, false
+	}
+	c.lru.MoveToFront(e)
+	return r, true
+}
+
+// Put implements QueryResultCache.
+func (c *MemoryQueryResultCache) Put(key string, r *CachedQueryResult) {
+	if c.maxBytes > 0 && r.Size > c.maxBytes {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if e, ok := c.entries[key]; ok {
+		c.remove(e)
+	}
+	c.entries[key] = c.lru.PushFront(&memoryCacheEntry{key: key, result: r})
+	c.size += r.Size
+	for c.maxBytes > 0 && c.size > c.maxBytes {
+		c.remove(c.lru.Back())
+	}
+}
+
+// MaxResultBytes implements QueryResultCache.
+func (c *MemoryQueryResultCache) MaxResultBytes() int64 {
+	return c.maxBytes
+}
+
+// Len returns the number of results in the cache.
+func (c *MemoryQueryResultCache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.lru.Len()
+}
+
+func (c *MemoryQueryResultCache) remove(e *list.Element) {
+	ent := c.lru.Remove(e).(*memoryCacheEntry)
+	delete(c.entries, ent.key)
+	c.size -= ent.result.Size
+}
+
+// resultCacheKey returns the key under which the results of q are cached.
+// It returns an error if the results of q should not be cached.
+func (q *Query) resultCacheKey() (string, error) {
+	qc := &q.QueryConfig
+	if qc.Dst != nil || qc.CreateSession || len(qc.ConnectionProperties) > 0 || len(qc.TableDefinitions) > 0 {
+		return "", errors.New("bigquery: query results are not cacheable")
+	}
+	loc := q.Location
+	if loc == "" {
+		loc = q.client.Location
+	}
+	fp := struct {
+		Project          string
+		Location         string
+		Query            string
+		DefaultProjectID string
+		DefaultDatasetID string
+		UseLegacySQL     bool
+		Parameters       []interface{}
+	}{
+		Project:          q.client.projectID,
+		Location:         loc,
+		Query:            normalizeSQL(qc.Q),
+		DefaultProjectID: qc.DefaultProjectID,
+		DefaultDatasetID: qc.DefaultDatasetID,
+		UseLegacySQL:     qc.UseLegacySQL,
+	}
+	for _, p := range qc.Parameters {
+		qp, err := p.toBQ()
+		if err != nil {
+			return "", err
+		}
+		fp.Parameters = append(fp.Parameters, qp)
+	}
+	b, err := json.Marshal(fp)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(b)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// normalizeSQL collapses each run of whitespace outside of quoted strings and
+// identifiers to a single space, and trims leading and trailing whitespace.
+func normalizeSQL(sql string) string {
+	var sb strings.Builder
+	var quote rune
+	space := false
+	escaped := false
+	for _, r := range strings.TrimSpace(sql) {
+		switch {
+		case quote != 0:
+			switch {
+			case escaped:
+				escaped = false
+			case r == '\\':
+				escaped = true
+			case r == quote:
+				quote = 0
+			}
+		case unicode.IsSpace(r):
+			space = true
+			continue
+		case r == '\'' || r == '"' || r == '`':
+			quote = r
+		}
+		if space {
+			sb.WriteByte(' ')
+			space = false
+		}
+		sb.WriteRune(r)
+	}
+	return sb.String()
+}
+
+// cachedPageFetcher serves pages of rows from a cached result.
+func cachedPageFetcher(r *CachedQueryResult) pageFetcher {
+	return func(ctx context.Context, _ *rowSource, _ Schema, startIndex uint64, pageSize int64, pageToken string) (*fetchPageResult, error) {
+		start := startIndex
+		if pageToken != "" {
+			n, err := strconv.ParseUint(pageToken, 10, 64)
+			if err != nil {
+				return nil, errors.New("bigquery: invalid page token for cached query result")
+			}
+			start = n
+		}
+		if start > uint64(len(r.Rows)) {
+			start = uint64(len(r.Rows))
+		}
+		end := uint64(len(r.Rows))
+		if pageSize > 0 && start+uint64(pageSize) < end {
+			end = start + uint64(pageSize)
+		}
+		// Copy the rows, so that callers cannot modify the cached result.
+		rows := make([][]Value, 0, end-start)
+		for _, row := range r.Rows[start:end] {
+			rows = append(rows, append([]Value(nil), row...))
+		}
+		var next string
+		if end < uint64(len(r.Rows)) {
+			next = strconv.FormatUint(end, 10)
+		}
+		return &fetchPageResult{
+			pageToken: next,
+			rows:      rows,
+			totalRows: r.TotalRows,
+			schema:    r.Schema,
+		}, nil
+	}
+}
+
+// resultRecorder collects the pages read by a RowIterator, and stores the
+// complete result in a cache once the last page has been read. Recording is
+// abandoned if the iterator does not read the result in order from the start,
+// or if the result grows too large for the cache.
+type resultRecorder struct {
+	cache    QueryResultCache
+	key      string
+	maxBytes int64
+
+	result    CachedQueryResult
+	nextToken string
+	done      bool
+}
+
+func (r *resultRecorder) wrap(pf pageFetcher) pageFetcher {
+	return func(ctx context.Context, src *rowSource, schema Schema, startIndex uint64, pageSize int64, pageToken string) (*fetchPageResult, error) {
+		res, err := pf(ctx, src, schema, startIndex, pageSize, pageToken)
+		if err != nil || r.done {
+			return res, err
+		}
+		if pageToken != r.nextToken || (pageToken == "" && startIndex != 0) {
+			r.abandon()
+			return res, nil
+		}
+		if r.result.Schema == nil {
+			r.result.Schema = res.schema
+		}
+		r.result.TotalRows = res.totalRows
+		r.result.Rows = append(r.result.Rows, res.rows...)
+		for _, row := range res.rows {
+			r.result.Size += approxValueSize(row)
+		}
+		if r.maxBytes > 0 && r.result.Size > r.maxBytes {
+			r.abandon()
+			return res, nil
+		}
+		r.nextToken = res.pageToken
+		if res.pageToken == "" {
+			r.done = true
+			r.result.CreationTime = time.Now()
+			result := r.result
+			r.cache.Put(r.key, &result)
+		}
+		return res, nil
+	}
+}
+
+func (r *resultRecorder) abandon() {
+	r.done = true
+	r.result = CachedQueryResult{}
+}
+
+// approxValueSize estimates the memory held by v, in bytes.
+func approxValueSize(v Value) int64 {
+	const wordSize = 8
+	switch x := v.(type) {
+	case nil:
+		return wordSize
+	case string:
+		return 2*wordSize + int64(len(x))
+	case []byte:
+		return 3*wordSize + int64(len(x))
+	case *big.Rat:
+		return 4*wordSize + int64(len(x.Num().Bits())+len(x.Denom().Bits()))*wordSize
+	case []Value:
+		n := int64(3 * wordSize)
+		for _, e := range x {
+			n += approxValueSize(e)
+		}
+		return n
+	default:
+		return 3 * wordSize
+	}
+}
@@ -0,0 +1,173 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bigquery
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"cloud.google.com/go/internal/testutil"
+	"google.golang.org/api/iterator"
+)
+
+func TestNormalizeSQL(t *testing.T) {
+	for _, tc := range []struct {
+		in, want string
+	}{
+		{"SELECT 1", "SELECT 1"},
+		{"  SELECT\n\t1  \n", "SELECT 1"},
+		{"SELECT  'a  b'  FROM\tt", "SELECT 'a  b' FROM t"},
+		{"SELECT \"x\\\"  y\"   ,  `my  col`", "SELECT \"x\\\"  y\" , `my  col`"},
+	} {
+		if got := normalizeSQL(tc.in); got != tc.want {
+			t.Errorf("normalizeSQL(%q) = %q, want %q", tc.in, got, tc.want)
+		}
+	}
+}
+
+func TestResultCacheKey(t *testing.T) {
+	c := &Client{projectID: "p"}
+	key := func(q *Query) string {
+		t.Helper()
+		k, err := q.resultCacheKey()
+		if err != nil {
+			t.Fatal(err)
+		}
+		return k
+	}
+	base := c.Query("SELECT @x")
+	base.Parameters = []QueryParameter{{Name: "x", Value: 1}}
+
+	same := c.Query("  SELECT   @x ")
+	same.Parameters = []QueryParameter{{Name: "x", Value: 1}}
+	if key(base) != key(same) {
+		t.Error("queries differing only in whitespace have different keys")
+	}
+
+	otherParam := c.Query("SELECT @x")
+	otherParam.Parameters = []QueryParameter{{Name: "x", Value: 2}}
+	otherDataset := c.Query("SELECT @x")
+	otherDataset.Parameters = []QueryParameter{{Name: "x", Value: 1}}
+	otherDataset.DefaultDatasetID = "d"
+	for _, q := range []*Query{otherParam, otherDataset, c.Query("SELECT @y")} {
+		if key(base) == key(q) {
+			t.Errorf("query %+v has the same key as %+v", q.QueryConfig, base.QueryConfig)
+		}
+	}
+
+	withDst := c.Query("SELECT 1")
+	withDst.Dst = c.Dataset("d").Table("t")
+	if _, err := withDst.resultCacheKey(); err == nil {
+		t.Error("query with destination table: got nil error, want error")
+	}
+}
+
+func TestMemoryQueryResultCache(t *testing.T) {
+	c := NewMemoryQueryResultCache(0, 100)
+	c.Put("a", &CachedQueryResult{Size: 40})
+	c.Put("b", &CachedQueryResult{Size: 40})
+	if _, ok := c.Get("a"); !ok {
+		t.Fatal("a: not found")
+	}
+	// Adding c exceeds the budget, and b is the least recently used.
+	c.Put("c", &CachedQueryResult{Size: 40})
+	if _, ok := c.Get("b"); ok {
+		t.Error("b: found, want evicted")
+	}
+	if got, want := c.Len(), 2; got != want {
+		t.Errorf("Len: got %d, want %d", got, want)
+	}
+	c.Put("big", &CachedQueryResult{Size: 101})
+	if _, ok := c.Get("big"); ok {
+		t.Error("big: found, want not stored")
+	}
+
+	c = NewMemoryQueryResultCache(time.Minute, 0)
+	c.Put("old", &CachedQueryResult{CreationTime: time.Now().Add(-2 * time.Minute)})
+	c.Put("new", &CachedQueryResult{CreationTime: time.Now()})
+	if _, ok := c.Get("old"); ok {
+		t.Error("old: found, want expired")
+	}
+	if _, ok := c.Get("new"); !ok {
+		t.Error("new: not found")
+	}
+}
+
+func TestResultRecorder(t *testing.T) {
+	schema := Schema{{Name: "s", Type: StringFieldType}}
+	pages := map[string]*fetchPageResult{
+		"":  {pageToken: "a", rows: [][]Value{{"x"}, {"y"}}, totalRows: 3, schema: schema},
+		"a": {pageToken: "", rows: [][]Value{{"z"}}, totalRows: 3, schema: schema},
+	}
+	pf := func(_ context.Context, _ *rowSource, _ Schema, _ uint64, _ int64, pageToken string) (*fetchPageResult, error) {
+		return pages[pageToken], nil
+	}
+	readAll := func(it *RowIterator) [][]Value {
+		var got [][]Value
+		for {
+			var row []Value
+			err := it.Next(&row)
+			if err == iterator.Done {
+				return got
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			got = append(got, row)
+		}
+	}
+	want := [][]Value{{"x"}, {"y"}, {"z"}}
+
+	cache := NewMemoryQueryResultCache(0, 0)
+	rec := &resultRecorder{cache: cache, key: "k"}
+	if diff := testutil.Diff(readAll(newRowIterator(context.Background(), nil, rec.wrap(pf))), want); diff != "" {
+		t.Fatalf("got=-, want=+:\n%s", diff)
+	}
+	res, ok := cache.Get("k")
+	if !ok {
+		t.Fatal("result not cached")
+	}
+	if res.TotalRows != 3 || res.Size == 0 {
+		t.Errorf("got TotalRows=%d, Size=%d", res.TotalRows, res.Size)
+	}
+
+	it := newRowIterator(context.Background(), nil, cachedPageFetcher(res))
+	it.PageInfo().MaxSize = 2
+	if diff := testutil.Diff(readAll(it), want); diff != "" {
+		t.Errorf("cached: got=-, want=+:\n%s", diff)
+	}
+	if diff := testutil.Diff(it.Schema, schema); diff != "" {
+		t.Errorf("cached schema: got=-, want=+:\n%s", diff)
+	}
+
+	// Results read from a start index are not cached.
+	cache = NewMemoryQueryResultCache(0, 0)
+	rec = &resultRecorder{cache: cache, key: "k"}
+	it = newRowIterator(context.Background(), nil, rec.wrap(pf))
+	it.StartIndex = 1
+	readAll(it)
+	if cache.Len() != 0 {
+		t.Error("result read from start index was cached")
+	}
+
+	// Results larger than the cache allows are not cached.
+	cache = NewMemoryQueryResultCache(0, 1)
+	rec = &resultRecorder{cache: cache, key: "k", maxBytes: cache.MaxResultBytes()}
+	readAll(newRowIterator(context.Background(), nil, rec.wrap(pf)))
+	if cache.Len() != 0 {
+		t.Error("oversized result was cached")
+	}
+}
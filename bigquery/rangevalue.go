@@ -0,0 +1,126 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bigquery
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"cloud.google.com/go/civil"
+)
+
+// unboundedRangeSentinel is the literal used in the wire format for a
+// missing start or end bound.
+const unboundedRangeSentinel = "UNBOUNDED"
+
+// RangeValue is a Go type for representing BigQuery RANGE values, which
+// describe a contiguous half-open interval [Start, End) of DATE, DATETIME
+// or TIMESTAMP values.
+//
+// Start and End have type civil.Date, civil.DateTime or time.Time depending
+// on the element type of the range. A nil bound is unbounded.
+//
+// More information about BigQuery RANGE types can be found at:
+// https://cloud.google.com/bigquery/docs/reference/standard-sql/data-types#range_type
+//
+// RANGE support is limited to reading values and saving them as rows. The
+// BigQuery API version used by this package has no way to describe the
+// element type of a RANGE, so a Schema with a RangeFieldType field can't be
+// used to create or update a table, and a *RangeValue can't be used as a
+// query parameter. Pass the range as a STRING parameter and CAST it to RANGE
+// in the query instead.
+//
+// RangeValue is EXPERIMENTAL and subject to change or removal without notice.
+type RangeValue struct {
+	// The inclusive start of the range, or nil if the range has no lower bound.
+	Start Value
+	// The exclusive end of the range, or nil if the range has no upper bound.
+	End Value
+}
+
+// String returns the string representation of the range in the format
+// accepted by BigQuery SQL, e.g. "[2022-01-01, UNBOUNDED)".
+func (rv *RangeValue) String() string {
+	return fmt.Sprintf("[%s, %s)", rangeBoundString(rv.Start), rangeBoundString(rv.End))
+}
+
+// RangeString returns a string representing a *RangeValue in a format compatible with
+// BigQuery SQL.
+func RangeString(rv *RangeValue) string {
+	return rv.String()
+}
+
+func rangeBoundString(v Value) string {
+	switch v := v.(type) {
+	case nil:
+		return unboundedRangeSentinel
+	case civil.Date:
+		return v.String()
+	case civil.DateTime:
+		return CivilDateTimeString(v)
+	case time.Time:
+		return v.Format(timestampFormat)
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// ParseRange parses the string representation of a RANGE value, as returned
+// by BigQuery, into a *RangeValue. The element type of each bound is
+// determined from its format.
+func ParseRange(s string) (*RangeValue, error) {
+	trimmed := strings.TrimSpace(s)
+	if !strings.HasPrefix(trimmed, "[") || !strings.HasSuffix(trimmed, ")") {
+		return nil, fmt.Errorf("bigquery: invalid RANGE value %q", s)
+	}
+	parts := strings.Split(trimmed[1:len(trimmed)-1], ",")
+	if len(parts) != 2 {
+		return nil, fmt.Errorf("bigquery: invalid RANGE value %q", s)
+	}
+	start, err := parseRangeBound(parts[0])
+	if err != nil {
+		return nil, fmt.Errorf("bigquery: invalid RANGE value %q: %v", s, err)
+	}
+	end, err := parseRangeBound(parts[1])
+	if err != nil {
+		return nil, fmt.Errorf("bigquery: invalid RANGE value %q: %v", s, err)
+	}
+	return &RangeValue{Start: start, End: end}, nil
+}
+
+// parseRangeBound parses a single bound of a RANGE value. DATE bounds are
+// formatted as YYYY-MM-DD, DATETIME bounds as YYYY-MM-DD[T ]HH:MM:SS[.F] and
+// TIMESTAMP bounds as integer microseconds since the Unix epoch.
+func parseRangeBound(s string) (Value, error) {
+	s = strings.TrimSpace(s)
+	if strings.EqualFold(s, unboundedRangeSentinel) || strings.EqualFold(s, "NULL") {
+		return nil, nil
+	}
+	if micros, err := strconv.ParseInt(s, 10, 64); err == nil {
+		return time.Unix(micros/1e6, (micros%1e6)*1e3).UTC(), nil
+	}
+	if !strings.ContainsAny(s, "T :") {
+		return civil.ParseDate(s)
+	}
+	if dt, err := civil.ParseDateTime(strings.Replace(s, " ", "T", 1)); err == nil {
+		return dt, nil
+	}
+	if t, err := time.Parse(timestampFormat, s); err == nil {
+		return t, nil
+	}
+	return time.Parse(time.RFC3339Nano, s)
+}
@@ -0,0 +1,124 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bigquery
+
+import (
+	"testing"
+	"time"
+
+	"cloud.google.com/go/civil"
+	"cloud.google.com/go/internal/testutil"
+)
+
+func TestParseRange(t *testing.T) {
+	testcases := []struct {
+		in      string
+		want    *RangeValue
+		wantStr string
+	}{
+		{
+			in:      "[2022-01-01, 2022-12-31)",
+			want:    &RangeValue{Start: civil.Date{Year: 2022, Month: 1, Day: 1}, End: civil.Date{Year: 2022, Month: 12, Day: 31}},
+			wantStr: "[2022-01-01, 2022-12-31)",
+		},
+		{
+			in:      "[UNBOUNDED, 2022-12-31)",
+			want:    &RangeValue{End: civil.Date{Year: 2022, Month: 12, Day: 31}},
+			wantStr: "[UNBOUNDED, 2022-12-31)",
+		},
+		{
+			in:      "[2022-01-01T12:30:00, UNBOUNDED)",
+			want:    &RangeValue{Start: civil.DateTime{Date: civil.Date{Year: 2022, Month: 1, Day: 1}, Time: civil.Time{Hour: 12, Minute: 30}}},
+			wantStr: "[2022-01-01 12:30:00, UNBOUNDED)",
+		},
+		{
+			in: "[1640995200000000, 1641081600500000)",
+			want: &RangeValue{
+				Start: time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC),
+				End:   time.Date(2022, 1, 2, 0, 0, 0, 500000000, time.UTC),
+			},
+			wantStr: "[2022-01-01 00:00:00+00:00, 2022-01-02 00:00:00.5+00:00)",
+		},
+		{
+			in:      "[UNBOUNDED, UNBOUNDED)",
+			want:    &RangeValue{},
+			wantStr: "[UNBOUNDED, UNBOUNDED)",
+		},
+	}
+	for _, tc := range testcases {
+		got, err := ParseRange(tc.in)
+		if err != nil {
+			t.Errorf("ParseRange(%q) error: %v", tc.in, err)
+			continue
+		}
+		if diff := testutil.Diff(got, tc.want); diff != "" {
+			t.Errorf("ParseRange(%q): -got, +want:\n%s", tc.in, diff)
+		}
+		if s := got.String(); s != tc.wantStr {
+			t.Errorf("String() for %q: got %q, want %q", tc.in, s, tc.wantStr)
+		}
+	}
+
+	for _, in := range []string{"", "2022-01-01, 2022-12-31", "[2022-01-01)", "[foo, bar)", "[2022-01-01, 2022-12-31]"} {
+		if _, err := ParseRange(in); err == nil {
+			t.Errorf("ParseRange(%q): got nil error, want error", in)
+		}
+	}
+}
+
+func TestRangeValueStruct(t *testing.T) {
+	schema := Schema{{Name: "R", Type: RangeFieldType}}
+	type rangeStruct struct {
+		R *RangeValue
+	}
+	val, err := convertBasicType("[2022-01-01, UNBOUNDED)", RangeFieldType)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got rangeStruct
+	mustLoad(t, &got, schema, []Value{val})
+	want := rangeStruct{R: &RangeValue{Start: civil.Date{Year: 2022, Month: 1, Day: 1}}}
+	if diff := testutil.Diff(got, want); diff != "" {
+		t.Errorf("load: -got, +want:\n%s", diff)
+	}
+
+	saved, _, err := (&StructSaver{Struct: want, Schema: schema}).Save()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := testutil.Diff(saved, map[string]Value{"R": "[2022-01-01, UNBOUNDED)"}); diff != "" {
+		t.Errorf("save: -got, +want:\n%s", diff)
+	}
+
+	if _, err := (QueryParameter{Value: &RangeValue{}}).toBQ(); err == nil {
+		t.Error("RANGE query parameter: got nil error, want error")
+	}
+}
+
+func TestRangeValueRepeatedSave(t *testing.T) {
+	schema := Schema{{Name: "R", Type: RangeFieldType, Repeated: true}}
+	type rangesStruct struct {
+		R []*RangeValue
+	}
+	in := rangesStruct{R: []*RangeValue{{Start: civil.Date{Year: 2022, Month: 1, Day: 1}}, nil}}
+	saved, _, err := (&StructSaver{Struct: in, Schema: schema}).Save()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]Value{"R": []interface{}{"[2022-01-01, UNBOUNDED)", nil}}
+	if diff := testutil.Diff(saved, want); diff != "" {
+		t.Errorf("save: -got, +want:\n%s", diff)
+	}
+}
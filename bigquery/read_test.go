@@ -240,3 +240,92 @@ func TestReadQueryOptions(t *testing.T) {
 		t.Errorf("reading: got:\n%v\nwant:\n%v", pf.calls, want)
 	}
 }
+
+func TestResumeRead(t *testing.T) {
+	ctx := context.Background()
+	c := &Client{projectID: "project-id"}
+	queryJob := &Job{
+		projectID: "project-id",
+		jobID:     "job-id",
+		c:         c,
+		config:    &bq.JobConfiguration{Query: &bq.JobConfigurationQuery{}},
+	}
+	pf := &pageFetcherReadStub{
+		values:     [][][]Value{{{1}, {2}, {3}}, {{4}, {5}}},
+		pageTokens: map[string]string{"": "a", "a": ""},
+	}
+	it, err := queryJob.read(ctx, waitForQueryStub, pf.fetchPage)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 2; i++ {
+		var row []Value
+		if err := it.Next(&row); err != nil {
+			t.Fatal(err)
+		}
+	}
+	tok, err := it.ResumeToken()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Resume with a new iterator, as a later request would.
+	pf = &pageFetcherReadStub{
+		values:     [][][]Value{{{3}, {4}, {5}}},
+		pageTokens: map[string]string{"": ""},
+	}
+	it, err = queryJob.resumeRead(ctx, tok, waitForQueryStub, pf.fetchPage)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, ok := collectValues(t, it)
+	if !ok {
+		return
+	}
+	if want := [][]Value{{3}, {4}, {5}}; !testutil.Equal(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+	if got := pf.calls[0].startIndex; got != 2 {
+		t.Errorf("start index: got %d, want 2", got)
+	}
+	tok, err = it.ResumeToken()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n, err := parseResumeToken(tok); err != nil || n != 5 {
+		t.Errorf("token after reading all rows: got (%d, %v), want 5", n, err)
+	}
+
+	if _, err := queryJob.resumeRead(ctx, "bogus", waitForQueryStub, pf.fetchPage); err == nil {
+		t.Error("invalid token: got nil error, want error")
+	}
+}
+
+func TestResumeTokenPager(t *testing.T) {
+	pf := &pageFetcherReadStub{
+		values:     [][][]Value{{{1}, {2}}, {{3}}},
+		pageTokens: map[string]string{"": "a", "a": ""},
+	}
+	it := newRowIterator(context.Background(), nil, pf.fetchPage)
+	var rows [][]Value
+	if _, err := iterator.NewPager(it, 2, "").NextPage(&rows); err != nil {
+		t.Fatal(err)
+	}
+	tok, err := it.ResumeToken()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n, _ := parseResumeToken(tok); n != 2 {
+		t.Errorf("got position %d, want 2", n)
+	}
+
+	it = newRowIterator(context.Background(), nil, pf.fetchPage)
+	it.PageInfo().Token = "a"
+	var row []Value
+	if err := it.Next(&row); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := it.ResumeToken(); err == nil {
+		t.Error("iterator started from page token: got nil error, want error")
+	}
+}
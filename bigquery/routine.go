@@ -189,6 +189,65 @@ type RoutineMetadata struct {
 	// For JAVASCRIPT function, it is the evaluated string in the AS clause of
 	// a CREATE FUNCTION statement.
 	Body string
+
+	// RemoteFunctionOptions contains information for a remote user-defined
+	// function.  Set only if the routine is a remote function.
+	RemoteFunctionOptions *RemoteFunctionOptions
+}
+
+// RemoteFunctionOptions contains information for a remote user-defined
+// function, which delegates its evaluation to an external service such as a
+// Cloud Function or Cloud Run endpoint.
+type RemoteFunctionOptions struct {
+	// Fully qualified name of the user-provided connection object which holds
+	// the authentication information to send requests to the remote service.
+	// Format:
+	// projects/{projectId}/locations/{locationId}/connections/{connectionId}
+	Connection string
+
+	// Endpoint of the user-provided remote service, e.g.
+	// https://us-east1-my_gcf_project.cloudfunctions.net/remote_add
+	Endpoint string
+
+	// Max number of rows in each batch sent to the remote service.
+	// If absent or if 0, BigQuery dynamically decides the number of rows in a batch.
+	MaxBatchingRows int64
+
+	// User-defined context as a set of key/value pairs, which will be sent as
+	// function invocation context together with batched arguments in the
+	// requests to the remote service. The total number of bytes of keys and
+	// values must be less than 8KB.
+	UserDefinedContext map[string]string
+}
+
+func (rfo *RemoteFunctionOptions) toBQ() (*bq.RemoteFunctionOptions, error) {
+	if rfo == nil {
+		return nil, nil
+	}
+	return &bq.RemoteFunctionOptions{
+		Connection:         rfo.Connection,
+		Endpoint:           rfo.Endpoint,
+		MaxBatchingRows:    rfo.MaxBatchingRows,
+		UserDefinedContext: rfo.UserDefinedContext,
+	}, nil
+}
+
+func bqToRemoteFunctionOptions(in *bq.RemoteFunctionOptions) (*RemoteFunctionOptions, error) {
+	if in == nil {
+		return nil, nil
+	}
+	rfo := &RemoteFunctionOptions{
+		Connection:      in.Connection,
+		Endpoint:        in.Endpoint,
+		MaxBatchingRows: in.MaxBatchingRows,
+	}
+	if in.UserDefinedContext != nil {
+		rfo.UserDefinedContext = make(map[string]string)
+		for k, v := range in.UserDefinedContext {
+			rfo.UserDefinedContext[k] = v
+		}
+	}
+	return rfo, nil
 }
 
 func (rm *RoutineMetadata) toBQ() (*bq.Routine, error) {
@@ -223,6 +282,13 @@ func (rm *RoutineMetadata) toBQ() (*bq.Routine, error) {
 	}
 	r.Arguments = args
 	r.ImportedLibraries = rm.ImportedLibraries
+	if rm.RemoteFunctionOptions != nil {
+		rfo, err := rm.RemoteFunctionOptions.toBQ()
+		if err != nil {
+			return nil, err
+		}
+		r.RemoteFunctionOptions = rfo
+	}
 	if !rm.CreationTime.IsZero() {
 		return nil, errors.New("cannot set CreationTime on create")
 	}
@@ -330,6 +396,8 @@ type RoutineMetadataToUpdate struct {
 	ImportedLibraries []string
 	ReturnType        *StandardSQLDataType
 	ReturnTableType   *StandardSQLTableType
+	// RemoteFunctionOptions replaces the remote function options of the routine.
+	RemoteFunctionOptions *RemoteFunctionOptions
 }
 
 func (rm *RoutineMetadataToUpdate) toBQ() (*bq.Routine, error) {
@@ -407,6 +475,14 @@ func (rm *RoutineMetadataToUpdate) toBQ() (*bq.Routine, error) {
 		r.ReturnTableType = tt
 		forceSend("ReturnTableType")
 	}
+	if rm.RemoteFunctionOptions != nil {
+		rfo, err := rm.RemoteFunctionOptions.toBQ()
+		if err != nil {
+			return nil, err
+		}
+		r.RemoteFunctionOptions = rfo
+		forceSend("RemoteFunctionOptions")
+	}
 	return r, nil
 }
 
@@ -437,5 +513,10 @@ func bqToRoutineMetadata(r *bq.Routine) (*RoutineMetadata, error) {
 		return nil, err
 	}
 	meta.ReturnTableType = tt
+	rfo, err := bqToRemoteFunctionOptions(r.RemoteFunctionOptions)
+	if err != nil {
+		return nil, err
+	}
+	meta.RemoteFunctionOptions = rfo
 	return meta, nil
 }
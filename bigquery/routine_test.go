@@ -32,6 +32,12 @@ func testRoutineConversion(t *testing.T, conversion string, in interface{}, want
 			t.Fatalf("failed input type conversion (bq.Routine): %v", in)
 		}
 		got, err = bqToRoutineMetadata(input)
+	case "FromRoutineMetadata":
+		input, ok := in.(*RoutineMetadata)
+		if !ok {
+			t.Fatalf("failed input type conversion: %v", in)
+		}
+		got, err = input.toBQ()
 	case "FromRoutineMetadataToUpdate":
 		input, ok := in.(*RoutineMetadataToUpdate)
 		if !ok {
@@ -118,6 +124,54 @@ func TestRoutineTypeConversions(t *testing.T) {
 				ReturnType:        &bq.StandardSqlDataType{TypeKind: "FOO"},
 				ForceSendFields:   []string{"DefinitionBody", "ImportedLibraries", "ReturnType"},
 			}},
+		{"remote_function", "ToRoutineMetadata",
+			&bq.Routine{
+				RoutineType: "SCALAR_FUNCTION",
+				RemoteFunctionOptions: &bq.RemoteFunctionOptions{
+					Connection:         "projects/p/locations/us/connections/c",
+					Endpoint:           "https://example.com/fn",
+					MaxBatchingRows:    50,
+					UserDefinedContext: map[string]string{"k": "v"},
+				},
+			},
+			&RoutineMetadata{
+				Type: "SCALAR_FUNCTION",
+				RemoteFunctionOptions: &RemoteFunctionOptions{
+					Connection:         "projects/p/locations/us/connections/c",
+					Endpoint:           "https://example.com/fn",
+					MaxBatchingRows:    50,
+					UserDefinedContext: map[string]string{"k": "v"},
+				},
+			}},
+		{"remote_function", "FromRoutineMetadata",
+			&RoutineMetadata{
+				Type: "SCALAR_FUNCTION",
+				RemoteFunctionOptions: &RemoteFunctionOptions{
+					Connection:      "projects/p/locations/us/connections/c",
+					Endpoint:        "https://example.com/fn",
+					MaxBatchingRows: 10,
+				},
+			},
+			&bq.Routine{
+				RoutineType: "SCALAR_FUNCTION",
+				RemoteFunctionOptions: &bq.RemoteFunctionOptions{
+					Connection:      "projects/p/locations/us/connections/c",
+					Endpoint:        "https://example.com/fn",
+					MaxBatchingRows: 10,
+				},
+			}},
+		{"remote_function", "FromRoutineMetadataToUpdate",
+			&RoutineMetadataToUpdate{
+				RemoteFunctionOptions: &RemoteFunctionOptions{
+					Endpoint: "https://example.com/fn2",
+				},
+			},
+			&bq.Routine{
+				RemoteFunctionOptions: &bq.RemoteFunctionOptions{
+					Endpoint: "https://example.com/fn2",
+				},
+				ForceSendFields: []string{"RemoteFunctionOptions"},
+			}},
 		{"null_fields", "FromRoutineMetadataToUpdate",
 			&RoutineMetadataToUpdate{
 				Type:              "type",
@@ -121,6 +121,26 @@ type FieldSchema struct {
 	//
 	// See the Precision field for additional guidance about valid values.
 	Scale int64
+
+	// DefaultValueExpression is used to specify the default value of a field
+	// using a SQL expression.  It can only be set for top level fields (columns).
+	//
+	// You can use struct or array expression to specify default value for the
+	// entire struct or array. The valid SQL expressions are:
+	//
+	// - Literals for all data types, including STRUCT and ARRAY.
+	// - The following functions:
+	//   CURRENT_TIMESTAMP
+	//   CURRENT_TIME
+	//   CURRENT_DATE
+	//   CURRENT_DATETIME
+	//   GENERATE_UUID
+	//   RAND
+	//   SESSION_USER
+	//   ST_GEOGPOINT
+	// - Struct or array composed with the above allowed functions, for example:
+	//   [CURRENT_DATE(), DATE '2020-01-01']
+	DefaultValueExpression string
 }
 
 func (fs *FieldSchema) toBQ() *bq.TableFieldSchema {
@@ -132,6 +152,8 @@ func (fs *FieldSchema) toBQ() *bq.TableFieldSchema {
 		MaxLength:   fs.MaxLength,
 		Precision:   fs.Precision,
 		Scale:       fs.Scale,
+
+		DefaultValueExpression: fs.DefaultValueExpression,
 	}
 
 	if fs.Repeated {
@@ -190,6 +212,8 @@ func bqToFieldSchema(tfs *bq.TableFieldSchema) *FieldSchema {
 		MaxLength:   tfs.MaxLength,
 		Precision:   tfs.Precision,
 		Scale:       tfs.Scale,
+
+		DefaultValueExpression: tfs.DefaultValueExpression,
 	}
 
 	for _, f := range tfs.Fields {
@@ -244,6 +268,9 @@ const (
 	BigNumericFieldType FieldType = "BIGNUMERIC"
 	// IntervalFieldType is a representation of a duration or an amount of time.
 	IntervalFieldType FieldType = "INTERVAL"
+	// RangeFieldType represents a contiguous range of DATE, DATETIME or TIMESTAMP values.
+	// See RangeValue for the limits of RANGE support.
+	RangeFieldType FieldType = "RANGE"
 )
 
 var (
@@ -263,6 +290,7 @@ var (
 		GeographyFieldType:  true,
 		BigNumericFieldType: true,
 		IntervalFieldType:   true,
+		RangeFieldType:      true,
 	}
 	// The API will accept alias names for the types based on the Standard SQL type names.
 	fieldAliases = map[FieldType]FieldType{
@@ -294,6 +322,7 @@ var typeOfByteSlice = reflect.TypeOf([]byte{})
 //   TIME        civil.Time
 //   DATETIME    civil.DateTime
 //   NUMERIC     *big.Rat
+//   GEOGRAPHY   Geography
 //
 // The big.Rat type supports numbers of arbitrary size and precision. Values
 // will be rounded to 9 digits after the decimal point before being transmitted
@@ -303,8 +332,8 @@ var typeOfByteSlice = reflect.TypeOf([]byte{})
 // A Go slice or array type is inferred to be a BigQuery repeated field of the
 // element type. The element type must be one of the above listed types.
 //
-// Due to lack of unique native Go type for GEOGRAPHY, there is no schema
-// inference to GEOGRAPHY at this time.
+// A Go string is always inferred as STRING. To infer a GEOGRAPHY field, use the
+// Geography type.
 //
 // Nullable fields are inferred from the NullXXX types, declared in this package:
 //
@@ -417,6 +446,8 @@ func inferFieldSchema(fieldName string, rt reflect.Type, nullable bool) (*FieldS
 		// larger precision of BIGNUMERIC need to manipulate the inferred
 		// schema.
 		return &FieldSchema{Required: !nullable, Type: NumericFieldType}, nil
+	case typeOfGeography:
+		return &FieldSchema{Required: true, Type: GeographyFieldType}, nil
 	}
 	if ft := nullableFieldType(rt); ft != "" {
 		return &FieldSchema{Required: false, Type: ft}, nil
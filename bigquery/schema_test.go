@@ -320,6 +320,23 @@ func TestSchemaConversion(t *testing.T) {
 				},
 			},
 		},
+		{
+			// default values
+			bqSchema: &bq.TableSchema{
+				Fields: []*bq.TableFieldSchema{
+					{
+						Name:                   "created",
+						Type:                   "TIMESTAMP",
+						DefaultValueExpression: "CURRENT_TIMESTAMP()",
+					},
+				}},
+			schema: Schema{
+				{Name: "created",
+					Type:                   TimestampFieldType,
+					DefaultValueExpression: "CURRENT_TIMESTAMP()",
+				},
+			},
+		},
 		{
 			// policy tags
 			bqSchema: &bq.TableSchema{
@@ -1176,7 +1193,8 @@ func TestSchemaFromJSON(t *testing.T) {
 			bqSchemaJSON: []byte(`
 [
 	{"name":"strfield","type":"STRING","mode":"NULLABLE","description":"foo","maxLength":"100"},
-	{"name":"numfield","type":"BIGNUMERIC","description":"bar","mode":"REPEATED","precision":"10","scale":"5","policyTags":{"names":["baz"]}}
+	{"name":"numfield","type":"BIGNUMERIC","description":"bar","mode":"REPEATED","precision":"10","scale":"5","policyTags":{"names":["baz"]}},
+	{"name":"tsfield","type":"TIMESTAMP","defaultValueExpression":"CURRENT_TIMESTAMP()"}
 ]`),
 			expectedSchema: Schema{
 				&FieldSchema{
@@ -1196,6 +1214,11 @@ func TestSchemaFromJSON(t *testing.T) {
 						Names: []string{"baz"},
 					},
 				},
+				&FieldSchema{
+					Name:                   "tsfield",
+					Type:                   "TIMESTAMP",
+					DefaultValueExpression: "CURRENT_TIMESTAMP()",
+				},
 			},
 		},
 	}
@@ -0,0 +1,561 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package managedwriter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"cloud.google.com/go/bigquery"
+	"cloud.google.com/go/bigquery/storage/managedwriter/adapt"
+	"cloud.google.com/go/civil"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+// Inserter writes rows to the default stream of a table.  It accepts the same
+// row values as bigquery.Inserter, and is intended as a replacement for it
+// that uses the Storage Write API rather than the legacy insertAll
+// (tabledata.insertAll) streaming API.
+//
+// Unlike bigquery.Inserter, insert IDs are not used for deduplication; rows
+// written through the default stream are delivered at least once.
+type Inserter struct {
+	// IgnoreUnknownValues causes values not matching the schema to be
+	// ignored. If false, rows containing values not in the schema cause
+	// Put to return an error.
+	IgnoreUnknownValues bool
+
+	ms         *ManagedStream
+	schema     bigquery.Schema
+	descriptor protoreflect.MessageDescriptor
+}
+
+// NewInserter constructs an Inserter which appends rows to the default stream
+// of the given table.
+//
+// The schema must be the schema of the destination table, as returned by
+// bigquery.Table.Metadata, or a subset of its columns. Rows are encoded
+// against this schema before they are sent.
+func (c *Client) NewInserter(ctx context.Context, projectID, datasetID, tableID string, schema bigquery.Schema, opts ...WriterOption) (*Inserter, error) {
+	md, dp, err := schemaDescriptors(schema)
+	if err != nil {
+		return nil, err
+	}
+	o := []WriterOption{
+		WithDestinationTable(TableParentFromParts(projectID, datasetID, tableID)),
+		WithType(DefaultStream),
+		WithSchemaDescriptor(dp),
+	}
+	o = append(o, opts...)
+	ms, err := c.NewManagedStream(ctx, o...)
+	if err != nil {
+		return nil, err
+	}
+	return &Inserter{
+		ms:         ms,
+		schema:     schema,
+		descriptor: md,
+	}, nil
+}
+
+// schemaDescriptors builds the message descriptor used to encode rows for the
+// given schema, along with its normalized form for sending to the backend.
+func schemaDescriptors(schema bigquery.Schema) (protoreflect.MessageDescriptor, *descriptorpb.DescriptorProto, error) {
+	ts, err := adapt.BQSchemaToStorageTableSchema(schema)
+	if err != nil {
+		return nil, nil, fmt.Errorf("couldn't convert schema: %w", err)
+	}
+	d, err := adapt.StorageSchemaToProto2Descriptor(ts, "root")
+	if err != nil {
+		return nil, nil, fmt.Errorf("couldn't build descriptor: %w", err)
+	}
+	md, ok := d.(protoreflect.MessageDescriptor)
+	if !ok {
+		return nil, nil, fmt.Errorf("adapted descriptor is not a message descriptor")
+	}
+	dp, err := adapt.NormalizeDescriptor(md)
+	if err != nil {
+		return nil, nil, fmt.Errorf("couldn't normalize descriptor: %w", err)
+	}
+	return md, dp, nil
+}
+
+// Close releases the resources held by the inserter's underlying stream.
+func (ins *Inserter) Close() error {
+	return ins.ms.Close()
+}
+
+// Put uploads one or more rows to the table, and blocks until the append is
+// acknowledged.
+//
+// If src is a bigquery.ValueSaver, then its Save method is called to produce a
+// row for uploading.
+//
+// If src is a struct or pointer to a struct, then a schema is inferred from it
+// and used to create a bigquery.StructSaver.
+//
+// If src is a slice of ValueSavers, structs, or struct pointers, then each
+// element of the slice is treated as above, and all rows are uploaded in a
+// single append.
+func (ins *Inserter) Put(ctx context.Context, src interface{}) error {
+	savers, err := valueSavers(src)
+	if err != nil {
+		return err
+	}
+	data := make([][]byte, 0, len(savers))
+	for i, saver := range savers {
+		row, _, err := saver.Save()
+		if err != nil {
+			return err
+		}
+		msg, err := ins.rowToMessage(row)
+		if err != nil {
+			return fmt.Errorf("row %d: %w", i, err)
+		}
+		b, err := proto.Marshal(msg)
+		if err != nil {
+			return fmt.Errorf("row %d: %w", i, err)
+		}
+		data = append(data, b)
+	}
+	if len(data) == 0 {
+		return nil
+	}
+	res, err := ins.ms.AppendRows(ctx, data)
+	if err != nil {
+		return err
+	}
+	_, err = res.GetResult(ctx)
+	return err
+}
+
+func valueSavers(src interface{}) ([]bigquery.ValueSaver, error) {
+	saver, ok, err := toValueSaver(src)
+	if err != nil {
+		return nil, err
+	}
+	if ok {
+		return []bigquery.ValueSaver{saver}, nil
+	}
+	srcVal := reflect.ValueOf(src)
+	if srcVal.Kind() != reflect.Slice {
+		return nil, fmt.Errorf("%T is not a ValueSaver, struct, struct pointer, or slice", src)
+	}
+	var savers []bigquery.ValueSaver
+	for i := 0; i < srcVal.Len(); i++ {
+		s := srcVal.Index(i).Interface()
+		saver, ok, err := toValueSaver(s)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			return nil, fmt.Errorf("src[%d] has type %T, which is not a ValueSaver, struct or struct pointer", i, s)
+		}
+		savers = append(savers, saver)
+	}
+	return savers, nil
+}
+
+// toValueSaver mirrors the conversion used by bigquery.Inserter: x must
+// implement bigquery.ValueSaver already or be a struct or pointer to struct.
+func toValueSaver(x interface{}) (bigquery.ValueSaver, bool, error) {
+	if _, ok := x.(bigquery.StructSaver); ok {
+		return nil, false, errors.New("use &StructSaver, not StructSaver")
+	}
+	if ss, ok := x.(*bigquery.StructSaver); ok && ss.Schema == nil {
+		x = ss.Struct
+	}
+	if saver, ok := x.(bigquery.ValueSaver); ok {
+		return saver, ok, nil
+	}
+	v := reflect.ValueOf(x)
+	if v.Kind() == reflect.Interface {
+		v = v.Elem()
+	}
+	if v.Kind() == reflect.Ptr {
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct {
+		return nil, false, nil
+	}
+	schema, err := bigquery.InferSchema(v.Interface())
+	if err != nil {
+		return nil, false, err
+	}
+	return &bigquery.StructSaver{Struct: x, Schema: schema}, true, nil
+}
+
+// rowToMessage encodes a saved row as a message of the inserter's descriptor.
+func (ins *Inserter) rowToMessage(row map[string]bigquery.Value) (proto.Message, error) {
+	msg := dynamicpb.NewMessage(ins.descriptor)
+	if err := setMessageFields(msg, ins.schema, row, ins.IgnoreUnknownValues); err != nil {
+		return nil, err
+	}
+	return msg, nil
+}
+
+func setMessageFields(msg protoreflect.Message, schema bigquery.Schema, row map[string]bigquery.Value, ignoreUnknown bool) error {
+	fields := make(map[string]*bigquery.FieldSchema, len(schema))
+	for _, fs := range schema {
+		fields[strings.ToLower(fs.Name)] = fs
+	}
+	for k, v := range row {
+		name := strings.ToLower(k)
+		fs, ok := fields[name]
+		fd := msg.Descriptor().Fields().ByName(protoreflect.Name(name))
+		if !ok || fd == nil {
+			if ignoreUnknown {
+				continue
+			}
+			return fmt.Errorf("no column named %q in schema", k)
+		}
+		v = unwrapNull(v)
+		if v == nil {
+			continue
+		}
+		if err := setField(msg, fd, fs, v, ignoreUnknown); err != nil {
+			return fmt.Errorf("column %q: %w", k, err)
+		}
+	}
+	return nil
+}
+
+func setField(msg protoreflect.Message, fd protoreflect.FieldDescriptor, fs *bigquery.FieldSchema, v bigquery.Value, ignoreUnknown bool) error {
+	if !fs.Repeated {
+		pv, err := fieldValue(msg, fd, fs, v, ignoreUnknown)
+		if err != nil {
+			return err
+		}
+		msg.Set(fd, pv)
+		return nil
+	}
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Slice && rv.Kind() != reflect.Array {
+		return fmt.Errorf("got %T for repeated field, want a slice", v)
+	}
+	list := msg.Mutable(fd).List()
+	for i := 0; i < rv.Len(); i++ {
+		elem := rv.Index(i).Interface()
+		if elem == nil {
+			return fmt.Errorf("element %d is nil, but repeated fields cannot contain NULL", i)
+		}
+		pv, err := fieldValue(msg, fd, fs, elem, ignoreUnknown)
+		if err != nil {
+			return fmt.Errorf("element %d: %w", i, err)
+		}
+		list.Append(pv)
+	}
+	return nil
+}
+
+// fieldValue converts a single (non-repeated) value to the representation
+// the Storage Write API expects for the column's type.
+func fieldValue(msg protoreflect.Message, fd protoreflect.FieldDescriptor, fs *bigquery.FieldSchema, v bigquery.Value, ignoreUnknown bool) (protoreflect.Value, error) {
+	switch fs.Type {
+	case bigquery.RecordFieldType:
+		var row map[string]bigquery.Value
+		switch x := v.(type) {
+		case map[string]bigquery.Value:
+			row = x
+		case bigquery.ValueSaver:
+			r, _, err := x.Save()
+			if err != nil {
+				return protoreflect.Value{}, err
+			}
+			row = r
+		default:
+			return protoreflect.Value{}, fmt.Errorf("got %T for RECORD field, want map[string]bigquery.Value", v)
+		}
+		var sub protoreflect.Message
+		if fd.IsList() {
+			sub = msg.Mutable(fd).List().NewElement().Message()
+		} else {
+			sub = msg.NewField(fd).Message()
+		}
+		if err := setMessageFields(sub, fs.Schema, row, ignoreUnknown); err != nil {
+			return protoreflect.Value{}, err
+		}
+		return protoreflect.ValueOfMessage(sub), nil
+	case bigquery.StringFieldType:
+		if s, ok := v.(string); ok {
+			return protoreflect.ValueOfString(s), nil
+		}
+	case bigquery.GeographyFieldType:
+		switch x := v.(type) {
+		case string:
+			return protoreflect.ValueOfString(x), nil
+		case bigquery.Geography:
+			return protoreflect.ValueOfString(x.WKT), nil
+		}
+	case bigquery.BytesFieldType:
+		if b, ok := v.([]byte); ok {
+			return protoreflect.ValueOfBytes(b), nil
+		}
+	case bigquery.BooleanFieldType:
+		switch x := v.(type) {
+		case bool:
+			return protoreflect.ValueOfBool(x), nil
+		case string:
+			b, err := strconv.ParseBool(x)
+			if err != nil {
+				return protoreflect.Value{}, err
+			}
+			return protoreflect.ValueOfBool(b), nil
+		}
+	case bigquery.IntegerFieldType:
+		if i, ok := toInt64(v); ok {
+			return protoreflect.ValueOfInt64(i), nil
+		}
+		if s, ok := v.(string); ok {
+			i, err := strconv.ParseInt(s, 10, 64)
+			if err != nil {
+				return protoreflect.Value{}, err
+			}
+			return protoreflect.ValueOfInt64(i), nil
+		}
+	case bigquery.FloatFieldType:
+		switch x := v.(type) {
+		case float64:
+			return protoreflect.ValueOfFloat64(x), nil
+		case float32:
+			return protoreflect.ValueOfFloat64(float64(x)), nil
+		case string:
+			f, err := strconv.ParseFloat(x, 64)
+			if err != nil {
+				return protoreflect.Value{}, err
+			}
+			return protoreflect.ValueOfFloat64(f), nil
+		}
+		if i, ok := toInt64(v); ok {
+			return protoreflect.ValueOfFloat64(float64(i)), nil
+		}
+	case bigquery.TimestampFieldType:
+		switch x := v.(type) {
+		case time.Time:
+			return protoreflect.ValueOfInt64(x.Unix()*1e6 + int64(x.Nanosecond()/1e3)), nil
+		case int64:
+			return protoreflect.ValueOfInt64(x), nil
+		}
+	case bigquery.DateFieldType:
+		d, err := toDate(v)
+		if err != nil {
+			return protoreflect.Value{}, err
+		}
+		if d != nil {
+			return protoreflect.ValueOfInt32(int32(d.DaysSince(civil.Date{Year: 1970, Month: time.January, Day: 1}))), nil
+		}
+	case bigquery.TimeFieldType:
+		t, err := toTime(v)
+		if err != nil {
+			return protoreflect.Value{}, err
+		}
+		if t != nil {
+			return protoreflect.ValueOfInt64(encodePackedTimeMicros(*t)), nil
+		}
+	case bigquery.DateTimeFieldType:
+		dt, err := toDateTime(v)
+		if err != nil {
+			return protoreflect.Value{}, err
+		}
+		if dt != nil {
+			return protoreflect.ValueOfInt64(encodePackedDateTimeMicros(*dt)), nil
+		}
+	case bigquery.NumericFieldType, bigquery.BigNumericFieldType:
+		r, err := toRat(v)
+		if err != nil {
+			return protoreflect.Value{}, err
+		}
+		if r != nil {
+			scale := bigquery.NumericScaleDigits
+			if fs.Type == bigquery.BigNumericFieldType {
+				scale = bigquery.BigNumericScaleDigits
+			}
+			return protoreflect.ValueOfBytes(encodeNumeric(r, scale)), nil
+		}
+	default:
+		return protoreflect.Value{}, fmt.Errorf("unsupported field type %s", fs.Type)
+	}
+	return protoreflect.Value{}, fmt.Errorf("got %T for %s field", v, fs.Type)
+}
+
+// unwrapNull returns the value held by one of the bigquery.NullX types, or nil
+// if it is not valid. Other values are returned unchanged.
+func unwrapNull(v bigquery.Value) bigquery.Value {
+	var (
+		inner bigquery.Value
+		valid bool
+	)
+	switch x := v.(type) {
+	case bigquery.NullInt64:
+		inner, valid = x.Int64, x.Valid
+	case bigquery.NullString:
+		inner, valid = x.StringVal, x.Valid
+	case bigquery.NullGeography:
+		inner, valid = x.GeographyVal, x.Valid
+	case bigquery.NullFloat64:
+		inner, valid = x.Float64, x.Valid
+	case bigquery.NullBool:
+		inner, valid = x.Bool, x.Valid
+	case bigquery.NullTimestamp:
+		inner, valid = x.Timestamp, x.Valid
+	case bigquery.NullDate:
+		inner, valid = x.Date, x.Valid
+	case bigquery.NullTime:
+		inner, valid = x.Time, x.Valid
+	case bigquery.NullDateTime:
+		inner, valid = x.DateTime, x.Valid
+	default:
+		return v
+	}
+	if !valid {
+		return nil
+	}
+	return inner
+}
+
+func toInt64(v bigquery.Value) (int64, bool) {
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return rv.Int(), true
+	case reflect.Uint8, reflect.Uint16, reflect.Uint32:
+		return int64(rv.Uint()), true
+	}
+	return 0, false
+}
+
+func toDate(v bigquery.Value) (*civil.Date, error) {
+	switch x := v.(type) {
+	case civil.Date:
+		return &x, nil
+	case string:
+		d, err := civil.ParseDate(x)
+		if err != nil {
+			return nil, err
+		}
+		return &d, nil
+	}
+	return nil, nil
+}
+
+func toTime(v bigquery.Value) (*civil.Time, error) {
+	switch x := v.(type) {
+	case civil.Time:
+		return &x, nil
+	case string:
+		t, err := civil.ParseTime(x)
+		if err != nil {
+			return nil, err
+		}
+		return &t, nil
+	}
+	return nil, nil
+}
+
+func toDateTime(v bigquery.Value) (*civil.DateTime, error) {
+	switch x := v.(type) {
+	case civil.DateTime:
+		return &x, nil
+	case string:
+		// bigquery.CivilDateTimeString separates the date and time with a space.
+		dt, err := civil.ParseDateTime(strings.Replace(x, " ", "T", 1))
+		if err != nil {
+			return nil, err
+		}
+		return &dt, nil
+	}
+	return nil, nil
+}
+
+func toRat(v bigquery.Value) (*big.Rat, error) {
+	switch x := v.(type) {
+	case *big.Rat:
+		return x, nil
+	case string:
+		r, ok := new(big.Rat).SetString(x)
+		if !ok {
+			return nil, fmt.Errorf("couldn't parse %q as a numeric value", x)
+		}
+		return r, nil
+	}
+	return nil, nil
+}
+
+// Bit layout of the packed civil time encodings used by the Storage Write API
+// for TIME and DATETIME values.
+const (
+	microsBits  = 20
+	secondShift = 0
+	minuteShift = 6
+	hourShift   = 12
+	dayShift    = 17
+	monthShift  = 22
+	yearShift   = 26
+)
+
+func packedTimeSeconds(t civil.Time) int64 {
+	return int64(t.Hour)<<hourShift | int64(t.Minute)<<minuteShift | int64(t.Second)<<secondShift
+}
+
+// encodePackedTimeMicros encodes a civil.Time in the packed 64 bit format
+// expected for TIME columns.
+func encodePackedTimeMicros(t civil.Time) int64 {
+	return packedTimeSeconds(t)<<microsBits | int64(t.Nanosecond/1e3)
+}
+
+// encodePackedDateTimeMicros encodes a civil.DateTime in the packed 64 bit
+// format expected for DATETIME columns.
+func encodePackedDateTimeMicros(dt civil.DateTime) int64 {
+	secs := int64(dt.Date.Year)<<yearShift |
+		int64(dt.Date.Month)<<monthShift |
+		int64(dt.Date.Day)<<dayShift |
+		packedTimeSeconds(dt.Time)
+	return secs<<microsBits | int64(dt.Time.Nanosecond/1e3)
+}
+
+// encodeNumeric encodes r, rounded to the given number of decimal digits, as
+// the little-endian two's complement bytes of the scaled integer value, as
+// expected for NUMERIC and BIGNUMERIC columns.
+func encodeNumeric(r *big.Rat, scale int) []byte {
+	pow := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(scale)), nil)
+	num := new(big.Int).Mul(r.Num(), pow)
+	q, m := new(big.Int).QuoRem(num, r.Denom(), new(big.Int))
+	// Round half away from zero.
+	if m.Abs(m).Lsh(m, 1).Cmp(r.Denom()) >= 0 {
+		if r.Sign() < 0 {
+			q.Sub(q, big.NewInt(1))
+		} else {
+			q.Add(q, big.NewInt(1))
+		}
+	}
+	n := q.BitLen()/8 + 1
+	if q.Sign() < 0 {
+		q.Add(q, new(big.Int).Lsh(big.NewInt(1), uint(n*8)))
+	}
+	b := q.FillBytes(make([]byte, n))
+	for i, j := 0, len(b)-1; i < j; i, j = i+1, j-1 {
+		b[i], b[j] = b[j], b[i]
+	}
+	return b
+}
@@ -0,0 +1,169 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package managedwriter
+
+import (
+	"bytes"
+	"math/big"
+	"testing"
+	"time"
+
+	"cloud.google.com/go/bigquery"
+	"cloud.google.com/go/civil"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+func TestInserterRowToMessage(t *testing.T) {
+	type inner struct {
+		Name string
+	}
+	type row struct {
+		ID       int
+		Score    float64
+		Ok       bool
+		Data     []byte
+		TS       time.Time
+		D        civil.Date
+		T        civil.Time
+		DT       civil.DateTime
+		Num      *big.Rat
+		Opt      bigquery.NullInt64
+		Tags     []string
+		Children []inner
+	}
+	schema, err := bigquery.InferSchema(row{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	md, _, err := schemaDescriptors(schema)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ins := &Inserter{schema: schema, descriptor: md}
+
+	src := row{
+		ID:       7,
+		Score:    1.5,
+		Ok:       true,
+		Data:     []byte("abc"),
+		TS:       time.Date(2019, 8, 19, 13, 56, 5, 123456000, time.UTC),
+		D:        civil.Date{Year: 2019, Month: 8, Day: 19},
+		T:        civil.Time{Hour: 13, Minute: 56, Second: 5, Nanosecond: 123456000},
+		DT:       civil.DateTime{Date: civil.Date{Year: 2019, Month: 8, Day: 19}, Time: civil.Time{Hour: 13, Minute: 56, Second: 5, Nanosecond: 123456000}},
+		Num:      big.NewRat(3, 2),
+		Tags:     []string{"a", "b"},
+		Children: []inner{{Name: "x"}, {Name: "y"}},
+	}
+	savers, err := valueSavers([]*row{&src})
+	if err != nil {
+		t.Fatal(err)
+	}
+	vals, _, err := savers[0].Save()
+	if err != nil {
+		t.Fatal(err)
+	}
+	m, err := ins.rowToMessage(vals)
+	if err != nil {
+		t.Fatal(err)
+	}
+	msg := m.ProtoReflect()
+	get := func(name string) protoreflect.Value {
+		fd := md.Fields().ByName(protoreflect.Name(name))
+		if fd == nil {
+			t.Fatalf("no field %q", name)
+		}
+		return msg.Get(fd)
+	}
+	if got := get("id").Int(); got != 7 {
+		t.Errorf("id: got %d, want 7", got)
+	}
+	if got := get("score").Float(); got != 1.5 {
+		t.Errorf("score: got %v, want 1.5", got)
+	}
+	if got := get("ok").Bool(); !got {
+		t.Errorf("ok: got %v, want true", got)
+	}
+	if got := get("data").Bytes(); string(got) != "abc" {
+		t.Errorf("data: got %q, want %q", got, "abc")
+	}
+	if got, want := get("ts").Int(), src.TS.UnixNano()/1e3; got != want {
+		t.Errorf("ts: got %d, want %d", got, want)
+	}
+	if got := get("d").Int(); got != 18127 {
+		t.Errorf("d: got %d, want 18127", got)
+	}
+	if got := get("t").Int(); got != 59598037568 {
+		t.Errorf("t: got %d, want 59598037568", got)
+	}
+	if got := get("dt").Int(); got != 142112349804945984 {
+		t.Errorf("dt: got %d, want 142112349804945984", got)
+	}
+	if got, want := get("num").Bytes(), []byte{0x00, 0x2f, 0x68, 0x59}; !bytes.Equal(got, want) {
+		t.Errorf("num: got %x, want %x", got, want)
+	}
+	if msg.Has(md.Fields().ByName("opt")) {
+		t.Error("opt: invalid NullInt64 should leave the field unset")
+	}
+	if got := get("tags").List(); got.Len() != 2 || got.Get(1).String() != "b" {
+		t.Errorf("tags: got %v", got)
+	}
+	children := get("children").List()
+	if children.Len() != 2 {
+		t.Fatalf("children: got %d elements, want 2", children.Len())
+	}
+	child := children.Get(1).Message()
+	if got := child.Get(child.Descriptor().Fields().ByName("name")).String(); got != "y" {
+		t.Errorf("children[1].name: got %q, want %q", got, "y")
+	}
+}
+
+func TestInserterUnknownValues(t *testing.T) {
+	schema := bigquery.Schema{{Name: "a", Type: bigquery.StringFieldType}}
+	md, _, err := schemaDescriptors(schema)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ins := &Inserter{schema: schema, descriptor: md}
+	row := map[string]bigquery.Value{"A": "x", "b": 1}
+	if _, err := ins.rowToMessage(row); err == nil {
+		t.Error("got nil error for unknown column, want error")
+	}
+	ins.IgnoreUnknownValues = true
+	if _, err := ins.rowToMessage(row); err != nil {
+		t.Errorf("with IgnoreUnknownValues: %v", err)
+	}
+	if _, err := ins.rowToMessage(map[string]bigquery.Value{"a": 1}); err == nil {
+		t.Error("got nil error for mistyped value, want error")
+	}
+}
+
+func TestEncodeNumeric(t *testing.T) {
+	for _, tc := range []struct {
+		in    string
+		scale int
+		want  []byte
+	}{
+		{"0", 9, []byte{0x00}},
+		{"1.5", 9, []byte{0x00, 0x2f, 0x68, 0x59}},
+		{"-1", 9, []byte{0x00, 0x36, 0x65, 0xc4}},
+		{"0.0000000005", 9, []byte{0x01}},
+		{"-0.0000000005", 9, []byte{0xff}},
+	} {
+		r, _ := new(big.Rat).SetString(tc.in)
+		if got := encodeNumeric(r, tc.scale); !bytes.Equal(got, tc.want) {
+			t.Errorf("encodeNumeric(%s, %d): got %x, want %x", tc.in, tc.scale, got, tc.want)
+		}
+	}
+}
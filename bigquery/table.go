@@ -18,11 +18,13 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"strings"
 	"time"
 
 	"cloud.google.com/go/internal/optional"
 	"cloud.google.com/go/internal/trace"
 	bq "google.golang.org/api/bigquery/v2"
+	"google.golang.org/api/googleapi"
 )
 
 // A Table is a reference to a BigQuery table.
@@ -700,6 +702,22 @@ func WithMetadataView(tmv TableMetadataView) TableMetadataOption {
 	}
 }
 
+// WithMetadataFields restricts the table metadata returned by the Metadata()
+// call to the given fields, using the field names of the BigQuery REST API
+// (for example "schema", "labels" or "timePartitioning"). Fields of
+// TableMetadata that are not requested are left as their zero values.
+// Requesting only the fields needed reduces the cost of scanning the
+// metadata of many tables.
+func WithMetadataFields(fields ...string) TableMetadataOption {
+	return func(tgc *tableGetCall) {
+		fs := make([]googleapi.Field, len(fields))
+		for i, f := range fields {
+			fs[i] = googleapi.Field(f)
+		}
+		tgc.call.Fields(fs...)
+	}
+}
+
 // Metadata fetches the metadata for the table.
 func (t *Table) Metadata(ctx context.Context, opts ...TableMetadataOption) (md *TableMetadata, err error) {
 	ctx = trace.StartSpan(ctx, "cloud.google.com/go/bigquery.Table.Metadata")
@@ -797,6 +815,27 @@ func (t *Table) read(ctx context.Context, pf pageFetcher) *RowIterator {
 	return newRowIterator(ctx, &rowSource{t: t}, pf)
 }
 
+// ReadAt fetches the contents of the table as they were at time at, using
+// BigQuery time travel. at must be within the time travel window of the
+// dataset, which is seven days by default. This allows reading data that has
+// since been changed or deleted.
+//
+// ReadAt runs a query, so it is billed as one.
+func (t *Table) ReadAt(ctx context.Context, at time.Time) (it *RowIterator, err error) {
+	ctx = trace.StartSpan(ctx, "cloud.google.com/go/bigquery.Table.ReadAt")
+	defer func() { trace.EndSpan(ctx, err) }()
+
+	q, err := t.readAtQuery(at)
+	if err != nil {
+		return nil, err
+	}
+	return q.Read(ctx)
+}
+
+func (t *Table) readAtQuery(at time.Time) (*Query, error) {
+	return NewQueryBuilder().SQL("SELECT * FROM ").TableAt(t, at).Query(t.c)
+}
+
 // NeverExpire is a sentinel value used to remove a table'e expiration time.
 var NeverExpire = time.Time{}.Add(-1)
 
@@ -879,8 +918,15 @@ func (tm *TableMetadataToUpdate) toBQ() (*bq.Table, error) {
 		t.ExternalDataConfiguration = &cfg
 	}
 
+	if err := tm.validatePartitioning(); err != nil {
+		return nil, err
+	}
 	if tm.Clustering != nil {
-		t.Clustering = tm.Clustering.toBQ()
+		if len(tm.Clustering.Fields) == 0 {
+			t.NullFields = append(t.NullFields, "Clustering")
+		} else {
+			t.Clustering = tm.Clustering.toBQ()
+		}
 	}
 
 	if !validExpiration(tm.ExpirationTime) {
@@ -899,6 +945,16 @@ func (tm *TableMetadataToUpdate) toBQ() (*bq.Table, error) {
 			t.TimePartitioning.NullFields = []string{"ExpirationMs"}
 		}
 	}
+	if tm.PartitionExpiration != nil {
+		if t.TimePartitioning == nil {
+			t.TimePartitioning = &bq.TimePartitioning{}
+		}
+		if d := optional.ToDuration(tm.PartitionExpiration); d == 0 {
+			t.TimePartitioning.NullFields = []string{"ExpirationMs"}
+		} else {
+			t.TimePartitioning.ExpirationMs = int64(d / time.Millisecond)
+		}
+	}
 	if tm.RequirePartitionFilter != nil {
 		t.RequirePartitionFilter = optional.ToBool(tm.RequirePartitionFilter)
 		forceSend("RequirePartitionFilter")
@@ -923,6 +979,52 @@ func (tm *TableMetadataToUpdate) toBQ() (*bq.Table, error) {
 	return t, nil
 }
 
+// maxClusteringFields is the maximum number of clustering columns a table
+// may have.
+const maxClusteringFields = 4
+
+// validatePartitioning reports changes to the partitioning and clustering
+// configuration that the service would reject, so that they fail before
+// the table is patched.
+func (tm *TableMetadataToUpdate) validatePartitioning() error {
+	if tm.PartitionExpiration != nil {
+		if tm.TimePartitioning != nil {
+			return errors.New("bigquery: set either PartitionExpiration or TimePartitioning, not both")
+		}
+		if optional.ToDuration(tm.PartitionExpiration) < 0 {
+			return fmt.Errorf("bigquery: negative PartitionExpiration %v", tm.PartitionExpiration)
+		}
+	}
+	if tp := tm.TimePartitioning; tp != nil {
+		switch tp.Type {
+		case "", DayPartitioningType, HourPartitioningType, MonthPartitioningType, YearPartitioningType:
+		default:
+			return fmt.Errorf("bigquery: unknown time partitioning type %q", tp.Type)
+		}
+		if tp.Expiration < 0 {
+			return fmt.Errorf("bigquery: negative partition expiration %v", tp.Expiration)
+		}
+	}
+	if c := tm.Clustering; c != nil {
+		if len(c.Fields) > maxClusteringFields {
+			return fmt.Errorf("bigquery: at most %d clustering fields are allowed, got %d", maxClusteringFields, len(c.Fields))
+		}
+		seen := map[string]bool{}
+		for _, f := range c.Fields {
+			if f == "" {
+				return errors.New("bigquery: empty clustering field name")
+			}
+			// Column names are case-insensitive.
+			lf := strings.ToLower(f)
+			if seen[lf] {
+				return fmt.Errorf("bigquery: duplicate clustering field %q", f)
+			}
+			seen[lf] = true
+		}
+	}
+	return nil
+}
+
 // validExpiration ensures a specified time is either the sentinel NeverExpire,
 // the zero value, or within the defined range of UnixNano. Internal
 // represetations of expiration times are based upon Time.UnixNano. Any time
@@ -952,7 +1054,9 @@ type TableMetadataToUpdate struct {
 	// When updating a schema, you can add columns but not remove them.
 	Schema Schema
 
-	// The table's clustering configuration.
+	// The table's clustering configuration. At most four fields may be
+	// given. To remove clustering from the table, set Clustering to a
+	// Clustering with no fields.
 	// For more information on how modifying clustering affects the table, see:
 	// https://cloud.google.com/bigquery/docs/creating-clustered-tables#modifying-cluster-spec
 	Clustering *Clustering
@@ -983,8 +1087,14 @@ type TableMetadataToUpdate struct {
 	// configuration such as partition expiration and whether partition
 	// filtration is required at query time.  When calling Update, ensure
 	// that all mutable fields of TimePartitioning are populated.
+	// It cannot be set together with PartitionExpiration.
 	TimePartitioning *TimePartitioning
 
+	// PartitionExpiration changes only the partition expiration of a
+	// time-partitioned table, leaving the rest of its partitioning
+	// configuration as it is. Set it to zero to remove the expiration.
+	PartitionExpiration optional.Duration
+
 	// RequirePartitionFilter governs whether the table enforces partition
 	// elimination when referenced in a query.
 	RequirePartitionFilter optional.Bool
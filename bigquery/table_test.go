@@ -405,6 +405,24 @@ func TestTableMetadataToUpdateToBQ(t *testing.T) {
 				Clustering: &bq.Clustering{Fields: []string{"foo", "bar"}},
 			},
 		},
+		{
+			tm: TableMetadataToUpdate{Clustering: &Clustering{}},
+			want: &bq.Table{
+				NullFields: []string{"Clustering"},
+			},
+		},
+		{
+			tm: TableMetadataToUpdate{PartitionExpiration: 2 * time.Hour},
+			want: &bq.Table{
+				TimePartitioning: &bq.TimePartitioning{ExpirationMs: 7200000},
+			},
+		},
+		{
+			tm: TableMetadataToUpdate{PartitionExpiration: time.Duration(0)},
+			want: &bq.Table{
+				TimePartitioning: &bq.TimePartitioning{NullFields: []string{"ExpirationMs"}},
+			},
+		},
 	} {
 		got, _ := test.tm.toBQ()
 		if !testutil.Equal(got, test.want) {
@@ -442,6 +460,49 @@ func TestTableMetadataToUpdateToBQErrors(t *testing.T) {
 	}
 }
 
+func TestTableMetadataToUpdatePartitioningErrors(t *testing.T) {
+	for _, test := range []struct {
+		desc string
+		tm   TableMetadataToUpdate
+	}{
+		{
+			desc: "partition expiration with time partitioning",
+			tm: TableMetadataToUpdate{
+				TimePartitioning:    &TimePartitioning{},
+				PartitionExpiration: time.Hour,
+			},
+		},
+		{
+			desc: "negative partition expiration",
+			tm:   TableMetadataToUpdate{PartitionExpiration: -time.Hour},
+		},
+		{
+			desc: "negative time partitioning expiration",
+			tm:   TableMetadataToUpdate{TimePartitioning: &TimePartitioning{Expiration: -time.Hour}},
+		},
+		{
+			desc: "unknown partitioning type",
+			tm:   TableMetadataToUpdate{TimePartitioning: &TimePartitioning{Type: "WEEK"}},
+		},
+		{
+			desc: "too many clustering fields",
+			tm:   TableMetadataToUpdate{Clustering: &Clustering{Fields: []string{"a", "b", "c", "d", "e"}}},
+		},
+		{
+			desc: "duplicate clustering fields",
+			tm:   TableMetadataToUpdate{Clustering: &Clustering{Fields: []string{"a", "A"}}},
+		},
+		{
+			desc: "empty clustering field",
+			tm:   TableMetadataToUpdate{Clustering: &Clustering{Fields: []string{""}}},
+		},
+	} {
+		if _, err := test.tm.toBQ(); err == nil {
+			t.Errorf("[%s] got no error, want error", test.desc)
+		}
+	}
+}
+
 func TestTableIdentifiers(t *testing.T) {
 	testTable := &Table{
 		ProjectID: "p",
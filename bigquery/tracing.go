@@ -0,0 +1,94 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bigquery
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	bq "google.golang.org/api/bigquery/v2"
+)
+
+// tracerName is the name of the OpenTelemetry tracer used for the spans of
+// job operations.
+const tracerName = "cloud.google.com/go/bigquery"
+
+// Attribute keys used to annotate trace spans of job operations.
+const (
+	traceAttrProjectID      = attribute.Key("bigquery.project_id")
+	traceAttrJobID          = attribute.Key("bigquery.job_id")
+	traceAttrLocation       = attribute.Key("bigquery.location")
+	traceAttrBytesProcessed = attribute.Key("bigquery.total_bytes_processed")
+	traceAttrSlotMillis     = attribute.Key("bigquery.total_slot_ms")
+	traceAttrCacheHit       = attribute.Key("bigquery.cache_hit")
+	traceAttrTotalRows      = attribute.Key("bigquery.total_rows")
+	traceAttrPageRows       = attribute.Key("bigquery.page_rows")
+	traceAttrAttempts       = attribute.Key("bigquery.attempts")
+)
+
+// startJobSpan starts an OpenTelemetry span with the given name, using the
+// globally registered TracerProvider.
+func startJobSpan(ctx context.Context, name string) (context.Context, trace.Span) {
+	return otel.Tracer(tracerName).Start(ctx, name)
+}
+
+// endJobSpan ends span, recording err if it is not nil.
+func endJobSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+// jobTraceAttrs returns the trace attributes identifying a job.
+func jobTraceAttrs(projectID, jobID, location string) []attribute.KeyValue {
+	attrs := []attribute.KeyValue{
+		traceAttrProjectID.String(projectID),
+		traceAttrJobID.String(jobID),
+	}
+	if location != "" {
+		attrs = append(attrs, traceAttrLocation.String(location))
+	}
+	return attrs
+}
+
+// jobRefTraceAttrs returns the trace attributes identifying the job
+// referenced by jr.
+func jobRefTraceAttrs(jr *bq.JobReference) []attribute.KeyValue {
+	if jr == nil {
+		return nil
+	}
+	return jobTraceAttrs(jr.ProjectId, jr.JobId, jr.Location)
+}
+
+// jobStatsTraceAttrs returns the statistics of a job that are useful for
+// attributing its cost as trace attributes.
+func jobStatsTraceAttrs(s *JobStatistics) []attribute.KeyValue {
+	if s == nil {
+		return nil
+	}
+	attrs := []attribute.KeyValue{
+		traceAttrBytesProcessed.Int64(s.TotalBytesProcessed),
+		traceAttrSlotMillis.Int64(s.SlotMillis),
+	}
+	if qs, ok := s.Details.(*QueryStatistics); ok {
+		attrs = append(attrs, traceAttrCacheHit.Bool(qs.CacheHit))
+	}
+	return attrs
+}
@@ -0,0 +1,67 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bigquery
+
+import (
+	"testing"
+
+	"go.opentelemetry.io/otel/attribute"
+	bq "google.golang.org/api/bigquery/v2"
+)
+
+func TestJobTraceAttrs(t *testing.T) {
+	for _, test := range []struct {
+		got  []attribute.KeyValue
+		want []attribute.KeyValue
+	}{
+		{
+			got: append(
+				jobRefTraceAttrs(&bq.JobReference{ProjectId: "p", JobId: "j", Location: "US"}),
+				jobStatsTraceAttrs(&JobStatistics{
+					TotalBytesProcessed: 100,
+					SlotMillis:          20,
+					Details:             &QueryStatistics{CacheHit: true},
+				})...),
+			want: []attribute.KeyValue{
+				attribute.String("bigquery.project_id", "p"),
+				attribute.String("bigquery.job_id", "j"),
+				attribute.String("bigquery.location", "US"),
+				attribute.Int64("bigquery.total_bytes_processed", 100),
+				attribute.Int64("bigquery.total_slot_ms", 20),
+				attribute.Bool("bigquery.cache_hit", true),
+			},
+		},
+		{
+			got: append(
+				jobTraceAttrs("p", "j", ""),
+				jobStatsTraceAttrs(&JobStatistics{Details: &LoadStatistics{}})...),
+			want: []attribute.KeyValue{
+				attribute.String("bigquery.project_id", "p"),
+				attribute.String("bigquery.job_id", "j"),
+				attribute.Int64("bigquery.total_bytes_processed", 0),
+				attribute.Int64("bigquery.total_slot_ms", 0),
+			},
+		},
+		{
+			got:  append(jobRefTraceAttrs(nil), jobStatsTraceAttrs(nil)...),
+			want: nil,
+		},
+	} {
+		got, want := attribute.NewSet(test.got...), attribute.NewSet(test.want...)
+		if !got.Equals(&want) {
+			t.Errorf("got %v, want %v", test.got, test.want)
+		}
+	}
+}
@@ -308,6 +308,15 @@ func determineSetFunc(ftype reflect.Type, stype FieldType) setFunc {
 				})
 			}
 		}
+		if ftype == typeOfGeography {
+			return func(v reflect.Value, x interface{}) error {
+				if x == nil {
+					return errNoNulls
+				}
+				v.Set(reflect.ValueOf(Geography{WKT: x.(string)}))
+				return nil
+			}
+		}
 
 	case BytesFieldType:
 		if ftype == typeOfByteSlice {
@@ -414,6 +423,13 @@ func determineSetFunc(ftype reflect.Type, stype FieldType) setFunc {
 				return setNull(v, x, func() interface{} { return x.(*big.Rat) })
 			}
 		}
+
+	case RangeFieldType:
+		if ftype == typeOfRangeValue {
+			return func(v reflect.Value, x interface{}) error {
+				return setNull(v, x, func() interface{} { return x.(*RangeValue) })
+			}
+		}
 	}
 	return nil
 }
@@ -699,7 +715,8 @@ func structFieldToUploadValue(vfield reflect.Value, schemaField *FieldSchema) (i
 }
 
 func toUploadValue(val interface{}, fs *FieldSchema) interface{} {
-	if fs.Type == TimeFieldType || fs.Type == DateTimeFieldType || fs.Type == NumericFieldType || fs.Type == BigNumericFieldType {
+	switch fs.Type {
+	case TimeFieldType, DateTimeFieldType, NumericFieldType, BigNumericFieldType, GeographyFieldType, RangeFieldType:
 		return toUploadValueReflect(reflect.ValueOf(val), fs)
 	}
 	return val
@@ -742,6 +759,43 @@ func toUploadValueReflect(v reflect.Value, fs *FieldSchema) interface{} {
 		return formatUploadValue(v, fs, func(v reflect.Value) string {
 			return IntervalString(v.Interface().(*IntervalValue))
 		})
+	case RangeFieldType:
+		if r, ok := v.Interface().(*RangeValue); ok && r == nil {
+			return nil
+		}
+		if fs.Repeated && v.Type() == reflect.TypeOf([]*RangeValue(nil)) {
+			// Leave nil elements null, for the service to reject, rather
+			// than calling RangeString on them.
+			if v.Len() == 0 {
+				return nil
+			}
+			s := make([]interface{}, v.Len())
+			for i := range s {
+				if r := v.Index(i).Interface().(*RangeValue); r != nil {
+					s[i] = RangeString(r)
+				}
+			}
+			return s
+		}
+		return formatUploadValue(v, fs, func(v reflect.Value) string {
+			return RangeString(v.Interface().(*RangeValue))
+		})
+	case GeographyFieldType:
+		if g, ok := v.Interface().(Geography); ok {
+			return g.WKT
+		}
+		if !fs.Repeated {
+			return v.Interface()
+		}
+		if v.Type().Elem() == typeOfGeography {
+			return formatUploadValue(v, fs, func(v reflect.Value) string {
+				return v.Interface().(Geography).WKT
+			})
+		}
+		if v.Len() > 0 {
+			return v.Interface()
+		}
+		return nil
 	default:
 		if !fs.Repeated || v.Len() > 0 {
 			return v.Interface()
@@ -966,6 +1020,12 @@ func convertBasicType(val string, typ FieldType) (Value, error) {
 			return nil, fmt.Errorf("bigquery: invalid INTERVAL value %q", val)
 		}
 		return Value(i), nil
+	case RangeFieldType:
+		r, err := ParseRange(val)
+		if err != nil {
+			return nil, err
+		}
+		return Value(r), nil
 	default:
 		return nil, fmt.Errorf("unrecognized type: %s", typ)
 	}
@@ -858,6 +858,9 @@ func (iac *InstanceAdminClient) CreateInstanceWithClusters(ctx context.Context,
 	ctx = mergeOutgoingMetadata(ctx, iac.md)
 	clusters := make(map[string]*btapb.Cluster)
 	for _, cluster := range conf.Clusters {
+		if err := cluster.AutoscalingConfig.validate(); err != nil {
+			return err
+		}
 		clusters[cluster.ClusterID] = cluster.proto(iac.project)
 	}
 
@@ -944,6 +947,9 @@ func (iac *InstanceAdminClient) UpdateInstanceWithClusters(ctx context.Context,
 		if cluster.ClusterID == "" {
 			return errors.New("ClusterID is required for every cluster")
 		}
+		if err := cluster.AutoscalingConfig.validate(); err != nil {
+			return err
+		}
 	}
 
 	updatedInstance, err := iac.updateInstance(ctx, conf)
@@ -1060,10 +1066,28 @@ type AutoscalingConfig struct {
 	// equal to or greater than MinNodes.
 	MaxNodes int
 	// CPUTargetPercent sets the CPU utilization target for your cluster's
-	// workload.
+	// workload. It must be between 10 and 80.
 	CPUTargetPercent int
 }
 
+// validate checks the configuration against the limits of the service, so
+// that an invalid configuration fails before any RPC is made.
+func (a *AutoscalingConfig) validate() error {
+	if a == nil {
+		return nil
+	}
+	if a.MinNodes < 1 {
+		return fmt.Errorf("bigtable: autoscaling MinNodes must be at least 1, got %d", a.MinNodes)
+	}
+	if a.MaxNodes < a.MinNodes {
+		return fmt.Errorf("bigtable: autoscaling MaxNodes (%d) must not be less than MinNodes (%d)", a.MaxNodes, a.MinNodes)
+	}
+	if a.CPUTargetPercent < 10 || a.CPUTargetPercent > 80 {
+		return fmt.Errorf("bigtable: autoscaling CPUTargetPercent must be between 10 and 80, got %d", a.CPUTargetPercent)
+	}
+	return nil
+}
+
 func (a *AutoscalingConfig) proto() *btapb.Cluster_ClusterAutoscalingConfig {
 	if a == nil {
 		return nil
@@ -1170,6 +1194,9 @@ type ClusterInfo struct {
 // This method will return when the cluster has been created or when an error occurs.
 func (iac *InstanceAdminClient) CreateCluster(ctx context.Context, conf *ClusterConfig) error {
 	ctx = mergeOutgoingMetadata(ctx, iac.md)
+	if err := conf.AutoscalingConfig.validate(); err != nil {
+		return err
+	}
 
 	req := &btapb.CreateClusterRequest{
 		Parent:    "projects/" + iac.project + "/instances/" + conf.InstanceID,
@@ -1197,6 +1224,9 @@ func (iac *InstanceAdminClient) DeleteCluster(ctx context.Context, instanceID, c
 // UpdateCluster. See AutoscalingConfig documentation for deatils.
 func (iac *InstanceAdminClient) SetAutoscaling(ctx context.Context, instanceID, clusterID string, conf AutoscalingConfig) error {
 	ctx = mergeOutgoingMetadata(ctx, iac.md)
+	if err := conf.validate(); err != nil {
+		return err
+	}
 	cluster := &btapb.Cluster{
 		Name: "projects/" + iac.project + "/instances/" + instanceID + "/clusters/" + clusterID,
 		Config: &btapb.Cluster_ClusterConfig_{
@@ -422,6 +422,43 @@ func TestInstanceAdmin_CreateCluster_WithAutoscaling(t *testing.T) {
 	}
 }
 
+func TestInstanceAdmin_InvalidAutoscaling(t *testing.T) {
+	tcs := []struct {
+		desc   string
+		config AutoscalingConfig
+	}{
+		{desc: "when min nodes is zero", config: AutoscalingConfig{MinNodes: 0, MaxNodes: 2, CPUTargetPercent: 50}},
+		{desc: "when max nodes is less than min nodes", config: AutoscalingConfig{MinNodes: 3, MaxNodes: 2, CPUTargetPercent: 50}},
+		{desc: "when cpu target is too low", config: AutoscalingConfig{MinNodes: 1, MaxNodes: 2, CPUTargetPercent: 5}},
+		{desc: "when cpu target is too high", config: AutoscalingConfig{MinNodes: 1, MaxNodes: 2, CPUTargetPercent: 90}},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.desc, func(t *testing.T) {
+			mock := &mockAdminClock{}
+			c := setupClient(t, mock)
+			ctx := context.Background()
+
+			if err := c.SetAutoscaling(ctx, "myinst", "mycluster", tc.config); err == nil {
+				t.Error("SetAutoscaling: want error, got nil")
+			}
+			config := tc.config
+			if err := c.CreateCluster(ctx, &ClusterConfig{ClusterID: "mycluster", AutoscalingConfig: &config}); err == nil {
+				t.Error("CreateCluster: want error, got nil")
+			}
+			if err := c.CreateInstanceWithClusters(ctx, &InstanceWithClustersConfig{
+				InstanceID: "myinst",
+				Clusters:   []ClusterConfig{{ClusterID: "mycluster", AutoscalingConfig: &config}},
+			}); err == nil {
+				t.Error("CreateInstanceWithClusters: want error, got nil")
+			}
+			if mock.partialUpdateClusterReq != nil || mock.createClusterReq != nil || mock.createInstanceReq != nil {
+				t.Error("want no requests for an invalid config")
+			}
+		})
+	}
+}
+
 func TestInstanceAdmin_UpdateInstanceWithClusters_IgnoresInvalidClusters(t *testing.T) {
 	mock := &mockAdminClock{}
 	c := setupClient(t, mock)
@@ -23,6 +23,8 @@ import (
 	"io"
 	"net/url"
 	"strconv"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	btopt "cloud.google.com/go/bigtable/internal/option"
@@ -667,6 +669,12 @@ type entryErr struct {
 // fails, (nil, err) will be returned. If specific mutations
 // fail to apply, ([]err, nil) will be returned, and the errors
 // will correspond to the relevant rowKeys/muts arguments.
+// Mutations that fail with a retryable error are retried with backoff,
+// without resending the mutations that succeeded.
+//
+// By default the mutations are sent in one request at a time. Use
+// ApplyBulkFlowControl to send them in concurrent requests, and
+// GetBulkApplyResult to get a summary of the call.
 //
 // Conditional mutations cannot be applied in bulk and providing one will result in an error.
 func (t *Table) ApplyBulk(ctx context.Context, rowKeys []string, muts []*Mutation, opts ...ApplyOption) (errs []error, err error) {
@@ -687,27 +695,27 @@ func (t *Table) ApplyBulk(ctx context.Context, rowKeys []string, muts []*Mutatio
 		origEntries[i] = &entryErr{Entry: &btpb.MutateRowsRequest_Entry{RowKey: []byte(key), Mutations: mut.ops}}
 	}
 
-	for _, group := range groupEntries(origEntries, maxMutations) {
-		attrMap := make(map[string]interface{})
-		err = gax.Invoke(ctx, func(ctx context.Context, _ gax.CallSettings) error {
-			attrMap["rowCount"] = len(group)
-			trace.TracePrintf(ctx, attrMap, "Row count in ApplyBulk")
-			err := t.doApplyBulk(ctx, group, opts...)
-			if err != nil {
-				// We want to retry the entire request with the current group
-				return err
-			}
-			group = t.getApplyBulkRetries(group)
-			if len(group) > 0 && len(idempotentRetryCodes) > 0 {
-				// We have at least one mutation that needs to be retried.
-				// Return an arbitrary error that is retryable according to callOptions.
-				return status.Errorf(idempotentRetryCodes[0], "Synthetic error: partial failure of ApplyBulk")
+	var fc *flowController
+	stats := &bulkStats{}
+	for _, o := range opts {
+		switch o := o.(type) {
+		case *bulkFlowControl:
+			fc = newFlowController(o.BulkFlowControl)
+		case bulkApplyResult:
+			defer func() { stats.fill(o.result, origEntries) }()
+		}
+	}
+
+	if fc == nil {
+		for _, group := range groupEntries(origEntries, maxMutations) {
+			if err := t.applyGroup(ctx, group, stats, opts...); err != nil {
+				return nil, err
 			}
-			return nil
-		}, retryOptions...)
-		if err != nil {
-			return nil, err
 		}
+	} else if err := fc.run(ctx, origEntries, func(group []*entryErr) error {
+		return t.applyGroup(ctx, group, stats, opts...)
+	}); err != nil {
+		return nil, err
 	}
 
 	// All the errors are accumulated into an array and returned, interspersed with nils for successful
@@ -725,6 +733,205 @@ func (t *Table) ApplyBulk(ctx context.Context, rowKeys []string, muts []*Mutatio
 	return nil, nil
 }
 
+// applyGroup applies a group of entries that fits in one request, retrying
+// the entries that fail with a retryable error.
+func (t *Table) applyGroup(ctx context.Context, group []*entryErr, stats *bulkStats, opts ...ApplyOption) error {
+	attrMap := make(map[string]interface{})
+	return gax.Invoke(ctx, func(ctx context.Context, _ gax.CallSettings) error {
+		attrMap["rowCount"] = len(group)
+		trace.TracePrintf(ctx, attrMap, "Row count in ApplyBulk")
+		atomic.AddInt64(&stats.requests, 1)
+		err := t.doApplyBulk(ctx, group, opts...)
+		if err != nil {
+			// We want to retry the entire request with the current group
+			return err
+		}
+		group = t.getApplyBulkRetries(group)
+		if len(group) > 0 && len(idempotentRetryCodes) > 0 {
+			// We have at least one mutation that needs to be retried.
+			// Return an arbitrary error that is retryable according to callOptions.
+			atomic.AddInt64(&stats.retried, int64(len(group)))
+			return status.Errorf(idempotentRetryCodes[0], "Synthetic error: partial failure of ApplyBulk")
+		}
+		return nil
+	}, retryOptions...)
+}
+
+// BulkFlowControl configures how ApplyBulk splits its mutations into
+// concurrent requests. See ApplyBulkFlowControl.
+type BulkFlowControl struct {
+	// MaxMutationsPerRequest is the maximum number of mutations in a request.
+	// An entry with more mutations is sent in a request of its own. Zero means
+	// the limit of the service, 100,000.
+	MaxMutationsPerRequest int
+
+	// MaxOutstandingMutations is the maximum number of mutations in requests
+	// that have been sent but not yet completed. Zero means no limit.
+	MaxOutstandingMutations int
+
+	// MaxOutstandingBytes is the maximum encoded size of the entries in
+	// requests that have been sent but not yet completed. Zero means no
+	// limit.
+	MaxOutstandingBytes int
+}
+
+// ApplyBulkFlowControl returns an ApplyOption that makes ApplyBulk send its
+// mutations in concurrent requests, as configured by fc. A request is always
+// sent when no other is outstanding, even if it exceeds the limits on its
+// own. The option has no effect on Apply.
+func ApplyBulkFlowControl(fc BulkFlowControl) ApplyOption {
+	return &bulkFlowControl{fc}
+}
+
+type bulkFlowControl struct {
+	BulkFlowControl
+}
+
+func (*bulkFlowControl) after(proto.Message) {}
+
+// BulkApplyResult is a summary of a call to ApplyBulk.
+type BulkApplyResult struct {
+	// Entries is the number of rows that ApplyBulk was asked to mutate.
+	Entries int
+
+	// Failed is the number of entries that failed, after any retries. If the
+	// whole call failed, entries that were never attempted are not counted.
+	Failed int
+
+	// Retried is the number of times that entries were retried after failing
+	// with a retryable error.
+	Retried int
+
+	// Requests is the number of MutateRows requests made, including retries.
+	Requests int
+}
+
+// GetBulkApplyResult returns an ApplyOption that stores a summary of the
+// ApplyBulk call into result when the call returns. The option has no effect
+// on Apply.
+func GetBulkApplyResult(result *BulkApplyResult) ApplyOption {
+	return bulkApplyResult{result: result}
+}
+
+type bulkApplyResult struct {
+	result *BulkApplyResult
+}
+
+func (bulkApplyResult) after(proto.Message) {}
+
+// bulkStats counts the requests and retries of an ApplyBulk call, which may
+// be made concurrently.
+type bulkStats struct {
+	requests, retried int64
+}
+
+func (s *bulkStats) fill(r *BulkApplyResult, entries []*entryErr) {
+	*r = BulkApplyResult{
+		Entries:  len(entries),
+		Retried:  int(atomic.LoadInt64(&s.retried)),
+		Requests: int(atomic.LoadInt64(&s.requests)),
+	}
+	for _, e := range entries {
+		if e.Err != nil {
+			r.Failed++
+		}
+	}
+}
+
+// flowController limits the mutations and bytes of the requests of an
+// ApplyBulk call that are outstanding at the same time.
+type flowController struct {
+	BulkFlowControl
+
+	mu   sync.Mutex
+	cond *sync.Cond
+	// The requests, mutations and bytes outstanding.
+	requests, mutations, bytes int
+}
+
+func newFlowController(conf BulkFlowControl) *flowController {
+	if conf.MaxMutationsPerRequest <= 0 || conf.MaxMutationsPerRequest > maxMutations {
+		conf.MaxMutationsPerRequest = maxMutations
+	}
+	fc := &flowController{BulkFlowControl: conf}
+	fc.cond = sync.NewCond(&fc.mu)
+	return fc
+}
+
+// run splits entries into groups of MaxMutationsPerRequest mutations, and
+// calls f for each group in its own goroutine once the group fits within the
+// outstanding limits. It returns the first error returned by f.
+func (fc *flowController) run(ctx context.Context, entries []*entryErr, f func([]*entryErr) error) error {
+	var (
+		wg       sync.WaitGroup
+		mu       sync.Mutex
+		firstErr error
+	)
+	setErr := func(err error) {
+		mu.Lock()
+		defer mu.Unlock()
+		if firstErr == nil {
+			firstErr = err
+		}
+	}
+	for _, group := range groupEntries(entries, fc.MaxMutationsPerRequest) {
+		muts, bytes := entriesSize(group)
+		fc.acquire(muts, bytes)
+		if err := ctx.Err(); err != nil {
+			fc.release(muts, bytes)
+			setErr(err)
+			break
+		}
+		wg.Add(1)
+		go func(group []*entryErr) {
+			defer wg.Done()
+			defer fc.release(muts, bytes)
+			if err := f(group); err != nil {
+				setErr(err)
+			}
+		}(group)
+	}
+	wg.Wait()
+	return firstErr
+}
+
+// acquire waits until a request of the given size fits within the
+// outstanding limits, and adds it to the outstanding requests.
+func (fc *flowController) acquire(muts, bytes int) {
+	fc.mu.Lock()
+	defer fc.mu.Unlock()
+	for fc.requests > 0 && fc.exceeds(muts, bytes) {
+		fc.cond.Wait()
+	}
+	fc.requests++
+	fc.mutations += muts
+	fc.bytes += bytes
+}
+
+func (fc *flowController) exceeds(muts, bytes int) bool {
+	return (fc.MaxOutstandingMutations > 0 && fc.mutations+muts > fc.MaxOutstandingMutations) ||
+		(fc.MaxOutstandingBytes > 0 && fc.bytes+bytes > fc.MaxOutstandingBytes)
+}
+
+func (fc *flowController) release(muts, bytes int) {
+	fc.mu.Lock()
+	defer fc.mu.Unlock()
+	fc.requests--
+	fc.mutations -= muts
+	fc.bytes -= bytes
+	fc.cond.Broadcast()
+}
+
+// entriesSize returns the number of mutations and the encoded size of
+// entries.
+func entriesSize(entries []*entryErr) (muts, bytes int) {
+	for _, e := range entries {
+		muts += len(e.Entry.Mutations)
+		bytes += proto.Size(e.Entry)
+	}
+	return muts, bytes
+}
+
 // getApplyBulkRetries returns the entries that need to be retried
 func (t *Table) getApplyBulkRetries(entries []*entryErr) []*entryErr {
 	var retryEntries []*entryErr
@@ -17,7 +17,9 @@ package bigtable
 
 import (
 	"context"
+	"fmt"
 	"strings"
+	"sync"
 	"testing"
 	"time"
 
@@ -331,6 +333,90 @@ func TestRetryApplyBulk_IndividualErrorsAndDeadlineExceeded(t *testing.T) {
 	}
 }
 
+func TestRetryApplyBulk_FlowControl(t *testing.T) {
+	ctx := context.Background()
+
+	var (
+		mu                  sync.Mutex
+		inFlight, maxFlight int
+		row3Failed          bool
+	)
+	errInjector := func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if !strings.HasSuffix(info.FullMethod, "MutateRows") {
+			return handler(ctx, ss)
+		}
+		req := new(btpb.MutateRowsRequest)
+		must(ss.RecvMsg(req))
+		if len(req.Entries) > 2 {
+			t.Errorf("got %d entries in a request, want at most 2", len(req.Entries))
+		}
+		mu.Lock()
+		inFlight += len(req.Entries)
+		if inFlight > maxFlight {
+			maxFlight = inFlight
+		}
+		var codeList []codes.Code
+		for _, e := range req.Entries {
+			switch {
+			case string(e.RowKey) == "row3" && !row3Failed:
+				// Retryable failure of a single entry
+				row3Failed = true
+				codeList = append(codeList, codes.Unavailable)
+			case string(e.RowKey) == "bad":
+				codeList = append(codeList, codes.FailedPrecondition)
+			default:
+				codeList = append(codeList, codes.OK)
+			}
+		}
+		mu.Unlock()
+		time.Sleep(10 * time.Millisecond)
+		mu.Lock()
+		inFlight -= len(req.Entries)
+		mu.Unlock()
+		return writeMutateRowsResponse(ss, codeList...)
+	}
+
+	tbl, cleanup, err := setupFakeServer(grpc.StreamInterceptor(errInjector))
+	if err != nil {
+		t.Fatalf("fake server setup: %v", err)
+	}
+	defer cleanup()
+
+	var rowKeys []string
+	var muts []*Mutation
+	for i := 0; i < 10; i++ {
+		m := NewMutation()
+		m.Set("cf", "col", 1, []byte{})
+		rowKeys = append(rowKeys, fmt.Sprintf("row%d", i))
+		muts = append(muts, m)
+	}
+	bad := NewMutation()
+	bad.Set("cf", "col", 1, []byte{})
+	rowKeys = append(rowKeys, "bad")
+	muts = append(muts, bad)
+
+	var res BulkApplyResult
+	errs, err := tbl.ApplyBulk(ctx, rowKeys, muts,
+		ApplyBulkFlowControl(BulkFlowControl{MaxMutationsPerRequest: 2, MaxOutstandingMutations: 4}),
+		GetBulkApplyResult(&res))
+	if err != nil {
+		t.Fatalf("bulk with flow control: %v", err)
+	}
+	for i, err := range errs {
+		if wantErr := rowKeys[i] == "bad"; (err != nil) != wantErr {
+			t.Errorf("entry %q: got error %v, want error: %v", rowKeys[i], err, wantErr)
+		}
+	}
+	if maxFlight > 4 {
+		t.Errorf("got %d outstanding mutations, want at most 4", maxFlight)
+	}
+	// Six requests for the eleven entries, and one to retry row3.
+	want := BulkApplyResult{Entries: 11, Failed: 1, Retried: 1, Requests: 7}
+	if res != want {
+		t.Errorf("got result %+v, want %+v", res, want)
+	}
+}
+
 func writeMutateRowsResponse(ss grpc.ServerStream, codes ...codes.Code) error {
 	res := &btpb.MutateRowsResponse{Entries: make([]*btpb.MutateRowsResponse_Entry, len(codes))}
 	for i, code := range codes {
@@ -0,0 +1,296 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package datastore
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	admin "cloud.google.com/go/datastore/admin/apiv1"
+	"cloud.google.com/go/internal/trace"
+	gax "github.com/googleapis/gax-go/v2"
+	"google.golang.org/api/option"
+	adminpb "google.golang.org/genproto/googleapis/datastore/admin/v1"
+)
+
+// adminPollBackoff is the backoff between polls of the export and import
+// operations of an AdminClient.
+var adminPollBackoff = gax.Backoff{Initial: 5 * time.Second, Max: time.Minute, Multiplier: 1.5}
+
+// AdminClient is a client for exporting the entities of a project to Cloud
+// Storage and importing them back, for example for backups. It wraps the
+// export and import operations of the Datastore Admin API; use package
+// cloud.google.com/go/datastore/admin/apiv1 for the rest of that API.
+type AdminClient struct {
+	admin     *admin.DatastoreAdminClient
+	projectID string
+}
+
+// NewAdminClient creates a new AdminClient for the given project. Call Close
+// when done with the client.
+func NewAdminClient(ctx context.Context, projectID string, opts ...option.ClientOption) (*AdminClient, error) {
+	if projectID == DetectProjectID {
+		detected, err := detectProjectID(ctx, opts...)
+		if err != nil {
+			return nil, err
+		}
+		projectID = detected
+	}
+	if projectID == "" {
+		return nil, errors.New("datastore: missing project id")
+	}
+	c, err := admin.NewDatastoreAdminClient(ctx, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &AdminClient{admin: c, projectID: projectID}, nil
+}
+
+// Close closes the AdminClient.
+func (c *AdminClient) Close() error {
+	return c.admin.Close()
+}
+
+// EntityFilter selects the entities of an export or import.
+type EntityFilter struct {
+	// Kinds are the kinds of the entities. If empty, entities of all kinds are
+	// selected.
+	Kinds []string
+
+	// Namespaces are the namespaces of the entities, with the empty string
+	// for the default namespace. If empty, entities in all namespaces are
+	// selected.
+	Namespaces []string
+}
+
+func (f EntityFilter) toProto() *adminpb.EntityFilter {
+	return &adminpb.EntityFilter{Kinds: f.Kinds, NamespaceIds: f.Namespaces}
+}
+
+// ExportOptions are the options of AdminClient.ExportEntities.
+type ExportOptions struct {
+	// EntityFilter selects the entities to export. By default, all entities
+	// are exported.
+	EntityFilter
+
+	// Labels are client-assigned labels of the export operation.
+	Labels map[string]string
+}
+
+// ImportOptions are the options of AdminClient.ImportEntities.
+type ImportOptions struct {
+	// EntityFilter selects the entities to import among those of the export.
+	// By default, all of them are imported. The filter must select a subset
+	// of the entities selected by the export.
+	EntityFilter
+
+	// Labels are client-assigned labels of the import operation.
+	Labels map[string]string
+}
+
+// OperationProgress describes the progress of an export or import operation.
+type OperationProgress struct {
+	// State is the state of the operation, such as "PROCESSING" or
+	// "SUCCESSFUL".
+	State string
+
+	// StartTime is the time that the operation started. EndTime is the time
+	// that it finished, or zero if it is still running.
+	StartTime, EndTime time.Time
+
+	// Entities and Bytes are the numbers of entities and bytes processed so
+	// far, and EstimatedEntities and EstimatedBytes the estimates of their
+	// totals.
+	Entities, EstimatedEntities int64
+	Bytes, EstimatedBytes       int64
+}
+
+func newOperationProgress(common *adminpb.CommonMetadata, entities, bytes *adminpb.Progress) *OperationProgress {
+	p := &OperationProgress{
+		State:             common.GetState().String(),
+		Entities:          entities.GetWorkCompleted(),
+		EstimatedEntities: entities.GetWorkEstimated(),
+		Bytes:             bytes.GetWorkCompleted(),
+		EstimatedBytes:    bytes.GetWorkEstimated(),
+	}
+	if t := common.GetStartTime(); t != nil {
+		p.StartTime = t.AsTime()
+	}
+	if t := common.GetEndTime(); t != nil {
+		p.EndTime = t.AsTime()
+	}
+	return p
+}
+
+// ExportEntities starts exporting entities to Cloud Storage, and returns the
+// operation that does so. outputURLPrefix is the location of the export, of
+// the form gs://BUCKET_NAME[/NAMESPACE_PATH]; the export is written to a new
+// directory under it.
+func (c *AdminClient) ExportEntities(ctx context.Context, outputURLPrefix string, opts *ExportOptions) (_ *ExportOperation, err error) {
+	ctx = trace.StartSpan(ctx, "cloud.google.com/go/datastore.AdminClient.ExportEntities")
+	defer func() { trace.EndSpan(ctx, err) }()
+
+	if opts == nil {
+		opts = &ExportOptions{}
+	}
+	op, err := c.admin.ExportEntities(ctx, &adminpb.ExportEntitiesRequest{
+		ProjectId:       c.projectID,
+		Labels:          opts.Labels,
+		EntityFilter:    opts.EntityFilter.toProto(),
+		OutputUrlPrefix: outputURLPrefix,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &ExportOperation{op: op}, nil
+}
+
+// ExportOperation returns the export operation with the given name, which
+// must have been started by ExportEntities, possibly in another process.
+func (c *AdminClient) ExportOperation(name string) *ExportOperation {
+	return &ExportOperation{op: c.admin.ExportEntitiesOperation(name)}
+}
+
+// ImportEntities starts importing entities from an export, and returns the
+// operation that does so. inputURL is the location of the overall_export_metadata
+// file of the export, as reported by ExportOperation.Wait. Imported entities
+// overwrite existing entities with the same keys.
+func (c *AdminClient) ImportEntities(ctx context.Context, inputURL string, opts *ImportOptions) (_ *ImportOperation, err error) {
+	ctx = trace.StartSpan(ctx, "cloud.google.com/go/datastore.AdminClient.ImportEntities")
+	defer func() { trace.EndSpan(ctx, err) }()
+
+	if opts == nil {
+		opts = &ImportOptions{}
+	}
+	op, err := c.admin.ImportEntities(ctx, &adminpb.ImportEntitiesRequest{
+		ProjectId:    c.projectID,
+		Labels:       opts.Labels,
+		InputUrl:     inputURL,
+		EntityFilter: opts.EntityFilter.toProto(),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &ImportOperation{op: op}, nil
+}
+
+// ImportOperation returns the import operation with the given name, which
+// must have been started by ImportEntities, possibly in another process.
+func (c *AdminClient) ImportOperation(name string) *ImportOperation {
+	return &ImportOperation{op: c.admin.ImportEntitiesOperation(name)}
+}
+
+// ExportOperation is a running export of entities.
+type ExportOperation struct {
+	op   *admin.ExportEntitiesOperation
+	resp *adminpb.ExportEntitiesResponse
+}
+
+// Name returns the name of the operation, which AdminClient.ExportOperation
+// accepts.
+func (op *ExportOperation) Name() string {
+	return op.op.Name()
+}
+
+// Poll fetches the latest state of the operation, and reports whether it has
+// finished. If the operation failed, Poll returns its error.
+func (op *ExportOperation) Poll(ctx context.Context) (done bool, err error) {
+	resp, err := op.op.Poll(ctx)
+	if err != nil {
+		return false, err
+	}
+	op.resp = resp
+	return op.op.Done(), nil
+}
+
+// Progress returns the progress of the operation as of the last call to Poll,
+// or nil if it is not known yet.
+func (op *ExportOperation) Progress() (*OperationProgress, error) {
+	md, err := op.op.Metadata()
+	if err != nil || md == nil {
+		return nil, err
+	}
+	return newOperationProgress(md.Common, md.ProgressEntities, md.ProgressBytes), nil
+}
+
+// Wait polls the operation until it finishes, and returns the location of the
+// export, which ImportEntities accepts. If progress is not nil, it is called
+// with the progress of the operation after each poll.
+func (op *ExportOperation) Wait(ctx context.Context, progress func(*OperationProgress)) (outputURL string, err error) {
+	if err := waitOperation(ctx, op.Poll, op.Progress, progress); err != nil {
+		return "", err
+	}
+	return op.resp.GetOutputUrl(), nil
+}
+
+// ImportOperation is a running import of entities.
+type ImportOperation struct {
+	op *admin.ImportEntitiesOperation
+}
+
+// Name returns the name of the operation, which AdminClient.ImportOperation
+// accepts.
+func (op *ImportOperation) Name() string {
+	return op.op.Name()
+}
+
+// Poll fetches the latest state of the operation, and reports whether it has
+// finished. If the operation failed, Poll returns its error.
+func (op *ImportOperation) Poll(ctx context.Context) (done bool, err error) {
+	if err := op.op.Poll(ctx); err != nil {
+		return false, err
+	}
+	return op.op.Done(), nil
+}
+
+// Progress returns the progress of the operation as of the last call to Poll,
+// or nil if it is not known yet.
+func (op *ImportOperation) Progress() (*OperationProgress, error) {
+	md, err := op.op.Metadata()
+	if err != nil || md == nil {
+		return nil, err
+	}
+	return newOperationProgress(md.Common, md.ProgressEntities, md.ProgressBytes), nil
+}
+
+// Wait polls the operation until it finishes. If progress is not nil, it is
+// called with the progress of the operation after each poll.
+func (op *ImportOperation) Wait(ctx context.Context, progress func(*OperationProgress)) error {
+	return waitOperation(ctx, op.Poll, op.Progress, progress)
+}
+
+// waitOperation polls an operation with adminPollBackoff until it finishes,
+// reporting its progress to f if f is not nil.
+func waitOperation(ctx context.Context, poll func(context.Context) (bool, error), progress func() (*OperationProgress, error), f func(*OperationProgress)) error {
+	bo := adminPollBackoff
+	for {
+		done, err := poll(ctx)
+		if err != nil {
+			return err
+		}
+		if f != nil {
+			if p, err := progress(); err == nil && p != nil {
+				f(p)
+			}
+		}
+		if done {
+			return nil
+		}
+		if err := gax.Sleep(ctx, bo.Pause()); err != nil {
+			return err
+		}
+	}
+}
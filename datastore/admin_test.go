@@ -0,0 +1,151 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package datastore
+
+import (
+	"context"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	gax "github.com/googleapis/gax-go/v2"
+	"google.golang.org/api/option"
+	adminpb "google.golang.org/genproto/googleapis/datastore/admin/v1"
+	longrunningpb "google.golang.org/genproto/googleapis/longrunning"
+	statuspb "google.golang.org/genproto/googleapis/rpc/status"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/protobuf/types/known/anypb"
+)
+
+// fakeAdminServer implements the export and import operations of the
+// Datastore Admin API. Exports finish on the second poll, and imports fail.
+type fakeAdminServer struct {
+	adminpb.UnimplementedDatastoreAdminServer
+	longrunningpb.UnimplementedOperationsServer
+
+	mu        sync.Mutex
+	exportReq *adminpb.ExportEntitiesRequest
+	polls     int
+}
+
+func (s *fakeAdminServer) ExportEntities(_ context.Context, req *adminpb.ExportEntitiesRequest) (*longrunningpb.Operation, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.exportReq = req
+	return &longrunningpb.Operation{Name: "operations/export"}, nil
+}
+
+func (s *fakeAdminServer) ImportEntities(context.Context, *adminpb.ImportEntitiesRequest) (*longrunningpb.Operation, error) {
+	return &longrunningpb.Operation{Name: "operations/import"}, nil
+}
+
+func (s *fakeAdminServer) GetOperation(_ context.Context, req *longrunningpb.GetOperationRequest) (*longrunningpb.Operation, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if req.Name == "operations/import" {
+		return &longrunningpb.Operation{
+			Name:   req.Name,
+			Done:   true,
+			Result: &longrunningpb.Operation_Error{Error: &statuspb.Status{Code: int32(codes.InvalidArgument), Message: "bad input"}},
+		}, nil
+	}
+	s.polls++
+	md, err := anypb.New(&adminpb.ExportEntitiesMetadata{
+		Common:           &adminpb.CommonMetadata{State: adminpb.CommonMetadata_PROCESSING},
+		ProgressEntities: &adminpb.Progress{WorkCompleted: int64(5 * s.polls), WorkEstimated: 10},
+	})
+	if err != nil {
+		return nil, err
+	}
+	op := &longrunningpb.Operation{Name: req.Name, Metadata: md}
+	if s.polls >= 2 {
+		resp, err := anypb.New(&adminpb.ExportEntitiesResponse{OutputUrl: "gs://bucket/export/export.overall_export_metadata"})
+		if err != nil {
+			return nil, err
+		}
+		op.Done = true
+		op.Result = &longrunningpb.Operation_Response{Response: resp}
+	}
+	return op, nil
+}
+
+func TestAdminClientExportImport(t *testing.T) {
+	oldBackoff := adminPollBackoff
+	defer func() { adminPollBackoff = oldBackoff }()
+	adminPollBackoff = gax.Backoff{Initial: time.Millisecond}
+
+	s := &fakeAdminServer{}
+	srv := grpc.NewServer()
+	adminpb.RegisterDatastoreAdminServer(srv, s)
+	longrunningpb.RegisterOperationsServer(srv, s)
+	lis, err := net.Listen("tcp", "localhost:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	go srv.Serve(lis)
+	defer srv.Stop()
+
+	ctx := context.Background()
+	client, err := NewAdminClient(ctx, "project",
+		option.WithEndpoint(lis.Addr().String()),
+		option.WithoutAuthentication(),
+		option.WithGRPCDialOption(grpc.WithInsecure()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+
+	op, err := client.ExportEntities(ctx, "gs://bucket/export", &ExportOptions{
+		EntityFilter: EntityFilter{Kinds: []string{"Gopher"}, Namespaces: []string{""}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantReq := &adminpb.ExportEntitiesRequest{
+		ProjectId:       "project",
+		EntityFilter:    &adminpb.EntityFilter{Kinds: []string{"Gopher"}, NamespaceIds: []string{""}},
+		OutputUrlPrefix: "gs://bucket/export",
+	}
+	if !proto.Equal(s.exportReq, wantReq) {
+		t.Errorf("got request %v, want %v", s.exportReq, wantReq)
+	}
+	var progress []int64
+	url, err := client.ExportOperation(op.Name()).Wait(ctx, func(p *OperationProgress) {
+		if p.State != "PROCESSING" || p.EstimatedEntities != 10 {
+			t.Errorf("got progress %+v", p)
+		}
+		progress = append(progress, p.Entities)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "gs://bucket/export/export.overall_export_metadata"; url != want {
+		t.Errorf("got output URL %q, want %q", url, want)
+	}
+	if len(progress) != 2 || progress[0] != 5 || progress[1] != 10 {
+		t.Errorf("got progress %v, want [5 10]", progress)
+	}
+
+	iop, err := client.ImportEntities(ctx, url, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := iop.Wait(ctx, nil); err == nil {
+		t.Error("got nil error from failed import")
+	}
+}
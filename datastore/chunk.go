@@ -0,0 +1,158 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package datastore
+
+import (
+	"context"
+	"sync"
+
+	"github.com/golang/protobuf/proto"
+	pb "google.golang.org/genproto/googleapis/datastore/v1"
+)
+
+// Limits of the backend on the size of a single request. GetMulti, PutMulti
+// and DeleteMulti split larger batches into several requests.
+// Vars for testing.
+var (
+	maxLookupKeys      = 1000
+	maxCommitMutations = 500
+	maxCommitBytes     = 10 << 20
+)
+
+// chunk is the range [start, end) of a batch that is sent in one request.
+type chunk struct {
+	start, end int
+}
+
+// splitChunks splits a batch of n items into chunks of at most maxCount items
+// whose sizes, as given by size, sum to at most maxBytes. A single item larger
+// than maxBytes gets a chunk of its own, for the backend to reject. If size is
+// nil, only the number of items is limited.
+func splitChunks(n, maxCount, maxBytes int, size func(i int) int) []chunk {
+	var chunks []chunk
+	start, bytes := 0, 0
+	for i := 0; i < n; i++ {
+		s := 0
+		if size != nil {
+			s = size(i)
+		}
+		if i > start && (i-start == maxCount || bytes+s > maxBytes) {
+			chunks = append(chunks, chunk{start, i})
+			start, bytes = i, 0
+		}
+		bytes += s
+	}
+	return append(chunks, chunk{start, n})
+}
+
+// runChunks calls f for each of the chunks of a batch of n items,
+// concurrently. If there is a single chunk, runChunks returns its error as is.
+// Otherwise it returns a MultiError in correspondence with the items of the
+// batch, holding for each item the error of its chunk.
+func runChunks(n int, chunks []chunk, f func(chunk) error) error {
+	if len(chunks) == 1 {
+		return f(chunks[0])
+	}
+	errs := make([]error, len(chunks))
+	var wg sync.WaitGroup
+	for i, ch := range chunks {
+		wg.Add(1)
+		go func(i int, ch chunk) {
+			defer wg.Done()
+			errs[i] = f(ch)
+		}(i, ch)
+	}
+	wg.Wait()
+
+	var merr MultiError
+	for i, err := range errs {
+		if err == nil {
+			continue
+		}
+		if merr == nil {
+			merr = make(MultiError, n)
+		}
+		for j := chunks[i].start; j < chunks[i].end; j++ {
+			merr[j] = err
+		}
+	}
+	if merr != nil {
+		return merr
+	}
+	return nil
+}
+
+// lookup looks up keys in requests of at most maxLookupKeys keys, following
+// up on deferred keys. The entities found and missing are returned in no
+// particular order. The error is as described in runChunks; on a MultiError
+// the results of the successful requests are still returned.
+func (c *Client) lookup(ctx context.Context, keys []*pb.Key, opts *pb.ReadOptions) (found, missing []*pb.EntityResult, err error) {
+	var mu sync.Mutex
+	err = runChunks(len(keys), splitChunks(len(keys), maxLookupKeys, 0, nil), func(ch chunk) error {
+		req := &pb.LookupRequest{
+			ProjectId:   c.dataset,
+			Keys:        keys[ch.start:ch.end],
+			ReadOptions: opts,
+		}
+		resp, err := c.client.Lookup(ctx, req)
+		if err != nil {
+			return err
+		}
+		f, m := resp.Found, resp.Missing
+		// Upper bound 1000 iterations to prevent infinite loop. This matches the max
+		// number of Entities you can request from Datastore.
+		// Note that if ctx has a deadline, the deadline will probably
+		// be hit before we reach 1000 iterations.
+		for i := 0; len(resp.Deferred) > 0 && i < 1000; i++ {
+			req.Keys = resp.Deferred
+			resp, err = c.client.Lookup(ctx, req)
+			if err != nil {
+				return err
+			}
+			f = append(f, resp.Found...)
+			m = append(m, resp.Missing...)
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		found = append(found, f...)
+		missing = append(missing, m...)
+		return nil
+	})
+	return found, missing, err
+}
+
+// commit commits mutations non-transactionally, in requests within
+// maxCommitMutations and maxCommitBytes. It returns the results of the
+// mutations in order. The error is as described in runChunks; on a
+// MultiError the results of the successful requests are still returned.
+func (c *Client) commit(ctx context.Context, mutations []*pb.Mutation) ([]*pb.MutationResult, error) {
+	results := make([]*pb.MutationResult, len(mutations))
+	chunks := splitChunks(len(mutations), maxCommitMutations, maxCommitBytes, func(i int) int {
+		return proto.Size(mutations[i])
+	})
+	err := runChunks(len(mutations), chunks, func(ch chunk) error {
+		resp, err := c.client.Commit(ctx, &pb.CommitRequest{
+			ProjectId: c.dataset,
+			Mutations: mutations[ch.start:ch.end],
+			Mode:      pb.CommitRequest_NON_TRANSACTIONAL,
+		})
+		if err != nil {
+			return err
+		}
+		copy(results[ch.start:ch.end], resp.MutationResults)
+		return nil
+	})
+	return results, err
+}
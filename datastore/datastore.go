@@ -21,6 +21,7 @@ import (
 	"log"
 	"os"
 	"reflect"
+	"time"
 
 	"cloud.google.com/go/internal/trace"
 	"google.golang.org/api/option"
@@ -28,6 +29,7 @@ import (
 	gtransport "google.golang.org/api/transport/grpc"
 	pb "google.golang.org/genproto/googleapis/datastore/v1"
 	"google.golang.org/grpc"
+	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
 const (
@@ -56,6 +58,8 @@ type Client struct {
 	connPool gtransport.ConnPool
 	client   pb.DatastoreClient
 	dataset  string // Called dataset by the datastore API, synonym for project ID.
+
+	readSettings *readSettings // set by WithReadOptions
 }
 
 // NewClient creates a new Client for a given dataset.  If the project ID is
@@ -329,6 +333,56 @@ func (c *Client) Close() error {
 	return c.connPool.Close()
 }
 
+// ReadOption configures the reads of a Client returned by WithReadOptions.
+type ReadOption interface {
+	applyRead(*readSettings)
+}
+
+type readSettings struct {
+	readTime time.Time
+}
+
+// ReadTime returns a ReadOption that reads entities as they were at t instead
+// of their latest versions. t must be within the point-in-time recovery window
+// of the database: up to 7 days in the past if point-in-time recovery is
+// enabled, and up to an hour otherwise. Beyond the last hour, t must be a
+// whole minute.
+func ReadTime(t time.Time) ReadOption {
+	return readTime(t)
+}
+
+type readTime time.Time
+
+func (t readTime) applyRead(s *readSettings) {
+	s.readTime = time.Time(t)
+}
+
+// WithReadOptions returns a Client that applies the options to its Get and
+// GetMulti calls and to the queries it runs outside transactions. Writes and
+// transactions are not affected; use WithReadTime for a read-only transaction
+// at a point in time. The returned Client shares the connection of c, so
+// closing either closes both.
+func (c *Client) WithReadOptions(opts ...ReadOption) *Client {
+	s := &readSettings{}
+	for _, o := range opts {
+		o.applyRead(s)
+	}
+	c2 := *c
+	c2.readSettings = s
+	return &c2
+}
+
+// readOptions returns the read options of the reads of c outside
+// transactions, or nil for the defaults.
+func (c *Client) readOptions() *pb.ReadOptions {
+	if c.readSettings == nil || c.readSettings.readTime.IsZero() {
+		return nil
+	}
+	return &pb.ReadOptions{
+		ConsistencyType: &pb.ReadOptions_ReadTime{ReadTime: timestamppb.New(c.readSettings.readTime)},
+	}
+}
+
 // Get loads the entity stored for key into dst, which must be a struct pointer
 // or implement PropertyLoadSaver. If there is no such entity for the key, Get
 // returns ErrNoSuchEntity.
@@ -348,7 +402,7 @@ func (c *Client) Get(ctx context.Context, key *Key, dst interface{}) (err error)
 	if dst == nil { // get catches nil interfaces; we need to catch nil ptr here
 		return ErrInvalidEntityType
 	}
-	err = c.get(ctx, []*Key{key}, []interface{}{dst}, nil)
+	err = c.get(ctx, []*Key{key}, []interface{}{dst}, c.readOptions())
 	if me, ok := err.(MultiError); ok {
 		return me[0]
 	}
@@ -366,12 +420,16 @@ func (c *Client) Get(ctx context.Context, key *Key, dst interface{}) (err error)
 // PropertyList is a slice of structs. It is treated as invalid to avoid being
 // mistakenly passed when []PropertyList was intended.
 //
+// Batches larger than the backend allows in a single request are split into
+// several requests, which are made concurrently. If some of them fail, the
+// keys of the failed requests get their errors in a MultiError.
+//
 // err may be a MultiError. See ExampleMultiError to check it.
 func (c *Client) GetMulti(ctx context.Context, keys []*Key, dst interface{}) (err error) {
 	ctx = trace.StartSpan(ctx, "cloud.google.com/go/datastore.GetMulti")
 	defer func() { trace.EndSpan(ctx, err) }()
 
-	return c.get(ctx, keys, dst, nil)
+	return c.get(ctx, keys, dst, c.readOptions())
 }
 
 func (c *Client) get(ctx context.Context, keys []*Key, dst interface{}, opts *pb.ReadOptions) error {
@@ -394,6 +452,7 @@ func (c *Client) get(ctx context.Context, keys []*Key, dst interface{}, opts *pb
 	multiErr, any := make(MultiError, len(keys)), false
 	keyMap := make(map[string][]int, len(keys))
 	pbKeys := make([]*pb.Key, 0, len(keys))
+	pbKeyStrs := make([]string, 0, len(keys))
 	for i, k := range keys {
 		if !k.valid() {
 			multiErr[i] = ErrInvalidKey
@@ -405,6 +464,7 @@ func (c *Client) get(ctx context.Context, keys []*Key, dst interface{}, opts *pb
 			ks := k.String()
 			if _, ok := keyMap[ks]; !ok {
 				pbKeys = append(pbKeys, keyToProto(k))
+				pbKeyStrs = append(pbKeyStrs, ks)
 			}
 			keyMap[ks] = append(keyMap[ks], i)
 		}
@@ -412,32 +472,26 @@ func (c *Client) get(ctx context.Context, keys []*Key, dst interface{}, opts *pb
 	if any {
 		return multiErr
 	}
-	req := &pb.LookupRequest{
-		ProjectId:   c.dataset,
-		Keys:        pbKeys,
-		ReadOptions: opts,
-	}
-	resp, err := c.client.Lookup(ctx, req)
+	found, missing, err := c.lookup(ctx, pbKeys, opts)
+	filled := 0
 	if err != nil {
-		return err
-	}
-	found := resp.Found
-	missing := resp.Missing
-	// Upper bound 1000 iterations to prevent infinite loop. This matches the max
-	// number of Entities you can request from Datastore.
-	// Note that if ctx has a deadline, the deadline will probably
-	// be hit before we reach 1000 iterations.
-	for i := 0; len(resp.Deferred) > 0 && i < 1000; i++ {
-		req.Keys = resp.Deferred
-		resp, err = c.client.Lookup(ctx, req)
-		if err != nil {
+		// The keys of the failed requests get their errors, and the
+		// results of the others are loaded below.
+		lookupErr, ok := err.(MultiError)
+		if !ok {
 			return err
 		}
-		found = append(found, resp.Found...)
-		missing = append(missing, resp.Missing...)
+		for i, err := range lookupErr {
+			if err == nil {
+				continue
+			}
+			filled += len(keyMap[pbKeyStrs[i]])
+			for _, index := range keyMap[pbKeyStrs[i]] {
+				multiErr[index] = err
+			}
+			any = true
+		}
 	}
-
-	filled := 0
 	for _, e := range found {
 		k, err := protoToKey(e.Entity.Key)
 		if err != nil {
@@ -498,6 +552,10 @@ func (c *Client) Put(ctx context.Context, key *Key, src interface{}) (*Key, erro
 // PutMulti is a batch version of Put.
 //
 // src must satisfy the same conditions as the dst argument to GetMulti.
+// Large batches are split into several requests as described in GetMulti. As
+// the requests are not atomic together, some of them may succeed while others
+// fail; the keys of the successful ones are then returned along with a
+// MultiError.
 // err may be a MultiError. See ExampleMultiError to check it.
 func (c *Client) PutMulti(ctx context.Context, keys []*Key, src interface{}) (ret []*Key, err error) {
 	// TODO(jba): rewrite in terms of Mutate.
@@ -509,23 +567,23 @@ func (c *Client) PutMulti(ctx context.Context, keys []*Key, src interface{}) (re
 		return nil, err
 	}
 
-	// Make the request.
-	req := &pb.CommitRequest{
-		ProjectId: c.dataset,
-		Mutations: mutations,
-		Mode:      pb.CommitRequest_NON_TRANSACTIONAL,
-	}
-	resp, err := c.client.Commit(ctx, req)
-	if err != nil {
-		return nil, err
+	// Make the requests. If some of several requests fail, the keys of the
+	// others are still returned along with the MultiError.
+	results, commitErr := c.commit(ctx, mutations)
+	multiErr, ok := commitErr.(MultiError)
+	if commitErr != nil && !ok {
+		return nil, commitErr
 	}
 
 	// Copy any newly minted keys into the returned keys.
 	ret = make([]*Key, len(keys))
 	for i, key := range keys {
+		if multiErr != nil && multiErr[i] != nil {
+			continue
+		}
 		if key.Incomplete() {
 			// This key is in the mutation results.
-			ret[i], err = protoToKey(resp.MutationResults[i].Key)
+			ret[i], err = protoToKey(results[i].Key)
 			if err != nil {
 				return nil, errors.New("datastore: internal error: server returned an invalid key")
 			}
@@ -533,7 +591,7 @@ func (c *Client) PutMulti(ctx context.Context, keys []*Key, src interface{}) (re
 			ret[i] = key
 		}
 	}
-	return ret, nil
+	return ret, commitErr
 }
 
 func putMutations(keys []*Key, src interface{}) ([]*pb.Mutation, error) {
@@ -592,6 +650,9 @@ func (c *Client) Delete(ctx context.Context, key *Key) error {
 
 // DeleteMulti is a batch version of Delete.
 //
+// Large batches are split into several requests as described in GetMulti, so
+// some deletions may be applied even if others fail.
+//
 // err may be a MultiError. See ExampleMultiError to check it.
 func (c *Client) DeleteMulti(ctx context.Context, keys []*Key) (err error) {
 	// TODO(jba): rewrite in terms of Mutate.
@@ -603,13 +664,25 @@ func (c *Client) DeleteMulti(ctx context.Context, keys []*Key) (err error) {
 		return err
 	}
 
-	req := &pb.CommitRequest{
-		ProjectId: c.dataset,
-		Mutations: mutations,
-		Mode:      pb.CommitRequest_NON_TRANSACTIONAL,
+	_, err = c.commit(ctx, mutations)
+	commitErr, ok := err.(MultiError)
+	if !ok {
+		return err
 	}
-	_, err = c.client.Commit(ctx, req)
-	return err
+	// Duplicate keys were removed from the mutations, so map the errors of
+	// the mutations back to all of their keys.
+	index := make(map[string]int, len(mutations))
+	for _, k := range keys {
+		ks := k.String()
+		if _, ok := index[ks]; !ok {
+			index[ks] = len(index)
+		}
+	}
+	multiErr := make(MultiError, len(keys))
+	for i, k := range keys {
+		multiErr[i] = commitErr[index[k.String()]]
+	}
+	return multiErr
 }
 
 func deleteMutations(keys []*Key) ([]*pb.Mutation, error) {
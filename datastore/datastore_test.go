@@ -22,6 +22,7 @@ import (
 	"reflect"
 	"sort"
 	"strings"
+	"sync"
 	"testing"
 	"time"
 
@@ -30,6 +31,7 @@ import (
 	"github.com/google/go-cmp/cmp"
 	pb "google.golang.org/genproto/googleapis/datastore/v1"
 	"google.golang.org/grpc"
+	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
 type (
@@ -3557,6 +3559,182 @@ func TestDeleteMultiWithIncompleteKey(t *testing.T) {
 	}
 }
 
+func TestReadTime(t *testing.T) {
+	var lookupOpts, queryOpts *pb.ReadOptions
+	client := &Client{
+		dataset: "project",
+		client: &fakeDatastoreClient{
+			lookup: func(req *pb.LookupRequest) (*pb.LookupResponse, error) {
+				lookupOpts = req.ReadOptions
+				return &pb.LookupResponse{Missing: []*pb.EntityResult{{Entity: &pb.Entity{Key: keyToProto(NameKey("K", "a", nil))}}}}, nil
+			},
+			runQuery: func(req *pb.RunQueryRequest) (*pb.RunQueryResponse, error) {
+				queryOpts = req.ReadOptions
+				return &pb.RunQueryResponse{Batch: &pb.QueryResultBatch{MoreResults: pb.QueryResultBatch_NO_MORE_RESULTS}}, nil
+			},
+		},
+	}
+	ctx := context.Background()
+	rc := client.WithReadOptions(ReadTime(time.Unix(1000, 0)))
+	want := &pb.ReadOptions{ConsistencyType: &pb.ReadOptions_ReadTime{ReadTime: &timestamppb.Timestamp{Seconds: 1000}}}
+
+	var e struct{}
+	if err := rc.Get(ctx, NameKey("K", "a", nil), &e); err != ErrNoSuchEntity {
+		t.Fatalf("Get: got %v, want ErrNoSuchEntity", err)
+	}
+	if !proto.Equal(lookupOpts, want) {
+		t.Errorf("Get: got read options %v, want %v", lookupOpts, want)
+	}
+	if _, err := rc.Count(ctx, NewQuery("K")); err != nil {
+		t.Fatal(err)
+	}
+	if !proto.Equal(queryOpts, want) {
+		t.Errorf("Count: got read options %v, want %v", queryOpts, want)
+	}
+	if _, err := rc.Count(ctx, NewQuery("K").EventualConsistency()); err == nil {
+		t.Error("EventualConsistency query with a read time: got nil, want error")
+	}
+
+	// The original client is not affected.
+	if err := client.Get(ctx, NameKey("K", "a", nil), &e); err != ErrNoSuchEntity {
+		t.Fatalf("Get: got %v, want ErrNoSuchEntity", err)
+	}
+	if lookupOpts != nil {
+		t.Errorf("Get without read time: got read options %v, want nil", lookupOpts)
+	}
+}
+
+func TestChunkedMultiOps(t *testing.T) {
+	defer func(l, m int) { maxLookupKeys, maxCommitMutations = l, m }(maxLookupKeys, maxCommitMutations)
+	maxLookupKeys, maxCommitMutations = 2, 2
+
+	type ent struct{ N int }
+	keys := make([]*Key, 5)
+	for i := range keys {
+		keys[i] = IDKey("K", int64(i+1), nil)
+	}
+	failKey := keyToProto(keys[2])
+	rpcErr := errors.New("rpc failed")
+
+	var mu sync.Mutex
+	var lookups, commits int
+	client := &Client{
+		dataset: "project",
+		client: &fakeDatastoreClient{
+			lookup: func(req *pb.LookupRequest) (*pb.LookupResponse, error) {
+				mu.Lock()
+				defer mu.Unlock()
+				lookups++
+				if len(req.Keys) > 2 {
+					t.Errorf("got a lookup of %d keys, want at most 2", len(req.Keys))
+				}
+				resp := &pb.LookupResponse{}
+				for _, k := range req.Keys {
+					if proto.Equal(k, failKey) {
+						return nil, rpcErr
+					}
+					id := k.Path[0].GetId()
+					resp.Found = append(resp.Found, &pb.EntityResult{Entity: &pb.Entity{
+						Key:        k,
+						Properties: map[string]*pb.Value{"N": {ValueType: &pb.Value_IntegerValue{IntegerValue: id}}},
+					}})
+				}
+				return resp, nil
+			},
+			commit: func(req *pb.CommitRequest) (*pb.CommitResponse, error) {
+				mu.Lock()
+				defer mu.Unlock()
+				commits++
+				if len(req.Mutations) > 2 {
+					t.Errorf("got a commit of %d mutations, want at most 2", len(req.Mutations))
+				}
+				resp := &pb.CommitResponse{}
+				for _, m := range req.Mutations {
+					var k *pb.Key
+					switch op := m.Operation.(type) {
+					case *pb.Mutation_Upsert:
+						k = op.Upsert.Key
+					case *pb.Mutation_Delete:
+						k = op.Delete
+					}
+					if proto.Equal(k, failKey) {
+						return nil, rpcErr
+					}
+					resp.MutationResults = append(resp.MutationResults, &pb.MutationResult{Key: k})
+				}
+				return resp, nil
+			},
+		},
+	}
+	ctx := context.Background()
+	// keys[2] is in the same chunk as keys[3], which fails along with it.
+	checkErr := func(op string, err error, n int, failed ...int) {
+		t.Helper()
+		merr, ok := err.(MultiError)
+		if !ok || len(merr) != n {
+			t.Fatalf("%s: got %v, want a MultiError of length %d", op, err, n)
+		}
+		for i, err := range merr {
+			wantErr := false
+			for _, f := range failed {
+				wantErr = wantErr || i == f
+			}
+			if (err == rpcErr) != wantErr {
+				t.Errorf("%s: item %d: got error %v, want error: %v", op, i, err, wantErr)
+			}
+		}
+	}
+
+	dst := make([]ent, len(keys))
+	checkErr("GetMulti", client.GetMulti(ctx, keys, dst), len(keys), 2, 3)
+	if dst[0].N != 1 || dst[4].N != 5 {
+		t.Errorf("GetMulti: got %v, want the entities of the successful lookups", dst)
+	}
+	if lookups != 3 {
+		t.Errorf("GetMulti: got %d lookups, want 3", lookups)
+	}
+
+	ret, err := client.PutMulti(ctx, keys, dst)
+	checkErr("PutMulti", err, len(keys), 2, 3)
+	if !ret[4].Equal(keys[4]) || ret[2] != nil {
+		t.Errorf("PutMulti: got keys %v, want the keys of the successful commits", ret)
+	}
+	if commits != 3 {
+		t.Errorf("PutMulti: got %d commits, want 3", commits)
+	}
+
+	// Duplicate keys are deleted once, and get the error of their mutation.
+	checkErr("DeleteMulti", client.DeleteMulti(ctx, append(keys, keys[2])), len(keys)+1, 2, 3, 5)
+
+	// Without failures, a chunked batch returns no error.
+	ok := []*Key{keys[0], keys[1], keys[4]}
+	if err := client.GetMulti(ctx, ok, make([]ent, len(ok))); err != nil {
+		t.Errorf("GetMulti: got %v, want nil", err)
+	}
+}
+
+func TestSplitChunks(t *testing.T) {
+	sizes := []int{3, 3, 5, 1, 1, 1}
+	size := func(i int) int { return sizes[i] }
+	for _, test := range []struct {
+		n, maxCount, maxBytes int
+		size                  func(int) int
+		want                  []chunk
+	}{
+		{0, 2, 0, nil, []chunk{{0, 0}}},
+		{5, 2, 0, nil, []chunk{{0, 2}, {2, 4}, {4, 5}}},
+		{4, 4, 0, nil, []chunk{{0, 4}}},
+		// An item larger than maxBytes gets a chunk of its own.
+		{6, 10, 4, size, []chunk{{0, 1}, {1, 2}, {2, 3}, {3, 6}}},
+		{6, 2, 6, size, []chunk{{0, 2}, {2, 4}, {4, 6}}},
+	} {
+		got := splitChunks(test.n, test.maxCount, test.maxBytes, test.size)
+		if !testutil.Equal(got, test.want, cmp.AllowUnexported(chunk{})) {
+			t.Errorf("splitChunks(%d, %d, %d): got %v, want %v", test.n, test.maxCount, test.maxBytes, got, test.want)
+		}
+	}
+}
+
 type fakeDatastoreClient struct {
 	pb.DatastoreClient
 
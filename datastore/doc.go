@@ -499,6 +499,13 @@ Example code:
 Pass the ReadOnly option to RunInTransaction if your transaction is used only for Get,
 GetMulti or queries. Read-only transactions are more efficient.
 
+To read entities as they were at a point in the past, pass the WithReadTime
+option instead, or read outside a transaction with a client returned by
+Client.WithReadOptions with the ReadTime option:
+
+	past := client.WithReadOptions(datastore.ReadTime(time.Now().Add(-time.Hour).Truncate(time.Minute)))
+	err := past.Get(ctx, key, &x)
+
 Google Cloud Datastore Emulator
 
 This package supports the Cloud Datastore emulator, which is useful for testing and
@@ -43,8 +43,27 @@ const (
 	notEqual
 
 	keyFieldName = "__key__"
+
+	// maxFilterValues is the maximum number of values of an "in" or "not-in"
+	// filter.
+	maxFilterValues = 10
 )
 
+var operatorToString = map[operator]string{
+	lessThan:    "<",
+	lessEq:      "<=",
+	equal:       "=",
+	greaterEq:   ">=",
+	greaterThan: ">",
+	in:          "in",
+	notIn:       "not-in",
+	notEqual:    "!=",
+}
+
+func (o operator) String() string {
+	return operatorToString[o]
+}
+
 var operatorToProto = map[operator]pb.PropertyFilter_Operator{
 	lessThan:    pb.PropertyFilter_LESS_THAN,
 	lessEq:      pb.PropertyFilter_LESS_THAN_OR_EQUAL,
@@ -205,6 +224,14 @@ func (q *Query) Filter(filterStr string, value interface{}) *Query {
 // "=", "!=", "in", and "not-in".
 // Fields are compared against the provided value using the operator.
 // Multiple filters are AND'ed together.
+//
+// The value of an "in" or "not-in" filter must be a slice of one to ten
+// values, such as []string{"a", "b"}. A query may have only one "in" or
+// "not-in" filter, and only one "!=" or "not-in" filter. If the query is
+// ordered, the field of a "!=" or "not-in" filter must be the first one it
+// is ordered by. Queries that combine these operators with other filters or
+// orders may need a composite index; the error for a missing index describes
+// the index to create.
 // Field names which contain spaces, quote marks, or operator characters
 // should be passed as quoted Go string literals as returned by strconv.Quote
 // or the fmt package's %q verb.
@@ -364,6 +391,52 @@ func (q *Query) End(c Cursor) *Query {
 	return q
 }
 
+// validateFilters checks the combination of the "in", "not-in" and "!="
+// filters of the query, which the service restricts.
+func (q *Query) validateFilters() error {
+	var multi, notEq *filter
+	for i := range q.filter {
+		qf := &q.filter[i]
+		if qf.Op == in || qf.Op == notIn {
+			if multi != nil {
+				return fmt.Errorf("datastore: query cannot have both %q filter on %q and %q filter on %q",
+					multi.Op, multi.FieldName, qf.Op, qf.FieldName)
+			}
+			multi = qf
+		}
+		if qf.Op == notEqual || qf.Op == notIn {
+			if notEq != nil {
+				return fmt.Errorf("datastore: query cannot have both %q filter on %q and %q filter on %q",
+					notEq.Op, notEq.FieldName, qf.Op, qf.FieldName)
+			}
+			notEq = qf
+			if len(q.order) > 0 && q.order[0].FieldName != qf.FieldName {
+				return fmt.Errorf("datastore: field %q of %q filter must be the first sort order, not %q",
+					qf.FieldName, qf.Op, q.order[0].FieldName)
+			}
+		}
+	}
+	return nil
+}
+
+// filterValues returns the value of an "in" or "not-in" filter as a
+// []interface{}, checking that it is a slice of 1 to maxFilterValues values.
+func filterValues(qf filter) ([]interface{}, error) {
+	v := reflect.ValueOf(qf.Value)
+	if v.Kind() != reflect.Slice || v.Type().Elem().Kind() == reflect.Uint8 {
+		return nil, fmt.Errorf("datastore: value of %q filter on %q must be a slice, not %T", qf.Op, qf.FieldName, qf.Value)
+	}
+	if n := v.Len(); n == 0 || n > maxFilterValues {
+		return nil, fmt.Errorf("datastore: value of %q filter on %q must have 1 to %d elements, not %d",
+			qf.Op, qf.FieldName, maxFilterValues, n)
+	}
+	values := make([]interface{}, v.Len())
+	for i := range values {
+		values[i] = v.Index(i).Interface()
+	}
+	return values, nil
+}
+
 // toProto converts the query to a protocol buffer.
 func (q *Query) toProto(req *pb.RunQueryRequest) error {
 	if len(q.projection) != 0 && q.keysOnly {
@@ -395,12 +468,22 @@ func (q *Query) toProto(req *pb.RunQueryRequest) error {
 		dst.Projection = []*pb.Projection{{Property: &pb.PropertyReference{Name: keyFieldName}}}
 	}
 
+	if err := q.validateFilters(); err != nil {
+		return err
+	}
 	var filters []*pb.Filter
 	for _, qf := range q.filter {
 		if qf.FieldName == "" {
 			return errors.New("datastore: empty query filter field name")
 		}
-		v, err := interfaceToProto(reflect.ValueOf(qf.Value).Interface(), false)
+		value := qf.Value
+		if qf.Op == in || qf.Op == notIn {
+			var err error
+			if value, err = filterValues(qf); err != nil {
+				return err
+			}
+		}
+		v, err := interfaceToProto(reflect.ValueOf(value).Interface(), false)
 		if err != nil {
 			return fmt.Errorf("datastore: bad query filter value type: %v", err)
 		}
@@ -622,6 +705,12 @@ func (c *Client) Run(ctx context.Context, q *Query) *Iterator {
 
 	if err := q.toProto(t.req); err != nil {
 		t.err = err
+	} else if ro := c.readOptions(); ro != nil && q.trans == nil {
+		if q.eventual {
+			t.err = errors.New("datastore: cannot use EventualConsistency query with a read time")
+		} else {
+			t.req.ReadOptions = ro
+		}
 	}
 	return t
 }
@@ -507,6 +507,42 @@ func TestFilterField(t *testing.T) {
 	}
 }
 
+func TestInequalityFiltersToProto(t *testing.T) {
+	q := NewQuery("K").FilterField("a", "in", []string{"x", "y"}).FilterField("b", "!=", 3).Order("b")
+	var req pb.RunQueryRequest
+	if err := q.toProto(&req); err != nil {
+		t.Fatal(err)
+	}
+	got := req.GetQuery().GetFilter().GetCompositeFilter().GetFilters()[0].GetPropertyFilter()
+	want := &pb.PropertyFilter{
+		Property: &pb.PropertyReference{Name: "a"},
+		Op:       pb.PropertyFilter_IN,
+		Value: &pb.Value{ValueType: &pb.Value_ArrayValue{ArrayValue: &pb.ArrayValue{Values: []*pb.Value{
+			{ValueType: &pb.Value_StringValue{StringValue: "x"}},
+			{ValueType: &pb.Value_StringValue{StringValue: "y"}},
+		}}}},
+	}
+	if !proto.Equal(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+
+	for _, q := range []*Query{
+		NewQuery("K").FilterField("a", "in", 1),
+		NewQuery("K").FilterField("a", "in", []byte("ab")),
+		NewQuery("K").FilterField("a", "not-in", []int{}),
+		NewQuery("K").FilterField("a", "in", make([]int, 11)),
+		NewQuery("K").FilterField("a", "in", []int{1}).FilterField("b", "not-in", []int{2}),
+		NewQuery("K").FilterField("a", "!=", 1).FilterField("b", "!=", 2),
+		NewQuery("K").FilterField("a", "!=", 1).FilterField("b", "not-in", []int{2}),
+		NewQuery("K").FilterField("a", "not-in", []int{1}).Order("b"),
+	} {
+		var req pb.RunQueryRequest
+		if err := q.toProto(&req); err == nil {
+			t.Errorf("%+v: got nil, want error", q.filter)
+		}
+	}
+}
+
 func TestUnquote(t *testing.T) {
 	testCases := []struct {
 		input string
@@ -17,11 +17,13 @@ package datastore
 import (
 	"context"
 	"errors"
+	"time"
 
 	"cloud.google.com/go/internal/trace"
 	pb "google.golang.org/genproto/googleapis/datastore/v1"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
 // ErrConcurrentTransaction is returned when a transaction is rolled back due
@@ -33,6 +35,7 @@ var errExpiredTransaction = errors.New("datastore: transaction expired")
 type transactionSettings struct {
 	attempts int
 	readOnly bool
+	readTime time.Time
 	prevID   []byte // ID of the transaction to retry
 }
 
@@ -80,6 +83,20 @@ func (readOnly) apply(s *transactionSettings) {
 	s.readOnly = true
 }
 
+// WithReadTime returns a TransactionOption that makes the transaction read-only
+// and reads entities as they were at t, with the same restrictions on t as
+// ReadTime. It implies ReadOnly.
+func WithReadTime(t time.Time) TransactionOption {
+	return readTimeOption(t)
+}
+
+type readTimeOption time.Time
+
+func (t readTimeOption) apply(s *transactionSettings) {
+	s.readOnly = true
+	s.readTime = time.Time(t)
+}
+
 // Transaction represents a set of datastore operations to be committed atomically.
 //
 // Operations are enqueued by calling the Put and Delete methods on Transaction
@@ -116,8 +133,12 @@ func (c *Client) newTransaction(ctx context.Context, s *transactionSettings) (_
 		ctx = trace.StartSpan(ctx, "cloud.google.com/go/datastore.Transaction.ReadOnlyTransaction")
 		defer func() { trace.EndSpan(ctx, err) }()
 
+		ro := &pb.TransactionOptions_ReadOnly{}
+		if !s.readTime.IsZero() {
+			ro.ReadTime = timestamppb.New(s.readTime)
+		}
 		req.TransactionOptions = &pb.TransactionOptions{
-			Mode: &pb.TransactionOptions_ReadOnly_{ReadOnly: &pb.TransactionOptions_ReadOnly{}},
+			Mode: &pb.TransactionOptions_ReadOnly_{ReadOnly: ro},
 		}
 	} else if s.prevID != nil {
 		ctx = trace.StartSpan(ctx, "cloud.google.com/go/datastore.Transaction.ReadWriteTransaction")
@@ -17,9 +17,11 @@ package datastore
 import (
 	"context"
 	"testing"
+	"time"
 
 	"github.com/golang/protobuf/proto"
 	pb "google.golang.org/genproto/googleapis/datastore/v1"
+	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
 func TestNewTransaction(t *testing.T) {
@@ -53,6 +55,17 @@ func TestNewTransaction(t *testing.T) {
 				},
 			},
 		},
+		{
+			newTransactionSettings([]TransactionOption{WithReadTime(time.Unix(1000, 0))}),
+			&pb.BeginTransactionRequest{
+				ProjectId: "project",
+				TransactionOptions: &pb.TransactionOptions{
+					Mode: &pb.TransactionOptions_ReadOnly_{ReadOnly: &pb.TransactionOptions_ReadOnly{
+						ReadTime: &timestamppb.Timestamp{Seconds: 1000},
+					}},
+				},
+			},
+		},
 		{
 			&transactionSettings{prevID: []byte("tid")},
 			&pb.BeginTransactionRequest{
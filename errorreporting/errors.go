@@ -30,6 +30,7 @@ import (
 	"log"
 	"net/http"
 	"runtime"
+	"sync"
 	"time"
 
 	vkit "cloud.google.com/go/errorreporting/apiv1beta1"
@@ -55,6 +56,20 @@ type Config struct {
 	// OnError is the function to call if any background
 	// tasks errored. By default, errors are logged.
 	OnError func(err error)
+
+	// MaxReportsPerGroup, if positive, limits the number of errors of each
+	// group that Report sends in each ReportWindow, to avoid flooding the
+	// service during error storms. Errors are grouped by Entry.GroupKey.
+	// Errors over the limit are dropped, and the report of the next error of
+	// the group that is sent includes their number. ReportSync is not
+	// limited.
+	// Optional.
+	MaxReportsPerGroup int
+
+	// ReportWindow is the period over which MaxReportsPerGroup applies. The
+	// default is one minute.
+	// Optional.
+	ReportWindow time.Duration
 }
 
 // Entry holds information about the reported error.
@@ -63,6 +78,16 @@ type Entry struct {
 	Req   *http.Request // if error is associated with a request.
 	User  string        // an identifier for the user affected by the error
 
+	// ResponseStatusCode is the HTTP status code of the response to Req, if
+	// any.
+	ResponseStatusCode int
+
+	// GroupKey identifies errors that are limited together by
+	// Config.MaxReportsPerGroup. If empty, errors with the same message are
+	// grouped together. It does not affect how the service groups errors,
+	// which is based on their stack traces.
+	GroupKey string
+
 	// Stack specifies the stacktrace and call sequence correlated with
 	// the error. Stack's content must match the format specified by
 	// https://cloud.google.com/error-reporting/reference/rest/v1beta1/projects.events/report#ReportedErrorEvent.message
@@ -79,6 +104,7 @@ type Client struct {
 	apiClient      client
 	serviceContext *pb.ServiceContext
 	bundler        *bundler.Bundler
+	limiter        *groupLimiter // nil if reports are not limited
 
 	onErrorFn func(err error)
 }
@@ -113,6 +139,13 @@ func NewClient(ctx context.Context, projectID string, cfg Config, opts ...option
 		},
 		onErrorFn: cfg.OnError,
 	}
+	if cfg.MaxReportsPerGroup > 0 {
+		window := cfg.ReportWindow
+		if window <= 0 {
+			window = time.Minute
+		}
+		client.limiter = newGroupLimiter(cfg.MaxReportsPerGroup, window)
+	}
 	bundler := bundler.NewBundler((*pb.ReportErrorEventRequest)(nil), func(bundle interface{}) {
 		reqs := bundle.([]*pb.ReportErrorEventRequest)
 		for _, req := range reqs {
@@ -149,13 +182,21 @@ func (c *Client) Close() error {
 
 // Report writes an error report. It doesn't block. Errors in
 // writing the error report can be handled via Config.OnError.
+// The report may be dropped if Config.MaxReportsPerGroup is set.
 func (c *Client) Report(e Entry) {
-	c.bundler.Add(c.newRequest(e), 1)
+	var dropped int
+	if c.limiter != nil {
+		var ok bool
+		if ok, dropped = c.limiter.allow(e.groupKey()); !ok {
+			return
+		}
+	}
+	c.bundler.Add(c.newRequest(e, dropped), 1)
 }
 
 // ReportSync writes an error report. It blocks until the entry is written.
 func (c *Client) ReportSync(ctx context.Context, e Entry) error {
-	_, err := c.apiClient.ReportErrorEvent(ctx, c.newRequest(e))
+	_, err := c.apiClient.ReportErrorEvent(ctx, c.newRequest(e, 0))
 	return err
 }
 
@@ -168,7 +209,9 @@ func (c *Client) Flush() {
 	c.bundler.Flush()
 }
 
-func (c *Client) newRequest(e Entry) *pb.ReportErrorEventRequest {
+// newRequest returns the request that reports e. dropped is the number of
+// errors of the group of e that were not reported since the last one that was.
+func (c *Client) newRequest(e Entry, dropped int) *pb.ReportErrorEventRequest {
 	var stack string
 	if e.Stack != nil {
 		stack = string(e.Stack)
@@ -177,7 +220,11 @@ func (c *Client) newRequest(e Entry) *pb.ReportErrorEventRequest {
 		var buf [16 * 1024]byte
 		stack = chopStack(buf[0:runtime.Stack(buf[:], false)])
 	}
-	message := e.Error.Error() + "\n" + stack
+	message := e.Error.Error()
+	if dropped > 0 {
+		message += fmt.Sprintf(" (and %d similar errors not reported)", dropped)
+	}
+	message += "\n" + stack
 
 	var errorContext *pb.ErrorContext
 	if r := e.Req; r != nil {
@@ -188,6 +235,8 @@ func (c *Client) newRequest(e Entry) *pb.ReportErrorEventRequest {
 				UserAgent: r.UserAgent(),
 				Referrer:  r.Referer(),
 				RemoteIp:  r.RemoteAddr,
+
+				ResponseStatusCode: int32(e.ResponseStatusCode),
 			},
 		}
 	}
@@ -208,6 +257,77 @@ func (c *Client) newRequest(e Entry) *pb.ReportErrorEventRequest {
 	}
 }
 
+func (e Entry) groupKey() string {
+	if e.GroupKey != "" {
+		return e.GroupKey
+	}
+	return e.Error.Error()
+}
+
+// maxLimiterGroups is the number of groups above which a groupLimiter forgets
+// the groups that have nothing left to report.
+const maxLimiterGroups = 1000
+
+// groupLimiter limits the number of errors reported per group in each window.
+type groupLimiter struct {
+	max    int
+	window time.Duration
+	now    func() time.Time // for testing
+
+	mu     sync.Mutex
+	groups map[string]*groupState
+}
+
+type groupState struct {
+	start         time.Time // start of the current window
+	sent, dropped int
+}
+
+func newGroupLimiter(max int, window time.Duration) *groupLimiter {
+	return &groupLimiter{
+		max:    max,
+		window: window,
+		now:    time.Now,
+		groups: make(map[string]*groupState),
+	}
+}
+
+// allow reports whether an error of the group with the given key may be
+// reported. If so, it also returns the number of errors of the group that
+// were dropped since the last one that was allowed.
+func (l *groupLimiter) allow(key string) (ok bool, dropped int) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	now := l.now()
+	g := l.groups[key]
+	if g == nil {
+		if len(l.groups) >= maxLimiterGroups {
+			l.prune(now)
+		}
+		g = &groupState{start: now}
+		l.groups[key] = g
+	}
+	if now.Sub(g.start) >= l.window {
+		g.start, g.sent = now, 0
+	}
+	if g.sent >= l.max {
+		g.dropped++
+		return false, 0
+	}
+	g.sent++
+	dropped, g.dropped = g.dropped, 0
+	return true, dropped
+}
+
+// prune forgets the groups whose window has ended without dropped errors.
+func (l *groupLimiter) prune(now time.Time) {
+	for key, g := range l.groups {
+		if g.dropped == 0 && now.Sub(g.start) >= l.window {
+			delete(l.groups, key)
+		}
+	}
+}
+
 // chopStack trims a stack trace so that the function which panics or calls
 // Report is first.
 func chopStack(s []byte) string {
@@ -17,7 +17,9 @@ package errorreporting
 import (
 	"context"
 	"errors"
+	"net/http"
 	"strings"
+	"sync"
 	"testing"
 	"time"
 
@@ -29,16 +31,19 @@ import (
 
 type fakeReportErrorsClient struct {
 	req    *pb.ReportErrorEventRequest
+	reqs   []*pb.ReportErrorEventRequest // all successful requests
 	fail   bool
-	doneCh chan struct{}
+	doneCh chan struct{} // closed after the first request
+	once   sync.Once
 }
 
 func (c *fakeReportErrorsClient) ReportErrorEvent(ctx context.Context, req *pb.ReportErrorEventRequest, _ ...gax.CallOption) (*pb.ReportErrorEventResponse, error) {
-	defer close(c.doneCh)
+	defer c.once.Do(func() { close(c.doneCh) })
 	if c.fail {
 		return nil, errors.New("request failed")
 	}
 	c.req = req
+	c.reqs = append(c.reqs, req)
 	return &pb.ReportErrorEventResponse{}, nil
 }
 
@@ -173,3 +178,71 @@ created by testing.(*T).Run
 		}
 	}
 }
+
+func TestReportSyncRequestContext(t *testing.T) {
+	ctx := context.Background()
+	fc := newFakeReportErrorsClient()
+	c := newTestClient(fc, defaultConfig)
+	req, err := http.NewRequest("GET", "http://example.com/path", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	e := Entry{Error: errors.New("error"), User: "user", Req: req, ResponseStatusCode: 500}
+	if err := c.ReportSync(ctx, e); err != nil {
+		t.Fatalf("cannot upload errors: %v", err)
+	}
+	<-fc.doneCh
+	hr := fc.req.Event.Context.HttpRequest
+	if hr.GetMethod() != "GET" || hr.GetResponseStatusCode() != 500 {
+		t.Errorf("got HTTP request context %v, want method GET and status 500", hr)
+	}
+}
+
+func TestGroupLimiter(t *testing.T) {
+	l := newGroupLimiter(2, time.Minute)
+	now := time.Unix(0, 0)
+	l.now = func() time.Time { return now }
+
+	check := func(key string, wantOK bool, wantDropped int) {
+		t.Helper()
+		ok, dropped := l.allow(key)
+		if ok != wantOK || dropped != wantDropped {
+			t.Errorf("allow(%q) = %t, %d, want %t, %d", key, ok, dropped, wantOK, wantDropped)
+		}
+	}
+	check("a", true, 0)
+	check("a", true, 0)
+	check("a", false, 0)
+	check("a", false, 0)
+	check("b", true, 0)
+	now = now.Add(time.Minute)
+	check("a", true, 2)
+	check("a", true, 0)
+	check("a", false, 0)
+}
+
+func TestReportLimited(t *testing.T) {
+	fc := newFakeReportErrorsClient()
+	cfg := defaultConfig
+	cfg.MaxReportsPerGroup = 1
+	c := newTestClient(fc, cfg)
+	now := time.Unix(0, 0)
+	c.limiter.now = func() time.Time { return now }
+
+	for _, msg := range []string{"error 1", "error 2", "error 3"} {
+		c.Report(Entry{Error: errors.New(msg), GroupKey: "g"})
+	}
+	now = now.Add(time.Minute)
+	c.Report(Entry{Error: errors.New("error 4"), GroupKey: "g"})
+	c.Flush()
+	<-fc.doneCh
+	if len(fc.reqs) != 2 {
+		t.Fatalf("got %d error reports, want 2", len(fc.reqs))
+	}
+	if got := fc.reqs[0].Event.Message; !strings.HasPrefix(got, "error 1\n") {
+		t.Errorf("got message %q, want error 1", got)
+	}
+	if got := fc.reqs[1].Event.Message; !strings.HasPrefix(got, "error 4 (and 2 similar errors not reported)\n") {
+		t.Errorf("got message %q, want error 4 with 2 dropped", got)
+	}
+}
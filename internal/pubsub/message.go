@@ -14,6 +14,7 @@
 package pubsub
 
 import (
+	"context"
 	"time"
 )
 
@@ -26,6 +27,28 @@ type AckHandler interface {
 	OnNack()
 }
 
+// AckResultHandler is implemented by AckHandlers that can report the outcome
+// of an ack or nack.
+type AckResultHandler interface {
+	AckHandler
+
+	// OnAckWithResult processes a message ack and returns its result.
+	OnAckWithResult() *AckResult
+
+	// OnNackWithResult processes a message nack and returns its result.
+	OnNackWithResult() *AckResult
+}
+
+// LeaseHandler is implemented by AckHandlers that extend the ack deadline
+// of a message while it is being processed.
+type LeaseHandler interface {
+	AckHandler
+
+	// SetMaxExtension sets the maximum time, from when the message was
+	// received, for which its ack deadline is extended.
+	SetMaxExtension(d time.Duration)
+}
+
 // Message represents a Pub/Sub message.
 type Message struct {
 	// ID identifies this message. This ID is assigned by the server and is
@@ -85,6 +108,111 @@ func (m *Message) Nack() {
 	}
 }
 
+// AckWithResult acts like Ack but returns an AckResult, which reports whether
+// the acknowledgement was durably processed by the server. For subscriptions
+// without exactly-once delivery, the result is successful as soon as the ack
+// is recorded by the client.
+func (m *Message) AckWithResult() *AckResult {
+	if h, ok := m.ackh.(AckResultHandler); ok {
+		return h.OnAckWithResult()
+	}
+	m.Ack()
+	return newSuccessfulAckResult()
+}
+
+// NackWithResult acts like Nack but returns an AckResult, which reports
+// whether the negative acknowledgement was processed by the server. For
+// subscriptions without exactly-once delivery, the result is successful as
+// soon as the nack is recorded by the client.
+func (m *Message) NackWithResult() *AckResult {
+	if h, ok := m.ackh.(AckResultHandler); ok {
+		return h.OnNackWithResult()
+	}
+	m.Nack()
+	return newSuccessfulAckResult()
+}
+
+// SetMaxExtension overrides, for this message only, the maximum period for
+// which its ack deadline is automatically extended, measured from when it was
+// received. Once that period has passed, the message is redelivered unless it
+// is acked first. A negative value stops extending the deadline right away.
+// SetMaxExtension has no effect on messages whose ack deadlines are not
+// extended automatically, or that have already been acked or nacked.
+func (m *Message) SetMaxExtension(d time.Duration) {
+	if h, ok := m.ackh.(LeaseHandler); ok {
+		h.SetMaxExtension(d)
+	}
+}
+
+// AcknowledgeStatus represents the outcome of an ack or nack.
+type AcknowledgeStatus int
+
+const (
+	// AcknowledgeStatusSuccess indicates the request was processed
+	// successfully.
+	AcknowledgeStatusSuccess AcknowledgeStatus = iota
+	// AcknowledgeStatusPermissionDenied indicates the caller does not have
+	// permission to acknowledge messages of the subscription.
+	AcknowledgeStatusPermissionDenied
+	// AcknowledgeStatusFailedPrecondition indicates the subscription is
+	// detached or cannot be used, for example because of a KMS key issue.
+	AcknowledgeStatusFailedPrecondition
+	// AcknowledgeStatusInvalidAckID indicates the ack ID has expired or is
+	// otherwise invalid, so the message may be redelivered.
+	AcknowledgeStatusInvalidAckID
+	// AcknowledgeStatusOther indicates the request failed for another reason.
+	AcknowledgeStatusOther
+)
+
+// An AckResult holds the result of a call to AckWithResult or
+// NackWithResult.
+type AckResult struct {
+	ready  chan struct{}
+	status AcknowledgeStatus
+	err    error
+}
+
+// Ready returns a channel that is closed when the result is ready.
+// When the Ready channel is closed, Get is guaranteed not to block.
+func (r *AckResult) Ready() <-chan struct{} { return r.ready }
+
+// Get returns the status and error of the acknowledgement. The error is nil
+// if and only if the status is AcknowledgeStatusSuccess.
+// Get blocks until the result is available or the context is done.
+func (r *AckResult) Get(ctx context.Context) (AcknowledgeStatus, error) {
+	// If the result is already ready, return it even if the context is done.
+	select {
+	case <-r.Ready():
+		return r.status, r.err
+	default:
+	}
+	select {
+	case <-ctx.Done():
+		return AcknowledgeStatusOther, ctx.Err()
+	case <-r.Ready():
+		return r.status, r.err
+	}
+}
+
+// NewAckResult creates an AckResult.
+func NewAckResult() *AckResult {
+	return &AckResult{ready: make(chan struct{})}
+}
+
+// SetAckResult sets the status and error of an ack result and closes its
+// Ready channel.
+func SetAckResult(r *AckResult, status AcknowledgeStatus, err error) {
+	r.status = status
+	r.err = err
+	close(r.ready)
+}
+
+func newSuccessfulAckResult() *AckResult {
+	r := NewAckResult()
+	SetAckResult(r, AcknowledgeStatusSuccess, nil)
+	return r
+}
+
 // NewMessage creates a message with an AckHandler implementation, which should
 // not be nil.
 func NewMessage(ackh AckHandler) *Message {
@@ -0,0 +1,175 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package envelope implements envelope encryption with Cloud KMS: data is
+// encrypted locally with a data encryption key, and only that key is
+// encrypted (wrapped) by a CryptoKey. This way data of any size can be
+// encrypted with a single call to Cloud KMS.
+package envelope // import "cloud.google.com/go/kms/envelope"
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"strings"
+
+	kms "cloud.google.com/go/kms/apiv1"
+	gax "github.com/googleapis/gax-go/v2"
+	kmspb "google.golang.org/genproto/googleapis/cloud/kms/v1"
+)
+
+// envelopeVersion is the first byte of the envelopes written by Encrypt. The
+// rest of an envelope is the name of the CryptoKey, the wrapped data
+// encryption key, each preceded by its length as a uvarint, and the
+// AES-256-GCM nonce followed by the encrypted data. Everything before the
+// nonce is the header of the envelope, which is authenticated along with the
+// data.
+const envelopeVersion = 1
+
+// dataKeySize is the size of the data encryption keys, for AES-256.
+const dataKeySize = 32
+
+// Encrypt encrypts plaintext with envelope encryption: it generates a data
+// encryption key locally, encrypts plaintext with it using AES-256-GCM, and
+// encrypts (wraps) the data encryption key with the symmetric CryptoKey
+// name, of the form projects/*/locations/*/keyRings/*/cryptoKeys/*, using c.
+// It returns an envelope holding the name of the CryptoKey, the wrapped key
+// and the encrypted data, which Decrypt accepts.
+//
+// aad is optional additional authenticated data, which must be passed to
+// Decrypt again. It is authenticated but not encrypted, and not included in
+// the envelope.
+//
+// The opts apply to the call to Encrypt.
+func Encrypt(ctx context.Context, c *kms.KeyManagementClient, name string, plaintext, aad []byte, opts ...gax.CallOption) ([]byte, error) {
+	// A version of the CryptoKey may be used to encrypt, but decrypting
+	// requires the CryptoKey itself.
+	if i := strings.Index(name, "/cryptoKeyVersions/"); i >= 0 {
+		name = name[:i]
+	}
+	dek := make([]byte, dataKeySize)
+	if _, err := rand.Read(dek); err != nil {
+		return nil, fmt.Errorf("envelope: generating data encryption key: %v", err)
+	}
+	aead, err := newDataKeyAEAD(dek)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := c.Encrypt(ctx, &kmspb.EncryptRequest{
+		Name:                        name,
+		Plaintext:                   dek,
+		AdditionalAuthenticatedData: aad,
+	}, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, fmt.Errorf("envelope: generating nonce: %v", err)
+	}
+	env := []byte{envelopeVersion}
+	env = appendBytes(env, []byte(name))
+	env = appendBytes(env, resp.Ciphertext)
+	header := env
+	env = append(env, nonce...)
+	return aead.Seal(env, nonce, plaintext, associatedData(header, aad)), nil
+}
+
+// Decrypt decrypts an envelope written by Encrypt, using c to unwrap its data
+// encryption key. aad must be the additional authenticated data that was
+// passed to Encrypt.
+//
+// The opts apply to the call to Decrypt.
+func Decrypt(ctx context.Context, c *kms.KeyManagementClient, envelope, aad []byte, opts ...gax.CallOption) ([]byte, error) {
+	if len(envelope) == 0 || envelope[0] != envelopeVersion {
+		return nil, errors.New("envelope: not an envelope, or of an unsupported version")
+	}
+	rest := envelope[1:]
+	name, rest, ok := readBytes(rest)
+	if !ok {
+		return nil, errMalformedEnvelope
+	}
+	wrapped, rest, ok := readBytes(rest)
+	if !ok {
+		return nil, errMalformedEnvelope
+	}
+	header := envelope[:len(envelope)-len(rest)]
+	resp, err := c.Decrypt(ctx, &kmspb.DecryptRequest{
+		Name:                        string(name),
+		Ciphertext:                  wrapped,
+		AdditionalAuthenticatedData: aad,
+	}, opts...)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := newDataKeyAEAD(resp.Plaintext)
+	if err != nil {
+		return nil, err
+	}
+	if len(rest) < aead.NonceSize() {
+		return nil, errMalformedEnvelope
+	}
+	nonce, sealed := rest[:aead.NonceSize()], rest[aead.NonceSize():]
+	plaintext, err := aead.Open(nil, nonce, sealed, associatedData(header, aad))
+	if err != nil {
+		return nil, fmt.Errorf("envelope: decrypting envelope: %v", err)
+	}
+	return plaintext, nil
+}
+
+var errMalformedEnvelope = errors.New("envelope: malformed envelope")
+
+func newDataKeyAEAD(key []byte) (cipher.AEAD, error) {
+	if len(key) != dataKeySize {
+		return nil, fmt.Errorf("envelope: data encryption key is %d bytes, want %d", len(key), dataKeySize)
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// associatedData returns the data that AES-GCM authenticates along with the
+// encrypted data: the header of the envelope, so that it can't be altered,
+// followed by the caller's aad. The header is self-delimiting, so the two
+// can't be confused.
+func associatedData(header, aad []byte) []byte {
+	ad := make([]byte, 0, len(header)+len(aad))
+	ad = append(ad, header...)
+	return append(ad, aad...)
+}
+
+// appendBytes appends b to buf, preceded by its length.
+func appendBytes(buf, b []byte) []byte {
+	var n [binary.MaxVarintLen64]byte
+	buf = append(buf, n[:binary.PutUvarint(n[:], uint64(len(b)))]...)
+	return append(buf, b...)
+}
+
+// readBytes reads a slice written by appendBytes from the start of buf, and
+// returns it and the rest of buf.
+func readBytes(buf []byte) (b, rest []byte, ok bool) {
+	n, k := binary.Uvarint(buf)
+	if k <= 0 || n > uint64(len(buf)-k) {
+		return nil, nil, false
+	}
+	buf = buf[k:]
+	return buf[:n], buf[n:], true
+}
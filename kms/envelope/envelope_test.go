@@ -0,0 +1,154 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package envelope
+
+import (
+	"bytes"
+	"context"
+	"net"
+	"sync"
+	"testing"
+
+	kms "cloud.google.com/go/kms/apiv1"
+	"github.com/golang/protobuf/proto"
+	"google.golang.org/api/option"
+	kmspb "google.golang.org/genproto/googleapis/cloud/kms/v1"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// fakeKMSServer wraps keys by prefixing them with "wrapped:", and records the
+// requests it receives.
+type fakeKMSServer struct {
+	kmspb.UnimplementedKeyManagementServiceServer
+
+	mu   sync.Mutex
+	reqs []proto.Message
+}
+
+func (s *fakeKMSServer) Encrypt(_ context.Context, req *kmspb.EncryptRequest) (*kmspb.EncryptResponse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.reqs = append(s.reqs, req)
+	return &kmspb.EncryptResponse{Ciphertext: append([]byte("wrapped:"), req.Plaintext...)}, nil
+}
+
+func (s *fakeKMSServer) Decrypt(_ context.Context, req *kmspb.DecryptRequest) (*kmspb.DecryptResponse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.reqs = append(s.reqs, req)
+	if !bytes.HasPrefix(req.Ciphertext, []byte("wrapped:")) {
+		return nil, status.Error(codes.InvalidArgument, "bad ciphertext")
+	}
+	return &kmspb.DecryptResponse{Plaintext: bytes.TrimPrefix(req.Ciphertext, []byte("wrapped:"))}, nil
+}
+
+func (s *fakeKMSServer) takeRequests() []proto.Message {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	reqs := s.reqs
+	s.reqs = nil
+	return reqs
+}
+
+func newFakeClient(t *testing.T, s *fakeKMSServer) *kms.KeyManagementClient {
+	srv := grpc.NewServer()
+	kmspb.RegisterKeyManagementServiceServer(srv, s)
+	lis, err := net.Listen("tcp", "localhost:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	go srv.Serve(lis)
+	t.Cleanup(srv.Stop)
+	c, err := kms.NewKeyManagementClient(context.Background(),
+		option.WithEndpoint(lis.Addr().String()),
+		option.WithoutAuthentication(),
+		option.WithGRPCDialOption(grpc.WithInsecure()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { c.Close() })
+	return c
+}
+
+func TestEncryptDecrypt(t *testing.T) {
+	const keyName = "projects/p/locations/global/keyRings/r/cryptoKeys/k"
+	plaintext := []byte("attack at dawn")
+	aad := []byte("context")
+
+	ctx := context.Background()
+	s := &fakeKMSServer{}
+	c := newFakeClient(t, s)
+
+	env, err := Encrypt(ctx, c, keyName+"/cryptoKeyVersions/1", plaintext, aad)
+	if err != nil {
+		t.Fatal(err)
+	}
+	reqs := s.takeRequests()
+	encReq := reqs[0].(*kmspb.EncryptRequest)
+	if encReq.Name != keyName || len(encReq.Plaintext) != dataKeySize || !bytes.Equal(encReq.AdditionalAuthenticatedData, aad) {
+		t.Errorf("got encrypt request %v", encReq)
+	}
+	if bytes.Contains(env, plaintext) {
+		t.Error("envelope contains the plaintext")
+	}
+
+	got, err := Decrypt(ctx, c, env, aad)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, plaintext) {
+		t.Errorf("got plaintext %q, want %q", got, plaintext)
+	}
+	wantReq := &kmspb.DecryptRequest{
+		Name:                        keyName,
+		Ciphertext:                  append([]byte("wrapped:"), encReq.Plaintext...),
+		AdditionalAuthenticatedData: aad,
+	}
+	if reqs := s.takeRequests(); !proto.Equal(reqs[0], wantReq) {
+		t.Errorf("got decrypt request %v, want %v", reqs[0], wantReq)
+	}
+
+	if _, err := Decrypt(ctx, c, env, []byte("other")); err == nil {
+		t.Error("got nil error decrypting with the wrong additional authenticated data")
+	}
+	for _, bad := range [][]byte{nil, {2}, env[:3], env[:len(env)-len(plaintext)-20]} {
+		if _, err := Decrypt(ctx, c, bad, aad); err == nil {
+			t.Errorf("got nil error decrypting malformed envelope %q", bad)
+		}
+	}
+}
+
+func TestDecryptAlteredHeader(t *testing.T) {
+	const keyName = "projects/p/locations/global/keyRings/r/cryptoKeys/k"
+	ctx := context.Background()
+	c := newFakeClient(t, &fakeKMSServer{})
+
+	env, err := Encrypt(ctx, c, keyName, []byte("attack at dawn"), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Point the envelope at another CryptoKey of the same length. The fake
+	// server unwraps the data key regardless of the name, so only the
+	// authentication of the header catches this.
+	altered := bytes.Replace(env, []byte("cryptoKeys/k"), []byte("cryptoKeys/x"), 1)
+	if bytes.Equal(altered, env) {
+		t.Fatal("key name not found in envelope")
+	}
+	if _, err := Decrypt(ctx, c, altered, nil); err == nil {
+		t.Error("got nil error decrypting an envelope with an altered header")
+	}
+}
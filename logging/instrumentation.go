@@ -15,6 +15,7 @@
 package logging
 
 import (
+	"context"
 	"strings"
 
 	"cloud.google.com/go/logging/internal"
@@ -69,7 +70,7 @@ func (l *Logger) instrumentationEntry() (*logpb.LogEntry, error) {
 		},
 	}
 	// pass nil for Logger and 0 for skip levels to ignore auto-population
-	return toLogEntryInternal(ent, nil, l.client.parent, 0)
+	return toLogEntryInternal(context.Background(), ent, nil, l.client.parent, 0)
 }
 
 // hasInstrumentation returns true if any of the log entries has diagnostic LogId
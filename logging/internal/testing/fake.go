@@ -155,6 +155,35 @@ func (h *loggingHandler) ListLogEntries(_ context.Context, req *logpb.ListLogEnt
 	}, nil
 }
 
+// TailLogEntries streams log entries as they are ingested.
+//
+// This fake implementation streams the log entries that match the filter at
+// the start of the session, reports one suppressed entry, and ends the
+// session. It supports the same filters as ListLogEntries.
+func (h *loggingHandler) TailLogEntries(stream logpb.LoggingServiceV2_TailLogEntriesServer) error {
+	req, err := stream.Recv()
+	if err != nil {
+		return err
+	}
+	h.mu.Lock()
+	entries, err := h.filterEntries(req.Filter)
+	if err == nil {
+		entries = append([]*logpb.LogEntry(nil), entries...)
+		err = sortEntries(entries, "")
+	}
+	h.mu.Unlock()
+	if err != nil {
+		return err
+	}
+	return stream.Send(&logpb.TailLogEntriesResponse{
+		Entries: entries,
+		SuppressionInfo: []*logpb.TailLogEntriesResponse_SuppressionInfo{{
+			Reason:          logpb.TailLogEntriesResponse_SuppressionInfo_RATE_LIMIT,
+			SuppressedCount: 1,
+		}},
+	})
+}
+
 func (h *loggingHandler) filterEntries(filter string) ([]*logpb.LogEntry, error) {
 	logName, err := parseFilter(filter)
 	if err != nil {
@@ -0,0 +1,176 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logadmin
+
+import (
+	"context"
+	"io"
+	"time"
+
+	"cloud.google.com/go/logging"
+	"google.golang.org/api/iterator"
+	logpb "google.golang.org/genproto/googleapis/logging/v2"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+// BufferWindow sets the amount of time that a live tail session buffers log
+// entries, so that it can return them in order of their timestamps. It may be
+// up to a minute; the default is two seconds. BufferWindow only applies to
+// TailLogEntries; it is ignored when listing log entries.
+func BufferWindow(d time.Duration) EntriesOption { return bufferWindow(d) }
+
+type bufferWindow time.Duration
+
+func (bufferWindow) set(*logpb.ListLogEntriesRequest) {}
+
+func (w bufferWindow) setTail(r *logpb.TailLogEntriesRequest) {
+	r.BufferWindow = durationpb.New(time.Duration(w))
+}
+
+// tailOption is implemented by EntriesOptions that only apply to live tail
+// sessions.
+type tailOption interface {
+	setTail(*logpb.TailLogEntriesRequest)
+}
+
+// TailLogEntries starts a live tail session, which returns log entries as they
+// are ingested, like tail -f. The ProjectIDs, ResourceNames, Filter and
+// BufferWindow options apply to the session; NewestFirst is ignored, and
+// unlike Entries there is no default timestamp filter. By default, the
+// session is restricted to the project passed to NewClient. Requires
+// ReadScope or AdminScope.
+//
+// The session lasts until ctx is done or Close is called on the returned
+// iterator.
+func (c *Client) TailLogEntries(ctx context.Context, opts ...EntriesOption) (*TailIterator, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	stream, err := c.lClient.TailLogEntries(ctx)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	if err := stream.Send(tailLogEntriesRequest(c.parent, opts)); err != nil {
+		cancel()
+		return nil, err
+	}
+	return &TailIterator{stream: stream, cancel: cancel}, nil
+}
+
+func tailLogEntriesRequest(parent string, opts []EntriesOption) *logpb.TailLogEntriesRequest {
+	lreq := &logpb.ListLogEntriesRequest{
+		ResourceNames: []string{parent},
+	}
+	req := &logpb.TailLogEntriesRequest{}
+	for _, opt := range opts {
+		opt.set(lreq)
+		if t, ok := opt.(tailOption); ok {
+			t.setTail(req)
+		}
+	}
+	req.ResourceNames = lreq.ResourceNames
+	req.Filter = lreq.Filter
+	return req
+}
+
+// SuppressionReason is the reason that a live tail session omitted log
+// entries.
+type SuppressionReason int
+
+const (
+	// RateLimit means that the entries were omitted because of the rate limit
+	// of live tail sessions.
+	RateLimit = SuppressionReason(logpb.TailLogEntriesResponse_SuppressionInfo_RATE_LIMIT)
+
+	// NotConsumed means that the entries were omitted because they were not
+	// read fast enough.
+	NotConsumed = SuppressionReason(logpb.TailLogEntriesResponse_SuppressionInfo_NOT_CONSUMED)
+)
+
+// String returns the name of the reason, such as "RATE_LIMIT".
+func (r SuppressionReason) String() string {
+	return logpb.TailLogEntriesResponse_SuppressionInfo_Reason(r).String()
+}
+
+// SuppressionInfo describes log entries that a live tail session omitted.
+type SuppressionInfo struct {
+	Reason SuppressionReason
+
+	// Count is a lower bound on the number of entries omitted.
+	Count int
+}
+
+// A TailIterator iterates over the log entries of a live tail session.
+type TailIterator struct {
+	stream     logpb.LoggingServiceV2_TailLogEntriesClient
+	cancel     func()
+	items      []*logging.Entry
+	suppressed []*SuppressionInfo
+	err        error
+}
+
+// Next returns the next log entry, waiting until one is available. Its
+// second return value is iterator.Done if the session ended, or the error
+// that ended it. Once Next returns an error, all subsequent calls will return
+// the same error.
+func (it *TailIterator) Next() (*logging.Entry, error) {
+	for len(it.items) == 0 && it.err == nil {
+		it.err = it.fetch()
+	}
+	if len(it.items) == 0 {
+		return nil, it.err
+	}
+	item := it.items[0]
+	it.items = it.items[1:]
+	return item, nil
+}
+
+func (it *TailIterator) fetch() error {
+	res, err := it.stream.Recv()
+	if err == io.EOF {
+		return iterator.Done
+	}
+	if err != nil {
+		return err
+	}
+	for _, si := range res.SuppressionInfo {
+		it.suppressed = append(it.suppressed, &SuppressionInfo{
+			Reason: SuppressionReason(si.Reason),
+			Count:  int(si.SuppressedCount),
+		})
+	}
+	for _, le := range res.Entries {
+		e, err := fromLogEntry(le)
+		if err != nil {
+			return err
+		}
+		it.items = append(it.items, e)
+	}
+	return nil
+}
+
+// Suppressed returns the information about omitted entries that the session
+// has reported since the last call to Suppressed. The information is recorded
+// as it arrives along with the entries, so it may describe omissions among
+// entries that Next has not returned yet.
+func (it *TailIterator) Suppressed() []*SuppressionInfo {
+	s := it.suppressed
+	it.suppressed = nil
+	return s
+}
+
+// Close ends the live tail session. Next returns an error after Close.
+func (it *TailIterator) Close() {
+	it.cancel()
+}
@@ -0,0 +1,98 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logadmin
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"cloud.google.com/go/internal/testutil"
+	"cloud.google.com/go/logging/internal"
+	"google.golang.org/api/iterator"
+	logpb "google.golang.org/genproto/googleapis/logging/v2"
+	"google.golang.org/protobuf/types/known/durationpb"
+	tspb "google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func TestTailLogEntriesRequest(t *testing.T) {
+	for _, test := range []struct {
+		opts []EntriesOption
+		want *logpb.TailLogEntriesRequest
+	}{
+		{
+			opts: nil,
+			want: &logpb.TailLogEntriesRequest{ResourceNames: []string{"projects/PROJECT_ID"}},
+		},
+		{
+			opts: []EntriesOption{ProjectIDs([]string{"p1", "p2"}), Filter("f"), NewestFirst(), BufferWindow(10 * time.Second)},
+			want: &logpb.TailLogEntriesRequest{
+				ResourceNames: []string{"projects/p1", "projects/p2"},
+				Filter:        "f",
+				BufferWindow:  durationpb.New(10 * time.Second),
+			},
+		},
+	} {
+		got := tailLogEntriesRequest("projects/PROJECT_ID", test.opts)
+		if !testutil.Equal(got, test.want) {
+			t.Errorf("%v: got %v, want %v", test.opts, got, test.want)
+		}
+	}
+}
+
+func TestTailLogEntries(t *testing.T) {
+	if integrationTest {
+		t.Skip("live tail sessions are only tested against the fake")
+	}
+	ctx := context.Background()
+	logName := internal.LogPath("projects/"+testProjectID, "tail-test")
+	_, err := client.lClient.WriteLogEntries(ctx, &logpb.WriteLogEntriesRequest{
+		LogName: logName,
+		Entries: []*logpb.LogEntry{
+			{Timestamp: &tspb.Timestamp{Seconds: 1}, Payload: &logpb.LogEntry_TextPayload{TextPayload: "one"}},
+			{Timestamp: &tspb.Timestamp{Seconds: 2}, Payload: &logpb.LogEntry_TextPayload{TextPayload: "two"}},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	it, err := client.TailLogEntries(ctx, Filter(`logName = "`+logName+`"`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer it.Close()
+	var got []interface{}
+	for {
+		e, err := it.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		got = append(got, e.Payload)
+	}
+	if len(got) != 2 || got[0] != "one" || got[1] != "two" {
+		t.Errorf("got payloads %v, want [one two]", got)
+	}
+	s := it.Suppressed()
+	if len(s) != 1 || s[0].Reason != RateLimit || s[0].Count != 1 {
+		t.Errorf("got suppression info %v, want one suppressed entry", s)
+	}
+	if s := it.Suppressed(); s != nil {
+		t.Errorf("got suppression info %v again, want nil", s)
+	}
+}
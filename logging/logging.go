@@ -46,6 +46,7 @@ import (
 	"github.com/golang/protobuf/proto"
 	"github.com/golang/protobuf/ptypes"
 	structpb "github.com/golang/protobuf/ptypes/struct"
+	"go.opentelemetry.io/otel/trace"
 	"google.golang.org/api/option"
 	"google.golang.org/api/support/bundler"
 	mrpb "google.golang.org/genproto/googleapis/api/monitoredres"
@@ -452,6 +453,11 @@ type Entry struct {
 	// Trace is the resource name of the trace associated with the log entry,
 	// if any. If it contains a relative resource name, the name is assumed to
 	// be relative to //tracing.googleapis.com.
+	//
+	// If Trace is empty, Trace, SpanID and TraceSampled are populated from the
+	// OpenTelemetry span in the context passed to LogSync, or else from
+	// HTTPRequest: from the span in the context of its Request, or from its
+	// traceparent or X-Cloud-Trace-Context header.
 	Trace string
 
 	// ID of the span within the trace associated with the log entry.
@@ -628,7 +634,7 @@ func jsonValueToStructValue(v interface{}) *structpb.Value {
 // and will block, it is intended primarily for debugging or critical errors.
 // Prefer Log for most uses.
 func (l *Logger) LogSync(ctx context.Context, e Entry) error {
-	ent, err := toLogEntryInternal(e, l, l.client.parent, 1)
+	ent, err := toLogEntryInternal(ctx, e, l, l.client.parent, 1)
 	if err != nil {
 		return err
 	}
@@ -654,7 +660,7 @@ func (l *Logger) LogSync(ctx context.Context, e Entry) error {
 
 // Log buffers the Entry for output to the logging service. It never blocks.
 func (l *Logger) Log(e Entry) {
-	ent, err := toLogEntryInternal(e, l, l.client.parent, 1)
+	ent, err := toLogEntryInternal(context.Background(), e, l, l.client.parent, 1)
 	if err != nil {
 		l.client.error(err)
 		return
@@ -727,6 +733,11 @@ func populateTraceInfo(e *Entry, req *http.Request) bool {
 			return false
 		}
 	}
+	// The span of a request instrumented with OpenTelemetry is more specific
+	// than the trace context headers of the request.
+	if populateTraceInfoFromContext(e, req.Context()) {
+		return true
+	}
 	header := req.Header.Get("Traceparent")
 	if header != "" {
 		// do not use traceSampled flag defined by traceparent because
@@ -752,6 +763,19 @@ func populateTraceInfo(e *Entry, req *http.Request) bool {
 	return false
 }
 
+// populateTraceInfoFromContext populates the trace fields of e from the
+// OpenTelemetry span in ctx, if there is one.
+func populateTraceInfoFromContext(e *Entry, ctx context.Context) bool {
+	sc := trace.SpanContextFromContext(ctx)
+	if !sc.IsValid() {
+		return false
+	}
+	e.Trace = sc.TraceID().String()
+	e.SpanID = sc.SpanID().String()
+	e.TraceSampled = e.TraceSampled || sc.IsSampled()
+	return true
+}
+
 // As per format described at https://www.w3.org/TR/trace-context/#traceparent-header-field-values
 var validTraceParentExpression = regexp.MustCompile(`^(00)-([a-fA-F\d]{32})-([a-f\d]{16})-([a-fA-F\d]{2})$`)
 
@@ -827,10 +851,13 @@ func (l *Logger) ToLogEntry(e Entry, parent string) (*logpb.LogEntry, error) {
 	if err != nil {
 		return nil, err
 	}
-	return toLogEntryInternal(e, l, parent, 1)
+	return toLogEntryInternal(context.Background(), e, l, parent, 1)
 }
 
-func toLogEntryInternal(e Entry, l *Logger, parent string, skipLevels int) (*logpb.LogEntry, error) {
+// toLogEntryInternal converts e to a LogEntry. If e has no trace, the trace
+// fields are populated from the OpenTelemetry span in ctx, or else from the
+// HTTP request of e.
+func toLogEntryInternal(ctx context.Context, e Entry, l *Logger, parent string, skipLevels int) (*logpb.LogEntry, error) {
 	if e.LogName != "" {
 		return nil, errors.New("logging: Entry.LogName should be not be set when writing")
 	}
@@ -856,7 +883,9 @@ func toLogEntryInternal(e Entry, l *Logger, parent string, skipLevels int) (*log
 		}
 	}
 	if e.Trace == "" {
-		populateTraceInfo(&e, nil)
+		if !populateTraceInfoFromContext(&e, ctx) {
+			populateTraceInfo(&e, nil)
+		}
 		// format trace
 		if e.Trace != "" && !strings.Contains(e.Trace, "/traces/") {
 			e.Trace = fmt.Sprintf("%s/traces/%s", parent, e.Trace)
@@ -45,6 +45,7 @@ import (
 	"github.com/google/go-cmp/cmp"
 	"github.com/google/go-cmp/cmp/cmpopts"
 	gax "github.com/googleapis/gax-go/v2"
+	"go.opentelemetry.io/otel/trace"
 	"golang.org/x/oauth2"
 	"google.golang.org/api/iterator"
 	"google.golang.org/api/option"
@@ -263,6 +264,11 @@ func TestContextFunc(t *testing.T) {
 
 func TestToLogEntry(t *testing.T) {
 	u := &url.URL{Scheme: "http"}
+	spanCtx := trace.ContextWithSpanContext(context.Background(), trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID:    trace.TraceID{0x10, 0x54, 0x45, 0xaa, 0x78, 0x43, 0xbc, 0x8b, 0xf2, 0x06, 0xb1, 0x20, 0x00, 0x10, 0x00, 0x12},
+		SpanID:     trace.SpanID{0, 0, 0, 0, 0, 0, 0, 0x4a},
+		TraceFlags: trace.FlagsSampled,
+	}))
 	tests := []struct {
 		name      string
 		in        logging.Entry
@@ -459,6 +465,22 @@ func TestToLogEntry(t *testing.T) {
 				SpanId: "0000000000000aaa",
 			},
 		},
+		{
+			name: "OpenTelemetry span in request context together with Traceparent header",
+			in: logging.Entry{
+				HTTPRequest: &logging.HTTPRequest{
+					Request: (&http.Request{
+						URL:    u,
+						Header: http.Header{"Traceparent": {"00-105445aa7843bc8bf206b1200010aaaa-0000000000000aaa-00"}},
+					}).WithContext(spanCtx),
+				},
+			},
+			want: logpb.LogEntry{
+				Trace:        "projects/P/traces/105445aa7843bc8bf206b12000100012",
+				SpanId:       "000000000000004a",
+				TraceSampled: true,
+			},
+		},
 		{
 			name: "Traceparent header invalid protocol",
 			in: logging.Entry{
@@ -1410,6 +1432,59 @@ func TestInstrumentationIngestion(t *testing.T) {
 	internal.InstrumentOnce = onceBackup
 }
 
+func TestLogSyncTraceFromContext(t *testing.T) {
+	var got []*logpb.LogEntry
+	client, err := fakeClient("projects/test", func(e *logpb.WriteLogEntriesRequest) {
+		got = e.GetEntries()
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+
+	sc := trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID:    trace.TraceID{0x10, 0x54, 0x45, 0xaa, 0x78, 0x43, 0xbc, 0x8b, 0xf2, 0x06, 0xb1, 0x20, 0x00, 0x10, 0x00, 0x12},
+		SpanID:     trace.SpanID{0, 0, 0, 0, 0, 0, 0, 0x4a},
+		TraceFlags: trace.FlagsSampled,
+	})
+	ctx := trace.ContextWithSpanContext(context.Background(), sc)
+	logger := client.Logger("test-trace")
+	for _, test := range []struct {
+		name string
+		in   logging.Entry
+		want *logpb.LogEntry
+	}{
+		{
+			name: "trace from span",
+			in:   logging.Entry{Payload: "test string"},
+			want: &logpb.LogEntry{Trace: "projects/test/traces/105445aa7843bc8bf206b12000100012", SpanId: "000000000000004a", TraceSampled: true},
+		},
+		{
+			name: "trace set explicitly",
+			in:   logging.Entry{Payload: "test string", Trace: "projects/test/traces/t1", SpanID: "007"},
+			want: &logpb.LogEntry{Trace: "projects/test/traces/t1", SpanId: "007"},
+		},
+	} {
+		got = nil
+		if err := logger.LogSync(ctx, test.in); err != nil {
+			t.Fatal(err)
+		}
+		var e *logpb.LogEntry
+		for _, ent := range got {
+			if ent.GetTextPayload() == "test string" {
+				e = ent
+			}
+		}
+		if e == nil {
+			t.Fatalf("%s: entry not written, got %v", test.name, got)
+		}
+		if e.Trace != test.want.Trace || e.SpanId != test.want.SpanId || e.TraceSampled != test.want.TraceSampled {
+			t.Errorf("%s: got trace (%q, %q, %t), want (%q, %q, %t)", test.name,
+				e.Trace, e.SpanId, e.TraceSampled, test.want.Trace, test.want.SpanId, test.want.TraceSampled)
+		}
+	}
+}
+
 func TestInstrumentationWithRedirect(t *testing.T) {
 	want := []string{
 		// do not format the string to preserve expected new-line between messages
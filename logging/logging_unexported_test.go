@@ -17,6 +17,7 @@
 package logging
 
 import (
+	"context"
 	"encoding/json"
 	"net/http"
 	"net/url"
@@ -208,7 +209,7 @@ func TestToLogEntryPayload(t *testing.T) {
 			},
 		},
 	} {
-		e, err := toLogEntryInternal(Entry{Payload: test.in}, nil, "", 0)
+		e, err := toLogEntryInternal(context.Background(), Entry{Payload: test.in}, nil, "", 0)
 		if err != nil {
 			t.Fatalf("%+v: %v", test.in, err)
 		}
@@ -0,0 +1,227 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pubsub
+
+import (
+	"context"
+	"math"
+	"sort"
+	"sync"
+	"time"
+)
+
+// ConcurrencyAutoscaling controls how Receive adjusts the number of messages
+// that it processes at once. See ReceiveSettings.Autoscaling.
+//
+// Receive starts by processing MinOutstandingMessages messages at once.
+// Periodically, it compares the latency of processing messages, from the time
+// a message is passed to the function given to Receive until it is acked or
+// nacked, with TargetLatency. If most messages took longer, it lowers the
+// limit; if they were processed in time and Receive had to wait for the limit
+// before processing more messages, it raises the limit. The limit never
+// exceeds MaxOutstandingMessages.
+//
+// The limit applies only to how many messages are processed at once, not to
+// how many are pulled: Receive still pulls up to MaxOutstandingMessages and
+// MaxOutstandingBytes, and keeps extending the leases of the messages that
+// wait for the limit. Lower those settings to bound the number of messages
+// held in memory.
+type ConcurrencyAutoscaling struct {
+	// MinOutstandingMessages is the lowest number of messages that Receive
+	// processes at once, and the number it starts with. If zero, it is 1.
+	MinOutstandingMessages int
+
+	// TargetLatency is the processing latency that Receive tries to keep the
+	// 90th percentile of messages within. Keep it well below the ack deadline
+	// of the subscription, so that messages are processed before their
+	// deadline would need to be extended. If zero, it is 5 seconds.
+	TargetLatency time.Duration
+}
+
+const (
+	defaultAutoscalingTargetLatency = 5 * time.Second
+
+	// autoscalingPeriod is how often the limit is adjusted.
+	autoscalingPeriod = time.Second
+
+	// maxAutoscalingSamples bounds the latencies kept between adjustments.
+	maxAutoscalingSamples = 1000
+)
+
+// concurrencyLimiter limits the number of messages being processed to a limit
+// that can change while messages are outstanding.
+type concurrencyLimiter struct {
+	mu      sync.Mutex
+	limit   int
+	inUse   int
+	changed chan struct{} // closed when inUse or limit changes
+	waited  bool          // whether acquire has waited since the last call to takeWaited
+}
+
+func newConcurrencyLimiter(limit int) *concurrencyLimiter {
+	return &concurrencyLimiter{limit: limit, changed: make(chan struct{})}
+}
+
+// acquire waits until fewer than limit messages are in use, and then takes
+// one.
+func (l *concurrencyLimiter) acquire(ctx context.Context) error {
+	for {
+		l.mu.Lock()
+		if l.inUse < l.limit {
+			l.inUse++
+			l.mu.Unlock()
+			return nil
+		}
+		l.waited = true
+		ch := l.changed
+		l.mu.Unlock()
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ch:
+		}
+	}
+}
+
+func (l *concurrencyLimiter) release() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.inUse--
+	l.notifyLocked()
+}
+
+func (l *concurrencyLimiter) setLimit(n int) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.limit = n
+	l.notifyLocked()
+}
+
+func (l *concurrencyLimiter) currentLimit() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.limit
+}
+
+// takeWaited reports whether acquire has had to wait since the last call,
+// and resets the report.
+func (l *concurrencyLimiter) takeWaited() bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	w := l.waited
+	l.waited = false
+	return w
+}
+
+func (l *concurrencyLimiter) notifyLocked() {
+	close(l.changed)
+	l.changed = make(chan struct{})
+}
+
+// concurrencyAutoscaler adjusts the limit of a concurrencyLimiter from the
+// processing latencies it records.
+type concurrencyAutoscaler struct {
+	limiter  *concurrencyLimiter
+	min, max int
+	target   time.Duration
+
+	mu      sync.Mutex
+	samples []time.Duration
+}
+
+// newConcurrencyAutoscaler returns an autoscaler for cfg, whose limit never
+// exceeds maxCount. If maxCount is not positive, there is no upper limit.
+func newConcurrencyAutoscaler(cfg *ConcurrencyAutoscaling, maxCount int) *concurrencyAutoscaler {
+	a := &concurrencyAutoscaler{
+		min:    cfg.MinOutstandingMessages,
+		max:    maxCount,
+		target: cfg.TargetLatency,
+	}
+	if a.max <= 0 {
+		a.max = math.MaxInt32
+	}
+	if a.min <= 0 {
+		a.min = 1
+	}
+	if a.min > a.max {
+		a.min = a.max
+	}
+	if a.target <= 0 {
+		a.target = defaultAutoscalingTargetLatency
+	}
+	a.limiter = newConcurrencyLimiter(a.min)
+	return a
+}
+
+// record notes that a message took d to process.
+func (a *concurrencyAutoscaler) record(d time.Duration) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if len(a.samples) < maxAutoscalingSamples {
+		a.samples = append(a.samples, d)
+	}
+}
+
+// run adjusts the limit periodically until ctx is done.
+func (a *concurrencyAutoscaler) run(ctx context.Context) {
+	ticker := time.NewTicker(autoscalingPeriod)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			a.adjust()
+		}
+	}
+}
+
+// adjust changes the limit according to the latencies recorded since the
+// last adjustment: it lowers the limit by a quarter if the 90th percentile
+// exceeds the target, and raises it by a quarter if the latencies met the
+// target and the limit held back messages.
+func (a *concurrencyAutoscaler) adjust() {
+	a.mu.Lock()
+	samples := a.samples
+	a.samples = nil
+	a.mu.Unlock()
+	waited := a.limiter.takeWaited()
+	if len(samples) == 0 {
+		return
+	}
+	sort.Slice(samples, func(i, j int) bool { return samples[i] < samples[j] })
+	p90 := samples[(len(samples)*9)/10]
+	limit := a.limiter.currentLimit()
+	step := limit / 4
+	if step < 1 {
+		step = 1
+	}
+	switch {
+	case p90 > a.target:
+		limit -= step
+		if limit < a.min {
+			limit = a.min
+		}
+	case waited:
+		if limit > a.max-step {
+			limit = a.max
+		} else {
+			limit += step
+		}
+	default:
+		return
+	}
+	a.limiter.setLimit(limit)
+}
@@ -0,0 +1,164 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pubsub
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestConcurrencyLimiter(t *testing.T) {
+	ctx := context.Background()
+	l := newConcurrencyLimiter(1)
+	if err := l.acquire(ctx); err != nil {
+		t.Fatal(err)
+	}
+	cctx, cancel := context.WithTimeout(ctx, 10*time.Millisecond)
+	defer cancel()
+	if err := l.acquire(cctx); err != context.DeadlineExceeded {
+		t.Fatalf("got %v, want DeadlineExceeded", err)
+	}
+	if !l.takeWaited() {
+		t.Error("takeWaited: got false, want true")
+	}
+
+	// Raising the limit unblocks a waiting acquire.
+	done := make(chan error)
+	go func() { done <- l.acquire(ctx) }()
+	time.Sleep(10 * time.Millisecond)
+	l.setLimit(2)
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+
+	// Lowering the limit holds back acquires until enough are released.
+	l.setLimit(1)
+	go func() { done <- l.acquire(ctx) }()
+	l.release()
+	select {
+	case <-done:
+		t.Fatal("acquire succeeded above the limit")
+	case <-time.After(10 * time.Millisecond):
+	}
+	l.release()
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestConcurrencyAutoscalerAdjust(t *testing.T) {
+	a := newConcurrencyAutoscaler(&ConcurrencyAutoscaling{
+		MinOutstandingMessages: 4,
+		TargetLatency:          time.Second,
+	}, 6)
+	limit := func() int { return a.limiter.currentLimit() }
+	if got, want := limit(), 4; got != want {
+		t.Fatalf("initial limit: got %d, want %d", got, want)
+	}
+
+	// Fast processing without waiting for the limit leaves it unchanged.
+	a.record(time.Millisecond)
+	a.adjust()
+	if got, want := limit(), 4; got != want {
+		t.Errorf("unconstrained: got %d, want %d", got, want)
+	}
+
+	// Fast processing that was held back raises the limit, up to the max.
+	for _, want := range []int{5, 6, 6} {
+		a.limiter.waited = true
+		a.record(time.Millisecond)
+		a.adjust()
+		if got := limit(); got != want {
+			t.Errorf("constrained: got %d, want %d", got, want)
+		}
+	}
+
+	// Slow processing lowers the limit, down to the min.
+	for _, want := range []int{5, 4, 4} {
+		for i := 0; i < 10; i++ {
+			a.record(2 * time.Second)
+		}
+		a.adjust()
+		if got := limit(); got != want {
+			t.Errorf("slow: got %d, want %d", got, want)
+		}
+	}
+
+	// Without samples, nothing changes.
+	a.limiter.waited = true
+	a.adjust()
+	if got, want := limit(), 4; got != want {
+		t.Errorf("no samples: got %d, want %d", got, want)
+	}
+}
+
+func TestReceiveAutoscaling(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	client, srv := newFake(t)
+	defer client.Close()
+	defer srv.Close()
+
+	topic := mustCreateTopic(t, client, "t")
+	sub, err := client.CreateSubscription(ctx, "s", SubscriptionConfig{Topic: topic})
+	if err != nil {
+		t.Fatal(err)
+	}
+	const n = 20
+	for i := 0; i < n; i++ {
+		srv.Publish(topic.name, []byte(fmt.Sprint(i)), nil)
+	}
+
+	sub.ReceiveSettings.Autoscaling = &ConcurrencyAutoscaling{MinOutstandingMessages: 2}
+	var (
+		mu                sync.Mutex
+		received          = map[string]bool{}
+		active, maxActive int32
+	)
+	err = sub.Receive(ctx, func(ctx context.Context, m *Message) {
+		a := atomic.AddInt32(&active, 1)
+		for {
+			old := atomic.LoadInt32(&maxActive)
+			if a <= old || atomic.CompareAndSwapInt32(&maxActive, old, a) {
+				break
+			}
+		}
+		time.Sleep(5 * time.Millisecond)
+		// Leave before acking, which lets the next message in.
+		atomic.AddInt32(&active, -1)
+		m.Ack()
+		mu.Lock()
+		defer mu.Unlock()
+		received[string(m.Data)] = true
+		if len(received) == n {
+			cancel()
+		}
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := len(received); got != n {
+		t.Errorf("got %d messages, want %d", got, n)
+	}
+	// The limit starts at 2 and is adjusted once a second, so no more
+	// than 2 messages should have been processed at once.
+	if got := atomic.LoadInt32(&maxActive); got > 2 {
+		t.Errorf("processed %d messages at once, want at most 2", got)
+	}
+}
@@ -0,0 +1,80 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pubsub
+
+import (
+	"context"
+
+	"go.opencensus.io/plugin/ocgrpc"
+	"go.opencensus.io/stats"
+	"google.golang.org/api/option"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/encoding/gzip"
+	grpcstats "google.golang.org/grpc/stats"
+)
+
+// compressedPublishKey marks the context of a Publish call whose request is
+// compressed.
+type compressedPublishKey struct{}
+
+// compressionCallOptions returns the gRPC call options that compress a
+// Publish request of reqSize bytes according to the topic's settings, and the
+// context to make the call with.
+func (t *Topic) compressionCallOptions(ctx context.Context, reqSize int) (context.Context, []grpc.CallOption) {
+	ps := t.PublishSettings
+	if !ps.EnableCompression || reqSize < ps.CompressionBytesThreshold {
+		return ctx, nil
+	}
+	return context.WithValue(ctx, compressedPublishKey{}, true), []grpc.CallOption{grpc.UseCompressor(gzip.Name)}
+}
+
+// compressionStatsHandler is a gRPC stats handler that records the
+// compression ratio achieved by compressed Publish requests. It passes all
+// events on to next.
+type compressionStatsHandler struct {
+	next grpcstats.Handler
+}
+
+// newCompressionStatsHandler returns the stats handler for a client created
+// with opts, which wraps the OpenCensus handler that the transport would
+// otherwise install. It returns nil if telemetry is disabled, in which case
+// the transport installs no handler and neither should the client.
+func newCompressionStatsHandler(opts []option.ClientOption) *compressionStatsHandler {
+	for _, opt := range opts {
+		if opt == option.WithTelemetryDisabled() {
+			return nil
+		}
+	}
+	return &compressionStatsHandler{next: &ocgrpc.ClientHandler{}}
+}
+
+func (h *compressionStatsHandler) TagRPC(ctx context.Context, info *grpcstats.RPCTagInfo) context.Context {
+	return h.next.TagRPC(ctx, info)
+}
+
+func (h *compressionStatsHandler) HandleRPC(ctx context.Context, s grpcstats.RPCStats) {
+	if p, ok := s.(*grpcstats.OutPayload); ok && p.Client && p.WireLength > 0 && ctx.Value(compressedPublishKey{}) != nil {
+		stats.Record(ctx, PublishCompressionRatio.M(float64(p.Length)/float64(p.WireLength)))
+	}
+	h.next.HandleRPC(ctx, s)
+}
+
+func (h *compressionStatsHandler) TagConn(ctx context.Context, info *grpcstats.ConnTagInfo) context.Context {
+	return h.next.TagConn(ctx, info)
+}
+
+func (h *compressionStatsHandler) HandleConn(ctx context.Context, s grpcstats.ConnStats) {
+	h.next.HandleConn(ctx, s)
+}
@@ -0,0 +1,75 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pubsub
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"go.opencensus.io/stats/view"
+	"google.golang.org/api/option"
+)
+
+func TestPublishCompression(t *testing.T) {
+	ctx := context.Background()
+	client, srv := newFake(t)
+	defer client.Close()
+	defer srv.Close()
+
+	if err := view.Register(PublishCompressionRatioView); err != nil {
+		t.Fatal(err)
+	}
+	defer view.Unregister(PublishCompressionRatioView)
+
+	topic := mustCreateTopic(t, client, "t")
+	defer topic.Stop()
+	topic.PublishSettings.EnableCompression = true
+	data := []byte(strings.Repeat(`{"key": "value"}`, 1000))
+	if _, err := topic.Publish(ctx, &Message{Data: data}).Get(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := topic.Publish(ctx, &Message{Data: []byte("small")}).Get(ctx); err != nil {
+		t.Fatal(err)
+	}
+	msgs := srv.Messages()
+	if len(msgs) != 2 || string(msgs[0].Data) != string(data) {
+		t.Fatalf("server did not receive the compressed message intact")
+	}
+
+	rows, err := view.RetrieveData(PublishCompressionRatioView.Name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rows) != 1 {
+		t.Fatalf("got %d rows, want 1", len(rows))
+	}
+	d := rows[0].Data.(*view.DistributionData)
+	if d.Count != 1 {
+		t.Errorf("got %d compressed requests, want 1", d.Count)
+	}
+	if d.Mean <= 10 {
+		t.Errorf("got compression ratio %v, want more than 10", d.Mean)
+	}
+}
+
+func TestNewCompressionStatsHandler(t *testing.T) {
+	if h := newCompressionStatsHandler(nil); h.next == nil {
+		t.Error("got no OpenCensus handler, want one")
+	}
+	if h := newCompressionStatsHandler([]option.ClientOption{option.WithTelemetryDisabled()}); h != nil {
+		t.Error("got a stats handler with telemetry disabled, want none")
+	}
+}
@@ -0,0 +1,302 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pubsub
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"cloud.google.com/go/iam"
+	"golang.org/x/time/rate"
+)
+
+const (
+	publisherRole  iam.RoleName = "roles/pubsub.publisher"
+	subscriberRole iam.RoleName = "roles/pubsub.subscriber"
+)
+
+// ServiceAgent returns the IAM member of the Pub/Sub service agent of the
+// project with the given number. The service agent forwards undeliverable
+// messages to dead-letter topics.
+func ServiceAgent(projectNumber int64) string {
+	return fmt.Sprintf("serviceAccount:service-%d@gcp-sa-pubsub.iam.gserviceaccount.com", projectNumber)
+}
+
+// DeadLetterSettings configures dead lettering for a subscription with
+// Subscription.ConfigureDeadLetter.
+type DeadLetterSettings struct {
+	// Topic is the topic that undeliverable messages are forwarded to.
+	Topic *Topic
+
+	// MaxDeliveryAttempts is the number of delivery attempts after which a
+	// message is forwarded to Topic. It must be between 5 and 100.
+	MaxDeliveryAttempts int
+
+	// ServiceAgent is the IAM member that Pub/Sub uses to forward messages,
+	// as returned by ServiceAgent. It must be granted the publisher role on
+	// Topic and the subscriber role on the subscription.
+	ServiceAgent string
+
+	// GrantPermissions causes ConfigureDeadLetter to grant any roles that
+	// ServiceAgent is missing, instead of returning an error.
+	GrantPermissions bool
+}
+
+// A DeadLetterPermissionError is returned by ConfigureDeadLetter when the
+// service agent lacks the roles needed to forward messages.
+type DeadLetterPermissionError struct {
+	// Missing describes each missing grant, as "role on resource".
+	Missing []string
+}
+
+func (e *DeadLetterPermissionError) Error() string {
+	return fmt.Sprintf("pubsub: dead lettering service agent is missing %s", strings.Join(e.Missing, ", "))
+}
+
+// ConfigureDeadLetter sets the dead-letter policy of s after checking that
+// the Pub/Sub service agent can forward messages from s to the dead-letter
+// topic. Without those permissions, messages are never dead-lettered. If a
+// role is missing, ConfigureDeadLetter grants it when
+// settings.GrantPermissions is true, and otherwise returns a
+// *DeadLetterPermissionError without changing s.
+//
+// Checking and granting roles requires permission to get and set the IAM
+// policies of s and the dead-letter topic.
+func (s *Subscription) ConfigureDeadLetter(ctx context.Context, settings DeadLetterSettings) (SubscriptionConfig, error) {
+	if settings.Topic == nil {
+		return SubscriptionConfig{}, errors.New("pubsub: DeadLetterSettings.Topic is required")
+	}
+	if settings.ServiceAgent == "" {
+		return SubscriptionConfig{}, errors.New("pubsub: DeadLetterSettings.ServiceAgent is required")
+	}
+	if n := settings.MaxDeliveryAttempts; n < 5 || n > 100 {
+		return SubscriptionConfig{}, fmt.Errorf("pubsub: MaxDeliveryAttempts must be between 5 and 100; got %d", n)
+	}
+	grants := []struct {
+		h    *iam.Handle
+		name string
+		role iam.RoleName
+	}{
+		{settings.Topic.IAM(), settings.Topic.String(), publisherRole},
+		{s.IAM(), s.String(), subscriberRole},
+	}
+	var missing []string
+	for _, g := range grants {
+		p, err := g.h.Policy(ctx)
+		if err != nil {
+			return SubscriptionConfig{}, err
+		}
+		if p.HasRole(settings.ServiceAgent, g.role) {
+			continue
+		}
+		if !settings.GrantPermissions {
+			missing = append(missing, fmt.Sprintf("%s on %s", g.role, g.name))
+			continue
+		}
+		p.Add(settings.ServiceAgent, g.role)
+		if err := g.h.SetPolicy(ctx, p); err != nil {
+			return SubscriptionConfig{}, err
+		}
+	}
+	if len(missing) > 0 {
+		return SubscriptionConfig{}, &DeadLetterPermissionError{Missing: missing}
+	}
+	return s.Update(ctx, SubscriptionConfigToUpdate{
+		DeadLetterPolicy: &DeadLetterPolicy{
+			DeadLetterTopic:     settings.Topic.String(),
+			MaxDeliveryAttempts: settings.MaxDeliveryAttempts,
+		},
+	})
+}
+
+// ReplayCountAttribute is the message attribute in which a
+// DeadLetterReplayer records how many times a message has been replayed.
+const ReplayCountAttribute = "googclient_replay_count"
+
+// A DeadLetterReplayer moves messages from a subscription to a dead-letter
+// topic back to a topic, typically the one the messages were originally
+// published to, once the problem that caused them to be dead-lettered has
+// been fixed.
+//
+// Each replayed message carries the number of times it has been replayed in
+// the ReplayCountAttribute attribute. To keep a message that can never be
+// processed from cycling between the topics forever, messages that have been
+// replayed MaxReplays times are left on the dead-letter subscription.
+//
+// Messages that are left on the dead-letter subscription are held, neither
+// acked nor nacked, until Run returns, so that they are delivered to Run at
+// most once. They count against the MaxOutstandingMessages and
+// MaxOutstandingBytes receive settings of Source, so a Run that holds that
+// many messages stops receiving and returns after IdleTimeout.
+type DeadLetterReplayer struct {
+	// Source is the subscription to the dead-letter topic.
+	Source *Subscription
+
+	// Target is the topic the messages are published to.
+	Target *Topic
+
+	// MaxReplays is the number of times a message may be replayed. Messages
+	// that have reached it stay on Source. If zero, it is 3.
+	MaxReplays int
+
+	// MessagesPerSecond limits the rate at which messages are published to
+	// Target. If zero, the rate is not limited.
+	MessagesPerSecond float64
+
+	// IdleTimeout is how long Run waits for a message to replay before it
+	// returns. If zero, it is 30 seconds.
+	IdleTimeout time.Duration
+
+	// Filter, if not nil, is called for each message, and only messages for
+	// which it returns true are replayed. Other messages stay on Source.
+	Filter func(*Message) bool
+}
+
+// ReplayStats reports the outcome of DeadLetterReplayer.Run.
+type ReplayStats struct {
+	// Replayed is the number of messages published to Target and acked on
+	// Source.
+	Replayed int
+	// Skipped is the number of distinct messages left on Source, because
+	// they reached MaxReplays or were rejected by Filter.
+	Skipped int
+	// Failed is the number of messages that could not be published.
+	Failed int
+}
+
+// Run replays messages until ctx is done or no message has been replayed
+// for IdleTimeout. It returns what it did, and any error from receiving
+// messages.
+func (r *DeadLetterReplayer) Run(ctx context.Context) (ReplayStats, error) {
+	maxReplays := r.MaxReplays
+	if maxReplays == 0 {
+		maxReplays = 3
+	}
+	idleTimeout := r.IdleTimeout
+	if idleTimeout == 0 {
+		idleTimeout = 30 * time.Second
+	}
+	var limiter *rate.Limiter
+	if r.MessagesPerSecond > 0 {
+		limiter = rate.NewLimiter(rate.Limit(r.MessagesPerSecond), 1)
+	}
+
+	var (
+		mu           sync.Mutex
+		stats        ReplayStats
+		skipped      = map[string]bool{}
+		held         []*Message
+		released     bool
+		lastActivity = time.Now()
+	)
+	cctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	go func() {
+		t := time.NewTicker(idleTimeout / 10)
+		defer t.Stop()
+		for {
+			select {
+			case <-cctx.Done():
+				// Receive doesn't return while it holds messages, so hand
+				// the skipped messages back to Source once it is stopping.
+				mu.Lock()
+				released = true
+				ms := held
+				held = nil
+				mu.Unlock()
+				for _, m := range ms {
+					m.Nack()
+				}
+				return
+			case <-t.C:
+				mu.Lock()
+				idle := time.Since(lastActivity) >= idleTimeout
+				mu.Unlock()
+				if idle {
+					cancel()
+				}
+			}
+		}
+	}()
+
+	// skip leaves m on Source. Nacking it right away would have it
+	// redelivered to Run until Run returns, so it is held instead.
+	skip := func(m *Message) {
+		mu.Lock()
+		if !skipped[m.ID] {
+			skipped[m.ID] = true
+			stats.Skipped++
+		}
+		if !released {
+			held = append(held, m)
+			m = nil
+		}
+		mu.Unlock()
+		if m != nil {
+			m.Nack()
+		}
+	}
+	err := r.Source.Receive(cctx, func(ctx context.Context, m *Message) {
+		n := replayCount(m)
+		if n >= maxReplays || (r.Filter != nil && !r.Filter(m)) {
+			skip(m)
+			return
+		}
+		if limiter != nil {
+			if err := limiter.Wait(ctx); err != nil {
+				m.Nack()
+				return
+			}
+		}
+		attrs := make(map[string]string, len(m.Attributes)+1)
+		for k, v := range m.Attributes {
+			attrs[k] = v
+		}
+		attrs[ReplayCountAttribute] = strconv.Itoa(n + 1)
+		msg := &Message{Data: m.Data, Attributes: attrs}
+		if r.Target.EnableMessageOrdering {
+			msg.OrderingKey = m.OrderingKey
+		}
+		if _, err := r.Target.Publish(ctx, msg).Get(ctx); err != nil {
+			mu.Lock()
+			stats.Failed++
+			mu.Unlock()
+			m.Nack()
+			return
+		}
+		m.Ack()
+		mu.Lock()
+		stats.Replayed++
+		lastActivity = time.Now()
+		mu.Unlock()
+	})
+	mu.Lock()
+	defer mu.Unlock()
+	return stats, err
+}
+
+// replayCount returns the number of times m has been replayed.
+func replayCount(m *Message) int {
+	n, err := strconv.Atoi(m.Attributes[ReplayCountAttribute])
+	if err != nil || n < 0 {
+		return 0
+	}
+	return n
+}
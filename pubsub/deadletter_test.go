@@ -0,0 +1,167 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pubsub
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"cloud.google.com/go/internal/testutil"
+)
+
+func TestServiceAgent(t *testing.T) {
+	got := ServiceAgent(123)
+	if want := "serviceAccount:service-123@gcp-sa-pubsub.iam.gserviceaccount.com"; got != want {
+		t.Errorf("ServiceAgent(123) = %q, want %q", got, want)
+	}
+}
+
+func TestConfigureDeadLetterValidation(t *testing.T) {
+	ctx := context.Background()
+	client, srv := newFake(t)
+	defer client.Close()
+	defer srv.Close()
+
+	topic := mustCreateTopic(t, client, "t")
+	dlq := mustCreateTopic(t, client, "dlq")
+	sub, err := client.CreateSubscription(ctx, "s", SubscriptionConfig{Topic: topic})
+	if err != nil {
+		t.Fatal(err)
+	}
+	agent := ServiceAgent(1)
+	for _, settings := range []DeadLetterSettings{
+		{MaxDeliveryAttempts: 5, ServiceAgent: agent},
+		{Topic: dlq, MaxDeliveryAttempts: 5},
+		{Topic: dlq, MaxDeliveryAttempts: 4, ServiceAgent: agent},
+		{Topic: dlq, MaxDeliveryAttempts: 101, ServiceAgent: agent},
+	} {
+		if _, err := sub.ConfigureDeadLetter(ctx, settings); err == nil {
+			t.Errorf("ConfigureDeadLetter(%+v) got nil error, want error", settings)
+		}
+	}
+}
+
+func TestDeadLetterReplayer(t *testing.T) {
+	ctx := context.Background()
+	client, srv := newFake(t)
+	defer client.Close()
+	defer srv.Close()
+
+	target := mustCreateTopic(t, client, "target")
+	dlq := mustCreateTopic(t, client, "dlq")
+	targetSub, err := client.CreateSubscription(ctx, "target-sub", SubscriptionConfig{Topic: target})
+	if err != nil {
+		t.Fatal(err)
+	}
+	dlqSub, err := client.CreateSubscription(ctx, "dlq-sub", SubscriptionConfig{Topic: dlq})
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv.Publish(dlq.String(), []byte("new"), map[string]string{"k": "v"})
+	srv.Publish(dlq.String(), []byte("replayed-once"), map[string]string{ReplayCountAttribute: "1"})
+	srv.Publish(dlq.String(), []byte("exhausted"), map[string]string{ReplayCountAttribute: "2"})
+
+	r := &DeadLetterReplayer{
+		Source:      dlqSub,
+		Target:      target,
+		MaxReplays:  2,
+		IdleTimeout: 500 * time.Millisecond,
+	}
+	stats, err := r.Run(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := testutil.Diff(stats, ReplayStats{Replayed: 2, Skipped: 1}); diff != "" {
+		t.Errorf("stats: -got, +want:\n%s", diff)
+	}
+	target.Stop()
+
+	var (
+		mu  sync.Mutex
+		got []string
+	)
+	cctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+	err = targetSub.Receive(cctx, func(_ context.Context, m *Message) {
+		m.Ack()
+		mu.Lock()
+		defer mu.Unlock()
+		got = append(got, string(m.Data)+":"+m.Attributes[ReplayCountAttribute])
+		if len(got) == 2 {
+			cancel()
+		}
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sort.Strings(got)
+	if diff := testutil.Diff(got, []string{"new:1", "replayed-once:2"}); diff != "" {
+		t.Errorf("replayed messages: -got, +want:\n%s", diff)
+	}
+}
+
+func TestDeadLetterReplayerDeliversSkippedOnce(t *testing.T) {
+	ctx := context.Background()
+	client, srv := newFake(t)
+	defer client.Close()
+	defer srv.Close()
+
+	target := mustCreateTopic(t, client, "target")
+	defer target.Stop()
+	dlq := mustCreateTopic(t, client, "dlq")
+	dlqSub, err := client.CreateSubscription(ctx, "dlq-sub", SubscriptionConfig{Topic: dlq})
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv.Publish(dlq.String(), []byte("a"), nil)
+	srv.Publish(dlq.String(), []byte("b"), map[string]string{ReplayCountAttribute: "1"})
+	srv.Publish(dlq.String(), []byte("exhausted"), map[string]string{ReplayCountAttribute: "3"})
+
+	var (
+		mu         sync.Mutex
+		deliveries = map[string]int{}
+	)
+	r := &DeadLetterReplayer{
+		Source:      dlqSub,
+		Target:      target,
+		IdleTimeout: 500 * time.Millisecond,
+		Filter: func(m *Message) bool {
+			mu.Lock()
+			defer mu.Unlock()
+			deliveries[string(m.Data)]++
+			return false
+		},
+	}
+	stats, err := r.Run(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := testutil.Diff(stats, ReplayStats{Skipped: 3}); diff != "" {
+		t.Errorf("stats: -got, +want:\n%s", diff)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if diff := testutil.Diff(deliveries, map[string]int{"a": 1, "b": 1}); diff != "" {
+		t.Errorf("deliveries: -got, +want:\n%s", diff)
+	}
+	for _, m := range srv.Messages() {
+		if m.Acks != 0 {
+			t.Errorf("message %q was acked", m.Data)
+		}
+	}
+}
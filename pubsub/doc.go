@@ -86,6 +86,19 @@ to speed redelivery. For more information and configuration options, see
 Note: It is possible for Messages to be redelivered, even if Message.Ack has
 been called. Client code must be robust to multiple deliveries of messages.
 
+For subscriptions with exactly-once delivery enabled, a successful ack
+guarantees that the message will not be redelivered. Use
+Message.AckWithResult to find out whether the ack succeeded:
+
+	r := m.AckWithResult()
+	status, err := r.Get(ctx)
+	if err != nil {
+		// The message may be redelivered; status says why.
+	}
+
+For other subscriptions, AckWithResult and NackWithResult report success as
+soon as the ack or nack is recorded by the client.
+
 Note: This uses pubsub's streaming pull feature. This feature properties that
 may be surprising. Please take a look at https://cloud.google.com/pubsub/docs/pull#streamingpull
 for more details on how streaming pull behaves compared to the synchronous
@@ -137,6 +150,12 @@ minutes, every 3 minutes. Suppose the application crashes 5 seconds after the
 library sends such an extension: the Pub/Sub server would wait the remaining
 2m55s before re-sending the messages out to other subscribers.
 
+The length of each extension can be bounded with ReceiveSettings.MinExtensionPeriod
+and MaxExtensionPeriod. A handler that knows how long it will take can change
+MaxExtension for the message it is processing with Message.SetMaxExtension, and
+ReceiveSettings.ShouldExtendLease can decide, before each extension, whether a
+message's deadline should be extended again.
+
 Please note that the client library does not use the subscription's AckDeadline
 by default. To enforce the subscription AckDeadline, set MaxExtension to the
 subscription's AckDeadline:
@@ -155,6 +174,16 @@ For use cases where message processing exceeds 30 minutes, we recommend using
 the base client in a pull model, since long-lived streams are periodically killed
 by firewalls. See the example at https://godoc.org/cloud.google.com/go/pubsub/apiv1#example-SubscriberClient-Pull-LengthyClientProcessing
 
+Tracing
+
+If the context passed to Topic.Publish carries an OpenTelemetry span, the
+client starts a span for the message and records its context in the message's
+attributes, in the W3C Trace Context format, under names beginning with
+"googclient_". Receive starts a span for processing each message that
+continues that trace, and passes it to the callback in its context.
+ReceiveBatch starts one span per batch, linked to the spans of the messages'
+publishers. Spans are created with the global OpenTelemetry TracerProvider.
+
 Emulator
 
 To use an emulator with this library, you can set the PUBSUB_EMULATOR_HOST
@@ -42,6 +42,9 @@ type flowControllerPurpose int
 const (
 	flowControllerPurposeSubscription flowControllerPurpose = iota
 	flowControllerPurposeTopic
+	// flowControllerPurposeOrderingKey is for the flow controller of a single
+	// ordering key, which does not record outstanding stats.
+	flowControllerPurposeOrderingKey
 )
 
 // FlowControlSettings controls flow control for messages while publishing or subscribing.
@@ -202,6 +205,9 @@ func (f *flowController) count() int {
 }
 
 func (f *flowController) recordOutstandingMessages(ctx context.Context, n int64) {
+	if f.purpose == flowControllerPurposeOrderingKey {
+		return
+	}
 	if f.purpose == flowControllerPurposeTopic {
 		recordStat(ctx, PublisherOutstandingMessages, n)
 		return
@@ -211,6 +217,9 @@ func (f *flowController) recordOutstandingMessages(ctx context.Context, n int64)
 }
 
 func (f *flowController) recordOutstandingBytes(ctx context.Context, n int64) {
+	if f.purpose == flowControllerPurposeOrderingKey {
+		return
+	}
 	if f.purpose == flowControllerPurposeTopic {
 		recordStat(ctx, PublisherOutstandingBytes, n)
 		return
@@ -0,0 +1,78 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pubsub
+
+import (
+	"context"
+
+	ipubsub "cloud.google.com/go/internal/pubsub"
+)
+
+// A PublishHandler publishes a message. Topic.Publish is a PublishHandler.
+type PublishHandler func(ctx context.Context, msg *Message) *PublishResult
+
+// A PublishInterceptor intercepts each call to Topic.Publish for the topics
+// of a client. It may inspect or modify msg, and then call next to continue
+// publishing it; or it may return without calling next, to reject the
+// message, typically with a result made by FailedPublishResult. Interceptors
+// are set with ClientConfig.PublishInterceptors.
+//
+// Interceptors are called synchronously from Publish, before the message is
+// batched, so they should not block.
+type PublishInterceptor func(ctx context.Context, msg *Message, next PublishHandler) *PublishResult
+
+// A ReceiveHandler processes a received message, like the function passed to
+// Subscription.Receive.
+type ReceiveHandler func(ctx context.Context, msg *Message)
+
+// A ReceiveInterceptor intercepts each message received by
+// Subscription.Receive or ReceiveBatch for the subscriptions of a client,
+// before it is passed to the user's function. It may inspect or modify msg,
+// and then call next to continue processing it; or it may ack or nack msg
+// and return without calling next, to drop it. Interceptors are set with
+// ClientConfig.ReceiveInterceptors.
+type ReceiveInterceptor func(ctx context.Context, msg *Message, next ReceiveHandler)
+
+// FailedPublishResult returns a PublishResult that is already done, with
+// err. It is meant for PublishInterceptors that reject messages.
+func FailedPublishResult(err error) *PublishResult {
+	r := ipubsub.NewPublishResult()
+	ipubsub.SetPublishResult(r, "", err)
+	return r
+}
+
+// chainPublishInterceptors returns a PublishHandler that calls the
+// interceptors in order, and then h.
+func chainPublishInterceptors(interceptors []PublishInterceptor, h PublishHandler) PublishHandler {
+	for i := len(interceptors) - 1; i >= 0; i-- {
+		ic, next := interceptors[i], h
+		h = func(ctx context.Context, msg *Message) *PublishResult {
+			return ic(ctx, msg, next)
+		}
+	}
+	return h
+}
+
+// chainReceiveInterceptors returns a ReceiveHandler that calls the
+// interceptors in order, and then h.
+func chainReceiveInterceptors(interceptors []ReceiveInterceptor, h ReceiveHandler) ReceiveHandler {
+	for i := len(interceptors) - 1; i >= 0; i-- {
+		ic, next := interceptors[i], h
+		h = func(ctx context.Context, msg *Message) {
+			ic(ctx, msg, next)
+		}
+	}
+	return h
+}
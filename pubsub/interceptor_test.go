@@ -0,0 +1,106 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pubsub
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"cloud.google.com/go/pubsub/pstest"
+	"google.golang.org/api/option"
+	"google.golang.org/grpc"
+)
+
+func TestInterceptors(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	srv := pstest.NewServer()
+	defer srv.Close()
+
+	var (
+		mu    sync.Mutex
+		calls []string
+	)
+	record := func(s string) {
+		mu.Lock()
+		defer mu.Unlock()
+		calls = append(calls, s)
+	}
+	errRejected := errors.New("rejected")
+	client, err := NewClientWithConfig(ctx, "P", &ClientConfig{
+		PublishInterceptors: []PublishInterceptor{
+			func(ctx context.Context, msg *Message, next PublishHandler) *PublishResult {
+				record("publish 1")
+				if string(msg.Data) == "reject" {
+					return FailedPublishResult(errRejected)
+				}
+				msg.Attributes = map[string]string{"intercepted": "true"}
+				return next(ctx, msg)
+			},
+			func(ctx context.Context, msg *Message, next PublishHandler) *PublishResult {
+				record("publish 2")
+				return next(ctx, msg)
+			},
+		},
+		ReceiveInterceptors: []ReceiveInterceptor{
+			func(ctx context.Context, msg *Message, next ReceiveHandler) {
+				record("receive " + msg.Attributes["intercepted"])
+				next(ctx, msg)
+			},
+		},
+	},
+		option.WithEndpoint(srv.Addr),
+		option.WithoutAuthentication(),
+		option.WithGRPCDialOption(grpc.WithInsecure()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+
+	topic := mustCreateTopic(t, client, "t")
+	defer topic.Stop()
+	sub, err := client.CreateSubscription(ctx, "s", SubscriptionConfig{Topic: topic})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := topic.Publish(ctx, &Message{Data: []byte("reject")}).Get(ctx); err != errRejected {
+		t.Errorf("got %v, want %v", err, errRejected)
+	}
+	if _, err := topic.Publish(ctx, &Message{Data: []byte("accept")}).Get(ctx); err != nil {
+		t.Fatal(err)
+	}
+	cctx, ccancel := context.WithCancel(ctx)
+	err = sub.Receive(cctx, func(_ context.Context, m *Message) {
+		record("handler")
+		m.Ack()
+		ccancel()
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{"publish 1", "publish 1", "publish 2", "receive true", "handler"}
+	if len(calls) != len(want) {
+		t.Fatalf("got calls %v, want %v", calls, want)
+	}
+	for i := range want {
+		if calls[i] != want[i] {
+			t.Fatalf("got calls %v, want %v", calls, want)
+		}
+	}
+}
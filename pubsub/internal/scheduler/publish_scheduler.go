@@ -187,16 +187,33 @@ func (s *PublishScheduler) IsPaused(orderingKey string) bool {
 	return ok
 }
 
+// PausedKeys returns the ordering keys whose bundlers are paused, in no
+// particular order.
+func (s *PublishScheduler) PausedKeys() []string {
+	s.keysMu.RLock()
+	defer s.keysMu.RUnlock()
+	var keys []string
+	for k := range s.keysWithErrors {
+		keys = append(keys, k)
+	}
+	return keys
+}
+
 // Pause pauses the bundler associated with the provided ordering key,
 // preventing it from accepting new messages. Any outstanding messages
 // that haven't been published will error. If orderingKey is empty,
-// this is a no-op.
-func (s *PublishScheduler) Pause(orderingKey string) {
-	if orderingKey != "" {
-		s.keysMu.Lock()
-		defer s.keysMu.Unlock()
-		s.keysWithErrors[orderingKey] = struct{}{}
+// this is a no-op. Pause reports whether the key was newly paused.
+func (s *PublishScheduler) Pause(orderingKey string) bool {
+	if orderingKey == "" {
+		return false
+	}
+	s.keysMu.Lock()
+	defer s.keysMu.Unlock()
+	if _, ok := s.keysWithErrors[orderingKey]; ok {
+		return false
 	}
+	s.keysWithErrors[orderingKey] = struct{}{}
+	return true
 }
 
 // Resume resumes accepting message with the provided ordering key.
@@ -16,15 +16,18 @@ package pubsub
 
 import (
 	"context"
+	"errors"
 	"io"
 	"strings"
 	"sync"
 	"time"
 
+	ipubsub "cloud.google.com/go/internal/pubsub"
 	vkit "cloud.google.com/go/pubsub/apiv1"
 	"cloud.google.com/go/pubsub/internal/distribution"
 	gax "github.com/googleapis/gax-go/v2"
 	pb "google.golang.org/genproto/googleapis/pubsub/v1"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
@@ -68,11 +71,21 @@ type messageIterator struct {
 	// message arrives, we'll record now+MaxExtension in this table; whenever we have a chance
 	// to update ack deadlines (via modack), we'll consult this table and only include IDs
 	// that are not beyond their deadline.
-	keepAliveDeadlines        map[string]time.Time
-	pendingAcks               map[string]bool
-	pendingNacks              map[string]bool
-	pendingModAcks            map[string]bool // ack IDs whose ack deadline is to be modified
-	err                       error           // error from stream failure
+	keepAliveDeadlines map[string]time.Time
+	// messages holds the outstanding messages by ack ID, if
+	// ReceiveSettings.ShouldExtendLease needs them.
+	messages map[string]*Message
+	// The pending maps hold the result to report for each ack ID, which is
+	// nil if the outcome of the request need not be reported.
+	pendingAcks    map[string]*AckResult
+	pendingNacks   map[string]*AckResult
+	pendingModAcks map[string]*AckResult // ack IDs whose ack deadline is to be modified
+	err            error                 // error from stream failure
+	// senderStopped is set when the sender exits. Acks and nacks are no
+	// longer sent after that, so their results are reported as failed.
+	senderStopped bool
+	// enableExactlyOnceDelivery is whether the subscription has exactly-once
+	// delivery enabled, as last reported by the streaming pull.
 	enableExactlyOnceDelivery bool
 }
 
@@ -116,9 +129,10 @@ func newMessageIterator(subc *vkit.SubscriberClient, subName string, po *pullOpt
 		drained:            make(chan struct{}),
 		ackTimeDist:        distribution.New(int(maxDurationPerLeaseExtension/time.Second) + 1),
 		keepAliveDeadlines: map[string]time.Time{},
-		pendingAcks:        map[string]bool{},
-		pendingNacks:       map[string]bool{},
-		pendingModAcks:     map[string]bool{},
+		messages:           map[string]*Message{},
+		pendingAcks:        map[string]*AckResult{},
+		pendingNacks:       map[string]*AckResult{},
+		pendingModAcks:     map[string]*AckResult{},
 	}
 	it.wg.Add(1)
 	go it.sender()
@@ -167,19 +181,61 @@ func (it *messageIterator) addToDistribution(receiveTime time.Time) {
 }
 
 // Called when a message is acked/nacked.
-func (it *messageIterator) done(ackID string, ack bool, receiveTime time.Time) {
+func (it *messageIterator) done(ackID string, ack bool, r *AckResult, receiveTime time.Time) {
 	it.addToDistribution(receiveTime)
 	it.mu.Lock()
 	defer it.mu.Unlock()
 	delete(it.keepAliveDeadlines, ackID)
+	delete(it.messages, ackID)
+	if it.senderStopped {
+		setAckResult(r, AcknowledgeStatusOther, it.stoppedErr())
+		return
+	}
+	if !it.enableExactlyOnceDelivery {
+		// Acks are fire and forget, so they succeed once recorded.
+		setAckResult(r, AcknowledgeStatusSuccess, nil)
+		r = nil
+	}
 	if ack {
-		it.pendingAcks[ackID] = true
+		it.pendingAcks[ackID] = r
 	} else {
-		it.pendingNacks[ackID] = true
+		it.pendingNacks[ackID] = r
 	}
 	it.checkDrained()
 }
 
+// stoppedErr returns the error to report for acks and nacks that are not sent
+// because the iterator stopped.
+//
+// Called with the lock held.
+func (it *messageIterator) stoppedErr() error {
+	if it.err != nil {
+		return it.err
+	}
+	return errAckAfterStop
+}
+
+// errAckAfterStop is reported for acks and nacks of messages that the
+// iterator stopped managing, for example after Receive returned.
+var errAckAfterStop = errors.New("pubsub: ack or nack not sent because the subscriber stopped")
+
+// stopSending is called when the sender exits. It reports the acks and nacks
+// that are pending, and any that follow, as failed.
+func (it *messageIterator) stopSending() {
+	it.mu.Lock()
+	defer it.mu.Unlock()
+	it.senderStopped = true
+	err := it.stoppedErr()
+	for _, r := range it.pendingAcks {
+		setAckResult(r, AcknowledgeStatusOther, err)
+	}
+	for _, r := range it.pendingNacks {
+		setAckResult(r, AcknowledgeStatusOther, err)
+	}
+	it.pendingAcks = map[string]*AckResult{}
+	it.pendingNacks = map[string]*AckResult{}
+}
+
 // fail is called when a stream method returns a permanent error.
 // fail returns it.err. This may be err, or it may be the error
 // set by an earlier call to fail.
@@ -232,16 +288,22 @@ func (it *messageIterator) receive(maxToPull int32) ([]*Message, error) {
 	// We received some messages. Remember them so we can keep them alive. Also,
 	// do a receipt mod-ack when streaming.
 	maxExt := time.Now().Add(it.po.maxExtension)
-	ackIDs := map[string]bool{}
+	ackIDs := map[string]*AckResult{}
 	it.mu.Lock()
 	for _, m := range msgs {
 		ackID := msgAckID(m)
 		addRecv(m.ID, ackID, now)
 		it.keepAliveDeadlines[ackID] = maxExt
+		if ackh, ok := msgAckHandler(m); ok {
+			ackh.setMaxExtFunc = it.setMaxExtension
+		}
+		if it.po.shouldExtendLease != nil {
+			it.messages[ackID] = m
+		}
 		// Don't change the mod-ack if the message is going to be nacked. This is
 		// possible if there are retries.
-		if !it.pendingNacks[ackID] {
-			ackIDs[ackID] = true
+		if _, ok := it.pendingNacks[ackID]; !ok {
+			ackIDs[ackID] = nil
 		}
 	}
 	deadline := it.ackDeadline()
@@ -280,12 +342,18 @@ func (it *messageIterator) recvMessages() ([]*pb.ReceivedMessage, error) {
 	if err != nil {
 		return nil, err
 	}
+	if sp := res.GetSubscriptionProperties(); sp != nil {
+		it.mu.Lock()
+		it.enableExactlyOnceDelivery = sp.GetExactlyOnceDeliveryEnabled()
+		it.mu.Unlock()
+	}
 	return res.ReceivedMessages, nil
 }
 
 // sender runs in a goroutine and handles all sends to the stream.
 func (it *messageIterator) sender() {
 	defer it.wg.Done()
+	defer it.stopSending()
 	defer it.ackTicker.Stop()
 	defer it.nackTicker.Stop()
 	defer it.pingTicker.Stop()
@@ -302,11 +370,14 @@ func (it *messageIterator) sender() {
 		sendModAcks := false
 		sendPing := false
 
+		it.mu.Lock()
 		dl := it.ackDeadline()
+		it.mu.Unlock()
 
 		select {
 		case <-it.failed:
-			// Stream failed: nothing to do, so stop immediately.
+			// Stream failed: nothing to do, so stop immediately. Pending acks
+			// and nacks are reported as failed by stopSending.
 			return
 
 		case <-it.drained:
@@ -319,6 +390,7 @@ func (it *messageIterator) sender() {
 			done = true
 
 		case <-it.kaTick:
+			it.checkLeaseExtensions(dl)
 			it.mu.Lock()
 			it.handleKeepAlives()
 			sendModAcks = (len(it.pendingModAcks) > 0)
@@ -340,18 +412,18 @@ func (it *messageIterator) sender() {
 			sendPing = !it.po.synchronous
 		}
 		// Lock is held here.
-		var acks, nacks, modAcks map[string]bool
+		var acks, nacks, modAcks map[string]*AckResult
 		if sendAcks {
 			acks = it.pendingAcks
-			it.pendingAcks = map[string]bool{}
+			it.pendingAcks = map[string]*AckResult{}
 		}
 		if sendNacks {
 			nacks = it.pendingNacks
-			it.pendingNacks = map[string]bool{}
+			it.pendingNacks = map[string]*AckResult{}
 		}
 		if sendModAcks {
 			modAcks = it.pendingModAcks
-			it.pendingModAcks = map[string]bool{}
+			it.pendingModAcks = map[string]*AckResult{}
 		}
 		it.mu.Unlock()
 		// Make Ack and ModAck RPCs.
@@ -390,15 +462,59 @@ func (it *messageIterator) handleKeepAlives() {
 			// statements with range clause", note 3, and stated explicitly at
 			// https://groups.google.com/forum/#!msg/golang-nuts/UciASUb03Js/pzSq5iVFAQAJ.
 			delete(it.keepAliveDeadlines, id)
+			delete(it.messages, id)
 		} else {
 			// This will not conflict with a nack, because nacking removes the ID from keepAliveDeadlines.
-			it.pendingModAcks[id] = true
+			it.pendingModAcks[id] = nil
 		}
 	}
 	it.checkDrained()
 }
 
-func (it *messageIterator) sendAck(m map[string]bool) bool {
+// setMaxExtension changes the time until which the ack deadline of the
+// message with ackID is extended, if it is still outstanding.
+func (it *messageIterator) setMaxExtension(ackID string, until time.Time) {
+	it.mu.Lock()
+	defer it.mu.Unlock()
+	if _, ok := it.keepAliveDeadlines[ackID]; ok {
+		it.keepAliveDeadlines[ackID] = until
+	}
+}
+
+// checkLeaseExtensions asks ReceiveSettings.ShouldExtendLease whether to
+// extend the ack deadlines of the outstanding messages by extension, and
+// stops extending those it declines.
+func (it *messageIterator) checkLeaseExtensions(extension time.Duration) {
+	if it.po.shouldExtendLease == nil {
+		return
+	}
+	it.mu.Lock()
+	msgs := make(map[string]*Message, len(it.messages))
+	for id, m := range it.messages {
+		msgs[id] = m
+	}
+	it.mu.Unlock()
+	// Call the function without holding the lock, so that it can ack or
+	// nack messages.
+	var declined []string
+	for id, m := range msgs {
+		if !it.po.shouldExtendLease(m, extension) {
+			declined = append(declined, id)
+		}
+	}
+	if len(declined) == 0 {
+		return
+	}
+	it.mu.Lock()
+	defer it.mu.Unlock()
+	for _, id := range declined {
+		delete(it.keepAliveDeadlines, id)
+		delete(it.messages, id)
+	}
+	it.checkDrained()
+}
+
+func (it *messageIterator) sendAck(m map[string]*AckResult) bool {
 	// Account for the Subscription field.
 	overhead := calcFieldSizeString(it.subName)
 	return it.sendAckIDRPC(m, maxPayload-overhead, func(ids []string) error {
@@ -420,6 +536,18 @@ func (it *messageIterator) sendAck(m map[string]bool) bool {
 				Subscription: it.subName,
 				AckIds:       ids,
 			})
+			if it.exactlyOnce() {
+				// Report the outcome of each ack, retrying transient failures
+				// until the outer context expires.
+				if ids = resolveAckResults(m, ids, err); len(ids) == 0 {
+					return nil
+				}
+				if serr := gax.Sleep(cctx, bo.Pause()); serr != nil {
+					failAckResults(m, ids, err)
+					return nil
+				}
+				continue
+			}
 			// Retry DeadlineExceeded errors a few times before giving up and
 			// allowing the message to expire and be redelivered.
 			// The underlying library handles other retries, currently only
@@ -446,7 +574,7 @@ func (it *messageIterator) sendAck(m map[string]bool) bool {
 // on the time it takes to process messages. The percentile chosen is the 99%th
 // percentile in order to capture the highest amount of time necessary without
 // considering 1% outliers.
-func (it *messageIterator) sendModAck(m map[string]bool, deadline time.Duration) bool {
+func (it *messageIterator) sendModAck(m map[string]*AckResult, deadline time.Duration) bool {
 	deadlineSec := int32(deadline / time.Second)
 	// Account for the Subscription and AckDeadlineSeconds fields.
 	overhead := calcFieldSizeString(it.subName) + calcFieldSizeInt(int(deadlineSec))
@@ -475,6 +603,16 @@ func (it *messageIterator) sendModAck(m map[string]bool, deadline time.Duration)
 				AckDeadlineSeconds: deadlineSec,
 				AckIds:             ids,
 			})
+			if it.exactlyOnce() {
+				if ids = resolveAckResults(m, ids, err); len(ids) == 0 {
+					return nil
+				}
+				if serr := gax.Sleep(cctx, bo.Pause()); serr != nil {
+					failAckResults(m, ids, err)
+					return nil
+				}
+				continue
+			}
 			switch status.Code(err) {
 			case codes.Unavailable:
 				if err := gax.Sleep(cctx, bo.Pause()); err == nil {
@@ -503,7 +641,7 @@ func (it *messageIterator) sendModAck(m map[string]bool, deadline time.Duration)
 	})
 }
 
-func (it *messageIterator) sendAckIDRPC(ackIDSet map[string]bool, maxSize int, call func([]string) error) bool {
+func (it *messageIterator) sendAckIDRPC(ackIDSet map[string]*AckResult, maxSize int, call func([]string) error) bool {
 	ackIDs := make([]string, 0, len(ackIDSet))
 	for k := range ackIDSet {
 		ackIDs = append(ackIDs, k)
@@ -521,6 +659,101 @@ func (it *messageIterator) sendAckIDRPC(ackIDSet map[string]bool, maxSize int, c
 	return true
 }
 
+// exactlyOnce reports whether the subscription has exactly-once delivery
+// enabled.
+func (it *messageIterator) exactlyOnce() bool {
+	it.mu.Lock()
+	defer it.mu.Unlock()
+	return it.enableExactlyOnceDelivery
+}
+
+// Reasons reported in the ErrorInfo of a failed Acknowledge or
+// ModifyAckDeadline call on an exactly-once delivery subscription.
+const (
+	exactlyOnceAckIDFailure   = "EXACTLY_ONCE_ACKID_FAILURE"
+	transientFailurePrefix    = "TRANSIENT_"
+	permanentFailureInvalidID = "PERMANENT_FAILURE_INVALID_ACK_ID"
+)
+
+// resolveAckResults sets the results in m of the ack IDs ids according to
+// err, the error returned by the Acknowledge or ModifyAckDeadline call for
+// them. It returns the IDs that failed transiently and have a result to
+// report, which should be retried.
+func resolveAckResults(m map[string]*AckResult, ids []string, err error) (retry []string) {
+	if err == nil {
+		for _, id := range ids {
+			setAckResult(m[id], AcknowledgeStatusSuccess, nil)
+		}
+		return nil
+	}
+	failures := ackIDFailures(err)
+	for _, id := range ids {
+		r := m[id]
+		if r == nil {
+			continue
+		}
+		if failures != nil {
+			// Only the ack IDs listed in the error failed.
+			reason, ok := failures[id]
+			switch {
+			case !ok:
+				setAckResult(r, AcknowledgeStatusSuccess, nil)
+			case strings.HasPrefix(reason, transientFailurePrefix):
+				retry = append(retry, id)
+			case reason == permanentFailureInvalidID:
+				setAckResult(r, AcknowledgeStatusInvalidAckID, err)
+			default:
+				setAckResult(r, AcknowledgeStatusOther, err)
+			}
+			continue
+		}
+		switch status.Code(err) {
+		case codes.PermissionDenied:
+			setAckResult(r, AcknowledgeStatusPermissionDenied, err)
+		case codes.FailedPrecondition:
+			setAckResult(r, AcknowledgeStatusFailedPrecondition, err)
+		case codes.DeadlineExceeded, codes.Unavailable, codes.Internal, codes.ResourceExhausted, codes.Aborted:
+			retry = append(retry, id)
+		default:
+			setAckResult(r, AcknowledgeStatusOther, err)
+		}
+	}
+	return retry
+}
+
+// failAckResults reports err as the result of the ack IDs ids in m, which
+// could not be retried further.
+func failAckResults(m map[string]*AckResult, ids []string, err error) {
+	if err == nil {
+		err = context.DeadlineExceeded
+	}
+	for _, id := range ids {
+		setAckResult(m[id], AcknowledgeStatusOther, err)
+	}
+}
+
+// ackIDFailures returns the failure reason of each ack ID reported in the
+// details of err, or nil if err has no such details.
+func ackIDFailures(err error) map[string]string {
+	st, ok := status.FromError(err)
+	if !ok {
+		return nil
+	}
+	for _, d := range st.Details() {
+		if info, ok := d.(*errdetails.ErrorInfo); ok && info.GetReason() == exactlyOnceAckIDFailure {
+			return info.GetMetadata()
+		}
+	}
+	return nil
+}
+
+// setAckResult sets r, if it is not nil.
+func setAckResult(r *AckResult, s AcknowledgeStatus, err error) {
+	if r != nil {
+		ipubsub.SetAckResult(r, s, err)
+	}
+}
+
 // Send a message to the stream to keep it open. The stream will close if there's no
 // traffic on it for a while. By keeping it open, we delay the start of the
 // expiration timer on messages that are buffered by gRPC or elsewhere in the
@@ -25,10 +25,12 @@ import (
 	"testing"
 	"time"
 
+	ipubsub "cloud.google.com/go/internal/pubsub"
 	"cloud.google.com/go/internal/testutil"
 	"cloud.google.com/go/pubsub/pstest"
 	"google.golang.org/api/option"
 	pb "google.golang.org/genproto/googleapis/pubsub/v1"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
@@ -537,3 +539,158 @@ func TestAddToDistribution(t *testing.T) {
 		t.Errorf("99th percentile ack distribution got: %v, want %v", deadline, want)
 	}
 }
+
+func TestResolveAckResults(t *testing.T) {
+	newResults := func() map[string]*AckResult {
+		return map[string]*AckResult{
+			"a": ipubsub.NewAckResult(),
+			"b": ipubsub.NewAckResult(),
+			"c": ipubsub.NewAckResult(),
+			"d": nil,
+		}
+	}
+	ids := []string{"a", "b", "c", "d"}
+	st, err := status.New(codes.InvalidArgument, "some ack IDs failed").WithDetails(&errdetails.ErrorInfo{
+		Reason: exactlyOnceAckIDFailure,
+		Metadata: map[string]string{
+			"a": permanentFailureInvalidID,
+			"b": "TRANSIENT_FAILURE_UNORDERED_ACK_ID",
+			"d": "TRANSIENT_FAILURE_UNORDERED_ACK_ID",
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, test := range []struct {
+		desc      string
+		err       error
+		want      map[string]AcknowledgeStatus
+		wantRetry []string
+	}{
+		{
+			desc: "success",
+			want: map[string]AcknowledgeStatus{
+				"a": AcknowledgeStatusSuccess,
+				"b": AcknowledgeStatusSuccess,
+				"c": AcknowledgeStatusSuccess,
+			},
+		},
+		{
+			desc: "per ack ID failures",
+			err:  st.Err(),
+			want: map[string]AcknowledgeStatus{
+				"a": AcknowledgeStatusInvalidAckID,
+				"c": AcknowledgeStatusSuccess,
+			},
+			wantRetry: []string{"b"},
+		},
+		{
+			desc: "permission denied",
+			err:  status.Error(codes.PermissionDenied, ""),
+			want: map[string]AcknowledgeStatus{
+				"a": AcknowledgeStatusPermissionDenied,
+				"b": AcknowledgeStatusPermissionDenied,
+				"c": AcknowledgeStatusPermissionDenied,
+			},
+		},
+		{
+			desc: "failed precondition",
+			err:  status.Error(codes.FailedPrecondition, ""),
+			want: map[string]AcknowledgeStatus{
+				"a": AcknowledgeStatusFailedPrecondition,
+				"b": AcknowledgeStatusFailedPrecondition,
+				"c": AcknowledgeStatusFailedPrecondition,
+			},
+		},
+		{
+			desc:      "unavailable",
+			err:       status.Error(codes.Unavailable, ""),
+			want:      map[string]AcknowledgeStatus{},
+			wantRetry: []string{"a", "b", "c"},
+		},
+	} {
+		m := newResults()
+		retry := resolveAckResults(m, ids, test.err)
+		if diff := testutil.Diff(retry, test.wantRetry); diff != "" {
+			t.Errorf("%s: retry: got=-, want=+:\n%s", test.desc, diff)
+		}
+		for id, r := range m {
+			want, ok := test.want[id]
+			if r == nil || !ok {
+				if r != nil {
+					select {
+					case <-r.Ready():
+						t.Errorf("%s: result for %q is ready, want pending", test.desc, id)
+					default:
+					}
+				}
+				continue
+			}
+			got, err := r.Get(context.Background())
+			if got != want {
+				t.Errorf("%s: status for %q: got %v, want %v", test.desc, id, got, want)
+			}
+			if (err == nil) != (want == AcknowledgeStatusSuccess) {
+				t.Errorf("%s: error for %q: got %v", test.desc, id, err)
+			}
+		}
+	}
+}
+
+func TestIterator_LeaseExtensionControl(t *testing.T) {
+	srv := pstest.NewServer()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	srv.Publish(fullyQualifiedTopicName, []byte("creating a topic"), nil)
+
+	_, client, err := initConn(ctx, srv.Addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var extensions []time.Duration
+	iter := newMessageIterator(client.subc, fullyQualifiedTopicName, &pullOptions{
+		maxExtension: time.Hour,
+		shouldExtendLease: func(m *Message, d time.Duration) bool {
+			extensions = append(extensions, d)
+			return m.ID != "declined"
+		},
+	})
+	defer iter.stop()
+
+	now := time.Now()
+	msgs, err := convertMessages([]*pb.ReceivedMessage{
+		{AckId: "a1", Message: &pb.PubsubMessage{MessageId: "extended"}},
+		{AckId: "a2", Message: &pb.PubsubMessage{MessageId: "declined"}},
+		{AckId: "a3", Message: &pb.PubsubMessage{MessageId: "shortened"}},
+	}, now.Add(-time.Minute), iter.done)
+	if err != nil {
+		t.Fatal(err)
+	}
+	iter.mu.Lock()
+	for _, m := range msgs {
+		ackh, _ := msgAckHandler(m)
+		ackh.setMaxExtFunc = iter.setMaxExtension
+		iter.keepAliveDeadlines[ackh.ackID] = now.Add(time.Hour)
+		iter.messages[ackh.ackID] = m
+	}
+	iter.mu.Unlock()
+
+	// The message was received a minute ago, so this has already expired.
+	msgs[2].SetMaxExtension(30 * time.Second)
+	iter.checkLeaseExtensions(10 * time.Second)
+
+	iter.mu.Lock()
+	defer iter.mu.Unlock()
+	iter.pendingModAcks = map[string]*AckResult{}
+	iter.handleKeepAlives()
+	if _, ok := iter.pendingModAcks["a1"]; !ok || len(iter.pendingModAcks) != 1 {
+		t.Errorf("got modacks for %v, want only a1", iter.pendingModAcks)
+	}
+	if len(extensions) != 3 || extensions[0] != 10*time.Second {
+		t.Errorf("got extensions %v, want 3 of 10s", extensions)
+	}
+	// Let the iterator stop.
+	iter.keepAliveDeadlines = map[string]time.Time{}
+	iter.pendingModAcks = map[string]*AckResult{}
+}
@@ -36,6 +36,31 @@ import (
 // redelivered more quickly than if it were allowed to expire.
 type Message = ipubsub.Message
 
+// AckResult holds the result from a call to Message.AckWithResult or
+// Message.NackWithResult.
+type AckResult = ipubsub.AckResult
+
+// AcknowledgeStatus represents the status of an ack or nack reported by an
+// AckResult.
+type AcknowledgeStatus = ipubsub.AcknowledgeStatus
+
+const (
+	// AcknowledgeStatusSuccess indicates the request was processed
+	// successfully.
+	AcknowledgeStatusSuccess = ipubsub.AcknowledgeStatusSuccess
+	// AcknowledgeStatusPermissionDenied indicates the caller does not have
+	// permission to acknowledge messages of the subscription.
+	AcknowledgeStatusPermissionDenied = ipubsub.AcknowledgeStatusPermissionDenied
+	// AcknowledgeStatusFailedPrecondition indicates the subscription is
+	// detached or cannot be used, for example because of a KMS key issue.
+	AcknowledgeStatusFailedPrecondition = ipubsub.AcknowledgeStatusFailedPrecondition
+	// AcknowledgeStatusInvalidAckID indicates the ack ID has expired or is
+	// otherwise invalid. The message may be redelivered.
+	AcknowledgeStatusInvalidAckID = ipubsub.AcknowledgeStatusInvalidAckID
+	// AcknowledgeStatusOther indicates the request failed for another reason.
+	AcknowledgeStatusOther = ipubsub.AcknowledgeStatusOther
+)
+
 // msgAckHandler performs a safe cast of the message's ack handler to psAckHandler.
 func msgAckHandler(m *Message) (*psAckHandler, bool) {
 	ackh, ok := ipubsub.MessageAckHandler(m).(*psAckHandler)
@@ -50,7 +75,7 @@ func msgAckID(m *Message) string {
 }
 
 // The done method of the iterator that created a Message.
-type iterDoneFunc func(string, bool, time.Time)
+type iterDoneFunc func(string, bool, *AckResult, time.Time)
 
 func convertMessages(rms []*pb.ReceivedMessage, receiveTime time.Time, doneFunc iterDoneFunc) ([]*Message, error) {
 	msgs := make([]*Message, 0, len(rms))
@@ -65,7 +90,7 @@ func convertMessages(rms []*pb.ReceivedMessage, receiveTime time.Time, doneFunc
 }
 
 func toMessage(resp *pb.ReceivedMessage, receiveTime time.Time, doneFunc iterDoneFunc) (*Message, error) {
-	ackh := &psAckHandler{ackID: resp.AckId}
+	ackh := &psAckHandler{ackID: resp.AckId, ackResult: ipubsub.NewAckResult()}
 	msg := ipubsub.NewMessage(ackh)
 	if resp.Message == nil {
 		return msg, nil
@@ -102,6 +127,13 @@ type psAckHandler struct {
 
 	// The done method of the iterator that created this Message.
 	doneFunc iterDoneFunc
+
+	// ackResult is the result of the first ack or nack of this Message.
+	ackResult *AckResult
+
+	// setMaxExtFunc, if not nil, changes the time until which the iterator
+	// that created this Message extends its ack deadline.
+	setMaxExtFunc func(ackID string, until time.Time)
 }
 
 func (ah *psAckHandler) OnAck() {
@@ -112,12 +144,33 @@ func (ah *psAckHandler) OnNack() {
 	ah.done(false)
 }
 
+func (ah *psAckHandler) SetMaxExtension(d time.Duration) {
+	if ah.calledDone || ah.setMaxExtFunc == nil {
+		return
+	}
+	ah.setMaxExtFunc(ah.ackID, ah.receiveTime.Add(d))
+}
+
+func (ah *psAckHandler) OnAckWithResult() *AckResult {
+	ah.done(true)
+	return ah.ackResult
+}
+
+func (ah *psAckHandler) OnNackWithResult() *AckResult {
+	ah.done(false)
+	return ah.ackResult
+}
+
 func (ah *psAckHandler) done(ack bool) {
 	if ah.calledDone {
 		return
 	}
 	ah.calledDone = true
 	if ah.doneFunc != nil {
-		ah.doneFunc(ah.ackID, ack, ah.receiveTime)
+		ah.doneFunc(ah.ackID, ack, ah.ackResult, ah.receiveTime)
+	} else {
+		// There is no iterator to report the result; treat the message as
+		// done from the client's point of view.
+		ipubsub.SetAckResult(ah.ackResult, AcknowledgeStatusSuccess, nil)
 	}
 }
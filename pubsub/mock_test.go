@@ -42,6 +42,7 @@ type mockServer struct {
 	modAckErrs    []error
 	wg            sync.WaitGroup
 	sub           *pb.Subscription
+	exactlyOnce   bool // report exactly-once delivery in streaming pull responses
 }
 
 type pullResponse struct {
@@ -155,6 +156,13 @@ func (s *mockServer) StreamingPull(stream pb.Subscriber_StreamingPullServer) err
 		default:
 		}
 		res := &pb.StreamingPullResponse{ReceivedMessages: pr.msgs}
+		s.mu.Lock()
+		if s.exactlyOnce {
+			res.SubscriptionProperties = &pb.StreamingPullResponse_SubscriptionProperties{
+				ExactlyOnceDeliveryEnabled: true,
+			}
+		}
+		s.mu.Unlock()
 		if err := stream.Send(res); err != nil {
 			return err
 		}
@@ -0,0 +1,157 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pubsub
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// OrderingKeyResumePolicy controls how publishing resumes for an ordering key
+// that was paused because a message with that key failed to be published.
+type OrderingKeyResumePolicy struct {
+	// ResumeAfter is how long to wait after a key is paused before resuming
+	// publishing for it automatically. Messages published for the key in the
+	// meantime fail. If zero, the key is not resumed automatically, and
+	// ResumePublish must be called.
+	ResumeAfter time.Duration
+
+	// OnPause, if not nil, is called with the ordering key and the error that
+	// caused publishing for it to be paused. It is called once per pause, from
+	// the goroutine that observed the error, so it should return quickly.
+	OnPause func(orderingKey string, err error)
+}
+
+// pauseOrderingKey pauses publishing for orderingKey because of err, and
+// applies the topic's OrderingKeyResumePolicy if the key was not already
+// paused.
+func (t *Topic) pauseOrderingKey(orderingKey string, err error) {
+	if !t.scheduler.Pause(orderingKey) {
+		return
+	}
+	p := t.PublishSettings.OrderingKeyResumePolicy
+	if p.OnPause != nil {
+		p.OnPause(orderingKey, err)
+	}
+	if p.ResumeAfter <= 0 {
+		return
+	}
+	t.resumeMu.Lock()
+	defer t.resumeMu.Unlock()
+	if t.resumeTimers == nil {
+		t.resumeTimers = map[string]*time.Timer{}
+	}
+	var timer *time.Timer
+	timer = time.AfterFunc(p.ResumeAfter, func() {
+		t.resumeMu.Lock()
+		// The key may have been resumed, and paused again, since the timer
+		// was started.
+		current := t.resumeTimers[orderingKey] == timer
+		if current {
+			delete(t.resumeTimers, orderingKey)
+		}
+		t.resumeMu.Unlock()
+		if current {
+			t.scheduler.Resume(orderingKey)
+		}
+	})
+	t.resumeTimers[orderingKey] = timer
+}
+
+// stopResumeTimer cancels the automatic resumption of orderingKey.
+func (t *Topic) stopResumeTimer(orderingKey string) {
+	t.resumeMu.Lock()
+	defer t.resumeMu.Unlock()
+	if timer, ok := t.resumeTimers[orderingKey]; ok {
+		timer.Stop()
+		delete(t.resumeTimers, orderingKey)
+	}
+}
+
+// orderingKeyFlowControllers holds a flow controller for each ordering key
+// with outstanding messages.
+type orderingKeyFlowControllers struct {
+	fcs FlowControlSettings
+
+	mu   sync.Mutex
+	keys map[string]*keyFlowController
+}
+
+type keyFlowController struct {
+	fc flowController
+	// refs is the number of messages that hold or are waiting for the
+	// controller. The controller is discarded when it drops to zero.
+	refs int
+}
+
+// newOrderingKeyFlowControllers returns the flow controllers for fcs, or nil
+// if fcs sets no limits.
+func newOrderingKeyFlowControllers(fcs FlowControlSettings) *orderingKeyFlowControllers {
+	if fcs.MaxOutstandingMessages <= 0 && fcs.MaxOutstandingBytes <= 0 {
+		return nil
+	}
+	if fcs.LimitExceededBehavior == FlowControlIgnore {
+		// Limits were set, so enforce them.
+		fcs.LimitExceededBehavior = FlowControlBlock
+	}
+	return &orderingKeyFlowControllers{fcs: fcs, keys: map[string]*keyFlowController{}}
+}
+
+// acquire allocates space for a message of size bytes with orderingKey.
+func (k *orderingKeyFlowControllers) acquire(ctx context.Context, orderingKey string, size int) error {
+	if k == nil || orderingKey == "" {
+		return nil
+	}
+	k.mu.Lock()
+	kfc, ok := k.keys[orderingKey]
+	if !ok {
+		kfc = &keyFlowController{fc: newFlowController(k.fcs)}
+		kfc.fc.purpose = flowControllerPurposeOrderingKey
+		k.keys[orderingKey] = kfc
+	}
+	kfc.refs++
+	k.mu.Unlock()
+	if err := kfc.fc.acquire(ctx, size); err != nil {
+		k.unref(orderingKey, kfc)
+		return err
+	}
+	return nil
+}
+
+// release notes that a message of size bytes with orderingKey is no longer
+// outstanding.
+func (k *orderingKeyFlowControllers) release(ctx context.Context, orderingKey string, size int) {
+	if k == nil || orderingKey == "" {
+		return
+	}
+	k.mu.Lock()
+	kfc, ok := k.keys[orderingKey]
+	k.mu.Unlock()
+	if !ok {
+		return
+	}
+	kfc.fc.release(ctx, size)
+	k.unref(orderingKey, kfc)
+}
+
+func (k *orderingKeyFlowControllers) unref(orderingKey string, kfc *keyFlowController) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	kfc.refs--
+	if kfc.refs == 0 {
+		delete(k.keys, orderingKey)
+	}
+}
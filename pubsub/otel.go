@@ -0,0 +1,176 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pubsub
+
+import (
+	"context"
+	"strings"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	// tracerName is the name of the OpenTelemetry tracer used by this package.
+	tracerName = "cloud.google.com/go/pubsub"
+
+	// traceAttributePrefix is prepended to the names of the message
+	// attributes that carry trace context, so they don't collide with the
+	// attributes set by users.
+	traceAttributePrefix = "googclient_"
+
+	// maxMessageAttributes is the number of attributes a message may have.
+	maxMessageAttributes = 100
+)
+
+// tracePropagator encodes trace context in message attributes, in the W3C
+// Trace Context format.
+var tracePropagator propagation.TextMapPropagator = propagation.TraceContext{}
+
+func tracer() trace.Tracer {
+	return otel.Tracer(tracerName)
+}
+
+// messageCarrier adapts message attributes to propagation.TextMapCarrier.
+type messageCarrier map[string]string
+
+func (c messageCarrier) Get(key string) string {
+	return c[traceAttributePrefix+key]
+}
+
+func (c messageCarrier) Set(key, value string) {
+	c[traceAttributePrefix+key] = value
+}
+
+func (c messageCarrier) Keys() []string {
+	var keys []string
+	for k := range c {
+		if strings.HasPrefix(k, traceAttributePrefix) {
+			keys = append(keys, strings.TrimPrefix(k, traceAttributePrefix))
+		}
+	}
+	return keys
+}
+
+// startCreateSpan starts the span that covers publishing msg to topicID. If
+// the span is valid, it returns a copy of msg whose attributes carry the
+// context of the span, so that subscribers can continue the trace. The span is
+// valid if a TracerProvider is registered, in which case it is a root span if
+// ctx carries none, or else if ctx carries a span, which it then continues.
+// If the trace attributes don't fit in msg, or the span isn't valid, it
+// returns msg.
+func startCreateSpan(ctx context.Context, topicID string, msg *Message) (*Message, trace.Span) {
+	ctx, span := tracer().Start(ctx, topicID+" create",
+		trace.WithSpanKind(trace.SpanKindProducer),
+		trace.WithAttributes(
+			attribute.String("messaging.system", "gcp_pubsub"),
+			attribute.String("messaging.destination.name", topicID),
+			attribute.String("messaging.operation", "create"),
+		))
+	fields := tracePropagator.Fields()
+	if !span.SpanContext().IsValid() || len(msg.Attributes)+len(fields) > maxMessageAttributes {
+		return msg, span
+	}
+	attrs := make(messageCarrier, len(msg.Attributes)+len(fields))
+	for k, v := range msg.Attributes {
+		attrs[k] = v
+	}
+	tracePropagator.Inject(ctx, attrs)
+	return &Message{
+		Data:        msg.Data,
+		Attributes:  attrs,
+		OrderingKey: msg.OrderingKey,
+	}, span
+}
+
+// endCreateSpan ends span, which was returned by startCreateSpan, with the
+// outcome of the publish.
+func endCreateSpan(span trace.Span, id string, err error) {
+	if err != nil {
+		setSpanError(span, err)
+	} else {
+		span.SetAttributes(attribute.String("messaging.message.id", id))
+	}
+	span.End()
+}
+
+// startPublishSpan starts the span for the Publish RPC that sends a bundle of
+// messages. The span is linked to the create spans of the messages.
+func startPublishSpan(ctx context.Context, topicID string, bms []*bundledMessage) (context.Context, trace.Span) {
+	links := make([]trace.Link, 0, len(bms))
+	for _, bm := range bms {
+		if sc := bm.span.SpanContext(); sc.IsValid() {
+			links = append(links, trace.Link{SpanContext: sc})
+		}
+	}
+	return tracer().Start(ctx, topicID+" publish",
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithLinks(links...),
+		trace.WithAttributes(
+			attribute.String("messaging.system", "gcp_pubsub"),
+			attribute.String("messaging.destination.name", topicID),
+			attribute.String("messaging.operation", "publish"),
+			attribute.Int("messaging.batch.message_count", len(bms)),
+		))
+}
+
+// startProcessSpan starts the span for processing msgs received from
+// subscriptionID. A single message's span continues the trace that its
+// publisher propagated. A span for several messages is linked to each of
+// their publishers' spans instead.
+func startProcessSpan(ctx context.Context, subscriptionID string, msgs []*Message) (context.Context, trace.Span) {
+	opts := []trace.SpanStartOption{
+		trace.WithSpanKind(trace.SpanKindConsumer),
+		trace.WithAttributes(
+			attribute.String("messaging.system", "gcp_pubsub"),
+			attribute.String("messaging.source.name", subscriptionID),
+			attribute.String("messaging.operation", "process"),
+		),
+	}
+	if len(msgs) == 1 {
+		m := msgs[0]
+		if sc := extractSpanContext(m); sc.IsValid() {
+			ctx = trace.ContextWithRemoteSpanContext(ctx, sc)
+		}
+		opts = append(opts, trace.WithAttributes(attribute.String("messaging.message.id", m.ID)))
+	} else {
+		links := make([]trace.Link, 0, len(msgs))
+		for _, m := range msgs {
+			if sc := extractSpanContext(m); sc.IsValid() {
+				links = append(links, trace.Link{SpanContext: sc})
+			}
+		}
+		opts = append(opts,
+			trace.WithLinks(links...),
+			trace.WithAttributes(attribute.Int("messaging.batch.message_count", len(msgs))))
+	}
+	return tracer().Start(ctx, subscriptionID+" process", opts...)
+}
+
+// extractSpanContext returns the span context propagated in the attributes
+// of m, which is invalid if there is none.
+func extractSpanContext(m *Message) trace.SpanContext {
+	ctx := tracePropagator.Extract(context.Background(), messageCarrier(m.Attributes))
+	return trace.SpanContextFromContext(ctx)
+}
+
+// setSpanError records that the operation covered by span failed with err.
+func setSpanError(span trace.Span, err error) {
+	span.RecordError(err)
+	span.SetStatus(codes.Error, err.Error())
+}
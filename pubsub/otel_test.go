@@ -0,0 +1,137 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pubsub
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestTraceContextPropagation(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	client, srv := newFake(t)
+	defer client.Close()
+	defer srv.Close()
+
+	topic := mustCreateTopic(t, client, "t")
+	defer topic.Stop()
+	sub, err := client.CreateSubscription(ctx, "s", SubscriptionConfig{Topic: topic})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sc := trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID:    trace.TraceID{1, 2, 3},
+		SpanID:     trace.SpanID{4, 5, 6},
+		TraceFlags: trace.FlagsSampled,
+	})
+	userAttrs := map[string]string{"k": "v"}
+	if _, err := topic.Publish(trace.ContextWithSpanContext(ctx, sc), &Message{Data: []byte("traced"), Attributes: userAttrs}).Get(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := topic.Publish(ctx, &Message{Data: []byte("untraced")}).Get(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if len(userAttrs) != 1 {
+		t.Errorf("Publish modified the message attributes: %v", userAttrs)
+	}
+	for _, m := range srv.Messages() {
+		tp := m.Attributes[traceAttributePrefix+"traceparent"]
+		switch string(m.Data) {
+		case "traced":
+			if !strings.Contains(tp, sc.TraceID().String()) {
+				t.Errorf("traceparent attribute %q does not contain trace ID %s", tp, sc.TraceID())
+			}
+			if m.Attributes["k"] != "v" {
+				t.Errorf("user attribute lost: %v", m.Attributes)
+			}
+		case "untraced":
+			if tp != "" {
+				t.Errorf("untraced message has traceparent attribute %q", tp)
+			}
+		}
+	}
+
+	var mu sync.Mutex
+	got := map[string]trace.SpanContext{}
+	cctx, ccancel := context.WithCancel(ctx)
+	err = sub.Receive(cctx, func(ctx context.Context, m *Message) {
+		mu.Lock()
+		defer mu.Unlock()
+		got[string(m.Data)] = trace.SpanContextFromContext(ctx)
+		m.Ack()
+		if len(got) == 2 {
+			ccancel()
+		}
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if g := got["traced"]; g.TraceID() != sc.TraceID() {
+		t.Errorf("traced message: got trace ID %s in callback, want %s", g.TraceID(), sc.TraceID())
+	}
+	if g := got["untraced"]; g.IsValid() {
+		t.Errorf("untraced message: got span context %v in callback, want none", g)
+	}
+}
+
+func TestMessageCarrier(t *testing.T) {
+	c := messageCarrier{"a": "1"}
+	c.Set("traceparent", "x")
+	if got := c.Get("traceparent"); got != "x" {
+		t.Errorf("Get: got %q, want %q", got, "x")
+	}
+	if got := c[traceAttributePrefix+"traceparent"]; got != "x" {
+		t.Errorf("attribute: got %q, want %q", got, "x")
+	}
+	if keys := c.Keys(); len(keys) != 1 || keys[0] != "traceparent" {
+		t.Errorf("Keys: got %v, want [traceparent]", keys)
+	}
+}
+
+func TestStartCreateSpanAttributeLimit(t *testing.T) {
+	ctx := trace.ContextWithSpanContext(context.Background(), trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID:    trace.TraceID{1},
+		SpanID:     trace.SpanID{2},
+		TraceFlags: trace.FlagsSampled,
+	}))
+	for _, test := range []struct {
+		numAttrs   int
+		wantInject bool
+	}{
+		{maxMessageAttributes - len(tracePropagator.Fields()), true},
+		{maxMessageAttributes - len(tracePropagator.Fields()) + 1, false},
+	} {
+		msg := &Message{Attributes: map[string]string{}}
+		for i := 0; i < test.numAttrs; i++ {
+			msg.Attributes[fmt.Sprint(i)] = "v"
+		}
+		got, span := startCreateSpan(ctx, "t", msg)
+		span.End()
+		if len(got.Attributes) > maxMessageAttributes {
+			t.Errorf("%d attributes: got %d attributes, more than %d", test.numAttrs, len(got.Attributes), maxMessageAttributes)
+		}
+		if injected := got.Attributes[traceAttributePrefix+"traceparent"] != ""; injected != test.wantInject {
+			t.Errorf("%d attributes: got injected %t, want %t", test.numAttrs, injected, test.wantInject)
+		}
+	}
+}
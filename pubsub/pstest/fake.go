@@ -17,6 +17,13 @@
 // differently from the actual service in ways in which the service is
 // non-deterministic or unspecified: timing, delivery order, etc.
 //
+// Subscription filters and exactly-once delivery are supported: messages that
+// don't match a subscription's filter are not delivered to it, and on
+// exactly-once subscriptions each delivery has a new ack ID, and acking or
+// modifying an expired or superseded ack ID fails as it does in the service.
+// Messages published to a topic with a schema are checked with the limited
+// validation described at GServer.ValidateMessage.
+//
 // This package is EXPERIMENTAL and is subject to change without notice.
 //
 // See the example for usage.
@@ -24,6 +31,7 @@ package pstest
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"io"
 	"path"
@@ -35,6 +43,7 @@ import (
 
 	"cloud.google.com/go/internal/testutil"
 	pb "google.golang.org/genproto/googleapis/pubsub/v1"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 	durpb "google.golang.org/protobuf/types/known/durationpb"
@@ -364,6 +373,8 @@ func (s *GServer) UpdateTopic(_ context.Context, req *pb.UpdateTopicRequest) (*p
 				return nil, err
 			}
 			t.proto.MessageRetentionDuration = req.Topic.MessageRetentionDuration
+		case "schema_settings":
+			t.proto.SchemaSettings = req.Topic.SchemaSettings
 		default:
 			return nil, status.Errorf(codes.InvalidArgument, "unknown field name %q", path)
 		}
@@ -471,6 +482,10 @@ func (s *GServer) CreateSubscription(_ context.Context, ps *pb.Subscription) (*p
 	if err := checkAckDeadline(ps.AckDeadlineSeconds); err != nil {
 		return nil, err
 	}
+	filter, err := parseFilter(ps.Filter)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid filter %q: %v", ps.Filter, err)
+	}
 	if ps.MessageRetentionDuration == nil {
 		ps.MessageRetentionDuration = defaultMessageRetentionDuration
 	}
@@ -496,6 +511,7 @@ func (s *GServer) CreateSubscription(_ context.Context, ps *pb.Subscription) (*p
 	}
 
 	sub := newSubscription(top, &s.mu, s.timeNowFunc, deadLetterTopic, ps)
+	sub.filter = filter
 	top.subs[ps.Name] = sub
 	s.subs[ps.Name] = sub
 	sub.start(&s.wg)
@@ -626,7 +642,12 @@ func (s *GServer) UpdateSubscription(_ context.Context, req *pb.UpdateSubscripti
 			sub.proto.RetryPolicy = req.Subscription.RetryPolicy
 
 		case "filter":
+			filter, err := parseFilter(req.Subscription.Filter)
+			if err != nil {
+				return nil, status.Errorf(codes.InvalidArgument, "invalid filter %q: %v", req.Subscription.Filter, err)
+			}
 			sub.proto.Filter = req.Subscription.Filter
+			sub.filter = filter
 
 		case "enable_exactly_once_delivery":
 			sub.proto.EnableExactlyOnceDelivery = req.Subscription.EnableExactlyOnceDelivery
@@ -713,6 +734,17 @@ func (s *GServer) Publish(_ context.Context, req *pb.PublishRequest) (*pb.Publis
 	if top == nil {
 		return nil, status.Errorf(codes.NotFound, "topic %q", req.Topic)
 	}
+	if ss := top.proto.SchemaSettings; ss != nil {
+		sc := s.schemas[ss.Schema]
+		if sc == nil {
+			return nil, status.Errorf(codes.FailedPrecondition, "schema %q of topic %q does not exist", ss.Schema, req.Topic)
+		}
+		for _, pm := range req.Messages {
+			if err := validateMessageData(sc, ss.Encoding, pm.Data); err != nil {
+				return nil, status.Errorf(codes.InvalidArgument, "message does not conform to schema %q: %v", sc.Name, err)
+			}
+		}
+	}
 
 	if !s.autoPublishResponse {
 		r := <-s.publishResponses
@@ -769,6 +801,11 @@ func (t *topic) deleteSub(sub *subscription) {
 
 func (t *topic) publish(pm *pb.PubsubMessage, m *Message) {
 	for _, s := range t.subs {
+		// Messages that don't match a subscription's filter are never
+		// delivered to it.
+		if s.filter != nil && !s.filter(pm.Attributes) {
+			continue
+		}
 		s.msgs[pm.MessageId] = &message{
 			publishTime: m.PublishTime,
 			proto: &pb.ReceivedMessage{
@@ -789,6 +826,8 @@ type subscription struct {
 	proto           *pb.Subscription
 	ackTimeout      time.Duration
 	msgs            map[string]*message // unacked messages by message ID
+	ackIDs          map[string]string   // message IDs by ack ID, for exactly-once delivery
+	filter          filter              // nil if the subscription has no filter
 	streams         []*stream
 	done            chan struct{}
 	timeNowFunc     func() time.Time
@@ -807,6 +846,7 @@ func newSubscription(t *topic, mu *sync.Mutex, timeNowFunc func() time.Time, dea
 		proto:           ps,
 		ackTimeout:      at,
 		msgs:            map[string]*message{},
+		ackIDs:          map[string]string{},
 		done:            make(chan struct{}),
 		timeNowFunc:     timeNowFunc,
 	}
@@ -843,9 +883,18 @@ func (s *GServer) Acknowledge(_ context.Context, req *pb.AcknowledgeRequest) (*e
 	if err != nil {
 		return nil, err
 	}
-	for _, id := range req.AckIds {
+	var invalid []string
+	for _, ackID := range req.AckIds {
+		id, ok := sub.resolveAckID(ackID)
+		if !ok {
+			invalid = append(invalid, ackID)
+			continue
+		}
 		sub.ack(id)
 	}
+	if len(invalid) > 0 {
+		return nil, invalidAckIDsError(invalid)
+	}
 	return &emptypb.Empty{}, nil
 }
 
@@ -862,13 +911,22 @@ func (s *GServer) ModifyAckDeadline(_ context.Context, req *pb.ModifyAckDeadline
 		return nil, err
 	}
 	now := time.Now()
-	for _, id := range req.AckIds {
-		s.msgsByID[id].modacks = append(s.msgsByID[id].modacks, Modack{AckID: id, AckDeadline: req.AckDeadlineSeconds, ReceivedAt: now})
-	}
 	dur := secsToDur(req.AckDeadlineSeconds)
-	for _, id := range req.AckIds {
+	var invalid []string
+	for _, ackID := range req.AckIds {
+		id, ok := sub.resolveAckID(ackID)
+		if !ok {
+			invalid = append(invalid, ackID)
+			continue
+		}
+		if m := s.msgsByID[id]; m != nil {
+			m.modacks = append(m.modacks, Modack{AckID: ackID, AckDeadline: req.AckDeadlineSeconds, ReceivedAt: now})
+		}
 		sub.modifyAckDeadline(id, dur)
 	}
+	if len(invalid) > 0 {
+		return nil, invalidAckIDsError(invalid)
+	}
 	return &emptypb.Empty{}, nil
 }
 
@@ -1018,9 +1076,11 @@ func (s *subscription) pull(max int) []*pb.ReceivedMessage {
 		if s.proto.DeadLetterPolicy != nil {
 			m.proto.DeliveryAttempt = int32(*m.deliveries)
 		}
+		rm := s.deliveryProto(m)
 		(*m.deliveries)++
 		m.ackDeadline = now.Add(s.ackTimeout)
-		msgs = append(msgs, m.proto)
+		s.setAckID(m, rm.AckId)
+		msgs = append(msgs, rm)
 		if len(msgs) >= max {
 			break
 		}
@@ -1074,6 +1134,7 @@ func (s *subscription) deliver() {
 //
 // Must be called with the lock held.
 func (s *subscription) tryDeliverMessage(m *message, start int, now time.Time) (int, bool) {
+	rm := s.deliveryProto(m)
 	for i := 0; i < len(s.streams); i++ {
 		idx := (i + start) % len(s.streams)
 
@@ -1083,9 +1144,10 @@ func (s *subscription) tryDeliverMessage(m *message, start int, now time.Time) (
 			s.streams = deleteStreamAt(s.streams, idx)
 			i--
 
-		case st.msgc <- m.proto:
+		case st.msgc <- rm:
 			(*m.deliveries)++
 			m.ackDeadline = now.Add(st.ackTimeout)
+			s.setAckID(m, rm.GetAckId())
 			return idx, true
 
 		default:
@@ -1108,6 +1170,7 @@ func (s *subscription) maintainMessages(now time.Time) {
 		if !m.outstanding() && now.Sub(pubTime) > retentionDuration {
 			s.publishToDeadLetter(m)
 			delete(s.msgs, id)
+			delete(s.ackIDs, m.ackID)
 		}
 	}
 }
@@ -1178,7 +1241,8 @@ type message struct {
 	ackDeadline time.Time
 	deliveries  *int
 	acks        *int
-	streamIndex int // index of stream that currently owns msg, for round-robin delivery
+	streamIndex int    // index of stream that currently owns msg, for round-robin delivery
+	ackID       string // ack ID of the latest delivery, for exactly-once delivery
 }
 
 // A message is outstanding if it is owned by some stream.
@@ -1240,6 +1304,13 @@ func (st *stream) sendLoop() error {
 			return nil
 		case rm := <-st.msgc:
 			res := &pb.StreamingPullResponse{ReceivedMessages: []*pb.ReceivedMessage{rm}}
+			st.sub.mu.Lock()
+			if st.sub.proto.EnableExactlyOnceDelivery {
+				res.SubscriptionProperties = &pb.StreamingPullResponse_SubscriptionProperties{
+					ExactlyOnceDeliveryEnabled: true,
+				}
+			}
+			st.sub.mu.Unlock()
 			if err := st.gstream.Send(res); err != nil {
 				return err
 			}
@@ -1262,11 +1333,17 @@ func (s *subscription) handleStreamingPullRequest(st *stream, req *pb.StreamingP
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
+	// Invalid ack IDs are ignored, because there is no way to report them
+	// on the stream.
 	for _, ackID := range req.AckIds {
-		s.ack(ackID)
+		if id, ok := s.resolveAckID(ackID); ok {
+			s.ack(id)
+		}
 	}
-	for i, id := range req.ModifyDeadlineAckIds {
-		s.modifyAckDeadline(id, secsToDur(req.ModifyDeadlineSeconds[i]))
+	for i, ackID := range req.ModifyDeadlineAckIds {
+		if id, ok := s.resolveAckID(ackID); ok {
+			s.modifyAckDeadline(id, secsToDur(req.ModifyDeadlineSeconds[i]))
+		}
 	}
 	if req.StreamAckDeadlineSeconds > 0 {
 		st.ackTimeout = secsToDur(req.StreamAckDeadlineSeconds)
@@ -1279,9 +1356,76 @@ func (s *subscription) ack(id string) {
 	if m != nil {
 		(*m.acks)++
 		delete(s.msgs, id)
+		delete(s.ackIDs, m.ackID)
+	}
+}
+
+// deliveryProto returns the ReceivedMessage with which to deliver m. On
+// subscriptions with exactly-once delivery, each delivery of a message has a
+// new ack ID, and the ack IDs of earlier deliveries are no longer valid.
+//
+// Must be called with the lock held.
+func (s *subscription) deliveryProto(m *message) *pb.ReceivedMessage {
+	if !s.proto.EnableExactlyOnceDelivery {
+		return m.proto
+	}
+	return &pb.ReceivedMessage{
+		AckId:           fmt.Sprintf("%s-%d", m.proto.GetMessage().GetMessageId(), *m.deliveries),
+		Message:         m.proto.Message,
+		DeliveryAttempt: m.proto.DeliveryAttempt,
 	}
 }
 
+// setAckID records that m was delivered with ackID.
+//
+// Must be called with the lock held.
+func (s *subscription) setAckID(m *message, ackID string) {
+	if !s.proto.EnableExactlyOnceDelivery {
+		return
+	}
+	delete(s.ackIDs, m.ackID)
+	m.ackID = ackID
+	s.ackIDs[ackID] = m.proto.GetMessage().GetMessageId()
+}
+
+// resolveAckID returns the ID of the message that ackID was issued for. On
+// subscriptions with exactly-once delivery, it reports false unless ackID
+// belongs to the latest delivery of a message whose ack deadline has not
+// expired. Otherwise ack IDs are message IDs, and always valid.
+//
+// Must be called with the lock held.
+func (s *subscription) resolveAckID(ackID string) (string, bool) {
+	if !s.proto.EnableExactlyOnceDelivery {
+		return ackID, true
+	}
+	id, ok := s.ackIDs[ackID]
+	if !ok {
+		return "", false
+	}
+	m := s.msgs[id]
+	if m == nil || m.ackID != ackID || !m.outstanding() || s.timeNowFunc().After(m.ackDeadline) {
+		return "", false
+	}
+	return id, true
+}
+
+// invalidAckIDsError returns the error with which the service reports
+// invalid ack IDs on a subscription with exactly-once delivery.
+func invalidAckIDsError(ackIDs []string) error {
+	md := map[string]string{}
+	for _, id := range ackIDs {
+		md[id] = "PERMANENT_FAILURE_INVALID_ACK_ID"
+	}
+	st, err := status.New(codes.InvalidArgument, "some ack IDs are invalid").WithDetails(&errdetails.ErrorInfo{
+		Reason:   "EXACTLY_ONCE_ACKID_FAILURE",
+		Metadata: md,
+	})
+	if err != nil {
+		return status.Errorf(codes.Internal, "adding error details: %v", err)
+	}
+	return st.Err()
+}
+
 // Must be called with the lock held.
 func (s *subscription) modifyAckDeadline(id string, d time.Duration) {
 	m := s.msgs[id]
@@ -1404,6 +1548,11 @@ func (s *GServer) DeleteSchema(_ context.Context, req *pb.DeleteSchemaRequest) (
 	}
 
 	delete(s.schemas, req.Name)
+	for _, t := range s.topics {
+		if ss := t.proto.SchemaSettings; ss != nil && ss.Schema == req.Name {
+			ss.Schema = "_deleted-schema_"
+		}
+	}
 	return &emptypb.Empty{}, nil
 }
 
@@ -1422,8 +1571,9 @@ func (s *GServer) ValidateSchema(_ context.Context, req *pb.ValidateSchemaReques
 	return &pb.ValidateSchemaResponse{}, nil
 }
 
-// ValidateMessage mocks the ValidateMessage call but only checks that the schema definition to validate the
-// message against is not empty.
+// ValidateMessage mocks the ValidateMessage call. It checks that the schema
+// definition to validate the message against is not empty, and that the message
+// is valid according to validateMessageData.
 func (s *GServer) ValidateMessage(_ context.Context, req *pb.ValidateMessageRequest) (*pb.ValidateMessageResponse, error) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
@@ -1432,21 +1582,59 @@ func (s *GServer) ValidateMessage(_ context.Context, req *pb.ValidateMessageRequ
 		return ret.(*pb.ValidateMessageResponse), err
 	}
 
-	spec := req.GetSchemaSpec()
-	if valReq, ok := spec.(*pb.ValidateMessageRequest_Name); ok {
-		sc, ok := s.schemas[valReq.Name]
+	var sc *pb.Schema
+	switch spec := req.GetSchemaSpec().(type) {
+	case *pb.ValidateMessageRequest_Name:
+		var ok bool
+		sc, ok = s.schemas[spec.Name]
 		if !ok {
-			return nil, status.Errorf(codes.NotFound, "schema(%q) not found", valReq.Name)
+			return nil, status.Errorf(codes.NotFound, "schema(%q) not found", spec.Name)
 		}
+	case *pb.ValidateMessageRequest_Schema:
+		sc = spec.Schema
+	}
+	if sc != nil {
 		if sc.Definition == "" {
 			return nil, status.Error(codes.InvalidArgument, "schema definition cannot be empty")
 		}
-	}
-	if valReq, ok := spec.(*pb.ValidateMessageRequest_Schema); ok {
-		if valReq.Schema.Definition == "" {
-			return nil, status.Error(codes.InvalidArgument, "schema definition cannot be empty")
+		if err := validateMessageData(sc, req.Encoding, req.Message); err != nil {
+			return nil, status.Errorf(codes.InvalidArgument, "message does not conform to schema: %v", err)
 		}
 	}
 
 	return &pb.ValidateMessageResponse{}, nil
 }
+
+// validateMessageData checks that data is a message of schema sc, encoded
+// with enc. The fake does not decode Avro or protocol buffers: it only checks
+// that JSON-encoded messages are JSON objects and, for Avro record schemas,
+// that they have every field without a default value. Binary-encoded messages
+// are not checked.
+func validateMessageData(sc *pb.Schema, enc pb.Encoding, data []byte) error {
+	if enc != pb.Encoding_JSON {
+		return nil
+	}
+	var obj map[string]json.RawMessage
+	if err := json.Unmarshal(data, &obj); err != nil {
+		return fmt.Errorf("invalid JSON object: %v", err)
+	}
+	if sc.Type != pb.Schema_AVRO {
+		return nil
+	}
+	var record struct {
+		Type   string
+		Fields []struct {
+			Name    string
+			Default json.RawMessage
+		}
+	}
+	if err := json.Unmarshal([]byte(sc.Definition), &record); err != nil || record.Type != "record" {
+		return nil
+	}
+	for _, f := range record.Fields {
+		if _, ok := obj[f.Name]; !ok && f.Default == nil {
+			return fmt.Errorf("missing field %q", f.Name)
+		}
+	}
+	return nil
+}
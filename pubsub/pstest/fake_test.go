@@ -29,6 +29,7 @@ import (
 
 	"cloud.google.com/go/internal/testutil"
 	pb "google.golang.org/genproto/googleapis/pubsub/v1"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
 	"google.golang.org/genproto/protobuf/field_mask"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
@@ -1054,14 +1055,14 @@ func TestUpdateFilter(t *testing.T) {
 		AckDeadlineSeconds: minAckDeadlineSecs,
 		Name:               "projects/P/subscriptions/S",
 		Topic:              top.Name,
-		Filter:             "some-filter",
+		Filter:             `attributes.lang = "en"`,
 	})
 
 	update := &pb.Subscription{
 		AckDeadlineSeconds: sub.AckDeadlineSeconds,
 		Name:               sub.Name,
 		Topic:              top.Name,
-		Filter:             "new-filter",
+		Filter:             `attributes:lang`,
 	}
 
 	updated := mustUpdateSubscription(ctx, t, sclient, &pb.UpdateSubscriptionRequest{
@@ -1103,6 +1104,187 @@ func TestUpdateEnableExactlyOnceDelivery(t *testing.T) {
 	}
 }
 
+func TestSubscriptionFilter(t *testing.T) {
+	ctx := context.Background()
+	pclient, sclient, _, cleanup := newFake(ctx, t)
+	defer cleanup()
+
+	top := mustCreateTopic(ctx, t, pclient, &pb.Topic{Name: "projects/P/topics/T"})
+	if _, err := sclient.CreateSubscription(ctx, &pb.Subscription{
+		Name:               "projects/P/subscriptions/bad",
+		Topic:              top.Name,
+		AckDeadlineSeconds: 10,
+		Filter:             "some-filter",
+	}); status.Code(err) != codes.InvalidArgument {
+		t.Fatalf("got %v, want InvalidArgument", err)
+	}
+	sub := mustCreateSubscription(ctx, t, sclient, &pb.Subscription{
+		Name:               "projects/P/subscriptions/S",
+		Topic:              top.Name,
+		AckDeadlineSeconds: 10,
+		Filter:             `attributes.lang = "en"`,
+	})
+
+	publish(t, pclient, top, []*pb.PubsubMessage{
+		{Data: []byte("d1"), Attributes: map[string]string{"lang": "fr"}},
+		{Data: []byte("d2"), Attributes: map[string]string{"lang": "en"}},
+		{Data: []byte("d3")},
+	})
+	res, err := sclient.Pull(ctx, &pb.PullRequest{Subscription: sub.Name, MaxMessages: 10})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := len(res.ReceivedMessages), 1; got != want {
+		t.Fatalf("got %d messages, want %d", got, want)
+	}
+	if got, want := string(res.ReceivedMessages[0].Message.Data), "d2"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestExactlyOnceDelivery(t *testing.T) {
+	ctx := context.Background()
+	pclient, sclient, srv, cleanup := newFake(ctx, t)
+	defer cleanup()
+
+	top := mustCreateTopic(ctx, t, pclient, &pb.Topic{Name: "projects/P/topics/T"})
+	sub := mustCreateSubscription(ctx, t, sclient, &pb.Subscription{
+		Name:                      "projects/P/subscriptions/S",
+		Topic:                     top.Name,
+		AckDeadlineSeconds:        10,
+		EnableExactlyOnceDelivery: true,
+	})
+	publish(t, pclient, top, []*pb.PubsubMessage{{Data: []byte("d1")}})
+
+	spc := mustStartStreamingPull(ctx, t, sclient, sub)
+	res, err := spc.Recv()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !res.GetSubscriptionProperties().GetExactlyOnceDeliveryEnabled() {
+		t.Error("subscription properties do not report exactly-once delivery")
+	}
+	if err := spc.CloseSend(); err != nil {
+		t.Fatal(err)
+	}
+	first := res.ReceivedMessages[0].AckId
+
+	// Nack the message; it is redelivered with a new ack ID.
+	if _, err := sclient.ModifyAckDeadline(ctx, &pb.ModifyAckDeadlineRequest{
+		Subscription: sub.Name,
+		AckIds:       []string{first},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	res2, err := sclient.Pull(ctx, &pb.PullRequest{Subscription: sub.Name, MaxMessages: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := len(res2.ReceivedMessages); got != 1 {
+		t.Fatalf("got %d messages, want 1", got)
+	}
+	second := res2.ReceivedMessages[0].AckId
+	if second == first {
+		t.Fatalf("redelivery has the same ack ID %q", first)
+	}
+
+	// The ack ID of the first delivery is no longer valid.
+	_, err = sclient.Acknowledge(ctx, &pb.AcknowledgeRequest{
+		Subscription: sub.Name,
+		AckIds:       []string{first, second},
+	})
+	st, _ := status.FromError(err)
+	if st.Code() != codes.InvalidArgument {
+		t.Fatalf("got %v, want InvalidArgument", err)
+	}
+	var info *errdetails.ErrorInfo
+	for _, d := range st.Details() {
+		if i, ok := d.(*errdetails.ErrorInfo); ok {
+			info = i
+		}
+	}
+	want := map[string]string{first: "PERMANENT_FAILURE_INVALID_ACK_ID"}
+	if info == nil || info.Reason != "EXACTLY_ONCE_ACKID_FAILURE" || !reflect.DeepEqual(info.Metadata, want) {
+		t.Fatalf("got error details %v, want metadata %v", info, want)
+	}
+	// The valid ack ID was acknowledged, and acking it again fails.
+	if got := srv.Messages()[0].Acks; got != 1 {
+		t.Errorf("got %d acks, want 1", got)
+	}
+	if _, err := sclient.Acknowledge(ctx, &pb.AcknowledgeRequest{
+		Subscription: sub.Name,
+		AckIds:       []string{second},
+	}); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("got %v, want InvalidArgument", err)
+	}
+}
+
+func TestPublishSchemaValidation(t *testing.T) {
+	ctx := context.Background()
+	pclient, _, srv, cleanup := newFake(ctx, t)
+	defer cleanup()
+
+	conn, err := grpc.DialContext(ctx, srv.Addr, grpc.WithInsecure())
+	if err != nil {
+		t.Fatal(err)
+	}
+	schc := pb.NewSchemaServiceClient(conn)
+	sc, err := schc.CreateSchema(ctx, &pb.CreateSchemaRequest{
+		Parent:   "projects/P",
+		SchemaId: "S",
+		Schema: &pb.Schema{
+			Type:       pb.Schema_AVRO,
+			Definition: `{"type": "record", "name": "R", "fields": [{"name": "a", "type": "string"}, {"name": "b", "type": "int", "default": 0}]}`,
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	top := mustCreateTopic(ctx, t, pclient, &pb.Topic{
+		Name:           "projects/P/topics/T",
+		SchemaSettings: &pb.SchemaSettings{Schema: sc.Name, Encoding: pb.Encoding_JSON},
+	})
+
+	for _, test := range []struct {
+		data string
+		want codes.Code
+	}{
+		{`{"a": "x"}`, codes.OK},
+		{`{"a": "x", "b": 1}`, codes.OK},
+		{`{"b": 1}`, codes.InvalidArgument},
+		{`not json`, codes.InvalidArgument},
+	} {
+		_, err := pclient.Publish(ctx, &pb.PublishRequest{
+			Topic:    top.Name,
+			Messages: []*pb.PubsubMessage{{Data: []byte(test.data)}},
+		})
+		if got := status.Code(err); got != test.want {
+			t.Errorf("%s: got %v, want %v", test.data, err, test.want)
+		}
+		_, err = schc.ValidateMessage(ctx, &pb.ValidateMessageRequest{
+			Parent:     "projects/P",
+			SchemaSpec: &pb.ValidateMessageRequest_Name{Name: sc.Name},
+			Message:    []byte(test.data),
+			Encoding:   pb.Encoding_JSON,
+		})
+		if got := status.Code(err); got != test.want {
+			t.Errorf("ValidateMessage(%s): got %v, want %v", test.data, err, test.want)
+		}
+	}
+
+	// Once the schema is deleted, the topic can no longer be published to.
+	if _, err := schc.DeleteSchema(ctx, &pb.DeleteSchemaRequest{Name: sc.Name}); err != nil {
+		t.Fatal(err)
+	}
+	_, err = pclient.Publish(ctx, &pb.PublishRequest{
+		Topic:    top.Name,
+		Messages: []*pb.PubsubMessage{{Data: []byte(`{"a": "x"}`)}},
+	})
+	if status.Code(err) != codes.FailedPrecondition {
+		t.Errorf("got %v, want FailedPrecondition", err)
+	}
+}
+
 // Test Create, Get, List, and Delete methods for schema client.
 // Updating a schema is not available at this moment.
 func TestSchemaAdminClient(t *testing.T) {
@@ -0,0 +1,295 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pstest
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// A filter reports whether a message with the given attributes matches a
+// subscription filter.
+type filter func(attrs map[string]string) bool
+
+// parseFilter parses a subscription filter. It supports the same language as
+// the service, described at
+// https://cloud.google.com/pubsub/docs/filtering:
+//
+//	attributes:key                  the message has the attribute
+//	attributes.key = "value"        the attribute has the value
+//	attributes.key != "value"       the attribute is missing or has another value
+//	hasPrefix(attributes.key, "v")  the attribute starts with "v"
+//
+// Expressions can be negated with NOT or -, combined with AND and OR, and
+// grouped with parentheses. As in the service, AND and OR cannot be mixed
+// without parentheses. The empty filter matches every message.
+func parseFilter(s string) (filter, error) {
+	if strings.TrimSpace(s) == "" {
+		return func(map[string]string) bool { return true }, nil
+	}
+	toks, err := tokenizeFilter(s)
+	if err != nil {
+		return nil, err
+	}
+	p := &filterParser{toks: toks}
+	f, err := p.parseExpr()
+	if err != nil {
+		return nil, err
+	}
+	if !p.done() {
+		return nil, fmt.Errorf("unexpected %q", p.peek().text)
+	}
+	return f, nil
+}
+
+type filterTokenKind int
+
+const (
+	tokWord   filterTokenKind = iota // identifier or keyword
+	tokString                        // quoted string, unquoted
+	tokPunct                         // one of . : ( ) , - = !=
+)
+
+type filterToken struct {
+	kind filterTokenKind
+	text string
+}
+
+func tokenizeFilter(s string) ([]filterToken, error) {
+	var toks []filterToken
+	for i := 0; i < len(s); {
+		c := s[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			i++
+		case c == '"' || c == '\'':
+			j := i + 1
+			for j < len(s) && s[j] != c {
+				if s[j] == '\\' {
+					j++
+				}
+				j++
+			}
+			if j >= len(s) {
+				return nil, fmt.Errorf("unterminated string at offset %d", i)
+			}
+			lit := s[i : j+1]
+			if c == '\'' {
+				lit = `"` + strings.ReplaceAll(s[i+1:j], `"`, `\"`) + `"`
+			}
+			v, err := strconv.Unquote(lit)
+			if err != nil {
+				return nil, fmt.Errorf("bad string %s: %v", s[i:j+1], err)
+			}
+			toks = append(toks, filterToken{tokString, v})
+			i = j + 1
+		case c == '!' && i+1 < len(s) && s[i+1] == '=':
+			toks = append(toks, filterToken{tokPunct, "!="})
+			i += 2
+		case strings.IndexByte(".:(),-=", c) >= 0:
+			toks = append(toks, filterToken{tokPunct, string(c)})
+			i++
+		case isFilterWordChar(rune(c)):
+			j := i
+			for j < len(s) && isFilterWordChar(rune(s[j])) {
+				j++
+			}
+			toks = append(toks, filterToken{tokWord, s[i:j]})
+			i = j
+		default:
+			return nil, fmt.Errorf("unexpected character %q at offset %d", c, i)
+		}
+	}
+	return toks, nil
+}
+
+func isFilterWordChar(r rune) bool {
+	return r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+type filterParser struct {
+	toks []filterToken
+	pos  int
+}
+
+func (p *filterParser) done() bool { return p.pos >= len(p.toks) }
+
+func (p *filterParser) peek() filterToken {
+	if p.done() {
+		return filterToken{}
+	}
+	return p.toks[p.pos]
+}
+
+func (p *filterParser) next() filterToken {
+	t := p.peek()
+	p.pos++
+	return t
+}
+
+// is reports whether the next token is a punctuation or keyword token with
+// the given text.
+func (p *filterParser) is(text string) bool {
+	t := p.peek()
+	return !p.done() && t.kind != tokString && t.text == text
+}
+
+func (p *filterParser) expect(text string) error {
+	if !p.is(text) {
+		if p.done() {
+			return fmt.Errorf("expected %q, got end of filter", text)
+		}
+		return fmt.Errorf("expected %q, got %q", text, p.peek().text)
+	}
+	p.pos++
+	return nil
+}
+
+// parseExpr parses a sequence of terms joined by AND, or by OR.
+func (p *filterParser) parseExpr() (filter, error) {
+	f, err := p.parseTerm()
+	if err != nil {
+		return nil, err
+	}
+	op := ""
+	for p.is("AND") || p.is("OR") {
+		o := p.next().text
+		if op != "" && o != op {
+			return nil, fmt.Errorf("AND and OR must be separated by parentheses")
+		}
+		op = o
+		g, err := p.parseTerm()
+		if err != nil {
+			return nil, err
+		}
+		f = combineFilters(op, f, g)
+	}
+	return f, nil
+}
+
+func combineFilters(op string, f, g filter) filter {
+	if op == "AND" {
+		return func(attrs map[string]string) bool { return f(attrs) && g(attrs) }
+	}
+	return func(attrs map[string]string) bool { return f(attrs) || g(attrs) }
+}
+
+// parseTerm parses a possibly negated basic expression or parenthesized
+// expression.
+func (p *filterParser) parseTerm() (filter, error) {
+	if p.is("NOT") || p.is("-") {
+		p.next()
+		f, err := p.parseTerm()
+		if err != nil {
+			return nil, err
+		}
+		return func(attrs map[string]string) bool { return !f(attrs) }, nil
+	}
+	if p.is("(") {
+		p.next()
+		f, err := p.parseExpr()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expect(")"); err != nil {
+			return nil, err
+		}
+		return f, nil
+	}
+	if p.is("hasPrefix") {
+		p.next()
+		if err := p.expect("("); err != nil {
+			return nil, err
+		}
+		if err := p.expect("attributes"); err != nil {
+			return nil, err
+		}
+		if err := p.expect("."); err != nil {
+			return nil, err
+		}
+		key, err := p.parseValue()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expect(","); err != nil {
+			return nil, err
+		}
+		prefix, err := p.parseString()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expect(")"); err != nil {
+			return nil, err
+		}
+		return func(attrs map[string]string) bool {
+			v, ok := attrs[key]
+			return ok && strings.HasPrefix(v, prefix)
+		}, nil
+	}
+	if err := p.expect("attributes"); err != nil {
+		return nil, err
+	}
+	switch {
+	case p.is(":"):
+		p.next()
+		key, err := p.parseValue()
+		if err != nil {
+			return nil, err
+		}
+		return func(attrs map[string]string) bool {
+			_, ok := attrs[key]
+			return ok
+		}, nil
+	case p.is("."):
+		p.next()
+		key, err := p.parseValue()
+		if err != nil {
+			return nil, err
+		}
+		if !p.is("=") && !p.is("!=") {
+			return nil, fmt.Errorf("expected = or != after attributes.%s", key)
+		}
+		op := p.next().text
+		want, err := p.parseString()
+		if err != nil {
+			return nil, err
+		}
+		return func(attrs map[string]string) bool {
+			v, ok := attrs[key]
+			return (ok && v == want) == (op == "=")
+		}, nil
+	default:
+		return nil, fmt.Errorf("expected : or . after attributes")
+	}
+}
+
+// parseValue parses an attribute key, which may be quoted.
+func (p *filterParser) parseValue() (string, error) {
+	t := p.next()
+	if t.kind == tokPunct || t.text == "" && t.kind != tokString {
+		return "", fmt.Errorf("expected attribute key, got %q", t.text)
+	}
+	return t.text, nil
+}
+
+func (p *filterParser) parseString() (string, error) {
+	t := p.next()
+	if t.kind != tokString {
+		return "", fmt.Errorf("expected quoted string, got %q", t.text)
+	}
+	return t.text, nil
+}
@@ -0,0 +1,71 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pstest
+
+import "testing"
+
+func TestParseFilter(t *testing.T) {
+	attrs := map[string]string{"lang": "en", "name": "com.google.x", "empty": ""}
+	for _, test := range []struct {
+		filter string
+		want   bool
+	}{
+		{``, true},
+		{`attributes:lang`, true},
+		{`attributes:other`, false},
+		{`attributes:"lang"`, true},
+		{`attributes.lang = "en"`, true},
+		{`attributes.lang = 'en'`, true},
+		{`attributes.lang = "fr"`, false},
+		{`attributes.lang != "fr"`, true},
+		{`attributes.other != "fr"`, true},
+		{`attributes.empty = ""`, true},
+		{`hasPrefix(attributes.name, "com.google.")`, true},
+		{`hasPrefix(attributes.other, "")`, false},
+		{`NOT attributes:lang`, false},
+		{`-attributes:other`, true},
+		{`attributes:lang AND attributes.lang = "en" AND attributes:name`, true},
+		{`attributes:other OR attributes.lang = "fr"`, false},
+		{`attributes:other OR (attributes:lang AND NOT attributes.lang = "fr")`, true},
+	} {
+		f, err := parseFilter(test.filter)
+		if err != nil {
+			t.Errorf("%s: %v", test.filter, err)
+			continue
+		}
+		if got := f(attrs); got != test.want {
+			t.Errorf("%s: got %t, want %t", test.filter, got, test.want)
+		}
+	}
+}
+
+func TestParseFilterErrors(t *testing.T) {
+	for _, filter := range []string{
+		`some-filter`,
+		`attributes`,
+		`attributes.lang`,
+		`attributes.lang = en`,
+		`attributes.lang = "en`,
+		`attributes:a AND attributes:b OR attributes:c`,
+		`(attributes:a`,
+		`attributes:a)`,
+		`hasPrefix(attributes.a)`,
+		`data = "x"`,
+	} {
+		if _, err := parseFilter(filter); err == nil {
+			t.Errorf("%s: got nil, want error", filter)
+		}
+	}
+}
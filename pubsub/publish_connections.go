@@ -0,0 +1,58 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pubsub
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+
+	vkit "cloud.google.com/go/pubsub/apiv1"
+	"cloud.google.com/go/pubsub/internal"
+	"google.golang.org/api/option"
+)
+
+// newKeyPublisherClients returns config.OrderingKeyConnections publisher
+// clients, each with a single connection, created with opts.
+func newKeyPublisherClients(ctx context.Context, config *ClientConfig, opts []option.ClientOption) ([]*vkit.PublisherClient, error) {
+	// A later option overrides the connection pool size set by
+	// NewClientWithConfig or the user.
+	opts = append(opts[:len(opts):len(opts)], option.WithGRPCConnectionPool(1))
+	var pcs []*vkit.PublisherClient
+	for i := 0; i < config.OrderingKeyConnections; i++ {
+		pc, err := vkit.NewPublisherClient(ctx, opts...)
+		if err != nil {
+			for _, pc := range pcs {
+				pc.Close()
+			}
+			return nil, fmt.Errorf("pubsub(publisher): %v", err)
+		}
+		pc.CallOptions = mergePublisherCallOptions(pc.CallOptions, config.PublisherCallOptions)
+		pc.SetGoogleClientInfo("gccl", internal.Version)
+		pcs = append(pcs, pc)
+	}
+	return pcs, nil
+}
+
+// publisherClient returns the client with which to publish messages with
+// orderingKey.
+func (c *Client) publisherClient(orderingKey string) *vkit.PublisherClient {
+	if orderingKey == "" || len(c.keyPubcs) == 0 {
+		return c.pubc
+	}
+	h := fnv.New32a()
+	h.Write([]byte(orderingKey))
+	return c.keyPubcs[h.Sum32()%uint32(len(c.keyPubcs))]
+}
@@ -0,0 +1,123 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pubsub
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"cloud.google.com/go/pubsub/pstest"
+	"google.golang.org/api/option"
+	"google.golang.org/grpc"
+)
+
+func TestOrderingKeyConnections(t *testing.T) {
+	ctx := context.Background()
+	srv := pstest.NewServer()
+	defer srv.Close()
+	client, err := NewClientWithConfig(ctx, "P", &ClientConfig{OrderingKeyConnections: 3},
+		option.WithEndpoint(srv.Addr),
+		option.WithoutAuthentication(),
+		option.WithGRPCDialOption(grpc.WithInsecure()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+
+	if got, want := len(client.keyPubcs), 3; got != want {
+		t.Fatalf("got %d key publisher clients, want %d", got, want)
+	}
+	if got := client.publisherClient(""); got != client.pubc {
+		t.Error("messages without an ordering key do not use the default publisher client")
+	}
+	used := map[interface{}]bool{}
+	for i := 0; i < 20; i++ {
+		key := fmt.Sprintf("key-%d", i)
+		pc := client.publisherClient(key)
+		if pc != client.publisherClient(key) {
+			t.Errorf("%s: publisher client is not deterministic", key)
+		}
+		used[pc] = true
+	}
+	if len(used) < 2 {
+		t.Errorf("ordering keys used %d publisher clients, want them spread across several", len(used))
+	}
+
+	topic := mustCreateTopic(t, client, "t")
+	topic.EnableMessageOrdering = true
+	defer topic.Stop()
+	var results []*PublishResult
+	for i := 0; i < 10; i++ {
+		results = append(results, topic.Publish(ctx, &Message{
+			Data:        []byte(fmt.Sprint(i)),
+			OrderingKey: fmt.Sprintf("key-%d", i%3),
+		}))
+	}
+	for _, r := range results {
+		if _, err := r.Get(ctx); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// Messages of each key were published in order.
+	last := map[string]int{}
+	for _, m := range srv.Messages() {
+		var n int
+		fmt.Sscan(string(m.Data), &n)
+		if l, ok := last[m.OrderingKey]; ok && n < l {
+			t.Errorf("key %s: message %d published after %d", m.OrderingKey, n, l)
+		}
+		last[m.OrderingKey] = n
+	}
+	if got := len(srv.Messages()); got != 10 {
+		t.Errorf("got %d messages, want 10", got)
+	}
+}
+
+func TestOrderingKeyConnectionsClose(t *testing.T) {
+	ctx := context.Background()
+	srv := pstest.NewServer()
+	defer srv.Close()
+	config := &ClientConfig{OrderingKeyConnections: 2}
+
+	client, err := NewClientWithConfig(ctx, "P", config,
+		option.WithEndpoint(srv.Addr),
+		option.WithoutAuthentication(),
+		option.WithGRPCDialOption(grpc.WithInsecure()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if client.sharedConn {
+		t.Error("got a shared connection, want one per client")
+	}
+	if err := client.Close(); err != nil {
+		t.Errorf("closing client with separate connections: %v", err)
+	}
+
+	conn, err := grpc.Dial(srv.Addr, grpc.WithInsecure())
+	if err != nil {
+		t.Fatal(err)
+	}
+	client, err = NewClientWithConfig(ctx, "P", config, option.WithGRPCConn(conn))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !client.sharedConn {
+		t.Error("got separate connections, want the one passed with WithGRPCConn")
+	}
+	if err := client.Close(); err != nil {
+		t.Errorf("closing client with a shared connection: %v", err)
+	}
+}
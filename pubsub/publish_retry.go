@@ -0,0 +1,124 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pubsub
+
+import (
+	"context"
+	"time"
+
+	gax "github.com/googleapis/gax-go/v2"
+	"google.golang.org/grpc/codes"
+)
+
+// PublishRetrySettings control how failed publish requests are retried.
+type PublishRetrySettings struct {
+	// Codes are the gRPC status codes of the errors that are retried. If
+	// nil, the codes that are retried by default are used: Aborted,
+	// Canceled, Internal, ResourceExhausted, Unknown, Unavailable and
+	// DeadlineExceeded. To disable retries, set Codes to an empty, non-nil
+	// slice.
+	Codes []codes.Code
+
+	// Backoff controls the pauses between attempts. If zero, the default
+	// backoff is used, which starts at 100 milliseconds and grows by a
+	// factor of 1.3 up to one minute.
+	Backoff gax.Backoff
+}
+
+var defaultPublishRetryCodes = []codes.Code{
+	codes.Aborted,
+	codes.Canceled,
+	codes.Internal,
+	codes.ResourceExhausted,
+	codes.Unknown,
+	codes.Unavailable,
+	codes.DeadlineExceeded,
+}
+
+var defaultPublishBackoff = gax.Backoff{
+	Initial:    100 * time.Millisecond,
+	Max:        60 * time.Second,
+	Multiplier: 1.30,
+}
+
+func (rs *PublishRetrySettings) retryer() gax.Retryer {
+	c := rs.Codes
+	if c == nil {
+		c = defaultPublishRetryCodes
+	}
+	b := rs.Backoff
+	if b == (gax.Backoff{}) {
+		b = defaultPublishBackoff
+	}
+	return gax.OnCodes(c, b)
+}
+
+// PublishOverrides override the topic's PublishSettings for individual calls
+// to Publish. See WithPublishOverrides.
+type PublishOverrides struct {
+	// Timeout bounds the time spent publishing the message, starting from
+	// the call to Publish and including the time the message waits to be
+	// batched. If zero, only the topic's PublishSettings.Timeout applies.
+	Timeout time.Duration
+
+	// Retry, if not nil, replaces the topic's PublishSettings.Retry.
+	Retry *PublishRetrySettings
+}
+
+type publishOverridesKey struct{}
+
+// WithPublishOverrides returns a context that makes Topic.Publish apply o to
+// the messages published with it.
+//
+// Messages are published in batches, and a batch is sent with a single
+// request. When messages with different overrides are in the same batch, the
+// request is bounded by the earliest of their deadlines, and retried
+// according to the Retry settings of the first message that has them. To
+// keep latency-sensitive messages from being held back by others, publish
+// them to a separate Topic value.
+func WithPublishOverrides(ctx context.Context, o PublishOverrides) context.Context {
+	return context.WithValue(ctx, publishOverridesKey{}, o)
+}
+
+// applyPublishOverrides sets the deadline and retry settings of bm from the
+// overrides in ctx, if any.
+func applyPublishOverrides(ctx context.Context, bm *bundledMessage) {
+	o, ok := ctx.Value(publishOverridesKey{}).(PublishOverrides)
+	if !ok {
+		return
+	}
+	if o.Timeout > 0 {
+		bm.deadline = time.Now().Add(o.Timeout)
+	}
+	bm.retry = o.Retry
+}
+
+// bundleCallSettings returns the deadline and retry settings for publishing
+// bms: the earliest deadline of the messages, if any, and the first retry
+// settings of the messages, or of the topic.
+func (t *Topic) bundleCallSettings(bms []*bundledMessage) (deadline time.Time, retry *PublishRetrySettings) {
+	for _, bm := range bms {
+		if !bm.deadline.IsZero() && (deadline.IsZero() || bm.deadline.Before(deadline)) {
+			deadline = bm.deadline
+		}
+		if retry == nil {
+			retry = bm.retry
+		}
+	}
+	if retry == nil {
+		retry = t.PublishSettings.Retry
+	}
+	return deadline, retry
+}
@@ -0,0 +1,107 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pubsub
+
+import (
+	"sort"
+	"sync"
+)
+
+// PublisherState describes the messages that a Topic is publishing. It is
+// returned by Topic.PublisherState.
+type PublisherState struct {
+	// OutstandingMessages is the number of messages that were accepted by
+	// Publish and whose results are not yet ready.
+	OutstandingMessages int
+
+	// OutstandingBytes is the total size of the outstanding messages.
+	OutstandingBytes int
+
+	// OrderingKeys maps each ordering key that has outstanding messages to
+	// the number of its outstanding messages. Messages without an ordering
+	// key are not included.
+	OrderingKeys map[string]int
+
+	// PausedOrderingKeys lists, in sorted order, the ordering keys for which
+	// publishing is paused because of an earlier error. See
+	// Topic.ResumePublish.
+	PausedOrderingKeys []string
+
+	// FlowControl holds the flow control settings in effect for the topic:
+	// its PublishSettings.FlowControlSettings, with defaults for the limits
+	// that are not set. A negative or zero limit means there is no limit.
+	// Comparing the limits with OutstandingMessages and OutstandingBytes
+	// tells how close the topic is to blocking or rejecting messages.
+	FlowControl FlowControlSettings
+}
+
+// PublisherState returns a snapshot of the topic's outstanding messages and
+// flow control, for use in health checks or to shed load before the topic's
+// flow control limits are reached.
+func (t *Topic) PublisherState() PublisherState {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	ps := PublisherState{FlowControl: t.flowControlSettings()}
+	ps.OutstandingMessages, ps.OutstandingBytes, ps.OrderingKeys = t.outstanding.snapshot()
+	if t.scheduler != nil {
+		ps.PausedOrderingKeys = t.scheduler.PausedKeys()
+		sort.Strings(ps.PausedOrderingKeys)
+	}
+	return ps
+}
+
+// outstandingMessages counts the messages being published by a topic.
+type outstandingMessages struct {
+	mu       sync.Mutex
+	messages int
+	bytes    int
+	keys     map[string]int // outstanding messages by ordering key
+}
+
+func (o *outstandingMessages) add(orderingKey string, size int) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.messages++
+	o.bytes += size
+	if orderingKey != "" {
+		if o.keys == nil {
+			o.keys = map[string]int{}
+		}
+		o.keys[orderingKey]++
+	}
+}
+
+func (o *outstandingMessages) remove(orderingKey string, size int) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.messages--
+	o.bytes -= size
+	if orderingKey != "" {
+		o.keys[orderingKey]--
+		if o.keys[orderingKey] <= 0 {
+			delete(o.keys, orderingKey)
+		}
+	}
+}
+
+func (o *outstandingMessages) snapshot() (messages, bytes int, keys map[string]int) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	keys = make(map[string]int, len(o.keys))
+	for k, n := range o.keys {
+		keys[k] = n
+	}
+	return o.messages, o.bytes, keys
+}
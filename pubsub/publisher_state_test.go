@@ -0,0 +1,87 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pubsub
+
+import (
+	"context"
+	"testing"
+
+	"cloud.google.com/go/internal/testutil"
+	pb "google.golang.org/genproto/googleapis/pubsub/v1"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestPublisherState(t *testing.T) {
+	ctx := context.Background()
+	c, srv := newFake(t)
+	defer c.Close()
+	defer srv.Close()
+
+	topic := mustCreateTopic(t, c, "t")
+	topic.EnableMessageOrdering = true
+	topic.PublishSettings.CountThreshold = 1
+	topic.PublishSettings.FlowControlSettings.MaxOutstandingBytes = 1000
+
+	want := PublisherState{
+		OrderingKeys: map[string]int{},
+		FlowControl: FlowControlSettings{
+			MaxOutstandingMessages: 1000,
+			MaxOutstandingBytes:    1000,
+			LimitExceededBehavior:  FlowControlIgnore,
+		},
+	}
+	if got := topic.PublisherState(); !testutil.Equal(got, want) {
+		t.Errorf("before publishing: got %+v, want %+v", got, want)
+	}
+
+	// Hold the messages until the fake server is given responses.
+	srv.SetAutoPublishResponse(false)
+	var results []*PublishResult
+	var size int
+	for _, m := range []*Message{
+		{Data: []byte("a1"), OrderingKey: "a"},
+		{Data: []byte("a2"), OrderingKey: "a"},
+		{Data: []byte("b1"), OrderingKey: "b"},
+		{Data: []byte("x")},
+	} {
+		results = append(results, topic.Publish(ctx, m))
+		size += proto.Size(&pb.PubsubMessage{Data: m.Data, OrderingKey: m.OrderingKey})
+	}
+	topic.scheduler.Pause("c")
+
+	want.OutstandingMessages = 4
+	want.OutstandingBytes = size
+	want.OrderingKeys = map[string]int{"a": 2, "b": 1}
+	want.PausedOrderingKeys = []string{"c"}
+	if got := topic.PublisherState(); !testutil.Equal(got, want) {
+		t.Errorf("while publishing: got %+v, want %+v", got, want)
+	}
+
+	for range results {
+		srv.AddPublishResponse(&pb.PublishResponse{MessageIds: []string{"id"}}, nil)
+	}
+	for _, r := range results {
+		if _, err := r.Get(ctx); err != nil {
+			t.Fatal(err)
+		}
+	}
+	want.OutstandingMessages = 0
+	want.OutstandingBytes = 0
+	want.OrderingKeys = map[string]int{}
+	if got := topic.PublisherState(); !testutil.Equal(got, want) {
+		t.Errorf("after publishing: got %+v, want %+v", got, want)
+	}
+	topic.Stop()
+}
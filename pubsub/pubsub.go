@@ -20,7 +20,6 @@ import (
 	"os"
 	"reflect"
 	"runtime"
-	"strings"
 	"time"
 
 	vkit "cloud.google.com/go/pubsub/apiv1"
@@ -49,12 +48,40 @@ type Client struct {
 	projectID string
 	pubc      *vkit.PublisherClient
 	subc      *vkit.SubscriberClient
+
+	// keyPubcs publish the messages with ordering keys, if
+	// ClientConfig.OrderingKeyConnections is set.
+	keyPubcs []*vkit.PublisherClient
+
+	// sharedConn is whether all the clients above use the same connection,
+	// as with the emulator or option.WithGRPCConn. Closing pubc closes it.
+	sharedConn bool
+
+	publishInterceptors []PublishInterceptor
+	receiveInterceptors []ReceiveInterceptor
 }
 
 // ClientConfig has configurations for the client.
 type ClientConfig struct {
 	PublisherCallOptions  *vkit.PublisherCallOptions
 	SubscriberCallOptions *vkit.SubscriberCallOptions
+
+	// PublishInterceptors are called, in order, for each message published
+	// with the client's topics.
+	PublishInterceptors []PublishInterceptor
+
+	// ReceiveInterceptors are called, in order, for each message received
+	// from the client's subscriptions.
+	ReceiveInterceptors []ReceiveInterceptor
+
+	// OrderingKeyConnections, if positive, is the number of additional gRPC
+	// connections that the client opens to publish messages with ordering
+	// keys. Each ordering key is assigned to one of the connections by a
+	// hash of the key, so that the messages of hot topics with many keys are
+	// spread across the connections, while the messages of any one key are
+	// always sent on the same connection. Messages without an ordering key
+	// use the client's other connections.
+	OrderingKeyConnections int
 }
 
 // mergePublisherCallOptions merges two PublisherCallOptions into one and the first argument has
@@ -142,6 +169,11 @@ func NewClientWithConfig(ctx context.Context, projectID string, config *ClientCo
 				Time: 5 * time.Minute,
 			})),
 		}
+		// Record the compression ratio of publish requests, unless telemetry
+		// is disabled. Users can replace the handler with their own.
+		if h := newCompressionStatsHandler(opts); h != nil {
+			o = append(o, option.WithGRPCDialOption(grpc.WithStatsHandler(h)))
+		}
 	}
 	o = append(o, opts...)
 	pubc, err := vkit.NewPublisherClient(ctx, o...)
@@ -157,11 +189,25 @@ func NewClientWithConfig(ctx context.Context, projectID string, config *ClientCo
 		subc.CallOptions = mergeSubscriberCallOptions(subc.CallOptions, config.SubscriberCallOptions)
 	}
 	pubc.SetGoogleClientInfo("gccl", internal.Version)
-	return &Client{
-		projectID: projectID,
-		pubc:      pubc,
-		subc:      subc,
-	}, nil
+	var keyPubcs []*vkit.PublisherClient
+	if config != nil && config.OrderingKeyConnections > 0 {
+		keyPubcs, err = newKeyPublisherClients(ctx, config, o)
+		if err != nil {
+			return nil, err
+		}
+	}
+	c = &Client{
+		projectID:  projectID,
+		pubc:       pubc,
+		subc:       subc,
+		keyPubcs:   keyPubcs,
+		sharedConn: pubc.Connection() == subc.Connection(),
+	}
+	if config != nil {
+		c.publishInterceptors = config.PublishInterceptors
+		c.receiveInterceptors = config.ReceiveInterceptors
+	}
+	return c, nil
 }
 
 // Close releases any resources held by the client,
@@ -171,18 +217,23 @@ func NewClientWithConfig(ctx context.Context, projectID string, config *ClientCo
 // called at exit.
 func (c *Client) Close() error {
 	pubErr := c.pubc.Close()
+	if c.sharedConn {
+		// Closing pubc closed the connection of the other clients too.
+		if pubErr != nil {
+			return fmt.Errorf("pubsub publisher closing error: %v", pubErr)
+		}
+		return nil
+	}
+	for _, kc := range c.keyPubcs {
+		if err := kc.Close(); err != nil && pubErr == nil {
+			pubErr = err
+		}
+	}
 	subErr := c.subc.Close()
 	if pubErr != nil {
 		return fmt.Errorf("pubsub publisher closing error: %v", pubErr)
 	}
 	if subErr != nil {
-		// Suppress client connection closing errors. This will only happen
-		// when using the client in conjunction with the Pub/Sub emulator
-		// or fake (pstest). Closing both clients separately will never
-		// return this error against the live Pub/Sub service.
-		if strings.Contains(subErr.Error(), "the client connection is closing") {
-			return nil
-		}
 		return fmt.Errorf("pubsub subscriber closing error: %v", subErr)
 	}
 	return nil
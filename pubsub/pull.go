@@ -0,0 +1,175 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pubsub
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	gax "github.com/googleapis/gax-go/v2"
+	pb "google.golang.org/genproto/googleapis/pubsub/v1"
+	"google.golang.org/grpc"
+)
+
+// pullAckTimeout bounds the acknowledgement RPCs sent for messages returned
+// by PullN.
+const pullAckTimeout = 60 * time.Second
+
+// PullN pulls up to n messages from the subscription with a single unary
+// Pull call, and returns them. It returns fewer than n messages, possibly
+// none, if fewer are available. Unlike Receive, PullN starts no goroutines
+// and does not extend the ack deadlines of the messages; they are redelivered
+// once the subscription's ack deadline passes, unless they are acked first
+// or their deadlines are extended with ModifyAckDeadline.
+//
+// Calling Ack or Nack on a returned message sends the request immediately,
+// in the background; use AckWithResult or NackWithResult to wait for its
+// outcome. To ack many messages in one call, use AckMessages.
+//
+// ReceiveSettings do not apply to PullN.
+func (s *Subscription) PullN(ctx context.Context, n int) ([]*Message, error) {
+	if n <= 0 {
+		return nil, fmt.Errorf("pubsub: PullN requires a positive number of messages; got %d", n)
+	}
+	res, err := s.c.subc.Pull(ctx, &pb.PullRequest{
+		Subscription: s.name,
+		MaxMessages:  trunc32(int64(n)),
+	}, gax.WithGRPCOptions(grpc.MaxCallRecvMsgSize(maxSendRecvBytes)))
+	if err != nil {
+		return nil, err
+	}
+	recordStat(ctx, PullCount, int64(len(res.ReceivedMessages)))
+	return convertMessages(res.ReceivedMessages, time.Now(), s.pulledDone)
+}
+
+// pulledDone is the done function of messages returned by PullN. It sends the
+// ack or nack for ackID and reports its outcome in r.
+func (s *Subscription) pulledDone(ackID string, ack bool, r *AckResult, _ time.Time) {
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), pullAckTimeout)
+		defer cancel()
+		var err error
+		if ack {
+			err = s.acknowledge(ctx, []string{ackID})
+		} else {
+			err = s.modifyAckDeadline(ctx, []string{ackID}, 0)
+		}
+		setPulledAckResults(map[string]*AckResult{ackID: r}, []string{ackID}, err)
+	}()
+}
+
+// AckMessages acknowledges msgs, which must have been returned by PullN,
+// using as few requests as possible. Messages that have already been acked
+// or nacked are ignored. Afterwards, calling Ack or Nack on msgs has no
+// effect.
+func (s *Subscription) AckMessages(ctx context.Context, msgs []*Message) error {
+	results, ids, err := takePulledMessages(msgs)
+	if err != nil {
+		return err
+	}
+	err = s.sendPulled(ids, func(ids []string) error { return s.acknowledge(ctx, ids) })
+	setPulledAckResults(results, ids, err)
+	return err
+}
+
+// ModifyAckDeadline sets the ack deadlines of msgs, which must have been
+// returned by PullN and not yet acked or nacked, to deadline from now. This
+// extends the time available to process them before they are redelivered. A
+// deadline of zero makes the messages available for redelivery immediately.
+// The deadline must be at most 10 minutes.
+func (s *Subscription) ModifyAckDeadline(ctx context.Context, msgs []*Message, deadline time.Duration) error {
+	if deadline < 0 || deadline > maxDurationPerLeaseExtension {
+		return fmt.Errorf("pubsub: ack deadline must be between 0 and %v; got %v", maxDurationPerLeaseExtension, deadline)
+	}
+	ids := make([]string, 0, len(msgs))
+	for _, m := range msgs {
+		ackh, ok := msgAckHandler(m)
+		if !ok || ackh.ackID == "" {
+			return errors.New("pubsub: ModifyAckDeadline called with a message not returned by PullN")
+		}
+		if !ackh.calledDone {
+			ids = append(ids, ackh.ackID)
+		}
+	}
+	return s.sendPulled(ids, func(ids []string) error { return s.modifyAckDeadline(ctx, ids, deadline) })
+}
+
+// takePulledMessages marks msgs as done, and returns the ack IDs and results
+// of those that were not done already.
+func takePulledMessages(msgs []*Message) (map[string]*AckResult, []string, error) {
+	results := map[string]*AckResult{}
+	var ids []string
+	for _, m := range msgs {
+		ackh, ok := msgAckHandler(m)
+		if !ok || ackh.ackID == "" {
+			return nil, nil, errors.New("pubsub: AckMessages called with a message not returned by PullN")
+		}
+	}
+	for _, m := range msgs {
+		ackh, _ := msgAckHandler(m)
+		if ackh.calledDone {
+			continue
+		}
+		ackh.calledDone = true
+		results[ackh.ackID] = ackh.ackResult
+		ids = append(ids, ackh.ackID)
+	}
+	return results, ids, nil
+}
+
+// sendPulled calls send with batches of ids that fit in a request.
+func (s *Subscription) sendPulled(ids []string, send func([]string) error) error {
+	// Account for the Subscription and AckDeadlineSeconds fields.
+	maxSize := maxPayload - calcFieldSizeString(s.name) - calcFieldSizeInt(int(maxDurationPerLeaseExtension/time.Second))
+	var batch []string
+	for len(ids) > 0 {
+		batch, ids = splitRequestIDs(ids, maxSize)
+		if err := send(batch); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *Subscription) acknowledge(ctx context.Context, ids []string) error {
+	recordStat(ctx, AckCount, int64(len(ids)))
+	return s.c.subc.Acknowledge(ctx, &pb.AcknowledgeRequest{
+		Subscription: s.name,
+		AckIds:       ids,
+	})
+}
+
+func (s *Subscription) modifyAckDeadline(ctx context.Context, ids []string, deadline time.Duration) error {
+	if deadline == 0 {
+		recordStat(ctx, NackCount, int64(len(ids)))
+	} else {
+		recordStat(ctx, ModAckCount, int64(len(ids)))
+	}
+	return s.c.subc.ModifyAckDeadline(ctx, &pb.ModifyAckDeadlineRequest{
+		Subscription:       s.name,
+		AckDeadlineSeconds: int32(deadline / time.Second),
+		AckIds:             ids,
+	})
+}
+
+// setPulledAckResults sets the results in m of the ack IDs ids according to
+// err. Unlike Receive, acks of pulled messages are not retried.
+func setPulledAckResults(m map[string]*AckResult, ids []string, err error) {
+	if retry := resolveAckResults(m, ids, err); len(retry) > 0 {
+		failAckResults(m, retry, err)
+	}
+}
@@ -0,0 +1,82 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pubsub
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestPullN(t *testing.T) {
+	ctx := context.Background()
+	client, srv := newFake(t)
+	defer client.Close()
+	defer srv.Close()
+
+	topic := mustCreateTopic(t, client, "t")
+	sub, err := client.CreateSubscription(ctx, "s", SubscriptionConfig{Topic: topic})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, d := range []string{"a", "b", "c"} {
+		srv.Publish(topic.String(), []byte(d), nil)
+	}
+
+	if _, err := sub.PullN(ctx, 0); err == nil {
+		t.Error("PullN(0) got nil error, want error")
+	}
+	msgs, err := sub.PullN(ctx, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(msgs) != 2 {
+		t.Fatalf("PullN(2) returned %d messages, want 2", len(msgs))
+	}
+	if err := sub.ModifyAckDeadline(ctx, msgs, time.Minute); err != nil {
+		t.Fatalf("ModifyAckDeadline: %v", err)
+	}
+	if err := sub.ModifyAckDeadline(ctx, msgs, time.Hour); err == nil {
+		t.Error("ModifyAckDeadline(time.Hour) got nil error, want error")
+	}
+
+	r := msgs[0].AckWithResult()
+	if s, err := r.Get(ctx); s != AcknowledgeStatusSuccess || err != nil {
+		t.Errorf("AckWithResult: got (%v, %v), want success", s, err)
+	}
+	if err := sub.AckMessages(ctx, msgs); err != nil {
+		t.Fatalf("AckMessages: %v", err)
+	}
+
+	rest, err := sub.PullN(ctx, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rest) != 1 {
+		t.Fatalf("PullN(10) returned %d messages, want 1", len(rest))
+	}
+	if err := sub.AckMessages(ctx, rest); err != nil {
+		t.Fatalf("AckMessages: %v", err)
+	}
+	for _, m := range srv.Messages() {
+		if m.Acks != 1 {
+			t.Errorf("message %q acked %d times, want 1", m.Data, m.Acks)
+		}
+	}
+
+	if err := sub.AckMessages(ctx, []*Message{{Data: []byte("x")}}); err == nil {
+		t.Error("AckMessages with a message not from PullN got nil error, want error")
+	}
+}
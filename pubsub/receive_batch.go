@@ -0,0 +1,172 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pubsub
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// BatchSettings control how ReceiveBatch groups messages into batches.
+type BatchSettings struct {
+	// MaxMessages is the maximum number of messages in a batch.
+	// If zero, it is 100.
+	MaxMessages int
+
+	// MaxBytes is the maximum total size of the data of the messages in a
+	// batch. A batch is delivered as soon as it reaches MaxBytes, so a batch
+	// can exceed it by up to the size of one message. If zero, the size of
+	// batches is not limited.
+	MaxBytes int
+
+	// MaxLatency is the longest time a message waits in an incomplete batch
+	// before the batch is delivered. If zero, it is one second.
+	MaxLatency time.Duration
+}
+
+// DefaultBatchSettings holds the default values for BatchSettings.
+var DefaultBatchSettings = BatchSettings{
+	MaxMessages: 100,
+	MaxLatency:  time.Second,
+}
+
+// ReceiveBatch is like Receive, but it calls f with batches of messages
+// instead of one message at a time. A batch is delivered when it reaches
+// settings.MaxMessages or settings.MaxBytes, or when its oldest message has
+// waited for settings.MaxLatency. f is called for one batch at a time.
+//
+// f must ack or nack every message in the batch. Messages count against
+// ReceiveSettings.MaxOutstandingMessages and MaxOutstandingBytes until they
+// are acked or nacked, so those limits should allow at least one full batch
+// in addition to the one being handled by f.
+//
+// The context passed to f holds an OpenTelemetry span for processing the
+// batch, linked to the spans that published its messages.
+//
+// While ReceiveBatch is shutting down, f may be called with a done context
+// to handle messages that were already received. ReceiveBatch returns when
+// every received message has been delivered to f and f has returned.
+func (s *Subscription) ReceiveBatch(ctx context.Context, settings BatchSettings, f func(context.Context, []*Message)) error {
+	if settings.MaxMessages <= 0 {
+		settings.MaxMessages = DefaultBatchSettings.MaxMessages
+	}
+	if settings.MaxLatency <= 0 {
+		settings.MaxLatency = DefaultBatchSettings.MaxLatency
+	}
+	b := &messageBatcher{settings: settings, batches: make(chan []*Message)}
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for batch := range b.batches {
+			bctx, span := startProcessSpan(ctx, s.ID(), batch)
+			f(bctx, batch)
+			span.End()
+		}
+	}()
+	err := s.receive(ctx, false, func(_ context.Context, m *Message) {
+		b.add(m)
+	})
+	b.close()
+	<-done
+	return err
+}
+
+// messageBatcher groups messages into batches according to its settings and
+// sends them on batches.
+type messageBatcher struct {
+	settings BatchSettings
+	batches  chan []*Message
+
+	mu    sync.Mutex
+	msgs  []*Message
+	size  int
+	timer *time.Timer
+	// sending counts the batches that have been taken but not yet sent.
+	sending sync.WaitGroup
+}
+
+// add adds m to the current batch. If that completes the batch, add blocks
+// until the batch is taken from b.batches.
+func (b *messageBatcher) add(m *Message) {
+	b.mu.Lock()
+	if len(b.msgs) == 0 {
+		var timer *time.Timer
+		timer = time.AfterFunc(b.settings.MaxLatency, func() {
+			b.mu.Lock()
+			// The batch the timer was started for may already have been
+			// delivered.
+			var batch []*Message
+			if b.timer == timer {
+				batch = b.take()
+			}
+			b.mu.Unlock()
+			b.send(batch)
+		})
+		b.timer = timer
+	}
+	b.msgs = append(b.msgs, m)
+	b.size += len(m.Data)
+	full := len(b.msgs) >= b.settings.MaxMessages ||
+		(b.settings.MaxBytes > 0 && b.size >= b.settings.MaxBytes)
+	var batch []*Message
+	if full {
+		batch = b.take()
+	}
+	b.mu.Unlock()
+	b.send(batch)
+}
+
+// flush sends the current batch, if it is not empty.
+func (b *messageBatcher) flush() {
+	b.mu.Lock()
+	batch := b.take()
+	b.mu.Unlock()
+	b.send(batch)
+}
+
+// close flushes the current batch and closes b.batches once every batch has
+// been sent. add must not be called afterwards.
+func (b *messageBatcher) close() {
+	b.flush()
+	b.sending.Wait()
+	close(b.batches)
+}
+
+// send sends batch, which must have been returned by take, on b.batches.
+func (b *messageBatcher) send(batch []*Message) {
+	if batch == nil {
+		return
+	}
+	b.batches <- batch
+	b.sending.Done()
+}
+
+// take returns the current batch, or nil if it is empty, and starts a new
+// one.
+//
+// Called with the lock held.
+func (b *messageBatcher) take() []*Message {
+	if len(b.msgs) == 0 {
+		return nil
+	}
+	b.timer.Stop()
+	b.timer = nil
+	b.sending.Add(1)
+	batch := b.msgs
+	b.msgs = nil
+	b.size = 0
+	return batch
+}
@@ -0,0 +1,120 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pubsub
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestReceiveBatch(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	client, srv := newFake(t)
+	defer client.Close()
+	defer srv.Close()
+
+	topic := mustCreateTopic(t, client, "t")
+	sub, err := client.CreateSubscription(ctx, "s", SubscriptionConfig{Topic: topic})
+	if err != nil {
+		t.Fatal(err)
+	}
+	const numMsgs = 25
+	for i := 0; i < numMsgs; i++ {
+		srv.Publish(topic.String(), []byte(fmt.Sprint(i)), nil)
+	}
+
+	var (
+		mu    sync.Mutex
+		sizes []int
+		seen  = map[string]bool{}
+	)
+	cctx, ccancel := context.WithCancel(ctx)
+	err = sub.ReceiveBatch(cctx, BatchSettings{MaxMessages: 10, MaxLatency: 100 * time.Millisecond}, func(_ context.Context, msgs []*Message) {
+		mu.Lock()
+		defer mu.Unlock()
+		sizes = append(sizes, len(msgs))
+		for _, m := range msgs {
+			seen[string(m.Data)] = true
+			m.Ack()
+		}
+		if len(seen) == numMsgs {
+			ccancel()
+		}
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(seen) != numMsgs {
+		t.Errorf("got %d distinct messages, want %d", len(seen), numMsgs)
+	}
+	for _, n := range sizes {
+		if n < 1 || n > 10 {
+			t.Errorf("got batch of %d messages, want 1 to 10", n)
+		}
+	}
+}
+
+func TestMessageBatcher(t *testing.T) {
+	for _, test := range []struct {
+		desc     string
+		settings BatchSettings
+		data     []string
+		want     []int
+	}{
+		{
+			desc:     "count",
+			settings: BatchSettings{MaxMessages: 2, MaxLatency: time.Hour},
+			data:     []string{"a", "b", "c", "d"},
+			want:     []int{2, 2},
+		},
+		{
+			desc:     "bytes",
+			settings: BatchSettings{MaxMessages: 100, MaxBytes: 4, MaxLatency: time.Hour},
+			data:     []string{"aa", "b", "ccc", "dddd", "e"},
+			want:     []int{3, 1, 1},
+		},
+		{
+			desc:     "latency",
+			settings: BatchSettings{MaxMessages: 100, MaxLatency: 10 * time.Millisecond},
+			data:     []string{"a", "b"},
+			want:     []int{2},
+		},
+	} {
+		b := &messageBatcher{settings: test.settings, batches: make(chan []*Message)}
+		var got []int
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			for batch := range b.batches {
+				got = append(got, len(batch))
+			}
+		}()
+		for _, d := range test.data {
+			b.add(&Message{Data: []byte(d)})
+		}
+		if test.settings.MaxLatency < time.Hour {
+			time.Sleep(50 * time.Millisecond)
+		}
+		b.close()
+		<-done
+		if fmt.Sprint(got) != fmt.Sprint(test.want) {
+			t.Errorf("%s: got batch sizes %v, want %v", test.desc, got, test.want)
+		}
+	}
+}
@@ -261,3 +261,39 @@ func (s *SchemaClient) ValidateMessageWithID(ctx context.Context, msg []byte, en
 	}
 	return &ValidateMessageResult{}, nil
 }
+
+// ValidateMessageForTopic validates a message against the schema attached to
+// topic, using the message encoding configured for the topic. It can be used
+// to reject a message before it is published, instead of having the publish
+// fail. If the topic has no schema, any message is valid.
+//
+// ValidateMessageForTopic fetches the configuration of topic on each call.
+func (s *SchemaClient) ValidateMessageForTopic(ctx context.Context, msg []byte, topic *Topic) (*ValidateMessageResult, error) {
+	cfg, err := topic.Config(ctx)
+	if err != nil {
+		return nil, err
+	}
+	ss := cfg.SchemaSettings
+	if ss == nil || ss.Schema == "" {
+		return &ValidateMessageResult{}, nil
+	}
+	if ss.Schema == deletedSchemaName {
+		return nil, fmt.Errorf("pubsub: the schema of topic %s has been deleted", topic.name)
+	}
+	req := &pb.ValidateMessageRequest{
+		Parent: fmt.Sprintf("projects/%s", s.projectID),
+		SchemaSpec: &pb.ValidateMessageRequest_Name{
+			Name: ss.Schema,
+		},
+		Message:  msg,
+		Encoding: pb.Encoding(ss.Encoding),
+	}
+	if _, err := s.sc.ValidateMessage(ctx, req); err != nil {
+		return nil, err
+	}
+	return &ValidateMessageResult{}, nil
+}
+
+// deletedSchemaName is the schema name reported for a topic whose schema has
+// been deleted.
+const deletedSchemaName = "_deleted-schema_"
@@ -173,3 +173,47 @@ func mustCreateSchema(t *testing.T, c *SchemaClient, id string, sc SchemaConfig)
 	}
 	return schema
 }
+
+func TestSchemaValidateMessageForTopic(t *testing.T) {
+	ctx := context.Background()
+	admin, srv := newSchemaFake(t)
+	defer admin.Close()
+	client, err := NewClient(ctx, "my-proj", option.WithEndpoint(srv.Addr), option.WithoutAuthentication(), option.WithGRPCDialOption(grpc.WithInsecure()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+
+	schemaConfig := SchemaConfig{Type: SchemaAvro, Definition: "some-definition"}
+	sc, err := admin.CreateSchema(ctx, "my-schema", schemaConfig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	withSchema, err := client.CreateTopicWithConfig(ctx, "with-schema", &TopicConfig{
+		SchemaSettings: &SchemaSettings{Schema: sc.Name, Encoding: EncodingJSON},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := admin.ValidateMessageForTopic(ctx, []byte("{}"), withSchema); err != nil {
+		t.Errorf("ValidateMessageForTopic() got err: %v", err)
+	}
+
+	noSchema, err := client.CreateTopic(ctx, "no-schema")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := admin.ValidateMessageForTopic(ctx, []byte("anything"), noSchema); err != nil {
+		t.Errorf("ValidateMessageForTopic() without schema got err: %v", err)
+	}
+
+	missing, err := client.CreateTopicWithConfig(ctx, "missing-schema", &TopicConfig{
+		SchemaSettings: &SchemaSettings{Schema: "projects/my-proj/schemas/missing", Encoding: EncodingJSON},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := admin.ValidateMessageForTopic(ctx, []byte("{}"), missing); status.Code(err) != codes.NotFound {
+		t.Errorf("ValidateMessageForTopic() with missing schema got err: %v, want NotFound", err)
+	}
+}
@@ -16,6 +16,7 @@ package pubsub
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"strings"
 	"time"
@@ -99,7 +100,16 @@ func (s *Snapshot) Delete(ctx context.Context) error {
 // window (or to a point before the system's notion of the subscription
 // creation time), only retained messages will be marked as unacknowledged,
 // and already-expunged messages will not be restored.
+//
+// To replay messages that were already acknowledged, the subscription must
+// retain them, with SubscriptionConfig.RetainAckedMessages, or the topic must
+// retain all messages, with TopicConfig.RetentionDuration.
+//
+// SeekToTime returns an error for the zero time.
 func (s *Subscription) SeekToTime(ctx context.Context, t time.Time) error {
+	if t.IsZero() {
+		return errors.New("pubsub: SeekToTime called with the zero time")
+	}
 	ts := timestamppb.New(t)
 	_, err := s.c.subc.Seek(ctx, &pb.SeekRequest{
 		Subscription: s.name,
@@ -137,6 +147,9 @@ func (s *Subscription) CreateSnapshot(ctx context.Context, name string) (*Snapsh
 // The snapshot need not be created from this subscription,
 // but it must be for the topic this subscription is subscribed to.
 func (s *Subscription) SeekToSnapshot(ctx context.Context, snap *Snapshot) error {
+	if snap == nil {
+		return errors.New("pubsub: SeekToSnapshot called with a nil snapshot")
+	}
 	_, err := s.c.subc.Seek(ctx, &pb.SeekRequest{
 		Subscription: s.name,
 		Target:       &pb.SeekRequest_Snapshot{Snapshot: snap.name},
@@ -97,7 +97,7 @@ func testStreamingPullIteration(t *testing.T, client *Client, server *mockServer
 			t.Errorf("%d: no message for ackID %q", i, wantAckh.ackID)
 			continue
 		}
-		if !testutil.Equal(got, want, cmp.AllowUnexported(Message{}, psAckHandler{}), cmpopts.IgnoreTypes(time.Time{}, func(string, bool, time.Time) {})) {
+		if !testutil.Equal(got, want, cmp.AllowUnexported(Message{}, psAckHandler{}), cmpopts.IgnoreTypes(time.Time{}, func(string, bool, *AckResult, time.Time) {}, &AckResult{}, func(string, time.Time) {})) {
 			t.Errorf("%d: got\n%#v\nwant\n%#v", i, got, want)
 		}
 	}
@@ -443,6 +443,72 @@ func newMock(t *testing.T) (*Client, *mockServer) {
 	return client, srv
 }
 
+func TestStreamingPullAckWithResult(t *testing.T) {
+	for _, exactlyOnce := range []bool{false, true} {
+		client, server := newMock(t)
+		server.exactlyOnce = exactlyOnce
+		server.addStreamingPullMessages(testMessages)
+		var (
+			mu      sync.Mutex
+			results = map[string]*AckResult{}
+		)
+		_, err := pullN(context.Background(), client.Subscription("S"), len(testMessages), func(_ context.Context, m *Message) {
+			r := m.AckWithResult()
+			mu.Lock()
+			results[msgAckID(m)] = r
+			mu.Unlock()
+		})
+		if c := status.Convert(err); err != nil && c.Code() != codes.Canceled {
+			t.Fatalf("Pull: %v", err)
+		}
+		server.wait()
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		for id, r := range results {
+			if got, err := r.Get(ctx); got != AcknowledgeStatusSuccess || err != nil {
+				t.Errorf("exactlyOnce=%t, ack %q: got (%v, %v), want success", exactlyOnce, id, got, err)
+			}
+			if !server.Acked[id] {
+				t.Errorf("exactlyOnce=%t: msg %q should have been acked but wasn't", exactlyOnce, id)
+			}
+		}
+		cancel()
+		client.Close()
+		server.srv.Close()
+	}
+}
+
+func TestStreamingPullAckWithResultAfterReceive(t *testing.T) {
+	for _, exactlyOnce := range []bool{false, true} {
+		client, server := newMock(t)
+		server.exactlyOnce = exactlyOnce
+		server.addStreamingPullMessages(testMessages[:1])
+		server.addStreamingPullError(status.Error(codes.InvalidArgument, "bad"))
+		var (
+			mu  sync.Mutex
+			msg *Message
+		)
+		err := client.Subscription("S").Receive(context.Background(), func(_ context.Context, m *Message) {
+			mu.Lock()
+			msg = m
+			mu.Unlock()
+		})
+		if status.Code(err) != codes.InvalidArgument {
+			t.Fatalf("exactlyOnce=%t: got %v from Receive, want InvalidArgument", exactlyOnce, err)
+		}
+		if msg == nil {
+			t.Fatalf("exactlyOnce=%t: got no message", exactlyOnce)
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		got, err := msg.AckWithResult().Get(ctx)
+		if got != AcknowledgeStatusOther || status.Code(err) != codes.InvalidArgument {
+			t.Errorf("exactlyOnce=%t: got (%v, %v), want AcknowledgeStatusOther with the Receive error", exactlyOnce, got, err)
+		}
+		cancel()
+		client.Close()
+		server.srv.Close()
+	}
+}
+
 // pullN calls sub.Receive until at least n messages are received.
 func pullN(ctx context.Context, sub *Subscription, n int, f func(context.Context, *Message)) ([]*Message, error) {
 	var (
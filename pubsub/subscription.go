@@ -19,8 +19,10 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"net/url"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"cloud.google.com/go/iam"
@@ -148,6 +150,52 @@ type PushConfig struct {
 	AuthenticationMethod AuthenticationMethod
 }
 
+// PushVersionAttribute is the PushConfig attribute that selects the format of
+// the requests sent to the push endpoint. Its value is PushVersionV1 or
+// PushVersionV1Beta1.
+const PushVersionAttribute = "x-goog-version"
+
+// Values of the PushVersionAttribute attribute.
+const (
+	// PushVersionV1 sends messages in the format of the v1 Pub/Sub API.
+	// It is the default.
+	PushVersionV1 = "v1"
+	// PushVersionV1Beta1 sends messages in the format of the v1beta1 Pub/Sub
+	// API.
+	PushVersionV1Beta1 = "v1beta1"
+)
+
+// validate reports whether pc is a valid configuration for push delivery.
+func (pc *PushConfig) validate() error {
+	if pc.Endpoint == "" {
+		if pc.AuthenticationMethod != nil || len(pc.Attributes) != 0 {
+			return errors.New("PushConfig.Endpoint is required when AuthenticationMethod or Attributes are set")
+		}
+		// An empty PushConfig selects pull delivery.
+		return nil
+	}
+	u, err := url.Parse(pc.Endpoint)
+	if err != nil || u.Host == "" || (u.Scheme != "https" && u.Scheme != "http") {
+		return fmt.Errorf("PushConfig.Endpoint must be an absolute HTTP or HTTPS URL; got %q", pc.Endpoint)
+	}
+	if v, ok := pc.Attributes[PushVersionAttribute]; ok && v != PushVersionV1 && v != PushVersionV1Beta1 {
+		return fmt.Errorf("PushConfig attribute %s must be %q or %q; got %q", PushVersionAttribute, PushVersionV1, PushVersionV1Beta1, v)
+	}
+	switch am := pc.AuthenticationMethod.(type) {
+	case nil:
+	case *OIDCToken:
+		if u.Scheme != "https" {
+			return fmt.Errorf("PushConfig.Endpoint must use HTTPS with OIDC token authentication; got %q", pc.Endpoint)
+		}
+		if err := am.validate(); err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("unsupported PushConfig.AuthenticationMethod %T", am)
+	}
+	return nil
+}
+
 func (pc *PushConfig) toProto() *pb.PushConfig {
 	if pc == nil {
 		return nil
@@ -198,6 +246,19 @@ var _ AuthenticationMethod = (*OIDCToken)(nil)
 
 func (oidcToken *OIDCToken) isAuthMethod() bool { return true }
 
+func (oidcToken *OIDCToken) validate() error {
+	if oidcToken == nil {
+		return errors.New("OIDCToken must not be nil")
+	}
+	if oidcToken.ServiceAccountEmail == "" {
+		return errors.New("OIDCToken.ServiceAccountEmail is required")
+	}
+	if i := strings.Index(oidcToken.ServiceAccountEmail, "@"); i <= 0 || i == len(oidcToken.ServiceAccountEmail)-1 {
+		return fmt.Errorf("OIDCToken.ServiceAccountEmail must be an email address; got %q", oidcToken.ServiceAccountEmail)
+	}
+	return nil
+}
+
 func (oidcToken *OIDCToken) toProto() *pb.PushConfig_OidcToken_ {
 	if oidcToken == nil {
 		return nil
@@ -260,6 +321,16 @@ type BigQueryConfig struct {
 	State BigQueryConfigState
 }
 
+// validate reports options of bc that the service would reject.
+func (bc *BigQueryConfig) validate() error {
+	if bc.Table == "" {
+		if bc.UseTopicSchema || bc.WriteMetadata || bc.DropUnknownFields {
+			return errors.New("BigQueryConfig options require a Table")
+		}
+	}
+	return nil
+}
+
 func (bc *BigQueryConfig) toProto() *pb.BigQueryConfig {
 	if bc == nil {
 		return nil
@@ -650,6 +721,18 @@ type ReceiveSettings struct {
 	// which will be added in a future release.
 	MinExtensionPeriod time.Duration
 
+	// ShouldExtendLease, if not nil, is called with each outstanding message
+	// before its ack deadline is extended, along with the duration of the
+	// extension. If it returns false, the deadline of the message is no
+	// longer extended, and the message is redelivered once its current
+	// deadline expires, unless it is acked first. MaxExtension still applies
+	// to messages for which it returns true. To change MaxExtension for a
+	// single message, call Message.SetMaxExtension.
+	//
+	// ShouldExtendLease is called from a single goroutine and delays all
+	// extensions while it runs, so it should return quickly.
+	ShouldExtendLease func(m *Message, extension time.Duration) bool
+
 	// MaxOutstandingMessages is the maximum number of unprocessed messages
 	// (unacknowledged but not yet expired). If MaxOutstandingMessages is 0, it
 	// will be treated as if it were DefaultReceiveSettings.MaxOutstandingMessages.
@@ -664,6 +747,13 @@ type ReceiveSettings struct {
 	// for unprocessed messages.
 	MaxOutstandingBytes int
 
+	// Autoscaling, if not nil, makes Receive adjust the number of messages
+	// that it processes at once from their observed processing latency,
+	// instead of always allowing MaxOutstandingMessages, which remains the
+	// upper limit. It doesn't reduce the number of messages pulled. See
+	// ConcurrencyAutoscaling.
+	Autoscaling *ConcurrencyAutoscaling
+
 	// UseLegacyFlowControl disables enforcing flow control settings at the Cloud
 	// PubSub server and the less accurate method of only enforcing flow control
 	// at the client side is used.
@@ -872,7 +962,23 @@ const (
 )
 
 func (cfg *SubscriptionConfigToUpdate) validate() error {
-	if cfg == nil || cfg.ExpirationPolicy == nil {
+	if cfg == nil {
+		return nil
+	}
+	if cfg.PushConfig != nil {
+		if err := cfg.PushConfig.validate(); err != nil {
+			return err
+		}
+	}
+	if cfg.BigQueryConfig != nil {
+		if cfg.PushConfig != nil && cfg.PushConfig.Endpoint != "" && cfg.BigQueryConfig.Table != "" {
+			return errors.New("PushConfig and BigQueryConfig cannot both be set")
+		}
+		if err := cfg.BigQueryConfig.validate(); err != nil {
+			return err
+		}
+	}
+	if cfg.ExpirationPolicy == nil {
 		return nil
 	}
 	expPolicy, min := optional.ToDuration(cfg.ExpirationPolicy), minExpirationPolicy
@@ -937,6 +1043,15 @@ func (c *Client) CreateSubscription(ctx context.Context, id string, cfg Subscrip
 	if d := cfg.AckDeadline; d < 10*time.Second || d > 600*time.Second {
 		return nil, fmt.Errorf("ack deadline must be between 10 and 600 seconds; got: %v", d)
 	}
+	if cfg.PushConfig.Endpoint != "" && cfg.BigQueryConfig.Table != "" {
+		return nil, errors.New("pubsub: PushConfig and BigQueryConfig cannot both be set")
+	}
+	if err := cfg.PushConfig.validate(); err != nil {
+		return nil, fmt.Errorf("pubsub: %v", err)
+	}
+	if err := cfg.BigQueryConfig.validate(); err != nil {
+		return nil, fmt.Errorf("pubsub: %v", err)
+	}
 
 	sub := c.Subscription(id)
 	_, err := c.subc.CreateSubscription(ctx, cfg.toProto(sub.name))
@@ -976,6 +1091,15 @@ var errReceiveInProgress = errors.New("pubsub: Receive already in progress for t
 //
 // Each Subscription may have only one invocation of Receive active at a time.
 func (s *Subscription) Receive(ctx context.Context, f func(context.Context, *Message)) error {
+	return s.receive(ctx, true, f)
+}
+
+// receive implements Receive. If traceMessages is true, f is called for each
+// message with a context holding a span for processing the message.
+func (s *Subscription) receive(ctx context.Context, traceMessages bool, f func(context.Context, *Message)) error {
+	if len(s.c.receiveInterceptors) > 0 {
+		f = chainReceiveInterceptors(s.c.receiveInterceptors, f)
+	}
 	s.mu.Lock()
 	if s.receiveActive {
 		s.mu.Unlock()
@@ -1031,6 +1155,7 @@ func (s *Subscription) Receive(ctx context.Context, f func(context.Context, *Mes
 		maxOutstandingMessages: maxCount,
 		maxOutstandingBytes:    maxBytes,
 		useLegacyFlowControl:   s.ReceiveSettings.UseLegacyFlowControl,
+		shouldExtendLease:      s.ReceiveSettings.ShouldExtendLease,
 	}
 	fc := newSubscriptionFlowController(FlowControlSettings{
 		MaxOutstandingMessages: maxCount,
@@ -1038,6 +1163,11 @@ func (s *Subscription) Receive(ctx context.Context, f func(context.Context, *Mes
 		LimitExceededBehavior:  FlowControlBlock,
 	})
 
+	var autoscaler *concurrencyAutoscaler
+	if s.ReceiveSettings.Autoscaling != nil {
+		autoscaler = newConcurrencyAutoscaler(s.ReceiveSettings.Autoscaling, maxCount)
+	}
+
 	sched := scheduler.NewReceiveScheduler(maxCount)
 
 	// Wait for all goroutines started by Receive to return, so instead of an
@@ -1057,6 +1187,13 @@ func (s *Subscription) Receive(ctx context.Context, f func(context.Context, *Mes
 	ctx2, cancel2 := context.WithCancel(gctx)
 	defer cancel2()
 
+	if autoscaler != nil {
+		group.Go(func() error {
+			autoscaler.run(ctx2)
+			return nil
+		})
+	}
+
 	for i := 0; i < numGoroutines; i++ {
 		// The iterator does not use the context passed to Receive. If it did,
 		// canceling that context would immediately stop the iterator without
@@ -1134,12 +1271,28 @@ func (s *Subscription) Receive(ctx context.Context, f func(context.Context, *Mes
 						// Return nil if the context is done, not err.
 						return nil
 					}
+					if autoscaler != nil {
+						if err := autoscaler.limiter.acquire(ctx); err != nil {
+							fc.release(ctx, len(msg.Data))
+							for _, m := range msgs[i:] {
+								m.Nack()
+							}
+							return nil
+						}
+					}
 					ackh, _ := msgAckHandler(msg)
 					old := ackh.doneFunc
 					msgLen := len(msg.Data)
-					ackh.doneFunc = func(ackID string, ack bool, receiveTime time.Time) {
+					var started int64 // when processing began, in Unix nanoseconds
+					ackh.doneFunc = func(ackID string, ack bool, r *AckResult, receiveTime time.Time) {
 						defer fc.release(ctx, msgLen)
-						old(ackID, ack, receiveTime)
+						if autoscaler != nil {
+							if t := atomic.LoadInt64(&started); t != 0 {
+								autoscaler.record(time.Since(time.Unix(0, t)))
+							}
+							autoscaler.limiter.release()
+						}
+						old(ackID, ack, r, receiveTime)
 					}
 					wg.Add(1)
 					// Make sure the subscription has ordering enabled before adding to scheduler.
@@ -1151,7 +1304,15 @@ func (s *Subscription) Receive(ctx context.Context, f func(context.Context, *Mes
 					// constructor level?
 					if err := sched.Add(key, msg, func(msg interface{}) {
 						defer wg.Done()
-						f(ctx2, msg.(*Message))
+						m := msg.(*Message)
+						atomic.StoreInt64(&started, time.Now().UnixNano())
+						if !traceMessages {
+							f(ctx2, m)
+							return
+						}
+						mctx, span := startProcessSpan(ctx2, s.ID(), []*Message{m})
+						defer span.End()
+						f(mctx, m)
 					}); err != nil {
 						wg.Done()
 						// If there are any errors with scheduling messages,
@@ -1211,4 +1372,6 @@ type pullOptions struct {
 	maxOutstandingMessages int
 	maxOutstandingBytes    int
 	useLegacyFlowControl   bool
+	// shouldExtendLease is ReceiveSettings.ShouldExtendLease.
+	shouldExtendLease func(*Message, time.Duration) bool
 }
@@ -469,3 +469,151 @@ func TestBigQuerySubscription(t *testing.T) {
 		t.Fatalf("CreateBQSubscription mismatch: \n%s", diff)
 	}
 }
+
+func TestBigQuerySubscriptionUpdate(t *testing.T) {
+	ctx := context.Background()
+	client, srv := newFake(t)
+	defer client.Close()
+	defer srv.Close()
+
+	topic := mustCreateTopic(t, client, "t")
+	sub, err := client.CreateSubscription(ctx, "s", SubscriptionConfig{Topic: topic})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Switch from pull to delivery to BigQuery.
+	bqConfig := BigQueryConfig{
+		Table:             "some-project.some-dataset.some-table",
+		UseTopicSchema:    true,
+		WriteMetadata:     true,
+		DropUnknownFields: true,
+	}
+	cfg, err := sub.Update(ctx, SubscriptionConfigToUpdate{BigQueryConfig: &bqConfig})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := bqConfig
+	want.State = BigQueryConfigActive
+	if diff := testutil.Diff(cfg.BigQueryConfig, want); diff != "" {
+		t.Errorf("BigQueryConfig mismatch: -got, +want:\n%s", diff)
+	}
+
+	// Revert to pull.
+	cfg, err = sub.Update(ctx, SubscriptionConfigToUpdate{BigQueryConfig: &BigQueryConfig{}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := testutil.Diff(cfg.BigQueryConfig, BigQueryConfig{}); diff != "" {
+		t.Errorf("BigQueryConfig mismatch after revert: -got, +want:\n%s", diff)
+	}
+}
+
+func TestBigQuerySubscriptionValidation(t *testing.T) {
+	ctx := context.Background()
+	client, srv := newFake(t)
+	defer client.Close()
+	defer srv.Close()
+
+	topic := mustCreateTopic(t, client, "t")
+	table := "some-project.some-dataset.some-table"
+	for _, test := range []struct {
+		desc string
+		cfg  SubscriptionConfig
+	}{
+		{
+			desc: "push and BigQuery",
+			cfg: SubscriptionConfig{
+				PushConfig:     PushConfig{Endpoint: "https://example.com/push"},
+				BigQueryConfig: BigQueryConfig{Table: table},
+			},
+		},
+		{
+			desc: "options without table",
+			cfg:  SubscriptionConfig{BigQueryConfig: BigQueryConfig{WriteMetadata: true}},
+		},
+	} {
+		test.cfg.Topic = topic
+		if _, err := client.CreateSubscription(ctx, "s", test.cfg); err == nil {
+			t.Errorf("%s: CreateSubscription got nil error, want error", test.desc)
+		}
+	}
+
+	sub, err := client.CreateSubscription(ctx, "s", SubscriptionConfig{Topic: topic})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := sub.Update(ctx, SubscriptionConfigToUpdate{
+		PushConfig:     &PushConfig{Endpoint: "https://example.com/push"},
+		BigQueryConfig: &BigQueryConfig{Table: table},
+	}); err == nil {
+		t.Error("Update with push and BigQuery configs got nil error, want error")
+	}
+}
+
+func TestPushConfigValidation(t *testing.T) {
+	oidc := &OIDCToken{ServiceAccountEmail: "push@my-project.iam.gserviceaccount.com", Audience: "my-audience"}
+	for _, test := range []struct {
+		desc    string
+		pc      PushConfig
+		wantErr bool
+	}{
+		{desc: "pull", pc: PushConfig{}},
+		{desc: "endpoint", pc: PushConfig{Endpoint: "https://example.com/push"}},
+		{desc: "OIDC", pc: PushConfig{Endpoint: "https://example.com/push", AuthenticationMethod: oidc}},
+		{desc: "version", pc: PushConfig{Endpoint: "https://example.com/push", Attributes: map[string]string{PushVersionAttribute: PushVersionV1Beta1}}},
+		{desc: "attributes without endpoint", pc: PushConfig{Attributes: map[string]string{PushVersionAttribute: PushVersionV1}}, wantErr: true},
+		{desc: "OIDC without endpoint", pc: PushConfig{AuthenticationMethod: oidc}, wantErr: true},
+		{desc: "relative endpoint", pc: PushConfig{Endpoint: "/push"}, wantErr: true},
+		{desc: "bad scheme", pc: PushConfig{Endpoint: "ftp://example.com/push"}, wantErr: true},
+		{desc: "OIDC over HTTP", pc: PushConfig{Endpoint: "http://example.com/push", AuthenticationMethod: oidc}, wantErr: true},
+		{desc: "bad version", pc: PushConfig{Endpoint: "https://example.com/push", Attributes: map[string]string{PushVersionAttribute: "v2"}}, wantErr: true},
+		{desc: "no service account", pc: PushConfig{Endpoint: "https://example.com/push", AuthenticationMethod: &OIDCToken{Audience: "a"}}, wantErr: true},
+		{desc: "bad service account", pc: PushConfig{Endpoint: "https://example.com/push", AuthenticationMethod: &OIDCToken{ServiceAccountEmail: "push"}}, wantErr: true},
+		{desc: "nil OIDC", pc: PushConfig{Endpoint: "https://example.com/push", AuthenticationMethod: (*OIDCToken)(nil)}, wantErr: true},
+	} {
+		err := test.pc.validate()
+		if gotErr := err != nil; gotErr != test.wantErr {
+			t.Errorf("%s: got error %v, want error: %t", test.desc, err, test.wantErr)
+		}
+	}
+
+	ctx := context.Background()
+	client, srv := newFake(t)
+	defer client.Close()
+	defer srv.Close()
+	topic := mustCreateTopic(t, client, "t")
+	bad := PushConfig{Endpoint: "https://example.com/push", AuthenticationMethod: &OIDCToken{}}
+	if _, err := client.CreateSubscription(ctx, "s", SubscriptionConfig{Topic: topic, PushConfig: bad}); err == nil {
+		t.Error("CreateSubscription with invalid PushConfig got nil error, want error")
+	}
+	sub, err := client.CreateSubscription(ctx, "s", SubscriptionConfig{Topic: topic})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := sub.Update(ctx, SubscriptionConfigToUpdate{PushConfig: &bad}); err == nil {
+		t.Error("Update with invalid PushConfig got nil error, want error")
+	}
+}
+
+func TestSeekChecks(t *testing.T) {
+	ctx := context.Background()
+	client, srv := newFake(t)
+	defer client.Close()
+	defer srv.Close()
+
+	topic := mustCreateTopic(t, client, "t")
+	sub, err := client.CreateSubscription(ctx, "s", SubscriptionConfig{Topic: topic})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := sub.SeekToTime(ctx, time.Time{}); err == nil {
+		t.Error("SeekToTime(zero time): got nil, want error")
+	}
+	if err := sub.SeekToSnapshot(ctx, nil); err == nil {
+		t.Error("SeekToSnapshot(nil): got nil, want error")
+	}
+	if err := sub.SeekToTime(ctx, time.Now().Add(-time.Minute)); err != nil {
+		t.Errorf("SeekToTime(past time): %v", err)
+	}
+}
@@ -32,6 +32,7 @@ import (
 	gax "github.com/googleapis/gax-go/v2"
 	"go.opencensus.io/stats"
 	"go.opencensus.io/tag"
+	"go.opentelemetry.io/otel/trace"
 	"google.golang.org/api/support/bundler"
 	pb "google.golang.org/genproto/googleapis/pubsub/v1"
 	fmpb "google.golang.org/genproto/protobuf/field_mask"
@@ -80,6 +81,17 @@ type Topic struct {
 
 	flowController
 
+	// keyFlowControllers enforces the flow control limits of each ordering key.
+	keyFlowControllers *orderingKeyFlowControllers
+
+	resumeMu sync.Mutex
+	// resumeTimers holds the timers that automatically resume paused
+	// ordering keys.
+	resumeTimers map[string]*time.Timer
+
+	// outstanding tracks the messages being published, for PublisherState.
+	outstanding outstandingMessages
+
 	// EnableMessageOrdering enables delivery of ordered keys.
 	EnableMessageOrdering bool
 }
@@ -107,6 +119,12 @@ type PublishSettings struct {
 	// The maximum time that the client will attempt to publish a bundle of messages.
 	Timeout time.Duration
 
+	// Retry, if not nil, overrides how failed publish requests are retried.
+	// By default, the retry settings of ClientConfig.PublisherCallOptions
+	// are used. Retry and Timeout may be overridden for individual messages
+	// with WithPublishOverrides.
+	Retry *PublishRetrySettings
+
 	// The maximum number of bytes that the Bundler will keep in memory before
 	// returning ErrOverflow. This is now superseded by FlowControlSettings.MaxOutstandingBytes.
 	// If MaxOutstandingBytes is set, that value will override BufferedByteLimit.
@@ -117,6 +135,31 @@ type PublishSettings struct {
 
 	// FlowControlSettings defines publisher flow control settings.
 	FlowControlSettings FlowControlSettings
+
+	// OrderingKeyFlowControlSettings limits the outstanding messages and
+	// bytes of each ordering key separately, in addition to the limits of
+	// FlowControlSettings, so that a slow key cannot use up the whole
+	// topic's allowance. Messages without an ordering key are not affected.
+	// It is disabled by default.
+	OrderingKeyFlowControlSettings FlowControlSettings
+
+	// OrderingKeyResumePolicy controls what happens when publishing for an
+	// ordering key is paused because of an error. By default, the key stays
+	// paused until ResumePublish is called.
+	OrderingKeyResumePolicy OrderingKeyResumePolicy
+
+	// EnableCompression enables gzip compression of publish requests, which
+	// reduces network usage for compressible payloads, such as JSON, at the
+	// cost of CPU. The compression ratio achieved is recorded in
+	// PublishCompressionRatio.
+	EnableCompression bool
+
+	// CompressionBytesThreshold is the size in bytes above which publish
+	// requests are compressed, when EnableCompression is true. Smaller
+	// requests are sent uncompressed, since compressing them gains little.
+	//
+	// Defaults to DefaultPublishSettings.CompressionBytesThreshold.
+	CompressionBytesThreshold int
 }
 
 // DefaultPublishSettings holds the default values for topics' PublishSettings.
@@ -134,6 +177,7 @@ var DefaultPublishSettings = PublishSettings{
 		MaxOutstandingBytes:    -1,
 		LimitExceededBehavior:  FlowControlIgnore,
 	},
+	CompressionBytesThreshold: 240,
 }
 
 // CreateTopic creates a new topic.
@@ -164,6 +208,9 @@ func (c *Client) CreateTopic(ctx context.Context, topicID string) (*Topic, error
 //
 // If the topic already exists, an error will be returned.
 func (c *Client) CreateTopicWithConfig(ctx context.Context, topicID string, tc *TopicConfig) (*Topic, error) {
+	if err := tc.validate(); err != nil {
+		return nil, err
+	}
 	t := c.Topic(topicID)
 	topic := tc.toProto()
 	topic.Name = t.name
@@ -255,6 +302,26 @@ func (t *TopicConfig) ID() string {
 	return t.name[slash+1:]
 }
 
+// Bounds of a topic's message retention duration.
+const (
+	minTopicRetentionDuration = 10 * time.Minute
+	maxTopicRetentionDuration = 7 * 24 * time.Hour
+)
+
+func checkTopicRetentionDuration(d time.Duration) error {
+	if d < minTopicRetentionDuration || d > maxTopicRetentionDuration {
+		return fmt.Errorf("pubsub: topic RetentionDuration %v must be between %v and %v", d, minTopicRetentionDuration, maxTopicRetentionDuration)
+	}
+	return nil
+}
+
+func (tc *TopicConfig) validate() error {
+	if tc == nil || tc.RetentionDuration == nil {
+		return nil
+	}
+	return checkTopicRetentionDuration(optional.ToDuration(tc.RetentionDuration))
+}
+
 func (tc *TopicConfig) toProto() *pb.Topic {
 	var retDur *durationpb.Duration
 	if tc.RetentionDuration != nil {
@@ -294,6 +361,16 @@ type TopicConfigToUpdate struct {
 	RetentionDuration optional.Duration
 }
 
+func (cfg *TopicConfigToUpdate) validate() error {
+	if cfg.RetentionDuration == nil {
+		return nil
+	}
+	if r := optional.ToDuration(cfg.RetentionDuration); r >= 0 {
+		return checkTopicRetentionDuration(r)
+	}
+	return nil
+}
+
 func protoToTopicConfig(pbt *pb.Topic) TopicConfig {
 	tc := TopicConfig{
 		name:                 pbt.Name,
@@ -372,6 +449,9 @@ func (t *Topic) Config(ctx context.Context) (TopicConfig, error) {
 // Update changes an existing topic according to the fields set in cfg. It returns
 // the new TopicConfig.
 func (t *Topic) Update(ctx context.Context, cfg TopicConfigToUpdate) (TopicConfig, error) {
+	if err := cfg.validate(); err != nil {
+		return TopicConfig{}, err
+	}
 	req := t.updateRequest(cfg)
 	if len(req.UpdateMask.Paths) == 0 {
 		return TopicConfig{}, errors.New("pubsub: UpdateTopic call with nothing to update")
@@ -528,7 +608,16 @@ type PublishResult = ipubsub.PublishResult
 // Publish creates goroutines for batching and sending messages. These goroutines
 // need to be stopped by calling t.Stop(). Once stopped, future calls to Publish
 // will immediately return a PublishResult with an error.
+//
+// The client's PublishInterceptors are called before the message is batched.
 func (t *Topic) Publish(ctx context.Context, msg *Message) *PublishResult {
+	if len(t.c.publishInterceptors) == 0 {
+		return t.publish(ctx, msg)
+	}
+	return chainPublishInterceptors(t.c.publishInterceptors, t.publish)(ctx, msg)
+}
+
+func (t *Topic) publish(ctx context.Context, msg *Message) *PublishResult {
 	ctx, err := tag.New(ctx, tag.Insert(keyStatus, "OK"), tag.Upsert(keyTopic, t.name))
 	if err != nil {
 		log.Printf("pubsub: cannot create context with tag in Publish: %v", err)
@@ -539,6 +628,7 @@ func (t *Topic) Publish(ctx context.Context, msg *Message) *PublishResult {
 		ipubsub.SetPublishResult(r, "", errors.New("Topic.EnableMessageOrdering=false, but an OrderingKey was set in Message. Please remove the OrderingKey or turn on Topic.EnableMessageOrdering"))
 		return r
 	}
+	msg, span := startCreateSpan(ctx, t.ID(), msg)
 
 	// Calculate the size of the encoded proto message by accounting
 	// for the length of an individual PubSubMessage and Data/Attributes field.
@@ -554,19 +644,37 @@ func (t *Topic) Publish(ctx context.Context, msg *Message) *PublishResult {
 	// TODO(aboulhosn) [from bcmills] consider changing the semantics of bundler to perform this logic so we don't have to do it here
 	if t.stopped {
 		ipubsub.SetPublishResult(r, "", errTopicStopped)
+		endCreateSpan(span, "", errTopicStopped)
 		return r
 	}
 
+	// Acquire the limits of the ordering key first, so that a message that
+	// waits for its key doesn't hold capacity that other keys could use.
+	if err := t.keyFlowControllers.acquire(ctx, msg.OrderingKey, msgSize); err != nil {
+		t.pauseOrderingKey(msg.OrderingKey, err)
+		ipubsub.SetPublishResult(r, "", err)
+		endCreateSpan(span, "", err)
+		return r
+	}
 	if err := t.flowController.acquire(ctx, msgSize); err != nil {
-		t.scheduler.Pause(msg.OrderingKey)
+		t.keyFlowControllers.release(ctx, msg.OrderingKey, msgSize)
+		t.pauseOrderingKey(msg.OrderingKey, err)
 		ipubsub.SetPublishResult(r, "", err)
+		endCreateSpan(span, "", err)
 		return r
 	}
-	err = t.scheduler.Add(msg.OrderingKey, &bundledMessage{msg, r, msgSize}, msgSize)
+	bm := &bundledMessage{msg: msg, res: r, size: msgSize, span: span}
+	applyPublishOverrides(ctx, bm)
+	t.outstanding.add(msg.OrderingKey, msgSize)
+	err = t.scheduler.Add(msg.OrderingKey, bm, msgSize)
 	if err != nil {
 		fmt.Printf("got err: %v\n", err)
-		t.scheduler.Pause(msg.OrderingKey)
+		t.outstanding.remove(msg.OrderingKey, msgSize)
+		t.flowController.release(ctx, msgSize)
+		t.keyFlowControllers.release(ctx, msg.OrderingKey, msgSize)
+		t.pauseOrderingKey(msg.OrderingKey, err)
 		ipubsub.SetPublishResult(r, "", err)
+		endCreateSpan(span, "", err)
 	}
 	return r
 }
@@ -597,6 +705,10 @@ type bundledMessage struct {
 	msg  *Message
 	res  *PublishResult
 	size int
+	span trace.Span
+	// deadline and retry hold the message's PublishOverrides, if any.
+	deadline time.Time
+	retry    *PublishRetrySettings
 }
 
 func (t *Topic) initBundler() {
@@ -640,23 +752,16 @@ func (t *Topic) initBundler() {
 	}
 	t.scheduler.BundleByteThreshold = t.PublishSettings.ByteThreshold
 
-	fcs := DefaultPublishSettings.FlowControlSettings
-	fcs.LimitExceededBehavior = t.PublishSettings.FlowControlSettings.LimitExceededBehavior
 	if t.PublishSettings.FlowControlSettings.MaxOutstandingBytes > 0 {
-		b := t.PublishSettings.FlowControlSettings.MaxOutstandingBytes
-		fcs.MaxOutstandingBytes = b
-
 		// If MaxOutstandingBytes is set, disable BufferedByteLimit by setting it to maxint.
 		// This is because there's no way to set "unlimited" for BufferedByteLimit,
 		// and simply setting it to MaxOutstandingBytes occasionally leads to issues where
 		// BufferedByteLimit is reached even though there are resources available.
 		t.PublishSettings.BufferedByteLimit = maxInt
 	}
-	if t.PublishSettings.FlowControlSettings.MaxOutstandingMessages > 0 {
-		fcs.MaxOutstandingMessages = t.PublishSettings.FlowControlSettings.MaxOutstandingMessages
-	}
 
-	t.flowController = newTopicFlowController(fcs)
+	t.flowController = newTopicFlowController(t.flowControlSettings())
+	t.keyFlowControllers = newOrderingKeyFlowControllers(t.PublishSettings.OrderingKeyFlowControlSettings)
 
 	bufferedByteLimit := DefaultPublishSettings.BufferedByteLimit
 	if t.PublishSettings.BufferedByteLimit > 0 {
@@ -669,11 +774,31 @@ func (t *Topic) initBundler() {
 	t.scheduler.BundleByteLimit = MaxPublishRequestBytes - calcFieldSizeString(t.name) - 5
 }
 
+// flowControlSettings returns the settings of the topic's flow controller:
+// the topic's FlowControlSettings, with defaults for the unset limits.
+func (t *Topic) flowControlSettings() FlowControlSettings {
+	fcs := DefaultPublishSettings.FlowControlSettings
+	fcs.LimitExceededBehavior = t.PublishSettings.FlowControlSettings.LimitExceededBehavior
+	if t.PublishSettings.FlowControlSettings.MaxOutstandingBytes > 0 {
+		fcs.MaxOutstandingBytes = t.PublishSettings.FlowControlSettings.MaxOutstandingBytes
+	}
+	if t.PublishSettings.FlowControlSettings.MaxOutstandingMessages > 0 {
+		fcs.MaxOutstandingMessages = t.PublishSettings.FlowControlSettings.MaxOutstandingMessages
+	}
+	return fcs
+}
+
 func (t *Topic) publishMessageBundle(ctx context.Context, bms []*bundledMessage) {
 	ctx, err := tag.New(ctx, tag.Insert(keyStatus, "OK"), tag.Upsert(keyTopic, t.name))
 	if err != nil {
 		log.Printf("pubsub: cannot create context with tag in publishMessageBundle: %v", err)
 	}
+	deadline, retry := t.bundleCallSettings(bms)
+	if !deadline.IsZero() {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithDeadline(ctx, deadline)
+		defer cancel()
+	}
 	pbMsgs := make([]*pb.PubsubMessage, len(bms))
 	var orderingKey string
 	for i, bm := range bms {
@@ -685,6 +810,8 @@ func (t *Topic) publishMessageBundle(ctx context.Context, bms []*bundledMessage)
 		}
 		bm.msg = nil // release bm.msg for GC
 	}
+	ctx, span := startPublishSpan(ctx, t.ID(), bms)
+	defer span.End()
 	var res *pb.PublishResponse
 	start := time.Now()
 	if orderingKey != "" && t.scheduler.IsPaused(orderingKey) {
@@ -692,21 +819,31 @@ func (t *Topic) publishMessageBundle(ctx context.Context, bms []*bundledMessage)
 	} else {
 		// Apply custom publish retryer on top of user specified retryer and
 		// default retryer.
-		opts := t.c.pubc.CallOptions.Publish
-		var settings gax.CallSettings
-		for _, opt := range opts {
-			opt.Resolve(&settings)
+		var defaultRetryer gax.Retryer
+		if retry != nil {
+			defaultRetryer = retry.retryer()
+		} else {
+			opts := t.c.pubc.CallOptions.Publish
+			var settings gax.CallSettings
+			for _, opt := range opts {
+				opt.Resolve(&settings)
+			}
+			defaultRetryer = settings.Retry()
 		}
-		r := &publishRetryer{defaultRetryer: settings.Retry()}
-		res, err = t.c.pubc.Publish(ctx, &pb.PublishRequest{
+		r := &publishRetryer{defaultRetryer: defaultRetryer}
+		req := &pb.PublishRequest{
 			Topic:    t.name,
 			Messages: pbMsgs,
-		}, gax.WithGRPCOptions(grpc.MaxCallSendMsgSize(maxSendRecvBytes)),
+		}
+		cctx, copts := t.compressionCallOptions(ctx, proto.Size(req))
+		res, err = t.c.publisherClient(orderingKey).Publish(cctx, req,
+			gax.WithGRPCOptions(append(copts, grpc.MaxCallSendMsgSize(maxSendRecvBytes))...),
 			gax.WithRetry(func() gax.Retryer { return r }))
 	}
 	end := time.Now()
 	if err != nil {
-		t.scheduler.Pause(orderingKey)
+		t.pauseOrderingKey(orderingKey, err)
+		setSpanError(span, err)
 		// Update context with error tag for OpenCensus,
 		// using same stats.Record() call as success case.
 		ctx, _ = tag.New(ctx, tag.Upsert(keyStatus, "ERROR"),
@@ -717,10 +854,14 @@ func (t *Topic) publishMessageBundle(ctx context.Context, bms []*bundledMessage)
 		PublishedMessages.M(int64(len(bms))))
 	for i, bm := range bms {
 		t.flowController.release(ctx, bm.size)
+		t.keyFlowControllers.release(ctx, orderingKey, bm.size)
+		t.outstanding.remove(orderingKey, bm.size)
 		if err != nil {
 			ipubsub.SetPublishResult(bm.res, "", err)
+			endCreateSpan(bm.span, "", err)
 		} else {
 			ipubsub.SetPublishResult(bm.res, res.MessageIds[i], nil)
+			endCreateSpan(bm.span, res.MessageIds[i], nil)
 		}
 	}
 }
@@ -737,5 +878,6 @@ func (t *Topic) ResumePublish(orderingKey string) {
 		return
 	}
 
+	t.stopResumeTimer(orderingKey)
 	t.scheduler.Resume(orderingKey)
 }
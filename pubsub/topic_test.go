@@ -26,6 +26,7 @@ import (
 	"cloud.google.com/go/internal/testutil"
 	"cloud.google.com/go/pubsub/pstest"
 	"github.com/google/go-cmp/cmp/cmpopts"
+	gax "github.com/googleapis/gax-go/v2"
 	"google.golang.org/api/iterator"
 	"google.golang.org/api/option"
 	"google.golang.org/api/support/bundler"
@@ -331,6 +332,37 @@ func mustCreateTopicWithConfig(t *testing.T, c *Client, id string, tc *TopicConf
 	return topic
 }
 
+func TestUpdateTopic_RetentionDuration(t *testing.T) {
+	ctx := context.Background()
+	client, srv := newFake(t)
+	defer client.Close()
+	defer srv.Close()
+
+	if _, err := client.CreateTopicWithConfig(ctx, "bad", &TopicConfig{RetentionDuration: time.Minute}); err == nil {
+		t.Error("CreateTopicWithConfig with a one minute retention: got nil, want error")
+	}
+	topic := mustCreateTopic(t, client, "T")
+	for _, d := range []time.Duration{time.Minute, 8 * 24 * time.Hour} {
+		if _, err := topic.Update(ctx, TopicConfigToUpdate{RetentionDuration: d}); err == nil {
+			t.Errorf("Update with retention %v: got nil, want error", d)
+		}
+	}
+	config, err := topic.Update(ctx, TopicConfigToUpdate{RetentionDuration: 2 * time.Hour})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := config.RetentionDuration, 2*time.Hour; got != want {
+		t.Errorf("got retention %v, want %v", got, want)
+	}
+	config, err = topic.Update(ctx, TopicConfigToUpdate{RetentionDuration: -1 * time.Second})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if config.RetentionDuration != nil {
+		t.Errorf("got retention %v, want nil", config.RetentionDuration)
+	}
+}
+
 func TestDetachSubscription(t *testing.T) {
 	ctx := context.Background()
 	c, srv := newFake(t)
@@ -516,6 +548,135 @@ func TestPublishFlowControl_SignalErrorOrderingKey(t *testing.T) {
 	}
 }
 
+func TestPublishOrderingKeyFlowControl(t *testing.T) {
+	ctx := context.Background()
+	c, srv := newFake(t)
+	defer c.Close()
+	defer srv.Close()
+
+	topic, err := c.CreateTopic(ctx, "some-topic")
+	if err != nil {
+		t.Fatal(err)
+	}
+	topic.PublishSettings.FlowControlSettings = FlowControlSettings{
+		MaxOutstandingMessages: 2,
+		LimitExceededBehavior:  FlowControlBlock,
+	}
+	topic.PublishSettings.OrderingKeyFlowControlSettings = FlowControlSettings{
+		MaxOutstandingMessages: 1,
+		LimitExceededBehavior:  FlowControlBlock,
+	}
+	topic.PublishSettings.CountThreshold = 1
+	topic.EnableMessageOrdering = true
+
+	srv.SetAutoPublishResponse(false)
+
+	ra := publishSingleMessageWithKey(ctx, topic, "AA", "a")
+	// The second message for key "a" blocks until the first is published.
+	ra2Published := make(chan *PublishResult)
+	go func() {
+		r := publishSingleMessageWithKey(ctx, topic, "AA", "a")
+		select {
+		case <-ra.Ready():
+		default:
+			t.Error("second message for key was accepted before the first was published")
+		}
+		ra2Published <- r
+	}()
+	select {
+	case <-ra2Published:
+		t.Fatal("second message for key was accepted while the first was outstanding")
+	case <-time.After(50 * time.Millisecond):
+	}
+	// Other keys have their own limits, and the message waiting for key "a"
+	// doesn't hold topic-wide capacity.
+	rbPublished := make(chan *PublishResult)
+	go func() { rbPublished <- publishSingleMessageWithKey(ctx, topic, "AA", "b") }()
+	var rb *PublishResult
+	select {
+	case rb = <-rbPublished:
+	case <-time.After(5 * time.Second):
+		t.Error("message for another key was blocked by the topic-wide limit")
+	}
+
+	addSingleResponse(srv, "1")
+	addSingleResponse(srv, "2")
+	addSingleResponse(srv, "3")
+	if rb == nil {
+		rb = <-rbPublished
+	}
+	for _, r := range []*PublishResult{ra, rb, <-ra2Published} {
+		if _, err := r.Get(ctx); err != nil {
+			t.Fatalf("Get() got: %v", err)
+		}
+	}
+	topic.keyFlowControllers.mu.Lock()
+	if n := len(topic.keyFlowControllers.keys); n != 0 {
+		t.Errorf("got %d ordering key flow controllers after publishing, want 0", n)
+	}
+	topic.keyFlowControllers.mu.Unlock()
+}
+
+func TestPublishOrderingKeyResumePolicy(t *testing.T) {
+	ctx := context.Background()
+	c, srv := newFake(t)
+	defer c.Close()
+	defer srv.Close()
+
+	topic, err := c.CreateTopic(ctx, "some-topic")
+	if err != nil {
+		t.Fatal(err)
+	}
+	topic.PublishSettings.FlowControlSettings = FlowControlSettings{
+		MaxOutstandingBytes:   10,
+		LimitExceededBehavior: FlowControlSignalError,
+	}
+	var (
+		mu     sync.Mutex
+		paused []string
+	)
+	topic.PublishSettings.OrderingKeyResumePolicy = OrderingKeyResumePolicy{
+		ResumeAfter: 50 * time.Millisecond,
+		OnPause: func(key string, err error) {
+			mu.Lock()
+			defer mu.Unlock()
+			if err != ErrFlowControllerMaxOutstandingBytes {
+				t.Errorf("OnPause(%q) got err %v, want %v", key, err, ErrFlowControllerMaxOutstandingBytes)
+			}
+			paused = append(paused, key)
+		},
+	}
+	topic.PublishSettings.CountThreshold = 1
+	topic.EnableMessageOrdering = true
+
+	r1 := publishSingleMessageWithKey(ctx, topic, "AAAAAAAAAAA", "a")
+	if _, err := r1.Get(ctx); err != ErrFlowControllerMaxOutstandingBytes {
+		t.Fatalf("r1.Get() got: %v, want %v", err, ErrFlowControllerMaxOutstandingBytes)
+	}
+	// Messages fail while the key is paused, without pausing it again.
+	r2 := publishSingleMessageWithKey(ctx, topic, "AAAA", "a")
+	if _, err := r2.Get(ctx); err == nil {
+		t.Fatal("r2.Get() got nil instead of error while the key is paused")
+	}
+	mu.Lock()
+	if diff := testutil.Diff(paused, []string{"a"}); diff != "" {
+		t.Errorf("paused keys: -got, +want:\n%s", diff)
+	}
+	mu.Unlock()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for topic.scheduler.IsPaused("a") {
+		if time.Now().After(deadline) {
+			t.Fatal("ordering key was not resumed")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	r3 := publishSingleMessageWithKey(ctx, topic, "AAAA", "a")
+	if _, err := r3.Get(ctx); err != nil {
+		t.Fatalf("r3.Get() after resume got: %v", err)
+	}
+}
+
 func TestPublishFlowControl_Block(t *testing.T) {
 	ctx := context.Background()
 	c, srv := newFake(t)
@@ -631,3 +792,76 @@ func addSingleResponse(srv *pstest.Server, id string) {
 		MessageIds: []string{id},
 	}, nil)
 }
+
+func TestPublishRetryOverrides(t *testing.T) {
+	ctx := context.Background()
+	client, srv := newFake(t)
+	defer client.Close()
+	defer srv.Close()
+
+	topic := mustCreateTopic(t, client, "t")
+	defer topic.Stop()
+	srv.SetAutoPublishResponse(false)
+
+	// An error with a code that is retried by default.
+	srv.AddPublishResponse(nil, status.Error(codes.Unavailable, "unavailable"))
+	srv.AddPublishResponse(&pb.PublishResponse{MessageIds: []string{"1"}}, nil)
+	if _, err := topic.Publish(ctx, &Message{Data: []byte("a")}).Get(ctx); err != nil {
+		t.Errorf("default retry: got %v, want success", err)
+	}
+
+	// Disable retries for the topic.
+	topic.PublishSettings.Retry = &PublishRetrySettings{Codes: []codes.Code{}}
+	srv.AddPublishResponse(nil, status.Error(codes.Unavailable, "unavailable"))
+	_, err := topic.Publish(ctx, &Message{Data: []byte("b")}).Get(ctx)
+	if status.Code(err) != codes.Unavailable {
+		t.Errorf("retries disabled: got %v, want code Unavailable", err)
+	}
+
+	// Retry again for a single message.
+	srv.AddPublishResponse(nil, status.Error(codes.Unavailable, "unavailable"))
+	srv.AddPublishResponse(&pb.PublishResponse{MessageIds: []string{"2"}}, nil)
+	octx := WithPublishOverrides(ctx, PublishOverrides{Retry: &PublishRetrySettings{
+		Codes:   []codes.Code{codes.Unavailable},
+		Backoff: gax.Backoff{Initial: time.Millisecond},
+	}})
+	if _, err := topic.Publish(octx, &Message{Data: []byte("c")}).Get(ctx); err != nil {
+		t.Errorf("retry override: got %v, want success", err)
+	}
+
+	// Bound a single message's publish time while the server doesn't respond.
+	octx = WithPublishOverrides(ctx, PublishOverrides{Timeout: 100 * time.Millisecond})
+	start := time.Now()
+	_, err = topic.Publish(octx, &Message{Data: []byte("d")}).Get(ctx)
+	if status.Code(err) != codes.DeadlineExceeded {
+		t.Errorf("timeout override: got %v, want code DeadlineExceeded", err)
+	}
+	if d := time.Since(start); d > 5*time.Second {
+		t.Errorf("timeout override: publish took %v", d)
+	}
+	// Unblock the server.
+	srv.AddPublishResponse(&pb.PublishResponse{MessageIds: []string{"3"}}, nil)
+}
+
+func TestBundleCallSettings(t *testing.T) {
+	topicRetry := &PublishRetrySettings{}
+	msgRetry := &PublishRetrySettings{}
+	early := time.Now().Add(time.Second)
+	late := early.Add(time.Second)
+	topic := &Topic{PublishSettings: PublishSettings{Retry: topicRetry}}
+
+	deadline, retry := topic.bundleCallSettings([]*bundledMessage{{}, {deadline: late}, {deadline: early, retry: msgRetry}})
+	if !deadline.Equal(early) {
+		t.Errorf("got deadline %v, want %v", deadline, early)
+	}
+	if retry != msgRetry {
+		t.Error("got topic retry settings, want message retry settings")
+	}
+	deadline, retry = topic.bundleCallSettings([]*bundledMessage{{}})
+	if !deadline.IsZero() {
+		t.Errorf("got deadline %v, want none", deadline)
+	}
+	if retry != topicRetry {
+		t.Error("got message retry settings, want topic retry settings")
+	}
+}
@@ -100,6 +100,11 @@ var (
 	// PublisherOutstandingBytes is a measure of the number of bytes all outstanding publish messages held by the client take up.
 	// It is EXPERIMENTAL and subject to change or removal without notice.
 	PublisherOutstandingBytes = stats.Int64(statsPrefix+"publisher_outstanding_bytes", "Number of outstanding publish bytes", stats.UnitDimensionless)
+
+	// PublishCompressionRatio is a measure of the ratio of the uncompressed to the compressed size of
+	// compressed publish requests. See PublishSettings.EnableCompression.
+	// It is EXPERIMENTAL and subject to change or removal without notice.
+	PublishCompressionRatio = stats.Float64(statsPrefix+"publish_compression_ratio", "Ratio of uncompressed to compressed size of publish requests", stats.UnitDimensionless)
 )
 
 var (
@@ -162,6 +167,10 @@ var (
 	// PublisherOutstandingBytesView is the last value of OutstandingBytes
 	// It is EXPERIMENTAL and subject to change or removal without notice.
 	PublisherOutstandingBytesView *view.View
+
+	// PublishCompressionRatioView is a distribution of PublishCompressionRatio.
+	// It is EXPERIMENTAL and subject to change or removal without notice.
+	PublishCompressionRatioView *view.View
 )
 
 func init() {
@@ -169,6 +178,13 @@ func init() {
 	PublishLatencyView = createDistView(PublishLatency, keyTopic, keyStatus, keyError)
 	PublisherOutstandingMessagesView = createLastValueView(PublisherOutstandingMessages, keyTopic)
 	PublisherOutstandingBytesView = createLastValueView(PublisherOutstandingBytes, keyTopic)
+	PublishCompressionRatioView = &view.View{
+		Name:        PublishCompressionRatio.Name(),
+		Description: PublishCompressionRatio.Description(),
+		TagKeys:     []tag.Key{keyTopic},
+		Measure:     PublishCompressionRatio,
+		Aggregation: view.Distribution(1, 1.5, 2, 3, 4, 6, 8, 12, 16, 32),
+	}
 	PullCountView = createCountView(PullCount, keySubscription)
 	AckCountView = createCountView(AckCount, keySubscription)
 	NackCountView = createCountView(NackCount, keySubscription)
@@ -186,6 +202,7 @@ func init() {
 		PublishLatencyView,
 		PublisherOutstandingMessagesView,
 		PublisherOutstandingBytesView,
+		PublishCompressionRatioView,
 	}
 
 	DefaultSubscribeViews = []*view.View{
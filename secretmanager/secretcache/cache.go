@@ -0,0 +1,255 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package secretcache provides a cache of Secret Manager secret versions on
+// top of the client in cloud.google.com/go/secretmanager/apiv1.
+package secretcache
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+
+	secretmanager "cloud.google.com/go/secretmanager/apiv1"
+	secretmanagerpb "google.golang.org/genproto/googleapis/cloud/secretmanager/v1"
+)
+
+// defaultTTL is the default of Options.TTL.
+const defaultTTL = 5 * time.Minute
+
+// Options configures a Cache.
+type Options struct {
+	// TTL is how long Get returns a version alias, such as "latest", from the
+	// cache before accessing it again. The default is five minutes. Versions
+	// referred to by number never change, so they are cached until the cache
+	// is closed.
+	TTL time.Duration
+
+	// RefreshInterval, if positive, is the interval at which the cache
+	// accesses the cached version aliases in the background, so that Get
+	// rarely has to wait for them. It should be less than TTL.
+	RefreshInterval time.Duration
+
+	// OnRotate, if not nil, is called when a version alias that was cached
+	// is found to refer to a new version, with the name of the alias and the
+	// response of accessing the new version.
+	OnRotate func(name string, resp *secretmanagerpb.AccessSecretVersionResponse)
+
+	// OnError, if not nil, is called with the errors of background
+	// refreshes. Get keeps returning the cached payload of an alias whose
+	// refresh fails until its TTL expires.
+	OnError func(err error)
+}
+
+// A Cache accesses secret versions and caches their payloads. It is safe for
+// concurrent use. Call Close when done with it.
+type Cache struct {
+	client *secretmanager.Client
+	opts   Options
+	now    func() time.Time // for testing
+
+	ctx    context.Context // done when the cache is closed
+	cancel func()
+	wg     sync.WaitGroup
+
+	mu      sync.Mutex
+	entries map[string]*entry
+}
+
+type entry struct {
+	resp    *secretmanagerpb.AccessSecretVersionResponse // nil until fetched
+	fetched time.Time
+
+	// gen is incremented when the entry is invalidated, so that accesses
+	// started before then don't store their responses.
+	gen uint64
+
+	// call is the access of the version in progress, if any, which
+	// concurrent calls to Get wait for rather than accessing it again.
+	call *call
+}
+
+type call struct {
+	done chan struct{} // closed when resp and err are set
+	resp *secretmanagerpb.AccessSecretVersionResponse
+	err  error
+}
+
+// New returns a Cache that accesses secret versions with client. opts may be
+// nil.
+func New(client *secretmanager.Client, opts *Options) *Cache {
+	c := &Cache{
+		client:  client,
+		now:     time.Now,
+		entries: make(map[string]*entry),
+	}
+	if opts != nil {
+		c.opts = *opts
+	}
+	if c.opts.TTL <= 0 {
+		c.opts.TTL = defaultTTL
+	}
+	c.ctx, c.cancel = context.WithCancel(context.Background())
+	if c.opts.RefreshInterval > 0 {
+		c.wg.Add(1)
+		go c.refreshLoop()
+	}
+	return c
+}
+
+// Get returns the response of accessing the secret version with the given
+// name, of the form projects/*/secrets/*/versions/*, from the cache if
+// possible. The version may be a number or an alias such as "latest". The
+// returned response is shared and must not be modified.
+func (c *Cache) Get(ctx context.Context, name string) (*secretmanagerpb.AccessSecretVersionResponse, error) {
+	c.mu.Lock()
+	if e := c.entries[name]; e != nil && e.resp != nil && (isPinnedVersion(name) || c.now().Sub(e.fetched) < c.opts.TTL) {
+		c.mu.Unlock()
+		return e.resp, nil
+	}
+	c.mu.Unlock()
+	return c.fetch(ctx, name)
+}
+
+// Payload returns the payload data of the secret version with the given name,
+// as Get does.
+func (c *Cache) Payload(ctx context.Context, name string) ([]byte, error) {
+	resp, err := c.Get(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	return resp.GetPayload().GetData(), nil
+}
+
+// Invalidate removes the secret version with the given name from the cache.
+// Accesses of the version that are in progress don't add it back.
+func (c *Cache) Invalidate(name string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if e := c.entries[name]; e != nil {
+		e.gen++
+		delete(c.entries, name)
+	}
+}
+
+// Close stops the background refreshes and waits for them to finish.
+func (c *Cache) Close() {
+	c.cancel()
+	c.wg.Wait()
+}
+
+// fetch accesses the secret version with the given name and caches the
+// response, calling OnRotate if it refers to a new version. If the version is
+// already being accessed, fetch waits for that access instead. If ctx is done
+// first, fetch returns ctx.Err(), but the access continues for the other
+// callers waiting for it.
+func (c *Cache) fetch(ctx context.Context, name string) (*secretmanagerpb.AccessSecretVersionResponse, error) {
+	c.mu.Lock()
+	e := c.entries[name]
+	if e == nil {
+		e = &entry{}
+		c.entries[name] = e
+	}
+	cl := e.call
+	if cl == nil {
+		cl = &call{done: make(chan struct{})}
+		e.call = cl
+		go c.access(name, e, e.gen, cl)
+	}
+	c.mu.Unlock()
+	select {
+	case <-cl.done:
+		return cl.resp, cl.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// access performs cl, the access of the secret version with the given name
+// for entry e. It uses the context of the cache rather than that of the
+// caller that started it, so that the access isn't canceled while other
+// callers wait for it, and ends when the cache is closed.
+func (c *Cache) access(name string, e *entry, gen uint64, cl *call) {
+	defer close(cl.done)
+	cl.resp, cl.err = c.client.AccessSecretVersion(c.ctx, &secretmanagerpb.AccessSecretVersionRequest{Name: name})
+	c.mu.Lock()
+	e.call = nil
+	var old *secretmanagerpb.AccessSecretVersionResponse
+	stored := cl.err == nil && e.gen == gen
+	if stored {
+		old = e.resp
+		e.resp, e.fetched = cl.resp, c.now()
+	} else if e.resp == nil && c.entries[name] == e {
+		// Don't keep entries for versions that were never accessed
+		// successfully, such as versions that don't exist.
+		delete(c.entries, name)
+	}
+	c.mu.Unlock()
+
+	if stored && old != nil && old.GetName() != cl.resp.GetName() && c.opts.OnRotate != nil {
+		c.opts.OnRotate(name, cl.resp)
+	}
+}
+
+func (c *Cache) refreshLoop() {
+	defer c.wg.Done()
+	ticker := time.NewTicker(c.opts.RefreshInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-c.ctx.Done():
+			return
+		case <-ticker.C:
+			c.refresh()
+		}
+	}
+}
+
+// refresh accesses all cached version aliases again.
+func (c *Cache) refresh() {
+	var names []string
+	c.mu.Lock()
+	for name, e := range c.entries {
+		if e.resp != nil && !isPinnedVersion(name) {
+			names = append(names, name)
+		}
+	}
+	c.mu.Unlock()
+	for _, name := range names {
+		if _, err := c.fetch(c.ctx, name); err != nil {
+			if c.ctx.Err() != nil {
+				return
+			}
+			if c.opts.OnError != nil {
+				c.opts.OnError(err)
+			}
+		}
+	}
+}
+
+// isPinnedVersion reports whether the secret version name refers to a version
+// by number, rather than by an alias whose version can change.
+func isPinnedVersion(name string) bool {
+	v := name[strings.LastIndex(name, "/")+1:]
+	if v == "" {
+		return false
+	}
+	for _, r := range v {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
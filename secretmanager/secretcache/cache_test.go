@@ -0,0 +1,306 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package secretcache
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	secretmanager "cloud.google.com/go/secretmanager/apiv1"
+	"google.golang.org/api/option"
+	secretmanagerpb "google.golang.org/genproto/googleapis/cloud/secretmanager/v1"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// fakeSecretServer serves versions of a single secret, whose latest version
+// is the last one.
+type fakeSecretServer struct {
+	secretmanagerpb.UnimplementedSecretManagerServiceServer
+
+	// If block is not nil, accesses send on started, if it has room, and
+	// wait for block to be closed before responding.
+	block   chan struct{}
+	started chan struct{}
+
+	mu       sync.Mutex
+	versions []string // payloads of versions 1, 2, ...
+	accesses int
+}
+
+func (s *fakeSecretServer) AccessSecretVersion(_ context.Context, req *secretmanagerpb.AccessSecretVersionRequest) (*secretmanagerpb.AccessSecretVersionResponse, error) {
+	resp, err := s.access(req.Name)
+	if s.block != nil {
+		select {
+		case s.started <- struct{}{}:
+		default:
+		}
+		<-s.block
+	}
+	return resp, err
+}
+
+func (s *fakeSecretServer) access(name string) (*secretmanagerpb.AccessSecretVersionResponse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.accesses++
+	secret := name[:strings.LastIndex(name, "/")+1]
+	v := name[len(secret):]
+	n := len(s.versions)
+	if v != "latest" {
+		n = 0
+		for _, r := range v {
+			n = n*10 + int(r-'0')
+		}
+	}
+	if n < 1 || n > len(s.versions) {
+		return nil, status.Errorf(codes.NotFound, "version %s not found", name)
+	}
+	return &secretmanagerpb.AccessSecretVersionResponse{
+		Name:    fmt.Sprintf("%s%d", secret, n),
+		Payload: &secretmanagerpb.SecretPayload{Data: []byte(s.versions[n-1])},
+	}, nil
+}
+
+func (s *fakeSecretServer) addVersion(payload string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.versions = append(s.versions, payload)
+}
+
+func (s *fakeSecretServer) numAccesses() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.accesses
+}
+
+func newFakeSecretClient(t *testing.T, s *fakeSecretServer) *secretmanager.Client {
+	srv := grpc.NewServer()
+	secretmanagerpb.RegisterSecretManagerServiceServer(srv, s)
+	lis, err := net.Listen("tcp", "localhost:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	go srv.Serve(lis)
+	t.Cleanup(srv.Stop)
+	client, err := secretmanager.NewClient(context.Background(),
+		option.WithEndpoint(lis.Addr().String()),
+		option.WithoutAuthentication(),
+		option.WithGRPCDialOption(grpc.WithInsecure()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { client.Close() })
+	return client
+}
+
+func TestGet(t *testing.T) {
+	ctx := context.Background()
+	s := &fakeSecretServer{versions: []string{"one"}}
+	cache := New(newFakeSecretClient(t, s), &Options{TTL: time.Minute})
+	defer cache.Close()
+	now := time.Unix(0, 0)
+	cache.now = func() time.Time { return now }
+
+	const latest = "projects/p/secrets/s/versions/latest"
+	check := func(name, want string, wantAccesses int) {
+		t.Helper()
+		got, err := cache.Payload(ctx, name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(got) != want {
+			t.Errorf("%s: got %q, want %q", name, got, want)
+		}
+		if got := s.numAccesses(); got != wantAccesses {
+			t.Errorf("%s: got %d accesses, want %d", name, got, wantAccesses)
+		}
+	}
+	check(latest, "one", 1)
+	check(latest, "one", 1)
+	check("projects/p/secrets/s/versions/1", "one", 2)
+
+	s.addVersion("two")
+	now = now.Add(time.Minute)
+	check(latest, "two", 3)
+	check("projects/p/secrets/s/versions/1", "one", 3)
+
+	cache.Invalidate(latest)
+	check(latest, "two", 4)
+
+	if _, err := cache.Get(ctx, "projects/p/secrets/s/versions/3"); status.Code(err) != codes.NotFound {
+		t.Errorf("got error %v, want NotFound", err)
+	}
+}
+
+func TestRefresh(t *testing.T) {
+	ctx := context.Background()
+	s := &fakeSecretServer{versions: []string{"one"}}
+	rotated := make(chan string, 1)
+	cache := New(newFakeSecretClient(t, s), &Options{
+		RefreshInterval: time.Millisecond,
+		OnRotate: func(name string, resp *secretmanagerpb.AccessSecretVersionResponse) {
+			select {
+			case rotated <- string(resp.Payload.Data):
+			default:
+			}
+		},
+	})
+	defer cache.Close()
+
+	const latest = "projects/p/secrets/s/versions/latest"
+	if _, err := cache.Get(ctx, latest); err != nil {
+		t.Fatal(err)
+	}
+	s.addVersion("two")
+	select {
+	case got := <-rotated:
+		if got != "two" {
+			t.Errorf("got rotation to %q, want two", got)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for rotation")
+	}
+	got, err := cache.Payload(ctx, latest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != "two" {
+		t.Errorf("got %q, want two", got)
+	}
+}
+
+func TestInvalidateDuringAccess(t *testing.T) {
+	ctx := context.Background()
+	s := &fakeSecretServer{versions: []string{"one"}, block: make(chan struct{}), started: make(chan struct{}, 1)}
+	cache := New(newFakeSecretClient(t, s), nil)
+	defer cache.Close()
+
+	const latest = "projects/p/secrets/s/versions/latest"
+	done := make(chan error)
+	go func() {
+		_, err := cache.Get(ctx, latest)
+		done <- err
+	}()
+	<-s.started
+	// The access in progress responds with version one, which must not be
+	// cached once the version is invalidated.
+	s.addVersion("two")
+	cache.Invalidate(latest)
+	close(s.block)
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+	got, err := cache.Payload(ctx, latest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != "two" {
+		t.Errorf("got %q, want two", got)
+	}
+}
+
+func TestConcurrentGets(t *testing.T) {
+	ctx := context.Background()
+	s := &fakeSecretServer{versions: []string{"one"}, block: make(chan struct{}), started: make(chan struct{}, 1)}
+	cache := New(newFakeSecretClient(t, s), nil)
+	defer cache.Close()
+
+	const latest = "projects/p/secrets/s/versions/latest"
+	var wg sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			got, err := cache.Payload(ctx, latest)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			if string(got) != "one" {
+				t.Errorf("got %q, want one", got)
+			}
+		}()
+	}
+	<-s.started
+	// Give the other calls time to join the access in progress.
+	time.Sleep(10 * time.Millisecond)
+	close(s.block)
+	wg.Wait()
+	if got := s.numAccesses(); got != 1 {
+		t.Errorf("got %d accesses, want 1", got)
+	}
+}
+
+func TestCanceledGetDuringAccess(t *testing.T) {
+	s := &fakeSecretServer{versions: []string{"one"}, block: make(chan struct{}), started: make(chan struct{}, 1)}
+	cache := New(newFakeSecretClient(t, s), nil)
+	defer cache.Close()
+
+	const latest = "projects/p/secrets/s/versions/latest"
+	ctx, cancel := context.WithCancel(context.Background())
+	canceled := make(chan error)
+	go func() {
+		_, err := cache.Get(ctx, latest)
+		canceled <- err
+	}()
+	<-s.started
+	other := make(chan error)
+	go func() {
+		got, err := cache.Payload(context.Background(), latest)
+		if err == nil && string(got) != "one" {
+			err = fmt.Errorf("got %q, want one", got)
+		}
+		other <- err
+	}()
+	// Give the other call time to join the access in progress.
+	time.Sleep(10 * time.Millisecond)
+	cancel()
+	if err := <-canceled; err != context.Canceled {
+		t.Errorf("canceled Get: got error %v, want %v", err, context.Canceled)
+	}
+	// The access started by the canceled call still completes for the other.
+	close(s.block)
+	if err := <-other; err != nil {
+		t.Error(err)
+	}
+	if got := s.numAccesses(); got != 1 {
+		t.Errorf("got %d accesses, want 1", got)
+	}
+}
+
+func TestGetErrorNotCached(t *testing.T) {
+	ctx := context.Background()
+	s := &fakeSecretServer{versions: []string{"one"}}
+	cache := New(newFakeSecretClient(t, s), nil)
+	defer cache.Close()
+
+	for i := 3; i < 6; i++ {
+		if _, err := cache.Get(ctx, fmt.Sprintf("projects/p/secrets/s/versions/%d", i)); status.Code(err) != codes.NotFound {
+			t.Errorf("got error %v, want NotFound", err)
+		}
+	}
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+	if n := len(cache.entries); n != 0 {
+		t.Errorf("got %d cache entries after failed accesses, want 0", n)
+	}
+}
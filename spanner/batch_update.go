@@ -0,0 +1,166 @@
+/*
+Copyright 2022 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package spanner
+
+import (
+	"context"
+	"time"
+
+	"cloud.google.com/go/internal/trace"
+	sppb "google.golang.org/genproto/googleapis/spanner/v1"
+	"google.golang.org/protobuf/proto"
+)
+
+const (
+	// defaultBatchUpdateStatements is the default maximum number of
+	// statements in one group of Client.BatchUpdate.
+	defaultBatchUpdateStatements = 100
+
+	// defaultBatchUpdateBytes is the default maximum size of the statements
+	// in one group of Client.BatchUpdate.
+	defaultBatchUpdateBytes = 1 << 20
+)
+
+// BatchUpdateOptions provides options for Client.BatchUpdate.
+type BatchUpdateOptions struct {
+	// MaxStatements is the maximum number of statements in one group. If
+	// zero, 100 is used.
+	MaxStatements int
+
+	// MaxBytes is the maximum size in bytes of the SQL text and parameters of
+	// the statements in one group. A statement that is larger on its own
+	// forms a group of its own. If zero, 1 MiB is used.
+	MaxBytes int
+
+	// QueryOptions provides the request tag and priority of the batches of
+	// DML statements. Any other options are ignored.
+	QueryOptions QueryOptions
+
+	// TransactionOptions provides options for the transaction of each group.
+	TransactionOptions TransactionOptions
+}
+
+// BatchUpdateResult is the outcome of executing one group of the statements
+// passed to Client.BatchUpdate.
+type BatchUpdateResult struct {
+	// Start and End are the indexes of the first statement of the group, and
+	// of the statement after the last one, in the statements passed to
+	// BatchUpdate.
+	Start, End int
+
+	// RowCounts holds the number of rows that each statement of the group
+	// modified. If Err is not nil, it holds the counts of the statements that
+	// were executed before the statement that failed, if any. These
+	// statements have been rolled back.
+	RowCounts []int64
+
+	// CommitTimestamp is the time at which the group was committed. It is
+	// zero if Err is not nil.
+	CommitTimestamp time.Time
+
+	// Err is the error that prevented the group from being committed, or nil
+	// if the group was committed.
+	Err error
+}
+
+// BatchUpdate executes a large number of DML statements. The statements are
+// split into groups of at most opts.MaxStatements statements and
+// opts.MaxBytes bytes, and each group is executed with one
+// ReadWriteTransaction.BatchUpdate call in a read/write transaction of its
+// own. Groups that are aborted are retried like any read/write transaction.
+//
+// The statements of a group are committed atomically, but each group is
+// committed independently of the others. The groups are executed one after
+// the other, in the order of stmts, and a group that fails does not prevent
+// the following groups from being executed. Use BatchUpdate only for
+// statements that do not have to be committed together.
+//
+// BatchUpdate returns one result per group, in the order of the groups. Use
+// FailedStatements to select the statements to pass to BatchUpdate again.
+func (c *Client) BatchUpdate(ctx context.Context, stmts []Statement, opts BatchUpdateOptions) (results []BatchUpdateResult) {
+	var err error
+	ctx = trace.StartSpan(ctx, "cloud.google.com/go/spanner.ClientBatchUpdate")
+	defer func() { trace.EndSpan(ctx, err) }()
+
+	groups, err := groupStatements(stmts, opts.MaxStatements, opts.MaxBytes)
+	if err != nil {
+		return []BatchUpdateResult{{Start: 0, End: len(stmts), Err: err}}
+	}
+	start := 0
+	for _, g := range groups {
+		r := BatchUpdateResult{Start: start, End: start + len(g)}
+		start = r.End
+		var resp CommitResponse
+		resp, r.Err = c.ReadWriteTransactionWithOptions(ctx, func(ctx context.Context, tx *ReadWriteTransaction) error {
+			var err error
+			r.RowCounts, err = tx.BatchUpdateWithOptions(ctx, g, opts.QueryOptions)
+			return err
+		}, opts.TransactionOptions)
+		if r.Err == nil {
+			r.CommitTimestamp = resp.CommitTs
+		} else if err == nil {
+			err = r.Err
+		}
+		results = append(results, r)
+	}
+	return results
+}
+
+// FailedStatements returns the statements of the groups whose results, as
+// returned by Client.BatchUpdate, have an error. Passing them to BatchUpdate
+// again retries them.
+func FailedStatements(stmts []Statement, results []BatchUpdateResult) []Statement {
+	var failed []Statement
+	for _, r := range results {
+		if r.Err != nil && r.Start >= 0 && r.Start <= r.End && r.End <= len(stmts) {
+			failed = append(failed, stmts[r.Start:r.End]...)
+		}
+	}
+	return failed
+}
+
+// groupStatements splits stmts into groups of at most maxStatements
+// statements and maxBytes bytes.
+func groupStatements(stmts []Statement, maxStatements, maxBytes int) ([][]Statement, error) {
+	if maxStatements <= 0 {
+		maxStatements = defaultBatchUpdateStatements
+	}
+	if maxBytes <= 0 {
+		maxBytes = defaultBatchUpdateBytes
+	}
+	var (
+		groups [][]Statement
+		start  int
+		size   int
+	)
+	for i, st := range stmts {
+		params, paramTypes, err := st.convertParams()
+		if err != nil {
+			return nil, err
+		}
+		n := proto.Size(&sppb.ExecuteBatchDmlRequest_Statement{Sql: st.SQL, Params: params, ParamTypes: paramTypes})
+		if i > start && (i-start >= maxStatements || size+n > maxBytes) {
+			groups = append(groups, stmts[start:i])
+			start, size = i, 0
+		}
+		size += n
+	}
+	if start < len(stmts) {
+		groups = append(groups, stmts[start:])
+	}
+	return groups, nil
+}
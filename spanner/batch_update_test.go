@@ -0,0 +1,111 @@
+/*
+Copyright 2022 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package spanner
+
+import (
+	"context"
+	"testing"
+
+	. "cloud.google.com/go/spanner/internal/testutil"
+	sppb "google.golang.org/genproto/googleapis/spanner/v1"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestGroupStatements(t *testing.T) {
+	stmts := make([]Statement, 5)
+	for i := range stmts {
+		stmts[i] = NewStatement(UpdateBarSetFoo)
+	}
+	for _, test := range []struct {
+		maxStatements, maxBytes int
+		want                    []int
+	}{
+		{0, 0, []int{5}},
+		{2, 0, []int{2, 2, 1}},
+		// Statements that are larger than maxBytes form groups of their own.
+		{0, 1, []int{1, 1, 1, 1, 1}},
+	} {
+		groups, err := groupStatements(stmts, test.maxStatements, test.maxBytes)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var got []int
+		for _, g := range groups {
+			got = append(got, len(g))
+		}
+		if !testEqual(got, test.want) {
+			t.Errorf("max %d statements, %d bytes: got group sizes %v, want %v", test.maxStatements, test.maxBytes, got, test.want)
+		}
+	}
+}
+
+func TestClient_BatchUpdate(t *testing.T) {
+	t.Parallel()
+	server, client, teardown := setupMockedTestServer(t)
+	defer teardown()
+	ctx := context.Background()
+
+	const invalidSQL = "UPDATE FOO SET BAR=1 WHERE BAZ=INVALID"
+	server.TestSpanner.PutStatementResult(invalidSQL, &StatementResult{
+		Type: StatementResultError,
+		Err:  status.Error(codes.InvalidArgument, "invalid statement"),
+	})
+	stmts := []Statement{
+		NewStatement(UpdateBarSetFoo),
+		NewStatement(UpdateBarSetFoo),
+		NewStatement(UpdateBarSetFoo),
+		NewStatement(invalidSQL),
+		NewStatement(UpdateBarSetFoo),
+	}
+	results := client.BatchUpdate(ctx, stmts, BatchUpdateOptions{MaxStatements: 2})
+	if got, want := len(results), 3; got != want {
+		t.Fatalf("got %d results, want %d", got, want)
+	}
+	for i, want := range []BatchUpdateResult{
+		{Start: 0, End: 2, RowCounts: []int64{UpdateBarSetFooRowCount, UpdateBarSetFooRowCount}},
+		{Start: 2, End: 4, RowCounts: []int64{UpdateBarSetFooRowCount}},
+		{Start: 4, End: 5, RowCounts: []int64{UpdateBarSetFooRowCount}},
+	} {
+		got := results[i]
+		if got.Start != want.Start || got.End != want.End || !testEqual(got.RowCounts, want.RowCounts) {
+			t.Errorf("group %d: got %d-%d with counts %v, want %d-%d with counts %v", i, got.Start, got.End, got.RowCounts, want.Start, want.End, want.RowCounts)
+		}
+		if failed := i == 1; (got.Err != nil) != failed || got.CommitTimestamp.IsZero() != failed {
+			t.Errorf("group %d: got error %v and commit timestamp %v, want failed=%v", i, got.Err, got.CommitTimestamp, failed)
+		}
+	}
+	if got, want := ErrCode(results[1].Err), codes.InvalidArgument; got != want {
+		t.Errorf("got error code %v, want %v", got, want)
+	}
+	if got, want := FailedStatements(stmts, results), stmts[2:4]; !testEqual(got, want) {
+		t.Errorf("failed statements: got %v, want %v", got, want)
+	}
+
+	var batches, commits int
+	for _, req := range drainRequestsFromServer(server.TestSpanner) {
+		switch req.(type) {
+		case *sppb.ExecuteBatchDmlRequest:
+			batches++
+		case *sppb.CommitRequest:
+			commits++
+		}
+	}
+	if batches != 3 || commits != 2 {
+		t.Errorf("got %d batches and %d commits, want 3 batches and 2 commits", batches, commits)
+	}
+}
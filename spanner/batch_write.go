@@ -0,0 +1,129 @@
+/*
+Copyright 2022 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package spanner
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"cloud.google.com/go/internal/trace"
+	"google.golang.org/grpc/codes"
+)
+
+// batchWriteConcurrency is the maximum number of mutation groups that
+// BatchWrite commits at the same time.
+const batchWriteConcurrency = 10
+
+// A MutationGroup is a list of mutations that BatchWrite applies atomically.
+type MutationGroup struct {
+	// Mutations are the mutations of the group.
+	Mutations []*Mutation
+}
+
+// BatchWriteResult is the outcome of applying one of the mutation groups
+// passed to BatchWrite.
+type BatchWriteResult struct {
+	// Index is the index of the group in the groups passed to BatchWrite.
+	Index int
+
+	// CommitTimestamp is the time at which the group was committed. It is
+	// zero if Err is not nil.
+	CommitTimestamp time.Time
+
+	// Err is the error that prevented the group from being applied, or nil if
+	// the group was applied.
+	Err error
+}
+
+// BatchWrite applies groups of mutations to the database. The mutations of a
+// group are applied atomically, but each group is applied independently of the
+// others: a group may be applied while another fails, and groups are not
+// applied in any particular order. This makes BatchWrite suited to high
+// throughput ingestion of data that does not need to be written atomically
+// across groups. Mutations that must be applied together should be in the
+// same group.
+//
+// Like Apply with ApplyAtLeastOnce, BatchWrite does not provide replay
+// protection, so a group may be applied more than once. Groups that are
+// aborted are retried; groups that fail for other reasons are reported in the
+// results. The TransactionTag and Priority options apply to the commit of
+// every group, and groups that exceed the limits of ApplyCommitLimits fail
+// with a CommitLimitError; ApplyAtLeastOnce and ApplySplitCommits have no
+// effect.
+//
+// BatchWrite returns one result per group, in the order of groups. Use
+// FailedMutationGroups to select the groups to pass to BatchWrite again.
+func (c *Client) BatchWrite(ctx context.Context, groups []*MutationGroup, opts ...ApplyOption) []BatchWriteResult {
+	ao := c.defaultApplyOption()
+	for _, opt := range opts {
+		opt(ao)
+	}
+
+	var err error
+	ctx = trace.StartSpan(ctx, "cloud.google.com/go/spanner.BatchWrite")
+	defer func() { trace.EndSpan(ctx, err) }()
+
+	results := make([]BatchWriteResult, len(groups))
+	sem := make(chan struct{}, batchWriteConcurrency)
+	var wg sync.WaitGroup
+	for i, g := range groups {
+		results[i].Index = i
+		if g == nil || len(g.Mutations) == 0 {
+			results[i].Err = spannerErrorf(codes.InvalidArgument, "mutation group %d is empty", i)
+			continue
+		}
+		// Groups are atomic, so they are never split.
+		if _, err := splitMutations(g.Mutations, ao.maxCommitMutations, ao.maxCommitBytes, false); err != nil {
+			results[i].Err = err
+			continue
+		}
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+			results[i].Err = ToSpannerError(ctx.Err())
+			continue
+		}
+		wg.Add(1)
+		go func(r *BatchWriteResult, ms []*Mutation) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			t := &writeOnlyTransaction{sp: c.idleSessions, commitPriority: ao.priority, transactionTag: ao.transactionTag, routeToLeader: !c.disableRouteToLeader}
+			r.CommitTimestamp, r.Err = t.applyAtLeastOnce(ctx, ms...)
+		}(&results[i], g.Mutations)
+	}
+	wg.Wait()
+	for _, r := range results {
+		if r.Err != nil {
+			err = r.Err
+			break
+		}
+	}
+	return results
+}
+
+// FailedMutationGroups returns the groups whose results, as returned by
+// BatchWrite, have an error. Passing them to BatchWrite again retries them.
+func FailedMutationGroups(groups []*MutationGroup, results []BatchWriteResult) []*MutationGroup {
+	var failed []*MutationGroup
+	for _, r := range results {
+		if r.Err != nil && r.Index >= 0 && r.Index < len(groups) {
+			failed = append(failed, groups[r.Index])
+		}
+	}
+	return failed
+}
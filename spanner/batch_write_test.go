@@ -0,0 +1,97 @@
+/*
+Copyright 2022 Google LLC
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package spanner
+
+import (
+	"context"
+	"testing"
+
+	. "cloud.google.com/go/spanner/internal/testutil"
+	sppb "google.golang.org/genproto/googleapis/spanner/v1"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestClient_BatchWrite(t *testing.T) {
+	t.Parallel()
+	server, client, teardown := setupMockedTestServer(t)
+	defer teardown()
+
+	groups := make([]*MutationGroup, 5)
+	for i := range groups {
+		groups[i] = &MutationGroup{Mutations: []*Mutation{
+			Insert("Accounts", []string{"AccountId", "Nickname"}, []interface{}{int64(i), "Foo"}),
+		}}
+	}
+	groups = append(groups, &MutationGroup{})
+	// One commit fails permanently, another is aborted and retried.
+	server.TestSpanner.PutExecutionTime(MethodCommitTransaction,
+		SimulatedExecutionTime{
+			Errors: []error{
+				status.Error(codes.InvalidArgument, "invalid"),
+				status.Error(codes.Aborted, "Transaction aborted"),
+			},
+		})
+	results := client.BatchWrite(context.Background(), groups, TransactionTag("batch"))
+	if g, w := len(results), len(groups); g != w {
+		t.Fatalf("result count mismatch\nGot: %v\nWant: %v", g, w)
+	}
+	var failed int
+	for i, r := range results {
+		if r.Index != i {
+			t.Errorf("result %d: index mismatch\nGot: %v\nWant: %v", i, r.Index, i)
+		}
+		if r.Err != nil {
+			failed++
+			if !r.CommitTimestamp.IsZero() {
+				t.Errorf("result %d: got commit timestamp for failed group", i)
+			}
+		} else if r.CommitTimestamp.IsZero() {
+			t.Errorf("result %d: missing commit timestamp", i)
+		}
+	}
+	if g, w := ErrCode(results[len(results)-1].Err), codes.InvalidArgument; g != w {
+		t.Errorf("empty group: error code mismatch\nGot: %v\nWant: %v", g, w)
+	}
+	if g, w := failed, 2; g != w {
+		t.Fatalf("failed group count mismatch\nGot: %v\nWant: %v", g, w)
+	}
+
+	requests := drainRequestsFromServer(server.TestSpanner)
+	var commits int
+	for _, req := range requests {
+		if c, ok := req.(*sppb.CommitRequest); ok {
+			commits++
+			if g, w := c.RequestOptions.TransactionTag, "batch"; g != w {
+				t.Errorf("transaction tag mismatch\nGot: %v\nWant: %v", g, w)
+			}
+		}
+	}
+	if g, w := commits, 6; g != w {
+		t.Errorf("commit count mismatch\nGot: %v\nWant: %v", g, w)
+	}
+
+	// Retry the group that failed on the server.
+	retry := FailedMutationGroups(groups, results)
+	if g, w := len(retry), 2; g != w {
+		t.Fatalf("failed groups mismatch\nGot: %v\nWant: %v", g, w)
+	}
+	results = client.BatchWrite(context.Background(), retry[:1])
+	if results[0].Err != nil {
+		t.Fatalf("retry failed: %v", results[0].Err)
+	}
+}
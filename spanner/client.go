@@ -22,14 +22,17 @@ import (
 	"log"
 	"os"
 	"regexp"
+	"sync"
 	"time"
 
 	"cloud.google.com/go/internal/trace"
 	vkit "cloud.google.com/go/spanner/apiv1"
 	"cloud.google.com/go/spanner/internal"
+	"go.opentelemetry.io/otel/metric"
 	"google.golang.org/api/option"
 	"google.golang.org/api/option/internaloption"
 	gtransport "google.golang.org/api/transport/grpc"
+	adminpb "google.golang.org/genproto/googleapis/spanner/admin/database/v1"
 	sppb "google.golang.org/genproto/googleapis/spanner/v1"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
@@ -41,6 +44,10 @@ const (
 	// the resource being operated on.
 	resourcePrefixHeader = "google-cloud-resource-prefix"
 
+	// routeToLeaderHeader is the name of the metadata header used to indicate
+	// that an RPC should be routed to the leader region of the database.
+	routeToLeaderHeader = "x-goog-spanner-route-to-leader"
+
 	// numChannels is the default value for NumChannels of client.
 	numChannels = 4
 )
@@ -81,7 +88,19 @@ type Client struct {
 	idleSessions *sessionPool
 	logger       *log.Logger
 	qo           QueryOptions
+	ro           ReadOptions
+	txo          TransactionOptions
+	otm          *openTelemetryMetrics
 	ct           *commonTags
+
+	// disableRouteToLeader is the value of ClientConfig.DisableRouteToLeader.
+	disableRouteToLeader bool
+	// onTransactionRetry is the value of ClientConfig.OnTransactionRetry.
+	onTransactionRetry func(context.Context, TransactionRetryInfo)
+
+	// dialect caches the result of DatabaseDialect.
+	dialectMu sync.Mutex
+	dialect   adminpb.DatabaseDialect
 }
 
 // DatabaseName returns the full name of a database, e.g.,
@@ -109,13 +128,62 @@ type ClientConfig struct {
 	// for more info.
 	SessionLabels map[string]string
 
-	// QueryOptions is the configuration for executing a sql query.
+	// QueryOptions is the configuration for executing a sql query. Its
+	// Priority and RequestTag apply to every query and DML statement of the
+	// client unless overridden per call.
 	QueryOptions QueryOptions
 
+	// ReadOptions is the default configuration for reading rows. Options
+	// passed to ReadWithOptions and ReadRowWithOptions take precedence. Set
+	// its RequestTag to tag every read of the client. Only its Priority and
+	// RequestTag may be set, because a client-wide Index or Limit could not
+	// be overridden to read without one.
+	ReadOptions ReadOptions
+
+	// TransactionOptions is the default configuration for read/write
+	// transactions, including those started by Apply and BatchWrite. Options
+	// passed to ReadWriteTransactionWithOptions,
+	// NewReadWriteStmtBasedTransactionWithOptions and Apply take precedence.
+	// Set its TransactionTag to identify the transactions of a workload in
+	// the transaction and lock statistics of the database.
+	TransactionOptions TransactionOptions
+
 	// CallOptions is the configuration for providing custom retry settings that
 	// override the default values.
 	CallOptions *vkit.CallOptions
 
+	// OpenTelemetryMeterProvider is the provider of the meter with which the
+	// client records its metrics with OpenTelemetry: the state of its session
+	// pool, the latency of acquiring sessions, the number of sessions that
+	// have been checked out for more than an hour (if
+	// SessionPoolConfig.TrackSessionHandles is set), and the GFE latency of
+	// its RPCs. If nil, the client records no OpenTelemetry metrics.
+	//
+	// It implements the metric API of go.opentelemetry.io/otel/metric
+	// v0.25.0, the newest one that supports the Go versions that this package
+	// supports.
+	//
+	// This is EXPERIMENTAL and subject to change or removal without notice.
+	OpenTelemetryMeterProvider metric.MeterProvider
+
+	// DisableRouteToLeader disables the routing of read/write transactions,
+	// partitioned DML and the creation of sessions to the leader region of
+	// the database. Routing these RPCs to the leader reduces their latency in
+	// multi-region instances, as the leader has to take part in them anyway.
+	// It has no effect on read-only transactions. Use Client.DefaultLeader to
+	// find out the leader region of the database.
+	DisableRouteToLeader bool
+
+	// OnTransactionRetry is called each time that an attempt of a read/write
+	// transaction fails with an error that is retried, such as Aborted, before
+	// the client waits for the next attempt. It can be used to find the
+	// transactions that contend for the same data. The function is called on
+	// the goroutine of the transaction and should return quickly.
+	//
+	// The retries are also recorded on the TransactionRetriesCount measure,
+	// and on the OpenTelemetry metrics of OpenTelemetryMeterProvider.
+	OnTransactionRetry func(ctx context.Context, info TransactionRetryInfo)
+
 	// logger is the logger to use for this client. If it is nil, all logging
 	// will be directed to the standard logger.
 	logger *log.Logger
@@ -129,6 +197,15 @@ func contextWithOutgoingMetadata(ctx context.Context, md metadata.MD) context.Co
 	return metadata.NewOutgoingContext(ctx, md)
 }
 
+// contextWithRouteToLeader adds the header that routes an RPC to the leader
+// region of the database to ctx if routeToLeader is true.
+func contextWithRouteToLeader(ctx context.Context, routeToLeader bool) context.Context {
+	if !routeToLeader {
+		return ctx
+	}
+	return metadata.AppendToOutgoingContext(ctx, routeToLeaderHeader, "true")
+}
+
 // NewClient creates a client to a database. A valid database name has the
 // form projects/PROJECT_ID/instances/INSTANCE_ID/databases/DATABASE_ID. It uses
 // a default configuration.
@@ -143,6 +220,9 @@ func NewClientWithConfig(ctx context.Context, database string, config ClientConf
 	if err := validDatabaseName(database); err != nil {
 		return nil, err
 	}
+	if config.ReadOptions.Index != "" || config.ReadOptions.Limit != 0 {
+		return nil, spannerErrorf(codes.InvalidArgument, "ClientConfig.ReadOptions must not set Index or Limit")
+	}
 
 	ctx = trace.StartSpan(ctx, "cloud.google.com/go/spanner.NewClient")
 	defer func() { trace.EndSpan(ctx, err) }()
@@ -194,19 +274,32 @@ func NewClientWithConfig(ctx context.Context, database string, config ClientConf
 	}
 	// Create a session client.
 	sc := newSessionClient(pool, database, sessionLabels, metadata.Pairs(resourcePrefixHeader, database), config.logger, config.CallOptions)
+	sc.disableRouteToLeader = config.DisableRouteToLeader
 	// Create a session pool.
 	config.SessionPoolConfig.sessionLabels = sessionLabels
-	sp, err := newSessionPool(sc, config.SessionPoolConfig)
+	otm, err := newOpenTelemetryMetrics(config.OpenTelemetryMeterProvider, sc.id, database)
 	if err != nil {
 		sc.close()
 		return nil, err
 	}
+	sp, err := newSessionPool(sc, config.SessionPoolConfig, otm)
+	if err != nil {
+		sc.close()
+		return nil, err
+	}
+	otm.observeSessionPool(sp)
 	c = &Client{
 		sc:           sc,
 		idleSessions: sp,
 		logger:       config.logger,
 		qo:           getQueryOptions(config.QueryOptions),
-		ct:           getCommonTags(sc),
+		ro:           config.ReadOptions,
+		txo:          config.TransactionOptions,
+		ct:           getCommonTags(sc, otm),
+		otm:          otm,
+
+		disableRouteToLeader: config.DisableRouteToLeader,
+		onTransactionRetry:   config.OnTransactionRetry,
 	}
 	return c, nil
 }
@@ -246,6 +339,7 @@ func getQueryOptions(opts QueryOptions) QueryOptions {
 
 // Close closes the client.
 func (c *Client) Close() {
+	c.otm.close()
 	if c.idleSessions != nil {
 		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 		defer cancel()
@@ -254,6 +348,68 @@ func (c *Client) Close() {
 	c.sc.close()
 }
 
+// databaseDialectQuery returns the SQL dialect of a database. It is valid in
+// both dialects.
+const databaseDialectQuery = "SELECT option_value FROM information_schema.database_options WHERE option_name = 'database_dialect'"
+
+// DatabaseDialect returns the SQL dialect of the client's database. Databases
+// that use the PostgreSQL dialect take statements with positional parameters
+// ($1, $2, ...), which can be bound with NewPositionalStatement, and return
+// NUMERIC values that should be read as PGNumeric, which can also hold NaN.
+//
+// The dialect is queried from the database on the first call, and cached.
+func (c *Client) DatabaseDialect(ctx context.Context) (adminpb.DatabaseDialect, error) {
+	c.dialectMu.Lock()
+	defer c.dialectMu.Unlock()
+	if c.dialect != adminpb.DatabaseDialect_DATABASE_DIALECT_UNSPECIFIED {
+		return c.dialect, nil
+	}
+	// Databases created before the PostgreSQL dialect was introduced don't
+	// have the option.
+	dialect := adminpb.DatabaseDialect_GOOGLE_STANDARD_SQL
+	iter := c.Single().Query(ctx, NewStatement(databaseDialectQuery))
+	err := iter.Do(func(r *Row) error {
+		var name string
+		if err := r.Column(0, &name); err != nil {
+			return err
+		}
+		v, ok := adminpb.DatabaseDialect_value[name]
+		if !ok {
+			return spannerErrorf(codes.Internal, "unknown database dialect %q", name)
+		}
+		dialect = adminpb.DatabaseDialect(v)
+		return nil
+	})
+	if err != nil {
+		return adminpb.DatabaseDialect_DATABASE_DIALECT_UNSPECIFIED, err
+	}
+	c.dialect = dialect
+	return dialect, nil
+}
+
+// defaultLeaderQuery returns the default leader region of a database. It is
+// valid in both dialects.
+const defaultLeaderQuery = "SELECT option_value FROM information_schema.database_options WHERE option_name = 'default_leader'"
+
+// DefaultLeader returns the default leader region of the client's database,
+// such as "us-central1", or an empty string if no default leader has been set.
+// Read/write transactions are routed to the leader region unless
+// ClientConfig.DisableRouteToLeader is set.
+//
+// The default leader can be changed with a DDL statement, and is therefore
+// queried from the database on every call.
+func (c *Client) DefaultLeader(ctx context.Context) (string, error) {
+	var leader string
+	iter := c.Single().Query(ctx, NewStatement(defaultLeaderQuery))
+	err := iter.Do(func(r *Row) error {
+		return r.Column(0, &leader)
+	})
+	if err != nil {
+		return "", err
+	}
+	return leader, nil
+}
+
 // Single provides a read-only snapshot transaction optimized for the case
 // where only a single read or query is needed.  This is more efficient than
 // using ReadOnlyTransaction() for a single read or query.
@@ -268,6 +424,7 @@ func (c *Client) Single() *ReadOnlyTransaction {
 	t.txReadOnly.sp = c.idleSessions
 	t.txReadOnly.txReadEnv = t
 	t.txReadOnly.qo = c.qo
+	t.txReadOnly.ro = c.ro
 	t.txReadOnly.replaceSessionFunc = func(ctx context.Context) error {
 		if t.sh == nil {
 			return spannerErrorf(codes.InvalidArgument, "missing session handle on transaction")
@@ -304,6 +461,7 @@ func (c *Client) ReadOnlyTransaction() *ReadOnlyTransaction {
 	t.txReadOnly.sp = c.idleSessions
 	t.txReadOnly.txReadEnv = t
 	t.txReadOnly.qo = c.qo
+	t.txReadOnly.ro = c.ro
 	t.ct = c.ct
 	return t
 }
@@ -373,6 +531,7 @@ func (c *Client) BatchReadOnlyTransaction(ctx context.Context, tb TimestampBound
 	t.txReadOnly.sh = sh
 	t.txReadOnly.txReadEnv = t
 	t.txReadOnly.qo = c.qo
+	t.txReadOnly.ro = c.ro
 	t.ct = c.ct
 	return t, nil
 }
@@ -401,6 +560,7 @@ func (c *Client) BatchReadOnlyTransactionFromID(tid BatchReadOnlyTransactionID)
 	t.txReadOnly.sh = sh
 	t.txReadOnly.txReadEnv = t
 	t.txReadOnly.qo = c.qo
+	t.txReadOnly.ro = c.ro
 	t.ct = c.ct
 	return t
 }
@@ -486,7 +646,9 @@ func (c *Client) rwTransaction(ctx context.Context, f func(context.Context, *Rea
 		t.txReadOnly.sh = sh
 		t.txReadOnly.txReadEnv = t
 		t.txReadOnly.qo = c.qo
-		t.txOpts = options
+		t.txReadOnly.ro = c.ro
+		t.txReadOnly.routeToLeader = !c.disableRouteToLeader
+		t.txOpts = c.txo.merge(options)
 		t.ct = c.ct
 
 		trace.TracePrintf(ctx, map[string]interface{}{"transactionID": string(sh.getTransactionID())},
@@ -496,6 +658,12 @@ func (c *Client) rwTransaction(ctx context.Context, f func(context.Context, *Rea
 		}
 		resp, err = t.runInTransaction(ctx, f)
 		return err
+	}, func(ctx context.Context, info TransactionRetryInfo) {
+		info.TransactionTag = c.txo.merge(options).TransactionTag
+		recordTransactionRetry(ctx, c.ct, info)
+		if c.onTransactionRetry != nil {
+			c.onTransactionRetry(ctx, info)
+		}
 	})
 	return resp, err
 }
@@ -509,6 +677,13 @@ type applyOption struct {
 	transactionTag string
 	// priority is the RPC priority that is used for the commit operation.
 	priority sppb.RequestOptions_Priority
+	// maxCommitMutations and maxCommitBytes are the limits of one commit.
+	// Zero selects the defaults.
+	maxCommitMutations int
+	maxCommitBytes     int
+	// If splitCommits == true, Client.Apply splits mutations that exceed the
+	// limits of one commit into multiple commits.
+	splitCommits bool
 }
 
 // An ApplyOption is an optional argument to Apply.
@@ -548,8 +723,12 @@ func Priority(priority sppb.RequestOptions_Priority) ApplyOption {
 }
 
 // Apply applies a list of mutations atomically to the database.
+//
+// Mutations that exceed the limits of one commit fail with a CommitLimitError
+// before anything is sent to the database. See ApplyCommitLimits and
+// ApplySplitCommits.
 func (c *Client) Apply(ctx context.Context, ms []*Mutation, opts ...ApplyOption) (commitTimestamp time.Time, err error) {
-	ao := &applyOption{}
+	ao := c.defaultApplyOption()
 	for _, opt := range opts {
 		opt(ao)
 	}
@@ -557,16 +736,37 @@ func (c *Client) Apply(ctx context.Context, ms []*Mutation, opts ...ApplyOption)
 	ctx = trace.StartSpan(ctx, "cloud.google.com/go/spanner.Apply")
 	defer func() { trace.EndSpan(ctx, err) }()
 
+	batches, err := splitMutations(ms, ao.maxCommitMutations, ao.maxCommitBytes, ao.splitCommits)
+	if err != nil {
+		return time.Time{}, err
+	}
+	for _, batch := range batches {
+		if commitTimestamp, err = c.apply(ctx, batch, ao); err != nil {
+			return time.Time{}, err
+		}
+	}
+	return commitTimestamp, nil
+}
+
+// apply commits ms in one commit.
+func (c *Client) apply(ctx context.Context, ms []*Mutation, ao *applyOption) (time.Time, error) {
 	if !ao.atLeastOnce {
 		resp, err := c.ReadWriteTransactionWithOptions(ctx, func(ctx context.Context, t *ReadWriteTransaction) error {
 			return t.BufferWrite(ms)
-		}, TransactionOptions{CommitPriority: ao.priority, TransactionTag: ao.transactionTag})
+		}, TransactionOptions{CommitOptions: c.txo.CommitOptions, CommitPriority: ao.priority, TransactionTag: ao.transactionTag})
 		return resp.CommitTs, err
 	}
-	t := &writeOnlyTransaction{sp: c.idleSessions, commitPriority: ao.priority, transactionTag: ao.transactionTag}
+	t := &writeOnlyTransaction{sp: c.idleSessions, commitPriority: ao.priority, transactionTag: ao.transactionTag, routeToLeader: !c.disableRouteToLeader}
 	return t.applyAtLeastOnce(ctx, ms...)
 }
 
+// defaultApplyOption returns the applyOption that Apply and BatchWrite start
+// from, which carries the transaction tag and commit priority of the client's
+// default TransactionOptions.
+func (c *Client) defaultApplyOption() *applyOption {
+	return &applyOption{transactionTag: c.txo.TransactionTag, priority: c.txo.CommitPriority}
+}
+
 // logf logs the given message to the given logger, or the standard logger if
 // the given logger is nil.
 func logf(logger *log.Logger, format string, v ...interface{}) {
@@ -23,6 +23,7 @@ import (
 	"math/big"
 	"os"
 	"strings"
+	"sync"
 	"testing"
 	"time"
 
@@ -34,8 +35,11 @@ import (
 	gax "github.com/googleapis/gax-go/v2"
 	"google.golang.org/api/iterator"
 	"google.golang.org/api/option"
+	adminpb "google.golang.org/genproto/googleapis/spanner/admin/database/v1"
 	sppb "google.golang.org/genproto/googleapis/spanner/v1"
+	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
 	"google.golang.org/grpc/status"
 )
 
@@ -119,6 +123,244 @@ func TestClient_Single(t *testing.T) {
 	}
 }
 
+func TestClient_DatabaseDialect(t *testing.T) {
+	t.Parallel()
+	server, client, teardown := setupMockedTestServer(t)
+	defer teardown()
+	ctx := context.Background()
+
+	// A database without the option uses GoogleSQL.
+	server.TestSpanner.PutStatementResult(databaseDialectQuery, &StatementResult{
+		Type:      StatementResultResultSet,
+		ResultSet: dialectResultSet(),
+	})
+	dialect, err := client.DatabaseDialect(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := dialect, adminpb.DatabaseDialect_GOOGLE_STANDARD_SQL; got != want {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+
+	// The dialect is cached.
+	client.dialect = adminpb.DatabaseDialect_DATABASE_DIALECT_UNSPECIFIED
+	server.TestSpanner.PutStatementResult(databaseDialectQuery, &StatementResult{
+		Type:      StatementResultResultSet,
+		ResultSet: dialectResultSet("POSTGRESQL"),
+	})
+	for i := 0; i < 2; i++ {
+		dialect, err = client.DatabaseDialect(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got, want := dialect, adminpb.DatabaseDialect_POSTGRESQL; got != want {
+			t.Fatalf("got %v, want %v", got, want)
+		}
+	}
+	var queries int
+	for _, req := range drainRequestsFromServer(server.TestSpanner) {
+		if sqlReq, ok := req.(*sppb.ExecuteSqlRequest); ok && sqlReq.Sql == databaseDialectQuery {
+			queries++
+		}
+	}
+	if got, want := queries, 2; got != want {
+		t.Errorf("got %d dialect queries, want %d", got, want)
+	}
+}
+
+func dialectResultSet(values ...string) *sppb.ResultSet {
+	rs := &sppb.ResultSet{
+		Metadata: &sppb.ResultSetMetadata{
+			RowType: &sppb.StructType{
+				Fields: []*sppb.StructType_Field{
+					{Name: "option_value", Type: &sppb.Type{Code: sppb.TypeCode_STRING}},
+				},
+			},
+		},
+	}
+	for _, v := range values {
+		rs.Rows = append(rs.Rows, &structpb.ListValue{
+			Values: []*structpb.Value{{Kind: &structpb.Value_StringValue{StringValue: v}}},
+		})
+	}
+	return rs
+}
+
+func TestClient_DefaultLeader(t *testing.T) {
+	t.Parallel()
+	server, client, teardown := setupMockedTestServer(t)
+	defer teardown()
+	ctx := context.Background()
+
+	for _, test := range []struct {
+		values []string
+		want   string
+	}{
+		{nil, ""},
+		{[]string{"us-east1"}, "us-east1"},
+	} {
+		server.TestSpanner.PutStatementResult(defaultLeaderQuery, &StatementResult{
+			Type:      StatementResultResultSet,
+			ResultSet: dialectResultSet(test.values...),
+		})
+		got, err := client.DefaultLeader(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != test.want {
+			t.Errorf("got %q, want %q", got, test.want)
+		}
+	}
+}
+
+// routeToLeaderRecorder records for each method whether its last call was
+// routed to the leader.
+type routeToLeaderRecorder struct {
+	mu     sync.Mutex
+	routed map[string]bool
+}
+
+func (r *routeToLeaderRecorder) record(ctx context.Context, method string) {
+	md, _ := metadata.FromOutgoingContext(ctx)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.routed[method[strings.LastIndex(method, "/")+1:]] = len(md.Get(routeToLeaderHeader)) > 0
+}
+
+func (r *routeToLeaderRecorder) reset() map[string]bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	routed := r.routed
+	r.routed = map[string]bool{}
+	return routed
+}
+
+func (r *routeToLeaderRecorder) clientOptions() []option.ClientOption {
+	return []option.ClientOption{
+		option.WithGRPCDialOption(grpc.WithChainUnaryInterceptor(func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+			r.record(ctx, method)
+			return invoker(ctx, method, req, reply, cc, opts...)
+		})),
+		option.WithGRPCDialOption(grpc.WithChainStreamInterceptor(func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+			r.record(ctx, method)
+			return streamer(ctx, desc, cc, method, opts...)
+		})),
+	}
+}
+
+func TestClient_RouteToLeader(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+
+	runReadWrite := func(client *Client) {
+		_, err := client.ReadWriteTransaction(ctx, func(ctx context.Context, tx *ReadWriteTransaction) error {
+			if err := tx.Query(ctx, NewStatement(SelectSingerIDAlbumIDAlbumTitleFromAlbums)).Do(func(*Row) error { return nil }); err != nil {
+				return err
+			}
+			_, err := tx.Update(ctx, NewStatement(UpdateBarSetFoo))
+			return err
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for _, disable := range []bool{false, true} {
+		rec := &routeToLeaderRecorder{routed: map[string]bool{}}
+		_, client, teardown := setupMockedTestServerWithConfigAndClientOptions(t, ClientConfig{
+			SessionPoolConfig:    SessionPoolConfig{MinOpened: 1},
+			DisableRouteToLeader: disable,
+		}, rec.clientOptions())
+		rec.reset()
+
+		if err := client.Single().Query(ctx, NewStatement(SelectSingerIDAlbumIDAlbumTitleFromAlbums)).Do(func(*Row) error { return nil }); err != nil {
+			t.Fatal(err)
+		}
+		if got := rec.reset(); got["ExecuteStreamingSql"] {
+			t.Errorf("disable=%v: read-only query routed to leader", disable)
+		}
+
+		runReadWrite(client)
+		got := rec.reset()
+		for _, method := range []string{"BeginTransaction", "ExecuteStreamingSql", "ExecuteSql", "Commit"} {
+			if routed, ok := got[method]; !ok || routed == disable {
+				t.Errorf("disable=%v: read/write %s: got routed=%v (called=%v), want %v", disable, method, routed, ok, !disable)
+			}
+		}
+
+		if _, err := client.Apply(ctx, []*Mutation{Insert("foo", []string{"col1"}, []interface{}{"val1"})}); err != nil {
+			t.Fatal(err)
+		}
+		if got := rec.reset(); got["Commit"] == disable {
+			t.Errorf("disable=%v: Apply: got routed=%v, want %v", disable, got["Commit"], !disable)
+		}
+
+		if _, err := client.PartitionedUpdate(ctx, NewStatement(UpdateBarSetFoo)); err != nil {
+			t.Fatal(err)
+		}
+		if got := rec.reset(); got["ExecuteSql"] == disable {
+			t.Errorf("disable=%v: PartitionedUpdate: got routed=%v, want %v", disable, got["ExecuteSql"], !disable)
+		}
+		teardown()
+	}
+}
+
+func TestClient_OnTransactionRetry(t *testing.T) {
+	t.Parallel()
+	var (
+		mu    sync.Mutex
+		infos []TransactionRetryInfo
+	)
+	m := newFakeMeter()
+	server, client, teardown := setupMockedTestServerWithConfig(t, ClientConfig{
+		OnTransactionRetry: func(_ context.Context, info TransactionRetryInfo) {
+			mu.Lock()
+			defer mu.Unlock()
+			infos = append(infos, info)
+		},
+		OpenTelemetryMeterProvider: fakeMeterProvider{m: m},
+	})
+	defer teardown()
+	ctx := context.Background()
+
+	server.TestSpanner.PutExecutionTime(MethodCommitTransaction, SimulatedExecutionTime{
+		Errors: []error{status.Error(codes.Aborted, "Transaction was aborted due to contention"), status.Error(codes.Aborted, "Transaction was aborted due to contention")},
+	})
+	_, err := client.ReadWriteTransactionWithOptions(ctx, func(ctx context.Context, tx *ReadWriteTransaction) error {
+		_, err := tx.Update(ctx, NewStatement(UpdateBarSetFoo))
+		return err
+	}, TransactionOptions{TransactionTag: "contended"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if got, want := len(infos), 2; got != want {
+		t.Fatalf("got %d retries, want %d", got, want)
+	}
+	for i, info := range infos {
+		if got, want := info.Attempt, i+1; got != want {
+			t.Errorf("retry %d: got attempt %d, want %d", i, got, want)
+		}
+		if got, want := ErrCode(info.Err), codes.Aborted; got != want {
+			t.Errorf("retry %d: got error code %v, want %v", i, got, want)
+		}
+		if !strings.Contains(ErrDesc(info.Err), "contention") {
+			t.Errorf("retry %d: got error %v, want the abort reason", i, info.Err)
+		}
+		if info.Delay <= 0 {
+			t.Errorf("retry %d: got delay %v, want a positive delay", i, info.Delay)
+		}
+		if got, want := info.TransactionTag, "contended"; got != want {
+			t.Errorf("retry %d: got transaction tag %q, want %q", i, got, want)
+		}
+	}
+	if got, _ := m.get("spanner/num_transaction_retries"); got != 2 {
+		t.Errorf("spanner/num_transaction_retries: got %v, want 2", got)
+	}
+}
+
 func TestClient_Single_Unavailable(t *testing.T) {
 	t.Parallel()
 	err := testSingleQuery(t, status.Error(codes.Unavailable, "Temporary unavailable"))
@@ -2579,6 +2821,46 @@ func TestClient_Apply_Priority(t *testing.T) {
 	checkCommitForExpectedRequestOptions(t, server.TestSpanner, sppb.RequestOptions{Priority: sppb.RequestOptions_PRIORITY_MEDIUM})
 }
 
+func TestClient_DefaultPriority(t *testing.T) {
+	t.Parallel()
+
+	low := sppb.RequestOptions_PRIORITY_LOW
+	server, client, teardown := setupMockedTestServerWithConfig(t, ClientConfig{
+		QueryOptions:       QueryOptions{Priority: low},
+		ReadOptions:        ReadOptions{Priority: low},
+		TransactionOptions: TransactionOptions{CommitPriority: low},
+	})
+	defer teardown()
+	ctx := context.Background()
+
+	iter := client.Single().Query(ctx, NewStatement(SelectSingerIDAlbumIDAlbumTitleFromAlbums))
+	iter.Next()
+	iter.Stop()
+	iter = client.Single().Read(ctx, "FOO", AllKeys(), []string{"BAR"})
+	iter.Next()
+	iter.Stop()
+	checkRequestsForExpectedRequestOptions(t, server.TestSpanner, 2, sppb.RequestOptions{Priority: low})
+
+	iter = client.Single().QueryWithOptions(ctx, NewStatement(SelectSingerIDAlbumIDAlbumTitleFromAlbums), QueryOptions{Priority: sppb.RequestOptions_PRIORITY_HIGH})
+	iter.Next()
+	iter.Stop()
+	checkRequestsForExpectedRequestOptions(t, server.TestSpanner, 1, sppb.RequestOptions{Priority: sppb.RequestOptions_PRIORITY_HIGH})
+
+	client.ReadWriteTransaction(ctx, func(ctx context.Context, tx *ReadWriteTransaction) error {
+		tx.Update(ctx, NewStatement(UpdateBarSetFoo))
+		tx.BatchUpdate(ctx, []Statement{NewStatement(UpdateBarSetFoo)})
+		checkRequestsForExpectedRequestOptions(t, server.TestSpanner, 2, sppb.RequestOptions{Priority: low})
+		return nil
+	})
+	checkCommitForExpectedRequestOptions(t, server.TestSpanner, sppb.RequestOptions{Priority: low})
+
+	client.Apply(ctx, []*Mutation{Insert("foo", []string{"col1"}, []interface{}{"val1"})}, ApplyAtLeastOnce())
+	checkCommitForExpectedRequestOptions(t, server.TestSpanner, sppb.RequestOptions{Priority: low})
+
+	client.Apply(ctx, []*Mutation{Insert("foo", []string{"col1"}, []interface{}{"val1"})}, Priority(sppb.RequestOptions_PRIORITY_HIGH))
+	checkCommitForExpectedRequestOptions(t, server.TestSpanner, sppb.RequestOptions{Priority: sppb.RequestOptions_PRIORITY_HIGH})
+}
+
 func TestClient_ReadOnlyTransaction_Tag(t *testing.T) {
 	t.Parallel()
 
@@ -2714,6 +2996,64 @@ func TestClient_Apply_Tagging(t *testing.T) {
 	checkCommitForExpectedRequestOptions(t, server.TestSpanner, sppb.RequestOptions{TransactionTag: "tx-tag"})
 }
 
+func TestClient_ReadOptionsWithIndexOrLimit(t *testing.T) {
+	t.Parallel()
+
+	_, opts, serverTeardown := NewMockedSpannerInMemTestServer(t)
+	defer serverTeardown()
+
+	for _, ro := range []ReadOptions{{Index: "idx"}, {Limit: 10}} {
+		_, err := NewClientWithConfig(context.Background(), "projects/p/instances/i/databases/d", ClientConfig{ReadOptions: ro}, opts...)
+		if g, w := ErrCode(err), codes.InvalidArgument; g != w {
+			t.Errorf("%+v: error code mismatch\nGot: %v\nWant: %v", ro, g, w)
+		}
+	}
+}
+
+func TestClient_DefaultTags(t *testing.T) {
+	t.Parallel()
+
+	server, client, teardown := setupMockedTestServerWithConfig(t, ClientConfig{
+		ReadOptions:        ReadOptions{RequestTag: "default-request-tag"},
+		TransactionOptions: TransactionOptions{TransactionTag: "default-tx-tag"},
+	})
+	defer teardown()
+	ctx := context.Background()
+
+	iter := client.Single().Read(ctx, "FOO", AllKeys(), []string{"BAR"})
+	iter.Next()
+	iter.Stop()
+	checkRequestsForExpectedRequestOptions(t, server.TestSpanner, 1, sppb.RequestOptions{RequestTag: "default-request-tag"})
+
+	iter = client.Single().ReadWithOptions(ctx, "FOO", AllKeys(), []string{"BAR"}, &ReadOptions{RequestTag: "request-tag-1"})
+	iter.Next()
+	iter.Stop()
+	checkRequestsForExpectedRequestOptions(t, server.TestSpanner, 1, sppb.RequestOptions{RequestTag: "request-tag-1"})
+
+	for _, to := range []struct {
+		opts TransactionOptions
+		want string
+	}{
+		{TransactionOptions{}, "default-tx-tag"},
+		{TransactionOptions{TransactionTag: "tx-tag-1"}, "tx-tag-1"},
+	} {
+		client.ReadWriteTransactionWithOptions(ctx, func(ctx context.Context, tx *ReadWriteTransaction) error {
+			iter := tx.Read(ctx, "FOO", AllKeys(), []string{"BAR"})
+			iter.Next()
+			iter.Stop()
+			checkRequestsForExpectedRequestOptions(t, server.TestSpanner, 1, sppb.RequestOptions{RequestTag: "default-request-tag", TransactionTag: to.want})
+			return nil
+		}, to.opts)
+		checkCommitForExpectedRequestOptions(t, server.TestSpanner, sppb.RequestOptions{TransactionTag: to.want})
+	}
+
+	client.Apply(ctx, []*Mutation{Insert("foo", []string{"col1"}, []interface{}{"val1"})}, ApplyAtLeastOnce())
+	checkCommitForExpectedRequestOptions(t, server.TestSpanner, sppb.RequestOptions{TransactionTag: "default-tx-tag"})
+
+	client.Apply(ctx, []*Mutation{Insert("foo", []string{"col1"}, []interface{}{"val1"})}, TransactionTag("tx-tag"))
+	checkCommitForExpectedRequestOptions(t, server.TestSpanner, sppb.RequestOptions{TransactionTag: "tx-tag"})
+}
+
 func TestClient_PartitionQuery_RequestOptions(t *testing.T) {
 	t.Parallel()
 
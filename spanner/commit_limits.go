@@ -0,0 +1,140 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package spanner
+
+import (
+	"fmt"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/protobuf/proto"
+)
+
+const (
+	// DefaultMaxCommitMutations is the default maximum number of mutations
+	// that Apply sends in one commit. Cloud Spanner counts each column that
+	// is written by an insert or update, and each delete, as one mutation.
+	// Cloud Spanner also counts the changes to secondary indexes, which the
+	// client cannot see. Use ApplyCommitLimits to use a lower limit for
+	// tables with indexes.
+	DefaultMaxCommitMutations = 80000
+
+	// DefaultMaxCommitBytes is the default maximum size in bytes of the
+	// mutations that Apply sends in one commit.
+	DefaultMaxCommitBytes = 100 << 20
+)
+
+// CommitLimitError is wrapped in a Spanner error with code InvalidArgument
+// when the mutations that are passed to Apply exceed the number of mutations
+// or the size that fit in one commit. No mutations are applied in that case.
+// Use ApplySplitCommits to split the mutations into multiple commits instead.
+type CommitLimitError struct {
+	// Mutations is the number of mutations of the commit, or of the single
+	// mutation that exceeds the limits on its own.
+	Mutations int
+	// MaxMutations is the maximum number of mutations in one commit.
+	MaxMutations int
+	// Bytes is the size in bytes of the mutations that Mutations counts.
+	Bytes int
+	// MaxBytes is the maximum size of the mutations in one commit.
+	MaxBytes int
+}
+
+// Error implements error.Error.
+func (e *CommitLimitError) Error() string {
+	return fmt.Sprintf("commit exceeds limits: %d mutations (max %d), %d bytes (max %d)", e.Mutations, e.MaxMutations, e.Bytes, e.MaxBytes)
+}
+
+// ApplyCommitLimits returns an ApplyOption that sets the maximum number of
+// mutations and the maximum size in bytes of the mutations of one commit.
+// Zero or negative values select DefaultMaxCommitMutations and
+// DefaultMaxCommitBytes.
+func ApplyCommitLimits(maxMutations, maxBytes int) ApplyOption {
+	return func(ao *applyOption) {
+		ao.maxCommitMutations = maxMutations
+		ao.maxCommitBytes = maxBytes
+	}
+}
+
+// ApplySplitCommits returns an ApplyOption that makes Apply split mutations
+// that exceed the limits of one commit into multiple commits, instead of
+// returning a CommitLimitError. The mutations are committed in the order that
+// they are passed to Apply, and the commit timestamp of the last commit is
+// returned.
+//
+// With this option, Apply is no longer atomic: if one of the commits fails,
+// the mutations of the preceding commits have been applied, and the mutations
+// of the following commits have not. Only use this option for mutations that
+// can be applied again, such as InsertOrUpdate and Replace, so that a failed
+// Apply can be retried. A single mutation that exceeds the limits on its own
+// still fails with a CommitLimitError.
+func ApplySplitCommits() ApplyOption {
+	return func(ao *applyOption) {
+		ao.splitCommits = true
+	}
+}
+
+// mutationCount returns the number of mutations that Cloud Spanner counts for
+// m, not including changes to secondary indexes.
+func (m *Mutation) mutationCount() int {
+	if m.op == opDelete || len(m.columns) == 0 {
+		return 1
+	}
+	return len(m.columns)
+}
+
+// splitMutations splits ms into batches that each fit in one commit. If split
+// is false, it returns ms as the only batch if it fits in one commit, and an
+// error otherwise.
+func splitMutations(ms []*Mutation, maxMutations, maxBytes int, split bool) ([][]*Mutation, error) {
+	if maxMutations <= 0 {
+		maxMutations = DefaultMaxCommitMutations
+	}
+	if maxBytes <= 0 {
+		maxBytes = DefaultMaxCommitBytes
+	}
+	var (
+		batches               [][]*Mutation
+		start                 int
+		count, size           int
+		totalCount, totalSize int
+	)
+	for i, m := range ms {
+		pb, err := m.proto()
+		if err != nil {
+			return nil, err
+		}
+		n, b := m.mutationCount(), proto.Size(pb)
+		if n > maxMutations || b > maxBytes {
+			return nil, errCommitLimit(n, maxMutations, b, maxBytes)
+		}
+		if split && i > start && (count+n > maxMutations || size+b > maxBytes) {
+			batches = append(batches, ms[start:i])
+			start, count, size = i, 0, 0
+		}
+		count += n
+		size += b
+		totalCount += n
+		totalSize += b
+	}
+	if !split && (totalCount > maxMutations || totalSize > maxBytes) {
+		return nil, errCommitLimit(totalCount, maxMutations, totalSize, maxBytes)
+	}
+	return append(batches, ms[start:]), nil
+}
+
+func errCommitLimit(mutations, maxMutations, bytes, maxBytes int) error {
+	e := &CommitLimitError{Mutations: mutations, MaxMutations: maxMutations, Bytes: bytes, MaxBytes: maxBytes}
+	return &Error{Code: codes.InvalidArgument, err: e, Desc: e.Error()}
+}
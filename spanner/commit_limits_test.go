@@ -0,0 +1,123 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package spanner
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	sppb "google.golang.org/genproto/googleapis/spanner/v1"
+	"google.golang.org/grpc/codes"
+)
+
+func TestSplitMutations(t *testing.T) {
+	ms := []*Mutation{
+		Insert("t", []string{"a", "b"}, []interface{}{1, 2}),
+		Delete("t", Key{1}),
+		Update("t", []string{"a", "b", "c"}, []interface{}{1, 2, 3}),
+		Replace("t", []string{"a"}, []interface{}{1}),
+	}
+	for _, test := range []struct {
+		maxMutations int
+		want         []int
+	}{
+		{0, []int{4}},
+		{6, []int{3, 1}},
+		{3, []int{2, 1, 1}},
+	} {
+		batches, err := splitMutations(ms, test.maxMutations, 0, true)
+		if err != nil {
+			t.Fatalf("max %d: %v", test.maxMutations, err)
+		}
+		var got []int
+		for _, b := range batches {
+			got = append(got, len(b))
+		}
+		if !testEqual(got, test.want) {
+			t.Errorf("max %d: got batch sizes %v, want %v", test.maxMutations, got, test.want)
+		}
+	}
+
+	// Without splitting, the mutations must fit in one commit.
+	_, err := splitMutations(ms, 6, 0, false)
+	var cle *CommitLimitError
+	if !errors.As(err, &cle) || ErrCode(err) != codes.InvalidArgument {
+		t.Fatalf("got %v, want a CommitLimitError", err)
+	}
+	if got, want := *cle, (CommitLimitError{Mutations: 7, MaxMutations: 6, Bytes: cle.Bytes, MaxBytes: DefaultMaxCommitBytes}); got != want {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+
+	// A mutation that exceeds the limits on its own cannot be split.
+	for _, limits := range [][2]int{{2, 0}, {0, 10}} {
+		if _, err := splitMutations(ms, limits[0], limits[1], true); !errors.As(err, &cle) {
+			t.Errorf("limits %v: got %v, want a CommitLimitError", limits, err)
+		}
+	}
+}
+
+func TestClient_ApplyCommitLimits(t *testing.T) {
+	t.Parallel()
+	server, client, teardown := setupMockedTestServer(t)
+	defer teardown()
+	ctx := context.Background()
+
+	ms := []*Mutation{
+		InsertOrUpdate("foo", []string{"col1", "col2"}, []interface{}{"a", 1}),
+		InsertOrUpdate("foo", []string{"col1", "col2"}, []interface{}{"b", 2}),
+		InsertOrUpdate("foo", []string{"col1", "col2"}, []interface{}{"c", 3}),
+	}
+	countCommits := func() (commits []int) {
+		for _, req := range drainRequestsFromServer(server.TestSpanner) {
+			if commit, ok := req.(*sppb.CommitRequest); ok {
+				commits = append(commits, len(commit.Mutations))
+			}
+		}
+		return commits
+	}
+
+	for _, atLeastOnce := range []bool{false, true} {
+		opts := []ApplyOption{ApplyCommitLimits(4, 0)}
+		if atLeastOnce {
+			opts = append(opts, ApplyAtLeastOnce())
+		}
+		_, err := client.Apply(ctx, ms, opts...)
+		var cle *CommitLimitError
+		if !errors.As(err, &cle) || !strings.Contains(err.Error(), "6 mutations (max 4)") {
+			t.Fatalf("atLeastOnce=%v: got %v, want a CommitLimitError", atLeastOnce, err)
+		}
+		if got := countCommits(); len(got) != 0 {
+			t.Errorf("atLeastOnce=%v: got commits %v, want none", atLeastOnce, got)
+		}
+
+		if _, err := client.Apply(ctx, ms, append(opts, ApplySplitCommits())...); err != nil {
+			t.Fatalf("atLeastOnce=%v: %v", atLeastOnce, err)
+		}
+		if got, want := countCommits(), []int{2, 1}; !testEqual(got, want) {
+			t.Errorf("atLeastOnce=%v: got commits with %v mutations, want %v", atLeastOnce, got, want)
+		}
+	}
+
+	results := client.BatchWrite(ctx, []*MutationGroup{{Mutations: ms[:2]}, {Mutations: ms}}, ApplyCommitLimits(4, 0))
+	if results[0].Err != nil {
+		t.Errorf("group 0: %v", results[0].Err)
+	}
+	var cle *CommitLimitError
+	if !errors.As(results[1].Err, &cle) {
+		t.Errorf("group 1: got %v, want a CommitLimitError", results[1].Err)
+	}
+}
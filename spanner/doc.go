@@ -322,6 +322,51 @@ of the transaction:
         return txn.BufferWrite([]*spanner.Mutation{m})
     })
 
+To write large amounts of data that need not be written atomically as a whole,
+use BatchWrite. It applies each group of mutations atomically, but
+independently of the other groups, and reports the outcome of each group:
+
+    groups := []*spanner.MutationGroup{{Mutations: []*spanner.Mutation{m1, m2}}, {Mutations: []*spanner.Mutation{m3}}}
+    results := client.BatchWrite(ctx, groups)
+    if failed := spanner.FailedMutationGroups(groups, results); len(failed) > 0 {
+        // Handle or retry the failed groups.
+    }
+
+A commit can hold a limited number of mutations. Apply fails with a
+CommitLimitError if the mutations exceed that limit, unless ApplySplitCommits
+allows it to split them into several commits, which are not atomic as a whole:
+
+    _, err := client.Apply(ctx, ms, spanner.ApplySplitCommits())
+
+
+Tags
+
+Request tags and transaction tags identify a workload in the query, read,
+transaction and lock statistics of a database. Set a request tag with the
+RequestTag field of QueryOptions or ReadOptions, and a transaction tag with the
+TransactionTag field of TransactionOptions or the TransactionTag ApplyOption:
+
+    iter := client.Single().QueryWithOptions(ctx, stmt, spanner.QueryOptions{RequestTag: "app=cart,action=list"})
+    _, err := client.ReadWriteTransactionWithOptions(ctx, f, spanner.TransactionOptions{TransactionTag: "app=cart,action=checkout"})
+
+Defaults for all the reads and read/write transactions of a client can be set
+with the ReadOptions and TransactionOptions fields of ClientConfig.
+
+
+Priority
+
+Reads, queries and commits can be given a priority of LOW, MEDIUM or HIGH, so
+that background work yields to user-facing traffic when the database is busy.
+Set it with the Priority field of QueryOptions or ReadOptions, the
+CommitPriority field of TransactionOptions, or the Priority ApplyOption:
+
+    low := sppb.RequestOptions_PRIORITY_LOW
+    iter := client.Single().ReadWithOptions(ctx, "Accounts", spanner.AllKeys(), columns, &spanner.ReadOptions{Priority: low})
+    _, err := client.Apply(ctx, ms, spanner.Priority(low))
+
+To run all of a client's work at the same priority, set the priorities in the
+QueryOptions, ReadOptions and TransactionOptions fields of ClientConfig.
+
 
 Structs
 
@@ -362,6 +407,10 @@ For large databases, it may be more efficient to partition the DML statement.
 Use client.PartitionedUpdate to run a DML statement in this way. Not all DML
 statements can be partitioned.
 
+To run many DML statements that need not be committed together as a whole, use
+client.BatchUpdate. It executes the statements in groups, each in a
+transaction of its own, and reports the outcome of each group.
+
 
 Tracing
 
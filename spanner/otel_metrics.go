@@ -0,0 +1,248 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package spanner
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"cloud.google.com/go/spanner/internal"
+	"go.opencensus.io/stats"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/metric/unit"
+	"google.golang.org/grpc/metadata"
+)
+
+const (
+	// otelMeterName is the name of the OpenTelemetry meter of this package.
+	otelMeterName = "cloud.google.com/go/spanner"
+
+	// otelMetricsPrefix is prepended to the names of the OpenTelemetry
+	// metrics.
+	otelMetricsPrefix = "spanner/"
+
+	// leakedSessionAge is how long a session must have been checked out of
+	// the pool to be counted as leaked.
+	leakedSessionAge = time.Hour
+)
+
+var (
+	otelAttrKeyClientID   = attribute.Key("client_id")
+	otelAttrKeyDatabase   = attribute.Key("database")
+	otelAttrKeyInstance   = attribute.Key("instance_id")
+	otelAttrKeyLibVersion = attribute.Key("library_version")
+	otelAttrKeyType       = attribute.Key("type")
+	otelAttrKeyMethod     = attribute.Key("grpc_client_method")
+	otelAttrKeyReason     = attribute.Key("reason")
+)
+
+// openTelemetryMetrics records the metrics of a client with OpenTelemetry.
+// The metrics mirror the OpenCensus measures of this package, and add the
+// latency of acquiring sessions and the number of leaked sessions.
+//
+// A nil *openTelemetryMetrics records nothing.
+type openTelemetryMetrics struct {
+	// attrs are the attributes common to all metrics of the client.
+	attrs []attribute.KeyValue
+
+	meter metric.Meter
+
+	// pool is the session pool whose state the gauges report, or nil once
+	// the client is closed. The metric API has no way to unregister the
+	// callback of the gauges, so they stop reporting instead.
+	mu   sync.Mutex
+	pool *sessionPool
+
+	openSessionCount       metric.Int64GaugeObserver
+	maxAllowedSessions     metric.Int64GaugeObserver
+	sessionsCount          metric.Int64GaugeObserver
+	maxInUseSessions       metric.Int64GaugeObserver
+	leakedSessions         metric.Int64GaugeObserver
+	getSessionTimeouts     metric.Int64Counter
+	acquiredSessions       metric.Int64Counter
+	releasedSessions       metric.Int64Counter
+	sessionAcquireLatency  metric.Float64Histogram
+	gfeLatency             metric.Float64Histogram
+	gfeHeaderMissingCounts metric.Int64Counter
+	transactionRetries     metric.Int64Counter
+}
+
+// newOpenTelemetryMetrics creates the instruments of the client with the
+// given ID and database from mp. It returns nil if mp is nil.
+func newOpenTelemetryMetrics(mp metric.MeterProvider, clientID, db string) (*openTelemetryMetrics, error) {
+	if mp == nil {
+		return nil, nil
+	}
+	_, instance, database, err := parseDatabaseName(db)
+	if err != nil {
+		return nil, err
+	}
+	m := &openTelemetryMetrics{
+		attrs: []attribute.KeyValue{
+			otelAttrKeyClientID.String(clientID),
+			otelAttrKeyDatabase.String(database),
+			otelAttrKeyInstance.String(instance),
+			otelAttrKeyLibVersion.String(internal.Version),
+		},
+		meter: mp.Meter(otelMeterName, metric.WithInstrumentationVersion(internal.Version)),
+	}
+	batch := m.meter.NewBatchObserver(m.observeSessionPoolState)
+	gauges := []struct {
+		g           *metric.Int64GaugeObserver
+		name, descr string
+	}{
+		{&m.openSessionCount, "open_session_count", "Number of sessions currently opened"},
+		{&m.maxAllowedSessions, "max_allowed_sessions", "The maximum number of sessions allowed. Configurable by the user."},
+		{&m.sessionsCount, "num_sessions_in_pool", "The number of sessions currently in use, being prepared, or idle for reads or writes"},
+		{&m.maxInUseSessions, "max_in_use_sessions", "The maximum number of sessions in use during the last 10 minute interval"},
+		{&m.leakedSessions, "num_leaked_sessions", "The number of sessions checked out of the pool for more than an hour. Only recorded if SessionPoolConfig.TrackSessionHandles is set."},
+	}
+	for _, g := range gauges {
+		if *g.g, err = batch.NewInt64GaugeObserver(otelMetricsPrefix+g.name, metric.WithDescription(g.descr), metric.WithUnit(unit.Dimensionless)); err != nil {
+			return nil, err
+		}
+	}
+	counters := []struct {
+		c           *metric.Int64Counter
+		name, descr string
+	}{
+		{&m.getSessionTimeouts, "get_session_timeouts", "The number of get sessions timeouts due to pool exhaustion"},
+		{&m.acquiredSessions, "num_acquired_sessions", "The number of sessions acquired from the session pool"},
+		{&m.releasedSessions, "num_released_sessions", "The number of sessions released by the user and pool maintainer"},
+		{&m.gfeHeaderMissingCounts, "gfe_header_missing_count", "Number of RPC responses received without the server-timing header, most likely means that the RPC never reached Google's network"},
+		{&m.transactionRetries, "num_transaction_retries", "The number of read/write transaction attempts that failed and were retried"},
+	}
+	for _, c := range counters {
+		if *c.c, err = m.meter.NewInt64Counter(otelMetricsPrefix+c.name, metric.WithDescription(c.descr), metric.WithUnit(unit.Dimensionless)); err != nil {
+			return nil, err
+		}
+	}
+	if m.sessionAcquireLatency, err = m.meter.NewFloat64Histogram(otelMetricsPrefix+"session_acquire_latency",
+		metric.WithDescription("The time it takes to acquire a session from the session pool"), metric.WithUnit(unit.Milliseconds)); err != nil {
+		return nil, err
+	}
+	if m.gfeLatency, err = m.meter.NewFloat64Histogram(otelMetricsPrefix+"gfe_latency",
+		metric.WithDescription("Latency between Google's network receiving an RPC and reading back the first byte of the response"), metric.WithUnit(unit.Milliseconds)); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// observeSessionPool makes the gauges of m report the state of p.
+func (m *openTelemetryMetrics) observeSessionPool(p *sessionPool) {
+	if m == nil {
+		return
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.pool = p
+}
+
+// observeSessionPoolState is the callback of the gauges of m.
+func (m *openTelemetryMetrics) observeSessionPoolState(_ context.Context, result metric.BatchObserverResult) {
+	m.mu.Lock()
+	p := m.pool
+	m.mu.Unlock()
+	if p == nil {
+		return
+	}
+	withType := func(t string) []attribute.KeyValue {
+		return append(m.attrs[:len(m.attrs):len(m.attrs)], otelAttrKeyType.String(t))
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	obs := []metric.Observation{
+		m.openSessionCount.Observation(int64(p.numOpened)),
+		m.maxAllowedSessions.Observation(int64(p.MaxOpened)),
+		m.maxInUseSessions.Observation(int64(p.maxNumInUse)),
+	}
+	if p.TrackSessionHandles {
+		obs = append(obs, m.leakedSessions.Observation(int64(p.numLeakedSessionsLocked(time.Now()))))
+	}
+	result.Observe(m.attrs, obs...)
+	result.Observe(withType(tagNumInUseSessions.Value), m.sessionsCount.Observation(int64(p.numInUse)))
+	result.Observe(withType(tagNumBeingPrepared.Value), m.sessionsCount.Observation(int64(p.prepareReqs)))
+	result.Observe(withType(tagNumReadSessions.Value), m.sessionsCount.Observation(int64(p.numReads)))
+	result.Observe(withType(tagNumWriteSessions.Value), m.sessionsCount.Observation(int64(p.numWrites)))
+}
+
+// close stops the reporting of the session pool gauges.
+func (m *openTelemetryMetrics) close() {
+	m.observeSessionPool(nil)
+}
+
+// recordPoolStat records n on the OpenTelemetry counter that corresponds to
+// the OpenCensus measure s. Measures that are reported by gauges are ignored.
+func (m *openTelemetryMetrics) recordPoolStat(ctx context.Context, s *stats.Int64Measure, n int64) {
+	if m == nil {
+		return
+	}
+	var c metric.Int64Counter
+	switch s {
+	case GetSessionTimeoutsCount:
+		c = m.getSessionTimeouts
+	case AcquiredSessionsCount:
+		c = m.acquiredSessions
+	case ReleasedSessionsCount:
+		c = m.releasedSessions
+	default:
+		return
+	}
+	c.Add(ctx, n, m.attrs...)
+}
+
+// recordSessionAcquireLatency records the time since start, at which a
+// session of the given type ("read" or "write") was requested from the pool.
+func (m *openTelemetryMetrics) recordSessionAcquireLatency(ctx context.Context, start time.Time, sessionType string) {
+	if m == nil {
+		return
+	}
+	attrs := append(m.attrs[:len(m.attrs):len(m.attrs)], otelAttrKeyType.String(sessionType))
+	m.sessionAcquireLatency.Record(ctx, float64(time.Since(start))/float64(time.Millisecond), attrs...)
+}
+
+// recordGFELatency records the GFE latency in the server-timing header of md
+// for the given method, or that the header is missing.
+func (m *openTelemetryMetrics) recordGFELatency(ctx context.Context, md metadata.MD, keyMethod string) error {
+	if m == nil {
+		return nil
+	}
+	attrs := append(m.attrs[:len(m.attrs):len(m.attrs)], otelAttrKeyMethod.String(keyMethod))
+	if len(md.Get("server-timing")) == 0 {
+		m.gfeHeaderMissingCounts.Add(ctx, 1, attrs...)
+		return nil
+	}
+	serverTiming := md.Get("server-timing")[0]
+	gfeLatency, err := strconv.Atoi(strings.TrimPrefix(serverTiming, "gfet4t7; dur="))
+	if !strings.HasPrefix(serverTiming, "gfet4t7; dur=") || err != nil {
+		return err
+	}
+	m.gfeLatency.Record(ctx, float64(gfeLatency), attrs...)
+	return nil
+}
+
+// recordTransactionRetry records a retry of a read/write transaction for the
+// given reason.
+func (m *openTelemetryMetrics) recordTransactionRetry(ctx context.Context, reason string) {
+	if m == nil {
+		return
+	}
+	attrs := append(m.attrs[:len(m.attrs):len(m.attrs)], otelAttrKeyReason.String(reason))
+	m.transactionRetries.Add(ctx, 1, attrs...)
+}
@@ -0,0 +1,211 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package spanner
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	. "cloud.google.com/go/spanner/internal/testutil"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/metric/number"
+	"go.opentelemetry.io/otel/metric/sdkapi"
+)
+
+// fakeMeter is a metric MeterImpl that keeps the values that are recorded
+// with its instruments, keyed by instrument name and the value of the "type"
+// or "grpc_client_method" attribute, if any.
+type fakeMeter struct {
+	mu       sync.Mutex
+	values   map[string]float64
+	samples  map[string]int
+	callback sdkapi.AsyncBatchRunner
+}
+
+func newFakeMeter() *fakeMeter {
+	return &fakeMeter{values: map[string]float64{}, samples: map[string]int{}}
+}
+
+func (m *fakeMeter) key(name string, attrs []attribute.KeyValue) string {
+	for _, k := range []attribute.Key{otelAttrKeyType, otelAttrKeyMethod} {
+		for _, a := range attrs {
+			if a.Key == k {
+				return name + "/" + a.Value.AsString()
+			}
+		}
+	}
+	return name
+}
+
+func (m *fakeMeter) get(k string) (value float64, samples int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.values[k], m.samples[k]
+}
+
+// observe runs the callback of the gauges.
+func (m *fakeMeter) observe() {
+	m.mu.Lock()
+	callback := m.callback
+	m.mu.Unlock()
+	if callback == nil {
+		return
+	}
+	callback.Run(context.Background(), func(attrs []attribute.KeyValue, obs ...sdkapi.Observation) {
+		m.mu.Lock()
+		defer m.mu.Unlock()
+		for _, o := range obs {
+			n := o.Number()
+			m.values[m.key(o.AsyncImpl().Descriptor().Name(), attrs)] = n.CoerceToFloat64(o.AsyncImpl().Descriptor().NumberKind())
+		}
+	})
+}
+
+func (m *fakeMeter) RecordBatch(ctx context.Context, attrs []attribute.KeyValue, ms ...sdkapi.Measurement) {
+	for _, meas := range ms {
+		meas.SyncImpl().RecordOne(ctx, meas.Number(), attrs)
+	}
+}
+
+func (m *fakeMeter) NewSyncInstrument(d sdkapi.Descriptor) (sdkapi.SyncImpl, error) {
+	return &fakeSyncInstrument{m: m, d: d}, nil
+}
+
+func (m *fakeMeter) NewAsyncInstrument(d sdkapi.Descriptor, runner sdkapi.AsyncRunner) (sdkapi.AsyncImpl, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.callback = runner.(sdkapi.AsyncBatchRunner)
+	return fakeAsyncInstrument{d: d}, nil
+}
+
+type fakeMeterProvider struct {
+	m *fakeMeter
+}
+
+func (p fakeMeterProvider) Meter(string, ...metric.MeterOption) metric.Meter {
+	return metric.WrapMeterImpl(p.m)
+}
+
+type fakeSyncInstrument struct {
+	m *fakeMeter
+	d sdkapi.Descriptor
+}
+
+func (i *fakeSyncInstrument) Implementation() interface{}   { return i }
+func (i *fakeSyncInstrument) Descriptor() sdkapi.Descriptor { return i.d }
+
+func (i *fakeSyncInstrument) Bind(attrs []attribute.KeyValue) sdkapi.BoundSyncImpl {
+	panic("unused")
+}
+
+func (i *fakeSyncInstrument) RecordOne(_ context.Context, n number.Number, attrs []attribute.KeyValue) {
+	i.m.mu.Lock()
+	defer i.m.mu.Unlock()
+	k := i.m.key(i.d.Name(), attrs)
+	i.m.values[k] += n.CoerceToFloat64(i.d.NumberKind())
+	i.m.samples[k]++
+}
+
+type fakeAsyncInstrument struct {
+	d sdkapi.Descriptor
+}
+
+func (i fakeAsyncInstrument) Implementation() interface{}   { return i }
+func (i fakeAsyncInstrument) Descriptor() sdkapi.Descriptor { return i.d }
+
+func TestOpenTelemetryMetrics(t *testing.T) {
+	t.Parallel()
+
+	m := newFakeMeter()
+	_, client, teardown := setupMockedTestServerWithConfig(t, ClientConfig{
+		SessionPoolConfig: SessionPoolConfig{
+			MinOpened:           1,
+			MaxOpened:           10,
+			TrackSessionHandles: true,
+		},
+		OpenTelemetryMeterProvider: fakeMeterProvider{m: m},
+	})
+	defer teardown()
+	ctx := context.Background()
+
+	iter := client.Single().Query(ctx, NewStatement(SelectSingerIDAlbumIDAlbumTitleFromAlbums))
+	if err := iter.Do(func(*Row) error { return nil }); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.Apply(ctx, []*Mutation{Insert("foo", []string{"col1"}, []interface{}{"val1"})}); err != nil {
+		t.Fatal(err)
+	}
+	for _, k := range []string{"spanner/num_acquired_sessions", "spanner/num_released_sessions"} {
+		if got, _ := m.get(k); got != 2 {
+			t.Errorf("%s: got %v, want 2", k, got)
+		}
+	}
+	for _, k := range []string{"spanner/session_acquire_latency/read", "spanner/session_acquire_latency/write"} {
+		if _, got := m.get(k); got != 1 {
+			t.Errorf("%s: got %v samples, want 1", k, got)
+		}
+	}
+	latency, n := m.get("spanner/gfe_latency/commit")
+	missing, _ := m.get("spanner/gfe_header_missing_count/commit")
+	if n+int(missing) != 1 {
+		t.Errorf("commit: got %d GFE latencies and %v missing headers, want one in total", n, missing)
+	}
+	if n == 1 && latency != 123 {
+		t.Errorf("commit: got GFE latency %v, want 123", latency)
+	}
+
+	// A session that has been checked out for a long time is reported as
+	// leaked.
+	sh, err := client.idleSessions.take(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sh.mu.Lock()
+	sh.checkoutTime = time.Now().Add(-2 * leakedSessionAge)
+	sh.mu.Unlock()
+	m.observe()
+	for k, want := range map[string]float64{
+		"spanner/max_allowed_sessions":                     10,
+		"spanner/num_sessions_in_pool/num_in_use_sessions": 1,
+		"spanner/num_leaked_sessions":                      1,
+	} {
+		if got, _ := m.get(k); got != want {
+			t.Errorf("%s: got %v, want %v", k, got, want)
+		}
+	}
+	if got, _ := m.get("spanner/open_session_count"); got < 1 {
+		t.Errorf("spanner/open_session_count: got %v, want at least 1", got)
+	}
+	sh.recycle()
+	m.observe()
+	for _, k := range []string{"spanner/num_sessions_in_pool/num_in_use_sessions", "spanner/num_leaked_sessions"} {
+		if got, _ := m.get(k); got != 0 {
+			t.Errorf("%s after recycle: got %v, want 0", k, got)
+		}
+	}
+
+	// After Close, the gauges stop reporting.
+	client.Close()
+	m.mu.Lock()
+	m.values = map[string]float64{}
+	m.mu.Unlock()
+	m.observe()
+	if got, _ := m.get("spanner/max_allowed_sessions"); got != 0 {
+		t.Errorf("spanner/max_allowed_sessions after Close: got %v, want no value", got)
+	}
+}
@@ -59,6 +59,8 @@ func (c *Client) partitionedUpdate(ctx context.Context, statement Statement, opt
 	if sh != nil {
 		defer sh.recycle()
 	}
+	// Partitioned DML is executed by the leader of the database.
+	ctx = contextWithRouteToLeader(ctx, !c.disableRouteToLeader)
 
 	// Create the parameters and the SQL request, but without a transaction.
 	// The transaction reference will be added by the executePdml method.
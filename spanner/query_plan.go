@@ -0,0 +1,218 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package spanner
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"cloud.google.com/go/internal/protostruct"
+	sppb "google.golang.org/genproto/googleapis/spanner/v1"
+	"google.golang.org/grpc/codes"
+)
+
+// PlanNode is a node of a query plan, linked to its children. Use NewPlanTree
+// or RowIterator.PlanTree to build the tree of a query plan.
+type PlanNode struct {
+	// Index is the index of the node in the plan.
+	Index int32
+
+	// Kind is RELATIONAL for nodes that produce rows, and SCALAR for nodes
+	// that produce a single value.
+	Kind sppb.PlanNode_Kind
+
+	// DisplayName is the name of the operator of the node, such as
+	// "Distributed Union" or "Table Scan".
+	DisplayName string
+
+	// Description is the condensed representation of a SCALAR node, such as
+	// "($SingerId = 1)". It is empty for RELATIONAL nodes.
+	Description string
+
+	// Metadata holds the attributes of the node.
+	Metadata map[string]interface{}
+
+	// ExecutionStats holds the execution statistics of the node, such as
+	// its latency and the number of rows it returned. It is only set for
+	// plans of queries executed in PROFILE mode, for example with
+	// QueryWithStats.
+	ExecutionStats map[string]interface{}
+
+	// Children are the links to the children of the node.
+	Children []PlanNodeLink
+}
+
+// PlanNodeLink links a PlanNode to one of its children.
+type PlanNodeLink struct {
+	// Node is the child node.
+	Node *PlanNode
+
+	// Type is the type of the link, for example to tell apart the build and
+	// probe children of a hash join.
+	Type string
+
+	// Variable is the name of the output variable of the parent that the child
+	// represents, if any.
+	Variable string
+}
+
+// NewPlanTree links the nodes of plan and returns the root of the plan, which
+// is its first node.
+func NewPlanTree(plan *sppb.QueryPlan) (*PlanNode, error) {
+	if plan == nil || len(plan.PlanNodes) == 0 {
+		return nil, spannerErrorf(codes.InvalidArgument, "query plan has no nodes")
+	}
+	nodes := make([]*PlanNode, len(plan.PlanNodes))
+	for i, pn := range plan.PlanNodes {
+		if int(pn.Index) != i {
+			return nil, spannerErrorf(codes.InvalidArgument, "query plan node %d has index %d", i, pn.Index)
+		}
+		nodes[i] = &PlanNode{
+			Index:          pn.Index,
+			Kind:           pn.Kind,
+			DisplayName:    pn.DisplayName,
+			Description:    pn.GetShortRepresentation().GetDescription(),
+			Metadata:       protostruct.DecodeToMap(pn.Metadata),
+			ExecutionStats: protostruct.DecodeToMap(pn.ExecutionStats),
+		}
+	}
+	for i, pn := range plan.PlanNodes {
+		for _, cl := range pn.ChildLinks {
+			if cl.ChildIndex < 0 || int(cl.ChildIndex) >= len(nodes) {
+				return nil, spannerErrorf(codes.InvalidArgument, "query plan node %d links to unknown node %d", i, cl.ChildIndex)
+			}
+			nodes[i].Children = append(nodes[i].Children, PlanNodeLink{
+				Node:     nodes[cl.ChildIndex],
+				Type:     cl.Type,
+				Variable: cl.Variable,
+			})
+		}
+	}
+	return nodes[0], nil
+}
+
+// Walk calls f for n and, if f returns true, for its descendants in depth
+// first order. The depth of n is 0, of its children 1, and so on. A node that
+// is the child of several nodes is visited once for each of them.
+func (n *PlanNode) Walk(f func(node *PlanNode, depth int) bool) {
+	n.walk(f, 0)
+}
+
+func (n *PlanNode) walk(f func(node *PlanNode, depth int) bool, depth int) {
+	if !f(n, depth) {
+		return
+	}
+	for _, c := range n.Children {
+		c.Node.walk(f, depth+1)
+	}
+}
+
+// PlanTree returns the root of the query plan of the iterator, with its nodes
+// linked. The plan is available after Next returns iterator.Done for queries
+// executed in PLAN or PROFILE mode, for example with QueryWithStats.
+func (r *RowIterator) PlanTree() (*PlanNode, error) {
+	if r.QueryPlan == nil {
+		return nil, spannerErrorf(codes.FailedPrecondition, "no query plan available; run the query in PLAN or PROFILE mode and read all rows")
+	}
+	return NewPlanTree(r.QueryPlan)
+}
+
+// QueryStatistics holds the execution statistics of a query.
+type QueryStatistics struct {
+	// QueryText is the text of the query.
+	QueryText string
+
+	// ElapsedTime is the wall time that the query took.
+	ElapsedTime time.Duration
+
+	// CPUTime is the CPU time that the query took.
+	CPUTime time.Duration
+
+	// QueryPlanCreationTime is the time that was spent creating the plan of
+	// the query.
+	QueryPlanCreationTime time.Duration
+
+	// RowsReturned is the number of rows that the query returned.
+	RowsReturned int64
+
+	// RowsScanned is the number of rows that the query scanned.
+	RowsScanned int64
+
+	// OptimizerVersion is the version of the query optimizer that was used.
+	OptimizerVersion string
+
+	// Raw holds all statistics as returned by Cloud Spanner, including those
+	// without a field in QueryStatistics.
+	Raw map[string]interface{}
+}
+
+// Statistics returns the typed execution statistics of the query of the
+// iterator. They are available after Next returns iterator.Done for queries
+// executed in PROFILE mode, for example with QueryWithStats.
+//
+// Statistics that are missing, or that are not in a format that is understood,
+// are left at their zero values in the fields of the result, but are included
+// in its Raw map.
+func (r *RowIterator) Statistics() (*QueryStatistics, error) {
+	if r.QueryStats == nil {
+		return nil, spannerErrorf(codes.FailedPrecondition, "no query statistics available; run the query in PROFILE mode and read all rows")
+	}
+	return newQueryStatistics(r.QueryStats), nil
+}
+
+func newQueryStatistics(m map[string]interface{}) *QueryStatistics {
+	s := &QueryStatistics{Raw: m}
+	str := func(k string) string {
+		v, _ := m[k].(string)
+		return v
+	}
+	s.QueryText = str("query_text")
+	s.OptimizerVersion = str("optimizer_version")
+	s.ElapsedTime, _ = parseStatsDuration(str("elapsed_time"))
+	s.CPUTime, _ = parseStatsDuration(str("cpu_time"))
+	s.QueryPlanCreationTime, _ = parseStatsDuration(str("query_plan_creation_time"))
+	s.RowsReturned, _ = strconv.ParseInt(str("rows_returned"), 10, 64)
+	s.RowsScanned, _ = strconv.ParseInt(str("rows_scanned"), 10, 64)
+	return s
+}
+
+// parseStatsDuration parses a duration in the format of query statistics,
+// such as "1.23 msecs".
+func parseStatsDuration(s string) (time.Duration, error) {
+	fs := strings.Fields(s)
+	if len(fs) != 2 {
+		return 0, fmt.Errorf("invalid duration %q", s)
+	}
+	v, err := strconv.ParseFloat(fs[0], 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid duration %q: %v", s, err)
+	}
+	var unit time.Duration
+	switch fs[1] {
+	case "usecs", "usec":
+		unit = time.Microsecond
+	case "msecs", "msec":
+		unit = time.Millisecond
+	case "secs", "sec":
+		unit = time.Second
+	case "mins", "min":
+		unit = time.Minute
+	default:
+		return 0, fmt.Errorf("invalid duration %q: unknown unit", s)
+	}
+	return time.Duration(v * float64(unit)), nil
+}
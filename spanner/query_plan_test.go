@@ -0,0 +1,156 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package spanner
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	sppb "google.golang.org/genproto/googleapis/spanner/v1"
+	"google.golang.org/grpc/codes"
+	structpb "google.golang.org/protobuf/types/known/structpb"
+)
+
+func TestNewPlanTree(t *testing.T) {
+	stats, err := structpb.NewStruct(map[string]interface{}{"rows": map[string]interface{}{"total": "3"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	plan := &sppb.QueryPlan{PlanNodes: []*sppb.PlanNode{
+		{
+			Index:          0,
+			Kind:           sppb.PlanNode_RELATIONAL,
+			DisplayName:    "Distributed Union",
+			ChildLinks:     []*sppb.PlanNode_ChildLink{{ChildIndex: 1}, {ChildIndex: 2, Type: "Split Range"}},
+			ExecutionStats: stats,
+		},
+		{
+			Index:       1,
+			Kind:        sppb.PlanNode_RELATIONAL,
+			DisplayName: "Table Scan",
+			ChildLinks:  []*sppb.PlanNode_ChildLink{{ChildIndex: 3, Variable: "SingerId"}},
+		},
+		{
+			Index:               2,
+			Kind:                sppb.PlanNode_SCALAR,
+			DisplayName:         "Function",
+			ShortRepresentation: &sppb.PlanNode_ShortRepresentation{Description: "($SingerId = 1)"},
+		},
+		{
+			Index:       3,
+			Kind:        sppb.PlanNode_SCALAR,
+			DisplayName: "Reference",
+		},
+	}}
+	root, err := NewPlanTree(plan)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := root.ExecutionStats, map[string]interface{}{"rows": map[string]interface{}{"total": "3"}}; !testEqual(got, want) {
+		t.Errorf("execution stats: got %v, want %v", got, want)
+	}
+	if got, want := root.Children[1].Type, "Split Range"; got != want {
+		t.Errorf("link type: got %q, want %q", got, want)
+	}
+	if got, want := root.Children[0].Node.Children[0].Variable, "SingerId"; got != want {
+		t.Errorf("link variable: got %q, want %q", got, want)
+	}
+	var lines []string
+	root.Walk(func(n *PlanNode, depth int) bool {
+		lines = append(lines, fmt.Sprintf("%s%s%s", strings.Repeat("  ", depth), n.DisplayName, n.Description))
+		return n.Kind == sppb.PlanNode_RELATIONAL
+	})
+	want := []string{
+		"Distributed Union",
+		"  Table Scan",
+		"    Reference",
+		"  Function($SingerId = 1)",
+	}
+	if !testEqual(lines, want) {
+		t.Errorf("walk: got %q, want %q", lines, want)
+	}
+
+	for _, bad := range []*sppb.QueryPlan{
+		nil,
+		{},
+		{PlanNodes: []*sppb.PlanNode{{Index: 1}}},
+		{PlanNodes: []*sppb.PlanNode{{ChildLinks: []*sppb.PlanNode_ChildLink{{ChildIndex: 1}}}}},
+	} {
+		if _, err := NewPlanTree(bad); ErrCode(err) != codes.InvalidArgument {
+			t.Errorf("NewPlanTree(%v): got %v, want InvalidArgument", bad, err)
+		}
+	}
+}
+
+func TestRowIteratorStatistics(t *testing.T) {
+	r := &RowIterator{}
+	if _, err := r.Statistics(); ErrCode(err) != codes.FailedPrecondition {
+		t.Errorf("without stats: got %v, want FailedPrecondition", err)
+	}
+	if _, err := r.PlanTree(); ErrCode(err) != codes.FailedPrecondition {
+		t.Errorf("without plan: got %v, want FailedPrecondition", err)
+	}
+	r.QueryStats = map[string]interface{}{
+		"query_text":               "SELECT 1",
+		"elapsed_time":             "1.5 msecs",
+		"cpu_time":                 "800 usecs",
+		"query_plan_creation_time": "2 secs",
+		"rows_returned":            "1",
+		"rows_scanned":             "10",
+		"optimizer_version":        "4",
+		"remote_server_calls":      "0/0",
+	}
+	got, err := r.Statistics()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := &QueryStatistics{
+		QueryText:             "SELECT 1",
+		ElapsedTime:           1500 * time.Microsecond,
+		CPUTime:               800 * time.Microsecond,
+		QueryPlanCreationTime: 2 * time.Second,
+		RowsReturned:          1,
+		RowsScanned:           10,
+		OptimizerVersion:      "4",
+		Raw:                   r.QueryStats,
+	}
+	if !testEqual(got, want) {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+}
+
+func TestParseStatsDuration(t *testing.T) {
+	for _, test := range []struct {
+		in   string
+		want time.Duration
+	}{
+		{"12 usecs", 12 * time.Microsecond},
+		{"0.25 msecs", 250 * time.Microsecond},
+		{"1.5 secs", 1500 * time.Millisecond},
+		{"2 mins", 2 * time.Minute},
+	} {
+		got, err := parseStatsDuration(test.in)
+		if err != nil || got != test.want {
+			t.Errorf("%q: got (%v, %v), want %v", test.in, got, err, test.want)
+		}
+	}
+	for _, in := range []string{"", "1.5", "x secs", "1 hours"} {
+		if _, err := parseStatsDuration(in); err == nil {
+			t.Errorf("%q: got nil error", in)
+		}
+	}
+}
@@ -79,16 +79,33 @@ func (r *spannerRetryer) Retry(err error) (time.Duration, bool) {
 	return delay, true
 }
 
+// TransactionRetryInfo describes an attempt of a read/write transaction that
+// failed and that is retried. It is passed to ClientConfig.OnTransactionRetry.
+type TransactionRetryInfo struct {
+	// Attempt is the number of the attempt that failed, starting at 1.
+	Attempt int
+	// Err is the error of the attempt. It is either an Aborted error, whose
+	// description holds the reason that Cloud Spanner aborted the
+	// transaction, or a Session not found error.
+	Err error
+	// Delay is the time that the client waits before the next attempt. It is
+	// zero if the error was Session not found.
+	Delay time.Duration
+	// TransactionTag is the transaction tag of the transaction, if any.
+	TransactionTag string
+}
+
 // runWithRetryOnAbortedOrSessionNotFound executes the given function and
 // retries it if it returns an Aborted or Session not found error. The retry
 // is delayed if the error was Aborted. The delay between retries is the delay
 // returned by Cloud Spanner, or if none is returned, the calculated delay with
 // a minimum of 10ms and maximum of 32s. There is no delay before the retry if
-// the error was Session not found.
-func runWithRetryOnAbortedOrSessionNotFound(ctx context.Context, f func(context.Context) error) error {
+// the error was Session not found. If onRetry is not nil, it is called before
+// each retry.
+func runWithRetryOnAbortedOrSessionNotFound(ctx context.Context, f func(context.Context) error, onRetry func(context.Context, TransactionRetryInfo)) error {
 	retryer := onCodes(DefaultRetryBackoff, codes.Aborted)
 	funcWithRetry := func(ctx context.Context) error {
-		for {
+		for attempt := 1; ; attempt++ {
 			err := f(ctx)
 			if err == nil {
 				return nil
@@ -113,12 +130,18 @@ func runWithRetryOnAbortedOrSessionNotFound(ctx context.Context, f func(context.
 			}
 			if isSessionNotFoundError(retryErr) {
 				trace.TracePrintf(ctx, nil, "Retrying after Session not found")
+				if onRetry != nil {
+					onRetry(ctx, TransactionRetryInfo{Attempt: attempt, Err: err})
+				}
 				continue
 			}
 			delay, shouldRetry := retryer.Retry(retryErr)
 			if !shouldRetry {
 				return err
 			}
+			if onRetry != nil {
+				onRetry(ctx, TransactionRetryInfo{Attempt: attempt, Err: err, Delay: delay})
+			}
 			trace.TracePrintf(ctx, nil, "Backing off after ABORTED for %s, then retrying", delay)
 			if err := gax.Sleep(ctx, delay); err != nil {
 				return err
@@ -210,6 +210,12 @@ func (s *session) isValid() bool {
 	return s.valid
 }
 
+// routeToLeader returns true if the write preparation of the session should be
+// routed to the leader region of the database.
+func (s *session) routeToLeader() bool {
+	return s.pool != nil && s.pool.sc != nil && !s.pool.sc.disableRouteToLeader
+}
+
 // isWritePrepared returns true if the session is prepared for write.
 func (s *session) isWritePrepared() bool {
 	s.mu.Lock()
@@ -363,7 +369,7 @@ func (s *session) prepareForWrite(ctx context.Context) error {
 	if s.isWritePrepared() {
 		return nil
 	}
-	tx, err := beginTransaction(contextWithOutgoingMetadata(ctx, s.md), s.getID(), s.client)
+	tx, err := beginTransaction(contextWithRouteToLeader(contextWithOutgoingMetadata(ctx, s.md), s.routeToLeader()), s.getID(), s.client)
 	// Session not found should cause the session to be removed from the pool.
 	if isSessionNotFoundError(err) {
 		s.pool.remove(s, false)
@@ -586,10 +592,13 @@ type sessionPool struct {
 
 	// tagMap is a map of all tags that are associated with the emitted metrics.
 	tagMap *tag.Map
+
+	// otm records the metrics of the pool with OpenTelemetry, if enabled.
+	otm *openTelemetryMetrics
 }
 
 // newSessionPool creates a new session pool.
-func newSessionPool(sc *sessionClient, config SessionPoolConfig) (*sessionPool, error) {
+func newSessionPool(sc *sessionClient, config SessionPoolConfig, otm *openTelemetryMetrics) (*sessionPool, error) {
 	if err := config.validate(); err != nil {
 		return nil, err
 	}
@@ -600,6 +609,7 @@ func newSessionPool(sc *sessionClient, config SessionPoolConfig) (*sessionPool,
 		SessionPoolConfig: config,
 		mw:                newMaintenanceWindow(config.MaxOpened),
 		rand:              rand.New(rand.NewSource(time.Now().UnixNano())),
+		otm:               otm,
 	}
 	if config.HealthCheckWorkers == 0 {
 		// With 10 workers and assuming average latency of 5ms for
@@ -665,6 +675,7 @@ func (p *sessionPool) recordStat(ctx context.Context, m *stats.Int64Measure, n i
 		logf(p.sc.logger, "Failed to tag metrics, error: %v", err)
 	}
 	recordStat(ctx, m, n)
+	p.otm.recordPoolStat(ctx, m, n)
 }
 
 func (p *sessionPool) initPool(numSessions uint64) error {
@@ -850,6 +861,22 @@ func (p *sessionPool) getTrackedSessionHandleStacksLocked() string {
 	return stackTraces
 }
 
+// numLeakedSessionsLocked returns the number of tracked session handles that
+// were checked out of the pool more than leakedSessionAge before now. This
+// method requires the caller to have locked p.mu.
+func (p *sessionPool) numLeakedSessionsLocked(now time.Time) int {
+	n := 0
+	for element := p.trackedSessionHandles.Front(); element != nil; element = element.Next() {
+		sh := element.Value.(*sessionHandle)
+		sh.mu.Lock()
+		if !sh.checkoutTime.IsZero() && now.Sub(sh.checkoutTime) > leakedSessionAge {
+			n++
+		}
+		sh.mu.Unlock()
+	}
+	return n
+}
+
 // shouldPrepareWriteLocked returns true if we should prepare more sessions for write.
 func (p *sessionPool) shouldPrepareWriteLocked() bool {
 	return !p.disableBackgroundPrepareSessions && float64(p.numOpened)*p.WriteSessions > float64(p.idleWriteList.Len()+int(p.prepareReqs))
@@ -903,6 +930,7 @@ func (p *sessionPool) isHealthy(s *session) bool {
 // for read operations.
 func (p *sessionPool) take(ctx context.Context) (*sessionHandle, error) {
 	trace.TracePrintf(ctx, nil, "Acquiring a read-only session")
+	start := time.Now()
 	for {
 		var s *session
 
@@ -938,6 +966,7 @@ func (p *sessionPool) take(ctx context.Context) (*sessionHandle, error) {
 				continue
 			}
 			p.incNumInUse(ctx)
+			p.otm.recordSessionAcquireLatency(ctx, start, "read")
 			return p.newSessionHandle(s), nil
 		}
 
@@ -982,6 +1011,7 @@ func (p *sessionPool) take(ctx context.Context) (*sessionHandle, error) {
 // returned should be used for read write transactions.
 func (p *sessionPool) takeWriteSession(ctx context.Context) (*sessionHandle, error) {
 	trace.TracePrintf(ctx, nil, "Acquiring a read-write session")
+	start := time.Now()
 	for {
 		var (
 			s   *session
@@ -1070,6 +1100,7 @@ func (p *sessionPool) takeWriteSession(ctx context.Context) (*sessionHandle, err
 			}
 		}
 		p.incNumInUse(ctx)
+		p.otm.recordSessionAcquireLatency(ctx, start, "write")
 		return p.newSessionHandle(s), nil
 	}
 }
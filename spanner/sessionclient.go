@@ -95,6 +95,10 @@ type sessionClient struct {
 	batchTimeout  time.Duration
 	logger        *log.Logger
 	callOptions   *vkit.CallOptions
+
+	// disableRouteToLeader disables routing the creation of sessions to the
+	// leader region of the database.
+	disableRouteToLeader bool
 }
 
 // newSessionClient creates a session client to use for a database.
@@ -131,7 +135,7 @@ func (sc *sessionClient) createSession(ctx context.Context) (*session, error) {
 	if err != nil {
 		return nil, err
 	}
-	ctx = contextWithOutgoingMetadata(ctx, sc.md)
+	ctx = contextWithRouteToLeader(contextWithOutgoingMetadata(ctx, sc.md), !sc.disableRouteToLeader)
 	var md metadata.MD
 	sid, err := client.CreateSession(ctx, &sppb.CreateSessionRequest{
 		Database: sc.database,
@@ -232,7 +236,7 @@ func (sc *sessionClient) batchCreateSessions(createSessionCount int32, distribut
 func (sc *sessionClient) executeBatchCreateSessions(client *vkit.Client, createCount int32, labels map[string]string, md metadata.MD, consumer sessionConsumer) {
 	ctx, cancel := context.WithTimeout(context.Background(), sc.batchTimeout)
 	defer cancel()
-	ctx = contextWithOutgoingMetadata(ctx, sc.md)
+	ctx = contextWithRouteToLeader(contextWithOutgoingMetadata(ctx, sc.md), !sc.disableRouteToLeader)
 
 	ctx = trace.StartSpan(ctx, "cloud.google.com/go/spanner.BatchCreateSessions")
 	defer func() { trace.EndSpan(ctx, nil) }()
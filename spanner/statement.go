@@ -35,6 +35,11 @@ import (
 // statement with unbound parameters. On the other hand, it is allowable to
 // bind parameter names that are not used.
 //
+// Statements for databases that use the PostgreSQL dialect instead use
+// positional placeholders: '$' followed by the parameter's position, starting
+// at 1. The parameter at position n is bound with the name "pn". See
+// NewPositionalStatement.
+//
 // See the documentation of the Row type for how Go types are mapped to Cloud
 // Spanner types.
 type Statement struct {
@@ -47,6 +52,17 @@ func NewStatement(sql string) Statement {
 	return Statement{SQL: sql, Params: map[string]interface{}{}}
 }
 
+// NewPositionalStatement returns a Statement with the given SQL, in which
+// params are bound to the positional parameters $1, $2, ... of a statement
+// for a database that uses the PostgreSQL dialect.
+func NewPositionalStatement(sql string, params ...interface{}) Statement {
+	stmt := NewStatement(sql)
+	for i, p := range params {
+		stmt.Params[fmt.Sprintf("p%d", i+1)] = p
+	}
+	return stmt
+}
+
 // convertParams converts a statement's parameters into proto Param and
 // ParamTypes.
 func (s *Statement) convertParams() (*structpb.Struct, map[string]*sppb.Type, error) {
@@ -193,3 +193,11 @@ func TestNewStatement(t *testing.T) {
 		t.Errorf("got %q, want %q", got, want)
 	}
 }
+
+func TestNewPositionalStatement(t *testing.T) {
+	s := NewPositionalStatement("SELECT * FROM t WHERE a = $1 AND b = $2", int64(1), "x")
+	want := map[string]interface{}{"p1": int64(1), "p2": "x"}
+	if !testEqual(s.Params, want) {
+		t.Errorf("got %v, want %v", s.Params, want)
+	}
+}
@@ -42,6 +42,7 @@ var (
 	tagNumReadSessions  = tag.Tag{Key: tagKeyType, Value: "num_read_sessions"}
 	tagNumWriteSessions = tag.Tag{Key: tagKeyType, Value: "num_write_prepared_sessions"}
 	tagKeyMethod        = tag.MustNewKey("grpc_client_method")
+	tagKeyReason        = tag.MustNewKey("reason")
 	// gfeLatencyMetricsEnabled is used to track if GFELatency and GFEHeaderMissingCount need to be recorded
 	gfeLatencyMetricsEnabled = false
 	// mutex to avoid data race in reading/writing the above flag
@@ -198,9 +199,29 @@ var (
 		Aggregation: view.Count(),
 		TagKeys:     append(tagCommonKeys, tagKeyMethod),
 	}
+
+	// TransactionRetriesCount is a measure of the number of read/write
+	// transaction attempts that failed and were retried. The "reason" tag is
+	// "aborted" or "session_not_found".
+	// It is EXPERIMENTAL and subject to change or removal without notice.
+	TransactionRetriesCount = stats.Int64(
+		statsPrefix+"num_transaction_retries",
+		"The number of read/write transaction attempts that failed and were retried",
+		stats.UnitDimensionless,
+	)
+
+	// TransactionRetriesCountView is a view of the total number of
+	// TransactionRetriesCount, by reason.
+	// It is EXPERIMENTAL and subject to change or removal without notice.
+	TransactionRetriesCountView = &view.View{
+		Measure:     TransactionRetriesCount,
+		Aggregation: view.Count(),
+		TagKeys:     append(tagCommonKeys[:len(tagCommonKeys):len(tagCommonKeys)], tagKeyReason),
+	}
 )
 
-// EnableStatViews enables all views of metrics relate to session management.
+// EnableStatViews enables all views of metrics relate to session management
+// and transaction retries.
 func EnableStatViews() error {
 	return view.Register(
 		OpenSessionCountView,
@@ -210,6 +231,7 @@ func EnableStatViews() error {
 		GetSessionTimeoutsCountView,
 		AcquiredSessionsCountView,
 		ReleasedSessionsCountView,
+		TransactionRetriesCountView,
 	)
 }
 
@@ -275,7 +297,19 @@ func captureGFELatencyStats(ctx context.Context, md metadata.MD, keyMethod strin
 	return nil
 }
 
+// gfeLatencyMetricsEnabled reports whether the GFE latency of the RPCs of the
+// client with the given tags is recorded, with OpenCensus or OpenTelemetry.
+func (ct *commonTags) gfeLatencyMetricsEnabled() bool {
+	return ct != nil && (getGFELatencyMetricsFlag() || ct.otm != nil)
+}
+
 func createContextAndCaptureGFELatencyMetrics(ctx context.Context, ct *commonTags, md metadata.MD, keyMethod string) error {
+	if err := ct.otm.recordGFELatency(ctx, md, keyMethod); err != nil {
+		return err
+	}
+	if !getGFELatencyMetricsFlag() {
+		return nil
+	}
 	var ctxGFE, err = tag.New(ctx,
 		tag.Upsert(tagKeyClientID, ct.clientID),
 		tag.Upsert(tagKeyDatabase, ct.database),
@@ -288,7 +322,36 @@ func createContextAndCaptureGFELatencyMetrics(ctx context.Context, ct *commonTag
 	return captureGFELatencyStats(ctxGFE, md, keyMethod)
 }
 
-func getCommonTags(sc *sessionClient) *commonTags {
+// transactionRetryReason returns the value of the reason tag for a retry of a
+// transaction after err.
+func transactionRetryReason(err error) string {
+	if isSessionNotFoundError(err) {
+		return "session_not_found"
+	}
+	return "aborted"
+}
+
+// recordTransactionRetry records a retry of a read/write transaction.
+func recordTransactionRetry(ctx context.Context, ct *commonTags, info TransactionRetryInfo) {
+	if ct == nil {
+		return
+	}
+	reason := transactionRetryReason(info.Err)
+	ct.otm.recordTransactionRetry(ctx, reason)
+	ctx, err := tag.New(ctx,
+		tag.Upsert(tagKeyClientID, ct.clientID),
+		tag.Upsert(tagKeyDatabase, ct.database),
+		tag.Upsert(tagKeyInstance, ct.instance),
+		tag.Upsert(tagKeyLibVersion, ct.libVersion),
+		tag.Upsert(tagKeyReason, reason),
+	)
+	if err != nil {
+		return
+	}
+	recordStat(ctx, TransactionRetriesCount, 1)
+}
+
+func getCommonTags(sc *sessionClient, otm *openTelemetryMetrics) *commonTags {
 	_, instance, database, err := parseDatabaseName(sc.database)
 	if err != nil {
 		return nil
@@ -298,6 +361,7 @@ func getCommonTags(sc *sessionClient) *commonTags {
 		database:   database,
 		instance:   instance,
 		libVersion: internal.Version,
+		otm:        otm,
 	}
 }
 
@@ -311,4 +375,6 @@ type commonTags struct {
 	instance string
 	// Library Version
 	libVersion string
+	// otm records the GFE latency with OpenTelemetry, if enabled.
+	otm *openTelemetryMetrics
 }
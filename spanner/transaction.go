@@ -76,6 +76,14 @@ type txReadOnly struct {
 	// qo provides options for executing a sql query.
 	qo QueryOptions
 
+	// ro provides default options for reading rows.
+	ro ReadOptions
+
+	// routeToLeader is true if the RPCs of the transaction should be routed to
+	// the leader region of the database. It is only set for read/write
+	// transactions.
+	routeToLeader bool
+
 	// txOpts provides options for a transaction.
 	txOpts TransactionOptions
 
@@ -97,6 +105,22 @@ type TransactionOptions struct {
 	CommitPriority sppb.RequestOptions_Priority
 }
 
+// merge combines two TransactionOptions, with the options of the parameter
+// taking precedence over the options of to.
+func (to TransactionOptions) merge(opts TransactionOptions) TransactionOptions {
+	merged := to
+	if opts.CommitOptions.ReturnCommitStats {
+		merged.CommitOptions.ReturnCommitStats = true
+	}
+	if opts.TransactionTag != "" {
+		merged.TransactionTag = opts.TransactionTag
+	}
+	if opts.CommitPriority != sppb.RequestOptions_PRIORITY_UNSPECIFIED {
+		merged.CommitPriority = opts.CommitPriority
+	}
+	return merged
+}
+
 func (to *TransactionOptions) requestPriority() sppb.RequestOptions_Priority {
 	return to.CommitPriority
 }
@@ -139,6 +163,25 @@ type ReadOptions struct {
 	RequestTag string
 }
 
+// merge combines two ReadOptions, with the options of the parameter taking
+// precedence over the options of ro.
+func (ro ReadOptions) merge(opts ReadOptions) ReadOptions {
+	merged := ro
+	if opts.Index != "" {
+		merged.Index = opts.Index
+	}
+	if opts.Limit > 0 {
+		merged.Limit = opts.Limit
+	}
+	if opts.Priority != sppb.RequestOptions_PRIORITY_UNSPECIFIED {
+		merged.Priority = opts.Priority
+	}
+	if opts.RequestTag != "" {
+		merged.RequestTag = opts.RequestTag
+	}
+	return merged
+}
+
 // ReadWithOptions returns a RowIterator for reading multiple rows from the
 // database. Pass a ReadOptions to modify the read operation.
 func (t *txReadOnly) ReadWithOptions(ctx context.Context, table string, keys KeySet, columns []string, opts *ReadOptions) (ri *RowIterator) {
@@ -162,20 +205,19 @@ func (t *txReadOnly) ReadWithOptions(ctx context.Context, table string, keys Key
 		// Might happen if transaction is closed in the middle of a API call.
 		return &RowIterator{err: errSessionClosed(sh)}
 	}
-	index := ""
-	limit := 0
-	prio := sppb.RequestOptions_PRIORITY_UNSPECIFIED
-	requestTag := ""
+	ro := t.ro
 	if opts != nil {
-		index = opts.Index
-		if opts.Limit > 0 {
-			limit = opts.Limit
-		}
-		prio = opts.Priority
-		requestTag = opts.RequestTag
+		ro = ro.merge(*opts)
 	}
+	index := ro.Index
+	limit := 0
+	if ro.Limit > 0 {
+		limit = ro.Limit
+	}
+	prio := ro.Priority
+	requestTag := ro.RequestTag
 	return streamWithReplaceSessionFunc(
-		contextWithOutgoingMetadata(ctx, sh.getMetadata()),
+		contextWithRouteToLeader(contextWithOutgoingMetadata(ctx, sh.getMetadata()), t.routeToLeader),
 		sh.session.logger,
 		func(ctx context.Context, resumeToken []byte) (streamingReceiver, error) {
 			client, err := client.StreamingRead(ctx,
@@ -194,7 +236,7 @@ func (t *txReadOnly) ReadWithOptions(ctx context.Context, table string, keys Key
 				return client, err
 			}
 			md, err := client.Header()
-			if getGFELatencyMetricsFlag() && md != nil && t.ct != nil {
+			if md != nil && t.ct.gfeLatencyMetricsEnabled() {
 				if err := createContextAndCaptureGFELatencyMetrics(ctx, t.ct, md, "ReadWithOptions"); err != nil {
 					trace.TracePrintf(ctx, nil, "Error in recording GFE Latency. Try disabling and rerunning. Error: %v", err)
 				}
@@ -336,10 +378,7 @@ func createRequestOptions(prio sppb.RequestOptions_Priority, requestTag, transac
 // AnalyzeQuery to get just the plan.
 func (t *txReadOnly) Query(ctx context.Context, statement Statement) *RowIterator {
 	mode := sppb.ExecuteSqlRequest_NORMAL
-	return t.query(ctx, statement, QueryOptions{
-		Mode:    &mode,
-		Options: t.qo.Options,
-	})
+	return t.query(ctx, statement, t.qo.merge(QueryOptions{Mode: &mode}))
 }
 
 // QueryWithOptions executes a SQL statment against the database. It returns
@@ -354,19 +393,13 @@ func (t *txReadOnly) QueryWithOptions(ctx context.Context, statement Statement,
 // be populated with a query plan and execution statistics.
 func (t *txReadOnly) QueryWithStats(ctx context.Context, statement Statement) *RowIterator {
 	mode := sppb.ExecuteSqlRequest_PROFILE
-	return t.query(ctx, statement, QueryOptions{
-		Mode:    &mode,
-		Options: t.qo.Options,
-	})
+	return t.query(ctx, statement, t.qo.merge(QueryOptions{Mode: &mode}))
 }
 
 // AnalyzeQuery returns the query plan for statement.
 func (t *txReadOnly) AnalyzeQuery(ctx context.Context, statement Statement) (*sppb.QueryPlan, error) {
 	mode := sppb.ExecuteSqlRequest_PLAN
-	iter := t.query(ctx, statement, QueryOptions{
-		Mode:    &mode,
-		Options: t.qo.Options,
-	})
+	iter := t.query(ctx, statement, t.qo.merge(QueryOptions{Mode: &mode}))
 	defer iter.Stop()
 	for {
 		_, err := iter.Next()
@@ -392,7 +425,7 @@ func (t *txReadOnly) query(ctx context.Context, statement Statement, options Que
 	}
 	client := sh.getClient()
 	return streamWithReplaceSessionFunc(
-		contextWithOutgoingMetadata(ctx, sh.getMetadata()),
+		contextWithRouteToLeader(contextWithOutgoingMetadata(ctx, sh.getMetadata()), t.routeToLeader),
 		sh.session.logger,
 		func(ctx context.Context, resumeToken []byte) (streamingReceiver, error) {
 			req.ResumeToken = resumeToken
@@ -402,7 +435,7 @@ func (t *txReadOnly) query(ctx context.Context, statement Statement, options Que
 				return client, err
 			}
 			md, err := client.Header()
-			if getGFELatencyMetricsFlag() && md != nil && t.ct != nil {
+			if md != nil && t.ct.gfeLatencyMetricsEnabled() {
 				if err := createContextAndCaptureGFELatencyMetrics(ctx, t.ct, md, "query"); err != nil {
 					trace.TracePrintf(ctx, nil, "Error in recording GFE Latency. Try disabling and rerunning. Error: %v", err)
 				}
@@ -577,7 +610,7 @@ func (t *ReadOnlyTransaction) begin(ctx context.Context) error {
 			},
 		}, gax.WithGRPCOptions(grpc.Header(&md)))
 
-		if getGFELatencyMetricsFlag() && md != nil && t.ct != nil {
+		if md != nil && t.ct.gfeLatencyMetricsEnabled() {
 			if err := createContextAndCaptureGFELatencyMetrics(ctx, t.ct, md, "begin_BeginTransaction"); err != nil {
 				trace.TracePrintf(ctx, nil, "Error in recording GFE Latency. Try disabling and rerunning. Error: %v", err)
 			}
@@ -908,10 +941,7 @@ func (t *ReadWriteTransaction) BufferWrite(ms []*Mutation) error {
 // commit.
 func (t *ReadWriteTransaction) Update(ctx context.Context, stmt Statement) (rowCount int64, err error) {
 	mode := sppb.ExecuteSqlRequest_NORMAL
-	return t.update(ctx, stmt, QueryOptions{
-		Mode:    &mode,
-		Options: t.qo.Options,
-	})
+	return t.update(ctx, stmt, t.qo.merge(QueryOptions{Mode: &mode}))
 }
 
 // UpdateWithOptions executes a DML statement against the database. It returns
@@ -929,9 +959,9 @@ func (t *ReadWriteTransaction) update(ctx context.Context, stmt Statement, opts
 		return 0, err
 	}
 	var md metadata.MD
-	resultSet, err := sh.getClient().ExecuteSql(contextWithOutgoingMetadata(ctx, sh.getMetadata()), req, gax.WithGRPCOptions(grpc.Header(&md)))
+	resultSet, err := sh.getClient().ExecuteSql(contextWithRouteToLeader(contextWithOutgoingMetadata(ctx, sh.getMetadata()), t.routeToLeader), req, gax.WithGRPCOptions(grpc.Header(&md)))
 
-	if getGFELatencyMetricsFlag() && md != nil && t.ct != nil {
+	if md != nil && t.ct.gfeLatencyMetricsEnabled() {
 		if err := createContextAndCaptureGFELatencyMetrics(ctx, t.ct, md, "update"); err != nil {
 			trace.TracePrintf(ctx, nil, "Error in recording GFE Latency. Try disabling and rerunning. Error: %v", err)
 		}
@@ -998,7 +1028,7 @@ func (t *ReadWriteTransaction) batchUpdateWithOptions(ctx context.Context, stmts
 	}
 
 	var md metadata.MD
-	resp, err := sh.getClient().ExecuteBatchDml(contextWithOutgoingMetadata(ctx, sh.getMetadata()), &sppb.ExecuteBatchDmlRequest{
+	resp, err := sh.getClient().ExecuteBatchDml(contextWithRouteToLeader(contextWithOutgoingMetadata(ctx, sh.getMetadata()), t.routeToLeader), &sppb.ExecuteBatchDmlRequest{
 		Session:        sh.getID(),
 		Transaction:    ts,
 		Statements:     sppbStmts,
@@ -1006,7 +1036,7 @@ func (t *ReadWriteTransaction) batchUpdateWithOptions(ctx context.Context, stmts
 		RequestOptions: createRequestOptions(opts.Priority, opts.RequestTag, t.txOpts.TransactionTag),
 	}, gax.WithGRPCOptions(grpc.Header(&md)))
 
-	if getGFELatencyMetricsFlag() && md != nil && t.ct != nil {
+	if md != nil && t.ct.gfeLatencyMetricsEnabled() {
 		if err := createContextAndCaptureGFELatencyMetrics(ctx, t.ct, md, "batchUpdateWithOptions"); err != nil {
 			trace.TracePrintf(ctx, nil, "Error in recording GFE Latency. Try disabling and rerunning. Error: %v", ToSpannerError(err))
 		}
@@ -1082,7 +1112,7 @@ func (t *ReadWriteTransaction) begin(ctx context.Context) error {
 		t.state = txActive
 		return nil
 	}
-	tx, err := beginTransaction(contextWithOutgoingMetadata(ctx, t.sh.getMetadata()), t.sh.getID(), t.sh.getClient())
+	tx, err := beginTransaction(contextWithRouteToLeader(contextWithOutgoingMetadata(ctx, t.sh.getMetadata()), t.routeToLeader), t.sh.getID(), t.sh.getClient())
 	if err == nil {
 		t.tx = tx
 		t.state = txActive
@@ -1127,7 +1157,7 @@ func (t *ReadWriteTransaction) commit(ctx context.Context, options CommitOptions
 	}
 
 	var md metadata.MD
-	res, e := client.Commit(contextWithOutgoingMetadata(ctx, t.sh.getMetadata()), &sppb.CommitRequest{
+	res, e := client.Commit(contextWithRouteToLeader(contextWithOutgoingMetadata(ctx, t.sh.getMetadata()), t.routeToLeader), &sppb.CommitRequest{
 		Session: sid,
 		Transaction: &sppb.CommitRequest_TransactionId{
 			TransactionId: t.tx,
@@ -1136,7 +1166,7 @@ func (t *ReadWriteTransaction) commit(ctx context.Context, options CommitOptions
 		Mutations:         mPb,
 		ReturnCommitStats: options.ReturnCommitStats,
 	}, gax.WithGRPCOptions(grpc.Header(&md)))
-	if getGFELatencyMetricsFlag() && md != nil && t.ct != nil {
+	if md != nil && t.ct.gfeLatencyMetricsEnabled() {
 		if err := createContextAndCaptureGFELatencyMetrics(ctx, t.ct, md, "commit"); err != nil {
 			trace.TracePrintf(ctx, nil, "Error in recording GFE Latency. Try disabling and rerunning. Error: %v", err)
 		}
@@ -1169,7 +1199,7 @@ func (t *ReadWriteTransaction) rollback(ctx context.Context) {
 	if sid == "" || client == nil {
 		return
 	}
-	err := client.Rollback(contextWithOutgoingMetadata(ctx, t.sh.getMetadata()), &sppb.RollbackRequest{
+	err := client.Rollback(contextWithRouteToLeader(contextWithOutgoingMetadata(ctx, t.sh.getMetadata()), t.routeToLeader), &sppb.RollbackRequest{
 		Session:       sid,
 		TransactionId: t.tx,
 	})
@@ -1271,7 +1301,9 @@ func NewReadWriteStmtBasedTransactionWithOptions(ctx context.Context, c *Client,
 	t.txReadOnly.sh = sh
 	t.txReadOnly.txReadEnv = t
 	t.txReadOnly.qo = c.qo
-	t.txOpts = options
+	t.txReadOnly.ro = c.ro
+	t.txReadOnly.routeToLeader = !c.disableRouteToLeader
+	t.txOpts = c.txo.merge(options)
 	t.ct = c.ct
 
 	if err = t.begin(ctx); err != nil {
@@ -1324,6 +1356,9 @@ type writeOnlyTransaction struct {
 	transactionTag string
 	// commitPriority is the RPC priority to use for the commit operation.
 	commitPriority sppb.RequestOptions_Priority
+	// routeToLeader is true if the commit should be routed to the leader
+	// region of the database.
+	routeToLeader bool
 }
 
 // applyAtLeastOnce commits a list of mutations to Cloud Spanner at least once,
@@ -1356,7 +1391,7 @@ func (t *writeOnlyTransaction) applyAtLeastOnce(ctx context.Context, ms ...*Muta
 			}
 			defer sh.recycle()
 		}
-		res, err := sh.getClient().Commit(contextWithOutgoingMetadata(ctx, sh.getMetadata()), &sppb.CommitRequest{
+		res, err := sh.getClient().Commit(contextWithRouteToLeader(contextWithOutgoingMetadata(ctx, sh.getMetadata()), t.routeToLeader), &sppb.CommitRequest{
 			Session: sh.getID(),
 			Transaction: &sppb.CommitRequest_SingleUseTransaction{
 				SingleUseTransaction: &sppb.TransactionOptions{
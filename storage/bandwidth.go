@@ -0,0 +1,96 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package storage
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	gax "github.com/googleapis/gax-go/v2"
+)
+
+// limiterPieceSize is the largest amount of data that a Reader or Writer with
+// a BandwidthLimiter transfers at once, so that the data flows smoothly.
+const limiterPieceSize = 64 << 10
+
+// Progress describes the progress of the transfer of a Reader or Writer.
+type Progress struct {
+	// Bytes is the number of bytes of object data transferred so far.
+	Bytes int64
+
+	// Elapsed is the time since the transfer started.
+	Elapsed time.Duration
+
+	// Rate is the average rate of the transfer so far, in bytes per second.
+	Rate float64
+}
+
+func newProgress(bytes int64, start time.Time) Progress {
+	p := Progress{Bytes: bytes, Elapsed: time.Since(start)}
+	if p.Elapsed > 0 {
+		p.Rate = float64(bytes) / p.Elapsed.Seconds()
+	}
+	return p
+}
+
+// A BandwidthLimiter caps the rate at which the Readers and Writers that use
+// it transfer object data. One BandwidthLimiter can be shared by any number of
+// Readers and Writers to cap their combined rate, for example to keep
+// background transfers from saturating a network link. A BandwidthLimiter is
+// safe for concurrent use.
+//
+// The rate may briefly exceed the limit by up to one second worth of data
+// after a period of lower use.
+type BandwidthLimiter struct {
+	rate float64 // bytes per second
+
+	mu     sync.Mutex
+	tokens float64 // bytes that may be transferred now; negative if in debt
+	last   time.Time
+}
+
+// NewBandwidthLimiter returns a BandwidthLimiter that allows bytesPerSecond
+// bytes per second.
+func NewBandwidthLimiter(bytesPerSecond int64) (*BandwidthLimiter, error) {
+	if bytesPerSecond <= 0 {
+		return nil, fmt.Errorf("storage: bandwidth limit must be positive, got %d", bytesPerSecond)
+	}
+	return &BandwidthLimiter{
+		rate:   float64(bytesPerSecond),
+		tokens: float64(bytesPerSecond),
+		last:   time.Now(),
+	}, nil
+}
+
+// wait reserves n bytes and waits until the limit allows them to be
+// transferred, or ctx is done.
+func (l *BandwidthLimiter) wait(ctx context.Context, n int) error {
+	l.mu.Lock()
+	now := time.Now()
+	l.tokens += now.Sub(l.last).Seconds() * l.rate
+	if l.tokens > l.rate {
+		l.tokens = l.rate
+	}
+	l.last = now
+	l.tokens -= float64(n)
+	debt := l.tokens
+	l.mu.Unlock()
+	if debt >= 0 {
+		return nil
+	}
+	return gax.Sleep(ctx, time.Duration(-debt/l.rate*float64(time.Second)))
+}
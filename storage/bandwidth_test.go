@@ -0,0 +1,109 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package storage
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"google.golang.org/api/googleapi"
+	"google.golang.org/api/option"
+)
+
+func TestNewBandwidthLimiter(t *testing.T) {
+	if _, err := NewBandwidthLimiter(0); err == nil {
+		t.Error("got nil error for a limit of zero")
+	}
+}
+
+func TestReaderBandwidthAndProgress(t *testing.T) {
+	data := bytes.Repeat([]byte("x"), 150<<10)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write(data)
+	}))
+	defer srv.Close()
+	client, err := NewClient(context.Background(), option.WithEndpoint(srv.URL+"/storage/v1/"), option.WithoutAuthentication())
+	if err != nil {
+		t.Fatal(err)
+	}
+	r, err := client.Bucket("bucket").Object("obj").NewReader(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	// The first 100 KiB are allowed at once, and the rest takes half a second.
+	r.BandwidthLimiter, err = NewBandwidthLimiter(100 << 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var last Progress
+	r.OnProgress = func(p Progress) { last = p }
+	start := time.Now()
+	got, err := ioutil.ReadAll(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, data) {
+		t.Errorf("got %d bytes, want %d", len(got), len(data))
+	}
+	if d := time.Since(start); d < 400*time.Millisecond {
+		t.Errorf("read took %v, want at least 400ms", d)
+	}
+	if last.Bytes != int64(len(data)) || last.Rate <= 0 {
+		t.Errorf("got progress %+v, want %d bytes", last, len(data))
+	}
+}
+
+func TestWriterBandwidthAndProgress(t *testing.T) {
+	s := &resumableServer{}
+	srv := httptest.NewServer(s)
+	defer srv.Close()
+	s.url = srv.URL
+	client, err := NewClient(context.Background(), option.WithEndpoint(srv.URL+"/storage/v1/"), option.WithoutAuthentication())
+	if err != nil {
+		t.Fatal(err)
+	}
+	data := bytes.Repeat([]byte("x"), 3*googleapi.MinUploadChunkSize)
+	w := client.Bucket("bucket").Object("obj").NewWriter(context.Background())
+	w.ChunkSize = googleapi.MinUploadChunkSize
+	w.CheckpointFunc = func(string, int64) {}
+	// The first two chunks are allowed at once, and the last takes half a
+	// second.
+	w.BandwidthLimiter, err = NewBandwidthLimiter(2 * googleapi.MinUploadChunkSize)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var progress []int64
+	w.OnProgress = func(p Progress) { progress = append(progress, p.Bytes) }
+	start := time.Now()
+	if _, err := w.Write(data); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if d := time.Since(start); d < 400*time.Millisecond {
+		t.Errorf("write took %v, want at least 400ms", d)
+	}
+	want := []int64{googleapi.MinUploadChunkSize, 2 * googleapi.MinUploadChunkSize, 3 * googleapi.MinUploadChunkSize}
+	if len(progress) != len(want) || progress[2] != want[2] {
+		t.Errorf("got progress %v, want %v", progress, want)
+	}
+}
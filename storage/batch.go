@@ -0,0 +1,146 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package storage
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"cloud.google.com/go/internal/trace"
+)
+
+// defaultBatchConcurrency is the default of Batch.Concurrency.
+const defaultBatchConcurrency = 16
+
+// A Batch deletes objects and updates their metadata in bulk. Add operations
+// with Delete and Update, then run them all with Run. The operations of a
+// Batch run concurrently, with each one retried like the ObjectHandle method
+// it corresponds to, so a Batch works with both the JSON and the gRPC APIs.
+//
+// The zero value is an empty Batch ready to use. A Batch is not safe for
+// concurrent use.
+type Batch struct {
+	// Concurrency is the maximum number of operations that run at once. The
+	// default is 16.
+	Concurrency int
+
+	ops []batchOp
+}
+
+// batchOp is an operation of a Batch.
+type batchOp struct {
+	o      *ObjectHandle
+	update *ObjectAttrsToUpdate // nil for a deletion
+}
+
+// Delete adds the deletion of the object of o to the batch. The generation and
+// conditions of o apply as they do for ObjectHandle.Delete.
+func (b *Batch) Delete(o *ObjectHandle) {
+	b.ops = append(b.ops, batchOp{o: o})
+}
+
+// Update adds an update of the metadata of the object of o to the batch. The
+// generation and conditions of o apply as they do for ObjectHandle.Update.
+func (b *Batch) Update(o *ObjectHandle, uattrs ObjectAttrsToUpdate) {
+	b.ops = append(b.ops, batchOp{o: o, update: &uattrs})
+}
+
+// Len returns the number of operations in the batch.
+func (b *Batch) Len() int {
+	return len(b.ops)
+}
+
+// Run runs the operations of the batch and removes them from it, so that the
+// Batch can be reused. It returns a slice with an entry for each operation, in
+// the order they were added, that holds the attributes of the updated object
+// for an update and nil for a deletion.
+//
+// If any operation fails, Run returns a MultiError with the errors of the
+// operations in the same order, and nil for those that succeeded. Operations
+// that didn't start before ctx was done fail with the error of ctx.
+func (b *Batch) Run(ctx context.Context) (_ []*ObjectAttrs, err error) {
+	ctx = trace.StartSpan(ctx, "cloud.google.com/go/storage.Batch.Run")
+	defer func() { trace.EndSpan(ctx, err) }()
+
+	ops := b.ops
+	b.ops = nil
+	workers := b.Concurrency
+	if workers <= 0 {
+		workers = defaultBatchConcurrency
+	}
+	if workers > len(ops) {
+		workers = len(ops)
+	}
+
+	attrs := make([]*ObjectAttrs, len(ops))
+	errs := make(MultiError, len(ops))
+	next := make(chan int)
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range next {
+				op := ops[i]
+				if err := ctx.Err(); err != nil {
+					errs[i] = err
+				} else if op.update == nil {
+					errs[i] = op.o.Delete(ctx)
+				} else {
+					attrs[i], errs[i] = op.o.Update(ctx, *op.update)
+				}
+			}
+		}()
+	}
+	for i := range ops {
+		next <- i
+	}
+	close(next)
+	wg.Wait()
+
+	for _, err := range errs {
+		if err != nil {
+			return attrs, errs
+		}
+	}
+	return attrs, nil
+}
+
+// MultiError is returned by Batch.Run when operations fail. Its errors are in
+// one-to-one correspondence with the operations of the batch; those of
+// successful operations are nil.
+type MultiError []error
+
+func (m MultiError) Error() string {
+	s, n := "", 0
+	for _, e := range m {
+		if e != nil {
+			if n == 0 {
+				s = e.Error()
+			}
+			n++
+		}
+	}
+	switch n {
+	case 0:
+		return "(0 errors)"
+	case 1:
+		return s
+	case 2:
+		return s + " (and 1 other error)"
+	}
+	return fmt.Sprintf("%s (and %d other errors)", s, n-1)
+}
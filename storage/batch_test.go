@@ -0,0 +1,105 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package storage
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"google.golang.org/api/option"
+)
+
+func TestBatch(t *testing.T) {
+	var mu sync.Mutex
+	var inFlight, maxInFlight int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		inFlight++
+		if inFlight > maxInFlight {
+			maxInFlight = inFlight
+		}
+		mu.Unlock()
+		time.Sleep(5 * time.Millisecond)
+		defer func() {
+			mu.Lock()
+			inFlight--
+			mu.Unlock()
+		}()
+
+		name := strings.TrimPrefix(r.URL.Path, "/storage/v1/b/bucket/o/")
+		switch {
+		case strings.HasPrefix(name, "missing"):
+			http.Error(w, "not found", http.StatusNotFound)
+		case r.Method == "DELETE":
+			w.WriteHeader(http.StatusNoContent)
+		case r.Method == "PATCH":
+			fmt.Fprintf(w, `{"bucket": "bucket", "name": %q, "contentType": "text/plain"}`, name)
+		default:
+			http.Error(w, "unexpected request", http.StatusBadRequest)
+		}
+	}))
+	defer srv.Close()
+	client, err := NewClient(context.Background(), option.WithEndpoint(srv.URL+"/storage/v1/"), option.WithoutAuthentication())
+	if err != nil {
+		t.Fatal(err)
+	}
+	bkt := client.Bucket("bucket")
+
+	b := &Batch{Concurrency: 3}
+	for i := 0; i < 10; i++ {
+		b.Delete(bkt.Object(fmt.Sprintf("del%d", i)))
+	}
+	b.Update(bkt.Object("upd"), ObjectAttrsToUpdate{ContentType: "text/plain"})
+	b.Delete(bkt.Object("missing"))
+	if got, want := b.Len(), 12; got != want {
+		t.Fatalf("got %d operations, want %d", got, want)
+	}
+	attrs, err := b.Run(context.Background())
+	merr, ok := err.(MultiError)
+	if !ok || len(merr) != 12 {
+		t.Fatalf("got %v, want a MultiError with 12 entries", err)
+	}
+	for i, err := range merr {
+		if wantErr := i == 11; (err != nil) != wantErr {
+			t.Errorf("operation %d: got error %v, want error: %v", i, err, wantErr)
+		}
+	}
+	if merr[11] != ErrObjectNotExist {
+		t.Errorf("got %v for the missing object, want ErrObjectNotExist", merr[11])
+	}
+	if attrs[10] == nil || attrs[10].Name != "upd" || attrs[10].ContentType != "text/plain" {
+		t.Errorf("got attrs %+v for the update, want the updated object", attrs[10])
+	}
+	if attrs[0] != nil {
+		t.Errorf("got attrs %+v for a deletion, want nil", attrs[0])
+	}
+	if maxInFlight > 3 {
+		t.Errorf("got %d concurrent requests, want at most 3", maxInFlight)
+	}
+	if b.Len() != 0 {
+		t.Errorf("got %d operations after Run, want 0", b.Len())
+	}
+
+	b.Delete(bkt.Object("del"))
+	if _, err := b.Run(context.Background()); err != nil {
+		t.Errorf("reused batch: got %v, want nil", err)
+	}
+}
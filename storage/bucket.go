@@ -533,6 +533,22 @@ type BucketAttrs struct {
 	// See https://cloud.google.com/storage/docs/managing-turbo-replication for
 	// more information.
 	RPO RPO
+
+	// CustomPlacementConfig places the data of a custom dual-region bucket in
+	// an explicit pair of regions. Location must be set to the multi-region
+	// code that contains both regions, such as "US" or "EU". This field can
+	// only be set when the bucket is created.
+	// See https://cloud.google.com/storage/docs/locations#location-dr for more
+	// information.
+	CustomPlacementConfig *CustomPlacementConfig
+}
+
+// CustomPlacementConfig holds the configuration of a custom dual-region
+// bucket.
+type CustomPlacementConfig struct {
+	// DataLocations lists the regions in which the data of the bucket is
+	// placed, for example []string{"US-EAST1", "US-WEST1"}.
+	DataLocations []string
 }
 
 // BucketPolicyOnly is an alias for UniformBucketLevelAccess.
@@ -815,6 +831,7 @@ func newBucket(b *raw.Bucket) (*BucketAttrs, error) {
 		LocationType:             b.LocationType,
 		ProjectNumber:            b.ProjectNumber,
 		RPO:                      toRPO(b),
+		CustomPlacementConfig:    toCustomPlacementConfig(b.CustomPlacementConfig),
 	}, nil
 }
 
@@ -845,6 +862,7 @@ func newBucketFromProto(b *storagepb.Bucket) *BucketAttrs {
 		PublicAccessPrevention:   toPublicAccessPreventionFromProto(b.GetIamConfig()),
 		LocationType:             b.GetLocationType(),
 		RPO:                      toRPOFromProto(b),
+		CustomPlacementConfig:    toCustomPlacementConfigFromProto(b.GetCustomPlacementConfig()),
 	}
 }
 
@@ -882,22 +900,23 @@ func (b *BucketAttrs) toRawBucket() *raw.Bucket {
 		}
 	}
 	return &raw.Bucket{
-		Name:             b.Name,
-		Location:         b.Location,
-		StorageClass:     b.StorageClass,
-		Acl:              toRawBucketACL(b.ACL),
-		DefaultObjectAcl: toRawObjectACL(b.DefaultObjectACL),
-		Versioning:       v,
-		Labels:           labels,
-		Billing:          bb,
-		Lifecycle:        toRawLifecycle(b.Lifecycle),
-		RetentionPolicy:  b.RetentionPolicy.toRawRetentionPolicy(),
-		Cors:             toRawCORS(b.CORS),
-		Encryption:       b.Encryption.toRawBucketEncryption(),
-		Logging:          b.Logging.toRawBucketLogging(),
-		Website:          b.Website.toRawBucketWebsite(),
-		IamConfiguration: bktIAM,
-		Rpo:              b.RPO.String(),
+		Name:                  b.Name,
+		Location:              b.Location,
+		StorageClass:          b.StorageClass,
+		Acl:                   toRawBucketACL(b.ACL),
+		DefaultObjectAcl:      toRawObjectACL(b.DefaultObjectACL),
+		Versioning:            v,
+		Labels:                labels,
+		Billing:               bb,
+		Lifecycle:             toRawLifecycle(b.Lifecycle),
+		RetentionPolicy:       b.RetentionPolicy.toRawRetentionPolicy(),
+		Cors:                  toRawCORS(b.CORS),
+		Encryption:            b.Encryption.toRawBucketEncryption(),
+		Logging:               b.Logging.toRawBucketLogging(),
+		Website:               b.Website.toRawBucketWebsite(),
+		IamConfiguration:      bktIAM,
+		Rpo:                   b.RPO.String(),
+		CustomPlacementConfig: b.CustomPlacementConfig.toRawCustomPlacementConfig(),
 	}
 }
 
@@ -940,22 +959,23 @@ func (b *BucketAttrs) toProtoBucket() *storagepb.Bucket {
 	}
 
 	return &storagepb.Bucket{
-		Name:             b.Name,
-		Location:         b.Location,
-		StorageClass:     b.StorageClass,
-		Acl:              toProtoBucketACL(b.ACL),
-		DefaultObjectAcl: toProtoObjectACL(b.DefaultObjectACL),
-		Versioning:       v,
-		Labels:           labels,
-		Billing:          bb,
-		Lifecycle:        toProtoLifecycle(b.Lifecycle),
-		RetentionPolicy:  b.RetentionPolicy.toProtoRetentionPolicy(),
-		Cors:             toProtoCORS(b.CORS),
-		Encryption:       b.Encryption.toProtoBucketEncryption(),
-		Logging:          b.Logging.toProtoBucketLogging(),
-		Website:          b.Website.toProtoBucketWebsite(),
-		IamConfig:        bktIAM,
-		Rpo:              b.RPO.String(),
+		Name:                  b.Name,
+		Location:              b.Location,
+		StorageClass:          b.StorageClass,
+		Acl:                   toProtoBucketACL(b.ACL),
+		DefaultObjectAcl:      toProtoObjectACL(b.DefaultObjectACL),
+		Versioning:            v,
+		Labels:                labels,
+		Billing:               bb,
+		Lifecycle:             toProtoLifecycle(b.Lifecycle),
+		RetentionPolicy:       b.RetentionPolicy.toProtoRetentionPolicy(),
+		Cors:                  toProtoCORS(b.CORS),
+		Encryption:            b.Encryption.toProtoBucketEncryption(),
+		Logging:               b.Logging.toProtoBucketLogging(),
+		Website:               b.Website.toProtoBucketWebsite(),
+		IamConfig:             bktIAM,
+		Rpo:                   b.RPO.String(),
+		CustomPlacementConfig: b.CustomPlacementConfig.toProtoCustomPlacementConfig(),
 	}
 }
 
@@ -1825,6 +1845,42 @@ func toBucketWebsiteFromProto(w *storagepb.Bucket_Website) *BucketWebsite {
 	}
 }
 
+func (c *CustomPlacementConfig) toRawCustomPlacementConfig() *raw.BucketCustomPlacementConfig {
+	if c == nil {
+		return nil
+	}
+	return &raw.BucketCustomPlacementConfig{
+		DataLocations: c.DataLocations,
+	}
+}
+
+func (c *CustomPlacementConfig) toProtoCustomPlacementConfig() *storagepb.Bucket_CustomPlacementConfig {
+	if c == nil {
+		return nil
+	}
+	return &storagepb.Bucket_CustomPlacementConfig{
+		DataLocations: c.DataLocations,
+	}
+}
+
+func toCustomPlacementConfig(c *raw.BucketCustomPlacementConfig) *CustomPlacementConfig {
+	if c == nil {
+		return nil
+	}
+	return &CustomPlacementConfig{
+		DataLocations: c.DataLocations,
+	}
+}
+
+func toCustomPlacementConfigFromProto(c *storagepb.Bucket_CustomPlacementConfig) *CustomPlacementConfig {
+	if c == nil {
+		return nil
+	}
+	return &CustomPlacementConfig{
+		DataLocations: c.GetDataLocations(),
+	}
+}
+
 func toBucketPolicyOnly(b *raw.BucketIamConfiguration) BucketPolicyOnly {
 	if b == nil || b.BucketPolicyOnly == nil || !b.BucketPolicyOnly.Enabled {
 		return BucketPolicyOnly{}
@@ -2049,7 +2105,7 @@ func (it *ObjectIterator) fetch(pageSize int, pageToken string) (string, error)
 	var resp *raw.Objects
 	var err error
 	err = run(it.ctx, func() error {
-		resp, err = req.Context(it.ctx).Do()
+		resp, err = req.Context(it.ctx).Do(softDeletedOption(it.query.SoftDeleted)...)
 		return err
 	}, it.bucket.retry, true, setRetryHeaderHTTP(req))
 	if err != nil {
@@ -46,6 +46,7 @@ func TestBucketAttrsToRawBucket(t *testing.T) {
 		PublicAccessPrevention:   PublicAccessPreventionEnforced,
 		VersioningEnabled:        false,
 		RPO:                      RPOAsyncTurbo,
+		CustomPlacementConfig:    &CustomPlacementConfig{DataLocations: []string{"US-EAST1", "US-WEST1"}},
 		// should be ignored:
 		MetaGeneration: 39,
 		Created:        time.Now(),
@@ -145,7 +146,10 @@ func TestBucketAttrsToRawBucket(t *testing.T) {
 		},
 		Versioning: nil, // ignore VersioningEnabled if false
 		Rpo:        rpoAsyncTurbo,
-		Labels:     map[string]string{"label": "value"},
+		CustomPlacementConfig: &raw.BucketCustomPlacementConfig{
+			DataLocations: []string{"US-EAST1", "US-WEST1"},
+		},
+		Labels: map[string]string{"label": "value"},
 		Cors: []*raw.BucketCors{
 			{
 				MaxAgeSeconds:  3600,
@@ -730,6 +734,9 @@ func TestNewBucket(t *testing.T) {
 		Logging:       &raw.BucketLogging{LogBucket: "lb", LogObjectPrefix: "p"},
 		Website:       &raw.BucketWebsite{MainPageSuffix: "mps", NotFoundPage: "404"},
 		ProjectNumber: 123231313,
+		CustomPlacementConfig: &raw.BucketCustomPlacementConfig{
+			DataLocations: []string{"US-EAST1", "US-WEST1"},
+		},
 	}
 	want := &BucketAttrs{
 		Name:                  "name",
@@ -779,7 +786,10 @@ func TestNewBucket(t *testing.T) {
 		ACL:              []ACLRule{{Entity: "allUsers", Role: RoleReader, Email: "joe@example.com"}},
 		DefaultObjectACL: nil,
 		LocationType:     "dual-region",
-		ProjectNumber:    123231313,
+		CustomPlacementConfig: &CustomPlacementConfig{
+			DataLocations: []string{"US-EAST1", "US-WEST1"},
+		},
+		ProjectNumber: 123231313,
 	}
 	got, err := newBucket(rb)
 	if err != nil {
@@ -248,6 +248,9 @@ as the documentation of BucketHandle.GenerateSignedPostPolicyV4.
     }
     fmt.Printf("URL: %s\nFields; %v\n", pv4.URL, pv4.Fields)
 
+PostPolicyV4.HTMLForm renders a ready-to-use HTML form for the policy, and
+PostPolicyV4.JSONForm encodes it for scripts that build the form themselves.
+
 Errors
 
 Errors returned by this client are often of the type googleapi.Error.
@@ -297,5 +300,18 @@ client (using Client.SetRetry). For example:
 	if err := o.Delete(ctx); err != nil {
 		// Handle err.
 	}
+
+Experimental gRPC API
+
+NewGRPCClient creates a client that reads and writes object data with the gRPC
+API of Cloud Storage, which can lower the latency and CPU usage of large
+transfers from Google Compute Engine and Google Kubernetes Engine. Other
+operations use the JSON API. This API is EXPERIMENTAL and subject to change or
+removal without notice.
+
+	client, err := storage.NewGRPCClient(ctx)
+	if err != nil {
+		// TODO: Handle error.
+	}
 */
 package storage // import "cloud.google.com/go/storage"
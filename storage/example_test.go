@@ -116,6 +116,34 @@ func ExampleBucketHandle_Update() {
 	fmt.Println(attrs)
 }
 
+func ExampleBucketHandle_Create_turboReplication() {
+	ctx := context.Background()
+	client, err := storage.NewClient(ctx)
+	if err != nil {
+		// TODO: handle error.
+	}
+	// Turbo replication is available for dual-region buckets.
+	attrs := &storage.BucketAttrs{Location: "NAM4", RPO: storage.RPOAsyncTurbo}
+	if err := client.Bucket("my-bucket").Create(ctx, "my-project", attrs); err != nil {
+		// TODO: handle error.
+	}
+}
+
+func ExampleBucketHandle_Update_turboReplication() {
+	ctx := context.Background()
+	client, err := storage.NewClient(ctx)
+	if err != nil {
+		// TODO: handle error.
+	}
+	// Turn off turbo replication. Use storage.RPOAsyncTurbo to turn it on.
+	attrs, err := client.Bucket("my-bucket").Update(ctx,
+		storage.BucketAttrsToUpdate{RPO: storage.RPODefault})
+	if err != nil {
+		// TODO: handle error.
+	}
+	fmt.Println(attrs.RPO)
+}
+
 // If your update is based on the bucket's previous attributes, match the
 // metageneration number to make sure the bucket hasn't changed since you read it.
 func ExampleBucketHandle_Update_readModifyWrite() {
@@ -0,0 +1,370 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package storage
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"cloud.google.com/go/internal/trace"
+	gax "github.com/googleapis/gax-go/v2"
+	"google.golang.org/api/googleapi"
+	"google.golang.org/api/iterator"
+)
+
+// ErrFolderNotExist indicates that the folder does not exist.
+var ErrFolderNotExist = errors.New("storage: folder doesn't exist")
+
+// Folder describes a folder of a bucket with hierarchical namespace enabled.
+// Unlike the simulated folders of other buckets, which only exist as the
+// common prefixes of object names, these folders are resources of their own.
+type Folder struct {
+	// Bucket is the name of the bucket of the folder.
+	Bucket string
+
+	// Name is the name of the folder, which ends with a slash, for example
+	// "dir/subdir/".
+	Name string
+
+	// Metageneration is the version of the metadata of the folder.
+	Metageneration int64
+
+	// Created and Updated are the times at which the folder was created and
+	// last updated.
+	Created, Updated time.Time
+}
+
+// rawFolder is the JSON API representation of a folder.
+type rawFolder struct {
+	Bucket         string    `json:"bucket"`
+	Name           string    `json:"name"`
+	Metageneration int64     `json:"metageneration,string"`
+	CreateTime     time.Time `json:"createTime"`
+	UpdateTime     time.Time `json:"updateTime"`
+}
+
+func (f *rawFolder) toFolder() *Folder {
+	return &Folder{
+		Bucket:         f.Bucket,
+		Name:           f.Name,
+		Metageneration: f.Metageneration,
+		Created:        f.CreateTime,
+		Updated:        f.UpdateTime,
+	}
+}
+
+// FolderHandle provides operations on a folder of a bucket with hierarchical
+// namespace enabled. Use BucketHandle.Folder to get a handle.
+//
+// This type is EXPERIMENTAL and subject to change or removal without notice.
+// It is not supported by the gRPC API.
+type FolderHandle struct {
+	b    *BucketHandle
+	name string
+}
+
+// Folder returns a handle for the folder with the given name in a bucket with
+// hierarchical namespace enabled. A trailing slash is added to the name if it
+// is missing. The folder need not exist.
+func (b *BucketHandle) Folder(name string) *FolderHandle {
+	if !strings.HasSuffix(name, "/") {
+		name += "/"
+	}
+	return &FolderHandle{b: b, name: name}
+}
+
+// Name returns the name of the folder.
+func (f *FolderHandle) Name() string {
+	return f.name
+}
+
+// Create creates the folder. If recursive is true, the parent folders that
+// don't exist yet are created too; otherwise the parent folder must exist.
+//
+// Create is not idempotent: a retry after a request that created the folder
+// would fail because the folder exists, so it is only retried with the
+// RetryAlways policy.
+func (f *FolderHandle) Create(ctx context.Context, recursive bool) (folder *Folder, err error) {
+	ctx = trace.StartSpan(ctx, "cloud.google.com/go/storage.Folder.Create")
+	defer func() { trace.EndSpan(ctx, err) }()
+
+	params := url.Values{}
+	if recursive {
+		params.Set("recursive", "true")
+	}
+	var rf rawFolder
+	err = f.b.c.doJSON(ctx, "POST", folderPath(f.b.name, ""), f.b.withUserProject(params),
+		map[string]string{"name": f.name}, &rf, f.b.retry, false)
+	if err != nil {
+		return nil, err
+	}
+	return rf.toFolder(), nil
+}
+
+// Attrs returns the metadata of the folder. It returns ErrFolderNotExist if
+// the folder does not exist.
+func (f *FolderHandle) Attrs(ctx context.Context) (folder *Folder, err error) {
+	ctx = trace.StartSpan(ctx, "cloud.google.com/go/storage.Folder.Attrs")
+	defer func() { trace.EndSpan(ctx, err) }()
+
+	var rf rawFolder
+	err = f.b.c.doJSON(ctx, "GET", folderPath(f.b.name, f.name), f.b.withUserProject(nil), nil, &rf, f.b.retry, true)
+	if isNotFound(err) {
+		return nil, ErrFolderNotExist
+	}
+	if err != nil {
+		return nil, err
+	}
+	return rf.toFolder(), nil
+}
+
+// Delete deletes the folder, which must be empty. It returns
+// ErrFolderNotExist if the folder does not exist.
+func (f *FolderHandle) Delete(ctx context.Context) (err error) {
+	ctx = trace.StartSpan(ctx, "cloud.google.com/go/storage.Folder.Delete")
+	defer func() { trace.EndSpan(ctx, err) }()
+
+	err = f.b.c.doJSON(ctx, "DELETE", folderPath(f.b.name, f.name), f.b.withUserProject(nil), nil, nil, f.b.retry, true)
+	if isNotFound(err) {
+		return ErrFolderNotExist
+	}
+	return err
+}
+
+// Rename renames the folder, including all folders and objects in it, to
+// newName and returns the renamed folder. Renaming is a long-running
+// operation of the service; Rename waits for it to finish, polling its status
+// until ctx is done. It returns ErrFolderNotExist if the folder does not
+// exist.
+func (f *FolderHandle) Rename(ctx context.Context, newName string) (folder *Folder, err error) {
+	ctx = trace.StartSpan(ctx, "cloud.google.com/go/storage.Folder.Rename")
+	defer func() { trace.EndSpan(ctx, err) }()
+
+	if !strings.HasSuffix(newName, "/") {
+		newName += "/"
+	}
+	path := folderPath(f.b.name, f.name) + "/renameTo/folders/" + url.PathEscape(newName)
+	var op folderOperation
+	// Renaming is not idempotent: once the folder has been renamed, it no
+	// longer exists under its old name.
+	err = f.b.c.doJSON(ctx, "POST", path, f.b.withUserProject(nil), nil, &op, f.b.retry, false)
+	if isNotFound(err) {
+		return nil, ErrFolderNotExist
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	bo := gax.Backoff{Initial: 500 * time.Millisecond, Max: 10 * time.Second, Multiplier: 1.5}
+	for !op.Done {
+		if err := gax.Sleep(ctx, bo.Pause()); err != nil {
+			return nil, err
+		}
+		i := strings.LastIndex(op.Name, "/operations/")
+		if i < 0 {
+			return nil, fmt.Errorf("storage: unexpected name %q of rename operation", op.Name)
+		}
+		opPath := "b/" + url.PathEscape(f.b.name) + "/operations/" + url.PathEscape(op.Name[i+len("/operations/"):])
+		op = folderOperation{}
+		if err := f.b.c.doJSON(ctx, "GET", opPath, f.b.withUserProject(nil), nil, &op, f.b.retry, true); err != nil {
+			return nil, err
+		}
+	}
+	if op.Error != nil {
+		return nil, &googleapi.Error{Code: op.Error.Code, Message: op.Error.Message}
+	}
+	if op.Response != nil {
+		return op.Response.toFolder(), nil
+	}
+	return f.b.Folder(newName).Attrs(ctx)
+}
+
+// folderOperation is the JSON API representation of a long-running operation
+// on a folder.
+type folderOperation struct {
+	Name  string `json:"name"`
+	Done  bool   `json:"done"`
+	Error *struct {
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+	} `json:"error"`
+	Response *rawFolder `json:"response"`
+}
+
+// FolderQuery selects the folders that Folders lists.
+type FolderQuery struct {
+	// Prefix, if not empty, selects the folders whose names start with it.
+	Prefix string
+
+	// Delimiter, if set to "/", selects only the folders directly under
+	// Prefix, rather than all folders under it.
+	Delimiter string
+}
+
+// Folders returns an iterator over the folders of a bucket with hierarchical
+// namespace enabled that match q. If q is nil, all folders are listed.
+//
+// This method is EXPERIMENTAL and subject to change or removal without notice.
+func (b *BucketHandle) Folders(ctx context.Context, q *FolderQuery) *FolderIterator {
+	it := &FolderIterator{ctx: ctx, bucket: b}
+	if q != nil {
+		it.query = *q
+	}
+	it.pageInfo, it.nextFunc = iterator.NewPageInfo(
+		it.fetch,
+		func() int { return len(it.items) },
+		func() interface{} { b := it.items; it.items = nil; return b })
+	return it
+}
+
+// A FolderIterator is an iterator over Folders.
+//
+// Note: This iterator is not safe for concurrent operations without explicit synchronization.
+type FolderIterator struct {
+	ctx      context.Context
+	bucket   *BucketHandle
+	query    FolderQuery
+	pageInfo *iterator.PageInfo
+	nextFunc func() error
+	items    []*Folder
+}
+
+// Next returns the next result. Its second return value is iterator.Done if
+// there are no more results. Once Next returns iterator.Done, all subsequent
+// calls will return iterator.Done.
+func (it *FolderIterator) Next() (*Folder, error) {
+	if err := it.nextFunc(); err != nil {
+		return nil, err
+	}
+	f := it.items[0]
+	it.items = it.items[1:]
+	return f, nil
+}
+
+// PageInfo supports pagination. See the google.golang.org/api/iterator package for details.
+func (it *FolderIterator) PageInfo() *iterator.PageInfo { return it.pageInfo }
+
+func (it *FolderIterator) fetch(pageSize int, pageToken string) (string, error) {
+	params := url.Values{}
+	if it.query.Prefix != "" {
+		params.Set("prefix", it.query.Prefix)
+	}
+	if it.query.Delimiter != "" {
+		params.Set("delimiter", it.query.Delimiter)
+	}
+	if pageToken != "" {
+		params.Set("pageToken", pageToken)
+	}
+	if pageSize > 0 {
+		params.Set("pageSize", fmt.Sprint(pageSize))
+	}
+	var resp struct {
+		Items         []*rawFolder `json:"items"`
+		NextPageToken string       `json:"nextPageToken"`
+	}
+	b := it.bucket
+	if err := b.c.doJSON(it.ctx, "GET", folderPath(b.name, ""), b.withUserProject(params), nil, &resp, b.retry, true); err != nil {
+		return "", err
+	}
+	for _, f := range resp.Items {
+		it.items = append(it.items, f.toFolder())
+	}
+	return resp.NextPageToken, nil
+}
+
+// folderPath returns the path of the folders of a bucket relative to the base
+// path of the JSON API, or of one folder if name is not empty.
+func folderPath(bucket, name string) string {
+	p := "b/" + url.PathEscape(bucket) + "/folders"
+	if name != "" {
+		p += "/" + url.PathEscape(name)
+	}
+	return p
+}
+
+// withUserProject adds the user project of b, if any, to params.
+func (b *BucketHandle) withUserProject(params url.Values) url.Values {
+	if params == nil {
+		params = url.Values{}
+	}
+	if b.userProject != "" {
+		params.Set("userProject", b.userProject)
+	}
+	return params
+}
+
+func isNotFound(err error) bool {
+	var e *googleapi.Error
+	return errors.As(err, &e) && e.Code == http.StatusNotFound
+}
+
+// doJSON sends a request to the JSON API for the resources that the
+// generated library doesn't support. The path is relative to the base path of
+// the API. If in is not nil, it is sent as the JSON body of the request; if
+// out is not nil, the JSON body of the response is decoded into it.
+func (c *Client) doJSON(ctx context.Context, method, path string, params url.Values, in, out interface{}, retry *retryConfig, isIdempotent bool) error {
+	var body []byte
+	if in != nil {
+		var err error
+		if body, err = json.Marshal(in); err != nil {
+			return err
+		}
+	}
+	params.Set("alt", "json")
+	params.Set("prettyPrint", "false")
+	u := googleapi.ResolveRelative(c.raw.BasePath, path) + "?" + params.Encode()
+	header := jsonHeader{}
+	return run(ctx, func() error {
+		req, err := http.NewRequest(method, u, bytes.NewReader(body))
+		if err != nil {
+			return err
+		}
+		req = req.WithContext(ctx)
+		for k, v := range header {
+			req.Header[k] = v
+		}
+		if in != nil {
+			req.Header.Set("Content-Type", "application/json")
+		}
+		resp, err := c.hc.Do(req)
+		if err != nil {
+			return err
+		}
+		defer resp.Body.Close()
+		if err := googleapi.CheckResponse(resp); err != nil {
+			return err
+		}
+		if out == nil {
+			_, err = io.Copy(ioutil.Discard, resp.Body)
+			return err
+		}
+		return json.NewDecoder(resp.Body).Decode(out)
+	}, retry, isIdempotent, setRetryHeaderHTTP(header))
+}
+
+// jsonHeader holds the headers of the requests of doJSON, so that
+// setRetryHeaderHTTP can set them.
+type jsonHeader http.Header
+
+func (h jsonHeader) Header() http.Header { return http.Header(h) }
@@ -0,0 +1,188 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package storage
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"google.golang.org/api/iterator"
+	"google.golang.org/api/option"
+)
+
+// folderServer is a minimal in-memory implementation of the folder API.
+type folderServer struct {
+	mu      sync.Mutex
+	folders map[string]bool
+}
+
+func (s *folderServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	writeFolder := func(name string) {
+		fmt.Fprintf(w, `{"bucket": "bucket", "name": %q, "metageneration": "1"}`, name)
+	}
+	path := strings.TrimPrefix(r.URL.EscapedPath(), "/storage/v1/b/bucket/")
+	switch {
+	case r.Method == "POST" && path == "folders":
+		var f struct{ Name string }
+		json.NewDecoder(r.Body).Decode(&f)
+		s.folders[f.Name] = true
+		writeFolder(f.Name)
+	case r.Method == "GET" && path == "folders":
+		var names []string
+		for name := range s.folders {
+			if strings.HasPrefix(name, r.URL.Query().Get("prefix")) {
+				names = append(names, name)
+			}
+		}
+		sort.Strings(names)
+		// Return one folder per page.
+		i := 0
+		if tok := r.URL.Query().Get("pageToken"); tok != "" {
+			fmt.Sscan(tok, &i)
+		}
+		if i >= len(names) {
+			fmt.Fprint(w, `{}`)
+			return
+		}
+		next := ""
+		if i+1 < len(names) {
+			next = fmt.Sprint(i + 1)
+		}
+		fmt.Fprintf(w, `{"items": [{"name": %q}], "nextPageToken": %q}`, names[i], next)
+	case strings.HasPrefix(path, "operations/"):
+		fmt.Fprint(w, `{"name": "op", "done": true, "response": {"name": "new/"}}`)
+	case strings.HasPrefix(path, "folders/"):
+		parts := strings.SplitN(strings.TrimPrefix(path, "folders/"), "/renameTo/folders/", 2)
+		name, _ := url.PathUnescape(parts[0])
+		if !s.folders[name] {
+			http.Error(w, "not found", http.StatusNotFound)
+			return
+		}
+		switch {
+		case len(parts) == 2:
+			newName, _ := url.PathUnescape(parts[1])
+			delete(s.folders, name)
+			s.folders[newName] = true
+			fmt.Fprint(w, `{"name": "projects/_/buckets/bucket/operations/op", "done": false}`)
+		case r.Method == "GET":
+			writeFolder(name)
+		case r.Method == "DELETE":
+			delete(s.folders, name)
+		}
+	default:
+		http.Error(w, "unexpected request", http.StatusBadRequest)
+	}
+}
+
+func TestFolders(t *testing.T) {
+	srv := httptest.NewServer(&folderServer{folders: map[string]bool{}})
+	defer srv.Close()
+	ctx := context.Background()
+	client, err := NewClient(ctx, option.WithEndpoint(srv.URL+"/storage/v1/"), option.WithoutAuthentication())
+	if err != nil {
+		t.Fatal(err)
+	}
+	b := client.Bucket("bucket")
+
+	for _, name := range []string{"a/", "a/b", "c/"} {
+		f, err := b.Folder(name).Create(ctx, true)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want := strings.TrimSuffix(name, "/") + "/"; f.Name != want || f.Metageneration != 1 {
+			t.Errorf("got %+v, want folder %q", f, want)
+		}
+	}
+	if _, err := b.Folder("a/b/").Attrs(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := b.Folder("x/").Attrs(ctx); err != ErrFolderNotExist {
+		t.Errorf("got %v, want ErrFolderNotExist", err)
+	}
+
+	list := func(prefix string) []string {
+		var names []string
+		it := b.Folders(ctx, &FolderQuery{Prefix: prefix})
+		for {
+			f, err := it.Next()
+			if err == iterator.Done {
+				return names
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			names = append(names, f.Name)
+		}
+	}
+	if got, want := fmt.Sprint(list("a/")), "[a/ a/b/]"; got != want {
+		t.Errorf("got folders %s, want %s", got, want)
+	}
+
+	f, err := b.Folder("c/").Rename(ctx, "new")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if f.Name != "new/" {
+		t.Errorf("got renamed folder %q, want new/", f.Name)
+	}
+	if err := b.Folder("a/b/").Delete(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := fmt.Sprint(list("")), "[a/ new/]"; got != want {
+		t.Errorf("got folders %s, want %s", got, want)
+	}
+	if err := b.Folder("a/b/").Delete(ctx); err != ErrFolderNotExist {
+		t.Errorf("got %v, want ErrFolderNotExist", err)
+	}
+}
+
+func TestFolderCreateNotRetried(t *testing.T) {
+	var (
+		mu    sync.Mutex
+		posts int
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		posts++
+		mu.Unlock()
+		http.Error(w, "unavailable", http.StatusServiceUnavailable)
+	}))
+	defer srv.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	client, err := NewClient(ctx, option.WithEndpoint(srv.URL+"/storage/v1/"), option.WithoutAuthentication())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.Bucket("bucket").Folder("a/").Create(ctx, false); err == nil {
+		t.Fatal("got nil error, want error")
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if posts != 1 {
+		t.Errorf("got %d requests, want 1", posts)
+	}
+}
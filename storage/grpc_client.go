@@ -24,6 +24,7 @@ import (
 	gapic "cloud.google.com/go/storage/internal/apiv2"
 	"google.golang.org/api/iterator"
 	"google.golang.org/api/option"
+	"google.golang.org/api/option/internaloption"
 	iampb "google.golang.org/genproto/googleapis/iam/v1"
 	storagepb "google.golang.org/genproto/googleapis/storage/v2"
 	"google.golang.org/grpc"
@@ -54,6 +55,9 @@ const (
 func defaultGRPCOptions() []option.ClientOption {
 	defaults := []option.ClientOption{
 		option.WithGRPCConnectionPool(defaultConnPoolSize),
+		// DirectPath is only used where it is available, such as on Google
+		// Compute Engine, and the client falls back to CloudPath otherwise.
+		internaloption.EnableDirectPath(true),
 	}
 
 	// Set emulator options for gRPC if an emulator was specified. Note that in a
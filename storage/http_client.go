@@ -357,7 +357,7 @@ func (c *httpStorageClient) ListObjects(ctx context.Context, bucket string, q *Q
 		var resp *raw.Objects
 		var err error
 		err = run(it.ctx, func() error {
-			resp, err = req.Context(it.ctx).Do()
+			resp, err = req.Context(it.ctx).Do(softDeletedOption(it.query.SoftDeleted)...)
 			return err
 		}, s.retry, s.idempotent, setRetryHeaderHTTP(req))
 		if err != nil {
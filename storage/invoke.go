@@ -89,6 +89,14 @@ func setRetryHeaderGRPC(_ context.Context) func(string, int) {
 	}
 }
 
+// ShouldRetry reports whether err is a transient error that the client
+// retries by default. It can be used to decide whether to retry an operation
+// that failed after the retries of the client have been exhausted, or that
+// the client did not retry because it is not idempotent.
+func ShouldRetry(err error) bool {
+	return shouldRetry(err)
+}
+
 func shouldRetry(err error) bool {
 	if err == nil {
 		return false
@@ -23,7 +23,9 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"html"
 	"net/url"
+	"sort"
 	"strings"
 	"time"
 )
@@ -173,6 +175,37 @@ type PostPolicyV4 struct {
 	Fields map[string]string
 }
 
+// HTMLForm returns an HTML form that uploads a file that the user selects
+// with the signed policy. The form has a hidden input for each field of p, a
+// file input named "file" and a submit button.
+func (p *PostPolicyV4) HTMLForm() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "<form action=\"%s\" method=\"POST\" enctype=\"multipart/form-data\">\n", html.EscapeString(p.URL))
+	keys := make([]string, 0, len(p.Fields))
+	for key := range p.Fields {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		fmt.Fprintf(&b, "  <input type=\"hidden\" name=\"%s\" value=\"%s\">\n", html.EscapeString(key), html.EscapeString(p.Fields[key]))
+	}
+	// The file must be the last field of the form.
+	b.WriteString("  <input type=\"file\" name=\"file\">\n")
+	b.WriteString("  <input type=\"submit\" value=\"Upload\">\n")
+	b.WriteString("</form>\n")
+	return b.String()
+}
+
+// JSONForm returns p encoded as a JSON object with the members "url" and
+// "fields", for clients such as browser scripts that build the multipart form
+// themselves. The file must be appended to the form after the fields.
+func (p *PostPolicyV4) JSONForm() ([]byte, error) {
+	return json.Marshal(struct {
+		URL    string            `json:"url"`
+		Fields map[string]string `json:"fields"`
+	}{p.URL, p.Fields})
+}
+
 // PostPolicyV4Condition describes the constraints that the subsequent
 // object upload's multipart form fields will be expected to conform to.
 type PostPolicyV4Condition interface {
@@ -182,19 +215,42 @@ type PostPolicyV4Condition interface {
 
 type startsWith struct {
 	key, value string
+	// any makes the condition match any value, even if value is empty.
+	any bool
 }
 
 func (sw *startsWith) MarshalJSON() ([]byte, error) {
-	return json.Marshal([]string{"starts-with", sw.key, sw.value})
+	key := sw.key
+	if !strings.HasPrefix(key, "$") {
+		key = "$" + key
+	}
+	return json.Marshal([]string{"starts-with", key, sw.value})
 }
 func (sw *startsWith) isEmpty() bool {
-	return sw.value == ""
+	return sw.value == "" && !sw.any
 }
 
 // ConditionStartsWith checks that an attributes starts with value.
+// The key is the name of any form field, such as "$key", "$content-type" or
+// "$x-goog-meta-owner"; the leading "$" is added if it is missing.
 // An empty value will cause this condition to be ignored.
 func ConditionStartsWith(key, value string) PostPolicyV4Condition {
-	return &startsWith{key, value}
+	return &startsWith{key: key, value: value}
+}
+
+// ConditionAnyValue allows the form field with the given name to have any
+// value. Every field of the form that is not covered by the policy must be
+// allowed this way, unless its name starts with "x-ignore-". The leading "$"
+// of the key is added if it is missing.
+func ConditionAnyValue(key string) PostPolicyV4Condition {
+	return &startsWith{key: key, any: true}
+}
+
+// ConditionMatches checks that the form field with the given name has exactly
+// the given value. Use it for fields that are not covered by PolicyV4Fields.
+// An empty value will cause this condition to be ignored.
+func ConditionMatches(key, value string) PostPolicyV4Condition {
+	return &singleValueCondition{strings.TrimPrefix(key, "$"), value}
 }
 
 type contentLengthRangeCondition struct {
@@ -221,6 +277,8 @@ func (svc *singleValueCondition) isEmpty() bool {
 
 // ConditionContentLengthRange constraints the limits that the
 // multipart upload's range header will be expected to be within.
+// The limits are the minimum and maximum size of the uploaded file in bytes,
+// inclusive.
 func ConditionContentLengthRange(start, end uint64) PostPolicyV4Condition {
 	return &contentLengthRangeCondition{start, end}
 }
@@ -282,6 +340,12 @@ func GenerateSignedPostPolicyV4(bucket, object string, opts *PostPolicyV4Options
 		return nil, err
 	}
 
+	for _, cond := range opts.Conditions {
+		if clr, ok := cond.(*contentLengthRangeCondition); ok && clr.start > clr.end {
+			return nil, fmt.Errorf("storage: content-length-range minimum %d exceeds maximum %d", clr.start, clr.end)
+		}
+	}
+
 	// Build the policy.
 	conds := make([]PostPolicyV4Condition, len(opts.Conditions))
 	copy(conds, opts.Conditions)
@@ -15,6 +15,8 @@
 package storage
 
 import (
+	"encoding/base64"
+	"encoding/json"
 	"reflect"
 	"testing"
 	"time"
@@ -68,3 +70,78 @@ func TestPostPolicyV4OptionsClone(t *testing.T) {
 		t.Errorf("clone does not match (original: -, cloned: +):\n%s", diff)
 	}
 }
+
+func TestPostPolicyV4Conditions(t *testing.T) {
+	t.Parallel()
+
+	opts := &PostPolicyV4Options{
+		GoogleAccessID: "access@example.com",
+		SignRawBytes:   func(b []byte) ([]byte, error) { return []byte("sig"), nil },
+		Expires:        time.Now().Add(time.Hour),
+		Fields:         &PolicyV4Fields{RedirectToURLOnSuccess: "https://example.com/done"},
+		Conditions: []PostPolicyV4Condition{
+			ConditionContentLengthRange(1, 1<<20),
+			ConditionStartsWith("content-type", "image/"),
+			ConditionAnyValue("$x-goog-meta-owner"),
+			ConditionMatches("x-goog-meta-project", "p1"),
+		},
+	}
+	pv4, err := GenerateSignedPostPolicyV4("bucket", "object", opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := base64.StdEncoding.DecodeString(pv4.Fields["policy"])
+	if err != nil {
+		t.Fatal(err)
+	}
+	var policy struct{ Conditions []json.RawMessage }
+	if err := json.Unmarshal(b, &policy); err != nil {
+		t.Fatal(err)
+	}
+	got := map[string]bool{}
+	for _, c := range policy.Conditions {
+		got[string(c)] = true
+	}
+	for _, want := range []string{
+		`["content-length-range",1,1048576]`,
+		`["starts-with","$content-type","image/"]`,
+		`["starts-with","$x-goog-meta-owner",""]`,
+		`{"x-goog-meta-project":"p1"}`,
+		`{"success_action_redirect":"https://example.com/done"}`,
+	} {
+		if !got[want] {
+			t.Errorf("policy conditions %s do not contain %s", b, want)
+		}
+	}
+
+	opts.Conditions = []PostPolicyV4Condition{ConditionContentLengthRange(10, 1)}
+	if _, err := GenerateSignedPostPolicyV4("bucket", "object", opts); err == nil {
+		t.Error("got nil error for an empty content-length-range")
+	}
+}
+
+func TestPostPolicyV4Forms(t *testing.T) {
+	t.Parallel()
+
+	pv4 := &PostPolicyV4{
+		URL:    "https://storage.googleapis.com/bucket/",
+		Fields: map[string]string{"key": "a&b", "policy": "p"},
+	}
+	wantHTML := `<form action="https://storage.googleapis.com/bucket/" method="POST" enctype="multipart/form-data">
+  <input type="hidden" name="key" value="a&amp;b">
+  <input type="hidden" name="policy" value="p">
+  <input type="file" name="file">
+  <input type="submit" value="Upload">
+</form>
+`
+	if got := pv4.HTMLForm(); got != wantHTML {
+		t.Errorf("HTMLForm:\ngot  %s\nwant %s", got, wantHTML)
+	}
+	b, err := pv4.JSONForm()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := string(b), `{"url":"https://storage.googleapis.com/bucket/","fields":{"key":"a\u0026b","policy":"p"}}`; got != want {
+		t.Errorf("JSONForm: got %s, want %s", got, want)
+	}
+}
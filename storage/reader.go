@@ -93,6 +93,11 @@ func (o *ObjectHandle) NewReader(ctx context.Context) (*Reader, error) {
 func (o *ObjectHandle) NewRangeReader(ctx context.Context, offset, length int64) (r *Reader, err error) {
 	ctx = trace.StartSpan(ctx, "cloud.google.com/go/storage.Object.NewRangeReader")
 	defer func() { trace.EndSpan(ctx, err) }()
+	defer func() {
+		if r != nil {
+			r.ctx = ctx
+		}
+	}()
 
 	if o.c.tc != nil {
 		return o.newRangeReaderWithGRPC(ctx, offset, length)
@@ -374,7 +379,21 @@ var emptyBody = ioutil.NopCloser(strings.NewReader(""))
 // the stored CRC, returning an error from Read if there is a mismatch. This integrity check
 // is skipped if transcoding occurs. See https://cloud.google.com/storage/docs/transcoding.
 type Reader struct {
-	Attrs              ReaderObjectAttrs
+	Attrs ReaderObjectAttrs
+
+	// OnProgress, if not nil, is called after each Read call that returns
+	// data, with the number of bytes read so far and the average read rate
+	// since the first Read call. OnProgress should return quickly without
+	// blocking.
+	OnProgress func(Progress)
+
+	// BandwidthLimiter, if not nil, caps the rate at which data is read from
+	// the Reader.
+	BandwidthLimiter *BandwidthLimiter
+
+	ctx                context.Context
+	read               int64     // bytes returned by Read, for OnProgress
+	start              time.Time // time of the first Read call
 	body               io.ReadCloser
 	seen, remain, size int64
 	checkCRC           bool   // should we check the CRC?
@@ -405,7 +424,24 @@ func (r *Reader) Read(p []byte) (int, error) {
 		read = r.reader.Read
 	}
 
+	if r.start.IsZero() {
+		r.start = time.Now()
+	}
+	if r.BandwidthLimiter != nil && len(p) > limiterPieceSize {
+		p = p[:limiterPieceSize]
+	}
 	n, err := read(p)
+	if n > 0 {
+		if r.BandwidthLimiter != nil {
+			if werr := r.BandwidthLimiter.wait(r.ctx, n); werr != nil && err == nil {
+				err = werr
+			}
+		}
+		r.read += int64(n)
+		if r.OnProgress != nil {
+			r.OnProgress(newProgress(r.read, r.start))
+		}
+	}
 	if r.remain != -1 {
 		r.remain -= int64(n)
 	}
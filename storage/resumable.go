@@ -0,0 +1,412 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package storage
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	gax "github.com/googleapis/gax-go/v2"
+	"google.golang.org/api/googleapi"
+	raw "google.golang.org/api/storage/v1"
+)
+
+// defaultChunkRetryDeadline is the default of Writer.ChunkRetryDeadline, as
+// used by the JSON API library.
+const defaultChunkRetryDeadline = 32 * time.Second
+
+// ResumeWriter returns a Writer that continues the resumable upload session
+// with the given URI, as reported by Writer.SessionURI or Writer.CheckpointFunc
+// for an earlier Writer of the same object. This allows an upload that was
+// interrupted, for example by a crash of the process, to continue where it
+// stopped instead of starting over.
+//
+// ResumeWriter asks the service how many bytes of the object it has persisted,
+// which the Offset method of the returned Writer reports. Write the data of the
+// object from that offset on to the Writer, and close it. The attributes of the
+// object were set when the session was created; those of the returned Writer
+// are ignored. If the upload was already complete, Close returns nil without
+// writing, and Attrs reports the object.
+//
+// If the object is encrypted with a customer-supplied key, ResumeWriter must be
+// called on a handle with the same key. Resumable upload sessions expire after
+// a week. ResumeWriter is not supported by the gRPC API.
+func (o *ObjectHandle) ResumeWriter(ctx context.Context, sessionURI string) (*Writer, error) {
+	if err := o.validate(); err != nil {
+		return nil, err
+	}
+	if sessionURI == "" {
+		return nil, errors.New("storage: ResumeWriter: empty session URI")
+	}
+	w := o.NewWriter(ctx)
+	w.sessionURI = sessionURI
+	obj, persisted, err := w.putSession(nil, -1, -1)
+	if err != nil {
+		return nil, err
+	}
+	w.offset = persisted
+	if obj != nil {
+		w.obj = newObject(obj)
+	}
+	return w, nil
+}
+
+// SessionURI returns the URI of the resumable upload session of w, or the
+// empty string if w has none. Only Writers with a CheckpointFunc and Writers
+// returned by ResumeWriter manage a session themselves and report it. The URI
+// is available once the first call to Write or Close has created the session.
+func (w *Writer) SessionURI() string {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.sessionURI
+}
+
+// Offset returns the number of bytes of the object that the service has
+// persisted in the resumable upload session of w. See SessionURI.
+func (w *Writer) Offset() int64 {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.offset
+}
+
+// managesSession reports whether w uploads the object with a resumable upload
+// session that it manages itself, rather than through the JSON API library.
+func (w *Writer) managesSession() bool {
+	return w.CheckpointFunc != nil || w.sessionURI != ""
+}
+
+// openSession starts the upload of a Writer that manages its session.
+func (w *Writer) openSession() error {
+	if err := w.validateWriteAttrs(); err != nil {
+		return err
+	}
+	if w.o.c.tc != nil {
+		return errors.New("storage: resumable upload sessions are not supported by the gRPC API")
+	}
+	if w.ChunkSize <= 0 {
+		return errors.New("storage: Writer.ChunkSize must be positive to checkpoint or resume an upload")
+	}
+
+	pr, pw := io.Pipe()
+	w.pw = pw
+	w.opened = true
+
+	go w.monitorCancel()
+
+	go func() {
+		defer close(w.donec)
+
+		obj, err := w.uploadSession(pr)
+		if err != nil {
+			w.error(err)
+			pr.CloseWithError(err)
+			return
+		}
+		w.obj = obj
+	}()
+	return nil
+}
+
+// uploadSession uploads the data written to w in chunks of w.ChunkSize,
+// creating the session first if w has none.
+func (w *Writer) uploadSession(pr *io.PipeReader) (*ObjectAttrs, error) {
+	if w.obj != nil {
+		// ResumeWriter found that the upload was already complete.
+		if n, _ := pr.Read(make([]byte, 1)); n > 0 {
+			return nil, errors.New("storage: resumable upload session is already complete")
+		}
+		return w.obj, nil
+	}
+	if w.sessionURI == "" {
+		uri, err := w.startSession()
+		if err != nil {
+			return nil, err
+		}
+		w.mu.Lock()
+		w.sessionURI = uri
+		w.mu.Unlock()
+		w.checkpoint(0)
+	}
+
+	// All chunks but the last must be a multiple of 256 KiB.
+	chunkSize := (w.ChunkSize + googleapi.MinUploadChunkSize - 1) / googleapi.MinUploadChunkSize * googleapi.MinUploadChunkSize
+	buf := make([]byte, chunkSize)
+	for {
+		n, err := io.ReadFull(pr, buf)
+		final := err == io.EOF || err == io.ErrUnexpectedEOF
+		if err != nil && !final {
+			return nil, err
+		}
+		obj, err := w.uploadChunk(buf[:n], final)
+		if err != nil {
+			return nil, err
+		}
+		if final {
+			w.crc32cStored = obj.Crc32c != ""
+			return newObject(obj), nil
+		}
+	}
+}
+
+// startSession creates a resumable upload session for the object of w with
+// its attributes and conditions, and returns the URI of the session.
+func (w *Writer) startSession() (string, error) {
+	attrs := w.ObjectAttrs
+	rawObj := attrs.toRawObject(w.o.bucket)
+	if w.SendCRC32C {
+		rawObj.Crc32c = encodeUint32(attrs.CRC32C)
+	}
+	if w.MD5 != nil {
+		rawObj.Md5Hash = base64.StdEncoding.EncodeToString(w.MD5)
+	}
+	body, err := json.Marshal(rawObj)
+	if err != nil {
+		return "", err
+	}
+
+	params := queryParams{
+		"alt":         {"json"},
+		"prettyPrint": {"false"},
+		"uploadType":  {"resumable"},
+		"name":        {w.o.object},
+		"projection":  {"full"},
+	}
+	if err := applyConds("NewWriter", w.o.gen, w.o.conds, params); err != nil {
+		return "", err
+	}
+	if attrs.KMSKeyName != "" {
+		params.Set("kmsKeyName", attrs.KMSKeyName)
+	}
+	if attrs.PredefinedACL != "" {
+		params.Set("predefinedAcl", attrs.PredefinedACL)
+	}
+	if w.o.userProject != "" {
+		params.Set("userProject", w.o.userProject)
+	}
+	u, err := url.Parse(googleapi.ResolveRelative(w.o.c.raw.BasePath, "/upload/storage/v1/b/{bucket}/o"))
+	if err != nil {
+		return "", err
+	}
+	googleapi.Expand(u, map[string]string{"bucket": w.o.bucket})
+	u.RawQuery = url.Values(params).Encode()
+
+	var uri string
+	isIdempotent := w.o.conds != nil && (w.o.conds.GenerationMatch >= 0 || w.o.conds.DoesNotExist == true)
+	err = run(w.ctx, func() error {
+		req, err := http.NewRequest("POST", u.String(), bytes.NewReader(body))
+		if err != nil {
+			return err
+		}
+		req = req.WithContext(w.ctx)
+		req.Header.Set("Content-Type", "application/json")
+		if attrs.ContentType != "" {
+			req.Header.Set("X-Upload-Content-Type", attrs.ContentType)
+		}
+		if err := setEncryptionHeaders(req.Header, w.o.encryptionKey, false); err != nil {
+			return err
+		}
+		setClientHeader(req.Header)
+		resp, err := w.o.c.hc.Do(req)
+		if err != nil {
+			return err
+		}
+		defer resp.Body.Close()
+		if err := googleapi.CheckResponse(resp); err != nil {
+			return err
+		}
+		if uri = resp.Header.Get("Location"); uri == "" {
+			return errors.New("storage: response to the creation of a resumable upload session has no Location")
+		}
+		return nil
+	}, w.o.retry, isIdempotent, func(string, int) {})
+	return uri, err
+}
+
+// queryParams collects the query parameters of a request that the generated
+// library doesn't build, such as the one that creates a resumable upload
+// session. It has the condition methods of raw.ObjectsInsertCall, so that
+// applyConds can set them.
+type queryParams url.Values
+
+func (p queryParams) Set(key, value string) { url.Values(p).Set(key, value) }
+
+func (p queryParams) IfGenerationMatch(gen int64) {
+	p.Set("ifGenerationMatch", strconv.FormatInt(gen, 10))
+}
+
+func (p queryParams) IfGenerationNotMatch(gen int64) {
+	p.Set("ifGenerationNotMatch", strconv.FormatInt(gen, 10))
+}
+
+func (p queryParams) IfMetagenerationMatch(gen int64) {
+	p.Set("ifMetagenerationMatch", strconv.FormatInt(gen, 10))
+}
+
+func (p queryParams) IfMetagenerationNotMatch(gen int64) {
+	p.Set("ifMetagenerationNotMatch", strconv.FormatInt(gen, 10))
+}
+
+// uploadChunk uploads chunk, which starts at the offset that the service has
+// persisted, and retries transient errors. If final is true, chunk is the end
+// of the object, and uploadChunk returns the object.
+func (w *Writer) uploadChunk(chunk []byte, final bool) (*raw.Object, error) {
+	base := w.Offset()
+	end := base + int64(len(chunk))
+	total := int64(-1)
+	if final {
+		total = end
+	}
+
+	retry := w.o.retry
+	if retry == nil {
+		retry = defaultRetry
+	}
+	errorFunc := shouldRetry
+	if retry.shouldRetry != nil {
+		errorFunc = retry.shouldRetry
+	}
+	var bo gax.Backoff
+	if retry.backoff != nil {
+		bo = *retry.backoff
+	}
+	deadline := w.ChunkRetryDeadline
+	if deadline == 0 {
+		deadline = defaultChunkRetryDeadline
+	}
+	start := time.Now()
+
+	for {
+		off := w.Offset()
+		if off < base || off > end {
+			return nil, fmt.Errorf("storage: service persisted %d bytes of the upload, want between %d and %d", off, base, end)
+		}
+		obj, persisted, err := w.putSession(chunk[off-base:], off, total)
+		if err == nil {
+			if obj != nil {
+				w.setOffset(end)
+				return obj, nil
+			}
+			if persisted == off && off < end {
+				return nil, fmt.Errorf("storage: service persisted none of the %d bytes sent at offset %d", end-off, off)
+			}
+			w.setOffset(persisted)
+			if persisted == end && !final {
+				return nil, nil
+			}
+			// The service persisted part of the chunk; send the rest.
+			continue
+		}
+		if retry.policy == RetryNever || !errorFunc(err) || time.Since(start) > deadline {
+			return nil, err
+		}
+		if err := gax.Sleep(w.ctx, bo.Pause()); err != nil {
+			return nil, err
+		}
+		// Ask the service how much of the data it has persisted before sending
+		// the rest again. If that fails too, the next attempt sends the data
+		// from the last known offset.
+		obj, persisted, err = w.putSession(nil, -1, -1)
+		if err == nil && obj != nil {
+			w.setOffset(end)
+			return obj, nil
+		}
+		if err == nil {
+			w.setOffset(persisted)
+		}
+	}
+}
+
+// putSession sends data at offset off to the session of w. If total is not
+// negative, it is the size of the object, and data is its end. If data is nil
+// and off is negative, putSession only queries the status of the session. It
+// returns the number of bytes that the service has persisted, and the object
+// if the upload is complete.
+func (w *Writer) putSession(data []byte, off, total int64) (*raw.Object, int64, error) {
+	size := "*"
+	if total >= 0 {
+		size = strconv.FormatInt(total, 10)
+	}
+	var contentRange string
+	if len(data) == 0 {
+		contentRange = "bytes */" + size
+	} else {
+		contentRange = fmt.Sprintf("bytes %d-%d/%s", off, off+int64(len(data))-1, size)
+	}
+	req, err := http.NewRequest("PUT", w.sessionURI, bytes.NewReader(data))
+	if err != nil {
+		return nil, 0, err
+	}
+	req = req.WithContext(w.ctx)
+	req.Header.Set("Content-Range", contentRange)
+	if err := setEncryptionHeaders(req.Header, w.o.encryptionKey, false); err != nil {
+		return nil, 0, err
+	}
+	setClientHeader(req.Header)
+	resp, err := w.o.c.hc.Do(req)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer resp.Body.Close()
+	switch resp.StatusCode {
+	case http.StatusOK, http.StatusCreated:
+		var obj raw.Object
+		if err := json.NewDecoder(resp.Body).Decode(&obj); err != nil {
+			return nil, 0, err
+		}
+		return &obj, int64(obj.Size), nil
+	case http.StatusPermanentRedirect:
+		// The Range header, if any, is "bytes=0-N" for the persisted bytes.
+		rng := resp.Header.Get("Range")
+		if rng == "" {
+			return nil, 0, nil
+		}
+		i := strings.LastIndex(rng, "-")
+		last, err := strconv.ParseInt(rng[i+1:], 10, 64)
+		if i < 0 || err != nil {
+			return nil, 0, fmt.Errorf("storage: invalid Range %q in response of resumable upload session", rng)
+		}
+		return nil, last + 1, nil
+	}
+	return nil, 0, googleapi.CheckResponse(resp)
+}
+
+// setOffset records that the service has persisted off bytes, and reports it
+// to the callbacks of w.
+func (w *Writer) setOffset(off int64) {
+	w.mu.Lock()
+	changed := off != w.offset
+	w.offset = off
+	w.mu.Unlock()
+	if changed {
+		w.progress(off)
+		w.checkpoint(off)
+	}
+}
+
+func (w *Writer) checkpoint(off int64) {
+	if w.CheckpointFunc != nil {
+		w.CheckpointFunc(w.SessionURI(), off)
+	}
+}
@@ -0,0 +1,163 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package storage
+
+import (
+	"context"
+	"net/url"
+	"strconv"
+	"time"
+
+	"cloud.google.com/go/internal/trace"
+)
+
+const (
+	// ObjectRetentionUnlocked is the mode of an object retention
+	// configuration that can be shortened or removed by users with the
+	// storage.objects.overrideUnlockedRetention permission.
+	ObjectRetentionUnlocked = "Unlocked"
+
+	// ObjectRetentionLocked is the mode of an object retention configuration
+	// that can only be extended, never shortened or removed.
+	ObjectRetentionLocked = "Locked"
+)
+
+// ObjectRetention is the retention configuration of a single object, which
+// keeps the object from being deleted or overwritten until a point in time.
+// It complements the RetentionPolicy of a bucket, which applies to all of its
+// objects. Object retention must be enabled on the bucket.
+type ObjectRetention struct {
+	// Mode is ObjectRetentionUnlocked or ObjectRetentionLocked.
+	Mode string
+
+	// RetainUntil is the time until which the object is retained.
+	RetainUntil time.Time
+}
+
+// rawObjectRetention is the JSON API representation of an ObjectRetention.
+type rawObjectRetention struct {
+	Mode            string    `json:"mode"`
+	RetainUntilTime time.Time `json:"retainUntilTime"`
+}
+
+func (r *rawObjectRetention) toObjectRetention() *ObjectRetention {
+	if r == nil {
+		return nil
+	}
+	return &ObjectRetention{Mode: r.Mode, RetainUntil: r.RetainUntilTime}
+}
+
+// Retention returns the retention configuration of the object, or nil if it
+// has none.
+//
+// The attributes returned by Attrs don't include the retention configuration,
+// because the version of the JSON API library that this package uses doesn't
+// support it.
+//
+// This method is EXPERIMENTAL and subject to change or removal without notice.
+// It is not supported by the gRPC API.
+func (o *ObjectHandle) Retention(ctx context.Context) (r *ObjectRetention, err error) {
+	ctx = trace.StartSpan(ctx, "cloud.google.com/go/storage.Object.Retention")
+	defer func() { trace.EndSpan(ctx, err) }()
+
+	if err := o.validate(); err != nil {
+		return nil, err
+	}
+	params, err := o.jsonParams("Retention")
+	if err != nil {
+		return nil, err
+	}
+	params.Set("fields", "retention")
+	var resp struct {
+		Retention *rawObjectRetention `json:"retention"`
+	}
+	err = o.c.doJSON(ctx, "GET", o.jsonPath(), url.Values(params), nil, &resp, o.retry, true)
+	if isNotFound(err) {
+		return nil, ErrObjectNotExist
+	}
+	if err != nil {
+		return nil, err
+	}
+	return resp.Retention.toObjectRetention(), nil
+}
+
+// SetRetention sets the retention configuration of the object and returns
+// the new configuration. If r is nil, the configuration is removed.
+//
+// An unlocked configuration can be shortened or removed only if
+// overrideUnlocked is true. A locked configuration can only be extended.
+//
+// The update is retried on transient errors only if the handle has a
+// MetagenerationMatch condition.
+//
+// This method is EXPERIMENTAL and subject to change or removal without notice.
+// It is not supported by the gRPC API.
+func (o *ObjectHandle) SetRetention(ctx context.Context, r *ObjectRetention, overrideUnlocked bool) (_ *ObjectRetention, err error) {
+	ctx = trace.StartSpan(ctx, "cloud.google.com/go/storage.Object.SetRetention")
+	defer func() { trace.EndSpan(ctx, err) }()
+
+	if err := o.validate(); err != nil {
+		return nil, err
+	}
+	params, err := o.jsonParams("SetRetention")
+	if err != nil {
+		return nil, err
+	}
+	params.Set("fields", "retention")
+	if overrideUnlocked {
+		params.Set("overrideUnlockedRetention", "true")
+	}
+	var body struct {
+		// A nil Retention is sent as null, which removes the configuration.
+		Retention *rawObjectRetention `json:"retention"`
+	}
+	if r != nil {
+		body.Retention = &rawObjectRetention{Mode: r.Mode, RetainUntilTime: r.RetainUntil}
+	}
+	var resp struct {
+		Retention *rawObjectRetention `json:"retention"`
+	}
+	isIdempotent := o.conds != nil && o.conds.MetagenerationMatch != 0
+	err = o.c.doJSON(ctx, "PATCH", o.jsonPath(), url.Values(params), &body, &resp, o.retry, isIdempotent)
+	if isNotFound(err) {
+		return nil, ErrObjectNotExist
+	}
+	if err != nil {
+		return nil, err
+	}
+	return resp.Retention.toObjectRetention(), nil
+}
+
+// jsonParams returns the query parameters for the generation, conditions and
+// user project of o, for requests that doJSON sends.
+func (o *ObjectHandle) jsonParams(method string) (queryParams, error) {
+	params := queryParams{}
+	if err := applyConds(method, defaultGen, o.conds, params); err != nil {
+		return nil, err
+	}
+	if o.gen >= 0 {
+		params.Set("generation", strconv.FormatInt(o.gen, 10))
+	}
+	if o.userProject != "" {
+		params.Set("userProject", o.userProject)
+	}
+	return params, nil
+}
+
+// jsonPath returns the path of the object relative to the base path of the
+// JSON API.
+func (o *ObjectHandle) jsonPath() string {
+	return "b/" + url.PathEscape(o.bucket) + "/o/" + url.PathEscape(o.object)
+}
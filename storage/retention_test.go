@@ -0,0 +1,88 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package storage
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"google.golang.org/api/option"
+)
+
+func TestObjectRetention(t *testing.T) {
+	var retention string // the JSON of the retention configuration, if any
+	var gotQuery string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if got, want := r.URL.EscapedPath(), "/storage/v1/b/bucket/o/dir%2Fobj"; got != want {
+			http.Error(w, "not found", http.StatusNotFound)
+			return
+		}
+		gotQuery = r.URL.RawQuery
+		if r.Method == "PATCH" {
+			b, _ := ioutil.ReadAll(r.Body)
+			retention = string(b)
+		}
+		if retention == "" {
+			w.Write([]byte("{}"))
+			return
+		}
+		w.Write([]byte(retention))
+	}))
+	defer srv.Close()
+	ctx := context.Background()
+	client, err := NewClient(ctx, option.WithEndpoint(srv.URL+"/storage/v1/"), option.WithoutAuthentication())
+	if err != nil {
+		t.Fatal(err)
+	}
+	o := client.Bucket("bucket").Object("dir/obj")
+
+	r, err := o.Retention(ctx)
+	if err != nil || r != nil {
+		t.Fatalf("got (%+v, %v), want no retention", r, err)
+	}
+	until := time.Date(2030, 1, 2, 3, 4, 5, 0, time.UTC)
+	r, err = o.If(Conditions{MetagenerationMatch: 3}).SetRetention(ctx, &ObjectRetention{Mode: ObjectRetentionUnlocked, RetainUntil: until}, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := (ObjectRetention{Mode: ObjectRetentionUnlocked, RetainUntil: until}); r == nil || *r != want {
+		t.Errorf("got %+v, want %+v", r, want)
+	}
+	if want := "alt=json&fields=retention&ifMetagenerationMatch=3&prettyPrint=false"; gotQuery != want {
+		t.Errorf("got query %q, want %q", gotQuery, want)
+	}
+	if r, err = o.Retention(ctx); err != nil || r == nil || !r.RetainUntil.Equal(until) {
+		t.Errorf("got (%+v, %v), want retention until %v", r, err, until)
+	}
+
+	r, err = o.SetRetention(ctx, nil, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r != nil || retention != `{"retention":null}` {
+		t.Errorf("got %+v and request %s, want the retention removed", r, retention)
+	}
+	if want := "alt=json&fields=retention&overrideUnlockedRetention=true&prettyPrint=false"; gotQuery != want {
+		t.Errorf("got query %q, want %q", gotQuery, want)
+	}
+
+	if _, err := client.Bucket("bucket").Object("missing").Retention(ctx); err != ErrObjectNotExist {
+		t.Errorf("got %v, want ErrObjectNotExist", err)
+	}
+}
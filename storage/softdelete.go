@@ -0,0 +1,173 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package storage
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"strconv"
+	"time"
+
+	"cloud.google.com/go/internal/trace"
+	"google.golang.org/api/googleapi"
+	raw "google.golang.org/api/storage/v1"
+)
+
+// SoftDeletePolicy is the soft delete policy of a bucket. Objects that are
+// deleted or overwritten in a bucket with a soft delete policy are kept as
+// soft-deleted objects for the retention duration of the policy, during which
+// they can be listed with Query.SoftDeleted and restored with
+// ObjectHandle.Restore.
+type SoftDeletePolicy struct {
+	// RetentionDuration is how long soft-deleted objects are kept. It must be
+	// zero, which disables soft delete, or between 7 and 90 days.
+	RetentionDuration time.Duration
+
+	// EffectiveTime is the time from which the policy applies. This field is
+	// read-only.
+	EffectiveTime time.Time
+}
+
+// rawSoftDeletePolicy is the JSON API representation of a SoftDeletePolicy.
+type rawSoftDeletePolicy struct {
+	RetentionDurationSeconds int64     `json:"retentionDurationSeconds,string"`
+	EffectiveTime            time.Time `json:"effectiveTime"`
+}
+
+func (p *rawSoftDeletePolicy) toSoftDeletePolicy() *SoftDeletePolicy {
+	if p == nil {
+		return nil
+	}
+	return &SoftDeletePolicy{
+		RetentionDuration: time.Duration(p.RetentionDurationSeconds) * time.Second,
+		EffectiveTime:     p.EffectiveTime,
+	}
+}
+
+// SoftDeletePolicy returns the soft delete policy of the bucket, or nil if it
+// has none.
+//
+// This method is EXPERIMENTAL and subject to change or removal without notice.
+// It is not supported by the gRPC API.
+func (b *BucketHandle) SoftDeletePolicy(ctx context.Context) (p *SoftDeletePolicy, err error) {
+	ctx = trace.StartSpan(ctx, "cloud.google.com/go/storage.Bucket.SoftDeletePolicy")
+	defer func() { trace.EndSpan(ctx, err) }()
+
+	params := b.withUserProject(url.Values{"fields": {"softDeletePolicy"}})
+	var resp struct {
+		SoftDeletePolicy *rawSoftDeletePolicy `json:"softDeletePolicy"`
+	}
+	err = b.c.doJSON(ctx, "GET", "b/"+url.PathEscape(b.name), params, nil, &resp, b.retry, true)
+	if isNotFound(err) {
+		return nil, ErrBucketNotExist
+	}
+	if err != nil {
+		return nil, err
+	}
+	return resp.SoftDeletePolicy.toSoftDeletePolicy(), nil
+}
+
+// SetSoftDeletePolicy sets the soft delete policy of the bucket and returns
+// the new policy. A policy with a zero RetentionDuration disables soft delete.
+//
+// The update is retried on transient errors only if the handle has a
+// MetagenerationMatch condition.
+//
+// This method is EXPERIMENTAL and subject to change or removal without notice.
+// It is not supported by the gRPC API.
+func (b *BucketHandle) SetSoftDeletePolicy(ctx context.Context, p SoftDeletePolicy) (_ *SoftDeletePolicy, err error) {
+	ctx = trace.StartSpan(ctx, "cloud.google.com/go/storage.Bucket.SetSoftDeletePolicy")
+	defer func() { trace.EndSpan(ctx, err) }()
+
+	params := queryParams{"fields": {"softDeletePolicy"}}
+	if err := applyBucketConds("SetSoftDeletePolicy", b.conds, params); err != nil {
+		return nil, err
+	}
+	body := map[string]map[string]string{
+		"softDeletePolicy": {"retentionDurationSeconds": strconv.FormatInt(int64(p.RetentionDuration/time.Second), 10)},
+	}
+	var resp struct {
+		SoftDeletePolicy *rawSoftDeletePolicy `json:"softDeletePolicy"`
+	}
+	isIdempotent := b.conds != nil && b.conds.MetagenerationMatch != 0
+	err = b.c.doJSON(ctx, "PATCH", "b/"+url.PathEscape(b.name), b.withUserProject(url.Values(params)), body, &resp, b.retry, isIdempotent)
+	if isNotFound(err) {
+		return nil, ErrBucketNotExist
+	}
+	if err != nil {
+		return nil, err
+	}
+	return resp.SoftDeletePolicy.toSoftDeletePolicy(), nil
+}
+
+// RestoreOptions are the options of ObjectHandle.Restore.
+type RestoreOptions struct {
+	// CopySourceACL restores the ACL of the object as it was when the object
+	// was deleted. By default, the restored object gets the default object ACL
+	// of the bucket.
+	CopySourceACL bool
+}
+
+// Restore restores a soft-deleted object as the live object, and returns the
+// attributes of the restored object. The handle must select the generation to
+// restore with Generation; the generations of soft-deleted objects can be
+// listed with Query.SoftDeleted. Restore fails if a live object exists, unless
+// the handle has conditions that allow it to be replaced.
+//
+// Restore is retried on transient errors only if the handle has a
+// GenerationMatch or DoesNotExist condition.
+//
+// This method is EXPERIMENTAL and subject to change or removal without notice.
+// It is not supported by the gRPC API.
+func (o *ObjectHandle) Restore(ctx context.Context, opts *RestoreOptions) (attrs *ObjectAttrs, err error) {
+	ctx = trace.StartSpan(ctx, "cloud.google.com/go/storage.Object.Restore")
+	defer func() { trace.EndSpan(ctx, err) }()
+
+	if err := o.validate(); err != nil {
+		return nil, err
+	}
+	if o.gen < 0 {
+		return nil, errors.New("storage: Restore requires the generation of the soft-deleted object")
+	}
+	params, err := o.jsonParams("Restore")
+	if err != nil {
+		return nil, err
+	}
+	params.Set("projection", "full")
+	if opts != nil && opts.CopySourceACL {
+		params.Set("copySourceAcl", "true")
+	}
+	var obj raw.Object
+	isIdempotent := o.conds != nil && (o.conds.GenerationMatch != 0 || o.conds.DoesNotExist)
+	err = o.c.doJSON(ctx, "POST", o.jsonPath()+"/restore", url.Values(params), nil, &obj, o.retry, isIdempotent)
+	if isNotFound(err) {
+		return nil, ErrObjectNotExist
+	}
+	if err != nil {
+		return nil, err
+	}
+	return newObject(&obj), nil
+}
+
+// softDeletedOption returns the call option that lists soft-deleted objects
+// instead of live ones if softDeleted is true. The JSON API library doesn't
+// have a method for the parameter yet.
+func softDeletedOption(softDeleted bool) []googleapi.CallOption {
+	if !softDeleted {
+		return nil
+	}
+	return []googleapi.CallOption{googleapi.QueryParameter("softDeleted", strconv.FormatBool(softDeleted))}
+}
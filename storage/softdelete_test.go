@@ -0,0 +1,110 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package storage
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"google.golang.org/api/iterator"
+	"google.golang.org/api/option"
+)
+
+func TestSoftDelete(t *testing.T) {
+	seconds := "0"
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		switch {
+		case r.URL.Path == "/storage/v1/b/bucket" && r.Method == "PATCH":
+			b, _ := ioutil.ReadAll(r.Body)
+			if got, want := string(b), `{"softDeletePolicy":{"retentionDurationSeconds":"864000"}}`; got != want {
+				http.Error(w, "unexpected body "+got, http.StatusBadRequest)
+				return
+			}
+			seconds = "864000"
+			fallthrough
+		case r.URL.Path == "/storage/v1/b/bucket":
+			fmt.Fprintf(w, `{"softDeletePolicy": {"retentionDurationSeconds": %q, "effectiveTime": "2022-06-01T00:00:00Z"}}`, seconds)
+		case r.URL.Path == "/storage/v1/b/bucket/o" && q.Get("softDeleted") == "true":
+			fmt.Fprint(w, `{"items": [{"name": "obj", "generation": "5"}]}`)
+		case r.URL.Path == "/storage/v1/b/bucket/o":
+			fmt.Fprint(w, `{}`)
+		case r.URL.Path == "/storage/v1/b/bucket/o/obj/restore" && r.Method == "POST":
+			if q.Get("generation") != "5" || q.Get("copySourceAcl") != "true" {
+				http.Error(w, "not found", http.StatusNotFound)
+				return
+			}
+			fmt.Fprint(w, `{"name": "obj", "generation": "6"}`)
+		default:
+			http.Error(w, "not found", http.StatusNotFound)
+		}
+	}))
+	defer srv.Close()
+	ctx := context.Background()
+	client, err := NewClient(ctx, option.WithEndpoint(srv.URL+"/storage/v1/"), option.WithoutAuthentication())
+	if err != nil {
+		t.Fatal(err)
+	}
+	b := client.Bucket("bucket")
+
+	p, err := b.SetSoftDeletePolicy(ctx, SoftDeletePolicy{RetentionDuration: 10 * 24 * time.Hour})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := SoftDeletePolicy{RetentionDuration: 10 * 24 * time.Hour, EffectiveTime: time.Date(2022, 6, 1, 0, 0, 0, 0, time.UTC)}
+	if *p != want {
+		t.Errorf("got policy %+v, want %+v", p, want)
+	}
+	if p, err := b.SoftDeletePolicy(ctx); err != nil || *p != want {
+		t.Errorf("got (%+v, %v), want %+v", p, err, want)
+	}
+	if _, err := client.Bucket("missing").SoftDeletePolicy(ctx); err != ErrBucketNotExist {
+		t.Errorf("got %v, want ErrBucketNotExist", err)
+	}
+
+	// Only soft-deleted objects are listed with SoftDeleted.
+	for _, softDeleted := range []bool{false, true} {
+		it := b.Objects(ctx, &Query{SoftDeleted: softDeleted})
+		attrs, err := it.Next()
+		if !softDeleted {
+			if err != iterator.Done {
+				t.Errorf("live objects: got (%+v, %v), want none", attrs, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		if attrs.Generation != 5 {
+			t.Errorf("got generation %d, want 5", attrs.Generation)
+		}
+		attrs, err = b.Object(attrs.Name).Generation(attrs.Generation).Restore(ctx, &RestoreOptions{CopySourceACL: true})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if attrs.Generation != 6 {
+			t.Errorf("restored object: got generation %d, want 6", attrs.Generation)
+		}
+	}
+
+	if _, err := b.Object("obj").Restore(ctx, nil); err == nil {
+		t.Error("Restore without a generation: got nil error")
+	}
+}
@@ -128,9 +128,14 @@ func NewClient(ctx context.Context, opts ...option.ClientOption) (*Client, error
 	// Use the experimental gRPC client if the env var is set.
 	// This is an experimental API and not intended for public use.
 	if withGRPC := os.Getenv("STORAGE_USE_GRPC"); withGRPC != "" {
-		return newGRPCClient(ctx, opts...)
+		return NewGRPCClient(ctx, opts...)
 	}
+	return newHTTPClient(ctx, opts...)
+}
 
+// newHTTPClient creates a new Storage client that uses the JSON API for all
+// operations.
+func newHTTPClient(ctx context.Context, opts ...option.ClientOption) (*Client, error) {
 	var creds *google.Credentials
 
 	// In general, it is recommended to use raw.NewService instead of htransport.NewClient
@@ -204,18 +209,33 @@ func NewClient(ctx context.Context, opts ...option.ClientOption) (*Client, error
 	}, nil
 }
 
-// newGRPCClient creates a new Storage client that initializes a gRPC-based
-// client. Calls that have not been implemented in gRPC will panic.
+// NewGRPCClient creates a new Google Cloud Storage client that reads and
+// writes object data with the gRPC API of Cloud Storage. Readers that are
+// created with ObjectHandle.NewReader or ObjectHandle.NewRangeReader, and
+// Writers that are created with ObjectHandle.NewWriter, stream the object
+// data over gRPC, and use DirectPath when the client runs on Google Compute
+// Engine or Google Kubernetes Engine and DirectPath is available. This reduces
+// the latency and CPU usage of large transfers. All other operations use the
+// JSON API, as with NewClient.
 //
-// This is an experimental API and not intended for public use.
-func newGRPCClient(ctx context.Context, opts ...option.ClientOption) (*Client, error) {
-	opts = append(defaultGRPCOptions(), opts...)
+// The options are used for both APIs. Options that select a transport, such
+// as option.WithHTTPClient, option.WithGRPCConn and option.WithEndpoint, are
+// not supported. To use emulators, set STORAGE_EMULATOR_HOST to the host of
+// the JSON API and STORAGE_EMULATOR_HOST_GRPC to the host of the gRPC API.
+//
+// This is an experimental API and subject to change or removal without
+// notice. The gRPC API of Cloud Storage may not be available to all projects.
+func NewGRPCClient(ctx context.Context, opts ...option.ClientOption) (*Client, error) {
+	c, err := newHTTPClient(ctx, opts...)
+	if err != nil {
+		return nil, err
+	}
 	tc, err := newGRPCStorageClient(ctx, withClientOptions(opts...))
 	if err != nil {
 		return nil, err
 	}
-
-	return &Client{tc: tc}, nil
+	c.tc = tc
+	return c, nil
 }
 
 // Close closes the Client.
@@ -285,7 +305,7 @@ func (s virtualHostedStyle) host(bucket string) string {
 }
 
 func (s bucketBoundHostname) host(bucket string) string {
-	return s.hostname
+	return stripScheme(s.hostname)
 }
 
 func (s pathStyle) path(bucket, object string) string {
@@ -323,7 +343,11 @@ func VirtualHostedStyle() URLStyle {
 // https://cloud.google.com/storage/docs/request-endpoints#cname and
 // https://cloud.google.com/load-balancing/docs/https/adding-backend-buckets-to-load-balancers
 // for details. Note that for CNAMEs, only HTTP is supported, so Insecure must
-// be set to true.
+// be set to true. A Cloud CDN or load balancer domain supports HTTPS.
+//
+// If a CDN or proxy in front of the bucket rewrites the Host header of the
+// requests it forwards, sign the rewritten host by adding a "host" header to
+// SignedURLOptions.Headers.
 func BucketBoundHostname(hostname string) URLStyle {
 	return bucketBoundHostname{hostname: hostname}
 }
@@ -336,6 +360,16 @@ func stripScheme(host string) string {
 	return host
 }
 
+// hasHeader reports whether names holds the header name.
+func hasHeader(names []string, name string) bool {
+	for _, n := range names {
+		if strings.EqualFold(strings.TrimSpace(n), name) {
+			return true
+		}
+	}
+	return false
+}
+
 // SignedURLOptions allows you to restrict the access to the signed URL.
 type SignedURLOptions struct {
 	// GoogleAccessID represents the authorizer of the signed URL generation.
@@ -423,6 +457,19 @@ type SignedURLOptions struct {
 	// Optional.
 	Insecure bool
 
+	// Hostname sets the host of the signed URL, for example to sign URLs for a
+	// private endpoint. It overrides STORAGE_EMULATOR_HOST. With
+	// VirtualHostedStyle, the URL host is the bucket name followed by a dot and
+	// Hostname. Hostname cannot be used with BucketBoundHostname, which sets
+	// the host itself.
+	//
+	// For V4 signing, the host is signed as the value of the Host header that
+	// the service receives. If that differs from the host of the URL, for
+	// example because a proxy rewrites it, add a "host" header with the value
+	// that the service receives to Headers.
+	// Optional.
+	Hostname string
+
 	// Scheme determines the version of URL signing to use. Default is
 	// SigningSchemeV2.
 	Scheme SigningScheme
@@ -441,10 +488,23 @@ func (opts *SignedURLOptions) clone() *SignedURLOptions {
 		MD5:             opts.MD5,
 		Style:           opts.Style,
 		Insecure:        opts.Insecure,
+		Hostname:        opts.Hostname,
 		Scheme:          opts.Scheme,
 	}
 }
 
+// host returns the host of the signed URL for bucket, based on the URL style
+// and Hostname.
+func (opts *SignedURLOptions) host(bucket string) string {
+	if opts.Hostname == "" {
+		return opts.Style.host(bucket)
+	}
+	if _, ok := opts.Style.(virtualHostedStyle); ok {
+		return bucket + "." + stripScheme(opts.Hostname)
+	}
+	return stripScheme(opts.Hostname)
+}
+
 var (
 	tabRegex = regexp.MustCompile(`[\t]+`)
 	// I was tempted to call this spacex. :)
@@ -523,7 +583,7 @@ func v4SanitizeHeaders(hdrs []string) []string {
 		sanitizedHeader := strings.TrimSpace(hdr)
 
 		var key, value string
-		headerMatches := strings.Split(sanitizedHeader, ":")
+		headerMatches := strings.SplitN(sanitizedHeader, ":", 2)
 		if len(headerMatches) < 2 {
 			continue
 		}
@@ -612,6 +672,9 @@ func validateOptions(opts *SignedURLOptions, now time.Time) error {
 	if _, ok := opts.Style.(pathStyle); !ok && opts.Scheme == SigningSchemeV2 {
 		return errors.New("storage: only path-style URLs are permitted with SigningSchemeV2")
 	}
+	if _, ok := opts.Style.(bucketBoundHostname); ok && opts.Hostname != "" {
+		return errors.New("storage: Hostname cannot be used with BucketBoundHostname")
+	}
 	if opts.Scheme == SigningSchemeV4 {
 		cutoff := now.Add(604801 * time.Second) // 7 days + 1 second
 		if !opts.Expires.Before(cutoff) {
@@ -671,7 +734,15 @@ func signedURLV4(bucket, name string, opts *SignedURLOptions, now time.Time) (st
 	// canonical query).
 	fmt.Fprintf(buf, "/%s\n", u.RawPath)
 
-	headerNames := append(extractHeaderNames(opts.Headers), "host")
+	// Sign the host of the URL, unless the caller supplied the Host header
+	// that the service receives.
+	u.Host = opts.host(bucket)
+	headers := opts.Headers
+	headerNames := extractHeaderNames(headers)
+	if !hasHeader(headerNames, "host") {
+		headers = append([]string{"host:" + u.Host}, headers...)
+		headerNames = append(headerNames, "host")
+	}
 	if opts.ContentType != "" {
 		headerNames = append(headerNames, "content-type")
 	}
@@ -699,9 +770,6 @@ func signedURLV4(bucket, name string, opts *SignedURLOptions, now time.Time) (st
 	escapedQuery := strings.Replace(canonicalQueryString.Encode(), "+", "%20", -1)
 	fmt.Fprintf(buf, "%s\n", escapedQuery)
 
-	// Fill in the hostname based on the desired URL style.
-	u.Host = opts.Style.host(bucket)
-
 	// Fill in the URL scheme.
 	if opts.Insecure {
 		u.Scheme = "http"
@@ -710,8 +778,7 @@ func signedURLV4(bucket, name string, opts *SignedURLOptions, now time.Time) (st
 	}
 
 	var headersWithValue []string
-	headersWithValue = append(headersWithValue, "host:"+u.Host)
-	headersWithValue = append(headersWithValue, opts.Headers...)
+	headersWithValue = append(headersWithValue, headers...)
 	if opts.ContentType != "" {
 		headersWithValue = append(headersWithValue, "content-type:"+opts.ContentType)
 	}
@@ -834,7 +901,7 @@ func signedURLV2(bucket, name string, opts *SignedURLOptions) (string, error) {
 	}
 	encoded := base64.StdEncoding.EncodeToString(b)
 	u.Scheme = "https"
-	u.Host = PathStyle().host(bucket)
+	u.Host = opts.host(bucket)
 	q := u.Query()
 	q.Set("GoogleAccessId", opts.GoogleAccessID)
 	q.Set("Expires", fmt.Sprintf("%d", opts.Expires.Unix()))
@@ -1627,6 +1694,12 @@ type Query struct {
 	// true, they will also be included as objects and their metadata will be
 	// populated in the returned ObjectAttrs.
 	IncludeTrailingDelimiter bool
+
+	// SoftDeleted lists only the soft-deleted objects of a bucket with a
+	// soft delete policy, including all of their generations, instead of the
+	// live objects. Soft-deleted objects can be restored with
+	// ObjectHandle.Restore. SoftDeleted is not supported by the gRPC API.
+	SoftDeleted bool
 }
 
 // attrToFieldMap maps the field names of ObjectAttrs to the underlying field
@@ -119,6 +119,11 @@ func TestV4HeaderSanitization(t *testing.T) {
 			in:   []string{"foo:bar        gaz"},
 			want: []string{"foo:bar gaz"},
 		},
+		{
+			desc: "colons in values are kept",
+			in:   []string{"host:proxy.example.com:8443"},
+			want: []string{"host:proxy.example.com:8443"},
+		},
 	}
 	for _, test := range tests {
 		got := v4SanitizeHeaders(test.in)
@@ -499,6 +504,96 @@ func TestSignedURL_EmulatorHost(t *testing.T) {
 	}
 }
 
+func TestSignedURLHosts(t *testing.T) {
+	expires, _ := time.Parse(time.RFC3339, "2002-10-02T10:00:00-05:00")
+	oldUTCNow := utcNow
+	defer func() { utcNow = oldUTCNow }()
+	utcNow = func() time.Time { return expires.Add(-24 * time.Hour) }
+
+	for _, test := range []struct {
+		desc       string
+		style      URLStyle
+		hostname   string
+		headers    []string
+		scheme     SigningScheme
+		wantURL    string // without the query
+		wantSigned string
+		wantErr    bool
+	}{
+		{
+			desc:       "path style with Hostname",
+			style:      PathStyle(),
+			hostname:   "https://private.example.com",
+			scheme:     SigningSchemeV4,
+			wantURL:    "https://private.example.com/bucket-name/object-name",
+			wantSigned: "host",
+		},
+		{
+			desc:       "virtual-hosted style with Hostname",
+			style:      VirtualHostedStyle(),
+			hostname:   "private.example.com",
+			scheme:     SigningSchemeV4,
+			wantURL:    "https://bucket-name.private.example.com/object-name",
+			wantSigned: "host",
+		},
+		{
+			desc:       "V2 with Hostname",
+			hostname:   "private.example.com",
+			scheme:     SigningSchemeV2,
+			wantURL:    "https://private.example.com/bucket-name/object-name",
+			wantSigned: "",
+		},
+		{
+			desc:       "bucket-bound hostname with a scheme and a Host header",
+			style:      BucketBoundHostname("https://cdn.example.com"),
+			headers:    []string{"Host: storage.googleapis.com", "x-goog-meta-a:b"},
+			scheme:     SigningSchemeV4,
+			wantURL:    "https://cdn.example.com/object-name",
+			wantSigned: "host;x-goog-meta-a",
+		},
+		{
+			desc:     "bucket-bound hostname with Hostname",
+			style:    BucketBoundHostname("cdn.example.com"),
+			hostname: "private.example.com",
+			scheme:   SigningSchemeV4,
+			wantErr:  true,
+		},
+	} {
+		t.Run(test.desc, func(t *testing.T) {
+			got, err := SignedURL("bucket-name", "object-name", &SignedURLOptions{
+				GoogleAccessID: "xxx@clientid",
+				PrivateKey:     dummyKey("rsa"),
+				Method:         "GET",
+				Expires:        expires,
+				Style:          test.style,
+				Hostname:       test.hostname,
+				Headers:        test.headers,
+				Scheme:         test.scheme,
+			})
+			if test.wantErr {
+				if err == nil {
+					t.Fatalf("got %q, want an error", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			u, err := url.Parse(got)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if q := u.Query(); q.Get("X-Goog-SignedHeaders") != test.wantSigned {
+				t.Errorf("got signed headers %q, want %q", q.Get("X-Goog-SignedHeaders"), test.wantSigned)
+			}
+			u.RawQuery = ""
+			if u.String() != test.wantURL {
+				t.Errorf("got URL %q, want %q", u, test.wantURL)
+			}
+		})
+	}
+}
+
 func TestSignedURL_MissingOptions(t *testing.T) {
 	now, _ := time.Parse(time.RFC3339, "2002-10-01T00:00:00-05:00")
 	expires, _ := time.Parse(time.RFC3339, "2002-10-15T00:00:00-05:00")
@@ -2115,6 +2210,33 @@ func TestWithEndpoint(t *testing.T) {
 	os.Setenv("STORAGE_EMULATOR_HOST", originalStorageEmulatorHost)
 }
 
+func TestNewGRPCClient(t *testing.T) {
+	for _, env := range []string{"STORAGE_EMULATOR_HOST", "STORAGE_EMULATOR_HOST_GRPC"} {
+		defer os.Setenv(env, os.Getenv(env))
+	}
+	os.Setenv("STORAGE_EMULATOR_HOST", "http://localhost:9000")
+	os.Setenv("STORAGE_EMULATOR_HOST_GRPC", "localhost:9001")
+
+	c, err := NewGRPCClient(context.Background())
+	if err != nil {
+		t.Fatalf("NewGRPCClient: %v", err)
+	}
+	// Object data is read and written with gRPC, everything else uses the
+	// JSON API.
+	if _, ok := c.tc.(*grpcStorageClient); !ok {
+		t.Errorf("got transport client %T, want *grpcStorageClient", c.tc)
+	}
+	if got, want := c.raw.BasePath, "http://localhost:9000/storage/v1/"; got != want {
+		t.Errorf("raw.BasePath: got %q, want %q", got, want)
+	}
+	if got, want := c.readHost, "localhost:9000"; got != want {
+		t.Errorf("readHost: got %q, want %q", got, want)
+	}
+	if err := c.Close(); err != nil {
+		t.Errorf("Close: %v", err)
+	}
+}
+
 // Create a client using a combination of custom endpoint and STORAGE_EMULATOR_HOST
 // env variable and verify that the client hits the correct endpoint for several
 // different operations performe in sequence.
@@ -2322,6 +2444,7 @@ func TestSignedURLOptionsClone(t *testing.T) {
 		MD5:             "some-checksum",
 		Style:           VirtualHostedStyle(),
 		Insecure:        true,
+		Hostname:        "localhost:8000",
 		Scheme:          SigningSchemeV2,
 	}
 
@@ -0,0 +1,49 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+/*
+Package transfermanager uploads and downloads many Cloud Storage objects
+concurrently.
+
+A Manager runs a pool of workers that transfer objects between Cloud Storage
+and local files. Transfers are added with UploadObject and DownloadObject, or
+for all files of a local directory and all objects with a prefix with
+UploadDirectory and DownloadDirectory. A transfer that fails with a transient
+error is retried as a whole. WaitAndClose waits for all transfers to finish and
+returns a summary of their results:
+
+	m, err := transfermanager.NewManager(client, transfermanager.WithWorkers(32))
+	if err != nil {
+		// TODO: Handle error.
+	}
+	err = m.DownloadDirectory(ctx, &transfermanager.DownloadDirectoryInput{
+		Bucket:   "my-bucket",
+		Prefix:   "logs/2022/",
+		LocalDir: "/tmp/logs",
+	})
+	if err != nil {
+		// TODO: Handle error.
+	}
+	summary, err := m.WaitAndClose()
+	if err != nil {
+		// Some transfers failed. Their errors are in summary.Results.
+	}
+
+DownloadSliced downloads a single large object faster by reading slices of it
+concurrently. An interrupted sliced download can be resumed by calling
+DownloadSliced again with the same input.
+
+This package is EXPERIMENTAL and subject to change or removal without notice.
+*/
+package transfermanager // import "cloud.google.com/go/storage/transfermanager"
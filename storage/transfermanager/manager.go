@@ -0,0 +1,411 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package transfermanager
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"cloud.google.com/go/storage"
+	"github.com/googleapis/gax-go/v2"
+	"google.golang.org/api/iterator"
+)
+
+// errClosed is returned when a transfer is added to a Manager after
+// WaitAndClose has been called.
+var errClosed = errors.New("transfermanager: manager is closed")
+
+// Manager transfers objects between Cloud Storage and local files with a pool
+// of workers. Create a Manager with NewManager, add transfers to it, and call
+// WaitAndClose to wait for the transfers and get their results.
+//
+// The methods that add transfers block until a worker is available, and fail
+// once WaitAndClose has been called. All methods are safe for concurrent use.
+type Manager struct {
+	client *storage.Client
+	config *config
+	work   chan *transfer
+	start  time.Time
+
+	workers sync.WaitGroup
+
+	// addMu is held for reading while a transfer is added, and for writing
+	// while work is closed.
+	addMu  sync.RWMutex
+	closed bool
+
+	mu      sync.Mutex
+	results []Result
+}
+
+// transfer is a transfer of one object that a worker runs.
+type transfer struct {
+	ctx      context.Context
+	upload   *UploadObjectInput
+	download *DownloadObjectInput
+	// err, if set, fails the transfer without attempting it.
+	err error
+}
+
+// NewManager creates a Manager that transfers objects with client and starts
+// its workers.
+func NewManager(client *storage.Client, opts ...Option) (*Manager, error) {
+	if client == nil {
+		return nil, errors.New("transfermanager: client must not be nil")
+	}
+	c := defaultConfig()
+	for _, o := range opts {
+		o.apply(c)
+	}
+	if c.workers < 1 {
+		return nil, fmt.Errorf("transfermanager: number of workers must be positive, got %d", c.workers)
+	}
+	if c.maxAttempts < 1 {
+		return nil, fmt.Errorf("transfermanager: maximum number of attempts must be positive, got %d", c.maxAttempts)
+	}
+	m := &Manager{
+		client: client,
+		config: c,
+		work:   make(chan *transfer),
+		start:  time.Now(),
+	}
+	m.workers.Add(c.workers)
+	for i := 0; i < c.workers; i++ {
+		go m.worker()
+	}
+	return m, nil
+}
+
+// UploadObjectInput describes the upload of a local file to an object.
+type UploadObjectInput struct {
+	// Path is the path of the local file.
+	Path string
+
+	// Bucket and Object are the names of the bucket and the object to upload
+	// the file to.
+	Bucket, Object string
+
+	// ContentType is the content type of the object. If empty, it is
+	// detected from the content of the file.
+	ContentType string
+
+	// Conditions are the preconditions of the upload, if any. For example,
+	// DoesNotExist only uploads the file if the object does not exist yet.
+	Conditions *storage.Conditions
+}
+
+// DownloadObjectInput describes the download of an object to a local file.
+type DownloadObjectInput struct {
+	// Bucket and Object are the names of the bucket and the object.
+	Bucket, Object string
+
+	// Path is the path of the local file. Its parent directories are created
+	// as needed, and an existing file is overwritten.
+	Path string
+}
+
+// UploadDirectoryInput describes the upload of all files of a local
+// directory tree.
+type UploadDirectoryInput struct {
+	// LocalDir is the local directory. Its files, including those in
+	// subdirectories, are uploaded.
+	LocalDir string
+
+	// Bucket is the name of the bucket to upload the files to.
+	Bucket string
+
+	// Prefix is prepended to the slash-separated paths of the files relative
+	// to LocalDir to form the names of the objects. It usually ends with a
+	// slash.
+	Prefix string
+}
+
+// DownloadDirectoryInput describes the download of all objects with a prefix
+// to a local directory tree.
+type DownloadDirectoryInput struct {
+	// Bucket is the name of the bucket.
+	Bucket string
+
+	// Prefix selects the objects to download. The part of the object names
+	// after the prefix is the path of the local files relative to LocalDir.
+	// Objects whose names end with a slash are skipped.
+	Prefix string
+
+	// LocalDir is the local directory to download the objects to.
+	LocalDir string
+}
+
+// Result is the outcome of one transfer.
+type Result struct {
+	// Upload is true for uploads and false for downloads.
+	Upload bool
+
+	// Bucket and Object are the names of the bucket and the object.
+	Bucket, Object string
+
+	// Path is the path of the local file.
+	Path string
+
+	// Bytes is the number of bytes that were transferred by the last attempt.
+	Bytes int64
+
+	// Attempts is the number of attempts of the transfer.
+	Attempts int
+
+	// Err is the error of the last attempt, or nil if the transfer succeeded.
+	Err error
+}
+
+// Summary reports the results of all transfers of a Manager.
+type Summary struct {
+	// Results holds the result of each transfer, in the order in which the
+	// transfers finished.
+	Results []Result
+
+	// Succeeded and Failed are the numbers of transfers that succeeded and
+	// failed.
+	Succeeded, Failed int
+
+	// Bytes is the number of bytes of the transfers that succeeded.
+	Bytes int64
+
+	// Duration is the time from the creation of the Manager until all
+	// transfers were done.
+	Duration time.Duration
+}
+
+// UploadObject adds the upload of a local file to m.
+func (m *Manager) UploadObject(ctx context.Context, in *UploadObjectInput) error {
+	return m.add(&transfer{ctx: ctx, upload: in})
+}
+
+// DownloadObject adds the download of an object to m.
+func (m *Manager) DownloadObject(ctx context.Context, in *DownloadObjectInput) error {
+	return m.add(&transfer{ctx: ctx, download: in})
+}
+
+// UploadDirectory adds the upload of each regular file of a local directory
+// tree to m. It returns an error if the directory cannot be walked; the
+// errors of the uploads are reported by WaitAndClose.
+func (m *Manager) UploadDirectory(ctx context.Context, in *UploadDirectoryInput) error {
+	return filepath.Walk(in.LocalDir, func(p string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if !info.Mode().IsRegular() {
+			return nil
+		}
+		rel, err := filepath.Rel(in.LocalDir, p)
+		if err != nil {
+			return err
+		}
+		return m.UploadObject(ctx, &UploadObjectInput{
+			Path:   p,
+			Bucket: in.Bucket,
+			Object: in.Prefix + filepath.ToSlash(rel),
+		})
+	})
+}
+
+// DownloadDirectory adds the download of each object with a prefix to m. It
+// returns an error if the objects cannot be listed; the errors of the
+// downloads are reported by WaitAndClose.
+func (m *Manager) DownloadDirectory(ctx context.Context, in *DownloadDirectoryInput) error {
+	it := m.client.Bucket(in.Bucket).Objects(ctx, &storage.Query{Prefix: in.Prefix})
+	for {
+		attrs, err := it.Next()
+		if err == iterator.Done {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		name := strings.TrimPrefix(attrs.Name, in.Prefix)
+		if name == "" || strings.HasSuffix(name, "/") {
+			continue
+		}
+		t := &transfer{ctx: ctx, download: &DownloadObjectInput{Bucket: in.Bucket, Object: attrs.Name}}
+		// Object names are slash-separated and may contain "..", which must
+		// not escape the local directory.
+		if rel := path.Clean("/" + name)[1:]; rel != name {
+			t.err = fmt.Errorf("transfermanager: object name %q is not a valid relative path", name)
+		} else {
+			t.download.Path = filepath.Join(in.LocalDir, filepath.FromSlash(rel))
+		}
+		if err := m.add(t); err != nil {
+			return err
+		}
+	}
+}
+
+// WaitAndClose waits for all transfers of m to finish, stops the workers and
+// returns a summary of the results. It returns an error if any transfer
+// failed. No transfers can be added to m once WaitAndClose has been called.
+func (m *Manager) WaitAndClose() (*Summary, error) {
+	m.addMu.Lock()
+	if m.closed {
+		m.addMu.Unlock()
+		return nil, errClosed
+	}
+	m.closed = true
+	close(m.work)
+	m.addMu.Unlock()
+	m.workers.Wait()
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	s := &Summary{Results: m.results, Duration: time.Since(m.start)}
+	for _, r := range m.results {
+		if r.Err != nil {
+			s.Failed++
+			continue
+		}
+		s.Succeeded++
+		s.Bytes += r.Bytes
+	}
+	if s.Failed > 0 {
+		return s, fmt.Errorf("transfermanager: %d of %d transfers failed", s.Failed, len(s.Results))
+	}
+	return s, nil
+}
+
+func (m *Manager) add(t *transfer) error {
+	m.addMu.RLock()
+	defer m.addMu.RUnlock()
+	if m.closed {
+		return errClosed
+	}
+	select {
+	case m.work <- t:
+		return nil
+	case <-t.ctx.Done():
+		return t.ctx.Err()
+	}
+}
+
+func (m *Manager) worker() {
+	defer m.workers.Done()
+	for t := range m.work {
+		m.finish(m.run(t))
+	}
+}
+
+// finish records the result of a transfer.
+func (m *Manager) finish(r Result) {
+	m.mu.Lock()
+	m.results = append(m.results, r)
+	m.mu.Unlock()
+	if m.config.progress != nil {
+		m.config.progress(r)
+	}
+}
+
+// run runs the attempts of t.
+func (m *Manager) run(t *transfer) Result {
+	var r Result
+	if t.upload != nil {
+		r = Result{Upload: true, Bucket: t.upload.Bucket, Object: t.upload.Object, Path: t.upload.Path}
+	} else {
+		r = Result{Bucket: t.download.Bucket, Object: t.download.Object, Path: t.download.Path}
+	}
+	if t.err != nil {
+		r.Err = t.err
+		return r
+	}
+	bo := m.config.backoff
+	for {
+		r.Attempts++
+		r.Bytes, r.Err = m.attempt(t)
+		if r.Err == nil || r.Attempts >= m.config.maxAttempts || !storage.ShouldRetry(r.Err) {
+			return r
+		}
+		if err := gax.Sleep(t.ctx, bo.Pause()); err != nil {
+			return r
+		}
+	}
+}
+
+// attempt runs one attempt of t.
+func (m *Manager) attempt(t *transfer) (int64, error) {
+	ctx := t.ctx
+	if m.config.perOpTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, m.config.perOpTimeout)
+		defer cancel()
+	}
+	if t.upload != nil {
+		return m.upload(ctx, t.upload)
+	}
+	return m.download(ctx, t.download)
+}
+
+func (m *Manager) upload(ctx context.Context, in *UploadObjectInput) (int64, error) {
+	f, err := os.Open(in.Path)
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+
+	o := m.client.Bucket(in.Bucket).Object(in.Object)
+	if in.Conditions != nil {
+		o = o.If(*in.Conditions)
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	w := o.NewWriter(ctx)
+	w.ContentType = in.ContentType
+	n, err := io.Copy(w, f)
+	if err != nil {
+		// Canceling the context aborts the upload.
+		cancel()
+		w.Close()
+		return n, err
+	}
+	return n, w.Close()
+}
+
+func (m *Manager) download(ctx context.Context, in *DownloadObjectInput) (n int64, err error) {
+	r, err := m.client.Bucket(in.Bucket).Object(in.Object).NewReader(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer r.Close()
+
+	if err := os.MkdirAll(filepath.Dir(in.Path), 0755); err != nil {
+		return 0, err
+	}
+	f, err := os.Create(in.Path)
+	if err != nil {
+		return 0, err
+	}
+	defer func() {
+		if cerr := f.Close(); err == nil {
+			err = cerr
+		}
+		// Don't leave partial files behind.
+		if err != nil {
+			os.Remove(in.Path)
+		}
+	}()
+	return io.Copy(f, r)
+}
@@ -0,0 +1,252 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package transfermanager
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"hash/crc32"
+	"io/ioutil"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"cloud.google.com/go/storage"
+	"github.com/googleapis/gax-go/v2"
+	"google.golang.org/api/option"
+)
+
+// fakeServer is a minimal in-memory implementation of the parts of the JSON
+// API that the Manager uses.
+type fakeServer struct {
+	mu      sync.Mutex
+	objects map[string][]byte // keyed by bucket/object
+	// failUploads is the number of uploads of each object that fail with a
+	// transient error before uploads succeed.
+	failUploads map[string]int
+	// reads is the number of reads of object data.
+	reads int
+}
+
+func (s *fakeServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	switch {
+	case r.Method == http.MethodPost && strings.HasPrefix(r.URL.Path, "/upload/storage/v1/b/"):
+		bucket := strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, "/upload/storage/v1/b/"), "/o")
+		_, params, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		mr := multipart.NewReader(r.Body, params["boundary"])
+		var meta struct{ Name string }
+		part, err := mr.NextPart()
+		if err == nil {
+			err = json.NewDecoder(part).Decode(&meta)
+		}
+		if err == nil {
+			part, err = mr.NextPart()
+		}
+		var data []byte
+		if err == nil {
+			data, err = ioutil.ReadAll(part)
+		}
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		key := bucket + "/" + meta.Name
+		if s.failUploads[key] > 0 {
+			s.failUploads[key]--
+			http.Error(w, "try again", http.StatusServiceUnavailable)
+			return
+		}
+		s.objects[key] = data
+		fmt.Fprintf(w, `{"bucket": %q, "name": %q, "size": "%d"}`, bucket, meta.Name, len(data))
+	case r.Method == http.MethodGet && strings.HasPrefix(r.URL.Path, "/storage/v1/b/") && strings.Contains(r.URL.Path, "/o/"):
+		parts := strings.SplitN(strings.TrimPrefix(r.URL.Path, "/storage/v1/b/"), "/o/", 2)
+		data, ok := s.objects[parts[0]+"/"+parts[1]]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		var crc [4]byte
+		binary.BigEndian.PutUint32(crc[:], crc32.Checksum(data, crc32.MakeTable(crc32.Castagnoli)))
+		fmt.Fprintf(w, `{"bucket": %q, "name": %q, "size": "%d", "generation": "1", "crc32c": %q}`,
+			parts[0], parts[1], len(data), base64.StdEncoding.EncodeToString(crc[:]))
+	case r.Method == http.MethodGet && strings.HasPrefix(r.URL.Path, "/storage/v1/b/"):
+		bucket := strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, "/storage/v1/b/"), "/o")
+		prefix := r.URL.Query().Get("prefix")
+		var items []string
+		for key := range s.objects {
+			if name := strings.TrimPrefix(key, bucket+"/"); name != key && strings.HasPrefix(name, prefix) {
+				items = append(items, fmt.Sprintf(`{"bucket": %q, "name": %q}`, bucket, name))
+			}
+		}
+		sort.Strings(items)
+		fmt.Fprintf(w, `{"items": [%s]}`, strings.Join(items, ","))
+	case r.Method == http.MethodGet:
+		data, ok := s.objects[strings.TrimPrefix(r.URL.Path, "/")]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		s.reads++
+		http.ServeContent(w, r, "", time.Time{}, bytes.NewReader(data))
+	default:
+		http.Error(w, "unexpected request", http.StatusBadRequest)
+	}
+}
+
+func newTestClient(t *testing.T, s *fakeServer) *storage.Client {
+	srv := httptest.NewServer(s)
+	t.Cleanup(srv.Close)
+	client, err := storage.NewClient(context.Background(), option.WithEndpoint(srv.URL+"/storage/v1/"), option.WithoutAuthentication())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return client
+}
+
+func writeFiles(t *testing.T, dir string, files map[string]string) {
+	for name, content := range files {
+		p := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(p, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestUploadAndDownloadDirectory(t *testing.T) {
+	s := &fakeServer{objects: map[string][]byte{}, failUploads: map[string]int{"bucket/up/b/c.txt": 1}}
+	client := newTestClient(t, s)
+	ctx := context.Background()
+
+	files := map[string]string{"a.txt": "alpha", "b/c.txt": "gamma", "b/d/e.txt": "epsilon"}
+	src := t.TempDir()
+	writeFiles(t, src, files)
+
+	var mu sync.Mutex
+	var progress []string
+	m, err := NewManager(client,
+		WithWorkers(2),
+		WithBackoff(gax.Backoff{Initial: time.Millisecond}),
+		WithProgress(func(r Result) {
+			mu.Lock()
+			defer mu.Unlock()
+			progress = append(progress, r.Object)
+		}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := m.UploadDirectory(ctx, &UploadDirectoryInput{LocalDir: src, Bucket: "bucket", Prefix: "up/"}); err != nil {
+		t.Fatal(err)
+	}
+	summary, err := m.WaitAndClose()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if summary.Succeeded != 3 || summary.Failed != 0 || summary.Bytes != int64(len("alphagammaepsilon")) {
+		t.Errorf("got summary %+v, want 3 succeeded transfers of 17 bytes", summary)
+	}
+	for _, r := range summary.Results {
+		want := 1
+		if r.Object == "up/b/c.txt" {
+			// The first upload failed with a transient error.
+			want = 2
+		}
+		if !r.Upload || r.Attempts != want {
+			t.Errorf("%s: got upload=%v after %d attempts, want an upload after %d", r.Object, r.Upload, r.Attempts, want)
+		}
+	}
+	if len(progress) != 3 {
+		t.Errorf("got progress for %v, want 3 transfers", progress)
+	}
+	for name, content := range files {
+		if got := string(s.objects["bucket/up/"+name]); got != content {
+			t.Errorf("object up/%s: got %q, want %q", name, got, content)
+		}
+	}
+
+	// Download the objects again, including one whose name would escape the
+	// local directory.
+	s.objects["bucket/up/../escape.txt"] = []byte("escape")
+	dst := t.TempDir()
+	m, err = NewManager(client)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := m.DownloadDirectory(ctx, &DownloadDirectoryInput{Bucket: "bucket", Prefix: "up/", LocalDir: dst}); err != nil {
+		t.Fatal(err)
+	}
+	summary, err = m.WaitAndClose()
+	if err == nil {
+		t.Fatal("got nil error, want an error for the escaping object")
+	}
+	if summary.Succeeded != 3 || summary.Failed != 1 {
+		t.Errorf("got %d succeeded and %d failed transfers, want 3 and 1", summary.Succeeded, summary.Failed)
+	}
+	for name, content := range files {
+		got, err := ioutil.ReadFile(filepath.Join(dst, filepath.FromSlash(name)))
+		if err != nil || string(got) != content {
+			t.Errorf("file %s: got (%q, %v), want %q", name, got, err, content)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(filepath.Dir(dst), "escape.txt")); !os.IsNotExist(err) {
+		t.Errorf("escaping object was written outside the local directory: %v", err)
+	}
+
+	if err := m.DownloadObject(ctx, &DownloadObjectInput{Bucket: "bucket", Object: "up/a.txt", Path: filepath.Join(dst, "x")}); err != errClosed {
+		t.Errorf("DownloadObject after WaitAndClose: got %v, want %v", err, errClosed)
+	}
+}
+
+func TestDownloadObjectNotFound(t *testing.T) {
+	client := newTestClient(t, &fakeServer{objects: map[string][]byte{}})
+	m, err := NewManager(client)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := filepath.Join(t.TempDir(), "missing")
+	if err := m.DownloadObject(context.Background(), &DownloadObjectInput{Bucket: "bucket", Object: "missing", Path: p}); err != nil {
+		t.Fatal(err)
+	}
+	summary, err := m.WaitAndClose()
+	if err == nil || len(summary.Results) != 1 || summary.Results[0].Err != storage.ErrObjectNotExist {
+		t.Fatalf("got (%+v, %v), want a failed transfer with ErrObjectNotExist", summary, err)
+	}
+	if summary.Results[0].Attempts != 1 {
+		t.Errorf("got %d attempts, want 1", summary.Results[0].Attempts)
+	}
+	if _, err := os.Stat(p); !os.IsNotExist(err) {
+		t.Errorf("got file for missing object: %v", err)
+	}
+}
@@ -0,0 +1,109 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package transfermanager
+
+import (
+	"time"
+
+	"github.com/googleapis/gax-go/v2"
+)
+
+// An Option configures a Manager.
+type Option interface {
+	apply(*config)
+}
+
+// config holds the settings of a Manager.
+type config struct {
+	// workers is the number of transfers that run at the same time.
+	workers int
+
+	// perOpTimeout is the timeout of one attempt of a transfer. Zero means no
+	// timeout.
+	perOpTimeout time.Duration
+
+	// maxAttempts is the maximum number of attempts of one transfer.
+	maxAttempts int
+
+	// backoff is the backoff between the attempts of a transfer.
+	backoff gax.Backoff
+
+	// progress is called after each transfer.
+	progress func(Result)
+}
+
+func defaultConfig() *config {
+	return &config{
+		workers:     16,
+		maxAttempts: 3,
+		backoff: gax.Backoff{
+			Initial:    time.Second,
+			Max:        30 * time.Second,
+			Multiplier: 2,
+		},
+	}
+}
+
+type withWorkers int
+
+func (w withWorkers) apply(c *config) { c.workers = int(w) }
+
+// WithWorkers sets the number of transfers that run at the same time. The
+// default is 16.
+func WithWorkers(numWorkers int) Option {
+	return withWorkers(numWorkers)
+}
+
+type withPerOpTimeout time.Duration
+
+func (w withPerOpTimeout) apply(c *config) { c.perOpTimeout = time.Duration(w) }
+
+// WithPerOpTimeout sets a timeout for each attempt of a transfer. By default,
+// attempts are only bound by the context of the transfer.
+func WithPerOpTimeout(timeout time.Duration) Option {
+	return withPerOpTimeout(timeout)
+}
+
+type withMaxAttempts int
+
+func (w withMaxAttempts) apply(c *config) { c.maxAttempts = int(w) }
+
+// WithMaxAttempts sets the maximum number of attempts of a transfer that
+// fails with a transient error, as reported by storage.ShouldRetry. The
+// default is 3.
+func WithMaxAttempts(attempts int) Option {
+	return withMaxAttempts(attempts)
+}
+
+type withBackoff gax.Backoff
+
+func (w withBackoff) apply(c *config) { c.backoff = gax.Backoff(w) }
+
+// WithBackoff sets the backoff between the attempts of a transfer. The
+// default starts at one second and doubles up to 30 seconds.
+func WithBackoff(backoff gax.Backoff) Option {
+	return withBackoff(backoff)
+}
+
+type withProgress func(Result)
+
+func (w withProgress) apply(c *config) { c.progress = w }
+
+// WithProgress sets a function that is called with the result of each
+// transfer when it is done. The function is called from the goroutines of the
+// workers, possibly concurrently, and should return quickly.
+func WithProgress(f func(Result)) Option {
+	return withProgress(f)
+}
@@ -0,0 +1,238 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package transfermanager
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"io/ioutil"
+	"os"
+	"sync"
+
+	"cloud.google.com/go/storage"
+)
+
+const (
+	// defaultSliceSize is the default size of the slices of a sliced
+	// download.
+	defaultSliceSize = 32 << 20
+
+	// defaultSliceWorkers is the default number of slices that a sliced
+	// download reads at the same time.
+	defaultSliceWorkers = 8
+
+	// checkpointSuffix is appended to the path of the file of a sliced
+	// download to form the path of its checkpoint.
+	checkpointSuffix = ".slices"
+)
+
+var crc32cTable = crc32.MakeTable(crc32.Castagnoli)
+
+// SlicedDownloadInput describes the download of one object in slices that
+// are read concurrently.
+type SlicedDownloadInput struct {
+	// Bucket and Object are the names of the bucket and the object.
+	Bucket, Object string
+
+	// Path is the path of the local file.
+	Path string
+
+	// SliceSize is the size in bytes of each slice. If zero, 32 MiB is used.
+	SliceSize int64
+
+	// Workers is the number of slices that are read at the same time. If
+	// zero, 8 is used.
+	Workers int
+}
+
+// sliceCheckpoint records the slices of a sliced download that have been
+// written to the local file, so that an interrupted download can be resumed.
+type sliceCheckpoint struct {
+	Generation int64  `json:"generation"`
+	Size       int64  `json:"size"`
+	SliceSize  int64  `json:"sliceSize"`
+	Done       []bool `json:"done"`
+}
+
+// DownloadSliced downloads an object to a local file by reading slices of the
+// object concurrently with range reads, which is faster than a single read for
+// large objects. The slices are written to their place in the file, and the
+// CRC32C checksum of the file is verified against the object when all slices
+// are done.
+//
+// While the download is in progress, the slices that are done are recorded in
+// a checkpoint file next to the local file, whose name has the suffix
+// ".slices". If the download is interrupted, calling DownloadSliced again with
+// the same input only reads the slices that are missing, as long as the
+// object has not been changed. The checkpoint is removed when the download
+// succeeds.
+//
+// DownloadSliced returns the number of bytes that it read from the object.
+// Objects that are stored with Content-Encoding gzip can't be read in slices.
+func DownloadSliced(ctx context.Context, client *storage.Client, in *SlicedDownloadInput) (int64, error) {
+	sliceSize := in.SliceSize
+	if sliceSize <= 0 {
+		sliceSize = defaultSliceSize
+	}
+	workers := in.Workers
+	if workers <= 0 {
+		workers = defaultSliceWorkers
+	}
+	o := client.Bucket(in.Bucket).Object(in.Object)
+	attrs, err := o.Attrs(ctx)
+	if err != nil {
+		return 0, err
+	}
+	if attrs.ContentEncoding == "gzip" {
+		return 0, fmt.Errorf("transfermanager: object %q has Content-Encoding gzip and can't be downloaded in slices", in.Object)
+	}
+	// Read all slices from the same generation of the object.
+	o = o.Generation(attrs.Generation)
+
+	cp := readCheckpoint(in.Path + checkpointSuffix)
+	if cp == nil || cp.Generation != attrs.Generation || cp.Size != attrs.Size || cp.SliceSize != sliceSize {
+		cp = &sliceCheckpoint{
+			Generation: attrs.Generation,
+			Size:       attrs.Size,
+			SliceSize:  sliceSize,
+			Done:       make([]bool, (attrs.Size+sliceSize-1)/sliceSize),
+		}
+	}
+	f, err := os.OpenFile(in.Path, os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+	if err := f.Truncate(attrs.Size); err != nil {
+		return 0, err
+	}
+
+	var (
+		mu       sync.Mutex
+		firstErr error
+		read     int64
+		wg       sync.WaitGroup
+	)
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	slices := make(chan int)
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for slice := range slices {
+				off := int64(slice) * sliceSize
+				length := sliceSize
+				if off+length > attrs.Size {
+					length = attrs.Size - off
+				}
+				n, err := readSlice(ctx, o, f, off, length)
+				mu.Lock()
+				read += n
+				if err == nil {
+					cp.Done[slice] = true
+					err = writeCheckpoint(in.Path+checkpointSuffix, cp)
+				}
+				if err != nil && firstErr == nil {
+					firstErr = err
+					cancel()
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+	for i, done := range cp.Done {
+		if done {
+			continue
+		}
+		select {
+		case slices <- i:
+		case <-ctx.Done():
+		}
+	}
+	close(slices)
+	wg.Wait()
+	if firstErr != nil {
+		return read, firstErr
+	}
+	if err := ctx.Err(); err != nil {
+		return read, err
+	}
+
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		return read, err
+	}
+	h := crc32.New(crc32cTable)
+	if _, err := io.Copy(h, f); err != nil {
+		return read, err
+	}
+	if got := h.Sum32(); got != attrs.CRC32C {
+		// Start over on the next attempt.
+		os.Remove(in.Path + checkpointSuffix)
+		return read, fmt.Errorf("transfermanager: CRC32C of %q is %d, want %d", in.Path, got, attrs.CRC32C)
+	}
+	return read, os.Remove(in.Path + checkpointSuffix)
+}
+
+// readSlice copies length bytes at offset off of o to the same offset of f.
+func readSlice(ctx context.Context, o *storage.ObjectHandle, f *os.File, off, length int64) (int64, error) {
+	r, err := o.NewRangeReader(ctx, off, length)
+	if err != nil {
+		return 0, err
+	}
+	defer r.Close()
+	return io.Copy(&offsetWriter{f: f, off: off}, r)
+}
+
+// offsetWriter writes to f sequentially from an offset.
+type offsetWriter struct {
+	f   *os.File
+	off int64
+}
+
+func (w *offsetWriter) Write(p []byte) (int, error) {
+	n, err := w.f.WriteAt(p, w.off)
+	w.off += int64(n)
+	return n, err
+}
+
+func readCheckpoint(path string) *sliceCheckpoint {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil
+	}
+	var cp sliceCheckpoint
+	if err := json.Unmarshal(b, &cp); err != nil || cp.SliceSize <= 0 || int64(len(cp.Done)) != (cp.Size+cp.SliceSize-1)/cp.SliceSize {
+		return nil
+	}
+	return &cp
+}
+
+// writeCheckpoint writes cp to path. It replaces the file atomically, so that
+// the checkpoint is never left half written.
+func writeCheckpoint(path string, cp *sliceCheckpoint) error {
+	b, err := json.Marshal(cp)
+	if err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	if err := ioutil.WriteFile(tmp, b, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
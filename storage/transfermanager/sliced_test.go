@@ -0,0 +1,85 @@
+// Copyright 2022 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package transfermanager
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestDownloadSliced(t *testing.T) {
+	data := bytes.Repeat([]byte("0123456789"), 10)
+	s := &fakeServer{objects: map[string][]byte{"bucket/big": data}}
+	client := newTestClient(t, s)
+	ctx := context.Background()
+	p := filepath.Join(t.TempDir(), "big")
+	in := &SlicedDownloadInput{Bucket: "bucket", Object: "big", Path: p, SliceSize: 30, Workers: 3}
+
+	n, err := DownloadSliced(ctx, client, in)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != int64(len(data)) || s.reads != 4 {
+		t.Errorf("got %d bytes in %d reads, want %d bytes in 4 reads", n, s.reads, len(data))
+	}
+	checkFile := func() {
+		t.Helper()
+		if got, err := ioutil.ReadFile(p); err != nil || !bytes.Equal(got, data) {
+			t.Errorf("got (%q, %v), want %q", got, err, data)
+		}
+		if _, err := os.Stat(p + checkpointSuffix); !os.IsNotExist(err) {
+			t.Errorf("checkpoint was not removed: %v", err)
+		}
+	}
+	checkFile()
+
+	// Resume a download of which the first two slices are done.
+	if err := ioutil.WriteFile(p, append(data[:60:60], make([]byte, 40)...), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := writeCheckpoint(p+checkpointSuffix, &sliceCheckpoint{Generation: 1, Size: 100, SliceSize: 30, Done: []bool{true, true, false, false}}); err != nil {
+		t.Fatal(err)
+	}
+	s.reads = 0
+	n, err = DownloadSliced(ctx, client, in)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 40 || s.reads != 2 {
+		t.Errorf("resumed: got %d bytes in %d reads, want 40 bytes in 2 reads", n, s.reads)
+	}
+	checkFile()
+
+	// A slice that is recorded as done but was not written correctly fails
+	// the checksum, and the next download starts over.
+	if err := ioutil.WriteFile(p, make([]byte, 100), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := writeCheckpoint(p+checkpointSuffix, &sliceCheckpoint{Generation: 1, Size: 100, SliceSize: 30, Done: []bool{true, false, false, false}}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := DownloadSliced(ctx, client, in); err == nil || !strings.Contains(err.Error(), "CRC32C") {
+		t.Fatalf("got %v, want a checksum error", err)
+	}
+	if _, err := DownloadSliced(ctx, client, in); err != nil {
+		t.Fatal(err)
+	}
+	checkFile()
+}
@@ -19,6 +19,8 @@ import (
 	"encoding/base64"
 	"errors"
 	"fmt"
+	"hash"
+	"hash/crc32"
 	"io"
 	"sync"
 	"time"
@@ -109,6 +111,57 @@ type Writer struct {
 	// ProgressFunc should return quickly without blocking.
 	ProgressFunc func(int64)
 
+	// CheckpointFunc, if not nil, makes the Writer upload the object with a
+	// resumable upload session that it manages itself, so that the upload can
+	// be resumed with ObjectHandle.ResumeWriter after the process restarts.
+	// CheckpointFunc is called with the URI of the session and the number of
+	// bytes that the service has persisted: once when the session has been
+	// created, and after each chunk. Save these values somewhere that survives
+	// a restart. The session URI authorizes the upload, so treat it like a
+	// credential.
+	//
+	// CheckpointFunc requires a positive ChunkSize and is not supported by the
+	// gRPC API. It must be set before the first Write call, and should return
+	// quickly without blocking.
+	CheckpointFunc func(sessionURI string, offset int64)
+
+	// OnProgress, if not nil, is called whenever ProgressFunc would be, with
+	// the number of bytes uploaded so far and the average upload rate since
+	// the first Write call. OnProgress should return quickly without
+	// blocking.
+	OnProgress func(Progress)
+
+	// BandwidthLimiter, if not nil, caps the rate at which data is written to
+	// w. Since data is uploaded in chunks of ChunkSize, a smaller ChunkSize
+	// makes the rate on the network smoother. BandwidthLimiter must be set
+	// before the first Write call.
+	BandwidthLimiter *BandwidthLimiter
+
+	// VerifyChecksums turns on an end-to-end integrity check of the upload.
+	// The Writer computes the CRC32C checksum of the data written to it, and
+	// Close compares it with the CRC32C that the service reports for the
+	// finished object, returning a *ChecksumMismatchError if they differ. If
+	// the service doesn't report a CRC32C, nothing is compared.
+	// VerifyChecksums must be set before the first call to Write or Close.
+	//
+	// Writers returned by ResumeWriter, and Writers whose upload resumes
+	// after an interruption that restarted it from a non-zero offset, skip
+	// the check, since they don't see all the data of the object.
+	//
+	// The check is opt-in because hashing every upload costs CPU time that
+	// most callers don't need: the transport already checksums the data it
+	// sends, and callers that know the checksum of their data ahead of time
+	// can have the service verify it with SendCRC32C or ObjectAttrs.MD5.
+	// Only CRC32C is compared, since it is much cheaper to compute than MD5
+	// and catches the same corruption.
+	VerifyChecksums bool
+
+	// DeleteOnChecksumMismatch makes Close delete the object if the check
+	// enabled by VerifyChecksums fails, so that the corrupt data doesn't
+	// remain in the bucket. Only the generation that the Writer created is
+	// deleted.
+	DeleteOnChecksumMismatch bool
+
 	ctx context.Context
 	o   *ObjectHandle
 
@@ -120,6 +173,52 @@ type Writer struct {
 
 	mu  sync.Mutex
 	err error
+
+	// sessionURI and offset are the URI of the resumable upload session and
+	// the number of bytes that the service has persisted, for Writers that
+	// manage their session. See SessionURI.
+	sessionURI string
+	offset     int64
+
+	// start and startOffset are the time of the first Write call and the
+	// offset of the upload at that time, for OnProgress.
+	start       time.Time
+	startOffset int64
+
+	// crc32c is the checksum of the data written to w, if VerifyChecksums
+	// is set, and crc32cStored reports whether the service reported the
+	// CRC32C of the object.
+	crc32c       hash.Hash32
+	crc32cStored bool
+}
+
+// ChecksumMismatchError is returned by Writer.Close when a checksum of the
+// data written to the Writer doesn't match the checksum that the service
+// reports for the object it stored.
+type ChecksumMismatchError struct {
+	Bucket     string
+	Object     string
+	Generation int64
+
+	// Checksum is the name of the checksum that didn't match, "crc32c".
+	Checksum string
+
+	// Written and Stored are the base64-encoded checksums of the data written
+	// to the Writer and of the stored object.
+	Written, Stored string
+
+	// Deleted reports whether the object was deleted because of
+	// Writer.DeleteOnChecksumMismatch.
+	Deleted bool
+}
+
+func (e *ChecksumMismatchError) Error() string {
+	msg := fmt.Sprintf("storage: %s mismatch for object %q in bucket %q: wrote %s, stored %s",
+		e.Checksum, e.Object, e.Bucket, e.Written, e.Stored)
+	if e.Deleted {
+		msg += " (object deleted)"
+	}
+	return msg
 }
 
 func (w *Writer) open() error {
@@ -160,8 +259,8 @@ func (w *Writer) open() error {
 			Context(w.ctx).
 			Name(w.o.object)
 
-		if w.ProgressFunc != nil {
-			call.ProgressUpdater(func(n, _ int64) { w.ProgressFunc(n) })
+		if w.ProgressFunc != nil || w.OnProgress != nil {
+			call.ProgressUpdater(func(n, _ int64) { w.progress(n) })
 		}
 		if attrs.KMSKeyName != "" {
 			call.KmsKeyName(attrs.KMSKeyName)
@@ -214,6 +313,7 @@ func (w *Writer) open() error {
 			return
 		}
 		w.obj = newObject(resp)
+		w.crc32cStored = resp.Crc32c != ""
 	}()
 	return nil
 }
@@ -235,8 +335,13 @@ func (w *Writer) Write(p []byte) (n int, err error) {
 		return 0, werr
 	}
 	if !w.opened {
-		// gRPC client has been initialized - use gRPC to upload.
-		if w.o.c.tc != nil {
+		w.begin()
+		if w.managesSession() {
+			if err := w.openSession(); err != nil {
+				return 0, err
+			}
+		} else if w.o.c.tc != nil {
+			// gRPC client has been initialized - use gRPC to upload.
 			if err := w.openWriter(); err != nil {
 				return 0, err
 			}
@@ -244,7 +349,10 @@ func (w *Writer) Write(p []byte) (n int, err error) {
 			return 0, err
 		}
 	}
-	n, err = w.pw.Write(p)
+	n, err = w.write(p)
+	if w.crc32c != nil {
+		w.crc32c.Write(p[:n])
+	}
 	if err != nil {
 		w.mu.Lock()
 		werr := w.err
@@ -259,12 +367,40 @@ func (w *Writer) Write(p []byte) (n int, err error) {
 	return n, err
 }
 
+// write writes p to the pipe, at the rate that w.BandwidthLimiter allows.
+func (w *Writer) write(p []byte) (n int, err error) {
+	if w.BandwidthLimiter == nil {
+		return w.pw.Write(p)
+	}
+	for len(p) > 0 {
+		piece := p
+		if len(piece) > limiterPieceSize {
+			piece = piece[:limiterPieceSize]
+		}
+		if err := w.BandwidthLimiter.wait(w.ctx, len(piece)); err != nil {
+			return n, err
+		}
+		m, err := w.pw.Write(piece)
+		n += m
+		if err != nil {
+			return n, err
+		}
+		p = p[m:]
+	}
+	return n, nil
+}
+
 // Close completes the write operation and flushes any buffered data.
 // If Close doesn't return an error, metadata about the written object
 // can be retrieved by calling Attrs.
 func (w *Writer) Close() error {
 	if !w.opened {
-		if w.o.c.tc != nil {
+		w.begin()
+		if w.managesSession() {
+			if err := w.openSession(); err != nil {
+				return err
+			}
+		} else if w.o.c.tc != nil {
 			if err := w.openWriter(); err != nil {
 				return err
 			}
@@ -280,8 +416,53 @@ func (w *Writer) Close() error {
 
 	<-w.donec
 	w.mu.Lock()
-	defer w.mu.Unlock()
-	return w.err
+	err := w.err
+	w.mu.Unlock()
+	if err != nil {
+		return err
+	}
+	if err := w.verifyChecksums(); err != nil {
+		w.error(err)
+		return err
+	}
+	return nil
+}
+
+// begin records the start of the upload, for OnProgress and the integrity
+// check.
+func (w *Writer) begin() {
+	w.start, w.startOffset = time.Now(), w.offset
+	if w.VerifyChecksums {
+		w.crc32c = crc32.New(crc32cTable)
+	}
+}
+
+// verifyChecksums compares the checksum of the data written to w with that of
+// the object that the service stored, if w.VerifyChecksums is set, and deletes
+// the object on a mismatch if w.DeleteOnChecksumMismatch is set.
+func (w *Writer) verifyChecksums() error {
+	if w.crc32c == nil || w.startOffset != 0 || w.obj == nil || !w.crc32cStored {
+		return nil
+	}
+	sum := w.crc32c.Sum32()
+	if sum == w.obj.CRC32C {
+		return nil
+	}
+	mismatch := &ChecksumMismatchError{
+		Bucket:     w.obj.Bucket,
+		Object:     w.obj.Name,
+		Generation: w.obj.Generation,
+		Checksum:   "crc32c",
+		Written:    encodeUint32(sum),
+		Stored:     encodeUint32(w.obj.CRC32C),
+	}
+	if w.DeleteOnChecksumMismatch {
+		if err := w.o.Generation(w.obj.Generation).Delete(w.ctx); err != nil {
+			return fmt.Errorf("storage: deleting object after checksum mismatch: %v: %w", err, mismatch)
+		}
+		mismatch.Deleted = true
+	}
+	return mismatch
 }
 
 func (w *Writer) openWriter() (err error) {
@@ -302,7 +483,7 @@ func (w *Writer) openWriter() (err error) {
 		donec:              w.donec,
 		setError:           w.error,
 		progress:           w.progress,
-		setObj:             func(o *ObjectAttrs) { w.obj = o },
+		setObj:             func(o *ObjectAttrs) { w.obj, w.crc32cStored = o, true },
 	}
 	w.pw, err = w.o.c.tc.OpenWriter(params)
 	if err != nil {
@@ -367,11 +548,19 @@ func (w *Writer) validateWriteAttrs() error {
 }
 
 // progress is a convenience wrapper that reports write progress to the Writer
-// ProgressFunc if it is set and progress is non-zero.
+// ProgressFunc and OnProgress if they are set and progress is non-zero.
 func (w *Writer) progress(p int64) {
-	if w.ProgressFunc != nil && p != 0 {
+	if p == 0 {
+		return
+	}
+	if w.ProgressFunc != nil {
 		w.ProgressFunc(p)
 	}
+	if w.OnProgress != nil {
+		pr := newProgress(p-w.startOffset, w.start)
+		pr.Bytes = p
+		w.OnProgress(pr)
+	}
 }
 
 // error acquires the Writer's lock, sets the Writer's err to the given error,
@@ -15,14 +15,24 @@
 package storage
 
 import (
+	"bytes"
 	"context"
+	"crypto/md5"
 	"crypto/sha256"
 	"encoding/base64"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io/ioutil"
 	"net/http"
+	"net/http/httptest"
 	"strings"
+	"sync"
 	"testing"
+	"time"
 
 	"cloud.google.com/go/internal/testutil"
+	gax "github.com/googleapis/gax-go/v2"
 	"google.golang.org/api/googleapi"
 	"google.golang.org/api/option"
 )
@@ -175,3 +185,205 @@ func TestCloseDoesNotLeak(t *testing.T) {
 
 	wc.Close()
 }
+
+// resumableServer is a minimal implementation of resumable upload sessions.
+type resumableServer struct {
+	mu   sync.Mutex
+	url  string
+	data []byte
+	done bool
+	// failPuts is the number of uploads of data that fail with a transient
+	// error.
+	failPuts int
+}
+
+func (s *resumableServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if r.Method == "POST" {
+		if got, want := r.URL.Query().Get("uploadType"), "resumable"; got != want {
+			http.Error(w, "uploadType is "+got, http.StatusBadRequest)
+			return
+		}
+		w.Header().Set("Location", s.url+"/session")
+		return
+	}
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	var first, last int64
+	var size string
+	if len(body) > 0 {
+		if s.failPuts > 0 {
+			s.failPuts--
+			http.Error(w, "try again", http.StatusServiceUnavailable)
+			return
+		}
+		fmt.Sscanf(r.Header.Get("Content-Range"), "bytes %d-%d/%s", &first, &last, &size)
+		if first != int64(len(s.data)) {
+			http.Error(w, "unexpected offset", http.StatusBadRequest)
+			return
+		}
+		s.data = append(s.data, body...)
+	} else {
+		fmt.Sscanf(r.Header.Get("Content-Range"), "bytes */%s", &size)
+	}
+	if size != "*" && size != "" {
+		s.done = true
+	}
+	if s.done {
+		fmt.Fprintf(w, `{"name": "obj", "size": "%d"}`, len(s.data))
+		return
+	}
+	if len(s.data) > 0 {
+		w.Header().Set("Range", fmt.Sprintf("bytes=0-%d", len(s.data)-1))
+	}
+	w.WriteHeader(http.StatusPermanentRedirect)
+}
+
+func TestResumeWriter(t *testing.T) {
+	s := &resumableServer{failPuts: 1}
+	srv := httptest.NewServer(s)
+	defer srv.Close()
+	s.url = srv.URL
+	client, err := NewClient(context.Background(), option.WithEndpoint(srv.URL+"/storage/v1/"), option.WithoutAuthentication())
+	if err != nil {
+		t.Fatal(err)
+	}
+	obj := client.Bucket("bucket").Object("obj").Retryer(WithBackoff(gax.Backoff{Initial: time.Millisecond}))
+	data := bytes.Repeat([]byte("x"), 3*googleapi.MinUploadChunkSize+100)
+
+	// Upload the first chunk and then abort the upload.
+	ctx, cancel := context.WithCancel(context.Background())
+	w := obj.NewWriter(ctx)
+	w.ChunkSize = googleapi.MinUploadChunkSize
+	checkpoints := make(chan int64, 10)
+	w.CheckpointFunc = func(uri string, off int64) {
+		if want := srv.URL + "/session"; uri != want {
+			t.Errorf("got session URI %q, want %q", uri, want)
+		}
+		checkpoints <- off
+	}
+	if _, err := w.Write(data[:googleapi.MinUploadChunkSize+10]); err != nil {
+		t.Fatal(err)
+	}
+	for off := range checkpoints {
+		if off == googleapi.MinUploadChunkSize {
+			break
+		}
+	}
+	cancel()
+	if err := w.Close(); err == nil {
+		t.Fatal("got nil error from Close of canceled upload")
+	}
+	uri := w.SessionURI()
+
+	w, err = obj.ResumeWriter(context.Background(), uri)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := w.Offset(), int64(googleapi.MinUploadChunkSize); got != want {
+		t.Fatalf("got offset %d, want %d", got, want)
+	}
+	w.ChunkSize = googleapi.MinUploadChunkSize
+	if _, err := w.Write(data[w.Offset():]); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(s.data, data) {
+		t.Errorf("got %d bytes, want %d", len(s.data), len(data))
+	}
+	if got, want := w.Attrs().Size, int64(len(data)); got != want {
+		t.Errorf("got size %d, want %d", got, want)
+	}
+	if s.failPuts != 0 {
+		t.Error("the failed chunk was not retried")
+	}
+
+	// Resuming a complete upload only reports the object.
+	w, err = obj.ResumeWriter(context.Background(), uri)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := w.Offset(), int64(len(data)); got != want {
+		t.Errorf("complete upload: got offset %d, want %d", got, want)
+	}
+	if err := w.Close(); err != nil || w.Attrs() == nil {
+		t.Errorf("complete upload: got (%v, %v), want the object", w.Attrs(), err)
+	}
+}
+
+func TestWriterVerifyChecksums(t *testing.T) {
+	data := []byte("hello, world")
+	for _, test := range []struct {
+		desc   string
+		stored []byte // the data whose checksums the server reports
+		fields string // the checksum fields that the server reports
+		verify bool
+		delete bool
+		want   string // the checksum that doesn't match, if any
+	}{
+		{desc: "match", stored: data, fields: "crc32c,md5", verify: true},
+		{desc: "crc32c mismatch", stored: []byte("corrupt"), fields: "crc32c,md5", verify: true, want: "crc32c"},
+		{desc: "md5 not compared", stored: []byte("corrupt"), fields: "md5", verify: true},
+		{desc: "no checksums", stored: []byte("corrupt"), verify: true},
+		{desc: "delete", stored: []byte("corrupt"), fields: "crc32c", verify: true, delete: true, want: "crc32c"},
+		{desc: "not verified by default", stored: []byte("corrupt"), fields: "crc32c,md5"},
+	} {
+		t.Run(test.desc, func(t *testing.T) {
+			var deleted string
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.Method == "DELETE" {
+					deleted = r.URL.Path + "?generation=" + r.URL.Query().Get("generation")
+					w.WriteHeader(http.StatusNoContent)
+					return
+				}
+				ioutil.ReadAll(r.Body)
+				obj := fmt.Sprintf(`"bucket": "bucket", "name": "obj", "generation": "7", "size": "%d"`, len(data))
+				if strings.Contains(test.fields, "crc32c") {
+					obj += fmt.Sprintf(`, "crc32c": %q`, encodeUint32(crc32.Checksum(test.stored, crc32cTable)))
+				}
+				if strings.Contains(test.fields, "md5") {
+					sum := md5.Sum(test.stored)
+					obj += fmt.Sprintf(`, "md5Hash": %q`, base64.StdEncoding.EncodeToString(sum[:]))
+				}
+				fmt.Fprintf(w, "{%s}", obj)
+			}))
+			defer srv.Close()
+			client, err := NewClient(context.Background(), option.WithEndpoint(srv.URL+"/storage/v1/"), option.WithoutAuthentication())
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			w := client.Bucket("bucket").Object("obj").NewWriter(context.Background())
+			w.VerifyChecksums = test.verify
+			w.DeleteOnChecksumMismatch = test.delete
+			if _, err := w.Write(data); err != nil {
+				t.Fatal(err)
+			}
+			err = w.Close()
+			if test.want == "" {
+				if err != nil {
+					t.Fatalf("got %v, want nil", err)
+				}
+				return
+			}
+			var mismatch *ChecksumMismatchError
+			if !errors.As(err, &mismatch) {
+				t.Fatalf("got %v, want a *ChecksumMismatchError", err)
+			}
+			if mismatch.Checksum != test.want || mismatch.Generation != 7 || mismatch.Deleted != test.delete {
+				t.Errorf("got %+v, want a %s mismatch of generation 7 with Deleted=%v", mismatch, test.want, test.delete)
+			}
+			if test.delete {
+				if want := "/storage/v1/b/bucket/o/obj?generation=7"; deleted != want {
+					t.Errorf("got delete of %q, want %q", deleted, want)
+				}
+			}
+		})
+	}
+}